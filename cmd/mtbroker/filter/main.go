@@ -17,12 +17,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/kelseyhightower/envconfig"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
@@ -37,6 +44,7 @@ import (
 	tracingconfig "knative.dev/pkg/tracing/config"
 
 	broker "knative.dev/eventing/cmd/mtbroker"
+	"knative.dev/eventing/pkg/apis/feature"
 	"knative.dev/eventing/pkg/mtbroker/filter"
 	"knative.dev/eventing/pkg/reconciler/names"
 
@@ -57,6 +65,9 @@ type envConfig struct {
 	PodName       string `envconfig:"POD_NAME" required:"true"`
 	ContainerName string `envconfig:"CONTAINER_NAME" required:"true"`
 	Port          int    `envconfig:"FILTER_PORT" default:"8080"`
+	// NodeName is the node of the filter, for the topology-aware-dispatch
+	// to prefer the subscriber endpoints of its zone.
+	NodeName string `envconfig:"NODE_NAME"`
 }
 
 func main() {
@@ -118,6 +129,35 @@ func main() {
 	if err != nil {
 		logger.Fatal("Error creating Handler", zap.Error(err))
 	}
+	triggerInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: handler.TriggerDeleted,
+	})
+	// Watch the reply validation config map, the replies only being validated
+	// against the CloudEvents spec when it doesn't exist.
+	configMapWatcher.WatchWithDefault(corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: filter.ReplyValidationConfigName},
+	}, handler.UpdateReplyValidation)
+
+	// Prefer the subscriber endpoints of the zone of the filter when the
+	// topology-aware-dispatch feature is enabled, the Services and the
+	// EndpointSlices of the cluster only being watched from then on.
+	kubeFactory := kubeinformers.NewSharedInformerFactory(kubeClient, controller.GetResyncPeriod(ctx))
+	if zone := nodeZone(ctx, logger, kubeClient, env.NodeName); zone != "" {
+		handler.SetZoneRouter(filter.NewZoneRouter(zone,
+			kubeFactory.Core().V1().Services().Lister(),
+			kubeFactory.Discovery().V1beta1().EndpointSlices().Lister()))
+	}
+	var startKubeFactory sync.Once
+	configMapWatcher.WatchWithDefault(corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: feature.FlagsConfigName},
+	}, func(cm *corev1.ConfigMap) {
+		handler.UpdateFeatures(cm)
+		if flags, err := feature.NewFlagsConfigFromConfigMap(cm); err == nil && flags.IsEnabled(feature.TopologyAwareDispatch) {
+			startKubeFactory.Do(func() {
+				go kubeFactory.Start(ctx.Done())
+			})
+		}
+	})
 
 	// configMapWatcher does not block, so start it first.
 	if err = configMapWatcher.Start(ctx.Done()); err != nil {
@@ -140,6 +180,19 @@ func main() {
 	logger.Info("Exiting...")
 }
 
+// nodeZone returns the zone of the node, empty when it can't be told.
+func nodeZone(ctx context.Context, logger *zap.Logger, kubeClient kubernetes.Interface, nodeName string) string {
+	if nodeName == "" {
+		return ""
+	}
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		logger.Warn("Failed to get the node, the sends won't prefer the endpoints of its zone", zap.String("node", nodeName), zap.Error(err))
+		return ""
+	}
+	return filter.NodeZone(node)
+}
+
 func flush(logger *zap.SugaredLogger) {
 	_ = logger.Sync()
 	metrics.FlushExporter()
@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
 	// Uncomment the following line to load the gcp plugin (only required to authenticate against GKE clusters).
 	// _ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -28,6 +29,7 @@ import (
 	"go.uber.org/zap"
 
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	endpointsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/endpoints"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection"
@@ -35,6 +37,7 @@ import (
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/network"
 	"knative.dev/pkg/signals"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/tracing"
@@ -42,6 +45,7 @@ import (
 
 	cmdbroker "knative.dev/eventing/cmd/mtbroker"
 	brokerinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1/broker"
+	triggerinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1/trigger"
 	"knative.dev/eventing/pkg/kncloudevents"
 	broker "knative.dev/eventing/pkg/mtbroker"
 	"knative.dev/eventing/pkg/mtbroker/ingress"
@@ -67,6 +71,21 @@ type envConfig struct {
 	ContainerName string `envconfig:"CONTAINER_NAME" required:"true"`
 	Port          int    `envconfig:"INGRESS_PORT" default:"8080"`
 	MaxTTL        int    `envconfig:"MAX_TTL" default:"255"`
+	// ReplyKey is the key shared by the replicas signing the reply ids of the
+	// request-reply mode, the replies being only correlated within each
+	// replica when empty.
+	ReplyKey string `envconfig:"REPLY_KEY"`
+	// ReplyTimeout is how long the callers wait for the reply of their event
+	// in request-reply mode, which is disabled when zero.
+	ReplyTimeout time.Duration `envconfig:"REPLY_TIMEOUT" default:"30s"`
+	// RecordingMaxEvents and RecordingRetention bound the events recorded
+	// per recording Broker, and RecordingMaxBrokers the recording Brokers.
+	RecordingMaxEvents  int           `envconfig:"RECORDING_MAX_EVENTS" default:"10000"`
+	RecordingRetention  time.Duration `envconfig:"RECORDING_RETENTION" default:"1h"`
+	RecordingMaxBrokers int           `envconfig:"RECORDING_MAX_BROKERS" default:"100"`
+	// ReplayPort is the port the replays of the recorded events are served
+	// on, the recording being disabled when zero, the default.
+	ReplayPort int `envconfig:"REPLAY_PORT" default:"0"`
 }
 
 func main() {
@@ -144,6 +163,34 @@ func main() {
 		Logger:       logger,
 		BrokerLister: brokerLister,
 	}
+	if env.ReplyTimeout > 0 {
+		// The replies reaching the other replicas are forwarded to the one
+		// waiting for them, found in the endpoints of the ingress.
+		registry := &ingress.EndpointsReplicas{
+			Lister:    endpointsinformer.Get(ctx).Lister(),
+			Namespace: system.Namespace(),
+			Name:      names.BrokerIngressName,
+			Port:      env.Port,
+		}
+		h.Replies = ingress.NewReplies(env.PodName, []byte(env.ReplyKey), registry, env.ReplyTimeout)
+	}
+	if env.ReplayPort > 0 && env.RecordingMaxEvents > 0 && env.RecordingMaxBrokers > 0 {
+		h.Recording = ingress.NewRecording(env.RecordingMaxBrokers, env.RecordingMaxEvents, env.RecordingRetention)
+		replayHandler := &ingress.ReplayHandler{
+			Recording:     h.Recording,
+			Sender:        sender,
+			TriggerLister: triggerinformer.Get(ctx).Lister(),
+			FilterHost:    network.GetServiceHostname(names.BrokerFilterName, system.Namespace()),
+			KubeClient:    kubeclient.Get(ctx),
+			Replica:       env.PodName,
+			Logger:        logger,
+		}
+		go func() {
+			if err := kncloudevents.NewHTTPMessageReceiver(env.ReplayPort).StartListen(ctx, replayHandler); err != nil {
+				logger.Error("Failed to serve the replays", zap.Error(err))
+			}
+		}()
+	}
 
 	// configMapWatcher does not block, so start it first.
 	if err = configMapWatcher.Start(ctx.Done()); err != nil {
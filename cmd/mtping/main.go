@@ -37,7 +37,14 @@ func main() {
 	// which under the cover delays the release of the lease.
 	ctx := mtping.NewDelayingContext(sctx, mtping.GetNoShutDownAfterValue())
 
+	// Let the adapter quiesce as soon as SIGTERM is received, when configured to.
+	ctx = mtping.WithTerminationSignal(ctx, sctx.Done())
+
 	ctx = adapter.WithController(ctx, mtping.NewController)
-	ctx = adapter.WithHAEnabled(ctx)
+	// When sharding, the buckets are claimed by the adapter itself, for them
+	// to be rebalanced as replicas come and go.
+	if !mtping.ShardingEnabled() {
+		ctx = adapter.WithHAEnabled(ctx)
+	}
 	adapter.MainWithContext(ctx, component, mtping.NewEnvConfig, mtping.NewAdapter)
 }
@@ -20,16 +20,22 @@ import (
 	"context"
 	"os"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
 
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection"
 	"knative.dev/pkg/injection/sharedmain"
 	"knative.dev/pkg/leaderelection"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/resolver"
 	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
 	tracingconfig "knative.dev/pkg/tracing/config"
 	"knative.dev/pkg/webhook"
 	"knative.dev/pkg/webhook/certificates"
@@ -45,6 +51,7 @@ import (
 	"knative.dev/eventing/pkg/apis/eventing"
 	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/apis/feature"
 	"knative.dev/eventing/pkg/apis/flows"
 	flowsv1 "knative.dev/eventing/pkg/apis/flows/v1"
 	flowsv1beta1 "knative.dev/eventing/pkg/apis/flows/v1beta1"
@@ -53,6 +60,7 @@ import (
 	messagingv1 "knative.dev/eventing/pkg/apis/messaging/v1"
 	messagingv1beta1 "knative.dev/eventing/pkg/apis/messaging/v1beta1"
 	"knative.dev/eventing/pkg/apis/sources"
+	pingdefaultconfig "knative.dev/eventing/pkg/apis/sources/config"
 	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
 	sourcesv1alpha1 "knative.dev/eventing/pkg/apis/sources/v1alpha1"
 	sourcesv1alpha2 "knative.dev/eventing/pkg/apis/sources/v1alpha2"
@@ -114,17 +122,33 @@ var ourTypes = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
 
 var callbacks = map[schema.GroupVersionKind]validation.Callback{}
 
+// namespaceDefaultsGetter watches the config-br-defaults ConfigMaps of all
+// the namespaces for the namespace-local Broker defaults.
+func namespaceDefaultsGetter(ctx context.Context) defaultconfig.NamespaceDefaultsGetter {
+	factory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeclient.Get(ctx), controller.GetResyncPeriod(ctx),
+		kubeinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", defaultconfig.DefaultsConfigName).String()
+		}))
+	lister := factory.Core().V1().ConfigMaps().Lister()
+	factory.Start(ctx.Done())
+	return defaultconfig.NewNamespaceDefaultsGetter(lister, system.Namespace())
+}
+
 func NewDefaultingAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 	// Decorate contexts with the current state of the config.
-	store := defaultconfig.NewStore(logging.FromContext(ctx).Named("config-store"))
+	store := defaultconfig.NewStore(logging.FromContext(ctx).Named("config-store")).
+		WithNamespaceDefaults(namespaceDefaultsGetter(ctx))
 	store.WatchConfigs(cmw)
 
 	channelStore := channeldefaultconfig.NewStore(logging.FromContext(ctx).Named("channel-config-store"))
 	channelStore.WatchConfigs(cmw)
 
+	pingStore := pingdefaultconfig.NewStore(logging.FromContext(ctx).Named("ping-config-store"))
+	pingStore.WatchConfigs(cmw)
+
 	// Decorate contexts with the current state of the config.
 	ctxFunc := func(ctx context.Context) context.Context {
-		return channelStore.ToContext(store.ToContext(ctx))
+		return pingStore.ToContext(channelStore.ToContext(store.ToContext(ctx)))
 	}
 
 	return defaulting.NewAdmissionController(ctx,
@@ -148,15 +172,30 @@ func NewDefaultingAdmissionController(ctx context.Context, cmw configmap.Watcher
 
 func NewValidationAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 	// Decorate contexts with the current state of the config.
-	store := defaultconfig.NewStore(logging.FromContext(ctx).Named("config-store"))
+	store := defaultconfig.NewStore(logging.FromContext(ctx).Named("config-store")).
+		WithNamespaceDefaults(namespaceDefaultsGetter(ctx))
 	store.WatchConfigs(cmw)
 
 	channelStore := channeldefaultconfig.NewStore(logging.FromContext(ctx).Named("channel-config-store"))
 	channelStore.WatchConfigs(cmw)
 
+	pingStore := pingdefaultconfig.NewStore(logging.FromContext(ctx).Named("ping-config-store"))
+	pingStore.WatchConfigs(cmw)
+
+	featureStore := feature.NewStore(logging.FromContext(ctx).Named("feature-config-store"))
+	featureStore.WatchConfigs(cmw)
+
+	// Resolves the sinks of the sources at admission when the feature is
+	// enabled. Nothing is reconciled when the resolved sinks change.
+	sinkResolver := resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+
 	// Decorate contexts with the current state of the config.
 	ctxFunc := func(ctx context.Context) context.Context {
-		return channelStore.ToContext(store.ToContext(ctx))
+		ctx = featureStore.ToContext(pingStore.ToContext(channelStore.ToContext(store.ToContext(ctx))))
+		if feature.FromContext(ctx).IsEnabled(feature.AdmissionSinkResolution) {
+			ctx = sources.WithSinkResolver(ctx, sinkResolver)
+		}
+		return ctx
 	}
 
 	return validation.NewAdmissionController(ctx,
@@ -194,8 +233,10 @@ func NewConfigValidationController(ctx context.Context, _ configmap.Watcher) *co
 		configmap.Constructors{
 			tracingconfig.ConfigName: tracingconfig.NewTracingConfigFromConfigMap,
 			// metrics.ConfigMapName():   metricsconfig.NewObservabilityConfigFromConfigMap,
-			logging.ConfigMapName():        logging.NewConfigFromConfigMap,
-			leaderelection.ConfigMapName(): leaderelection.NewConfigFromConfigMap,
+			logging.ConfigMapName():                  logging.NewConfigFromConfigMap,
+			leaderelection.ConfigMapName():           leaderelection.NewConfigFromConfigMap,
+			pingdefaultconfig.PingDefaultsConfigName: pingdefaultconfig.NewPingDefaultsConfigFromConfigMap,
+			feature.FlagsConfigName:                  feature.NewFlagsConfigFromConfigMap,
 		},
 	)
 }
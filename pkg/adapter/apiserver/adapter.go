@@ -22,6 +22,7 @@ import (
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -67,6 +68,8 @@ func (a *apiServerAdapter) start(ctx context.Context, stopCh <-chan struct{}) er
 		source: a.source,
 		logger: a.logger,
 		ref:    a.config.EventMode == v1alpha2.ReferenceMode,
+
+		initial: a.config.SendInitialEvents,
 	}
 
 	if a.config.ResourceOwner != nil {
@@ -102,12 +105,15 @@ func (a *apiServerAdapter) start(ctx context.Context, stopCh <-chan struct{}) er
 				}
 
 				lw := &cache.ListWatch{
-					ListFunc:  asUnstructuredLister(ctx, res.List, configRes.LabelSelector),
-					WatchFunc: asUnstructuredWatcher(ctx, res.Watch, configRes.LabelSelector),
+					ListFunc:  asUnstructuredLister(ctx, res.List, configRes.LabelSelector, configRes.FieldSelector),
+					WatchFunc: asUnstructuredWatcher(ctx, res.Watch, configRes.LabelSelector, configRes.FieldSelector),
 				}
 
 				reflector := cache.NewReflector(lw, &unstructured.Unstructured{}, delegate, resyncPeriod)
 				go reflector.Run(stop)
+				if a.config.SendInitialEvents && a.config.ResyncPeriod > 0 {
+					go a.resync(lw, delegate, stop)
+				}
 				exists = true
 				break
 			}
@@ -119,17 +125,52 @@ func (a *apiServerAdapter) start(ctx context.Context, stopCh <-chan struct{}) er
 	}
 
 	<-stopCh
-	stop <- struct{}{}
+	close(stop)
 	return nil
 }
 
+// resync sends the add events of the objects listed by lw again every
+// resync period, the reflector sending the initial ones.
+func (a *apiServerAdapter) resync(lw cache.ListerWatcher, store cache.Store, stop <-chan struct{}) {
+	ticker := time.NewTicker(a.config.ResyncPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		list, err := lw.List(metav1.ListOptions{})
+		if err != nil {
+			a.logger.Errorw("failed to list the resources to resync", zap.Error(err))
+			continue
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			a.logger.Errorw("failed to extract the resources to resync", zap.Error(err))
+			continue
+		}
+		items := make([]interface{}, 0, len(objs))
+		for _, obj := range objs {
+			items = append(items, obj)
+		}
+		if err := store.Replace(items, ""); err != nil {
+			a.logger.Errorw("failed to resync the resources", zap.Error(err))
+		}
+	}
+}
+
 type unstructuredLister func(context.Context, metav1.ListOptions) (*unstructured.UnstructuredList, error)
 
-func asUnstructuredLister(ctx context.Context, ulist unstructuredLister, selector string) cache.ListFunc {
+func asUnstructuredLister(ctx context.Context, ulist unstructuredLister, selector, fieldSelector string) cache.ListFunc {
 	return func(opts metav1.ListOptions) (runtime.Object, error) {
 		if selector != "" && opts.LabelSelector == "" {
 			opts.LabelSelector = selector
 		}
+		if fieldSelector != "" && opts.FieldSelector == "" {
+			opts.FieldSelector = fieldSelector
+		}
 		ul, err := ulist(ctx, opts)
 		if err != nil {
 			return nil, err
@@ -140,11 +181,14 @@ func asUnstructuredLister(ctx context.Context, ulist unstructuredLister, selecto
 
 type structuredWatcher func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
 
-func asUnstructuredWatcher(ctx context.Context, wf structuredWatcher, selector string) cache.WatchFunc {
+func asUnstructuredWatcher(ctx context.Context, wf structuredWatcher, selector, fieldSelector string) cache.WatchFunc {
 	return func(lo metav1.ListOptions) (watch.Interface, error) {
 		if selector != "" && lo.LabelSelector == "" {
 			lo.LabelSelector = selector
 		}
+		if fieldSelector != "" && lo.FieldSelector == "" {
+			lo.FieldSelector = fieldSelector
+		}
 		return wf(ctx, lo)
 	}
 }
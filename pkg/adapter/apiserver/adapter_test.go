@@ -28,12 +28,14 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
 	discoveryfake "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/dynamic"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	kubetesting "k8s.io/client-go/testing"
 	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/eventing/pkg/apis/sources"
 	rectesting "knative.dev/eventing/pkg/reconciler/testing"
 	"knative.dev/pkg/logging"
 	pkgtesting "knative.dev/pkg/reconciler/testing"
@@ -181,6 +183,108 @@ func TestAdapter_StartNonNamespacedResource(t *testing.T) {
 	}
 }
 
+func TestAdapter_SendInitialEvents(t *testing.T) {
+	tests := map[string]struct {
+		sendInitialEvents bool
+		resyncPeriod      time.Duration
+		wantMin, wantMax  int
+	}{
+		"no initial events": {},
+		"initial events": {
+			sendInitialEvents: true,
+			wantMin:           1,
+			wantMax:           1,
+		},
+		"resynced initial events": {
+			sendInitialEvents: true,
+			resyncPeriod:      200 * time.Millisecond,
+			wantMin:           2,
+			wantMax:           10,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+
+			config := Config{
+				Namespace: "default",
+				Resources: []ResourceWatch{{
+					GVR: schema.GroupVersionResource{
+						Version:  "v1",
+						Resource: "pods",
+					},
+				}},
+				EventMode:         "Resource",
+				SendInitialEvents: tc.sendInitialEvents,
+				ResyncPeriod:      tc.resyncPeriod,
+			}
+			ctx, _ := pkgtesting.SetupFakeContext(t)
+
+			a := &apiServerAdapter{
+				ce:     ce,
+				logger: logging.FromContext(ctx),
+				config: config,
+
+				discover: makeDiscoveryClient(),
+				k8s:      makeDynamicClient(simplePod("foo", "default")),
+				source:   "unit-test",
+				name:     "unittest",
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				a.Start(ctx)
+				close(done)
+			}()
+
+			time.Sleep(1 * time.Second)
+
+			cancel()
+			<-done
+
+			sent := ce.Sent()
+			if len(sent) < tc.wantMin || len(sent) > tc.wantMax {
+				t.Fatalf("Expected between %d and %d events to be sent, got %d", tc.wantMin, tc.wantMax, len(sent))
+			}
+			for _, event := range sent {
+				if event.Type() != sources.ApiServerSourceAddEventType {
+					t.Errorf("Expected %q events to be sent, got %q", sources.ApiServerSourceAddEventType, event.Type())
+				}
+			}
+		})
+	}
+}
+
+func TestAdapter_Selectors(t *testing.T) {
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+
+	var listed, watched metav1.ListOptions
+	list := asUnstructuredLister(ctx, func(_ context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+		listed = opts
+		return &unstructured.UnstructuredList{}, nil
+	}, "app=test", "spec.nodeName=test-node")
+	watchFunc := asUnstructuredWatcher(ctx, func(_ context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+		watched = opts
+		return watch.NewEmptyWatch(), nil
+	}, "app=test", "spec.nodeName=test-node")
+
+	if _, err := list(metav1.ListOptions{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, err := watchFunc(metav1.ListOptions{}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for what, opts := range map[string]metav1.ListOptions{"listed": listed, "watched": watched} {
+		if opts.LabelSelector != "app=test" {
+			t.Errorf("Expected the resources to be %s with the label selector app=test, got %q", what, opts.LabelSelector)
+		}
+		if opts.FieldSelector != "spec.nodeName=test-node" {
+			t.Errorf("Expected the resources to be %s with the field selector spec.nodeName=test-node, got %q", what, opts.FieldSelector)
+		}
+	}
+}
+
 // Common methods:
 
 // GetDynamicClient returns the mockDynamicClient to use for this test case.
@@ -16,6 +16,8 @@ limitations under the License.
 package apiserver
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	v1 "knative.dev/eventing/pkg/apis/sources/v1"
 )
@@ -28,6 +30,11 @@ type ResourceWatch struct {
 	// label selector.
 	// +optional
 	LabelSelector string `json:"selector,omitempty"`
+
+	// FieldSelector filters this source to objects to those resources pass the
+	// field selector.
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
 }
 
 type Config struct {
@@ -52,4 +59,14 @@ type Config struct {
 	// Defaults to `Reference`
 	// +optional
 	EventMode string `json:"mode,omitempty"`
+
+	// SendInitialEvents sends an add event for each existing object of the
+	// Resources on startup and when they are listed again.
+	// +optional
+	SendInitialEvents bool `json:"sendInitialEvents,omitempty"`
+
+	// ResyncPeriod is the interval the add events of the existing objects
+	// are sent again, when SendInitialEvents.
+	// +optional
+	ResyncPeriod time.Duration `json:"resyncPeriod,omitempty"`
 }
@@ -20,15 +20,20 @@ import (
 	"context"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 	"go.uber.org/zap"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/eventing/pkg/adapter/apiserver/events"
+	"knative.dev/eventing/pkg/adapter/v2"
 )
 
 type resourceDelegate struct {
 	ce     cloudevents.Client
 	source string
 	ref    bool
+	// initial sends the add events of the existing objects when they are
+	// listed.
+	initial bool
 
 	logger *zap.SugaredLogger
 }
@@ -42,9 +47,7 @@ func (a *resourceDelegate) Add(obj interface{}) error {
 		return err
 	}
 
-	if result := a.ce.Send(context.Background(), event); !cloudevents.IsACK(result) {
-		a.logger.Errorw("failed to send event", zap.Error(result))
-	}
+	a.send(event)
 	return nil
 }
 
@@ -55,9 +58,7 @@ func (a *resourceDelegate) Update(obj interface{}) error {
 		return err
 	}
 
-	if result := a.ce.Send(context.Background(), event); !cloudevents.IsACK(result) {
-		a.logger.Error("failed to send event", zap.Error(result))
-	}
+	a.send(event)
 	return nil
 }
 
@@ -68,8 +69,35 @@ func (a *resourceDelegate) Delete(obj interface{}) error {
 		return err
 	}
 
-	if result := a.ce.Send(context.Background(), event); !cloudevents.IsACK(result) {
-		a.logger.Error("failed to send event", zap.Error(result))
+	a.send(event)
+	return nil
+}
+
+// send sends the event without waiting for its delivery when the client
+// batches the events, for the events of the informer to be batched together.
+func (a *resourceDelegate) send(event cloudevents.Event) {
+	logResult := func(result protocol.Result) {
+		if !cloudevents.IsACK(result) {
+			a.logger.Errorw("failed to send event", zap.Error(result))
+		}
+	}
+	if async, ok := a.ce.(adapter.AsyncSender); ok {
+		async.SendAsync(context.Background(), event, logResult)
+		return
+	}
+	logResult(a.ce.Send(context.Background(), event))
+}
+
+// Implements cache.Store, sending an add event for each listed object when
+// the initial events are requested.
+func (a *resourceDelegate) Replace(objs []interface{}, _ string) error {
+	if !a.initial {
+		return nil
+	}
+	for _, obj := range objs {
+		// The events failing to be created are logged, the others are still
+		// sent.
+		_ = a.Add(obj)
 	}
 	return nil
 }
@@ -96,11 +124,6 @@ func (a *resourceDelegate) GetByKey(key string) (item interface{}, exists bool,
 	return nil, false, nil
 }
 
-// Implements cache.Store
-func (a *resourceDelegate) Replace([]interface{}, string) error {
-	return nil
-}
-
 // Implements cache.Store
 func (a *resourceDelegate) Resync() error {
 	return nil
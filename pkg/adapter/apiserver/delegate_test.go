@@ -16,8 +16,13 @@ limitations under the License.
 package apiserver
 
 import (
+	"context"
 	"testing"
 
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
 	"knative.dev/eventing/pkg/apis/sources"
 )
 
@@ -57,6 +62,38 @@ func TestResourceDeleteEventNil(t *testing.T) {
 	validateNotSent(t, ce, sources.ApiServerSourceDeleteEventType)
 }
 
+func TestResourceReplaceEvents(t *testing.T) {
+	d, ce := makeResourceAndTestingClient()
+	d.Replace([]interface{}{simplePod("unit", "test")}, "")
+	validateNotSent(t, ce, sources.ApiServerSourceAddEventType)
+
+	d.initial = true
+	d.Replace([]interface{}{simplePod("unit", "test")}, "")
+	validateSent(t, ce, sources.ApiServerSourceAddEventType)
+}
+
+// asyncClient records the events sent without waiting for their delivery.
+type asyncClient struct {
+	*adaptertest.TestCloudEventsClient
+	async int
+}
+
+func (c *asyncClient) SendAsync(ctx context.Context, out event.Event, done func(protocol.Result)) {
+	c.async++
+	done(c.Send(ctx, out))
+}
+
+func TestResourceSendAsync(t *testing.T) {
+	d, ce := makeResourceAndTestingClient()
+	async := &asyncClient{TestCloudEventsClient: ce}
+	d.ce = async
+	d.Add(simplePod("unit", "test"))
+	validateSent(t, ce, sources.ApiServerSourceAddEventType)
+	if async.async != 1 {
+		t.Errorf("Expected the event to be sent asynchronously, got %d async sends", async.async)
+	}
+}
+
 // HACKHACKHACK For test coverage.
 func TestResourceStub(t *testing.T) {
 	d, _ := makeResourceAndTestingClient()
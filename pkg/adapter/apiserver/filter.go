@@ -57,6 +57,16 @@ func (c *controllerFilter) Delete(obj interface{}) error {
 	return c.delegate.Delete(obj)
 }
 
+func (c *controllerFilter) Replace(objs []interface{}, resourceVersion string) error {
+	unfiltered := make([]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		if !c.filtered(obj) {
+			unfiltered = append(unfiltered, obj)
+		}
+	}
+	return c.delegate.Replace(unfiltered, resourceVersion)
+}
+
 func (c *controllerFilter) filtered(obj interface{}) bool {
 	u := obj.(*unstructured.Unstructured)
 	controller := metav1.GetControllerOf(u)
@@ -86,11 +96,6 @@ func (c *controllerFilter) GetByKey(key string) (item interface{}, exists bool,
 	return nil, false, nil
 }
 
-// Implements cache.Store
-func (c *controllerFilter) Replace([]interface{}, string) error {
-	return nil
-}
-
 // Implements cache.Store
 func (c *controllerFilter) Resync() error {
 	return nil
@@ -95,6 +95,13 @@ func TestControllerDeleteEventWithGoodControllerNoAPIVersion(t *testing.T) {
 	validateSent(t, tc, sources.ApiServerSourceDeleteRefEventType)
 }
 
+func TestControllerReplaceEventsWithGoodController(t *testing.T) {
+	c, tc := makeController("apps/v1", "ReplicaSet")
+	c.delegate.(*resourceDelegate).initial = true
+	c.Replace([]interface{}{simplePod("unit", "test"), simpleOwnedPod("unit", "test")}, "")
+	validateSent(t, tc, sources.ApiServerSourceAddRefEventType)
+}
+
 func makeController(apiVersion, kind string) (*controllerFilter, *adaptertest.TestCloudEventsClient) {
 	delegate, tc := makeRefAndTestingClient()
 	return &controllerFilter{
@@ -20,64 +20,553 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
 	"sync"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	kle "knative.dev/pkg/leaderelection"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 
 	"knative.dev/eventing/pkg/adapter/v2"
 	"knative.dev/eventing/pkg/apis/sources/v1beta1"
+	"knative.dev/eventing/pkg/client/clientset/versioned"
+	eventingclient "knative.dev/eventing/pkg/client/injection/client"
 )
 
 const (
 	EnvNoShutdownAfter = "K_NO_SHUTDOWN_AFTER"
+
+	// EnvHealthPort is the port serving the drain state of the adapter.
+	EnvHealthPort = "K_HEALTH_PORT"
+
+	// DefaultHealthPort is the health port of the adapter deployment.
+	DefaultHealthPort = 8080
+
+	// EnvDeliveryStatus enables reporting the delivery statuses of the
+	// sources.
+	EnvDeliveryStatus = "K_SOURCE_DELIVERY_STATUS"
+
+	// defaultStopTimeout bounds the time the adapter waits for the jobs in
+	// flight on termination, when no drain timeout is set. It must be lower
+	// than the termination grace period of the pod, 30s by default.
+	defaultStopTimeout = 25 * time.Second
 )
 
+type envConfig struct {
+	adapter.EnvConfig
+
+	// MaxGoroutines is the maximum number of jobs running concurrently.
+	// Fires exceeding this budget are dropped. Zero means unbounded.
+	MaxGoroutines int `envconfig:"K_MAX_GOROUTINES"`
+
+	// MaxConcurrentSends is the maximum number of fires sending their
+	// events at the same time. The fires exceeding it wait up to
+	// SendQueueTimeout before being dropped. Zero means unbounded.
+	MaxConcurrentSends int           `envconfig:"K_MAX_CONCURRENT_SENDS"`
+	SendQueueTimeout   time.Duration `envconfig:"K_SEND_QUEUE_TIMEOUT" default:"1s"`
+
+	// Region is set as the region extension of all events. When empty, the
+	// region is read from the labels of the node named by NodeName.
+	Region string `envconfig:"K_REGION"`
+
+	// NodeName is the name of the node the adapter runs on.
+	NodeName string `envconfig:"NODE_NAME"`
+
+	// TraceParent enables setting a traceparent extension on all events.
+	TraceParent bool `envconfig:"K_TRACEPARENT"`
+
+	// AttemptSpans enables a child span per send attempt in the span of
+	// each fire.
+	AttemptSpans bool `envconfig:"K_ATTEMPT_SPANS"`
+
+	// SchemaFingerprints enables recording a fingerprint of the shape of
+	// the events of each source, logging its changes.
+	SchemaFingerprints bool `envconfig:"K_SCHEMA_FINGERPRINTS"`
+
+	// QueueDir is the directory where the events failing to be sent are
+	// persisted, to be sent again on restart. Disabled when empty.
+	QueueDir string `envconfig:"K_QUEUE_DIR"`
+
+	// QueueMaxBytes caps the size of the persisted events. Zero means unbounded.
+	QueueMaxBytes int64 `envconfig:"K_QUEUE_MAX_BYTES"`
+
+	// DrainTimeout, when set, makes the adapter stop firing as soon as it
+	// is asked to terminate, and bounds the time it waits for the events
+	// in flight to be sent.
+	DrainTimeout time.Duration `envconfig:"K_DRAIN_TIMEOUT"`
+
+	// HealthPort, when set, is the port serving the drain state of the
+	// adapter on /healthz, and draining it on /drain, for the preStop hook
+	// of the pods to wait for the events in flight to be sent.
+	HealthPort int `envconfig:"K_HEALTH_PORT"`
+
+	// SinkResolutionTTL, when set, makes the adapter read the address of the
+	// Addressable sinks on each fire, caching it for this duration.
+	SinkResolutionTTL time.Duration `envconfig:"K_SINK_RESOLUTION_TTL"`
+
+	// DedupRedisURL is the URL of the Redis server shared by the replicas
+	// to emit each tick once. Disabled when empty.
+	DedupRedisURL string `envconfig:"K_DEDUP_REDIS_URL"`
+
+	// DedupCollisionPolicy is what to do with the events whose ID is claimed
+	// by a different event, skip or send. Defaults to skip.
+	DedupCollisionPolicy string `envconfig:"K_DEDUP_COLLISION_POLICY"`
+
+	// RateLimit, when set, is the maximum number of fires per second across
+	// all the replicas, coordinating through the RateLimitConfigMap of the
+	// system namespace. Bursts of up to RateLimitBurst fires are allowed.
+	RateLimit          float64 `envconfig:"K_RATE_LIMIT"`
+	RateLimitBurst     int     `envconfig:"K_RATE_LIMIT_BURST" default:"1"`
+	RateLimitConfigMap string  `envconfig:"K_RATE_LIMIT_CONFIGMAP" default:"pingsource-mt-adapter-rate-limit"`
+
+	// RetryJitter is the jitter strategy applied to the retry backoff, one
+	// of none, full or equal. The retries aren't jittered when empty.
+	RetryJitter string `envconfig:"K_RETRY_JITTER"`
+
+	// RetryMaxAttempts and RetryMaxElapsed, when set, bound the attempts of
+	// a send and the time spent retrying it.
+	RetryMaxAttempts int           `envconfig:"K_RETRY_MAX_ATTEMPTS"`
+	RetryMaxElapsed  time.Duration `envconfig:"K_RETRY_MAX_ELAPSED"`
+
+	// FireJitter, when set, is the window the fires of the sources sharing
+	// a schedule are spread over.
+	FireJitter time.Duration `envconfig:"K_FIRE_JITTER"`
+
+	// LatencyWindow, when set, is the number of the last sends of each
+	// source whose average and LatencyPercentile latency are reported in
+	// the source status, at most once per LatencyReportInterval.
+	LatencyWindow         int           `envconfig:"K_LATENCY_WINDOW"`
+	LatencyPercentile     int           `envconfig:"K_LATENCY_PERCENTILE" default:"99"`
+	LatencyReportInterval time.Duration `envconfig:"K_LATENCY_REPORT_INTERVAL" default:"1m"`
+
+	// OTelMetrics enables recording the runner counters and the send latency
+	// in the mtping OpenCensus views, exported by the metrics backend along
+	// with the per-source views, such as to an OpenTelemetry collector with
+	// the opencensus backend.
+	OTelMetrics bool `envconfig:"K_OTEL_METRICS"`
+
+	// MaxConsecutivePanics is the number of consecutive fires the job of a
+	// source can panic on before the source is disabled and marked not
+	// ready. Zero never disables the sources.
+	MaxConsecutivePanics int `envconfig:"K_MAX_CONSECUTIVE_PANICS" default:"5"`
+
+	// SchedulesConfigMap, when set, is the name of the ConfigMap of the
+	// system namespace defining PingSources besides the CRD ones, see
+	// ConfigMapLoader.
+	SchedulesConfigMap string `envconfig:"K_SCHEDULES_CONFIGMAP"`
+
+	// DeliveryStatus enables reporting the delivery status of each source
+	// to a Lease, for the PingSource reconciler to propagate it to the
+	// SinkDeliverable condition of the source.
+	DeliveryStatus bool `envconfig:"K_SOURCE_DELIVERY_STATUS"`
+
+	// Sharding enables sharing the buckets of the leader election
+	// configuration between the replicas, see ShardingEnabled.
+	Sharding bool `envconfig:"K_SHARDING"`
+}
+
 // mtpingAdapter implements the PingSource mt adapter to sinks
 type mtpingAdapter struct {
 	logger    *zap.SugaredLogger
 	runner    CronJobRunner
+	client    versioned.Interface // for marking sources completed
+	quiesce   bool                // pause all schedules on termination
 	entryidMu sync.RWMutex
 	entryids  map[string]cron.EntryID // key: resource namespace/name
+	// applied holds the schedules of the sources debouncing their updates,
+	// guarded by entryidMu.
+	applied map[string]appliedSpec // key: resource namespace/name
+	// terminal holds the terminal states of the sources removed by the
+	// runner not written yet, guarded by entryidMu.
+	terminal map[string]func(status *v1beta1.PingSourceStatus) // key: resource namespace/name
+	clock    clock.Clock
+
+	// stopTimeout bounds the time the runner waits for the jobs in flight
+	// to be done on stop.
+	stopTimeout time.Duration
+
+	// healthPort serves the drain state of the runner, when set.
+	healthPort int
+
+	// deliveryStatus writes the delivery statuses reported by the runner,
+	// when set.
+	deliveryStatus *adapter.DeliveryStatusReporter
+
+	// schedules loads the sources of the schedulesConfigMap, when set.
+	schedules          *ConfigMapLoader
+	schedulesConfigMap string
+	kubeClient         kubernetes.Interface
+
+	// sharding is the configuration of the buckets shared by the replicas,
+	// nil when not sharding.
+	sharding *kle.ComponentConfig
+	// parked holds the schedules of the buckets given back to the other
+	// replicas, when sharding. It is never started, for them not to fire.
+	parked CronJobRunner
 }
 
 var (
 	_ adapter.Adapter = (*mtpingAdapter)(nil)
 	_ MTAdapter       = (*mtpingAdapter)(nil)
+	_ shardedAdapter  = (*mtpingAdapter)(nil)
 )
 
 func NewEnvConfig() adapter.EnvConfigAccessor {
-	return &adapter.EnvConfig{}
+	return &envConfig{}
 }
 
-func NewAdapter(ctx context.Context, _ adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+func NewAdapter(ctx context.Context, env adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
 	logger := logging.FromContext(ctx)
-	runner := NewCronJobsRunner(ceClient, kubeclient.Get(ctx), logging.FromContext(ctx))
 
-	return &mtpingAdapter{
+	a := &mtpingAdapter{
 		logger:    logger,
-		runner:    runner,
+		client:    eventingclient.Get(ctx),
 		entryidMu: sync.RWMutex{},
 		entryids:  make(map[string]cron.EntryID),
+		applied:   make(map[string]appliedSpec),
+		terminal:  make(map[string]func(status *v1beta1.PingSourceStatus)),
+		clock:     clock.RealClock{},
+
+		stopTimeout: defaultStopTimeout,
+	}
+
+	opts := []Option{WithCompletionHandler(func(namespace, name string) {
+		a.complete(ctx, namespace, name)
+	}), WithInvalidEventHandler(func(namespace, name string, err error) {
+		a.degrade(ctx, namespace, name, err)
+	}), WithMissingTemplateKeyHandler(func(namespace, name string, err error, skipped bool) {
+		a.degradeTemplates(ctx, namespace, name, err, skipped)
+	}), WithPartialFailureHandler(func(namespace, name string, sent, failed uint64) {
+		a.reportBatches(ctx, namespace, name, sent, failed)
+	})}
+	if cfg, ok := env.(*envConfig); ok {
+		region := cfg.Region
+		if region == "" && cfg.NodeName != "" {
+			region = nodeRegion(ctx, kubeclient.Get(ctx), cfg.NodeName)
+		}
+		opts = append(opts,
+			WithMaxGoroutines(cfg.MaxGoroutines),
+			WithRegion(region))
+		if cfg.MaxConcurrentSends > 0 {
+			opts = append(opts, WithMaxConcurrentSends(cfg.MaxConcurrentSends, cfg.SendQueueTimeout))
+		}
+		if cfg.TraceParent {
+			opts = append(opts, WithTraceParent())
+		}
+		if cfg.AttemptSpans {
+			opts = append(opts, WithAttemptSpans())
+		}
+		if cfg.SchemaFingerprints {
+			opts = append(opts, WithSchemaFingerprints())
+		}
+		if cfg.QueueDir != "" {
+			opts = append(opts, WithPersistentQueue(cfg.QueueDir, cfg.QueueMaxBytes))
+		}
+		if cfg.DedupRedisURL != "" {
+			if store, err := NewRedisDedupStore(cfg.DedupRedisURL); err != nil {
+				logger.Errorw("invalid dedup store, replicas may emit the same events", zap.Error(err))
+			} else {
+				opts = append(opts, WithDedupStore(store))
+			}
+			if cfg.DedupCollisionPolicy != "" {
+				if policy, err := ParseDedupCollisionPolicy(cfg.DedupCollisionPolicy); err != nil {
+					logger.Errorw("invalid dedup collision policy, colliding events will be skipped", zap.Error(err))
+				} else {
+					opts = append(opts, WithDedupCollisionPolicy(policy))
+				}
+			}
+		}
+		if cfg.RateLimit > 0 {
+			opts = append(opts, WithRateLimiter(NewConfigMapRateLimiter(kubeclient.Get(ctx), system.Namespace(),
+				cfg.RateLimitConfigMap, cfg.RateLimit, cfg.RateLimitBurst)))
+		}
+		if cfg.RetryJitter != "" {
+			if strategy, err := ParseJitterStrategy(cfg.RetryJitter); err != nil {
+				logger.Errorw("invalid retry jitter, retries won't be jittered", zap.Error(err))
+			} else {
+				opts = append(opts, WithRetryJitter(strategy))
+			}
+		}
+		if cfg.RetryMaxAttempts > 0 || cfg.RetryMaxElapsed > 0 {
+			opts = append(opts, WithRetryBackoff(cfg.RetryMaxAttempts, cfg.RetryMaxElapsed))
+		}
+		if cfg.FireJitter > 0 {
+			opts = append(opts, WithFireJitter(cfg.FireJitter))
+		}
+		if cfg.LatencyWindow > 0 {
+			opts = append(opts, WithSendLatency(cfg.LatencyWindow, cfg.LatencyPercentile),
+				WithLatencyHandler(cfg.LatencyReportInterval, func(namespace, name string, latency *v1beta1.PingSourceSendLatency) {
+					a.reportLatency(ctx, namespace, name, latency)
+				}))
+		}
+		if maxPanics := cfg.MaxConsecutivePanics; maxPanics > 0 {
+			opts = append(opts, WithPanicHandler(maxPanics, func(namespace, name string, recovered interface{}) {
+				a.disable(ctx, namespace, name, maxPanics, recovered)
+			}))
+		}
+		if cfg.OTelMetrics {
+			opts = append(opts, WithOTelMetrics())
+		}
+		if cfg.SinkResolutionTTL > 0 {
+			opts = append(opts, WithSinkResolution(dynamicclient.Get(ctx), cfg.SinkResolutionTTL))
+		}
+		if cfg.DrainTimeout > 0 {
+			a.quiesce = true
+			a.stopTimeout = cfg.DrainTimeout
+			opts = append(opts, WithDrainTimeout(cfg.DrainTimeout))
+		}
+		a.healthPort = cfg.HealthPort
+		if cfg.DeliveryStatus {
+			a.deliveryStatus = adapter.NewDeliveryStatusReporter(kubeclient.Get(ctx), resourceGroup, logger)
+			opts = append(opts, WithDeliveryStatus(a.deliveryStatus))
+		}
+	}
+	a.runner = NewCronJobsRunner(ceClient, kubeclient.Get(ctx), logging.FromContext(ctx), opts...)
+	if cfg, ok := env.(*envConfig); ok && cfg.SchedulesConfigMap != "" {
+		a.schedules = NewConfigMapLoader(a.runner, logger)
+		a.schedulesConfigMap = cfg.SchedulesConfigMap
+		a.kubeClient = kubeclient.Get(ctx)
+	}
+	if cfg, ok := env.(*envConfig); ok && cfg.Sharding {
+		cc, err := cfg.GetLeaderElectionConfig()
+		if err != nil {
+			logger.Errorw("invalid leader election configuration, using the default one", zap.Error(err))
+		}
+		a.sharding = cc
+		a.parked = NewCronJobsRunner(ceClient, kubeclient.Get(ctx), logger)
 	}
+	return a
+}
+
+func (a *mtpingAdapter) shardingConfig() *kle.ComponentConfig {
+	return a.sharding
 }
 
 // Start implements adapter.Adapter
 func (a *mtpingAdapter) Start(ctx context.Context) error {
 	a.logger.Info("Starting job runner...")
+	if terminating := terminationSignal(ctx); a.quiesce && terminating != nil {
+		go func() {
+			select {
+			case <-terminating:
+				// Stop firing right away, in-flight events are drained on Stop.
+				a.logger.Info("Termination requested, pausing all schedules")
+				a.runner.PauseAll()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	if a.schedules != nil {
+		watcher := configmap.NewInformedWatcher(a.kubeClient, system.Namespace())
+		a.schedules.Watch(watcher, system.Namespace(), a.schedulesConfigMap)
+		if err := watcher.Start(ctx.Done()); err != nil {
+			return fmt.Errorf("failed to watch the schedules configmap: %w", err)
+		}
+	}
+	if a.healthPort > 0 {
+		server := &http.Server{
+			Addr:    fmt.Sprintf(":%d", a.healthPort),
+			Handler: newHealthHandler(a.runner, a.stopTimeout, a.logger),
+		}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				a.logger.Errorw("health server failed", zap.Error(err))
+			}
+		}()
+		// The health server keeps serving until the runner is stopped.
+		defer server.Close()
+	}
+	if a.deliveryStatus != nil {
+		go a.deliveryStatus.Start(ctx)
+	}
 	a.runner.Start(ctx.Done())
-	defer a.runner.Stop()
+	defer func() {
+		if err := a.runner.StopWithTimeout(a.stopTimeout); err != nil {
+			a.logger.Warnw("runner stopped with jobs in flight, in-flight cloudevents may be lost", zap.Error(err))
+		}
+	}()
 
 	a.logger.Infof("runner stopped")
 	return nil
 }
 
+// forget forgets the schedule of the given source, already removed by the
+// runner.
+func (a *mtpingAdapter) forget(namespace, name string) {
+	a.entryidMu.Lock()
+	delete(a.entryids, fmt.Sprintf("%s/%s", namespace, name))
+	delete(a.applied, fmt.Sprintf("%s/%s", namespace, name))
+	a.entryidMu.Unlock()
+}
+
+// reload forgets when the schedule of the given source was last applied, for
+// its next update not to be debounced.
+func (a *mtpingAdapter) reload(namespace, name string) {
+	a.entryidMu.Lock()
+	delete(a.applied, fmt.Sprintf("%s/%s", namespace, name))
+	a.entryidMu.Unlock()
+}
+
+// updateStatus applies update to the status of the given source, retrying on
+// conflicts. The status isn't written when update returns false.
+func (a *mtpingAdapter) updateStatus(ctx context.Context, namespace, name string, update func(status *v1beta1.PingSourceStatus) bool) error {
+	sources := a.client.SourcesV1beta1().PingSources(namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		source, err := sources.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		source = source.DeepCopy()
+		if !update(&source.Status) {
+			return nil
+		}
+		_, err = sources.UpdateStatus(ctx, source, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// finish writes the terminal state of the given source, removed by the
+// runner, and forgets its schedule once written. The state is written again
+// on the next update of the source when it doesn't stick, the source not
+// being scheduled again meanwhile.
+func (a *mtpingAdapter) finish(ctx context.Context, namespace, name string, update func(status *v1beta1.PingSourceStatus)) error {
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	err := a.updateStatus(ctx, namespace, name, func(status *v1beta1.PingSourceStatus) bool {
+		update(status)
+		return true
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		a.entryidMu.Lock()
+		a.terminal[key] = update
+		a.entryidMu.Unlock()
+		return err
+	}
+
+	a.entryidMu.Lock()
+	delete(a.terminal, key)
+	a.entryidMu.Unlock()
+	a.forget(namespace, name)
+	return err
+}
+
+// complete marks the source, removed by the runner, as completed.
+func (a *mtpingAdapter) complete(ctx context.Context, namespace, name string) {
+	logger := a.logger.With(zap.String("namespace", namespace), zap.String("name", name))
+	if err := a.finish(ctx, namespace, name, (*v1beta1.PingSourceStatus).MarkCompleted); err != nil {
+		logger.Errorw("failed to mark the source as completed", zap.Error(err))
+		return
+	}
+	logger.Info("source completed")
+}
+
+// degrade marks the source as emitting events not matching its EventSchema.
+// Sources already marked are left unchanged, not to update them on each fire.
+func (a *mtpingAdapter) degrade(ctx context.Context, namespace, name string, invalid error) {
+	if err := a.updateStatus(ctx, namespace, name, func(status *v1beta1.PingSourceStatus) bool {
+		if status.AreEventsInvalid() {
+			return false
+		}
+		status.MarkEventsInvalid("EventSchemaMismatch", "Skipped an event not matching the event schema: %v", invalid)
+		return true
+	}); err != nil {
+		a.logger.Errorw("failed to mark the source as emitting invalid events",
+			zap.String("namespace", namespace), zap.String("name", name), zap.Error(err))
+	}
+}
+
+// degradeTemplates marks the source as skipping its fires or rendering them
+// empty for its templates referencing missing keys, and clears the mark when
+// missing is nil. The runner calls it on the changes only.
+func (a *mtpingAdapter) degradeTemplates(ctx context.Context, namespace, name string, missing error, skipped bool) {
+	if err := a.updateStatus(ctx, namespace, name, func(status *v1beta1.PingSourceStatus) bool {
+		switch {
+		case missing == nil:
+			if !status.AreTemplateKeysMissing() {
+				return false
+			}
+			status.ClearTemplateKeysMissing()
+		case skipped:
+			status.MarkTemplateKeysMissing("MissingTemplateKey", "Skipped a fire for a missing template key: %v", missing)
+		default:
+			status.MarkTemplateKeysMissing("MissingTemplateKey", "Rendered a missing template key empty: %v", missing)
+		}
+		return true
+	}); err != nil {
+		a.logger.Errorw("failed to mark the source as rendering missing template keys",
+			zap.String("namespace", namespace), zap.String("name", name), zap.Error(err))
+	}
+}
+
+// reportBatches marks the source as sending only part of the events of its
+// fires when failed isn't zero, and as sending all of them otherwise.
+func (a *mtpingAdapter) reportBatches(ctx context.Context, namespace, name string, sent, failed uint64) {
+	if err := a.updateStatus(ctx, namespace, name, func(status *v1beta1.PingSourceStatus) bool {
+		if failed > 0 {
+			status.MarkBatchPartiallyDelivered("PartialBatchFailure", "Sent %d of the %d events of a fire", sent, sent+failed)
+		} else {
+			status.MarkBatchesDelivered()
+		}
+		return true
+	}); err != nil {
+		a.logger.Errorw("failed to report the delivery of the batches of the source",
+			zap.String("namespace", namespace), zap.String("name", name), zap.Error(err))
+	}
+}
+
+// disable marks the source, removed by the runner for its job panicking, as
+// not ready. The source is scheduled again once its spec changes.
+func (a *mtpingAdapter) disable(ctx context.Context, namespace, name string, panics int, recovered interface{}) {
+	logger := a.logger.With(zap.String("namespace", namespace), zap.String("name", name))
+	if err := a.finish(ctx, namespace, name, func(status *v1beta1.PingSourceStatus) {
+		status.MarkJobPanicking("JobPanicking", "Disabled after its job panicked on %d consecutive fires: %v", panics, recovered)
+	}); err != nil {
+		logger.Errorw("failed to mark the disabled source as not ready", zap.Error(err))
+		return
+	}
+	logger.Warn("source disabled for its job panicking")
+}
+
+// reportLatency sets the send latency of the source in its status.
+func (a *mtpingAdapter) reportLatency(ctx context.Context, namespace, name string, latency *v1beta1.PingSourceSendLatency) {
+	if err := a.updateStatus(ctx, namespace, name, func(status *v1beta1.PingSourceStatus) bool {
+		status.SendLatency = latency
+		return true
+	}); err != nil {
+		a.logger.Errorw("failed to report the send latency of the source",
+			zap.String("namespace", namespace), zap.String("name", name), zap.Error(err))
+	}
+}
+
+// nodeRegion returns the region label of the given node, or the empty
+// string when it can't be determined.
+func nodeRegion(ctx context.Context, kubeClient kubernetes.Interface, nodeName string) string {
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		logging.FromContext(ctx).Warnw("failed to get the adapter node, no region extension will be set", zap.Error(err))
+		return ""
+	}
+	if region, ok := node.Labels[corev1.LabelZoneRegionStable]; ok {
+		return region
+	}
+	return node.Labels[corev1.LabelZoneRegion]
+}
+
 func GetNoShutDownAfterValue() int {
 	str := os.Getenv(EnvNoShutdownAfter)
 	if str != "" {
@@ -100,31 +589,150 @@ func (a *mtpingAdapter) Update(ctx context.Context, source *v1beta1.PingSource)
 	// Is the schedule already cached?
 	a.entryidMu.RLock()
 	id, ok := a.entryids[key]
+	applied, debounced := a.applied[key]
+	terminal, finishing := a.terminal[key]
 	a.entryidMu.RUnlock()
 
+	// Sources removed by the runner are not scheduled again until their
+	// terminal state is written.
+	if finishing {
+		if err := a.finish(ctx, source.Namespace, source.Name, terminal); err != nil {
+			logging.FromContext(ctx).Errorw("failed to write the terminal state of the source", zap.Error(err))
+		}
+		return
+	}
+
+	// Identical updates applied shortly after the schedule are ignored, not
+	// to disrupt it.
+	window := debounceWindow(source)
+	var hash string
+	if window > 0 {
+		hash = specHash(source)
+		if ok && debounced && applied.hash == hash && a.clock.Since(applied.at) < window {
+			logging.FromContext(ctx).Debug("Schedule unchanged, not rescheduling")
+			return
+		}
+	}
+
 	if ok {
-		a.runner.RemoveSchedule(id)
+		if err := a.runner.RemoveSchedule(id); err != nil {
+			logging.FromContext(ctx).Warnw("failed to remove previous schedule", zap.Error(err))
+		}
 	}
 
-	id = a.runner.AddSchedule(source)
+	// Sources that already sent their single event are not scheduled again.
+	if source.Spec.StopAfterFirstSuccess && source.Status.IsCompleted() {
+		a.removeParked(ctx, source)
+		a.entryidMu.Lock()
+		delete(a.entryids, key)
+		delete(a.applied, key)
+		a.entryidMu.Unlock()
+		return
+	}
+
+	var err error
+	if !ok && a.unpark(ctx, source) {
+		// Moved back with its counters, the schedule is updated in place.
+		id, err = a.runner.UpdateSchedule(source)
+	} else {
+		id, err = a.runner.AddSchedule(source)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Errorw("failed to add schedule", zap.Error(err))
+	}
 
 	a.entryidMu.Lock()
-	a.entryids[key] = id
+	if id > 0 {
+		a.entryids[key] = id
+	} else {
+		delete(a.entryids, key)
+	}
+	if id > 0 && window > 0 {
+		a.applied[key] = appliedSpec{hash: hash, at: a.clock.Now()}
+	} else {
+		delete(a.applied, key)
+	}
 	a.entryidMu.Unlock()
 }
 
 func (a *mtpingAdapter) Remove(ctx context.Context, source *v1beta1.PingSource) {
+	a.release(ctx, source)
+	// The events persisted for the deleted source are not sent anymore.
+	if err := a.runner.RemoveQueued(source.Namespace, source.Name); err != nil {
+		logging.FromContext(ctx).Warnw("failed to remove the persisted cloudevents of the source", zap.Error(err))
+	}
+}
+
+// release removes the schedule of source, keeping its persisted events for
+// the bucket of source to be claimed again.
+func (a *mtpingAdapter) release(ctx context.Context, source *v1beta1.PingSource) {
 	key := fmt.Sprintf("%s/%s", source.Namespace, source.Name)
+	a.removeParked(ctx, source)
 
 	a.entryidMu.RLock()
 	id, ok := a.entryids[key]
 	a.entryidMu.RUnlock()
 
 	if ok {
-		a.runner.RemoveSchedule(id)
+		if err := a.runner.RemoveSchedule(id); err != nil {
+			logging.FromContext(ctx).Warnw("failed to remove schedule", zap.Error(err))
+		}
 
 		a.entryidMu.Lock()
 		delete(a.entryids, key)
+		delete(a.applied, key)
 		a.entryidMu.Unlock()
 	}
 }
+
+// park moves the schedule of source to the parked runner, its bucket being
+// given back to another replica by the sharder. The schedule keeps its
+// counters there without firing, going on with them if the bucket is claimed
+// again.
+func (a *mtpingAdapter) park(ctx context.Context, source *v1beta1.PingSource) {
+	key := fmt.Sprintf("%s/%s", source.Namespace, source.Name)
+	a.entryidMu.Lock()
+	_, ok := a.entryids[key]
+	delete(a.entryids, key)
+	delete(a.applied, key)
+	a.entryidMu.Unlock()
+	if !ok {
+		return
+	}
+	if _, err := a.runner.MoveSchedule(key, a.parked); err != nil {
+		logging.FromContext(ctx).Warnw("failed to park the schedule of the given back source, removing it", zap.Error(err))
+		if err := a.runner.RemoveScheduleByKey(source.Namespace, source.Name); err != nil {
+			logging.FromContext(ctx).Warnw("failed to remove schedule", zap.Error(err))
+		}
+	}
+}
+
+// unpark moves the schedule of source back from the parked runner, returning
+// whether it was parked.
+func (a *mtpingAdapter) unpark(ctx context.Context, source *v1beta1.PingSource) bool {
+	if a.parked == nil {
+		return false
+	}
+	if _, parked := a.parked.GetSchedule(source.Namespace, source.Name); !parked {
+		return false
+	}
+	if _, err := a.parked.MoveSchedule(fmt.Sprintf("%s/%s", source.Namespace, source.Name), a.runner); err != nil {
+		logging.FromContext(ctx).Warnw("failed to move the parked schedule back, scheduling the source anew", zap.Error(err))
+		a.removeParked(ctx, source)
+		return false
+	}
+	return true
+}
+
+// removeParked removes the parked schedule of source, if any.
+func (a *mtpingAdapter) removeParked(ctx context.Context, source *v1beta1.PingSource) {
+	if a.parked == nil {
+		return
+	}
+	if _, parked := a.parked.GetSchedule(source.Namespace, source.Name); !parked {
+		return
+	}
+	if err := a.parked.RemoveScheduleByKey(source.Namespace, source.Name); err != nil {
+		logging.FromContext(ctx).Warnw("failed to remove the parked schedule", zap.Error(err))
+	}
+}
@@ -18,17 +18,30 @@ package mtping
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clientgotesting "k8s.io/client-go/testing"
 
 	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
 
 	"github.com/robfig/cron/v3"
 
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	_ "knative.dev/pkg/client/injection/kube/client/fake"
+
+	"knative.dev/eventing/pkg/client/clientset/versioned/fake"
+	eventingclient "knative.dev/eventing/pkg/client/injection/client"
+	_ "knative.dev/eventing/pkg/client/injection/client/fake"
 	"knative.dev/pkg/logging"
 	rectesting "knative.dev/pkg/reconciler/testing"
 
@@ -70,33 +83,472 @@ func TestUpdateRemoveAdapter(t *testing.T) {
 		entryids:  make(map[string]cron.EntryID),
 	}
 
-	adapter.Update(ctx, &sourcesv1beta1.PingSource{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-name",
-			Namespace: "test-ns",
-		},
-	})
+	adapter.Update(ctx, newTestSource())
 
 	if _, ok := adapter.entryids["test-ns/test-name"]; !ok {
 		t.Error(`Expected cron entries to contain "test-ns/test-name"`)
 	}
 
-	adapter.Remove(ctx, &sourcesv1beta1.PingSource{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-name",
-			Namespace: "test-ns",
-		},
+	adapter.Remove(ctx, newTestSource())
+	if _, ok := adapter.entryids["test-ns/test-name"]; ok {
+		t.Error(`Expected cron entries to not contain "test-ns/test-name"`)
+	}
+}
+
+func TestParkAdapter(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertest.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	parked := NewCronJobsRunner(adaptertest.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	adapter := mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    runner,
+		parked:    parked,
+		entryidMu: sync.RWMutex{},
+		entryids:  make(map[string]cron.EntryID),
+		applied:   make(map[string]appliedSpec),
+	}
+	source := newTestSource()
+
+	adapter.Update(ctx, source)
+	runner.cron.Entry(adapter.entryids["test-ns/test-name"]).Job.Run()
+	adapter.park(ctx, source)
+	if _, ok := runner.GetSchedule("test-ns", "test-name"); ok {
+		t.Error("Expected the parked source not to be scheduled by the runner")
+	}
+	if _, ok := parked.GetSchedule("test-ns", "test-name"); !ok {
+		t.Error("Expected the source to be parked")
+	}
+
+	// Claimed again, the source goes on with its counters.
+	adapter.Update(ctx, source)
+	if _, ok := parked.GetSchedule("test-ns", "test-name"); ok {
+		t.Error("Expected the source not to be parked any more")
+	}
+	entry, ok := runner.GetSchedule("test-ns", "test-name")
+	if !ok || adapter.entryids["test-ns/test-name"] != entry.ID {
+		t.Fatal("Expected the source to be scheduled by the runner again")
+	}
+	entry.Job.Run()
+	if got := runner.Stats().Sources["test-ns/test-name"].Sent; got != 2 {
+		t.Errorf("Expected the counters to go on across the parking, got %d events sent", got)
+	}
+
+	adapter.park(ctx, source)
+	adapter.Remove(ctx, source)
+	if _, ok := parked.GetSchedule("test-ns", "test-name"); ok {
+		t.Error("Expected the removed source not to be parked any more")
+	}
+}
+
+func TestCompleteAdapter(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	adapter := mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    &testRunner{},
+		client:    eventingclient.Get(ctx),
+		entryidMu: sync.RWMutex{},
+		entryids:  make(map[string]cron.EntryID),
+	}
+
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.StopAfterFirstSuccess = true
 	})
+	if _, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Create(ctx, source, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create source:", err)
+	}
+
+	adapter.Update(ctx, source)
+	adapter.complete(ctx, "test-ns", "test-name")
+
 	if _, ok := adapter.entryids["test-ns/test-name"]; ok {
 		t.Error(`Expected cron entries to not contain "test-ns/test-name"`)
 	}
+	got, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if !got.Status.IsCompleted() {
+		t.Error("Expected the source to be marked completed")
+	}
+
+	// A completed source is not scheduled again.
+	adapter.Update(ctx, got)
+	if _, ok := adapter.entryids["test-ns/test-name"]; ok {
+		t.Error(`Expected completed source not to be scheduled`)
+	}
+}
+
+func TestCompleteAdapterConflict(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	client := fake.NewSimpleClientset(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.StopAfterFirstSuccess = true
+	}))
+	adapter := mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    &testRunner{},
+		client:    client,
+		entryidMu: sync.RWMutex{},
+		entryids:  map[string]cron.EntryID{"test-ns/test-name": 1},
+		terminal:  make(map[string]func(status *sourcesv1beta1.PingSourceStatus)),
+	}
+
+	// The first write conflicts and is retried, the next ones fail until the
+	// source is updated.
+	var writes int
+	failing := true
+	client.PrependReactor("update", "pingsources", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" {
+			return false, nil, nil
+		}
+		writes++
+		if writes == 1 {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Group: "sources.knative.dev", Resource: "pingsources"}, "test-name", errors.New("modified"))
+		}
+		if failing {
+			return true, nil, errors.New("unavailable")
+		}
+		return false, nil, nil
+	})
+
+	adapter.complete(ctx, "test-ns", "test-name")
+	if writes != 2 {
+		t.Errorf("Expected the conflicting write to be retried once, got %d writes", writes)
+	}
+	if _, ok := adapter.entryids["test-ns/test-name"]; !ok {
+		t.Error("Expected the schedule to be kept until the source is marked completed")
+	}
+
+	failing = false
+	source, err := client.SourcesV1beta1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	adapter.Update(ctx, source)
+	if _, ok := adapter.entryids["test-ns/test-name"]; ok {
+		t.Error("Expected the schedule to be forgotten once the source is marked completed")
+	}
+	if _, ok := adapter.terminal["test-ns/test-name"]; ok {
+		t.Error("Expected the terminal state to be forgotten once written")
+	}
+	if source, err = client.SourcesV1beta1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{}); err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if !source.Status.IsCompleted() {
+		t.Error("Expected the source to be marked completed on its next update")
+	}
+}
+
+func TestDegradeAdapter(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	adapter := mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    &testRunner{},
+		client:    eventingclient.Get(ctx),
+		entryidMu: sync.RWMutex{},
+		entryids:  make(map[string]cron.EntryID),
+	}
+
+	source := newTestSource()
+	if _, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Create(ctx, source, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create source:", err)
+	}
+
+	adapter.degrade(ctx, "test-ns", "test-name", errors.New("/count: string is not of type [integer]"))
+
+	got, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if !got.Status.AreEventsInvalid() {
+		t.Error("Expected the source to be marked as emitting invalid events")
+	}
+}
+
+func TestDegradeTemplatesAdapter(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	adapter := mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    &testRunner{},
+		client:    eventingclient.Get(ctx),
+		entryidMu: sync.RWMutex{},
+		entryids:  make(map[string]cron.EntryID),
+	}
+
+	source := newTestSource()
+	if _, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Create(ctx, source, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create source:", err)
+	}
+
+	adapter.degradeTemplates(ctx, "test-ns", "test-name", errors.New(`map has no entry for key "team"`), true)
+
+	got, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if !got.Status.AreTemplateKeysMissing() {
+		t.Error("Expected the source to be marked as referencing missing template keys")
+	}
+	if c := got.Status.GetCondition(sourcesv1beta1.PingSourceConditionTemplateKeysResolved); !strings.HasPrefix(c.Message, "Skipped a fire") {
+		t.Errorf("Expected the condition to tell the fire was skipped, got %q", c.Message)
+	}
+
+	adapter.degradeTemplates(ctx, "test-ns", "test-name", nil, false)
+	if got, err = adapter.client.SourcesV1beta1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{}); err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if got.Status.AreTemplateKeysMissing() {
+		t.Error("Expected the missing template keys to be cleared")
+	}
+}
+
+func TestReportBatchesAdapter(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	adapter := mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    &testRunner{},
+		client:    eventingclient.Get(ctx),
+		entryidMu: sync.RWMutex{},
+		entryids:  make(map[string]cron.EntryID),
+	}
+
+	source := newTestSource()
+	sources := adapter.client.SourcesV1beta1().PingSources("test-ns")
+	if _, err := sources.Create(ctx, source, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create source:", err)
+	}
+
+	adapter.reportBatches(ctx, "test-ns", "test-name", 2, 1)
+	got, err := sources.Get(ctx, "test-name", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if !got.Status.IsBatchPartiallyDelivered() {
+		t.Error("Expected the source to be marked as partially delivering its batches")
+	}
+	if c := got.Status.GetCondition(sourcesv1beta1.PingSourceConditionBatchesDelivered); c.Message != "Sent 2 of the 3 events of a fire" {
+		t.Errorf("Expected the counts in the condition message, got %q", c.Message)
+	}
+
+	adapter.reportBatches(ctx, "test-ns", "test-name", 3, 0)
+	if got, err = sources.Get(ctx, "test-name", metav1.GetOptions{}); err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if got.Status.IsBatchPartiallyDelivered() {
+		t.Error("Expected the source to be marked as delivering its batches")
+	}
+}
+
+func TestDisablePanickingSource(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	adapter := mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    &testRunner{},
+		client:    eventingclient.Get(ctx),
+		entryidMu: sync.RWMutex{},
+		entryids:  map[string]cron.EntryID{"test-ns/test-name": 1},
+		applied:   make(map[string]appliedSpec),
+	}
+
+	source := newTestSource()
+	if _, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Create(ctx, source, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create source:", err)
+	}
+
+	adapter.disable(ctx, "test-ns", "test-name", 5, "boom")
+
+	if _, ok := adapter.entryids["test-ns/test-name"]; ok {
+		t.Error("Expected the schedule of the disabled source to be forgotten")
+	}
+	got, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if !got.Status.IsJobPanicking() || got.Status.IsReady() {
+		t.Error("Expected the source to be marked as not ready for its job panicking")
+	}
+	if c := got.Status.GetTopLevelCondition(); c == nil || c.Reason != "JobPanicking" {
+		t.Errorf("Expected the Ready condition reason to be JobPanicking, got %+v", c)
+	}
+}
+
+func TestReportLatency(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	adapter := mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    &testRunner{},
+		client:    eventingclient.Get(ctx),
+		entryidMu: sync.RWMutex{},
+		entryids:  make(map[string]cron.EntryID),
+	}
+
+	source := newTestSource()
+	if _, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Create(ctx, source, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create source:", err)
+	}
+
+	latency := &sourcesv1beta1.PingSourceSendLatency{
+		Samples:           4,
+		Average:           metav1.Duration{Duration: 25 * time.Millisecond},
+		Percentile:        99,
+		PercentileLatency: metav1.Duration{Duration: 40 * time.Millisecond},
+	}
+	adapter.reportLatency(ctx, "test-ns", "test-name", latency)
+
+	got, err := adapter.client.SourcesV1beta1().PingSources("test-ns").Get(ctx, "test-name", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get source:", err)
+	}
+	if got.Status.SendLatency == nil || *got.Status.SendLatency != *latency {
+		t.Errorf("Expected send latency %+v, got %+v", latency, got.Status.SendLatency)
+	}
+}
+
+func TestQuiesceOnTermination(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertest.NewTestClient()
+	blocking := &blockingClient{Client: ce, release: make(chan struct{}), sending: make(chan struct{}, 1)}
+	runner := NewCronJobsRunner(blocking, kubeclient.Get(ctx), logging.FromContext(ctx), WithDrainTimeout(10*time.Second))
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryID).Job
+
+	adapter := &mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    runner,
+		quiesce:   true,
+		entryidMu: sync.RWMutex{},
+		entryids:  make(map[string]cron.EntryID),
+	}
+
+	terminating := make(chan struct{})
+	ctx, cancel := context.WithCancel(WithTerminationSignal(ctx, terminating))
+	stopped := make(chan struct{})
+	go func() {
+		if err := adapter.Start(ctx); err != nil {
+			t.Error("Unexpected error:", err)
+		}
+		close(stopped)
+	}()
+
+	go job.Run()
+	waitSignal(t, blocking.sending, "the fire to be in flight")
+
+	// Simulate SIGTERM.
+	close(terminating)
+	waitSignal(t, runner.pausedCh, "the runner to be paused")
+
+	// Fires after termination are dropped.
+	job.Run()
+
+	// The delayed cancellation of the adapter context.
+	cancel()
+	close(blocking.release)
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected adapter to be stopped after 5 seconds")
+	case <-stopped:
+	}
+
+	if got := len(ce.Sent()); got != 1 {
+		t.Error("Expected only the in-flight event to be sent, got", got)
+	}
+}
+
+func TestDebounceIdenticalUpdates(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertest.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	fakeClock := clock.NewFakeClock(time.Now())
+	adapter := &mtpingAdapter{
+		logger:    logging.FromContext(ctx),
+		runner:    runner,
+		entryidMu: sync.RWMutex{},
+		entryids:  make(map[string]cron.EntryID),
+		applied:   make(map[string]appliedSpec),
+		clock:     fakeClock,
+	}
+
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{DebounceAnnotation: "5m"}
+	})
+	scheduled := func() cron.EntryID {
+		t.Helper()
+		if got := len(runner.cron.Entries()); got != 1 {
+			t.Fatalf("Expected 1 cron entry, got %d", got)
+		}
+		return adapter.entryids["test-ns/test-name"]
+	}
+
+	adapter.Update(ctx, source)
+	id := scheduled()
+
+	// Reapplying the same spec doesn't disrupt the schedule.
+	for i := 0; i < 3; i++ {
+		fakeClock.Step(time.Minute)
+		adapter.Update(ctx, source.DeepCopy())
+		if got := scheduled(); got != id {
+			t.Fatalf("Expected identical update %d to keep entry %d, got %d", i+1, id, got)
+		}
+	}
+
+	// Changes are applied right away.
+	changed := source.DeepCopy()
+	changed.Spec.JsonData = "other data"
+	adapter.Update(ctx, changed)
+	if got := scheduled(); got == id {
+		t.Fatal("Expected a changed spec to be rescheduled")
+	}
+	id = scheduled()
+
+	// Identical updates reschedule once the window elapsed.
+	fakeClock.Step(5 * time.Minute)
+	adapter.Update(ctx, changed.DeepCopy())
+	if got := scheduled(); got == id {
+		t.Error("Expected an identical update to be rescheduled after the debounce window")
+	}
+
+	// Without the annotation, all updates reschedule.
+	undebounced := changed.DeepCopy()
+	undebounced.Annotations = nil
+	adapter.Update(ctx, undebounced)
+	id = scheduled()
+	adapter.Update(ctx, undebounced.DeepCopy())
+	if got := scheduled(); got == id {
+		t.Error("Expected updates to be rescheduled without the debounce annotation")
+	}
+}
+
+func TestNodeRegion(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	kubeClient := kubeclient.Get(ctx)
+
+	_, err := kubeClient.CoreV1().Nodes().Create(ctx, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-node",
+			Labels: map[string]string{corev1.LabelZoneRegionStable: "us-east-1"},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal("Failed to create node:", err)
+	}
+
+	if got := nodeRegion(ctx, kubeClient, "test-node"); got != "us-east-1" {
+		t.Errorf("Expected region us-east-1, got %q", got)
+	}
+	if got := nodeRegion(ctx, kubeClient, "missing-node"); got != "" {
+		t.Errorf("Expected no region for a missing node, got %q", got)
+	}
 }
 
 type testRunner struct {
 	CronJobRunner
 }
 
-func (*testRunner) AddSchedule(*sourcesv1beta1.PingSource) cron.EntryID {
-	return cron.EntryID(1)
+func (*testRunner) AddSchedule(*sourcesv1beta1.PingSource) (cron.EntryID, error) {
+	return cron.EntryID(1), nil
+}
+func (*testRunner) RemoveSchedule(cron.EntryID) error {
+	return nil
+}
+func (*testRunner) RemoveQueued(string, string) error {
+	return nil
 }
-func (*testRunner) RemoveSchedule(cron.EntryID) {}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// alert is the data of the alert events.
+type alert struct {
+	// EventID is the ID of the event that failed to be sent.
+	EventID string `json:"eventId"`
+	// Sink is where the event failed to be sent.
+	Sink string `json:"sink"`
+	// Time is when the fire failed.
+	Time time.Time `json:"time"`
+}
+
+// recordFailure marks the source as failing. It sends an alert to the alert
+// sink of the source when it wasn't failing already, telling the fire of
+// event failed.
+func (a *cronJobsRunner) recordFailure(ctx context.Context, opts jobOptions, event cloudevents.Event) {
+	if atomic.SwapInt32(&opts.stats.failing, 1) == 1 || opts.alertSink == "" {
+		return
+	}
+
+	data := alert{EventID: event.ID(), Time: a.clock.Now()}
+	if target := cecontext.TargetFrom(ctx); target != nil {
+		data.Sink = target.String()
+	}
+	alert := cloudevents.NewEvent()
+	alert.SetID(uuid.New().String())
+	alert.SetType(sourcesv1beta1.PingSourceAlertEventType)
+	alert.SetSource(event.Source())
+	alert.SetTime(data.Time)
+	if err := alert.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		a.Logger.Errorw("failed to encode alert", zap.String("source", event.Source()), zap.Error(err))
+		return
+	}
+
+	// Like the shadow copies, the alerts are sent once, without the
+	// context of the source.
+	alertCtx := cloudevents.ContextWithTarget(context.Background(), opts.alertSink)
+	if result := a.send(alertCtx, opts.client, alert); !cloudevents.IsACK(result) {
+		a.Logger.Warnw("failed to send alert to the alert sink", zap.Any("result", result),
+			zap.String("source", event.Source()), zap.String("id", event.ID()))
+	}
+}
+
+// recordSuccess marks the source as sending its events, for its next
+// failure to be alerted.
+func (a *cronJobsRunner) recordSuccess(opts jobOptions) {
+	atomic.StoreInt32(&opts.stats.failing, 0)
+}
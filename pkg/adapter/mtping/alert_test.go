@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// alertingClient records the events sent to alertSink, and fails the other
+// sends while failing is set.
+type alertingClient struct {
+	cloudevents.Client
+
+	alertSink string
+
+	mu      sync.Mutex
+	failing bool
+	alerts  []cloudevents.Event
+}
+
+func (c *alertingClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cecontext.TargetFrom(ctx).String() == c.alertSink {
+		c.alerts = append(c.alerts, event)
+		return protocol.ResultACK
+	}
+	if c.failing {
+		return cehttp.NewResult(400, "%w", protocol.ResultNACK)
+	}
+	return c.Client.Send(ctx, event)
+}
+
+func (c *alertingClient) setFailing(failing bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failing = failing
+}
+
+func (c *alertingClient) Alerts() []cloudevents.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]cloudevents.Event(nil), c.alerts...)
+}
+
+func TestAlertOnFailure(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	alertURI, _ := apis.ParseURL("http://alerts.example.com")
+	client := &alertingClient{Client: adaptertesting.NewTestClient(), alertSink: alertURI.String()}
+	runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("mysink")
+		s.Status.AlertSinkURI = alertURI
+	}))
+	fire := runner.cron.Entry(entryID).Job.Run
+
+	fire()
+	if got := len(client.Alerts()); got != 0 {
+		t.Fatal("Expected no alert while the source is healthy, got", got)
+	}
+
+	client.setFailing(true)
+	for i := 0; i < 3; i++ {
+		fire()
+	}
+	alerts := client.Alerts()
+	if len(alerts) != 1 {
+		t.Fatal("Expected exactly 1 alert on the transition to failing, got", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.Type() != sourcesv1beta1.PingSourceAlertEventType {
+		t.Errorf("Expected the alert type %q, got %q", sourcesv1beta1.PingSourceAlertEventType, alert.Type())
+	}
+	if want := sourcesv1beta1.PingSourceSource("test-ns", "test-name"); alert.Source() != want {
+		t.Errorf("Expected the alert source %q, got %q", want, alert.Source())
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(alert.Data(), &data); err != nil {
+		t.Fatal("Failed to decode the alert data:", err)
+	}
+	if data["sink"] != apis.HTTP("mysink").String() || data["eventId"] == "" {
+		t.Error("Expected the alert to tell the failed event and its sink, got", data)
+	}
+
+	// Recovering makes the next failure alerted again.
+	client.setFailing(false)
+	fire()
+	client.setFailing(true)
+	fire()
+	fire()
+	if got := len(client.Alerts()); got != 2 {
+		t.Error("Expected 2 alerts once failing again, got", got)
+	}
+}
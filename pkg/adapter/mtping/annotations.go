@@ -0,0 +1,204 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"strconv"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+const (
+	// VerboseLoggingAnnotation enables logging of the HTTP requests sent to
+	// the sink of a PingSource, and of the responses received.
+	VerboseLoggingAnnotation = "pingsource.knative.dev/verbose-logging"
+
+	// LogSamplingAnnotation is N when only one fire out of N of a
+	// PingSource is logged. Failures are not sampled, but at most one
+	// failure per minute is logged.
+	LogSamplingAnnotation = "pingsource.knative.dev/log-sampling"
+
+	// RetryAfterJitterAnnotation is the fraction, between 0 and 1, by which
+	// the delay advertised by the Retry-After header of the sink responses
+	// is randomly spread before retrying. Setting it makes the runner honor
+	// Retry-After for this PingSource.
+	RetryAfterJitterAnnotation = "pingsource.knative.dev/retry-after-jitter"
+
+	// ProxyURLAnnotation is the URL of the HTTP proxy the events of a
+	// PingSource are sent through.
+	ProxyURLAnnotation = "pingsource.knative.dev/proxy-url"
+
+	// TLSServerNameAnnotation is the server name used to verify the
+	// certificate of the sink of a PingSource, when different from the
+	// sink host.
+	TLSServerNameAnnotation = "pingsource.knative.dev/tls-server-name"
+
+	// H2CAnnotation enables sending the events of a PingSource over HTTP/2
+	// cleartext with prior knowledge, for the sinks only speaking h2c. The
+	// sink must be an http URL. Can't be combined with ProxyURLAnnotation.
+	H2CAnnotation = "pingsource.knative.dev/h2c"
+
+	// LoadBalancingAnnotation is the LoadBalancingPolicy spreading the events
+	// of a PingSource over the IPv4 addresses its sink host resolves to.
+	// Disabled when missing. Can't be combined with H2CAnnotation or
+	// ProxyURLAnnotation.
+	LoadBalancingAnnotation = "pingsource.knative.dev/load-balancing"
+
+	// WarmupAnnotation enables opening a connection to the sink of a
+	// PingSource when it is scheduled, so that the first fire doesn't wait
+	// for the connection to be established.
+	WarmupAnnotation = "pingsource.knative.dev/warmup"
+
+	// RetryOnResetAnnotation enables retrying, once and on a new
+	// connection, the sends of a PingSource whose connection the sink
+	// resets or closes before responding. The request body is replayed.
+	RetryOnResetAnnotation = "pingsource.knative.dev/retry-on-reset"
+
+	// PreciseTimingAnnotation, set to true, keeps the fires of a PingSource
+	// on schedule when the runner spreads the fires with a jitter window.
+	PreciseTimingAnnotation = "pingsource.knative.dev/precise-timing"
+
+	// MaxEventSizeAnnotation is the maximum size, in bytes, of the event data
+	// accepted by the sink of a PingSource.
+	MaxEventSizeAnnotation = "pingsource.knative.dev/max-event-size"
+
+	// OversizePolicyAnnotation is the OversizePolicy applied to the events
+	// of a PingSource exceeding MaxEventSizeAnnotation. Defaults to skip.
+	OversizePolicyAnnotation = "pingsource.knative.dev/oversize-policy"
+
+	// SecondOffsetsAnnotation is a comma-separated list of the seconds, from
+	// 0 to 59, at which a PingSource fires within each minute matched by its
+	// schedule, e.g. "5,35". Defaults to the start of the minute.
+	SecondOffsetsAnnotation = "pingsource.knative.dev/second-offsets"
+
+	// SummaryScheduleAnnotation is the cron schedule of the summary events
+	// of a PingSource, reporting its fires since the previous summary.
+	// Disabled when missing.
+	SummaryScheduleAnnotation = "pingsource.knative.dev/summary-schedule"
+
+	// OverlapPolicyAnnotation is the OverlapPolicy applied when several
+	// schedules of a PingSource fire at the same time. Defaults to coalesce.
+	OverlapPolicyAnnotation = "pingsource.knative.dev/overlap-policy"
+
+	// ConcurrencyPolicyAnnotation is the ConcurrencyPolicy applied when a
+	// PingSource fires while its previous fire is still sending, such as
+	// with a sink slower than the schedule. Defaults to allow.
+	ConcurrencyPolicyAnnotation = "pingsource.knative.dev/concurrency-policy"
+
+	// EmissionRateAnnotation caps the number of events per second a
+	// PingSource sends, the sends over it waiting their turn. Bursts of up
+	// to EmissionBurstAnnotation events, 1 by default, are sent at once.
+	// Disabled when missing.
+	EmissionRateAnnotation  = "pingsource.knative.dev/emission-rate"
+	EmissionBurstAnnotation = "pingsource.knative.dev/emission-burst"
+
+	// DebounceAnnotation is the duration, e.g. "5m", during which the
+	// updates of a PingSource leaving its spec, annotations and sink
+	// unchanged don't reschedule it. Disabled when missing.
+	DebounceAnnotation = "pingsource.knative.dev/debounce"
+
+	// TimeSourceAnnotation overrides the time attribute of the events of a
+	// PingSource, for testing and simulation: an RFC 3339 timestamp, e.g.
+	// "1970-01-01T00:00:00Z", sets it on all the events, and a duration,
+	// e.g. "-24h", shifts the time of the fires. The events are sent at
+	// the time of the fires regardless.
+	TimeSourceAnnotation = "pingsource.knative.dev/time-source"
+
+	// ExtensionAnnotationPrefix prefixes the annotations of a PingSource
+	// setting an extension of its events, named after the rest of the
+	// annotation key: ce-ext.pingsource.knative.dev/team sets the team
+	// extension. The CloudEventOverrides extensions take precedence.
+	ExtensionAnnotationPrefix = "ce-ext.pingsource.knative.dev/"
+)
+
+// OversizePolicy tells what to do with the events exceeding the size limit
+// of the sink.
+type OversizePolicy string
+
+const (
+	// OversizeSkip drops oversized events.
+	OversizeSkip OversizePolicy = "skip"
+
+	// OversizeSplit sends the data of oversized events in chunks, as events
+	// carrying the chunkid, chunkindex and chunkcount extensions.
+	OversizeSplit OversizePolicy = "split"
+)
+
+// OverlapPolicy tells what to send when several schedules of a source fire
+// at the same time.
+type OverlapPolicy string
+
+const (
+	// OverlapCoalesce sends a single event.
+	OverlapCoalesce OverlapPolicy = "coalesce"
+
+	// OverlapSendAll sends an event per schedule, in the order of the
+	// schedules, Schedule first. The events carry the index of their
+	// schedule in the scheduleindex extension, 0 being Schedule.
+	OverlapSendAll OverlapPolicy = "send-all"
+)
+
+// ConcurrencyPolicy tells what to do when a source fires while its previous
+// fire is still running.
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyAllow runs the fires concurrently.
+	ConcurrencyAllow ConcurrencyPolicy = "allow"
+
+	// ConcurrencySkip skips the fires while the previous one is running.
+	ConcurrencySkip ConcurrencyPolicy = "skip"
+)
+
+// boolAnnotation returns the boolean value of the given annotation, or false
+// when the annotation is missing or is not a valid boolean.
+func boolAnnotation(source *sourcesv1beta1.PingSource, key string) bool {
+	value, ok := source.Annotations[key]
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	return err == nil && b
+}
+
+// intAnnotation returns the integer value of the given annotation, or def
+// when the annotation is missing or is not a valid non-negative integer.
+func intAnnotation(source *sourcesv1beta1.PingSource, key string, def int) int {
+	value, ok := source.Annotations[key]
+	if !ok {
+		return def
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return def
+	}
+	return i
+}
+
+// floatAnnotation returns the value of the given annotation as a float, and
+// whether the annotation was set to a valid non-negative float.
+func floatAnnotation(source *sourcesv1beta1.PingSource, key string) (float64, bool) {
+	value, ok := source.Annotations[key]
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		return 0, false
+	}
+	return f, true
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// basicAuth holds the credentials of a sink.
+type basicAuth struct {
+	username string
+	password string
+}
+
+type basicAuthKey struct{}
+
+// withBasicAuth returns a context carrying the credentials of the sink the
+// requests made with it are sent to.
+func withBasicAuth(ctx context.Context, auth basicAuth) context.Context {
+	return context.WithValue(ctx, basicAuthKey{}, auth)
+}
+
+// readBasicAuth reads the credentials referenced by the given source.
+func readBasicAuth(ctx context.Context, kubeClient kubernetes.Interface, source *sourcesv1beta1.PingSource) (basicAuth, error) {
+	name := source.Spec.BasicAuth.SecretName
+	secret, err := kubeClient.CoreV1().Secrets(source.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return basicAuth{}, err
+	}
+	username, ok := secret.Data[corev1.BasicAuthUsernameKey]
+	if !ok {
+		return basicAuth{}, fmt.Errorf("secret %s/%s has no %s key", source.Namespace, name, corev1.BasicAuthUsernameKey)
+	}
+	return basicAuth{
+		username: string(username),
+		password: string(secret.Data[corev1.BasicAuthPasswordKey]),
+	}, nil
+}
+
+// basicAuthRoundTripper sets the Authorization header of the requests whose
+// context carries credentials.
+type basicAuthRoundTripper struct {
+	next nethttp.RoundTripper
+}
+
+var _ nethttp.RoundTripper = (*basicAuthRoundTripper)(nil)
+
+func (t *basicAuthRoundTripper) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	if auth, ok := req.Context().Value(basicAuthKey{}).(basicAuth); ok {
+		// Round trippers must not modify the given request.
+		req = req.Clone(req.Context())
+		req.SetBasicAuth(auth.username, auth.password)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// basicAuthContext returns ctx carrying the sink credentials of source, when
+// it has some.
+func (a *cronJobsRunner) basicAuthContext(ctx context.Context, source *sourcesv1beta1.PingSource) (context.Context, error) {
+	if source.Spec.BasicAuth == nil {
+		return ctx, nil
+	}
+	auth, err := readBasicAuth(ctx, a.kubeClient, source)
+	if err != nil {
+		return nil, newScheduleError(source, ReasonReadFailed, fmt.Errorf("failed to read the sink credentials: %w", err))
+	}
+	return withBasicAuth(ctx, auth), nil
+}
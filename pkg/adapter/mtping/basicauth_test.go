@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestBasicAuth(t *testing.T) {
+	var mu sync.Mutex
+	var credentials [][2]string
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			w.WriteHeader(nethttp.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		credentials = append(credentials, [2]string{username, password})
+		mu.Unlock()
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	kubeClient := kubeclient.Get(ctx)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sink-credentials",
+			Namespace: "test-ns",
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("user"),
+			corev1.BasicAuthPasswordKey: []byte("first"),
+		},
+	}
+	if _, err := kubeClient.CoreV1().Secrets("test-ns").Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create secret:", err)
+	}
+
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeClient, logging.FromContext(ctx))
+	sinkURI, _ := apis.ParseURL(server.URL)
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+		s.Spec.BasicAuth = &sourcesv1beta1.PingSourceBasicAuth{SecretName: "sink-credentials"}
+	})
+	entryID := mustAddSchedule(t, runner, source)
+	runner.cron.Entry(entryID).Job.Run()
+
+	// Rotate the password, then reconcile.
+	secret.Data[corev1.BasicAuthPasswordKey] = []byte("second")
+	if _, err := kubeClient.CoreV1().Secrets("test-ns").Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Failed to update secret:", err)
+	}
+	if err := runner.RemoveSchedule(entryID); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	entryID = mustAddSchedule(t, runner, source)
+	runner.cron.Entry(entryID).Job.Run()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := [][2]string{{"user", "first"}, {"user", "second"}}
+	if len(credentials) != len(want) {
+		t.Fatalf("Expected %d authenticated requests, got %d", len(want), len(credentials))
+	}
+	for i := range want {
+		if credentials[i] != want[i] {
+			t.Errorf("Expected request %d credentials %v, got %v", i, want[i], credentials[i])
+		}
+	}
+}
+
+func TestBasicAuthMissingSecret(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	id, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("sink.example.com")
+		s.Spec.BasicAuth = &sourcesv1beta1.PingSourceBasicAuth{SecretName: "missing"}
+	}))
+	if err == nil {
+		t.Error("Expected the source not to be scheduled without its credentials, got", id)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// fireCounts counts the events of a fire that were sent and failed, the
+// chunks of a split event counting as one each.
+type fireCounts struct {
+	sent, failed uint64
+}
+
+// batchReport is the report of a source starting or ending to partially
+// fail its fires.
+type batchReport struct {
+	namespace, name string
+	sent, failed    uint64
+}
+
+// batchReports hands the reports to the partial handler in the background,
+// for the fires not to wait for the status writes. The reports of a source
+// not handled yet are coalesced to the last one.
+type batchReports struct {
+	mu sync.Mutex
+	// pending holds the reports not handled yet, keyed by namespace/name.
+	pending map[string]batchReport
+	// ready is signaled when pending isn't empty.
+	ready chan struct{}
+}
+
+func newBatchReports() *batchReports {
+	return &batchReports{
+		pending: make(map[string]batchReport),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// add adds report to the pending ones, without blocking.
+func (r *batchReports) add(report batchReport) {
+	r.mu.Lock()
+	r.pending[report.namespace+"/"+report.name] = report
+	r.mu.Unlock()
+	select {
+	case r.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the pending reports, removing them.
+func (r *batchReports) take() map[string]batchReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending := r.pending
+	r.pending = make(map[string]batchReport)
+	return pending
+}
+
+// run hands the pending reports to handle until stopCh is closed, the
+// reports pending then being handled last.
+func (r *batchReports) run(stopCh <-chan struct{}, handle func(namespace, name string, sent, failed uint64)) {
+	for {
+		select {
+		case <-r.ready:
+		case <-stopCh:
+			for _, report := range r.take() {
+				handle(report.namespace, report.name, report.sent, report.failed)
+			}
+			return
+		}
+		for _, report := range r.take() {
+			handle(report.namespace, report.name, report.sent, report.failed)
+		}
+	}
+}
+
+// recordFire adds the counts of a fire sending events to the counters of
+// the source. The sources starting or ending to partially fail their fires
+// are reported to the partial handler, in the background.
+func (a *cronJobsRunner) recordFire(opts jobOptions, counts *fireCounts) {
+	if counts.sent == 0 && counts.failed == 0 {
+		return
+	}
+	atomic.AddUint64(&opts.stats.sent, counts.sent)
+	atomic.AddUint64(&opts.stats.failed, counts.failed)
+	opts.stats.lastFire.Store(counts)
+
+	if a.batchReports == nil {
+		return
+	}
+	if counts.sent > 0 && counts.failed > 0 {
+		if atomic.SwapInt32(&opts.stats.partial, 1) == 0 {
+			a.batchReports.add(batchReport{namespace: opts.namespace, name: opts.name, sent: counts.sent, failed: counts.failed})
+		}
+	} else if counts.failed == 0 && atomic.SwapInt32(&opts.stats.partial, 0) == 1 {
+		a.batchReports.add(batchReport{namespace: opts.namespace, name: opts.name, sent: counts.sent})
+	}
+}
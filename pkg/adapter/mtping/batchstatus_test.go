@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+type partialReport struct {
+	sent, failed uint64
+}
+
+func TestPartiallyFailedBatch(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	sendErr := errors.New("sink unavailable")
+	// The first fire fails the middle one of its 3 chunks.
+	ce := adaptertesting.NewTestClientWithResults(nil, sendErr, nil)
+	reports := make(chan partialReport, 2)
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx),
+		WithPartialFailureHandler(func(namespace, name string, sent, failed uint64) {
+			reports <- partialReport{sent: sent, failed: failed}
+		}))
+	defer runner.PauseAll()
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.JsonData = "some data exceeding the sink limit"
+		s.Annotations = map[string]string{
+			MaxEventSizeAnnotation:   strconv.Itoa(sinkMaxSize),
+			OversizePolicyAnnotation: string(OversizeSplit),
+		}
+	}))
+	job := runner.cron.Entry(entryID).Job
+
+	job.Run()
+	stats := runner.Stats().Sources["test-ns/test-name"]
+	if stats.Sent != 2 || stats.Failed != 1 || stats.LastFireSent != 2 || stats.LastFireFailed != 1 {
+		t.Errorf("Expected 2 chunks sent and 1 failed, got %+v", stats)
+	}
+	if got := receiveReport(t, reports); got != (partialReport{sent: 2, failed: 1}) {
+		t.Fatalf("Expected the partially failed fire to be reported, got %+v", got)
+	}
+
+	job.Run()
+	stats = runner.Stats().Sources["test-ns/test-name"]
+	if stats.Sent != 5 || stats.Failed != 1 || stats.LastFireSent != 3 || stats.LastFireFailed != 0 {
+		t.Errorf("Expected 5 chunks sent and 1 failed, the last fire sending 3, got %+v", stats)
+	}
+	if got := receiveReport(t, reports); got != (partialReport{sent: 3}) {
+		t.Fatalf("Expected the fully sent fire to be reported, got %+v", got)
+	}
+
+	// Reported only when the fires start or end partially failing.
+	job.Run()
+	if pending := runner.batchReports.take(); len(pending) != 0 {
+		t.Errorf("Expected no report of a fire sent like the previous one, got %+v", pending)
+	}
+}
+
+// receiveReport returns the next report handed to the partial handler.
+func receiveReport(t *testing.T, reports <-chan partialReport) partialReport {
+	t.Helper()
+	select {
+	case report := <-reports:
+		return report
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the report")
+		return partialReport{}
+	}
+}
+
+func TestBatchReportsCoalesce(t *testing.T) {
+	r := newBatchReports()
+	r.add(batchReport{namespace: "test-ns", name: "test-name", sent: 2, failed: 1})
+	r.add(batchReport{namespace: "test-ns", name: "test-name", sent: 3})
+	r.add(batchReport{namespace: "test-ns", name: "other", sent: 1, failed: 1})
+
+	stopCh := make(chan struct{})
+	close(stopCh)
+	handled := make(map[string]partialReport)
+	r.run(stopCh, func(namespace, name string, sent, failed uint64) {
+		handled[namespace+"/"+name] = partialReport{sent: sent, failed: failed}
+	})
+	want := map[string]partialReport{
+		"test-ns/test-name": {sent: 3},
+		"test-ns/other":     {sent: 1, failed: 1},
+	}
+	if !reflect.DeepEqual(handled, want) {
+		t.Errorf("Expected the last report of each source to be handled on stop, got %+v", handled)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"sync/atomic"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+)
+
+// acquire reserves a slot in the goroutine budget. It returns false when
+// the budget is exhausted.
+func (a *cronJobsRunner) acquire() bool {
+	if a.goroutines == nil {
+		return true
+	}
+	select {
+	case a.goroutines <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *cronJobsRunner) release() {
+	if a.goroutines != nil {
+		<-a.goroutines
+	}
+}
+
+// budgetTick returns job shedding the fires exceeding the goroutine budget.
+// Cron starts the goroutine of a fire before running its job, this being the
+// outermost wrapper of the job for a shed fire to return right away, before
+// running the concurrency policy, the panic recovery or any other part of
+// the job.
+func (a *cronJobsRunner) budgetTick(ctx context.Context, event cloudevents.Event, opts jobOptions, job func()) func() {
+	return func() {
+		if !a.acquire() {
+			atomic.AddUint64(&a.shed, 1)
+			a.Logger.Debugw("goroutine budget exhausted, shedding fire", zap.String("source", event.Source()))
+			if a.otel != nil {
+				a.otel.export(ctx, a, opts.stats)
+			}
+			return
+		}
+		defer a.release()
+		job()
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	nethttp "net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"knative.dev/pkg/apis"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// transportConfig holds the transport settings of a source. Sources with
+// the same settings share a client.
+type transportConfig struct {
+	verbose          bool
+	retryAfter       bool
+	retryAfterJitter float64
+	proxyURL         string
+	tlsServerName    string
+	caPEM            string
+	h2c              bool
+	loadBalancing    LoadBalancingPolicy
+	warmup           bool
+	retryOnReset     bool
+	basicAuth        bool
+}
+
+// transportConfigFor returns the transport settings of the given source, the
+// TLS certificate of the sink being verified against caPEM when not empty.
+// The sources with distinct CAs get distinct transports.
+func transportConfigFor(source *sourcesv1beta1.PingSource, caPEM string) (transportConfig, error) {
+	cfg := transportConfig{
+		verbose:       boolAnnotation(source, VerboseLoggingAnnotation),
+		proxyURL:      source.Annotations[ProxyURLAnnotation],
+		tlsServerName: source.Annotations[TLSServerNameAnnotation],
+		caPEM:         caPEM,
+		h2c:           boolAnnotation(source, H2CAnnotation),
+		warmup:        boolAnnotation(source, WarmupAnnotation),
+		retryOnReset:  boolAnnotation(source, RetryOnResetAnnotation),
+		basicAuth:     source.Spec.BasicAuth != nil,
+	}
+	cfg.retryAfterJitter, cfg.retryAfter = floatAnnotation(source, RetryAfterJitterAnnotation)
+
+	if policy, ok := source.Annotations[LoadBalancingAnnotation]; ok {
+		var err error
+		if cfg.loadBalancing, err = ParseLoadBalancingPolicy(policy); err != nil {
+			return transportConfig{}, fmt.Errorf("invalid %s annotation: %w", LoadBalancingAnnotation, err)
+		}
+		if cfg.h2c || cfg.proxyURL != "" {
+			return transportConfig{}, fmt.Errorf("%s can't be combined with %s or %s", LoadBalancingAnnotation, H2CAnnotation, ProxyURLAnnotation)
+		}
+	}
+
+	if cfg.caPEM != "" {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(cfg.caPEM)) {
+			return transportConfig{}, errors.New("invalid sink CA certificates: no PEM encoded certificate found")
+		}
+		if cfg.h2c {
+			return transportConfig{}, fmt.Errorf("%s can't be combined with the sink CA certificates", H2CAnnotation)
+		}
+	}
+
+	if cfg.proxyURL != "" {
+		if u, err := url.Parse(cfg.proxyURL); err != nil || u.Host == "" {
+			return transportConfig{}, fmt.Errorf("invalid %s annotation %q", ProxyURLAnnotation, cfg.proxyURL)
+		}
+		if cfg.h2c {
+			return transportConfig{}, fmt.Errorf("%s can't be combined with %s", H2CAnnotation, ProxyURLAnnotation)
+		}
+	}
+	return cfg, nil
+}
+
+// clientPool holds one client per distinct transport configuration, as long
+// as a source uses it.
+type clientPool struct {
+	// def is the client used by the sources not customizing the transport.
+	def    cloudevents.Client
+	logger *zap.SugaredLogger
+	// resolver resolves the sink hosts of the load balanced clients.
+	resolver ipResolver
+	// clock times the waits honoring the Retry-After headers.
+	clock clock.Clock
+
+	mu      sync.Mutex
+	clients map[transportConfig]*pooledClient
+	// sources holds the configuration of the client used by each source,
+	// keyed by namespace/name.
+	sources map[string]transportConfig
+}
+
+type pooledClient struct {
+	client cloudevents.Client
+	// rt is the round tripper the client sends requests through.
+	rt nethttp.RoundTripper
+	// transport is the one rt sends requests through, its idle connections
+	// being closed once the client is discarded.
+	transport nethttp.RoundTripper
+	// refs is the number of sources using the client.
+	refs int
+}
+
+func newClientPool(def cloudevents.Client, logger *zap.SugaredLogger) *clientPool {
+	return &clientPool{
+		def:      def,
+		logger:   logger,
+		resolver: net.DefaultResolver,
+		clock:    clock.RealClock{},
+		clients:  make(map[transportConfig]*pooledClient),
+		sources:  make(map[string]transportConfig),
+	}
+}
+
+// Get returns the client for the given transport configuration used by the
+// source with the given key, creating it on first use. The client the source
+// used before is released.
+func (p *clientPool) Get(key string, cfg transportConfig) (cloudevents.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if previous, ok := p.sources[key]; ok && previous == cfg {
+		return p.clients[cfg].client, nil
+	}
+	p.release(key)
+	if cfg == (transportConfig{}) {
+		return p.def, nil
+	}
+
+	pc, ok := p.clients[cfg]
+	if !ok {
+		rt, transport := p.roundTripper(cfg)
+		client, err := newClient(rt)
+		if err != nil {
+			return nil, err
+		}
+		pc = &pooledClient{client: client, rt: rt, transport: transport}
+		p.clients[cfg] = pc
+	}
+	pc.refs++
+	p.sources[key] = cfg
+	return pc.client, nil
+}
+
+// Release releases the client used by the source with the given key.
+func (p *clientPool) Release(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.release(key)
+}
+
+// release releases the client used by the source with the given key. The
+// client is discarded once no source uses it, the idle connections of its
+// transport being closed.
+func (p *clientPool) release(key string) {
+	cfg, ok := p.sources[key]
+	if !ok {
+		return
+	}
+	delete(p.sources, key)
+	pc := p.clients[cfg]
+	if pc.refs--; pc.refs > 0 {
+		return
+	}
+	delete(p.clients, cfg)
+	// The default transport is shared with the other clients.
+	if idler, ok := pc.transport.(interface{ CloseIdleConnections() }); ok && pc.transport != nethttp.DefaultTransport {
+		idler.CloseIdleConnections()
+	}
+}
+
+// Warm opens a connection to target through the transport of the client
+// for cfg, so that the connection is kept alive for the first send. The
+// OPTIONS method is used, as for the CloudEvents webhook validation.
+func (p *clientPool) Warm(ctx context.Context, cfg transportConfig, target string) error {
+	p.mu.Lock()
+	pc, ok := p.clients[cfg]
+	p.mu.Unlock()
+	if !ok {
+		return errors.New("no client for the transport configuration")
+	}
+
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodOptions, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := pc.rt.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body for the connection to be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// Len returns the number of clients created by the pool.
+func (p *clientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// roundTripper returns the round tripper of the client for cfg, and the
+// transport it sends the requests through.
+func (p *clientPool) roundTripper(cfg transportConfig) (rt, transport nethttp.RoundTripper) {
+	rt = nethttp.DefaultTransport
+	if cfg.h2c {
+		// HTTP/2 without TLS, the connection being dialed in the clear.
+		rt = &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		}
+	} else if cfg.proxyURL != "" || cfg.tlsServerName != "" || cfg.caPEM != "" || cfg.loadBalancing != "" || cfg.retryOnReset {
+		// The sources retrying on reset close the idle connections of
+		// their own transport only.
+		t := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+		if cfg.proxyURL != "" {
+			// Validated by transportConfigFor.
+			proxy, _ := url.Parse(cfg.proxyURL)
+			t.Proxy = nethttp.ProxyURL(proxy)
+		}
+		if cfg.tlsServerName != "" || cfg.caPEM != "" {
+			t.TLSClientConfig = &tls.Config{
+				ServerName: cfg.tlsServerName,
+				MinVersion: tls.VersionTLS12,
+			}
+		}
+		if cfg.caPEM != "" {
+			// Validated by transportConfigFor.
+			t.TLSClientConfig.RootCAs = x509.NewCertPool()
+			t.TLSClientConfig.RootCAs.AppendCertsFromPEM([]byte(cfg.caPEM))
+		}
+		rt = t
+		if cfg.loadBalancing != "" {
+			rt = &balancingRoundTripper{
+				base:     t,
+				fallback: t,
+				resolver: p.resolver,
+				policy:   cfg.loadBalancing,
+				intn:     rand.Intn,
+				turns:    make(map[string]int),
+				backends: make(map[string]*nethttp.Transport),
+			}
+		}
+	}
+	transport = rt
+	if cfg.retryOnReset {
+		rt = &resetRetryRoundTripper{next: rt}
+	}
+	if cfg.basicAuth {
+		rt = &basicAuthRoundTripper{next: rt}
+	}
+	if cfg.verbose {
+		rt = &loggingRoundTripper{next: rt, logger: p.logger}
+	}
+	if cfg.retryAfter {
+		rt = &retryAfterRoundTripper{next: rt, jitter: cfg.retryAfterJitter, clock: p.clock}
+	}
+	return rt, transport
+}
+
+// clientOptions sets opts to send the events of eventSource through the
+// client shared by the sources customizing the transport like source. The
+// default client is used when the client for source can't be made.
+func (a *cronJobsRunner) clientOptions(ctx context.Context, source *sourcesv1beta1.PingSource, eventSource string, opts *jobOptions) error {
+	// Like the credentials, the CA certificates are read on each reconcile,
+	// and when the Secret or ConfigMap holding them changes.
+	caPEM := source.Spec.SinkCAPEM
+	if source.Spec.SinkCACerts != nil {
+		var err error
+		if caPEM, err = readSinkCACerts(ctx, a.kubeClient, source); err != nil {
+			return newScheduleError(source, ReasonReadFailed, fmt.Errorf("failed to read the sink CA certificates: %w", err))
+		}
+	}
+
+	cfg, err := transportConfigFor(source, caPEM)
+	if err != nil {
+		a.Logger.Errorw("invalid transport configuration, using default client", zap.String("source", eventSource), zap.Error(err))
+		a.clients.Release(opts.stats.key)
+		return nil
+	}
+	client, err := a.clients.Get(opts.stats.key, cfg)
+	if err != nil {
+		a.Logger.Errorw("failed to create client, using default client", zap.String("source", eventSource), zap.Error(err))
+		return nil
+	}
+	opts.client = client
+	if cfg.warmup {
+		go a.warm(cfg, source.Status.SinkURI)
+	}
+	return nil
+}
+
+// warmupTimeout bounds the time spent opening a connection to a sink.
+const warmupTimeout = 10 * time.Second
+
+// warm opens a connection to the sink with the client for cfg. Failures are
+// only logged, the connection being opened again on the first fire.
+func (a *cronJobsRunner) warm(cfg transportConfig, sink *apis.URL) {
+	if sink == nil || (sink.Scheme != "http" && sink.Scheme != "https") {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), warmupTimeout)
+	defer cancel()
+	if err := a.clients.Warm(ctx, cfg, sink.String()); err != nil {
+		a.Logger.Warnw("failed to warm up the connection to the sink", zap.String("sink", sink.Host), zap.Error(err))
+	}
+}
@@ -0,0 +1,346 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestClientPoolSharesClients(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger)
+
+	source := func(name string, annotations map[string]string) *sourcesv1beta1.PingSource {
+		return newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Name = name
+			s.Annotations = annotations
+		})
+	}
+	clientFor := func(src *sourcesv1beta1.PingSource) interface{} {
+		cfg, err := transportConfigFor(src, "")
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		client, err := runner.clients.Get(src.Namespace+"/"+src.Name, cfg)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		return client
+	}
+
+	proxied := map[string]string{
+		ProxyURLAnnotation:      "http://proxy.example.com:3128",
+		TLSServerNameAnnotation: "sink.example.com",
+	}
+	first := clientFor(source("first", proxied))
+	second := clientFor(source("second", proxied))
+	third := clientFor(source("third", map[string]string{
+		ProxyURLAnnotation:      "http://other-proxy.example.com:3128",
+		TLSServerNameAnnotation: "sink.example.com",
+	}))
+
+	if first != second {
+		t.Error("Expected sources with the same transport configuration to share a client")
+	}
+	if first == third {
+		t.Error("Expected a source with a different transport configuration to get its own client")
+	}
+	if got := runner.clients.Len(); got != 2 {
+		t.Error("Expected 2 pooled clients, got", got)
+	}
+	if got := clientFor(source("default", nil)); got != ce {
+		t.Error("Expected a source without transport configuration to use the default client")
+	}
+}
+
+func TestClientPoolReleasesUnusedClients(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	server := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state nethttp.ConnState) {
+		if state == nethttp.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	sinkURI, _ := apis.ParseURL(server.URL)
+	var ids []cron.EntryID
+	for _, name := range []string{"first", "second"} {
+		ids = append(ids, mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Name = name
+			s.Status.SinkURI = sinkURI
+			// Any customization for the source to get its own transport.
+			s.Annotations = map[string]string{RetryOnResetAnnotation: "true"}
+		})))
+	}
+	if got := runner.clients.Len(); got != 1 {
+		t.Fatal("Expected the sources to share 1 client, got", got)
+	}
+	runner.cron.Entry(ids[0]).Job.Run()
+
+	if err := runner.RemoveSchedule(ids[0]); err != nil {
+		t.Fatal("Failed to remove the schedule:", err)
+	}
+	if got := runner.clients.Len(); got != 1 {
+		t.Fatal("Expected the client used by the other source to be kept, got clients:", got)
+	}
+	select {
+	case <-closed:
+		t.Fatal("Expected the connections of the kept client to stay open")
+	default:
+	}
+
+	if err := runner.RemoveSchedule(ids[1]); err != nil {
+		t.Fatal("Failed to remove the schedule:", err)
+	}
+	if got := runner.clients.Len(); got != 0 {
+		t.Fatal("Expected the unused client to be discarded, got clients:", got)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected the idle connections of the discarded client to be closed")
+	}
+}
+
+func TestH2CPriorKnowledge(t *testing.T) {
+	protos := make(chan string, 1)
+	sink := httptest.NewServer(h2c.NewHandler(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		protos <- r.Proto
+		w.WriteHeader(nethttp.StatusAccepted)
+	}), &http2.Server{}))
+	defer sink.Close()
+
+	testCases := map[string]struct {
+		annotations map[string]string
+		want        string
+	}{
+		"h2c": {
+			annotations: map[string]string{H2CAnnotation: "true"},
+			want:        "HTTP/2.0",
+		},
+		"default": {
+			// Any customization for the source to get its own HTTP client.
+			annotations: map[string]string{VerboseLoggingAnnotation: "true"},
+			want:        "HTTP/1.1",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			sinkURI, _ := apis.ParseURL(sink.URL)
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = sinkURI
+				s.Annotations = tc.annotations
+			}))
+			runner.cron.Entry(entryID).Job.Run()
+
+			select {
+			case got := <-protos:
+				if got != tc.want {
+					t.Errorf("Expected the event to be sent over %s, got %s", tc.want, got)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected the sink to receive the event")
+			}
+		})
+	}
+}
+
+func TestSinkCAPEM(t *testing.T) {
+	var received int32
+	sink := httptest.NewTLSServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer sink.Close()
+	sinkCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: sink.Certificate().Raw}))
+
+	testCases := map[string]struct {
+		caPEM string
+		want  int32
+	}{
+		"sink CA": {
+			caPEM: sinkCA,
+			want:  1,
+		},
+		"other CA": {
+			caPEM: newTestCA(t),
+			want:  0,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			atomic.StoreInt32(&received, 0)
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			sinkURI, _ := apis.ParseURL(sink.URL)
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = sinkURI
+				s.Spec.SinkCAPEM = tc.caPEM
+			}))
+			runner.cron.Entry(entryID).Job.Run()
+
+			if got := atomic.LoadInt32(&received); got != tc.want {
+				t.Errorf("Expected the sink to receive %d events, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+// newTestCA returns the PEM encoded certificate of a new self-signed CA.
+func newTestCA(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Failed to generate the CA key:", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("Failed to create the CA certificate:", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestTransportConfigInvalidSinkCAPEM(t *testing.T) {
+	_, err := transportConfigFor(newTestSource(), "not a certificate")
+	if err == nil {
+		t.Error("Expected an error for a CA without certificate")
+	}
+}
+
+func TestTransportConfigH2CWithProxy(t *testing.T) {
+	_, err := transportConfigFor(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{
+			H2CAnnotation:      "true",
+			ProxyURLAnnotation: "http://proxy.example.com:3128",
+		}
+	}), "")
+	if err == nil {
+		t.Error("Expected an error for h2c through a proxy")
+	}
+}
+
+func TestTransportConfigInvalidProxy(t *testing.T) {
+	_, err := transportConfigFor(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{ProxyURLAnnotation: "not a url"}
+	}), "")
+	if err == nil {
+		t.Error("Expected an error for an invalid proxy URL")
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	var conns, requests int32
+	server := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state nethttp.ConnState) {
+		if state == nethttp.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	sinkURI, _ := apis.ParseURL(server.URL)
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+		s.Annotations = map[string]string{WarmupAnnotation: "true"}
+	}))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&requests) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a connection to be established before the first fire")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&conns); got != 1 {
+		t.Fatal("Expected 1 connection after warmup, got", got)
+	}
+
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Error("Expected the event to be sent after the warmup request, got requests:", got)
+	}
+	if got := atomic.LoadInt32(&conns); got != 1 {
+		t.Error("Expected the first fire to reuse the warm connection, got connections:", got)
+	}
+}
+
+func TestWarmupUnreachableSink(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logger)
+
+	cfg := transportConfig{warmup: true}
+	if _, err := runner.clients.Get("test-ns/test-name", cfg); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	// Nothing listens on port 1.
+	if err := runner.clients.Warm(ctx, cfg, "http://127.0.0.1:1"); err == nil {
+		t.Error("Expected an error warming up an unreachable sink")
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// concurrencyTick returns job applying the concurrency policy of source.
+// The running fire of a source updated in place doesn't make the fires of
+// the new job skip.
+func (a *cronJobsRunner) concurrencyTick(source *sourcesv1beta1.PingSource, job func()) func() {
+	if ConcurrencyPolicy(source.Annotations[ConcurrencyPolicyAnnotation]) != ConcurrencySkip {
+		return job
+	}
+	logger := skipLogger{logger: a.Logger, source: sourcesv1beta1.PingSourceSource(source.Namespace, source.Name)}
+	return cron.SkipIfStillRunning(logger)(cron.FuncJob(job)).Run
+}
+
+// skipLogger logs the fires skipped by cron.SkipIfStillRunning.
+type skipLogger struct {
+	logger *zap.SugaredLogger
+	source string
+}
+
+func (l skipLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Infow("previous fire still running, skipping fire", zap.String("source", l.source))
+}
+
+func (l skipLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.logger.Errorw(msg, append(keysAndValues, zap.String("source", l.source), zap.Error(err))...)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/configmap"
+	"sigs.k8s.io/yaml"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// errRelativeSink is returned for the ConfigMap schedules whose sink isn't
+// an absolute URI, there being no reference to resolve it against.
+var errRelativeSink = errors.New("the sink must be an absolute URI")
+
+// ConfigMapLoader schedules the PingSources defined in a ConfigMap, for the
+// deployments without the PingSource CRD. Each key of the ConfigMap defines
+// a source named after it, in the namespace of the ConfigMap, its value
+// being the YAML spec of the source, with a sink URI.
+type ConfigMapLoader struct {
+	runner CronJobRunner
+	logger *zap.SugaredLogger
+
+	mu sync.Mutex
+	// loaded holds the schedules of the sources loaded, keyed by the
+	// ConfigMap key.
+	loaded map[string]loadedSchedule
+}
+
+// loadedSchedule is the schedule of a source loaded from the ConfigMap.
+type loadedSchedule struct {
+	id   cron.EntryID
+	hash string
+}
+
+// NewConfigMapLoader returns a loader scheduling the sources of a ConfigMap
+// with runner.
+func NewConfigMapLoader(runner CronJobRunner, logger *zap.SugaredLogger) *ConfigMapLoader {
+	return &ConfigMapLoader{
+		runner: runner,
+		logger: logger,
+		loaded: make(map[string]loadedSchedule),
+	}
+}
+
+// Watch makes the loader reconcile the schedules with the ConfigMap name
+// each time it changes. A missing ConfigMap defines no source.
+func (l *ConfigMapLoader) Watch(w configmap.DefaultingWatcher, namespace, name string) {
+	w.WatchWithDefault(corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+	}, l.Load)
+}
+
+// Load reconciles the schedules with the sources defined in cm: the new
+// sources are added, the changed ones are updated and the ones no longer
+// defined are removed. The sources failing to parse keep their previous
+// schedule, if any.
+func (l *ConfigMapLoader) Load(cm *corev1.ConfigMap) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, loaded := range l.loaded {
+		if _, ok := cm.Data[key]; !ok {
+			if err := l.runner.RemoveSchedule(loaded.id); err != nil {
+				l.logger.Warnw("failed to remove the configmap schedule", zap.String("key", key), zap.Error(err))
+			}
+			delete(l.loaded, key)
+		}
+	}
+
+	for key, value := range cm.Data {
+		source, err := configMapSource(cm.Namespace, key, value)
+		if err != nil {
+			l.logger.Errorw("invalid configmap schedule", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		hash := specHash(source)
+		if loaded, ok := l.loaded[key]; ok && loaded.hash == hash {
+			continue
+		}
+		id, err := l.runner.UpdateSchedule(source)
+		if err != nil {
+			l.logger.Errorw("failed to schedule the configmap source", zap.String("key", key), zap.Error(err))
+			delete(l.loaded, key)
+			continue
+		}
+		l.loaded[key] = loadedSchedule{id: id, hash: hash}
+	}
+}
+
+// configMapSource returns the source named name defined by the YAML spec.
+func configMapSource(namespace, name, spec string) (*sourcesv1beta1.PingSource, error) {
+	source := &sourcesv1beta1.PingSource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+	}
+	if err := yaml.UnmarshalStrict([]byte(spec), &source.Spec); err != nil {
+		return nil, fmt.Errorf("failed to parse the spec: %w", err)
+	}
+	if err := source.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	if source.Spec.Sink.URI == nil || !source.Spec.Sink.URI.URL().IsAbs() {
+		return nil, errRelativeSink
+	}
+	source.Status.SinkURI = source.Spec.Sink.URI
+	return source, nil
+}
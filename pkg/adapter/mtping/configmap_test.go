@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+const (
+	hourlySchedule = `
+schedule: "0 * * * *"
+jsonData: hourly
+sink:
+  uri: http://hourly.example.com
+`
+	dailySchedule = `
+schedule: "0 0 * * *"
+jsonData: daily
+sink:
+  uri: http://daily.example.com
+`
+	weeklySchedule = `
+schedule: "0 0 * * 0"
+jsonData: weekly
+sink:
+  uri: http://weekly.example.com
+`
+)
+
+// loadedIDs returns the entries of the sources loaded, keyed by ConfigMap
+// key.
+func loadedIDs(l *ConfigMapLoader) map[string]cron.EntryID {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ids := make(map[string]cron.EntryID, len(l.loaded))
+	for key, loaded := range l.loaded {
+		ids[key] = loaded.id
+	}
+	return ids
+}
+
+func schedulesConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "schedules"},
+		Data:       data,
+	}
+}
+
+func TestConfigMapLoader(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	loader := NewConfigMapLoader(runner, logging.FromContext(ctx))
+
+	loader.Load(schedulesConfigMap(map[string]string{"hourly": hourlySchedule, "daily": dailySchedule}))
+
+	ids := loadedIDs(loader)
+	if len(ids) != 2 {
+		t.Fatal("Expected 2 schedules loaded, got", ids)
+	}
+	for _, id := range ids {
+		runner.cron.Entry(id).Job.Run()
+	}
+	var got []string
+	for _, event := range ce.Sent() {
+		got = append(got, event.Source())
+	}
+	sort.Strings(got)
+	want := []string{
+		sourcesv1beta1.PingSourceSource("test-ns", "daily"),
+		sourcesv1beta1.PingSourceSource("test-ns", "hourly"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected sources fired (-want, +got):", diff)
+	}
+
+	// daily removed, hourly unchanged, weekly added.
+	loader.Load(schedulesConfigMap(map[string]string{"hourly": hourlySchedule, "weekly": weeklySchedule}))
+
+	updated := loadedIDs(loader)
+	if len(updated) != 2 || updated["weekly"] == 0 {
+		t.Fatal("Expected the hourly and weekly schedules loaded, got", updated)
+	}
+	if updated["hourly"] != ids["hourly"] {
+		t.Errorf("Expected the unchanged hourly entry %d to be kept, got %d", ids["hourly"], updated["hourly"])
+	}
+	if _, err := runner.Entry(ids["daily"]); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected the daily entry to be removed, got %v", err)
+	}
+}
+
+func TestConfigMapLoaderInvalid(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	loader := NewConfigMapLoader(runner, logging.FromContext(ctx))
+
+	loader.Load(schedulesConfigMap(map[string]string{"hourly": hourlySchedule}))
+	id := loadedIDs(loader)["hourly"]
+
+	loader.Load(schedulesConfigMap(map[string]string{
+		"hourly":   "schedule: [",
+		"relative": "schedule: \"0 * * * *\"\nsink:\n  uri: /relative\n",
+		"unknown":  "schedule: \"0 * * * *\"\nunknown: field\nsink:\n  uri: http://example.com\n",
+		"bad":      "schedule: \"not a schedule\"\nsink:\n  uri: http://example.com\n",
+	}))
+
+	if diff := cmp.Diff(map[string]cron.EntryID{"hourly": id}, loadedIDs(loader)); diff != "" {
+		t.Error("Expected only the previous hourly schedule to be kept (-want, +got):", diff)
+	}
+	if _, err := runner.Entry(id); err != nil {
+		t.Error("Expected the previous hourly schedule to be kept, got", err)
+	}
+}
+
+func TestConfigMapLoaderWatch(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	kubeClient := kubeclient.Get(ctx)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeClient, logging.FromContext(ctx))
+	loader := NewConfigMapLoader(runner, logging.FromContext(ctx))
+
+	cms := kubeClient.CoreV1().ConfigMaps("test-ns")
+	if _, err := cms.Create(ctx, schedulesConfigMap(map[string]string{"hourly": hourlySchedule, "daily": dailySchedule}), metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create the configmap:", err)
+	}
+
+	watcher := configmap.NewInformedWatcher(kubeClient, "test-ns")
+	loader.Watch(watcher, "test-ns", "schedules")
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := watcher.Start(stopCh); err != nil {
+		t.Fatal("Failed to start the watcher:", err)
+	}
+
+	waitLoaded := func(want ...string) {
+		t.Helper()
+		sort.Strings(want)
+		var got []string
+		if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			got = got[:0]
+			for key := range loadedIDs(loader) {
+				got = append(got, key)
+			}
+			sort.Strings(got)
+			return cmp.Equal(want, got), nil
+		}); err != nil {
+			t.Fatalf("Expected the schedules %v to be loaded, got %v", want, got)
+		}
+	}
+	waitLoaded("daily", "hourly")
+
+	if _, err := cms.Update(context.Background(), schedulesConfigMap(map[string]string{"weekly": weeklySchedule}), metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Failed to update the configmap:", err)
+	}
+	waitLoaded("weekly")
+}
@@ -38,3 +38,17 @@ func NewDelayingContext(ctx context.Context, afterSecond int) context.Context {
 	}()
 	return delayCtx
 }
+
+type terminationKey struct{}
+
+// WithTerminationSignal returns a context carrying a channel closed as soon
+// as the adapter is asked to terminate, possibly before ctx is cancelled.
+func WithTerminationSignal(ctx context.Context, terminating <-chan struct{}) context.Context {
+	return context.WithValue(ctx, terminationKey{}, terminating)
+}
+
+// terminationSignal returns the channel set by WithTerminationSignal, or nil.
+func terminationSignal(ctx context.Context) <-chan struct{} {
+	terminating, _ := ctx.Value(terminationKey{}).(<-chan struct{})
+	return terminating
+}
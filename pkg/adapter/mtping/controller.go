@@ -19,8 +19,15 @@ package mtping
 import (
 	"context"
 
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
 
 	"knative.dev/eventing/pkg/adapter/v2"
 	"knative.dev/eventing/pkg/apis/sources/v1beta1"
@@ -39,6 +46,13 @@ type MTAdapter interface {
 	Remove(ctx context.Context, source *v1beta1.PingSource)
 }
 
+// releasingAdapter is implemented by the adapters telling the sources of the
+// buckets lost to other replicas apart from the deleted ones.
+type releasingAdapter interface {
+	// release stops firing the schedule of source, its bucket being lost.
+	release(ctx context.Context, source *v1beta1.PingSource)
+}
+
 // NewController initializes the controller. This is called by the shared adapter Main
 // Registers event handlers to enqueue events.
 func NewController(ctx context.Context, adapter adapter.Adapter) *controller.Impl {
@@ -49,30 +63,65 @@ func NewController(ctx context.Context, adapter adapter.Adapter) *controller.Imp
 
 	r := &Reconciler{mtadapter}
 
-	// TODO: need pkg#1683
-	// lister := pingsourceinformer.Get(ctx).Lister()
-	//opts := func(impl *controller.Impl) controller.Options {
-	//	return controller.Options{
-	//		DemoteFunc: func(b reconciler.Bucket) {
-	//			all, _ := lister.List(labels.Everything())
-	//			// TODO: demote with error
-	//			//if err != nil {
-	//			//	return err
-	//			//}
-	//			for _, elt := range all {
-	//				mtadapter.Remove(ctx, elt)
-	//			}
-	//		},
-	//	}
-	//}
-
 	impl := pingsourcereconciler.NewImpl(ctx, r, func(impl *controller.Impl) controller.Options {
 		return controller.Options{
 			SkipStatusUpdates: true,
 		}
 	})
 
+	// The schedules of the buckets lost to other replicas are removed, for
+	// their sources not to be fired twice, or parked when sharding.
+	sa, sharded := adapter.(shardedAdapter)
+	sharded = sharded && sa.shardingConfig() != nil
+	giveBack := mtadapter.Remove
+	if ra, ok := adapter.(releasingAdapter); ok {
+		giveBack = ra.release
+	}
+	if sharded {
+		giveBack = sa.park
+	}
+	lister := pingsourceinformer.Get(ctx).Lister()
+	impl.Reconciler = &demotingReconciler{
+		Reconciler:  impl.Reconciler,
+		LeaderAware: impl.Reconciler.(reconciler.LeaderAware),
+		demote: func(bkt reconciler.Bucket) {
+			all, err := lister.List(labels.Everything())
+			if err != nil {
+				logging.FromContext(ctx).Errorw("failed to list the sources of the demoted bucket", zap.Error(err))
+				return
+			}
+			for _, source := range all {
+				if bkt.Has(types.NamespacedName{Namespace: source.Namespace, Name: source.Name}) {
+					giveBack(ctx, source)
+				}
+			}
+		},
+	}
+
+	if sharded {
+		s, err := newSharder(logging.FromContext(ctx), kubeclient.Get(ctx), system.Namespace(), impl.Name,
+			*sa.shardingConfig(), impl.Reconciler.(reconciler.LeaderAware))
+		if err != nil {
+			logging.FromContext(ctx).Fatalw("Failed to set up the sharding of the sources", zap.Error(err))
+		}
+		impl.Reconciler = &shardedReconciler{Reconciler: impl.Reconciler, sharder: s, ctx: ctx}
+	}
+
 	logging.FromContext(ctx).Info("Setting up event handlers")
 	pingsourceinformer.Get(ctx).Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+	watchSinkCACerts(ctx, kubeclient.Get(ctx), mtadapter, lister, impl.Enqueue, sinkCACertsPollInterval)
 	return impl
 }
+
+// demotingReconciler calls demote when demoted, after the reconciler.
+type demotingReconciler struct {
+	controller.Reconciler
+	reconciler.LeaderAware
+	demote func(reconciler.Bucket)
+}
+
+// Demote implements reconciler.LeaderAware
+func (r *demotingReconciler) Demote(bkt reconciler.Bucket) {
+	r.LeaderAware.Demote(bkt)
+	r.demote(bkt)
+}
@@ -18,12 +18,20 @@ package mtping
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/pkg/hash"
+	kle "knative.dev/pkg/leaderelection"
+	"knative.dev/pkg/reconciler"
 	. "knative.dev/pkg/reconciler/testing"
 
 	// Fake injection informers
-	_ "knative.dev/eventing/pkg/client/injection/informers/sources/v1beta1/pingsource/fake"
+	fakepingsourceinformer "knative.dev/eventing/pkg/client/injection/informers/sources/v1beta1/pingsource/fake"
 
 	"knative.dev/eventing/pkg/apis/sources/v1beta1"
 )
@@ -40,6 +48,85 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestDemoteRemovesSchedules(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	adapter := &removingAdapter{removed: sets.NewString()}
+	c := NewController(ctx, adapter)
+
+	bkt := hash.NewBucketSet(sets.NewString("bucket-a", "bucket-b")).Buckets()[0]
+	want := sets.NewString()
+	for i := 0; i < 10; i++ {
+		source := &v1beta1.PingSource{ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: fmt.Sprint("test-name-", i)}}
+		fakepingsourceinformer.Get(ctx).Informer().GetIndexer().Add(source)
+		if bkt.Has(types.NamespacedName{Namespace: source.Namespace, Name: source.Name}) {
+			want.Insert(source.Name)
+		}
+	}
+	if want.Len() == 0 || want.Len() == 10 {
+		t.Fatal("Expected the sources to be in both buckets, got", want.Len(), "in the demoted one")
+	}
+
+	la, ok := c.Reconciler.(reconciler.LeaderAware)
+	if !ok {
+		t.Fatalf("Expected a leader aware reconciler, got %T", c.Reconciler)
+	}
+	la.Demote(bkt)
+	if !adapter.removed.Equal(want) {
+		t.Errorf("Expected the schedules of %v to be removed, got %v", want.List(), adapter.removed.List())
+	}
+}
+
+func TestNewSharded(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+
+	c := NewController(ctx, &shardingTestAdapter{parked: sets.NewString()})
+
+	if _, ok := c.Reconciler.(*shardedReconciler); !ok {
+		t.Fatalf("Expected a sharded reconciler, got %T", c.Reconciler)
+	}
+}
+
+func TestDemoteParksShardedSchedules(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	adapter := &shardingTestAdapter{parked: sets.NewString()}
+	c := NewController(ctx, adapter)
+
+	source := &v1beta1.PingSource{ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test-name"}}
+	fakepingsourceinformer.Get(ctx).Informer().GetIndexer().Add(source)
+	sharder := c.Reconciler.(*shardedReconciler).sharder
+	for _, bkt := range sharder.buckets {
+		if bkt.Has(types.NamespacedName{Namespace: source.Namespace, Name: source.Name}) {
+			sharder.la.Demote(bkt)
+		}
+	}
+	if !adapter.parked.Equal(sets.NewString("test-name")) {
+		t.Errorf("Expected the schedule of the source to be parked, got %v", adapter.parked.List())
+	}
+}
+
+// removingAdapter records the sources it removes the schedules of.
+type removingAdapter struct {
+	testAdapter
+	removed sets.String
+}
+
+func (a *removingAdapter) Remove(ctx context.Context, source *v1beta1.PingSource) {
+	a.removed.Insert(source.Name)
+}
+
+type shardingTestAdapter struct {
+	testAdapter
+	parked sets.String
+}
+
+func (a *shardingTestAdapter) park(ctx context.Context, source *v1beta1.PingSource) {
+	a.parked.Insert(source.Name)
+}
+
+func (shardingTestAdapter) shardingConfig() *kle.ComponentConfig {
+	return &kle.ComponentConfig{Component: "pingsource-mt-adapter", Buckets: 2, Identity: "test"}
+}
+
 func (testAdapter) Start(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"knative.dev/pkg/apis"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// appliedSpec records the last schedule of a source debouncing its updates.
+type appliedSpec struct {
+	hash string
+	at   time.Time
+}
+
+// debounceWindow returns the duration set by DebounceAnnotation, or zero
+// when the annotation is missing or is not a valid positive duration.
+func debounceWindow(source *sourcesv1beta1.PingSource) time.Duration {
+	value, ok := source.Annotations[DebounceAnnotation]
+	if !ok {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// specHash returns a hash of what the schedule of the source is made of:
+// its spec, annotations and sink.
+func specHash(source *sourcesv1beta1.PingSource) string {
+	b, _ := json.Marshal(struct {
+		Spec        sourcesv1beta1.PingSourceSpec
+		Annotations map[string]string
+		SinkURI     *apis.URL
+	}{source.Spec, source.Annotations, source.Status.SinkURI})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// dedupTTL is how long an emitted event is remembered by the dedup store.
+// It only needs to exceed the clock skew between replicas.
+const dedupTTL = 10 * time.Minute
+
+// DedupStore records the events emitted by the replicas of the adapter, so
+// that a tick fired by several replicas is emitted only once.
+type DedupStore interface {
+	// Claim records key and returns true, unless key is already recorded.
+	// Keys are forgotten after ttl.
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// ValueDedupStore is a DedupStore recording a value with each key, so that
+// the replicas emitting the same event are told apart from distinct events
+// whose IDs collide.
+type ValueDedupStore interface {
+	DedupStore
+
+	// ClaimValue records key with value and returns true, unless key is
+	// already recorded, returning the recorded value.
+	ClaimValue(ctx context.Context, key, value string, ttl time.Duration) (bool, string, error)
+}
+
+// DedupCollisionPolicy tells what to do with an event whose ID is already
+// claimed by a different event.
+type DedupCollisionPolicy string
+
+const (
+	// DedupCollisionSkip logs and drops the colliding event.
+	DedupCollisionSkip DedupCollisionPolicy = "skip"
+
+	// DedupCollisionSend sends the colliding event anyway.
+	DedupCollisionSend DedupCollisionPolicy = "send"
+)
+
+// ParseDedupCollisionPolicy returns the collision policy named s.
+func ParseDedupCollisionPolicy(s string) (DedupCollisionPolicy, error) {
+	switch policy := DedupCollisionPolicy(s); policy {
+	case DedupCollisionSkip, DedupCollisionSend:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid dedup collision policy %q, expected %q or %q", s, DedupCollisionSkip, DedupCollisionSend)
+}
+
+// dedupEventID returns the ID of the event of the given source for the tick
+// at the given time. All replicas compute the same ID for the same tick.
+func dedupEventID(source string, tick time.Time) string {
+	name := fmt.Sprintf("%s@%d", source, tick.Unix())
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
+
+// dedupDigest returns the value claimed with the ID of the event: a digest
+// of its type, source and data, the same for all replicas.
+func dedupDigest(event cloudevents.Event) string {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(event.Type()), []byte(event.Source()), event.Data()} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// claim claims the event in the dedup store. It returns false when the
+// event must not be sent, having been emitted by another replica or
+// colliding with another event under DedupCollisionSkip.
+func (a *cronJobsRunner) claim(ctx context.Context, event cloudevents.Event) bool {
+	store, ok := a.dedup.(ValueDedupStore)
+	if !ok {
+		claimed, err := a.dedup.Claim(ctx, event.ID(), dedupTTL)
+		if err != nil {
+			// Better emit twice than not at all.
+			a.Logger.Warnw("failed to claim cloudevent, sending it anyway", zap.String("id", event.ID()), zap.Error(err))
+			return true
+		}
+		if !claimed {
+			a.Logger.Debugw("cloudevent already emitted by another replica", zap.String("id", event.ID()))
+		}
+		return claimed
+	}
+
+	digest := dedupDigest(event)
+	claimed, existing, err := store.ClaimValue(ctx, event.ID(), digest, dedupTTL)
+	switch {
+	case err != nil:
+		a.Logger.Warnw("failed to claim cloudevent, sending it anyway", zap.String("id", event.ID()), zap.Error(err))
+		return true
+	case claimed:
+		return true
+	case existing == digest:
+		a.Logger.Debugw("cloudevent already emitted by another replica", zap.String("id", event.ID()))
+		return false
+	}
+
+	atomic.AddUint64(&a.dedupCollisions, 1)
+	if a.collisionPolicy == DedupCollisionSend {
+		a.Logger.Warnw("cloudevent ID collides with another event, sending it anyway", zap.String("id", event.ID()),
+			zap.String("source", event.Source()))
+		return true
+	}
+	a.Logger.Errorw("cloudevent ID collides with another event, skipping it", zap.String("id", event.ID()),
+		zap.String("source", event.Source()))
+	return false
+}
+
+// redisDedupStore is a DedupStore recording keys in Redis with SET NX.
+type redisDedupStore struct {
+	target *url.URL
+	redis  redisSender
+}
+
+// NewRedisDedupStore returns a DedupStore backed by the Redis server at the
+// given URL, of the form redis://[user:password@]host[:port].
+func NewRedisDedupStore(rawURL string) (DedupStore, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme != redisScheme || target.Host == "" {
+		return nil, fmt.Errorf("invalid redis URL for host %q, expected redis://[user:password@]host[:port]", target.Host)
+	}
+	return &redisDedupStore{target: target}, nil
+}
+
+func (s *redisDedupStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	conn, rw, err := s.redis.dial(ctx, s.target)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	reply, err := redisDo(rw, "SET", "pingsource:dedup:"+key, "1", "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	// A nil reply means the key is already set.
+	return reply == "OK", nil
+}
+
+func (s *redisDedupStore) ClaimValue(ctx context.Context, key, value string, ttl time.Duration) (bool, string, error) {
+	conn, rw, err := s.redis.dial(ctx, s.target)
+	if err != nil {
+		return false, "", err
+	}
+	defer conn.Close()
+
+	reply, err := redisDo(rw, "SET", "pingsource:dedup:"+key, value, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, "", err
+	}
+	if reply == "OK" {
+		return true, "", nil
+	}
+	existing, err := redisDo(rw, "GET", "pingsource:dedup:"+key)
+	if err != nil {
+		return false, "", err
+	}
+	return false, existing, nil
+}
+
+// setFireID sets the ID of the event of the fire of tick. The replicas
+// deduplicating their fires produce the same ID for the same tick.
+func (a *cronJobsRunner) setFireID(event *cloudevents.Event, tick time.Time) {
+	if a.dedup == nil {
+		event.SetID(uuid.New().String()) // provide an ID here so we can track it with logging
+		return
+	}
+	key := event.Source()
+	if index, ok := event.Extensions()[scheduleIndexExtension]; ok {
+		key = fmt.Sprintf("%s#%v", key, index)
+	}
+	event.SetID(dedupEventID(key, tick.Truncate(time.Second)))
+}
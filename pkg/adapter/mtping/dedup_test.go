@@ -0,0 +1,239 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestDedupAcrossReplicas(t *testing.T) {
+	redis := newFakeRedis(t)
+	defer redis.Close()
+
+	store, err := NewRedisDedupStore(fmt.Sprintf("redis://%s", redis.Addr()))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	source := newTestSource()
+
+	// Both replicas fire the same ticks, like cron does.
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start.Add(-30 * time.Second))
+	clients := []*adaptertesting.TestCloudEventsClient{adaptertesting.NewTestClient(), adaptertesting.NewTestClient()}
+	jobs := make([]func(), 0, len(clients))
+	for _, ce := range clients {
+		runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithDedupStore(store))
+		runner.clock = fakeClock
+		jobs = append(jobs, runner.cron.Entry(mustAddSchedule(t, runner, source)).Job.Run)
+	}
+
+	for tick := 0; tick < 2; tick++ {
+		fakeClock.SetTime(start.Add(time.Duration(tick) * time.Minute))
+
+		var wg sync.WaitGroup
+		for _, job := range jobs {
+			wg.Add(1)
+			go func(job func()) {
+				defer wg.Done()
+				job()
+			}(job)
+		}
+		wg.Wait()
+
+		if got := len(clients[0].Sent()) + len(clients[1].Sent()); got != tick+1 {
+			t.Errorf("Expected %d events to be sent after tick %d, got %d", tick+1, tick+1, got)
+		}
+	}
+}
+
+func TestDedupSkewedReplicas(t *testing.T) {
+	redis := newFakeRedis(t)
+	defer redis.Close()
+
+	store, err := NewRedisDedupStore(fmt.Sprintf("redis://%s", redis.Addr()))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	source := newTestSource()
+
+	tick := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	// The second replica fires the same tick past the next second.
+	fired := []time.Time{tick.Add(900 * time.Millisecond), tick.Add(1400 * time.Millisecond)}
+	clients := []*adaptertesting.TestCloudEventsClient{adaptertesting.NewTestClient(), adaptertesting.NewTestClient()}
+	for i, ce := range clients {
+		runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithDedupStore(store))
+		fakeClock := clock.NewFakeClock(tick.Add(-30 * time.Second))
+		runner.clock = fakeClock
+		id := mustAddSchedule(t, runner, source)
+		fakeClock.SetTime(fired[i])
+		runner.cron.Entry(id).Job.Run()
+	}
+
+	sent := append(clients[0].Sent(), clients[1].Sent()...)
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+	}
+	if want := dedupEventID(sent[0].Source(), tick); sent[0].ID() != want {
+		t.Errorf("Expected the ID of the scheduled tick %q, got %q", want, sent[0].ID())
+	}
+}
+
+func TestRedisDedupStoreClaim(t *testing.T) {
+	redis := newFakeRedis(t)
+	defer redis.Close()
+
+	store, err := NewRedisDedupStore(fmt.Sprintf("redis://%s", redis.Addr()))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	if claimed, err := store.Claim(ctx, "key", time.Minute); err != nil || !claimed {
+		t.Errorf("Expected the first claim to succeed, got %v, %v", claimed, err)
+	}
+	if claimed, err := store.Claim(ctx, "key", time.Minute); err != nil || claimed {
+		t.Errorf("Expected the second claim to fail, got %v, %v", claimed, err)
+	}
+	if claimed, err := store.Claim(ctx, "other", time.Minute); err != nil || !claimed {
+		t.Errorf("Expected a claim of another key to succeed, got %v, %v", claimed, err)
+	}
+}
+
+func TestRedisDedupStoreClaimValue(t *testing.T) {
+	redis := newFakeRedis(t)
+	defer redis.Close()
+
+	store, err := NewRedisDedupStore(fmt.Sprintf("redis://%s", redis.Addr()))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	valueStore := store.(ValueDedupStore)
+
+	ctx := context.Background()
+	if claimed, _, err := valueStore.ClaimValue(ctx, "key", "first", time.Minute); err != nil || !claimed {
+		t.Errorf("Expected the first claim to succeed, got %v, %v", claimed, err)
+	}
+	if claimed, existing, err := valueStore.ClaimValue(ctx, "key", "second", time.Minute); err != nil || claimed || existing != "first" {
+		t.Errorf("Expected the second claim to fail with the first value, got %v, %q, %v", claimed, existing, err)
+	}
+}
+
+// collidingDedupStore is a ValueDedupStore where all keys are already
+// claimed with value.
+type collidingDedupStore struct {
+	DedupStore
+	value string
+}
+
+func (s *collidingDedupStore) ClaimValue(context.Context, string, string, time.Duration) (bool, string, error) {
+	return false, s.value, nil
+}
+
+func TestDedupCollisionPolicies(t *testing.T) {
+	source := newTestSource()
+
+	testCases := map[string]struct {
+		policy         DedupCollisionPolicy
+		claimedValue   string
+		wantSent       int
+		wantCollisions uint64
+	}{
+		"collision skipped by default": {
+			claimedValue:   "another event",
+			wantCollisions: 1,
+		},
+		"collision skipped": {
+			policy:         DedupCollisionSkip,
+			claimedValue:   "another event",
+			wantCollisions: 1,
+		},
+		"collision sent": {
+			policy:         DedupCollisionSend,
+			claimedValue:   "another event",
+			wantSent:       1,
+			wantCollisions: 1,
+		},
+		"emitted by another replica": {
+			policy:       DedupCollisionSend,
+			claimedValue: dedupDigest(mustMakeEvent(t, source)),
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx),
+				WithDedupStore(&collidingDedupStore{value: tc.claimedValue}), WithDedupCollisionPolicy(tc.policy))
+			runner.cron.Entry(mustAddSchedule(t, runner, source)).Job.Run()
+
+			if got := len(ce.Sent()); got != tc.wantSent {
+				t.Errorf("Expected %d events sent, got %d", tc.wantSent, got)
+			}
+			if got := runner.Stats().DedupCollisions; got != tc.wantCollisions {
+				t.Errorf("Expected %d collisions, got %d", tc.wantCollisions, got)
+			}
+		})
+	}
+}
+
+func TestParseDedupCollisionPolicy(t *testing.T) {
+	for _, s := range []string{"skip", "send"} {
+		if got, err := ParseDedupCollisionPolicy(s); err != nil || string(got) != s {
+			t.Errorf("ParseDedupCollisionPolicy(%q) = (%q, %v), want (%q, nil)", s, got, err, s)
+		}
+	}
+	if _, err := ParseDedupCollisionPolicy("retry"); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
+
+func TestNewRedisDedupStoreInvalidURL(t *testing.T) {
+	for _, u := range []string{"http://redis:6379", "redis://", "://"} {
+		if _, err := NewRedisDedupStore(u); err == nil {
+			t.Errorf("Expected an error for %q", u)
+		}
+	}
+}
+
+func TestDedupEventID(t *testing.T) {
+	tick := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if dedupEventID("a", tick) != dedupEventID("a", tick) {
+		t.Error("Expected the same source and tick to produce the same ID")
+	}
+	if dedupEventID("a", tick) == dedupEventID("b", tick) {
+		t.Error("Expected different sources to produce different IDs")
+	}
+	if dedupEventID("a", tick) == dedupEventID("a", tick.Add(time.Minute)) {
+		t.Error("Expected different ticks to produce different IDs")
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/rickb777/date/period"
+	"go.uber.org/zap"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+)
+
+const (
+	// errorDestExtension tells the dead-lettered events where they failed
+	// to be sent, like the Knative channels do.
+	errorDestExtension = "knativeerrordest"
+	// errorCodeExtension tells the HTTP status code of the last attempt to
+	// send the dead-lettered events, when there is one.
+	errorCodeExtension = "knativeerrorcode"
+)
+
+// deliveryRetries are the retries of the sources setting a delivery.
+type deliveryRetries struct {
+	// retries overrides the retries of the runner when not nil.
+	retries *int
+	policy  eventingduckv1.BackoffPolicyType
+	delay   time.Duration
+}
+
+// newDeliveryRetries returns the retries of spec, nil when it sets none,
+// the runner ones being used.
+func newDeliveryRetries(spec *eventingduckv1.DeliverySpec) (*deliveryRetries, error) {
+	if spec == nil || (spec.Retry == nil && spec.BackoffPolicy == nil && spec.BackoffDelay == nil) {
+		return nil, nil
+	}
+	r := &deliveryRetries{policy: eventingduckv1.BackoffPolicyExponential, delay: retryPeriod}
+	if spec.Retry != nil {
+		retries := int(*spec.Retry)
+		r.retries = &retries
+	}
+	if spec.BackoffPolicy != nil {
+		r.policy = *spec.BackoffPolicy
+	}
+	if spec.BackoffDelay != nil {
+		p, err := period.Parse(*spec.BackoffDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delivery backoff delay: %w", err)
+		}
+		r.delay, _ = p.Duration()
+	}
+	return r, nil
+}
+
+// backoff returns the delay before the retry, counted from 0, like the
+// Knative dispatchers do.
+func (r *deliveryRetries) backoff(retry int) time.Duration {
+	if r.policy == eventingduckv1.BackoffPolicyLinear {
+		return r.delay * time.Duration(retry+1)
+	}
+	return r.delay * time.Duration(math.Exp2(float64(retry+1)))
+}
+
+type deliveryRetriesKey struct{}
+
+// withDeliveryRetries makes send retry the failed requests made with ctx
+// as told by r.
+func withDeliveryRetries(ctx context.Context, r *deliveryRetries) context.Context {
+	return context.WithValue(ctx, deliveryRetriesKey{}, r)
+}
+
+// deliveryRetriesFrom returns the retries of the requests made with ctx,
+// nil for the runner ones.
+func deliveryRetriesFrom(ctx context.Context) *deliveryRetries {
+	r, _ := ctx.Value(deliveryRetriesKey{}).(*deliveryRetries)
+	return r
+}
+
+// reportRetries reports the retries result took, if any.
+func (a *cronJobsRunner) reportRetries(opts jobOptions, result protocol.Result) {
+	var retries *cehttp.RetriesResult
+	if errors.As(result, &retries) && retries.Retries > 0 {
+		a.reporter.ReportEventRetries(opts.stats.namespace, opts.stats.name, retries.Retries)
+	}
+}
+
+// responseCode returns the status code of the last response of the sink,
+// 0 when it didn't respond.
+func responseCode(result protocol.Result) int {
+	// The retries results don't unwrap the last one.
+	var retries *cehttp.RetriesResult
+	if errors.As(result, &retries) {
+		result = retries.Result
+	}
+	var httpResult *cehttp.Result
+	if errors.As(result, &httpResult) {
+		return httpResult.StatusCode
+	}
+	return 0
+}
+
+// deadLetter sends the event failed to be sent to target to the dead
+// letter sink of the source, once. It returns whether the event was
+// dead-lettered.
+func (a *cronJobsRunner) deadLetter(opts jobOptions, target string, event cloudevents.Event, result protocol.Result) bool {
+	if opts.deadLetterSink == "" {
+		return false
+	}
+	event = event.Clone()
+	event.SetExtension(errorDestExtension, target)
+	if code := responseCode(result); code != 0 {
+		event.SetExtension(errorCodeExtension, code)
+	}
+
+	// Like the alerts, the dead-lettered events are sent without the
+	// context of the source.
+	dlsCtx := cloudevents.ContextWithTarget(context.Background(), opts.deadLetterSink)
+	if result := a.send(dlsCtx, opts.client, event); !cloudevents.IsACK(result) {
+		a.Logger.Warnw("failed to send cloudevent to the dead letter sink", zap.Any("result", result),
+			zap.String("source", event.Source()), zap.String("id", event.ID()))
+		return false
+	}
+	a.reporter.ReportEventDeadLettered(opts.stats.namespace, opts.stats.name)
+	return true
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+)
+
+func TestDeliveryBackoff(t *testing.T) {
+	linear := eventingduckv1.BackoffPolicyLinear
+	testCases := map[string]struct {
+		spec *eventingduckv1.DeliverySpec
+		want []time.Duration
+	}{
+		"exponential by default": {
+			spec: &eventingduckv1.DeliverySpec{BackoffDelay: ptr.String("PT0.1S")},
+			want: []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond},
+		},
+		"linear": {
+			spec: &eventingduckv1.DeliverySpec{BackoffPolicy: &linear, BackoffDelay: ptr.String("PT0.1S")},
+			want: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond},
+		},
+		"adapter delay by default": {
+			spec: &eventingduckv1.DeliverySpec{Retry: ptr.Int32(3)},
+			want: []time.Duration{2 * retryPeriod, 4 * retryPeriod, 8 * retryPeriod},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			r, err := newDeliveryRetries(tc.spec)
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			for retry, want := range tc.want {
+				if got := r.backoff(retry); got != want {
+					t.Errorf("Expected the backoff %v before the retry %d, got %v", want, retry, got)
+				}
+			}
+		})
+	}
+
+	if r, err := newDeliveryRetries(&eventingduckv1.DeliverySpec{}); r != nil || err != nil {
+		t.Errorf("Expected no retries without settings, got (%v, %v)", r, err)
+	}
+	if _, err := newDeliveryRetries(&eventingduckv1.DeliverySpec{BackoffDelay: ptr.String("soon")}); err == nil {
+		t.Error("Expected an invalid backoff delay to fail")
+	}
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	unavailable := cehttp.NewResult(503, "%w", protocol.ResultNACK)
+	testCases := map[string]struct {
+		retry       int32
+		results     []protocol.Result
+		wantSends   int32
+		wantRetries int
+		wantSent    int
+	}{
+		"retried": {
+			retry:       3,
+			results:     []protocol.Result{unavailable, unavailable},
+			wantSends:   3,
+			wantRetries: 2,
+			wantSent:    1,
+		},
+		"retries exhausted": {
+			retry:       1,
+			results:     []protocol.Result{unavailable, unavailable},
+			wantSends:   2,
+			wantRetries: 1,
+		},
+		"no retry": {
+			results:   []protocol.Result{unavailable},
+			wantSends: 1,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClientWithResults(tc.results...)
+			client := &countingClient{Client: ce}
+			reporter := newFakeReporter()
+			runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx), WithStatsReporter(reporter))
+
+			source := retrySource()
+			source.Spec.Delivery = &eventingduckv1.DeliverySpec{
+				Retry:        ptr.Int32(tc.retry),
+				BackoffDelay: ptr.String("PT0.001S"),
+			}
+			entryID := mustAddSchedule(t, runner, source)
+			runner.cron.Entry(entryID).Job.Run()
+
+			if got := atomic.LoadInt32(&client.sends); got != tc.wantSends {
+				t.Errorf("Expected %d send attempts, got %d", tc.wantSends, got)
+			}
+			if got := len(ce.Sent()); got != tc.wantSent {
+				t.Errorf("Expected %d events sent, got %d", tc.wantSent, got)
+			}
+			if got := reporter.retries["test-ns/test-name"]; got != tc.wantRetries {
+				t.Errorf("Expected %d retries reported, got %d", tc.wantRetries, got)
+			}
+		})
+	}
+}
+
+func TestDeadLetterSink(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	dlsURI, _ := apis.ParseURL("http://dls.example.com")
+	client := &alertingClient{Client: adaptertesting.NewTestClient(), alertSink: dlsURI.String(), failing: true}
+	reporter := newFakeReporter()
+	runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx), WithStatsReporter(reporter))
+
+	source := retrySource()
+	source.Spec.Delivery = &eventingduckv1.DeliverySpec{Retry: ptr.Int32(0)}
+	source.Status.DeadLetterSinkURI = dlsURI
+	entryID := mustAddSchedule(t, runner, source)
+	runner.cron.Entry(entryID).Job.Run()
+
+	dead := client.Alerts()
+	if len(dead) != 1 {
+		t.Fatal("Expected the failed event to be dead-lettered, got", len(dead))
+	}
+	extensions := dead[0].Extensions()
+	if got, want := extensions[errorDestExtension], apis.HTTP("mysink").String(); got != want {
+		t.Errorf("Expected the error destination %q, got %v", want, got)
+	}
+	if got := fmt.Sprint(extensions[errorCodeExtension]); got != "400" {
+		t.Errorf("Expected the error code 400, got %s", got)
+	}
+	if got := reporter.deadLettered["test-ns/test-name"]; got != 1 {
+		t.Errorf("Expected 1 dead-lettered event reported, got %d", got)
+	}
+	if got := reporter.failed["test-ns/test-name"]; got != 1 {
+		t.Errorf("Expected the event to be reported failed, got %d", got)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DrainState is the state of the jobs of the runner.
+type DrainState struct {
+	// Paused is true once the runner stopped scheduling fires.
+	Paused bool `json:"paused"`
+	// InFlight is the number of jobs still running.
+	InFlight int64 `json:"inFlight"`
+}
+
+// Drain pauses the runner like PauseAll and waits for the jobs in flight up
+// to d, or until they are done when d is not positive. It returns
+// ErrStopTimeout, telling the number of jobs still running, when d is
+// reached first. Unlike StopWithTimeout, it leaves the senders of the
+// runner open, for it to be stopped later on.
+func (a *cronJobsRunner) Drain(d time.Duration) error {
+	a.PauseAll()
+	if !a.drain(d) {
+		return fmt.Errorf("%w after %v: %d jobs still running", ErrStopTimeout, d, atomic.LoadInt64(&a.inflightCount))
+	}
+	return nil
+}
+
+// DrainState returns whether the runner is paused and the number of jobs
+// in flight.
+func (a *cronJobsRunner) DrainState() DrainState {
+	a.pauseMu.RLock()
+	paused := a.paused
+	a.pauseMu.RUnlock()
+	return DrainState{Paused: paused, InFlight: atomic.LoadInt64(&a.inflightCount)}
+}
+
+// drain waits for the jobs in flight to be done. It returns false when
+// timeout is reached first.
+func (a *cronJobsRunner) drain(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		a.inflight.Wait()
+		close(done)
+	}()
+
+	if timeout <= 0 {
+		<-done
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,364 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sync/atomic"
+	"unicode/utf8"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// readEventSchema returns the EventSchema of the given source, reading it
+// from its ConfigMap when not inline. It returns nil when the ConfigMap
+// reference is optional and the ConfigMap or its key is missing.
+func readEventSchema(ctx context.Context, kubeClient kubernetes.Interface, source *sourcesv1beta1.PingSource) (*jsonSchema, error) {
+	spec := source.Spec.EventSchema
+	if spec.ConfigMapKeyRef == nil {
+		return compileSchema([]byte(spec.Inline))
+	}
+
+	ref := spec.ConfigMapKeyRef
+	optional := ref.Optional != nil && *ref.Optional
+	cm, err := kubeClient.CoreV1().ConfigMaps(source.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) && optional {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	data, ok := cm.Data[ref.Key]
+	if !ok {
+		if optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("configmap %s/%s has no %s key", source.Namespace, ref.Name, ref.Key)
+	}
+	return compileSchema([]byte(data))
+}
+
+// jsonSchema is the subset of JSON Schema the event data is validated
+// against: the type, enum, const, object, array, string and number
+// keywords, and the allOf, anyOf, oneOf and not combinations. The other
+// keywords are ignored.
+type jsonSchema struct {
+	Type  schemaTypes     `json:"type"`
+	Enum  []interface{}   `json:"enum"`
+	Const json.RawMessage `json:"const"`
+
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+
+	Items    *jsonSchema `json:"items"`
+	MinItems *int        `json:"minItems"`
+	MaxItems *int        `json:"maxItems"`
+
+	MinLength *int   `json:"minLength"`
+	MaxLength *int   `json:"maxLength"`
+	Pattern   string `json:"pattern"`
+
+	Minimum *float64 `json:"minimum"`
+	Maximum *float64 `json:"maximum"`
+
+	AllOf []*jsonSchema `json:"allOf"`
+	AnyOf []*jsonSchema `json:"anyOf"`
+	OneOf []*jsonSchema `json:"oneOf"`
+	Not   *jsonSchema   `json:"not"`
+
+	// Compiled from the keywords above.
+	constValue  interface{}
+	pattern     *regexp.Regexp
+	noAdditions bool
+	additions   *jsonSchema
+}
+
+// schemaTypes is the type keyword, a type name or a list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*t = schemaTypes{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(b, &names); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = names
+	return nil
+}
+
+// compileSchema parses the given JSON Schema.
+func compileSchema(b []byte) (*jsonSchema, error) {
+	s := &jsonSchema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+	}
+	if err := s.compile(); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+	}
+	return s, nil
+}
+
+func (s *jsonSchema) compile() error {
+	if len(s.Const) > 0 {
+		if err := json.Unmarshal(s.Const, &s.constValue); err != nil {
+			return fmt.Errorf("const: %w", err)
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+		s.pattern = re
+	}
+	if len(s.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(s.AdditionalProperties, &allowed); err == nil {
+			s.noAdditions = !allowed
+		} else if err := json.Unmarshal(s.AdditionalProperties, &s.additions); err != nil {
+			return fmt.Errorf("additionalProperties must be a boolean or a schema")
+		}
+	}
+
+	children := make([]*jsonSchema, 0, len(s.Properties)+len(s.AllOf)+len(s.AnyOf)+len(s.OneOf)+3)
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	children = append(children, s.AllOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+	children = append(children, s.Items, s.Not, s.additions)
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateData validates the given event data, an event without data being
+// validated as null.
+func (s *jsonSchema) ValidateData(data []byte) error {
+	var v interface{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("data is not JSON: %w", err)
+		}
+	}
+	return s.validate(v, "")
+}
+
+func (s *jsonSchema) validate(v interface{}, path string) error {
+	if len(s.Type) > 0 && !s.Type.match(v) {
+		return schemaError(path, "%s is not of type %v", jsonTypeOf(v), []string(s.Type))
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return schemaError(path, "value is not one of the enum")
+		}
+	}
+	if len(s.Const) > 0 && !reflect.DeepEqual(s.constValue, v) {
+		return schemaError(path, "value is not the const")
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return schemaError(path, "missing required property %q", name)
+			}
+		}
+		for name, value := range v {
+			if p, ok := s.Properties[name]; ok {
+				if err := p.validate(value, path+"/"+name); err != nil {
+					return err
+				}
+			} else if s.noAdditions {
+				return schemaError(path, "additional property %q is not allowed", name)
+			} else if s.additions != nil {
+				if err := s.additions.validate(value, path+"/"+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return schemaError(path, "array has fewer than %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return schemaError(path, "array has more than %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			return schemaError(path, "string is shorter than %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			return schemaError(path, "string is longer than %d", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return schemaError(path, "string does not match %q", s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return schemaError(path, "%v is less than %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return schemaError(path, "%v is greater than %v", v, *s.Maximum)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		if err := sub.validate(v, path); err != nil {
+			return err
+		}
+	}
+	if len(s.AnyOf) > 0 && s.matching(s.AnyOf, v, path) == 0 {
+		return schemaError(path, "value matches none of anyOf")
+	}
+	if len(s.OneOf) > 0 && s.matching(s.OneOf, v, path) != 1 {
+		return schemaError(path, "value does not match exactly one of oneOf")
+	}
+	if s.Not != nil && s.Not.validate(v, path) == nil {
+		return schemaError(path, "value matches not")
+	}
+	return nil
+}
+
+// matching returns the number of the given schemas v matches.
+func (s *jsonSchema) matching(schemas []*jsonSchema, v interface{}, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		if sub.validate(v, path) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (t schemaTypes) match(v interface{}) bool {
+	for _, name := range t {
+		switch typ := jsonTypeOf(v); {
+		case name == typ:
+			return true
+		case name == "number" && typ == "integer":
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf returns the JSON Schema type of the decoded JSON value v.
+func jsonTypeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func schemaError(path, format string, args ...interface{}) error {
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...))
+}
+
+// eventSchemaOptions sets opts to validate the event data of source against
+// its event schema, when it has one.
+func (a *cronJobsRunner) eventSchemaOptions(ctx context.Context, source *sourcesv1beta1.PingSource, opts *jobOptions) error {
+	if source.Spec.EventSchema == nil {
+		return nil
+	}
+	schema, err := readEventSchema(ctx, a.kubeClient, source)
+	if err != nil {
+		return newScheduleError(source, ReasonReadFailed, fmt.Errorf("failed to read the event schema: %w", err))
+	}
+	opts.eventSchema = schema
+	if a.invalid != nil {
+		namespace, name := source.Namespace, source.Name
+		opts.onInvalid = func(err error) {
+			a.invalid(namespace, name, err)
+		}
+	}
+	return nil
+}
+
+// validEvent validates the data of the event of a fire against the event
+// schema of opts. It returns false when the event is skipped.
+func (a *cronJobsRunner) validEvent(opts jobOptions, event cloudevents.Event) bool {
+	if opts.eventSchema == nil {
+		return true
+	}
+	err := opts.eventSchema.ValidateData(event.Data())
+	if err == nil {
+		return true
+	}
+	atomic.AddUint64(&opts.stats.invalid, 1)
+	if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+		a.Logger.Errorw("cloudevent does not match the event schema, skipping it", zap.String("source", event.Source()),
+			zap.String("id", event.ID()), zap.Error(err), zap.Int("suppressed", suppressed))
+	}
+	if opts.onInvalid != nil {
+		opts.onInvalid(err)
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+const pingSchema = `{
+	"type": "object",
+	"required": ["count"],
+	"properties": {
+		"count": {"type": "integer", "minimum": 0},
+		"unit": {"enum": ["s", "ms"]},
+		"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "maxItems": 2}
+	},
+	"additionalProperties": false
+}`
+
+func TestJSONSchemaValidateData(t *testing.T) {
+	schema, err := compileSchema([]byte(pingSchema))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	testCases := map[string]struct {
+		data      string
+		wantValid bool
+	}{
+		"valid":               {data: `{"count": 3, "unit": "s", "tags": ["a", "b"]}`, wantValid: true},
+		"minimal":             {data: `{"count": 0}`, wantValid: true},
+		"missing required":    {data: `{"unit": "s"}`},
+		"wrong type":          {data: `{"count": "three"}`},
+		"not an integer":      {data: `{"count": 1.5}`},
+		"below minimum":       {data: `{"count": -1}`},
+		"not in enum":         {data: `{"count": 1, "unit": "h"}`},
+		"too many items":      {data: `{"count": 1, "tags": ["a", "b", "c"]}`},
+		"pattern mismatch":    {data: `{"count": 1, "tags": ["A"]}`},
+		"additional property": {data: `{"count": 1, "extra": true}`},
+		"not an object":       {data: `[1]`},
+		"not JSON":            {data: `{"count":`},
+		"no data":             {},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := schema.ValidateData([]byte(tc.data))
+			if tc.wantValid && err != nil {
+				t.Error("Expected the data to be valid, got", err)
+			}
+			if !tc.wantValid && err == nil {
+				t.Error("Expected the data to be invalid")
+			}
+		})
+	}
+}
+
+func TestJSONSchemaCombinations(t *testing.T) {
+	schema, err := compileSchema([]byte(`{
+		"anyOf": [{"type": "string"}, {"type": "integer"}],
+		"not": {"const": "forbidden"}
+	}`))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for data, wantValid := range map[string]bool{
+		`"ok"`:        true,
+		`42`:          true,
+		`true`:        false,
+		`"forbidden"`: false,
+	} {
+		if err := schema.ValidateData([]byte(data)); (err == nil) != wantValid {
+			t.Errorf("ValidateData(%s) = %v, want valid %v", data, err, wantValid)
+		}
+	}
+}
+
+func TestCompileInvalidSchema(t *testing.T) {
+	for _, schema := range []string{`not json`, `{"type": 1}`, `{"pattern": "("}`, `{"additionalProperties": 1}`} {
+		if _, err := compileSchema([]byte(schema)); err == nil {
+			t.Errorf("Expected an error compiling %s", schema)
+		}
+	}
+}
+
+func TestEventSchemaSkipsInvalidEvents(t *testing.T) {
+	testCases := map[string]struct {
+		data        string
+		wantSent    int
+		wantInvalid uint64
+	}{
+		"valid payload": {
+			data:     `{"count": 1}`,
+			wantSent: 1,
+		},
+		"malformed payload": {
+			data:        `{"count": "one"}`,
+			wantInvalid: 1,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			var mu sync.Mutex
+			var degraded []string
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx),
+				WithInvalidEventHandler(func(namespace, name string, err error) {
+					mu.Lock()
+					defer mu.Unlock()
+					degraded = append(degraded, namespace+"/"+name)
+				}))
+
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.JsonData = tc.data
+				s.Spec.EventSchema = &sourcesv1beta1.PingSourceEventSchema{Inline: pingSchema}
+			}))
+			runner.cron.Entry(entryID).Job.Run()
+
+			if got := len(ce.Sent()); got != tc.wantSent {
+				t.Errorf("Expected %d events sent, got %d", tc.wantSent, got)
+			}
+			if got := runner.Stats().Sources["test-ns/test-name"].Invalid; got != tc.wantInvalid {
+				t.Errorf("Expected %d invalid events, got %d", tc.wantInvalid, got)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if tc.wantInvalid > 0 && (len(degraded) != 1 || degraded[0] != "test-ns/test-name") {
+				t.Errorf("Expected the source to be degraded, got %v", degraded)
+			}
+			if tc.wantInvalid == 0 && len(degraded) > 0 {
+				t.Errorf("Expected the source not to be degraded, got %v", degraded)
+			}
+		})
+	}
+}
+
+func TestEventSchemaFromConfigMap(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	kubeClient := kubeclient.Get(ctx)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeClient, logging.FromContext(ctx))
+
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.JsonData = `{"count": "one"}`
+		s.Spec.EventSchema = &sourcesv1beta1.PingSourceEventSchema{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "schemas"},
+				Key:                  "ping",
+			},
+		}
+	})
+
+	if id, err := runner.AddSchedule(source); err == nil {
+		t.Error("Expected a source with a missing schema not to be scheduled, got entry", id)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "schemas",
+			Namespace: "test-ns",
+		},
+		Data: map[string]string{"ping": pingSchema},
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps("test-ns").Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create configmap:", err)
+	}
+	entryID := mustAddSchedule(t, runner, source)
+	if entryID == 0 {
+		t.Fatal("Expected the source to be scheduled")
+	}
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected the malformed event to be skipped, got %d sent", got)
+	}
+}
@@ -0,0 +1,618 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// fireCondition is a compiled FireCondition, the subset of CEL gating the
+// fires: int, bool and string literals, the fireCount and now variables, the
+// arithmetic, comparison, logical and conditional operators, and the get*
+// accessors of the now timestamp, optionally given a time zone.
+type fireCondition struct {
+	expr string
+	eval celEval
+}
+
+// fireVars are the variables a fireCondition is evaluated over.
+type fireVars struct {
+	// fireCount is the number of the fire, starting at 1.
+	fireCount int64
+	// now is the time of the fire.
+	now time.Time
+}
+
+type celType int
+
+const (
+	celInt celType = iota
+	celBool
+	celString
+	celTimestamp
+)
+
+func (t celType) String() string {
+	return [...]string{"int", "bool", "string", "timestamp"}[t]
+}
+
+type celEval func(vars *fireVars) (interface{}, error)
+
+// celNode is a type checked expression.
+type celNode struct {
+	typ  celType
+	eval celEval
+}
+
+var errDivisionByZero = errors.New("division by zero")
+
+// compileFireCondition parses and type checks the given expression, which
+// must be a bool.
+func compileFireCondition(expr string) (*fireCondition, error) {
+	tokens, err := celLex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &celParser{tokens: tokens}
+	n, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != celEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+	if n.typ != celBool {
+		return nil, fmt.Errorf("condition is of type %s, not bool", n.typ)
+	}
+	return &fireCondition{expr: expr, eval: n.eval}, nil
+}
+
+// Eval returns whether the fire described by vars is to be emitted.
+func (c *fireCondition) Eval(vars fireVars) (bool, error) {
+	v, err := c.eval(&vars)
+	if err != nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
+
+type celTokenKind int
+
+const (
+	celEOF celTokenKind = iota
+	celIdent
+	celIntLit
+	celStringLit
+	celOp
+)
+
+type celToken struct {
+	kind celTokenKind
+	text string
+	pos  int
+	// value is the value of the literals.
+	value interface{}
+}
+
+// celOps are the operators, the two-character ones first.
+var celOps = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", ".", ",", "?", ":"}
+
+func celLex(expr string) ([]celToken, error) {
+	var tokens []celToken
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(expr) && (expr[j] == '_' || unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j]))) {
+				j++
+			}
+			tokens = append(tokens, celToken{kind: celIdent, text: expr[i:j], pos: i})
+			i = j
+		case unicode.IsDigit(c):
+			j := i + 1
+			for j < len(expr) && unicode.IsDigit(rune(expr[j])) {
+				j++
+			}
+			v, err := strconv.ParseInt(expr[i:j], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid int literal %q at offset %d", expr[i:j], i)
+			}
+			tokens = append(tokens, celToken{kind: celIntLit, text: expr[i:j], pos: i, value: v})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(expr) && rune(expr[j]) != c {
+				if expr[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			lit := expr[i : j+1]
+			if c == '\'' {
+				lit = `"` + strings.NewReplacer(`"`, `\"`, `\'`, `'`).Replace(expr[i+1:j]) + `"`
+			}
+			v, err := strconv.Unquote(lit)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string literal at offset %d", i)
+			}
+			tokens = append(tokens, celToken{kind: celStringLit, text: expr[i : j+1], pos: i, value: v})
+			i = j + 1
+		default:
+			op := ""
+			for _, o := range celOps {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			tokens = append(tokens, celToken{kind: celOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, celToken{kind: celEOF, text: "end of expression", pos: len(expr)}), nil
+}
+
+type celParser struct {
+	tokens []celToken
+	next   int
+}
+
+func (p *celParser) peek() celToken {
+	return p.tokens[p.next]
+}
+
+// accept consumes the next token when it is the operator op.
+func (p *celParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == celOp && tok.text == op {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *celParser) expect(op string) error {
+	if !p.accept(op) {
+		tok := p.peek()
+		return fmt.Errorf("expected %q, got %q at offset %d", op, tok.text, tok.pos)
+	}
+	return nil
+}
+
+// ternary parses cond ? a : b, the lowest precedence expression.
+func (p *celParser) ternary() (*celNode, error) {
+	cond, err := p.or()
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+	a, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	b, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if cond.typ != celBool {
+		return nil, fmt.Errorf("condition of ?: is of type %s, not bool", cond.typ)
+	}
+	if a.typ != b.typ {
+		return nil, fmt.Errorf("branches of ?: are of types %s and %s", a.typ, b.typ)
+	}
+	return &celNode{typ: a.typ, eval: func(vars *fireVars) (interface{}, error) {
+		c, err := cond.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		if c.(bool) {
+			return a.eval(vars)
+		}
+		return b.eval(vars)
+	}}, nil
+}
+
+func (p *celParser) or() (*celNode, error) {
+	return p.logical("||", p.and, true)
+}
+
+func (p *celParser) and() (*celNode, error) {
+	return p.logical("&&", p.relation, false)
+}
+
+// logical parses the short-circuiting operator op, whose result is short
+// when an operand evaluates to it.
+func (p *celParser) logical(op string, operand func() (*celNode, error), short bool) (*celNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(op) {
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if left.typ != celBool || right.typ != celBool {
+			return nil, fmt.Errorf("no such overload: %s %s %s", left.typ, op, right.typ)
+		}
+		l := left
+		left = &celNode{typ: celBool, eval: func(vars *fireVars) (interface{}, error) {
+			v, err := l.eval(vars)
+			if err != nil {
+				return nil, err
+			}
+			if v.(bool) == short {
+				return short, nil
+			}
+			return right.eval(vars)
+		}}
+	}
+	return left, nil
+}
+
+func (p *celParser) relation() (*celNode, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != celOp {
+			return left, nil
+		}
+		op := tok.text
+		switch op {
+		case "==", "!=", "<", "<=", ">", ">=":
+		default:
+			return left, nil
+		}
+		p.next++
+		right, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		if left.typ != right.typ || (op != "==" && op != "!=" && left.typ == celBool) {
+			return nil, fmt.Errorf("no such overload: %s %s %s", left.typ, op, right.typ)
+		}
+		left = binary(celBool, left, right, func(a, b interface{}) (interface{}, error) {
+			return compare(op, a, b), nil
+		})
+	}
+}
+
+func (p *celParser) additive() (*celNode, error) {
+	return p.arithmetic(p.multiplicative, "+", "-")
+}
+
+func (p *celParser) multiplicative() (*celNode, error) {
+	return p.arithmetic(p.unary, "*", "/", "%")
+}
+
+func (p *celParser) arithmetic(operand func() (*celNode, error), ops ...string) (*celNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, o := range ops {
+			if p.accept(o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case op == "+" && left.typ == celString && right.typ == celString:
+			left = binary(celString, left, right, func(a, b interface{}) (interface{}, error) {
+				return a.(string) + b.(string), nil
+			})
+		case left.typ == celInt && right.typ == celInt:
+			left = binary(celInt, left, right, func(a, b interface{}) (interface{}, error) {
+				return intOp(op, a.(int64), b.(int64))
+			})
+		default:
+			return nil, fmt.Errorf("no such overload: %s %s %s", left.typ, op, right.typ)
+		}
+	}
+}
+
+func (p *celParser) unary() (*celNode, error) {
+	switch {
+	case p.accept("!"):
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if n.typ != celBool {
+			return nil, fmt.Errorf("no such overload: !%s", n.typ)
+		}
+		return &celNode{typ: celBool, eval: func(vars *fireVars) (interface{}, error) {
+			v, err := n.eval(vars)
+			if err != nil {
+				return nil, err
+			}
+			return !v.(bool), nil
+		}}, nil
+	case p.accept("-"):
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if n.typ != celInt {
+			return nil, fmt.Errorf("no such overload: -%s", n.typ)
+		}
+		return &celNode{typ: celInt, eval: func(vars *fireVars) (interface{}, error) {
+			v, err := n.eval(vars)
+			if err != nil {
+				return nil, err
+			}
+			return -v.(int64), nil
+		}}, nil
+	}
+	return p.member()
+}
+
+// member parses a primary expression followed by method calls.
+func (p *celParser) member() (*celNode, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(".") {
+		tok := p.peek()
+		if tok.kind != celIdent {
+			return nil, fmt.Errorf("expected a method name, got %q at offset %d", tok.text, tok.pos)
+		}
+		p.next++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var args []*celNode
+		if !p.accept(")") {
+			for {
+				arg, err := p.ternary()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.accept(")") {
+					break
+				}
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if n, err = timestampMethod(n, tok.text, args); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+func (p *celParser) primary() (*celNode, error) {
+	tok := p.peek()
+	p.next++
+	switch tok.kind {
+	case celIntLit:
+		return constant(celInt, tok.value), nil
+	case celStringLit:
+		return constant(celString, tok.value), nil
+	case celIdent:
+		switch tok.text {
+		case "true", "false":
+			return constant(celBool, tok.text == "true"), nil
+		case "fireCount":
+			return &celNode{typ: celInt, eval: func(vars *fireVars) (interface{}, error) {
+				return vars.fireCount, nil
+			}}, nil
+		case "now":
+			return &celNode{typ: celTimestamp, eval: func(vars *fireVars) (interface{}, error) {
+				return vars.now, nil
+			}}, nil
+		}
+		return nil, fmt.Errorf("undeclared reference to %q", tok.text)
+	case celOp:
+		if tok.text == "(" {
+			n, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+}
+
+// timestampAccessors are the CEL timestamp accessors. Like in CEL, the day
+// of the month, month and day of the year start at 0, and the day of the
+// week at 0 for Sunday.
+var timestampAccessors = map[string]func(t time.Time) int64{
+	"getFullYear":   func(t time.Time) int64 { return int64(t.Year()) },
+	"getMonth":      func(t time.Time) int64 { return int64(t.Month()) - 1 },
+	"getDayOfYear":  func(t time.Time) int64 { return int64(t.YearDay()) - 1 },
+	"getDayOfMonth": func(t time.Time) int64 { return int64(t.Day()) - 1 },
+	"getDate":       func(t time.Time) int64 { return int64(t.Day()) },
+	"getDayOfWeek":  func(t time.Time) int64 { return int64(t.Weekday()) },
+	"getHours":      func(t time.Time) int64 { return int64(t.Hour()) },
+	"getMinutes":    func(t time.Time) int64 { return int64(t.Minute()) },
+	"getSeconds":    func(t time.Time) int64 { return int64(t.Second()) },
+}
+
+// timestampMethod returns the call of the accessor name on the timestamp n,
+// in UTC or in the time zone given as argument.
+func timestampMethod(n *celNode, name string, args []*celNode) (*celNode, error) {
+	accessor, ok := timestampAccessors[name]
+	if !ok || n.typ != celTimestamp || len(args) > 1 || (len(args) == 1 && args[0].typ != celString) {
+		types := make([]string, 0, len(args))
+		for _, arg := range args {
+			types = append(types, arg.typ.String())
+		}
+		return nil, fmt.Errorf("no such overload: %s.%s(%s)", n.typ, name, strings.Join(types, ", "))
+	}
+	if len(args) == 0 {
+		return &celNode{typ: celInt, eval: func(vars *fireVars) (interface{}, error) {
+			t, err := n.eval(vars)
+			if err != nil {
+				return nil, err
+			}
+			return accessor(t.(time.Time).UTC()), nil
+		}}, nil
+	}
+	zone := args[0]
+	return &celNode{typ: celInt, eval: func(vars *fireVars) (interface{}, error) {
+		t, err := n.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		name, err := zone.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		loc, err := time.LoadLocation(name.(string))
+		if err != nil {
+			return nil, err
+		}
+		return accessor(t.(time.Time).In(loc)), nil
+	}}, nil
+}
+
+func constant(typ celType, v interface{}) *celNode {
+	return &celNode{typ: typ, eval: func(*fireVars) (interface{}, error) { return v, nil }}
+}
+
+// binary returns the node applying op to the values of left and right.
+func binary(typ celType, left, right *celNode, op func(a, b interface{}) (interface{}, error)) *celNode {
+	return &celNode{typ: typ, eval: func(vars *fireVars) (interface{}, error) {
+		a, err := left.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		b, err := right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		return op(a, b)
+	}}
+}
+
+func intOp(op string, a, b int64) (interface{}, error) {
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, errDivisionByZero
+		}
+		return a / b, nil
+	default:
+		if b == 0 {
+			return nil, errDivisionByZero
+		}
+		return a % b, nil
+	}
+}
+
+// compare applies the comparison op to the values a and b of the same type.
+func compare(op string, a, b interface{}) bool {
+	var c int
+	switch a := a.(type) {
+	case int64:
+		c = cmpInt(a, b.(int64))
+	case string:
+		c = strings.Compare(a, b.(string))
+	case time.Time:
+		c = cmpInt(a.UnixNano(), b.(time.Time).UnixNano())
+	case bool:
+		if a != b.(bool) {
+			c = 1
+		}
+	}
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func cmpInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// shouldFire evaluates the fire condition of opts for the fireCount-th fire
+// of source, happening at now. It returns false when the fire is skipped.
+func (a *cronJobsRunner) shouldFire(opts jobOptions, fireCount uint64, now time.Time, source string) bool {
+	if opts.condition == nil {
+		return true
+	}
+	emit, err := opts.condition.Eval(fireVars{fireCount: int64(fireCount), now: now})
+	if err == nil && emit {
+		return true
+	}
+	atomic.AddUint64(&opts.stats.skipped, 1)
+	if err != nil {
+		if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+			a.Logger.Errorw("failed to evaluate the fire condition, skipping fire", zap.String("source", source),
+				zap.Error(err), zap.Int("suppressed", suppressed))
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+	"time"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestFireConditionEval(t *testing.T) {
+	// A Tuesday, at 10:30:15 UTC.
+	now := time.Date(2020, time.November, 3, 10, 30, 15, 0, time.UTC)
+
+	testCases := map[string]struct {
+		expr      string
+		fireCount int64
+		want      bool
+	}{
+		"even fire":          {expr: "fireCount % 2 == 0", fireCount: 4, want: true},
+		"odd fire":           {expr: "fireCount % 2 == 0", fireCount: 3},
+		"arithmetic":         {expr: "fireCount * 2 - 1 == 5 && -fireCount < 0", fireCount: 3, want: true},
+		"precedence":         {expr: "1 + 2 * 3 == 7 && (1 + 2) * 3 == 9", want: true},
+		"or":                 {expr: "fireCount > 10 || fireCount == 1", fireCount: 1, want: true},
+		"not":                {expr: "!(fireCount <= 1)", fireCount: 2, want: true},
+		"short circuit":      {expr: "fireCount == 0 || 1 / fireCount == 1", fireCount: 0, want: true},
+		"conditional":        {expr: "fireCount > 2 ? fireCount % 2 == 0 : true", fireCount: 1, want: true},
+		"business hours":     {expr: "now.getHours() >= 9 && now.getHours() < 17", want: true},
+		"weekday":            {expr: "now.getDayOfWeek() == 2", want: true},
+		"zero-based date":    {expr: "now.getMonth() == 10 && now.getDayOfMonth() == 2 && now.getDate() == 3", want: true},
+		"minutes and year":   {expr: "now.getMinutes() == 30 && now.getSeconds() == 15 && now.getFullYear() == 2020", want: true},
+		"time zone":          {expr: "now.getHours('Asia/Tokyo') == 19", want: true},
+		"double quoted zone": {expr: `now.getHours("America/New_York") == 5`, want: true},
+		"strings":            {expr: `"a" + 'b' == "ab" && "a" < "b"`, want: true},
+		"bool equality":      {expr: "(fireCount == 1) != false", fireCount: 1, want: true},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			condition, err := compileFireCondition(tc.expr)
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			got, err := condition.Eval(fireVars{fireCount: tc.fireCount, now: now})
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if got != tc.want {
+				t.Errorf("Eval(%s) = %v, want %v", tc.expr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFireConditionEvalError(t *testing.T) {
+	for _, expr := range []string{"10 / (fireCount - 1) > 0", "fireCount % 0 == 0", "now.getHours('Nowhere/Land') == 0"} {
+		condition, err := compileFireCondition(expr)
+		if err != nil {
+			t.Fatalf("Unexpected error compiling %s: %v", expr, err)
+		}
+		if _, err := condition.Eval(fireVars{fireCount: 1, now: time.Now()}); err == nil {
+			t.Errorf("Expected an error evaluating %s", expr)
+		}
+	}
+}
+
+func TestCompileInvalidFireCondition(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"fireCount",
+		"fireCount % 2",
+		"fireCount == ",
+		"fireCount == 'one'",
+		"fireCount && true",
+		"unknown == 1",
+		"now.getHours(1) == 1",
+		"now.getWeek() == 1",
+		"fireCount.getHours() == 1",
+		"true ? 1 : false",
+		"(fireCount == 1",
+		"fireCount == 1)",
+		"'unterminated == 1",
+		"fireCount # 2",
+	} {
+		if _, err := compileFireCondition(expr); err == nil {
+			t.Errorf("Expected an error compiling %q", expr)
+		}
+	}
+}
+
+func TestFireConditionSkipsFires(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.FireCondition = "fireCount % 2 == 0"
+	})
+	entryID := mustAddSchedule(t, runner, source)
+	job := runner.cron.Entry(entryID).Job
+	for i := 0; i < 4; i++ {
+		job.Run()
+	}
+
+	if got := len(ce.Sent()); got != 2 {
+		t.Errorf("Expected every other fire to be sent, got %d events", got)
+	}
+	stats := runner.Stats().Sources["test-ns/test-name"]
+	if stats.Skipped != 2 || stats.Sent != 2 {
+		t.Errorf("Expected 2 fires skipped and 2 sent, got %+v", stats)
+	}
+
+	source.Spec.FireCondition = "fireCount %"
+	if id, err := runner.AddSchedule(source); err == nil {
+		t.Error("Expected a source with an invalid fire condition not to be scheduled, got entry", id)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// fireOffset returns the delay of the fires of the source namespace/name
+// within window. It only depends on its arguments, for the fires of a
+// source to keep the same delay across restarts.
+func fireOffset(namespace, name string, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(namespace + "/" + name))
+	return time.Duration(h.Sum64() % uint64(window))
+}
+
+// delayFire waits for the offset of the fire of the source, fired at the
+// given time, within its jitter window. The window is narrowed to the next
+// fire of the source, the delay never pushing a fire past it. It returns
+// false when the runner is paused meanwhile, the fire being dropped.
+func (a *cronJobsRunner) delayFire(opts jobOptions, fired time.Time) bool {
+	window := opts.fireJitter
+	if window <= 0 {
+		return true
+	}
+	if opts.schedule != nil {
+		if next := opts.schedule.Next(fired); !next.IsZero() && next.Sub(fired) < window {
+			window = next.Sub(fired)
+		}
+	}
+	delay := fireOffset(opts.namespace, opts.name, window) - a.clock.Since(fired)
+	if delay <= 0 {
+		return true
+	}
+	select {
+	case <-a.clock.After(delay):
+		return true
+	case <-a.pausedCh:
+		return false
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestFireOffset(t *testing.T) {
+	const window = 30 * time.Second
+
+	first := fireOffset("test-ns", "first", window)
+	second := fireOffset("test-ns", "second", window)
+	if first == second {
+		t.Errorf("Expected the sources to get different offsets, both got %v", first)
+	}
+	if again := fireOffset("test-ns", "first", window); again != first {
+		t.Errorf("Expected a stable offset %v, got %v", first, again)
+	}
+
+	for i := 0; i < 100; i++ {
+		if offset := fireOffset("test-ns", fmt.Sprint("source-", i), window); offset < 0 || offset >= window {
+			t.Errorf("Expected the offset of source-%d within %v, got %v", i, window, offset)
+		}
+	}
+	if offset := fireOffset("test-ns", "first", 0); offset != 0 {
+		t.Error("Expected no offset without a window, got", offset)
+	}
+}
+
+func jitterSource(name, schedule string, annotations map[string]string) *sourcesv1beta1.PingSource {
+	return newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Name = name
+		s.Spec.Schedule = schedule
+		s.Status.SinkURI = apis.HTTP("mysink")
+		s.Annotations = annotations
+	})
+}
+
+func TestFireJitter(t *testing.T) {
+	const window = 30 * time.Second
+	testCases := map[string]struct {
+		schedule    string
+		annotations map[string]string
+		wantDelay   time.Duration
+	}{
+		"delayed": {
+			schedule:  "* * * * ?",
+			wantDelay: fireOffset("test-ns", "test-name", window),
+		},
+		"bounded by the next fire": {
+			schedule:  "CRON_TZ=UTC */5 * * * * *",
+			wantDelay: fireOffset("test-ns", "test-name", 5*time.Second),
+		},
+		"precise timing": {
+			schedule:    "* * * * ?",
+			annotations: map[string]string{PreciseTimingAnnotation: "true"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithFireJitter(window))
+			fakeClock := clock.NewFakeClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+			runner.clock = fakeClock
+
+			entryID := mustAddSchedule(t, runner, jitterSource("test-name", tc.schedule, tc.annotations))
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				runner.cron.Entry(entryID).Job.Run()
+			}()
+
+			if tc.wantDelay > 0 {
+				if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+					return fakeClock.HasWaiters(), nil
+				}); err != nil {
+					t.Fatal("The fire was never delayed")
+				}
+				fakeClock.Step(tc.wantDelay - time.Nanosecond)
+				if got := len(ce.Sent()); got != 0 || !fakeClock.HasWaiters() {
+					t.Fatalf("Expected the fire to be delayed by %v, got %d events sent", tc.wantDelay, got)
+				}
+				fakeClock.Step(time.Nanosecond)
+			}
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("The fire never ended")
+			}
+			if got := len(ce.Sent()); got != 1 {
+				t.Errorf("Expected the event to be sent, got %d", got)
+			}
+		})
+	}
+}
+
+func TestFireJitterStop(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithFireJitter(time.Hour))
+	fakeClock := clock.NewFakeClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	runner.clock = fakeClock
+
+	entryID := mustAddSchedule(t, runner, jitterSource("test-name", "0 * * * *", nil))
+	go runner.cron.Entry(entryID).Job.Run()
+
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatal("The fire was never delayed")
+	}
+	if err := runner.StopWithTimeout(5 * time.Second); err != nil {
+		t.Fatal("Expected the stop to interrupt the delay, got", err)
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event sent, got %d", got)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// healthPath serves the drain state of the adapter, failing once it
+	// stopped firing.
+	healthPath = "/healthz"
+	// drainPath stops the firing, and returns once the jobs in flight are
+	// done, for the preStop hook of the pods to let them send their events
+	// before the adapter is terminated.
+	drainPath = "/drain"
+)
+
+// The states of the adapter served by healthPath and drainPath.
+const (
+	healthRunning  = "running"
+	healthDraining = "draining"
+	healthDrained  = "drained"
+)
+
+// healthStatus is the body served by healthPath and drainPath.
+type healthStatus struct {
+	State string `json:"state"`
+	DrainState
+}
+
+// newHealthHandler returns the handler of healthPath and drainPath, the
+// drains waiting for the jobs in flight up to drainTimeout, or until they
+// are done when not positive.
+func newHealthHandler(runner CronJobRunner, drainTimeout time.Duration, logger *zap.SugaredLogger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		writeHealthStatus(w, runner.DrainState(), logger)
+	})
+	mux.HandleFunc(drainPath, func(w http.ResponseWriter, r *http.Request) {
+		logger.Info("Drain requested, pausing all schedules")
+		if err := runner.Drain(drainTimeout); err != nil {
+			logger.Warnw("Drain timeout reached", zap.Error(err))
+		}
+		writeHealthStatus(w, runner.DrainState(), logger)
+	})
+	return mux
+}
+
+// writeHealthStatus writes the status of the state, unavailable once the
+// adapter stopped firing.
+func writeHealthStatus(w http.ResponseWriter, state DrainState, logger *zap.SugaredLogger) {
+	status := healthStatus{State: healthRunning, DrainState: state}
+	code := http.StatusOK
+	switch {
+	case state.Paused && state.InFlight > 0:
+		status.State = healthDraining
+		code = http.StatusServiceUnavailable
+	case state.Paused:
+		status.State = healthDrained
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		logger.Debugw("Failed to write the health status", zap.Error(err))
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type drainingRunner struct {
+	CronJobRunner
+	state        DrainState
+	drainTimeout time.Duration
+}
+
+func (r *drainingRunner) Drain(d time.Duration) error {
+	r.drainTimeout = d
+	r.state = DrainState{Paused: true}
+	return nil
+}
+
+func (r *drainingRunner) DrainState() DrainState {
+	return r.state
+}
+
+func TestHealthHandler(t *testing.T) {
+	testCases := map[string]struct {
+		state    DrainState
+		path     string
+		wantCode int
+		want     healthStatus
+	}{
+		"running": {
+			state:    DrainState{InFlight: 2},
+			path:     healthPath,
+			wantCode: http.StatusOK,
+			want:     healthStatus{State: healthRunning, DrainState: DrainState{InFlight: 2}},
+		},
+		"draining": {
+			state:    DrainState{Paused: true, InFlight: 1},
+			path:     healthPath,
+			wantCode: http.StatusServiceUnavailable,
+			want:     healthStatus{State: healthDraining, DrainState: DrainState{Paused: true, InFlight: 1}},
+		},
+		"drained": {
+			state:    DrainState{Paused: true},
+			path:     healthPath,
+			wantCode: http.StatusServiceUnavailable,
+			want:     healthStatus{State: healthDrained, DrainState: DrainState{Paused: true}},
+		},
+		"drain": {
+			state:    DrainState{InFlight: 3},
+			path:     drainPath,
+			wantCode: http.StatusServiceUnavailable,
+			want:     healthStatus{State: healthDrained, DrainState: DrainState{Paused: true}},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			runner := &drainingRunner{state: tc.state}
+			handler := newHealthHandler(runner, 5*time.Second, zap.NewNop().Sugar())
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if w.Code != tc.wantCode {
+				t.Errorf("Expected status %d, got %d", tc.wantCode, w.Code)
+			}
+			var got healthStatus
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal("Failed to read the status:", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected status %+v, got %+v", tc.want, got)
+			}
+			if tc.path == drainPath && runner.drainTimeout != 5*time.Second {
+				t.Errorf("Expected the drain to wait up to 5s, got %v", runner.drainTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// JitterStrategy tells how the exponential retry backoff is randomized, so
+// that the sources failing together don't retry in lockstep.
+type JitterStrategy string
+
+const (
+	// JitterNone waits the exponential backoff as is.
+	JitterNone JitterStrategy = "none"
+	// JitterFull waits a random delay within [0, backoff).
+	JitterFull JitterStrategy = "full"
+	// JitterEqual waits half the backoff plus a random delay within
+	// [0, backoff/2).
+	JitterEqual JitterStrategy = "equal"
+)
+
+const (
+	// retryPeriod is the base of the exponential retry backoff.
+	retryPeriod = 50 * time.Millisecond
+	// retryMaxTries is the maximum number of retries of a send.
+	retryMaxTries = 5
+)
+
+// ParseJitterStrategy returns the jitter strategy named s.
+func ParseJitterStrategy(s string) (JitterStrategy, error) {
+	switch strategy := JitterStrategy(s); strategy {
+	case JitterNone, JitterFull, JitterEqual:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("invalid jitter strategy %q, expected one of %q, %q or %q", s, JitterNone, JitterFull, JitterEqual)
+}
+
+// jitterBackoff randomizes the backoff d according to strategy, using r, a
+// random number in [0, 1).
+func jitterBackoff(strategy JitterStrategy, d time.Duration, r float64) time.Duration {
+	switch strategy {
+	case JitterFull:
+		return time.Duration(float64(d) * r)
+	case JitterEqual:
+		return d/2 + time.Duration(float64(d/2)*r)
+	default:
+		return d
+	}
+}
+
+type jitteredRetriesKey struct{}
+
+// withJitteredRetries makes send retry the failed requests itself, waiting
+// a jittered exponential backoff between attempts, traced when the runner
+// has attempt spans.
+func withJitteredRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, jitteredRetriesKey{}, true)
+}
+
+// hasJitteredRetries tells whether send retries the requests made with ctx.
+func hasJitteredRetries(ctx context.Context) bool {
+	v, _ := ctx.Value(jitteredRetriesKey{}).(bool)
+	return v
+}
+
+type retryDeadlineKey struct{}
+
+// withRetryDeadline makes send give up retrying the failed requests made
+// with ctx rather than waiting past deadline, the next fire of the source.
+func withRetryDeadline(ctx context.Context, deadline time.Time) context.Context {
+	return context.WithValue(ctx, retryDeadlineKey{}, deadline)
+}
+
+// retryDeadlineFrom returns the deadline of the retries made with ctx.
+func retryDeadlineFrom(ctx context.Context) (time.Time, bool) {
+	deadline, ok := ctx.Value(retryDeadlineKey{}).(time.Time)
+	return deadline, ok
+}
+
+// sendWithRetries sends the event through client, retrying the failures
+// worth it, up to retryMaxTries times unless bounded otherwise, by the
+// runner or the delivery of the source. It gives up once the retry deadline
+// or retryMaxElapsed would be passed, or the runner is stopped.
+func (a *cronJobsRunner) sendWithRetries(ctx context.Context, client cloudevents.Client, event cloudevents.Event) protocol.Result {
+	maxRetries := retryMaxTries
+	if a.retryMaxAttempts > 0 {
+		maxRetries = a.retryMaxAttempts - 1
+	}
+	delivery := deliveryRetriesFrom(ctx)
+	if delivery != nil && delivery.retries != nil {
+		maxRetries = *delivery.retries
+	}
+	deadline, bounded := retryDeadlineFrom(ctx)
+	if a.retryMaxElapsed > 0 {
+		if elapsed := a.clock.Now().Add(a.retryMaxElapsed); !bounded || elapsed.Before(deadline) {
+			deadline, bounded = elapsed, true
+		}
+	}
+
+	start := time.Now()
+	var attempts []protocol.Result
+	for retry := 0; ; retry++ {
+		result := a.sendAttempt(ctx, client, event, retry+1)
+		if cloudevents.IsACK(result) || !retryable(result) || retry >= maxRetries {
+			return cehttp.NewRetriesResult(result, retry, start, attempts)
+		}
+		attempts = append(attempts, result)
+
+		// Same exponential backoff as the CloudEvents SDK, randomized.
+		backoff := retryPeriod * time.Duration(math.Exp2(float64(retry+1)))
+		if delivery != nil {
+			backoff = delivery.backoff(retry)
+		}
+		backoff = jitterBackoff(a.jitter, backoff, a.rand())
+		if bounded && a.clock.Now().Add(backoff).After(deadline) {
+			return cehttp.NewRetriesResult(result, retry, start, attempts)
+		}
+		select {
+		case <-ctx.Done():
+			return cehttp.NewRetriesResult(result, retry, start, attempts)
+		case <-a.pausedCh:
+			return cehttp.NewRetriesResult(result, retry, start, attempts)
+		case <-a.clock.After(backoff):
+		}
+	}
+}
+
+// retryable tells whether the send failure is worth retrying: the
+// connection errors, the server errors and the HTTP errors the CloudEvents
+// HTTP protocol retries are, the other HTTP errors aren't.
+func retryable(result error) bool {
+	var httpResult *cehttp.Result
+	if errors.As(result, &httpResult) {
+		switch code := httpResult.StatusCode; {
+		case code >= 500, code == 404, code == 425, code == 429:
+			return true
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestParseJitterStrategy(t *testing.T) {
+	for _, s := range []string{"none", "full", "equal"} {
+		if got, err := ParseJitterStrategy(s); err != nil || string(got) != s {
+			t.Errorf("ParseJitterStrategy(%q) = (%q, %v), want (%q, nil)", s, got, err, s)
+		}
+	}
+	if _, err := ParseJitterStrategy("decorrelated"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestJitterBackoff(t *testing.T) {
+	base := time.Second
+	testCases := map[JitterStrategy]struct {
+		min, max time.Duration
+	}{
+		JitterNone:  {min: base, max: base},
+		JitterFull:  {min: 0, max: base},
+		JitterEqual: {min: base / 2, max: base},
+	}
+	for strategy, tc := range testCases {
+		t.Run(string(strategy), func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				r := rand.Float64() //nolint:gosec // Cryptographic randomness not necessary here.
+				if got := jitterBackoff(strategy, base, r); got < tc.min || got > tc.max {
+					t.Fatalf("jitterBackoff(%q, %v, %v) = %v, want within [%v, %v]", strategy, base, r, got, tc.min, tc.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryJitter(t *testing.T) {
+	testCases := map[JitterStrategy][]time.Duration{
+		// The SDK backoff of 50ms * 2^tries, randomized with 0.5.
+		JitterNone:  {100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond},
+		JitterFull:  {50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond},
+		JitterEqual: {75 * time.Millisecond, 150 * time.Millisecond, 300 * time.Millisecond},
+	}
+	for strategy, delays := range testCases {
+		t.Run(string(strategy), func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			unavailable := cehttp.NewResult(503, "%w", protocol.ResultNACK)
+			ceClient := adaptertesting.NewTestClientWithResults(unavailable, unavailable, unavailable)
+			runner := NewCronJobsRunner(ceClient, kubeclient.Get(ctx), logging.FromContext(ctx), WithRetryJitter(strategy))
+			fakeClock := clock.NewFakeClock(time.Now())
+			runner.clock = fakeClock
+			runner.rand = func() float64 { return 0.5 }
+
+			done := make(chan protocol.Result, 1)
+			go func() {
+				done <- runner.send(withJitteredRetries(context.Background()), ceClient, cloudevents.NewEvent())
+			}()
+
+			for i, d := range delays {
+				if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+					return fakeClock.HasWaiters(), nil
+				}); err != nil {
+					t.Fatalf("Retry %d never waited", i+1)
+				}
+				fakeClock.Step(d - time.Millisecond)
+				if !fakeClock.HasWaiters() {
+					t.Fatalf("Expected retry %d to wait %v", i+1, d)
+				}
+				fakeClock.Step(time.Millisecond)
+			}
+
+			select {
+			case result := <-done:
+				if !cloudevents.IsACK(result) {
+					t.Errorf("Expected the last retry to succeed, got %v", result)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected the send to be done after the retries")
+			}
+			if got := len(ceClient.Sent()); got != 1 {
+				t.Errorf("Expected 1 event sent, got %d", got)
+			}
+		})
+	}
+}
+
+func TestRetryJitterPermanentError(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ceClient := adaptertesting.NewTestClientWithResults(cehttp.NewResult(400, "%w", protocol.ResultNACK))
+	runner := NewCronJobsRunner(ceClient, kubeclient.Get(ctx), logging.FromContext(ctx), WithRetryJitter(JitterFull))
+
+	if result := runner.send(withJitteredRetries(context.Background()), ceClient, cloudevents.NewEvent()); cloudevents.IsACK(result) {
+		t.Error("Expected a bad request not to be retried")
+	}
+	if got := len(ceClient.Sent()); got != 0 {
+		t.Errorf("Expected no event sent, got %d", got)
+	}
+}
+
+// countingClient counts the sends made through it.
+type countingClient struct {
+	cloudevents.Client
+
+	sends int32
+}
+
+func (c *countingClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	atomic.AddInt32(&c.sends, 1)
+	return c.Client.Send(ctx, event)
+}
+
+// retrySource returns a source firing every minute.
+func retrySource() *sourcesv1beta1.PingSource {
+	return newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("mysink")
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	unavailable := cehttp.NewResult(503, "%w", protocol.ResultNACK)
+	internal := cehttp.NewResult(500, "%w", protocol.ResultNACK)
+	tooMany := cehttp.NewResult(429, "%w", protocol.ResultNACK)
+	refused := errors.New("connection refused")
+
+	testCases := map[string]struct {
+		results     []protocol.Result
+		maxAttempts int
+		maxElapsed  time.Duration
+		wantSends   int32
+		wantSent    int
+	}{
+		"transient failures then success": {
+			results:     []protocol.Result{refused, internal, tooMany},
+			maxAttempts: 4,
+			wantSends:   4,
+			wantSent:    1,
+		},
+		"max attempts reached": {
+			results:     []protocol.Result{unavailable, unavailable, unavailable},
+			maxAttempts: 2,
+			wantSends:   2,
+		},
+		"max elapsed time reached": {
+			// Waiting 100ms then 200ms between the attempts.
+			results:    []protocol.Result{unavailable, unavailable, unavailable},
+			maxElapsed: 250 * time.Millisecond,
+			wantSends:  2,
+		},
+		"bad request": {
+			results:     []protocol.Result{cehttp.NewResult(400, "%w", protocol.ResultNACK)},
+			maxAttempts: 4,
+			wantSends:   1,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClientWithResults(tc.results...)
+			client := &countingClient{Client: ce}
+			runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx), WithRetryBackoff(tc.maxAttempts, tc.maxElapsed))
+
+			entryID := mustAddSchedule(t, runner, retrySource())
+			runner.cron.Entry(entryID).Job.Run()
+
+			if got := atomic.LoadInt32(&client.sends); got != tc.wantSends {
+				t.Errorf("Expected %d send attempts, got %d", tc.wantSends, got)
+			}
+			if got := len(ce.Sent()); got != tc.wantSent {
+				t.Errorf("Expected %d events sent, got %d", tc.wantSent, got)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffNextFire(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	unavailable := cehttp.NewResult(503, "%w", protocol.ResultNACK)
+	ce := adaptertesting.NewTestClientWithResults(unavailable, unavailable)
+	client := &countingClient{Client: ce}
+	runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx), WithRetryBackoff(5, 0))
+	// The first retry would wait past the next fire, 50ms later.
+	runner.clock = clock.NewFakeClock(time.Date(2020, 1, 1, 11, 59, 59, int(950*time.Millisecond), time.UTC))
+
+	entryID := mustAddSchedule(t, runner, retrySource())
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := atomic.LoadInt32(&client.sends); got != 1 {
+		t.Errorf("Expected 1 send attempt, got %d", got)
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event sent, got %d", got)
+	}
+}
+
+func TestRetryBackoffStop(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	unavailable := cehttp.NewResult(503, "%w", protocol.ResultNACK)
+	ce := adaptertesting.NewTestClientWithResults(unavailable, unavailable)
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithRetryBackoff(5, 0))
+	fakeClock := clock.NewFakeClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	runner.clock = fakeClock
+
+	entryID := mustAddSchedule(t, runner, retrySource())
+	go runner.cron.Entry(entryID).Job.Run()
+
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatal("The failed send was never retried")
+	}
+	if err := runner.StopWithTimeout(5 * time.Second); err != nil {
+		t.Fatal("Expected the stop to give up the retry, got", err)
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event sent, got %d", got)
+	}
+}
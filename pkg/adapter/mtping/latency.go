@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// defaultLatencyPercentile is the percentile of the send latency reported
+// when none is configured.
+const defaultLatencyPercentile = 99
+
+// LatencyStats summarizes the latency of the last sends of a source.
+type LatencyStats struct {
+	// Samples is the number of sends the latency is computed over.
+	Samples int
+	// Average is the average latency of the sends.
+	Average time.Duration
+	// Percentile is the latency the configured percentile of the sends
+	// didn't exceed.
+	Percentile time.Duration
+}
+
+// latencyWindow holds the latency of the last sends of a source, in a ring
+// buffer.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{samples: make([]time.Duration, size)}
+}
+
+// Record adds a send latency, evicting the oldest one when the window is
+// full.
+func (w *latencyWindow) Record(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.samples[w.next] = d
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// Summary returns the average and the given percentile of the recorded
+// latencies, the percentile using the nearest-rank method.
+func (w *latencyWindow) Summary(percentile int) LatencyStats {
+	w.mu.Lock()
+	n := w.next
+	if w.full {
+		n = len(w.samples)
+	}
+	samples := make([]time.Duration, n)
+	copy(samples, w.samples[:n])
+	w.mu.Unlock()
+
+	if n == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var sum time.Duration
+	for _, d := range samples {
+		sum += d
+	}
+	// The smallest sample at least percentile percent of the samples don't
+	// exceed.
+	rank := (percentile*n + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return LatencyStats{
+		Samples:    n,
+		Average:    sum / time.Duration(n),
+		Percentile: samples[rank-1],
+	}
+}
+
+// sendLatencyStatus returns the status reporting the given latency.
+func sendLatencyStatus(latency LatencyStats, percentile int) *sourcesv1beta1.PingSourceSendLatency {
+	return &sourcesv1beta1.PingSourceSendLatency{
+		Samples:           int32(latency.Samples),
+		Average:           metav1.Duration{Duration: latency.Average},
+		Percentile:        int32(percentile),
+		PercentileLatency: metav1.Duration{Duration: latency.Percentile},
+	}
+}
+
+// latencyOptions sets opts to measure the send latency of source, when the
+// runner does.
+func (a *cronJobsRunner) latencyOptions(source *sourcesv1beta1.PingSource, opts *jobOptions) {
+	if a.latencyWindow <= 0 {
+		return
+	}
+	if opts.stats.latency == nil {
+		opts.stats.latency = newLatencyWindow(a.latencyWindow)
+	}
+	if a.onLatency == nil {
+		return
+	}
+	namespace, name := source.Namespace, source.Name
+	reports := &logLimiter{interval: a.latencyInterval}
+	opts.onLatency = func(latency LatencyStats) {
+		if _, ok := reports.Allow(a.clock.Now()); ok {
+			a.onLatency(namespace, name, sendLatencyStatus(latency, a.latencyPercentile))
+		}
+	}
+}
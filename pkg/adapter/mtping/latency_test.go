@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// slowClient takes the next of latencies, on the fake clock, to send each
+// event.
+type slowClient struct {
+	cloudevents.Client
+
+	clock     *clock.FakeClock
+	mu        sync.Mutex
+	latencies []time.Duration
+}
+
+func (c *slowClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	c.mu.Lock()
+	c.clock.Step(c.latencies[0])
+	c.latencies = c.latencies[1:]
+	c.mu.Unlock()
+	return c.Client.Send(ctx, event)
+}
+
+func TestLatencyWindowSummary(t *testing.T) {
+	testCases := map[string]struct {
+		size       int
+		latencies  []time.Duration
+		percentile int
+		want       LatencyStats
+	}{
+		"empty": {
+			size:       3,
+			percentile: 99,
+		},
+		"single sample": {
+			size:       3,
+			latencies:  []time.Duration{7 * time.Millisecond},
+			percentile: 99,
+			want:       LatencyStats{Samples: 1, Average: 7 * time.Millisecond, Percentile: 7 * time.Millisecond},
+		},
+		"median": {
+			size:       10,
+			latencies:  []time.Duration{40, 10, 30, 20},
+			percentile: 50,
+			want:       LatencyStats{Samples: 4, Average: 25, Percentile: 20},
+		},
+		"90th percentile": {
+			size:       10,
+			latencies:  []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			percentile: 90,
+			want:       LatencyStats{Samples: 10, Average: 5, Percentile: 9},
+		},
+		"maximum": {
+			size:       10,
+			latencies:  []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 100},
+			percentile: 100,
+			want:       LatencyStats{Samples: 10, Average: 14, Percentile: 100},
+		},
+		"oldest evicted": {
+			size:       4,
+			latencies:  []time.Duration{100, 200, 3, 4, 5, 6},
+			percentile: 100,
+			want:       LatencyStats{Samples: 4, Average: 4, Percentile: 6},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			w := newLatencyWindow(tc.size)
+			for _, d := range tc.latencies {
+				w.Record(d)
+			}
+			if got := w.Summary(tc.percentile); got != tc.want {
+				t.Errorf("Summary(%d) = %+v, want %+v", tc.percentile, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSendLatency(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	fakeClock := clock.NewFakeClock(time.Now())
+	ce := &slowClient{
+		Client:    adaptertesting.NewTestClient(),
+		clock:     fakeClock,
+		latencies: []time.Duration{10 * time.Millisecond, 40 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond},
+	}
+
+	var mu sync.Mutex
+	var reports []*sourcesv1beta1.PingSourceSendLatency
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx),
+		WithSendLatency(10, 50),
+		WithLatencyHandler(time.Minute, func(namespace, name string, latency *sourcesv1beta1.PingSourceSendLatency) {
+			mu.Lock()
+			defer mu.Unlock()
+			if namespace != "test-ns" || name != "test-name" {
+				t.Errorf("Unexpected latency reported for %s/%s", namespace, name)
+			}
+			reports = append(reports, latency)
+		}))
+	runner.clock = fakeClock
+
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryID).Job
+	for i := 0; i < 4; i++ {
+		job.Run()
+	}
+
+	want := LatencyStats{Samples: 4, Average: 25 * time.Millisecond, Percentile: 20 * time.Millisecond}
+	if got := runner.Stats().Sources["test-ns/test-name"].Latency; got != want {
+		t.Errorf("Expected latency %+v, got %+v", want, got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The sends take less than the report interval, only the first fire is
+	// reported.
+	wantReport := sourcesv1beta1.PingSourceSendLatency{
+		Samples:           1,
+		Average:           metav1.Duration{Duration: 10 * time.Millisecond},
+		Percentile:        50,
+		PercentileLatency: metav1.Duration{Duration: 10 * time.Millisecond},
+	}
+	if len(reports) != 1 || *reports[0] != wantReport {
+		t.Errorf("Expected a single report %+v, got %v", wantReport, reports)
+	}
+}
+
+func TestSendLatencyDisabled(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := runner.Stats().Sources["test-ns/test-name"].Latency; got != (LatencyStats{}) {
+		t.Errorf("Expected no latency measured, got %+v", got)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LoadBalancingPolicy tells how the events are spread over the addresses
+// the sink host resolves to.
+type LoadBalancingPolicy string
+
+const (
+	// LoadBalanceRoundRobin sends the events to each address in turn.
+	LoadBalanceRoundRobin LoadBalancingPolicy = "round-robin"
+
+	// LoadBalanceRandom sends each event to a random address.
+	LoadBalanceRandom LoadBalancingPolicy = "random"
+)
+
+// ParseLoadBalancingPolicy returns the LoadBalancingPolicy named s.
+func ParseLoadBalancingPolicy(s string) (LoadBalancingPolicy, error) {
+	switch p := LoadBalancingPolicy(s); p {
+	case LoadBalanceRoundRobin, LoadBalanceRandom:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown load balancing policy %q", s)
+}
+
+// ipResolver resolves the addresses of the sink hosts, like net.Resolver.
+type ipResolver interface {
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+// balancingRoundTripper sends each request to one of the IPv4 addresses
+// the host of its URL resolves to, rather than relying on DNS to spread the
+// requests. The host is resolved on each request, and each address gets its
+// own transport so that the connections are kept alive per address.
+type balancingRoundTripper struct {
+	// base is cloned into the transport of each address.
+	base *nethttp.Transport
+	// fallback sends the requests whose host can't be resolved, or is an
+	// IP address.
+	fallback nethttp.RoundTripper
+	resolver ipResolver
+	policy   LoadBalancingPolicy
+	// intn returns a random number in [0, n).
+	intn func(n int) int
+
+	mu sync.Mutex
+	// turns holds the position of the round robin over the addresses of
+	// each host.
+	turns map[string]int
+	// backends holds the transports of the addresses, keyed by ip:port.
+	backends map[string]*nethttp.Transport
+}
+
+var _ nethttp.RoundTripper = (*balancingRoundTripper)(nil)
+
+// CloseIdleConnections closes the idle connections of the transports of the
+// addresses, and of the fallback.
+func (t *balancingRoundTripper) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, backend := range t.backends {
+		backend.CloseIdleConnections()
+	}
+	if idler, ok := t.fallback.(interface{ CloseIdleConnections() }); ok {
+		idler.CloseIdleConnections()
+	}
+}
+
+func (t *balancingRoundTripper) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	host := req.URL.Hostname()
+	if net.ParseIP(host) != nil {
+		return t.fallback.RoundTrip(req)
+	}
+	ips, err := t.resolver.LookupIP(req.Context(), "ip4", host)
+	if err != nil || len(ips) == 0 {
+		return t.fallback.RoundTrip(req)
+	}
+	// Resolvers may shuffle the records, the round robin needs an order.
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, ip.String())
+	}
+	sort.Strings(addrs)
+
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return t.backend(host, addrs, port).RoundTrip(req)
+}
+
+// backend picks one of addrs according to the policy, and returns the
+// transport dialing it.
+func (t *balancingRoundTripper) backend(host string, addrs []string, port string) *nethttp.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var i int
+	if t.policy == LoadBalanceRandom {
+		i = t.intn(len(addrs))
+	} else {
+		i = t.turns[host] % len(addrs)
+		t.turns[host] = i + 1
+	}
+	addr := net.JoinHostPort(addrs[i], port)
+	if backend, ok := t.backends[addr]; ok {
+		return backend
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	backend := t.base.Clone()
+	// The request URL is kept, for the TLS server name to be its host.
+	backend.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	t.backends[addr] = backend
+	return backend
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// fakeIPResolver resolves the hosts to fixed addresses.
+type fakeIPResolver map[string][]net.IP
+
+func (r fakeIPResolver) LookupIP(_ context.Context, network, host string) ([]net.IP, error) {
+	if network != "ip4" {
+		return nil, fmt.Errorf("unexpected network %s", network)
+	}
+	ips, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return ips, nil
+}
+
+// backendRecorder counts the requests received by each backend.
+type backendRecorder struct {
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (r *backendRecorder) count(backend string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[backend]
+}
+
+// startBackends starts a sink listening on the same port on each of the
+// given loopback addresses, and returns the port.
+func startBackends(t *testing.T, rec *backendRecorder, ips ...string) string {
+	port := "0"
+	for _, ip := range ips {
+		ip := ip
+		l, err := net.Listen("tcp", net.JoinHostPort(ip, port))
+		if err != nil {
+			t.Skip("Can't listen on the loopback addresses:", err)
+		}
+		_, port, _ = net.SplitHostPort(l.Addr().String())
+		sink := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			rec.mu.Lock()
+			rec.requests[ip]++
+			rec.mu.Unlock()
+			w.WriteHeader(nethttp.StatusAccepted)
+		}))
+		sink.Listener.Close()
+		sink.Listener = l
+		sink.Start()
+		t.Cleanup(sink.Close)
+	}
+	return port
+}
+
+func TestLoadBalancing(t *testing.T) {
+	backends := []string{"127.0.0.2", "127.0.0.3", "127.0.0.4"}
+
+	testCases := map[string]struct {
+		policy LoadBalancingPolicy
+		fires  int
+		// check asserts the number of requests n received by a backend.
+		check func(n int) bool
+	}{
+		"round robin": {
+			policy: LoadBalanceRoundRobin,
+			fires:  6,
+			check:  func(n int) bool { return n == 2 },
+		},
+		"random": {
+			policy: LoadBalanceRandom,
+			fires:  30,
+			check:  func(n int) bool { return n > 0 },
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rec := &backendRecorder{requests: make(map[string]int)}
+			port := startBackends(t, rec, backends...)
+
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+			// The fake resolver shuffles the addresses.
+			runner.clients.resolver = fakeIPResolver{"sink.test": {
+				net.ParseIP(backends[2]), net.ParseIP(backends[0]), net.ParseIP(backends[1]),
+			}}
+
+			sinkURI, _ := apis.ParseURL("http://sink.test:" + port + "/")
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = sinkURI
+				s.Annotations = map[string]string{LoadBalancingAnnotation: string(tc.policy)}
+			}))
+			job := runner.cron.Entry(entryID).Job
+			for i := 0; i < tc.fires; i++ {
+				job.Run()
+			}
+
+			total := 0
+			for _, backend := range backends {
+				n := rec.count(backend)
+				total += n
+				if !tc.check(n) {
+					t.Errorf("Unexpected %d requests received by %s", n, backend)
+				}
+			}
+			if total != tc.fires {
+				t.Errorf("Expected %d requests, got %d", tc.fires, total)
+			}
+		})
+	}
+}
+
+func TestLoadBalancingUnresolvedHost(t *testing.T) {
+	var requests int
+	var mu sync.Mutex
+	sink := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	rt := &balancingRoundTripper{
+		base:     nethttp.DefaultTransport.(*nethttp.Transport).Clone(),
+		fallback: nethttp.DefaultTransport,
+		resolver: fakeIPResolver{},
+		policy:   LoadBalanceRoundRobin,
+		turns:    make(map[string]int),
+		backends: make(map[string]*nethttp.Transport),
+	}
+	// The sink URL has an IP address, and localhost isn't known to the
+	// resolver: both go through the fallback.
+	_, port, _ := net.SplitHostPort(sink.Listener.Addr().String())
+	for _, target := range []string{sink.URL, "http://localhost:" + port} {
+		req, _ := nethttp.NewRequest(nethttp.MethodPost, target, nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("Unexpected error sending to %s: %v", target, err)
+		}
+		resp.Body.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+	if len(rt.backends) != 0 {
+		t.Errorf("Expected no load balanced backend, got %d", len(rt.backends))
+	}
+}
+
+func TestTransportConfigLoadBalancing(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		want        LoadBalancingPolicy
+		wantErr     bool
+	}{
+		"round robin": {
+			annotations: map[string]string{LoadBalancingAnnotation: "round-robin"},
+			want:        LoadBalanceRoundRobin,
+		},
+		"random": {
+			annotations: map[string]string{LoadBalancingAnnotation: "random"},
+			want:        LoadBalanceRandom,
+		},
+		"unknown policy": {
+			annotations: map[string]string{LoadBalancingAnnotation: "least-connections"},
+			wantErr:     true,
+		},
+		"with proxy": {
+			annotations: map[string]string{
+				LoadBalancingAnnotation: "random",
+				ProxyURLAnnotation:      "http://proxy.example.com:3128",
+			},
+			wantErr: true,
+		},
+		"with h2c": {
+			annotations: map[string]string{
+				LoadBalancingAnnotation: "random",
+				H2CAnnotation:           strconv.FormatBool(true),
+			},
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			cfg, err := transportConfigFor(newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Annotations = tc.annotations
+			}), "")
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", tc.wantErr, err)
+			}
+			if cfg.loadBalancing != tc.want {
+				t.Errorf("Expected policy %q, got %q", tc.want, cfg.loadBalancing)
+			}
+		})
+	}
+}
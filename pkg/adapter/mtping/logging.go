@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	nethttp "net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// loggingRoundTripper logs the requests and responses going through the
+// next round tripper. The body of the requests carrying sensitive data is
+// not logged.
+type loggingRoundTripper struct {
+	next   nethttp.RoundTripper
+	logger *zap.SugaredLogger
+}
+
+var _ nethttp.RoundTripper = (*loggingRoundTripper)(nil)
+
+func (t *loggingRoundTripper) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	if dump, err := httputil.DumpRequestOut(req, !hasSensitiveData(req.Context())); err != nil {
+		t.logger.Warnw("failed to dump request", zap.Error(err))
+	} else {
+		t.logger.Infow("sending request", zap.ByteString("request", dump))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.logger.Infow("request failed", zap.Error(err))
+		return resp, err
+	}
+
+	if dump, err := httputil.DumpResponse(resp, true); err != nil {
+		t.logger.Warnw("failed to dump response", zap.Error(err))
+	} else {
+		t.logger.Infow("received response", zap.ByteString("response", dump))
+	}
+	return resp, nil
+}
+
+// logLimiter allows logging a first error, then at most one error per
+// interval, counting the errors suppressed in between.
+type logLimiter struct {
+	interval time.Duration
+
+	mu         sync.Mutex
+	last       time.Time
+	suppressed int
+}
+
+// Allow returns whether an error occurring at now can be logged and, if so,
+// how many errors were suppressed since the last logged one.
+func (l *logLimiter) Allow(now time.Time) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() && now.Sub(l.last) < l.interval {
+		l.suppressed++
+		return 0, false
+	}
+	suppressed := l.suppressed
+	l.last = now
+	l.suppressed = 0
+	return suppressed, true
+}
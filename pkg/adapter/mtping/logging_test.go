@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestVerboseLogging(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		wantLogged  bool
+	}{
+		"enabled": {
+			annotations: map[string]string{VerboseLoggingAnnotation: "true"},
+			wantLogged:  true,
+		},
+		"disabled": {
+			annotations: map[string]string{VerboseLoggingAnnotation: "false"},
+		},
+		"missing": {},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sink := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				w.Header().Set("X-Sink", "pong")
+				w.WriteHeader(nethttp.StatusAccepted)
+			}))
+			defer sink.Close()
+
+			ctx, _ := rectesting.SetupFakeContext(t)
+			core, logs := observer.New(zapcore.InfoLevel)
+			ce := adaptertesting.NewTestClient()
+
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), zap.New(core).Sugar())
+
+			sinkURI, _ := apis.ParseURL(sink.URL)
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = sinkURI
+				s.Annotations = tc.annotations
+			}))
+			runner.cron.Entry(entryID).Job.Run()
+
+			requests := logs.FilterMessage("sending request").All()
+			responses := logs.FilterMessage("received response").All()
+
+			if !tc.wantLogged {
+				if len(requests) != 0 || len(responses) != 0 {
+					t.Errorf("Expected no request/response logs, got %d/%d", len(requests), len(responses))
+				}
+				if got := len(ce.Sent()); got != 1 {
+					t.Error("Expected 1 event to be sent with the default client, got", got)
+				}
+				return
+			}
+
+			if len(requests) != 1 {
+				t.Fatal("Expected 1 request log, got", len(requests))
+			}
+			request := requests[0].ContextMap()["request"].(string)
+			if !strings.Contains(request, "POST") || !strings.Contains(request, `{"body":"some data"}`) {
+				t.Error("Expected the request log to contain the method and the body, got", request)
+			}
+
+			if len(responses) != 1 {
+				t.Fatal("Expected 1 response log, got", len(responses))
+			}
+			response := responses[0].ContextMap()["response"].(string)
+			if !strings.Contains(response, "202 Accepted") || !strings.Contains(response, "X-Sink: pong") {
+				t.Error("Expected the response log to contain the status and the headers, got", response)
+			}
+		})
+	}
+}
+
+func TestLogSampling(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	failure := errors.New("sink unavailable")
+	ce := adaptertesting.NewTestClientWithResults(failure, failure, failure)
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), zap.New(core).Sugar())
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{LogSamplingAnnotation: "10"}
+	}))
+	job := runner.cron.Entry(entryID).Job
+
+	const fires = 20
+	var wg sync.WaitGroup
+	wg.Add(fires)
+	for i := 0; i < fires; i++ {
+		go func() {
+			defer wg.Done()
+			job.Run()
+		}()
+	}
+	wg.Wait()
+
+	sampledLogs, errorLogs := 0, 0
+	for _, e := range logs.All() {
+		if strings.HasPrefix(e.Message, "sending cloudevent id") {
+			sampledLogs++
+		}
+		if e.Level == zapcore.ErrorLevel {
+			errorLogs++
+		}
+	}
+	if sampledLogs != fires/10 {
+		t.Errorf("Expected %d sampled fire logs, got %d", fires/10, sampledLogs)
+	}
+	// Failures are not sampled, but rate limited.
+	if errorLogs != 1 {
+		t.Error("Expected the first failure to be logged, got", errorLogs)
+	}
+}
+
+func TestErrorLogRateLimit(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	core, logs := observer.New(zapcore.ErrorLevel)
+
+	failure := errors.New("sink unavailable")
+	results := make([]protocol.Result, 10)
+	for i := range results {
+		results[i] = failure
+	}
+	ce := adaptertesting.NewTestClientWithResults(results...)
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), zap.New(core).Sugar())
+	fakeClock := clock.NewFakeClock(time.Now())
+	runner.clock = fakeClock
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryID).Job
+
+	// Fires every 10 seconds for a minute and a half.
+	for i := 0; i < 10; i++ {
+		job.Run()
+		fakeClock.Step(10 * time.Second)
+	}
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatal("Expected 2 errors to be logged, got", len(entries))
+	}
+	if got := entries[0].ContextMap()["suppressed"]; got != int64(0) {
+		t.Error("Expected no suppressed error before the first one, got", got)
+	}
+	if got := entries[1].ContextMap()["suppressed"]; got != int64(5) {
+		t.Error("Expected 5 suppressed errors before the second one, got", got)
+	}
+	if got := runner.Stats().Sources["test-ns/test-name"].Failed; got != 10 {
+		t.Error("Expected all 10 failures to be counted, got", got)
+	}
+}
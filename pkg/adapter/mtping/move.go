@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrUnsupportedShard is returned when moving a schedule to a shard
+	// that isn't a runner returned by NewCronJobsRunner.
+	ErrUnsupportedShard = errors.New("unsupported target shard")
+)
+
+// MoveSchedule moves the schedule of the source with the given
+// namespace/name key to targetShard, re-registering it there with its
+// delivery counters and last run times. The fires already running finish on
+// the runner. It returns the entry ID of the schedule on targetShard,
+// ErrEntryNotFound when the source isn't scheduled, and a *ScheduleError when
+// targetShard can't schedule it, the schedule being kept then.
+func (a *cronJobsRunner) MoveSchedule(sourceKey string, targetShard CronJobRunner) (cron.EntryID, error) {
+	target, ok := targetShard.(*cronJobsRunner)
+	if !ok {
+		return 0, fmt.Errorf("%w: %T", ErrUnsupportedShard, targetShard)
+	}
+	a.statsMu.Lock()
+	job, ok := a.scheduled[sourceKey]
+	if !ok {
+		a.statsMu.Unlock()
+		return 0, fmt.Errorf("%w: %s", ErrEntryNotFound, sourceKey)
+	}
+	source := job.source
+	a.statsMu.Unlock()
+	if target == a {
+		return job.id, nil
+	}
+	if _, scheduled := target.GetSchedule(source.Namespace, source.Name); scheduled {
+		return 0, newScheduleError(source, ReasonAlreadyScheduled, errors.New("source already scheduled by the target shard"))
+	}
+
+	// The schedule is removed first for the source not to fire from both
+	// shards at once.
+	a.remove(job.id)
+	id, err := target.schedule(source, nil, job.stats)
+	if err != nil {
+		// Scheduled by the runner before, the source can be again.
+		if _, restoreErr := a.schedule(source, nil, job.stats); restoreErr != nil {
+			a.Logger.Errorw("failed to restore the schedule of a source that couldn't be moved",
+				zap.String("source", sourceKey), zap.Error(restoreErr))
+		}
+		return 0, err
+	}
+	return id, nil
+}
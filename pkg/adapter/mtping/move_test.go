@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"testing"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestMoveSchedule(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	fromCE, toCE := adaptertesting.NewTestClient(), adaptertesting.NewTestClient()
+	from := NewCronJobsRunner(fromCE, kubeclient.Get(ctx), logging.FromContext(ctx))
+	to := NewCronJobsRunner(toCE, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.JsonData = `{"msg":"hello"}`
+	})
+	id := mustAddSchedule(t, from, source)
+	from.cron.Entry(id).Job.Run()
+	triggered, _, _ := from.LastRun(source.Namespace, source.Name)
+
+	moved, err := from.MoveSchedule("test-ns/test-name", to)
+	if err != nil {
+		t.Fatal("MoveSchedule =", err)
+	}
+	if got := len(from.cron.Entries()); got != 0 {
+		t.Errorf("Expected no cron entry left on the previous runner, got %d", got)
+	}
+	if _, ok := from.GetSchedule(source.Namespace, source.Name); ok {
+		t.Error("Expected the source not to be scheduled by the previous runner")
+	}
+	if _, ok := to.GetSchedule(source.Namespace, source.Name); !ok {
+		t.Fatal("Expected the source to be scheduled by the target runner")
+	}
+	if got, _, _ := to.LastRun(source.Namespace, source.Name); !got.Equal(triggered) {
+		t.Errorf("Expected the last fire at %v to be kept, got %v", triggered, got)
+	}
+
+	to.cron.Entry(moved).Job.Run()
+	if got := len(fromCE.Sent()); got != 1 {
+		t.Errorf("Expected 1 event sent by the previous runner, got %d", got)
+	}
+	sent := toCE.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 event sent by the target runner, got %d", len(sent))
+	}
+	if got := string(sent[0].Data()); got != `{"msg":"hello"}` {
+		t.Errorf("Expected the data of the source to be sent, got %s", got)
+	}
+	if got := to.Stats().Sources["test-ns/test-name"].Sent; got != 2 {
+		t.Errorf("Expected the counters to go on across the move, got %d events sent", got)
+	}
+}
+
+func TestMoveScheduleNotScheduled(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	from := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	to := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	if _, err := from.MoveSchedule("test-ns/test-name", to); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound, got %v", err)
+	}
+	if _, err := from.MoveSchedule("test-ns/test-name", nil); !errors.Is(err, ErrUnsupportedShard) {
+		t.Errorf("Expected ErrUnsupportedShard, got %v", err)
+	}
+}
+
+func TestMoveScheduleAlreadyScheduled(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	from := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	to := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	source := newTestSource()
+	id := mustAddSchedule(t, from, source)
+	mustAddSchedule(t, to, source)
+
+	_, err := from.MoveSchedule("test-ns/test-name", to)
+	var scheduleErr *ScheduleError
+	if !errors.As(err, &scheduleErr) || scheduleErr.Reason != ReasonAlreadyScheduled {
+		t.Fatalf("Expected a %s error, got %v", ReasonAlreadyScheduled, err)
+	}
+	if entry, ok := from.GetSchedule(source.Namespace, source.Name); !ok || entry.ID != id {
+		t.Error("Expected the schedule to be kept by the runner")
+	}
+}
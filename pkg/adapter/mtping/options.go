@@ -0,0 +1,299 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// Option configures a cronJobsRunner.
+type Option func(*cronJobsRunner)
+
+// WithCronOptions passes the given options to the underlying cron scheduler.
+func WithCronOptions(opts ...cron.Option) Option {
+	return func(a *cronJobsRunner) {
+		a.cronOpts = append(a.cronOpts, opts...)
+	}
+}
+
+// WithMaxGoroutines bounds the number of jobs running concurrently across
+// all sources. Fires exceeding the budget are shed instead of executed, the
+// goroutine cron starts for a shed fire returning before running any of its
+// job.
+// Zero or a negative value means unbounded.
+func WithMaxGoroutines(n int) Option {
+	return func(a *cronJobsRunner) {
+		a.maxGoroutines = int32(n)
+	}
+}
+
+// WithMaxConcurrentSends bounds the number of fires sending their events at
+// the same time, across all sources. The fires finding no free slot wait up
+// to queueTimeout for one, or not at all when their source skips the
+// concurrent fires, and are dropped otherwise. Zero or a negative n means
+// unbounded.
+func WithMaxConcurrentSends(n int, queueTimeout time.Duration) Option {
+	return func(a *cronJobsRunner) {
+		a.maxSends = n
+		a.sendQueueTimeout = queueTimeout
+	}
+}
+
+// WithRegion sets the region extension on all events sent by the runner.
+// An empty region leaves events unchanged.
+func WithRegion(region string) Option {
+	return func(a *cronJobsRunner) {
+		a.region = region
+	}
+}
+
+// WithTraceParent makes the runner set the traceparent extension on every
+// event, generating a new trace context when no trace is in progress.
+func WithTraceParent() Option {
+	return func(a *cronJobsRunner) {
+		a.traceParent = true
+	}
+}
+
+// WithMetricsFlush sets the function called by Stop to flush the metrics
+// once all jobs are done. Defaults to flushing the metrics exporter.
+func WithMetricsFlush(flush func()) Option {
+	return func(a *cronJobsRunner) {
+		a.flush = flush
+	}
+}
+
+// WithPubSubEndpoint sets the endpoint of the Pub/Sub REST API publishing
+// to the sinks using the pubsub scheme, such as the one of an emulator. The
+// requests to a custom endpoint are not authenticated.
+func WithPubSubEndpoint(endpoint string) Option {
+	return func(a *cronJobsRunner) {
+		a.pubsub.endpoint = endpoint
+	}
+}
+
+// WithPersistentQueue makes the runner persist the events it fails to send
+// to dir, and send them again when started. maxBytes caps the total size of
+// the persisted events, zero meaning unbounded.
+func WithPersistentQueue(dir string, maxBytes int64) Option {
+	return func(a *cronJobsRunner) {
+		q, err := newDiskQueue(dir, maxBytes)
+		if err != nil {
+			a.Logger.Errorw("failed to create the persistent queue, failed events won't be persisted", zap.Error(err))
+			return
+		}
+		a.queue = q
+	}
+}
+
+// WithCompletionHandler sets the function called with the namespace and name
+// of the sources whose schedule is removed after their first successful send.
+func WithCompletionHandler(complete func(namespace, name string)) Option {
+	return func(a *cronJobsRunner) {
+		a.complete = complete
+	}
+}
+
+// WithInvalidEventHandler sets the function called with the namespace and
+// name of the sources skipping an event not matching their EventSchema, and
+// the validation error.
+func WithInvalidEventHandler(invalid func(namespace, name string, err error)) Option {
+	return func(a *cronJobsRunner) {
+		a.invalid = invalid
+	}
+}
+
+// WithPartialFailureHandler sets the function called with the namespace and
+// name of the sources when a fire sends only part of its events, and when a
+// following fire sends all of them, with the sent and failed counts of the
+// fire.
+func WithPartialFailureHandler(partial func(namespace, name string, sent, failed uint64)) Option {
+	return func(a *cronJobsRunner) {
+		a.partial = partial
+	}
+}
+
+// WithMissingTemplateKeyHandler sets the function called with the namespace
+// and name of the sources whose templates start referencing missing keys,
+// with the rendering error and whether the fire was skipped, with the Strict
+// TemplateMissingKey, or the keys rendered empty, with the Lenient one. It is
+// called with a nil error once the keys are resolved again.
+func WithMissingTemplateKeyHandler(missing func(namespace, name string, err error, skipped bool)) Option {
+	return func(a *cronJobsRunner) {
+		a.missingKeys = missing
+	}
+}
+
+// WithPanicHandler removes the schedule of the sources whose job panicked on
+// maxPanics consecutive fires, and sets the function called with their
+// namespace and name and the last value recovered.
+func WithPanicHandler(maxPanics int, handler func(namespace, name string, recovered interface{})) Option {
+	return func(a *cronJobsRunner) {
+		a.maxPanics = maxPanics
+		a.panicking = handler
+	}
+}
+
+// WithDrainTimeout bounds the time Stop waits for the jobs in flight to be
+// done. Zero or a negative value means unbounded.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(a *cronJobsRunner) {
+		a.drainTimeout = d
+	}
+}
+
+// WithDedupStore makes the runner claim each event in the given store
+// before sending it, so that replicas sharing the store emit each tick once.
+func WithDedupStore(store DedupStore) Option {
+	return func(a *cronJobsRunner) {
+		a.dedup = store
+	}
+}
+
+// WithDedupCollisionPolicy sets what the runner does with the events whose
+// ID is claimed by a different event in a ValueDedupStore. Defaults to skip.
+func WithDedupCollisionPolicy(policy DedupCollisionPolicy) Option {
+	return func(a *cronJobsRunner) {
+		a.collisionPolicy = policy
+	}
+}
+
+// WithRateLimiter makes the runner take each fire from the given limiter,
+// dropping the fires exceeding the rate shared by the replicas.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(a *cronJobsRunner) {
+		a.limiter = limiter
+	}
+}
+
+// WithTransforms appends transforms applied in order to the events of all
+// sources, after the source settings and before sending.
+func WithTransforms(transforms ...Transform) Option {
+	return func(a *cronJobsRunner) {
+		a.transforms = append(a.transforms, transforms...)
+	}
+}
+
+// WithSinkResolution makes the runner read the address of the Addressable
+// sinks on each fire, instead of relying on the address resolved when the
+// source is reconciled. Addresses are cached for ttl.
+func WithSinkResolution(client dynamic.Interface, ttl time.Duration) Option {
+	return func(a *cronJobsRunner) {
+		a.resolver = newAddressResolver(client, ttl)
+	}
+}
+
+// WithSchemaFingerprints makes the runner record a fingerprint of the shape
+// of the events of each source, reported by Stats. Changes are logged.
+func WithSchemaFingerprints() Option {
+	return func(a *cronJobsRunner) {
+		a.schemas = newSchemaRecorder()
+	}
+}
+
+// WithRetryJitter makes the runner randomize the exponential backoff between
+// the retries of a send according to strategy.
+func WithRetryJitter(strategy JitterStrategy) Option {
+	return func(a *cronJobsRunner) {
+		a.jitter = strategy
+	}
+}
+
+// WithAttemptSpans makes the span of each fire have a child span per
+// attempt of each send, telling its number and outcome.
+func WithAttemptSpans() Option {
+	return func(a *cronJobsRunner) {
+		a.attemptSpans = true
+	}
+}
+
+// WithSendLatency makes the runner measure the latency of the last window
+// sends of each source, reporting their average and the given percentile in
+// Stats. A percentile out of [1, 100] reports the 99th percentile.
+func WithSendLatency(window, percentile int) Option {
+	return func(a *cronJobsRunner) {
+		a.latencyWindow = window
+		if percentile >= 1 && percentile <= 100 {
+			a.latencyPercentile = percentile
+		}
+	}
+}
+
+// WithLatencyHandler sets the function called with the send latency of the
+// sources measured with WithSendLatency, at most once per interval for each
+// source.
+func WithLatencyHandler(interval time.Duration, handler func(namespace, name string, latency *sourcesv1beta1.PingSourceSendLatency)) Option {
+	return func(a *cronJobsRunner) {
+		a.onLatency = handler
+		a.latencyInterval = interval
+	}
+}
+
+// WithOTelMetrics makes the runner record its counters and the send latency
+// in the mtping OpenCensus views, for the metrics backend to export them
+// along with the per-source ones, such as to an OpenTelemetry collector.
+func WithOTelMetrics() Option {
+	return func(a *cronJobsRunner) {
+		a.otel = newOTelMetrics()
+	}
+}
+
+// WithStatsReporter sets the reporter of the per-source metrics, the
+// OpenCensus views by default.
+func WithStatsReporter(reporter StatsReporter) Option {
+	return func(a *cronJobsRunner) {
+		a.reporter = reporter
+	}
+}
+
+// WithRetryBackoff makes the runner retry the failed sends with an
+// exponential backoff, up to maxAttempts attempts and maxElapsed after the
+// first one. Zero keeps the default number of attempts, or doesn't bound the
+// time. The retries of a fire never wait past the next fire of its source.
+func WithRetryBackoff(maxAttempts int, maxElapsed time.Duration) Option {
+	return func(a *cronJobsRunner) {
+		a.retryMaxAttempts = maxAttempts
+		a.retryMaxElapsed = maxElapsed
+	}
+}
+
+// WithFireJitter spreads the fires of the sources sharing a schedule over
+// window, for them not to hit their sinks at once. Each source is delayed
+// by an offset derived from its namespace and name, the same on all the
+// replicas and across restarts, and never past its next fire. The sources
+// with the PreciseTimingAnnotation aren't delayed.
+func WithFireJitter(window time.Duration) Option {
+	return func(a *cronJobsRunner) {
+		a.fireJitter = window
+	}
+}
+
+// WithDeliveryStatus makes the runner report the results of the deliveries
+// of the sources to reporter, the PingSource reconciler propagating them to
+// the SinkDeliverable condition of the sources.
+func WithDeliveryStatus(reporter *adapter.DeliveryStatusReporter) Option {
+	return func(a *cronJobsRunner) {
+		a.deliveryStatus = reporter
+	}
+}
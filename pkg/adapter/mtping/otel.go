@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"knative.dev/pkg/metrics"
+)
+
+var (
+	// runnerCountersM are the counters of the runner, in the order of the
+	// values exported by export.
+	runnerCountersM = []*stats.Int64Measure{
+		stats.Int64("mtping_shed_total", "Number of fires dropped because the goroutine budget was exhausted", stats.UnitDimensionless),
+		stats.Int64("mtping_rate_limited_total", "Number of fires dropped because the shared rate limit was reached", stats.UnitDimensionless),
+		stats.Int64("mtping_dedup_collisions_total", "Number of events whose ID was claimed by a different event", stats.UnitDimensionless),
+	}
+
+	// sourceCountersM are the counters of the sources, in the order of the
+	// values exported by export.
+	sourceCountersM = []*stats.Int64Measure{
+		stats.Int64("mtping_fires_total", "Number of fires of the schedules", stats.UnitDimensionless),
+		stats.Int64("mtping_sent_total", "Number of events sent", stats.UnitDimensionless),
+		stats.Int64("mtping_failed_total", "Number of events that failed to be sent", stats.UnitDimensionless),
+		stats.Int64("mtping_invalid_total", "Number of events skipped for not matching the event schema", stats.UnitDimensionless),
+		stats.Int64("mtping_skipped_total", "Number of fires skipped by the fire condition", stats.UnitDimensionless),
+		stats.Int64("mtping_stale_total", "Number of fires skipped for exceeding the maximum staleness", stats.UnitDimensionless),
+		stats.Int64("mtping_missing_key_total", "Number of fires skipped for their templates referencing missing keys", stats.UnitDimensionless),
+		stats.Int64("mtping_shadow_sent_total", "Number of events copied to the shadow sink", stats.UnitDimensionless),
+		stats.Int64("mtping_shadow_failed_total", "Number of events that failed to be copied to the shadow sink", stats.UnitDimensionless),
+	}
+
+	// sendLatencyM records the latency of the sends of the sources, in
+	// milliseconds.
+	sendLatencyM = stats.Float64("mtping_send_latency", "Latency of the sends", stats.UnitMilliseconds)
+)
+
+func init() {
+	registerOTelViews()
+}
+
+func registerOTelViews() {
+	var views []*view.View
+	for _, m := range append(append([]*stats.Int64Measure{}, runnerCountersM...), sourceCountersM...) {
+		views = append(views, &view.View{
+			Description: m.Description(),
+			Measure:     m,
+			Aggregation: view.Sum(),
+		})
+	}
+	views = append(views, &view.View{
+		Description: sendLatencyM.Description(),
+		Measure:     sendLatencyM,
+		Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...),
+	})
+	if err := metrics.RegisterResourceView(views...); err != nil {
+		log.Printf("failed to register opencensus views, %s", err)
+	}
+}
+
+// otelMetrics records the runner counters and the send latency in the
+// OpenCensus views exported by the metrics backend, such as to an
+// OpenTelemetry collector with the opencensus backend. The counters of the
+// sources are recorded on the resource of their source. The counters are
+// recorded at the end of each fire, adding what was counted since the
+// previous export.
+type otelMetrics struct {
+	mu sync.Mutex
+	// runner holds the runner counters already exported.
+	runner exportedCounts
+	// sources holds the counters of the sources already exported.
+	sources map[*deliveryStats]*exportedCounts
+}
+
+type exportedCounts struct {
+	ctx    context.Context
+	counts []uint64
+}
+
+func newOTelMetrics() *otelMetrics {
+	return &otelMetrics{
+		runner:  exportedCounts{ctx: context.Background()},
+		sources: make(map[*deliveryStats]*exportedCounts),
+	}
+}
+
+// export adds to the counters what the runner and the source counted since
+// the previous export.
+func (m *otelMetrics) export(ctx context.Context, a *cronJobsRunner, stats *deliveryStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runner.add(runnerCountersM, []uint64{
+		atomic.LoadUint64(&a.shed),
+		atomic.LoadUint64(&a.rateLimited),
+		atomic.LoadUint64(&a.dedupCollisions),
+	})
+	m.source(stats).add(sourceCountersM, []uint64{
+		atomic.LoadUint64(&stats.fires),
+		atomic.LoadUint64(&stats.sent),
+		atomic.LoadUint64(&stats.failed),
+		atomic.LoadUint64(&stats.invalid),
+		atomic.LoadUint64(&stats.skipped),
+		atomic.LoadUint64(&stats.stale),
+		atomic.LoadUint64(&stats.missingKey),
+		atomic.LoadUint64(&stats.shadowSent),
+		atomic.LoadUint64(&stats.shadowFailed),
+	})
+}
+
+// recordLatency records the latency of a send of the source.
+func (m *otelMetrics) recordLatency(ctx context.Context, stats *deliveryStats, d time.Duration) {
+	m.mu.Lock()
+	sourceCtx := m.source(stats).ctx
+	m.mu.Unlock()
+	metrics.Record(sourceCtx, sendLatencyM.M(float64(d)/float64(time.Millisecond)))
+}
+
+// forget drops the counters of a removed source.
+func (m *otelMetrics) forget(stats *deliveryStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sources, stats)
+}
+
+// source returns the counters of the source already exported. m.mu must be
+// held.
+func (m *otelMetrics) source(stats *deliveryStats) *exportedCounts {
+	if src, ok := m.sources[stats]; ok {
+		return src
+	}
+	namespace, name := stats.key, ""
+	if i := strings.IndexByte(stats.key, '/'); i >= 0 {
+		namespace, name = stats.key[:i], stats.key[i+1:]
+	}
+	src := &exportedCounts{ctx: sourceContext(namespace, name)}
+	m.sources[stats] = src
+	return src
+}
+
+// add adds to counters the increase of counts since the previous call.
+func (e *exportedCounts) add(counters []*stats.Int64Measure, counts []uint64) {
+	if e.counts == nil {
+		e.counts = make([]uint64, len(counts))
+	}
+	for i, n := range counts {
+		if n > e.counts[i] {
+			metrics.Record(e.ctx, counters[i].M(int64(n-e.counts[i])))
+			e.counts[i] = n
+		}
+	}
+}
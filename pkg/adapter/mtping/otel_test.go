@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+
+	"go.opencensus.io/resource"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// resetOTelViews clears the data of the mtping views.
+func resetOTelViews() {
+	names := []string{sendLatencyM.Name()}
+	for _, m := range runnerCountersM {
+		names = append(names, m.Name())
+	}
+	for _, m := range sourceCountersM {
+		names = append(names, m.Name())
+	}
+	// OpenCensus metrics carry global state that need to be reset between unit tests.
+	metricstest.Unregister(names...)
+	registerOTelViews()
+}
+
+func TestOTelMetrics(t *testing.T) {
+	resetOTelViews()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithOTelMetrics())
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.FireCondition = "fireCount != 2"
+	}))
+	job := runner.cron.Entry(entryID).Job
+
+	resource := &resource.Resource{
+		Type: metricskey.ResourceTypeKnativeSource,
+		Labels: map[string]string{
+			metricskey.LabelNamespaceName: "test-ns",
+			metricskey.LabelName:          "test-name",
+			metricskey.LabelResourceGroup: resourceGroup,
+		},
+	}
+
+	job.Run()
+	assertSourceMetric(t, metricstest.IntMetric("mtping_sent_total", 1, nil).WithResource(resource))
+
+	job.Run()
+	job.Run()
+	assertSourceMetric(t, metricstest.IntMetric("mtping_fires_total", 3, nil).WithResource(resource))
+	assertSourceMetric(t, metricstest.IntMetric("mtping_sent_total", 2, nil).WithResource(resource))
+	assertSourceMetric(t, metricstest.IntMetric("mtping_skipped_total", 1, nil).WithResource(resource))
+	assertSourceMetric(t, metricstest.DistributionCountOnlyMetric("mtping_send_latency", 2, nil).WithResource(resource))
+	metricstest.AssertNoMetric(t, "mtping_failed_total")
+}
+
+func TestOTelRunnerMetrics(t *testing.T) {
+	resetOTelViews()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx),
+		WithOTelMetrics(), WithMaxGoroutines(1))
+
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	// Exhaust the goroutine budget for the fire to be shed.
+	if !runner.acquire() {
+		t.Fatal("Expected to acquire the goroutine budget")
+	}
+	runner.cron.Entry(entryID).Job.Run()
+	runner.release()
+
+	assertSourceMetric(t, metricstest.IntMetric("mtping_shed_total", 1, nil))
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// PreviewResult describes what the adapter would send for a PingSource.
+type PreviewResult struct {
+	// Event is the event sent on each fire. The ID is generated at fire time
+	// and is left unset. The type and source templates are rendered at Next.
+	Event cloudevents.Event
+
+	// Target is the sink URI the event is sent to. It is empty when the
+	// sink has not been resolved yet.
+	Target string
+
+	// Next is the next time the schedule fires.
+	Next time.Time
+}
+
+// ValidateAndPreview validates the PingSource, parses its schedule and
+// renders the event the adapter would send on each fire, without sending it.
+func ValidateAndPreview(src *sourcesv1beta1.PingSource) (PreviewResult, error) {
+	if err := src.Validate(context.Background()); err != nil {
+		return PreviewResult{}, err
+	}
+
+	schedule, err := sourceSchedule(src)
+	if err != nil {
+		return PreviewResult{}, err
+	}
+
+	event, err := makeEvent(src)
+	if err != nil {
+		return PreviewResult{}, err
+	}
+	result := PreviewResult{
+		Event: event,
+		Next:  schedule.Next(time.Now()),
+	}
+	templates, err := newEventTemplates(src)
+	if err != nil {
+		return PreviewResult{}, err
+	}
+	if templates != nil {
+		if _, err := templates.Render(&result.Event, result.Next); err != nil {
+			return PreviewResult{}, err
+		}
+	}
+	if src.Status.SinkURI != nil {
+		result.Target = src.Status.SinkURI.String()
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestValidateAndPreview(t *testing.T) {
+	sinkURI := apis.HTTP("sink.example.com")
+
+	testCases := map[string]struct {
+		spec    sourcesv1beta1.PingSourceSpec
+		wantErr bool
+	}{
+		"json data": {
+			spec: sourcesv1beta1.PingSourceSpec{
+				Schedule: "*/2 * * * *",
+				JsonData: `{"msg":"hello"}`,
+			},
+		},
+		"wrapped data": {
+			spec: sourcesv1beta1.PingSourceSpec{
+				Schedule: "0 * * * *",
+				JsonData: "some data",
+			},
+		},
+		"extension overrides": {
+			spec: sourcesv1beta1.PingSourceSpec{
+				SourceSpec: duckv1.SourceSpec{
+					CloudEventOverrides: &duckv1.CloudEventOverrides{
+						Extensions: map[string]string{"1": "one", "2": "two"},
+					},
+				},
+				Schedule: "* * * * *",
+			},
+		},
+		"invalid schedule": {
+			spec: sourcesv1beta1.PingSourceSpec{
+				Schedule: "every minute",
+			},
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			src := newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec = tc.spec
+				s.Spec.Sink = duckv1.Destination{URI: sinkURI}
+				s.Status.SinkURI = sinkURI
+			})
+
+			preview, err := ValidateAndPreview(src)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected a validation error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+
+			if preview.Target != sinkURI.String() {
+				t.Errorf("Expected target %q, got %q", sinkURI, preview.Target)
+			}
+			if !preview.Next.After(time.Now()) {
+				t.Error("Expected the next fire time to be in the future, got", preview.Next)
+			}
+
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+			entryID := mustAddSchedule(t, runner, src)
+			runner.cron.Entry(entryID).Job.Run()
+
+			if len(ce.Sent()) != 1 {
+				t.Fatal("Expected 1 event to be sent, got", len(ce.Sent()))
+			}
+			sent := ce.Sent()[0]
+			preview.Event.SetID(sent.ID())
+			if diff := cmp.Diff(sent.String(), preview.Event.String()); diff != "" {
+				t.Error("Unexpected preview (-sent, +preview):", diff)
+			}
+		})
+	}
+}
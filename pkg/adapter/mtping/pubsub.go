@@ -0,0 +1,208 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// pubsubScheme is the sink URI scheme selecting the Google Cloud Pub/Sub
+	// protocol. The sink URI has the form pubsub://<project>/<topic>, the
+	// topic having to exist.
+	pubsubScheme = "pubsub"
+
+	// pubsubEndpoint is the endpoint of the Pub/Sub REST API.
+	pubsubEndpoint = "https://pubsub.googleapis.com"
+
+	// pubsubScope is the OAuth2 scope of the application default
+	// credentials publishing to the topics.
+	pubsubScope = "https://www.googleapis.com/auth/pubsub"
+
+	// pubsubAttributePrefix prefixes the attributes of the messages holding
+	// the CloudEvents attributes, as in the Pub/Sub CloudEvents binding.
+	pubsubAttributePrefix = "ce-"
+	// pubsubContentType is the attribute of the messages holding the
+	// datacontenttype of the events.
+	pubsubContentType = "Content-Type"
+)
+
+// pubsubSender publishes CloudEvents to Pub/Sub topics in the binary mode
+// of the Pub/Sub CloudEvents binding, through the publish method of the
+// Pub/Sub REST API.
+type pubsubSender struct {
+	// endpoint is the endpoint of the Pub/Sub REST API, such as the one of
+	// an emulator, the requests to it not being authenticated. The
+	// application default credentials are used when empty.
+	endpoint string
+
+	mu     sync.Mutex
+	client *nethttp.Client
+}
+
+// pubsubMessage is a Pub/Sub message of the publish requests.
+type pubsubMessage struct {
+	Data       []byte            `json:"data,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// pubsubPublishRequest is the body of the publish requests.
+type pubsubPublishRequest struct {
+	Messages []*pubsubMessage `json:"messages"`
+}
+
+// Send publishes the event to the topic designated by target.
+func (s *pubsubSender) Send(ctx context.Context, target *url.URL, event cloudevents.Event) error {
+	project, topic := target.Host, strings.TrimPrefix(target.Path, "/")
+	if project == "" || topic == "" || strings.Contains(topic, "/") {
+		return fmt.Errorf("invalid pubsub sink %q, expected pubsub://<project>/<topic>", target)
+	}
+
+	msg := &pubsubMessage{}
+	if _, err := binding.Write(ctx, binding.ToMessage(&event), nil, msg); err != nil {
+		return err
+	}
+	body, err := json.Marshal(pubsubPublishRequest{Messages: []*pubsubMessage{msg}})
+	if err != nil {
+		return err
+	}
+
+	client, err := s.httpClient()
+	if err != nil {
+		return err
+	}
+	endpoint := s.endpoint
+	if endpoint == "" {
+		endpoint = pubsubEndpoint
+	}
+	u := strings.TrimSuffix(endpoint, "/") + "/v1/projects/" + url.PathEscape(project) + "/topics/" + url.PathEscape(topic) + ":publish"
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to publish to the pubsub topic %q of project %q: %s: %s", topic, project, resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+// httpClient returns the client of the Pub/Sub REST API, creating it on
+// first use.
+func (s *pubsubSender) httpClient() (*nethttp.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	if s.endpoint != "" {
+		s.client = &nethttp.Client{}
+		return s.client, nil
+	}
+	// The client outlives the sends.
+	ctx := context.Background()
+	ts, err := google.DefaultTokenSource(ctx, pubsubScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the pubsub credentials: %w", err)
+	}
+	s.client = oauth2.NewClient(ctx, ts)
+	return s.client, nil
+}
+
+// Close closes the idle connections of the client.
+func (s *pubsubSender) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+		s.client = nil
+	}
+}
+
+var _ binding.BinaryWriter = (*pubsubMessage)(nil)
+
+// Start implements binding.BinaryWriter.
+func (m *pubsubMessage) Start(ctx context.Context) error {
+	m.Attributes = make(map[string]string)
+	return nil
+}
+
+// SetAttribute implements binding.BinaryWriter.
+func (m *pubsubMessage) SetAttribute(attribute spec.Attribute, value interface{}) error {
+	name := pubsubAttributePrefix + attribute.Name()
+	if attribute.Kind() == spec.DataContentType {
+		name = pubsubContentType
+	}
+	return m.set(name, value)
+}
+
+// SetExtension implements binding.BinaryWriter.
+func (m *pubsubMessage) SetExtension(name string, value interface{}) error {
+	return m.set(pubsubAttributePrefix+name, value)
+}
+
+// SetData implements binding.BinaryWriter.
+func (m *pubsubMessage) SetData(data io.Reader) error {
+	var err error
+	m.Data, err = ioutil.ReadAll(data)
+	return err
+}
+
+// End implements binding.BinaryWriter.
+func (m *pubsubMessage) End(ctx context.Context) error {
+	return nil
+}
+
+// set sets the attribute name to the canonical string encoding of value,
+// deleting it when value is nil.
+func (m *pubsubMessage) set(name string, value interface{}) error {
+	if value == nil {
+		delete(m.Attributes, name)
+		return nil
+	}
+	s, err := types.Format(value)
+	if err != nil {
+		return err
+	}
+	m.Attributes[name] = s
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// fakePubSub is a fake Pub/Sub REST API recording the messages published
+// to its topic.
+type fakePubSub struct {
+	*httptest.Server
+	mu       sync.Mutex
+	messages []*pubsubMessage
+}
+
+func newFakePubSub(project, topic string) *fakePubSub {
+	fake := &fakePubSub{}
+	fake.Server = httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Method != nethttp.MethodPost || r.URL.Path != "/v1/projects/"+project+"/topics/"+topic+":publish" {
+			nethttp.Error(w, "topic not found", nethttp.StatusNotFound)
+			return
+		}
+		var req pubsubPublishRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+			return
+		}
+		fake.mu.Lock()
+		fake.messages = append(fake.messages, req.Messages...)
+		fake.mu.Unlock()
+		w.Write([]byte(`{"messageIds":["1"]}`))
+	}))
+	return fake
+}
+
+func (f *fakePubSub) Messages() []*pubsubMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*pubsubMessage(nil), f.messages...)
+}
+
+// toEvent reads the event of a message in the binary mode of the Pub/Sub
+// binding, whose attributes are named as the HTTP headers of the binary
+// mode of the HTTP binding.
+func toEvent(t *testing.T, msg *pubsubMessage) *cloudevents.Event {
+	header := nethttp.Header{}
+	for k, v := range msg.Attributes {
+		header.Set(k, v)
+	}
+	event, err := binding.ToEvent(context.Background(), cehttp.NewMessage(header, nethttp.NoBody))
+	if err != nil {
+		t.Fatal("Failed to read the event from the published message:", err)
+	}
+	if err := event.SetData(event.DataContentType(), msg.Data); err != nil {
+		t.Fatal("Failed to set the event data:", err)
+	}
+	return event
+}
+
+func TestPubSubSink(t *testing.T) {
+	srv := newFakePubSub("test-project", "pings")
+	defer srv.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithPubSubEndpoint(srv.URL))
+
+	sinkURI, _ := apis.ParseURL("pubsub://test-project/pings")
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+		s.Spec.SourceSpec = duckv1.SourceSpec{
+			CloudEventOverrides: &duckv1.CloudEventOverrides{
+				Extensions: map[string]string{"ext": "value"},
+			},
+		}
+	}))
+
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := len(ce.Sent()); got != 0 {
+		t.Error("Expected no event to be sent over HTTP, got", got)
+	}
+
+	messages := srv.Messages()
+	if len(messages) != 1 {
+		t.Fatal("Expected 1 published message, got", len(messages))
+	}
+	if ct := messages[0].Attributes[pubsubContentType]; ct != cloudevents.ApplicationJSON {
+		t.Errorf("Expected the %s attribute %q, got %q", pubsubContentType, cloudevents.ApplicationJSON, ct)
+	}
+	got := toEvent(t, messages[0])
+	if got.ID() == "" {
+		t.Error("Expected the published event to have an ID")
+	}
+	if got.Type() != sourcesv1beta1.PingSourceEventType {
+		t.Errorf("Expected the published event type %q, got %q", sourcesv1beta1.PingSourceEventType, got.Type())
+	}
+	if want := sourcesv1beta1.PingSourceSource("test-ns", "test-name"); got.Source() != want {
+		t.Errorf("Expected the published event source %q, got %q", want, got.Source())
+	}
+	if data := string(got.Data()); data != `{"body":"some data"}` {
+		t.Errorf("Expected the published event data %q, got %q", `{"body":"some data"}`, data)
+	}
+	if ext := got.Extensions()["ext"]; ext != "value" {
+		t.Errorf("Expected the published event extension ext to be %q, got %v", "value", ext)
+	}
+}
+
+func TestPubSubSinkMissingTopic(t *testing.T) {
+	srv := newFakePubSub("test-project", "pings")
+	defer srv.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx), WithPubSubEndpoint(srv.URL))
+
+	event := cloudevents.NewEvent()
+	event.SetID("id")
+	event.SetType(sourcesv1beta1.PingSourceEventType)
+	event.SetSource(sourcesv1beta1.PingSourceSource("test-ns", "test-name"))
+
+	for _, sink := range []string{"pubsub://test-project", "pubsub://test-project/unknown"} {
+		ctx = cloudevents.ContextWithTarget(context.Background(), sink)
+		if result := runner.send(ctx, runner.Client, event); cloudevents.IsACK(result) {
+			t.Errorf("Expected an error publishing to %s", sink)
+		}
+	}
+	if got := len(srv.Messages()); got != 0 {
+		t.Error("Expected no message to be published, got", got)
+	}
+}
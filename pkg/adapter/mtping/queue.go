@@ -0,0 +1,278 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"go.uber.org/zap"
+
+	kncloudevents "knative.dev/eventing/pkg/adapter/v2"
+	"knative.dev/eventing/pkg/diskqueue"
+)
+
+// queuedEvent is an event waiting to be sent again.
+type queuedEvent struct {
+	// Target is the sink URI of the event.
+	Target string `json:"target"`
+
+	// Namespace and Name identify the PingSource of the event.
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	Event cloudevents.Event `json:"event"`
+}
+
+// diskQueue persists the queued events, so they survive adapter restarts.
+type diskQueue struct {
+	*diskqueue.Queue
+}
+
+// newDiskQueue creates the queue of dir, maxBytes capping the total size of
+// the queued events. Zero means unbounded.
+func newDiskQueue(dir string, maxBytes int64) (*diskQueue, error) {
+	q, err := diskqueue.New(dir, 0, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &diskQueue{Queue: q}, nil
+}
+
+// Push persists the event. It returns diskqueue.ErrFull when the size cap
+// would be exceeded.
+func (q *diskQueue) Push(e queuedEvent) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = q.Queue.Push(b)
+	return err
+}
+
+// Load reads the queued event with the given ID.
+func (q *diskQueue) Load(id string) (queuedEvent, error) {
+	var e queuedEvent
+	b, err := q.Read(id)
+	if err != nil {
+		return e, err
+	}
+	err = json.Unmarshal(b, &e)
+	return e, err
+}
+
+// pendingFire holds the events of a fire in flight not sent yet.
+type pendingFire struct {
+	target          string
+	namespace, name string
+	// sensitive is true when the events carry data read from a Secret, not
+	// to be persisted.
+	sensitive bool
+
+	mu        sync.Mutex
+	events    []cloudevents.Event
+	persisted bool
+}
+
+// claim takes the next event to send. It returns false once the events
+// left were persisted. A nil pendingFire claims all the events.
+func (p *pendingFire) claim() bool {
+	if p == nil {
+		return true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.persisted {
+		return false
+	}
+	p.events = p.events[1:]
+	return true
+}
+
+// track records the events of a fire about to be sent to the target found
+// in ctx. It returns nil when the runner has no persistent queue.
+func (a *cronJobsRunner) track(ctx context.Context, events []cloudevents.Event) *pendingFire {
+	if a.queue == nil {
+		return nil
+	}
+	tag := kncloudevents.MetricTagFromContext(ctx)
+	p := &pendingFire{
+		target:    cecontext.TargetFrom(ctx).String(),
+		namespace: tag.Namespace,
+		name:      tag.Name,
+		sensitive: hasSensitiveData(ctx),
+		events:    events,
+	}
+	a.pendingMu.Lock()
+	a.pending[p] = struct{}{}
+	a.pendingMu.Unlock()
+	return p
+}
+
+func (a *cronJobsRunner) untrack(p *pendingFire) {
+	if p == nil {
+		return
+	}
+	a.pendingMu.Lock()
+	delete(a.pending, p)
+	a.pendingMu.Unlock()
+}
+
+// persistPending persists the events the fires in flight didn't send yet,
+// and makes them stop sending. The events being sent when it is called are
+// not persisted, they are queued by deliver only if their send fails.
+func (a *cronJobsRunner) persistPending() {
+	a.pendingMu.Lock()
+	defer a.pendingMu.Unlock()
+	persisted := 0
+	for p := range a.pending {
+		p.mu.Lock()
+		p.persisted = true
+		if p.sensitive {
+			a.Logger.Warnw("not persisting the in-flight cloudevents holding secret data, events are lost",
+				zap.String("namespace", p.namespace), zap.String("name", p.name), zap.Int("count", len(p.events)))
+			p.events = nil
+			p.mu.Unlock()
+			continue
+		}
+		for _, event := range p.events {
+			err := a.queue.Push(queuedEvent{
+				Target:    p.target,
+				Namespace: p.namespace,
+				Name:      p.name,
+				Event:     event,
+			})
+			if err != nil {
+				a.Logger.Errorw("failed to persist in-flight cloudevent, event is lost", zap.String("id", event.ID()), zap.Error(err))
+				continue
+			}
+			persisted++
+		}
+		p.events = nil
+		p.mu.Unlock()
+	}
+	if persisted > 0 {
+		a.Logger.Infow("persisted in-flight cloudevents", zap.Int("count", persisted))
+	}
+}
+
+// persist queues the event so it is sent again after a restart. The events
+// holding data read from a Secret are not persisted, not to be written to
+// the disk in clear.
+func (a *cronJobsRunner) persist(ctx context.Context, target string, event cloudevents.Event) {
+	if a.queue == nil {
+		return
+	}
+	if hasSensitiveData(ctx) {
+		a.Logger.Warnw("not persisting the cloudevent holding secret data, event is lost", zap.String("id", event.ID()))
+		return
+	}
+	tag := kncloudevents.MetricTagFromContext(ctx)
+	err := a.queue.Push(queuedEvent{
+		Target:    target,
+		Namespace: tag.Namespace,
+		Name:      tag.Name,
+		Event:     event,
+	})
+	if err != nil {
+		a.Logger.Errorw("failed to persist cloudevent, event is lost", zap.String("id", event.ID()), zap.Error(err))
+	}
+}
+
+// resendQueued sends the persisted events of the source of opts again,
+// through its client with the context of its fires, until the runner is
+// paused. Events failing again stay queued until the source is scheduled
+// again.
+func (a *cronJobsRunner) resendQueued(ctx context.Context, opts jobOptions) {
+	// A source scheduled again while its events are sent doesn't send them
+	// twice.
+	a.resendMu.Lock()
+	if a.resending[opts.stats.key] {
+		a.resendMu.Unlock()
+		return
+	}
+	a.resending[opts.stats.key] = true
+	a.resendMu.Unlock()
+	defer func() {
+		a.resendMu.Lock()
+		delete(a.resending, opts.stats.key)
+		a.resendMu.Unlock()
+	}()
+
+	names, err := a.queue.List()
+	if err != nil {
+		a.Logger.Errorw("failed to list persisted cloudevents", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-a.pausedCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return
+		}
+		e, err := a.queue.Load(name)
+		if err != nil {
+			a.Logger.Errorw("failed to load persisted cloudevent, discarding it", zap.String("id", name), zap.Error(err))
+			_ = a.queue.Remove(name)
+			continue
+		}
+		if e.Namespace != opts.namespace || e.Name != opts.name {
+			continue
+		}
+
+		if result := a.send(cloudevents.ContextWithTarget(ctx, e.Target), opts.client, e.Event); !cloudevents.IsACK(result) {
+			a.Logger.Warnw("failed to send persisted cloudevent, keeping it queued", zap.String("id", e.Event.ID()), zap.Any("result", result))
+			continue
+		}
+		if err := a.queue.Remove(name); err != nil {
+			a.Logger.Errorw("failed to remove sent cloudevent from the persistent queue", zap.String("id", e.Event.ID()), zap.Error(err))
+		}
+	}
+}
+
+// RemoveQueued removes the events persisted for the source with the given
+// namespace and name, for them not to be sent once the source is deleted.
+func (a *cronJobsRunner) RemoveQueued(namespace, name string) error {
+	if a.queue == nil {
+		return nil
+	}
+	names, err := a.queue.List()
+	if err != nil {
+		return err
+	}
+	for _, file := range names {
+		e, err := a.queue.Load(file)
+		if err != nil || (e.Namespace == namespace && e.Name == name) {
+			if err := a.queue.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+	"knative.dev/eventing/pkg/diskqueue"
+)
+
+func TestPersistentQueueResendOnRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mtping-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+
+	failing := adaptertesting.NewTestClientWithResults(errors.New("sink unavailable"))
+	runner := NewCronJobsRunner(failing, kubeclient.Get(ctx), logger, WithPersistentQueue(dir, 0))
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	runner.cron.Entry(entryID).Job.Run()
+
+	queued, err := runner.queue.List()
+	if err != nil {
+		t.Fatal("Failed to list the queue:", err)
+	}
+	if len(queued) != 1 {
+		t.Fatal("Expected 1 queued event, got", len(queued))
+	}
+	want, _ := runner.queue.Load(queued[0])
+
+	// Simulate a restart, the queued events being sent once the source is
+	// scheduled.
+	ce := adaptertesting.NewTestClient()
+	restarted := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger, WithPersistentQueue(dir, 0))
+	mustAddSchedule(t, restarted, newTestSource())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(ce.Sent()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	validateSent(t, ce, `{"body":"some data"}`, nil)
+	if got := ce.Sent()[0].ID(); got != want.Event.ID() {
+		t.Errorf("Expected the queued event %q to be sent, got %q", want.Event.ID(), got)
+	}
+
+	for time.Now().Before(deadline) {
+		if queued, _ = restarted.queue.List(); len(queued) == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(queued) != 0 {
+		t.Error("Expected the queue to be empty once sent, got", queued)
+	}
+}
+
+func TestPersistentQueuePersistInFlightOnStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mtping-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+
+	ce := adaptertesting.NewTestClient()
+	blocking := &blockingClient{Client: ce, release: make(chan struct{})}
+	runner := NewCronJobsRunner(blocking, kubeclient.Get(ctx), logger, WithPersistentQueue(dir, 0), WithDrainTimeout(100*time.Millisecond))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.Ordered = true
+	}))
+
+	// The first fire blocks sending its event while the others wait for it.
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runner.cron.Entry(entryID).Job.Run()
+		}()
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !(atomic.LoadInt32(&blocking.inflight) == 1 && pendingFires(runner) == 3) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	runner.Stop()
+	close(blocking.release)
+	wg.Wait()
+
+	if got := len(ce.Sent()); got != 1 {
+		t.Fatal("Expected only the event in flight to be sent, got", got)
+	}
+	queued, err := runner.queue.List()
+	if err != nil {
+		t.Fatal("Failed to list the queue:", err)
+	}
+	if len(queued) != 2 {
+		t.Fatal("Expected the events of the 2 waiting fires to be queued, got", len(queued))
+	}
+
+	// Simulate a restart.
+	restartedCE := adaptertesting.NewTestClient()
+	restarted := NewCronJobsRunner(restartedCE, kubeclient.Get(ctx), logger, WithPersistentQueue(dir, 0))
+	mustAddSchedule(t, restarted, newTestSource())
+
+	for len(restartedCE.Sent()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := len(restartedCE.Sent()); got != 2 {
+		t.Fatal("Expected the 2 queued events to be sent after the restart, got", got)
+	}
+	ids := map[string]bool{ce.Sent()[0].ID(): true}
+	for _, event := range restartedCE.Sent() {
+		ids[event.ID()] = true
+	}
+	if len(ids) != 3 {
+		t.Errorf("Expected the 3 events to be sent once, got %d distinct IDs", len(ids))
+	}
+}
+
+func TestPersistentQueueResendThroughSourceClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mtping-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	received := make(chan string, 2)
+	sink := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		received <- r.Header.Get("Ce-Id")
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithPersistentQueue(dir, 0))
+	for _, name := range []string{"test-name", "deleted-name"} {
+		event := cloudevents.NewEvent()
+		event.SetID(name)
+		event.SetType(sourcesv1beta1.PingSourceEventType)
+		event.SetSource(sourcesv1beta1.PingSourceSource("test-ns", name))
+		if err := runner.queue.Push(queuedEvent{Target: sink.URL, Namespace: "test-ns", Name: name, Event: event}); err != nil {
+			t.Fatal("Failed to queue the event:", err)
+		}
+	}
+
+	sinkURI, _ := apis.ParseURL(sink.URL)
+	mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		// Any customization for the source to get its own HTTP client.
+		s.Annotations = map[string]string{VerboseLoggingAnnotation: "true"}
+		s.Status.SinkURI = sinkURI
+	}))
+
+	select {
+	case id := <-received:
+		if id != "test-name" {
+			t.Errorf("Expected the event of the scheduled source to be sent, got %q", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the queued event to be sent through the client of the source")
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Error("Expected the default client not to be used, got events sent", got)
+	}
+
+	if err := runner.RemoveQueued("test-ns", "deleted-name"); err != nil {
+		t.Fatal("Failed to remove the queued events:", err)
+	}
+	queued, _ := runner.queue.List()
+	for _, name := range queued {
+		if e, _ := runner.queue.Load(name); e.Name == "deleted-name" {
+			t.Error("Expected the events of the deleted source to be removed, got", name)
+		}
+	}
+	select {
+	case id := <-received:
+		t.Errorf("Expected the event of the deleted source not to be sent, got %q", id)
+	default:
+	}
+}
+
+func TestPersistentQueueSkipsSecretData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mtping-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "payload", Namespace: "test-ns"},
+		Data:       map[string][]byte{"data": []byte(`{"token":"s3cr3t"}`)},
+	}
+	if _, err := kubeclient.Get(ctx).CoreV1().Secrets("test-ns").Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create the secret:", err)
+	}
+
+	failing := adaptertesting.NewTestClientWithResults(errors.New("sink unavailable"))
+	runner := NewCronJobsRunner(failing, kubeclient.Get(ctx), logging.FromContext(ctx), WithPersistentQueue(dir, 0))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.DataFromSecret = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "payload"},
+			Key:                  "data",
+		}
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	if queued, _ := runner.queue.List(); len(queued) != 0 {
+		t.Error("Expected the event holding secret data not to be persisted, got", queued)
+	}
+}
+
+// blockingClient blocks the sends until release is closed, signaling each
+// of them to sending when set.
+type blockingClient struct {
+	cloudevents.Client
+
+	inflight int32
+	release  chan struct{}
+	sending  chan struct{}
+}
+
+func (c *blockingClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	atomic.AddInt32(&c.inflight, 1)
+	defer atomic.AddInt32(&c.inflight, -1)
+	if c.sending != nil {
+		c.sending <- struct{}{}
+	}
+	<-c.release
+	return c.Client.Send(ctx, event)
+}
+
+// waitSignal waits for ch to be signaled or closed.
+func waitSignal(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for", what)
+	}
+}
+
+func pendingFires(a *cronJobsRunner) int {
+	a.pendingMu.Lock()
+	defer a.pendingMu.Unlock()
+	return len(a.pending)
+}
+
+func TestPersistentQueueSizeCap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mtping-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	event := cloudevents.NewEvent()
+	event.SetID("id")
+	event.SetType(sourcesv1beta1.PingSourceEventType)
+	event.SetSource(sourcesv1beta1.PingSourceSource("test-ns", "test-name"))
+
+	q, err := newDiskQueue(dir, 1024)
+	if err != nil {
+		t.Fatal("Failed to create the queue:", err)
+	}
+
+	pushed := 0
+	for ; pushed < 100; pushed++ {
+		if err := q.Push(queuedEvent{Target: "http://sink", Event: event}); err != nil {
+			if !errors.Is(err, diskqueue.ErrFull) {
+				t.Fatal("Expected diskqueue.ErrFull, got", err)
+			}
+			break
+		}
+	}
+	if pushed == 0 || pushed == 100 {
+		t.Fatal("Expected the queue to fill up after some events, pushed", pushed)
+	}
+
+	queued, _ := q.List()
+	if len(queued) != pushed {
+		t.Errorf("Expected %d queued events, got %d", pushed, len(queued))
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RateLimiter bounds the rate of the fires of all the replicas of the
+// adapter sharing it.
+type RateLimiter interface {
+	// Take consumes a fire from the shared budget and returns true, unless
+	// the budget is exhausted.
+	Take(ctx context.Context) (bool, error)
+}
+
+const (
+	// rateLimitTokensKey and rateLimitUpdatedKey are the keys of the
+	// ConfigMap holding the token bucket of a configMapRateLimiter.
+	rateLimitTokensKey  = "tokens"
+	rateLimitUpdatedKey = "updated"
+
+	// rateLimitConflictRetries bounds the attempts to update the bucket
+	// modified concurrently by another replica.
+	rateLimitConflictRetries = 5
+)
+
+// configMapRateLimiter is a RateLimiter keeping a token bucket in a
+// ConfigMap, updated with optimistic concurrency by the replicas.
+type configMapRateLimiter struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+	name       string
+
+	// rate is the number of tokens added per second, up to burst.
+	rate  float64
+	burst float64
+
+	clock clock.Clock
+	// mu serializes the updates of this replica.
+	mu sync.Mutex
+}
+
+// NewConfigMapRateLimiter returns a RateLimiter allowing rate fires per
+// second across the replicas, with bursts of up to burst fires. The replicas
+// coordinate through the given ConfigMap, created on first use.
+func NewConfigMapRateLimiter(kubeClient kubernetes.Interface, namespace, name string, rate float64, burst int) RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &configMapRateLimiter{
+		kubeClient: kubeClient,
+		namespace:  namespace,
+		name:       name,
+		rate:       rate,
+		burst:      float64(burst),
+		clock:      clock.RealClock{},
+	}
+}
+
+func (l *configMapRateLimiter) Take(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cms := l.kubeClient.CoreV1().ConfigMaps(l.namespace)
+	for attempt := 0; attempt < rateLimitConflictRetries; attempt++ {
+		now := l.clock.Now()
+		cm, err := cms.Get(ctx, l.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// The first fire starts with a full bucket.
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: l.namespace, Name: l.name},
+				Data:       l.bucket(l.burst-1, now),
+			}
+			if _, err := cms.Create(ctx, cm, metav1.CreateOptions{}); apierrors.IsAlreadyExists(err) {
+				continue
+			} else if err != nil {
+				return false, err
+			}
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		tokens := l.refill(cm.Data, now)
+		if tokens < 1 {
+			return false, nil
+		}
+		cm = cm.DeepCopy()
+		cm.Data = l.bucket(tokens-1, now)
+		if _, err := cms.Update(ctx, cm, metav1.UpdateOptions{}); apierrors.IsConflict(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, errors.New("rate limit bucket updated concurrently too many times")
+}
+
+// refill returns the tokens of the bucket stored in data, refilled for the
+// time elapsed since its last update. A corrupted bucket is reset to full.
+func (l *configMapRateLimiter) refill(data map[string]string, now time.Time) float64 {
+	tokens, err := strconv.ParseFloat(data[rateLimitTokensKey], 64)
+	if err != nil {
+		return l.burst
+	}
+	updated, err := time.Parse(time.RFC3339Nano, data[rateLimitUpdatedKey])
+	if err != nil {
+		return l.burst
+	}
+	if elapsed := now.Sub(updated).Seconds(); elapsed > 0 {
+		tokens += elapsed * l.rate
+	}
+	return math.Min(tokens, l.burst)
+}
+
+func (l *configMapRateLimiter) bucket(tokens float64, now time.Time) map[string]string {
+	return map[string]string{
+		rateLimitTokensKey:  strconv.FormatFloat(tokens, 'f', -1, 64),
+		rateLimitUpdatedKey: now.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// tokenBucket caps the rate of the sends of a source, allowing bursts. The
+// sends over the rate are delayed rather than dropped.
+type tokenBucket struct {
+	// rate is the number of tokens added per second, up to burst.
+	rate  float64
+	burst float64
+	clock clock.Clock
+
+	mu      sync.Mutex
+	tokens  float64
+	updated time.Time
+}
+
+func newTokenBucket(rate float64, burst int, clock clock.Clock) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		clock:   clock,
+		tokens:  float64(burst),
+		updated: clock.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait for it to be
+// available, zero when it is right away. The tokens are reserved in turn,
+// the later ones waiting longer.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// allowFire takes a token from the rate limit of settings for a fire of
+// source. It returns false when the fire is dropped.
+func (a *cronJobsRunner) allowFire(ctx context.Context, settings *fireSettings, source string) bool {
+	if settings.limiter == nil {
+		return true
+	}
+	allowed, err := settings.limiter.Take(ctx)
+	if err != nil {
+		// Better emit over the limit than not at all.
+		a.Logger.Warnw("failed to take from the rate limit, firing anyway", zap.String("source", source), zap.Error(err))
+		return true
+	}
+	if !allowed {
+		atomic.AddUint64(&a.rateLimited, 1)
+		a.Logger.Debugw("rate limit reached, dropping fire", zap.String("source", source))
+	}
+	return allowed
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestRateLimitAcrossReplicas(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	kubeClient := kubeclient.Get(ctx)
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	// Two replicas, each with its own limiter, sharing the bucket of a
+	// ConfigMap: 2 fires per second with bursts of 4.
+	clients := []*adaptertesting.TestCloudEventsClient{adaptertesting.NewTestClient(), adaptertesting.NewTestClient()}
+	runners := make([]*cronJobsRunner, 0, len(clients))
+	jobs := make([]func(), 0, len(clients)*2)
+	for i, ce := range clients {
+		limiter := NewConfigMapRateLimiter(kubeClient, "knative-testing", "rate-limit", 2, 4)
+		limiter.(*configMapRateLimiter).clock = fakeClock
+		runner := NewCronJobsRunner(ce, kubeClient, logging.FromContext(ctx), WithRateLimiter(limiter))
+		runners = append(runners, runner)
+		for j := 0; j < 2; j++ {
+			id := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Name = fmt.Sprintf("test-name-%d-%d", i, j)
+			}))
+			jobs = append(jobs, runner.cron.Entry(id).Job.Run)
+		}
+	}
+	sent := func() int {
+		return len(clients[0].Sent()) + len(clients[1].Sent())
+	}
+	fires := 0
+	fireAll := func() {
+		fires += len(jobs)
+		for _, job := range jobs {
+			job()
+		}
+	}
+
+	// The burst is used up by the first fires, across the replicas.
+	fireAll()
+	fireAll()
+	if got := sent(); got != 4 {
+		t.Fatalf("Expected the burst of 4 events to be sent, got %d", got)
+	}
+	if len(clients[0].Sent()) == 0 || len(clients[1].Sent()) == 0 {
+		t.Error("Expected both replicas to send events within the burst")
+	}
+
+	// Then the combined rate is capped.
+	for second := 1; second <= 3; second++ {
+		fakeClock.Step(time.Second)
+		fireAll()
+		if got, max := sent(), 4+2*second; got > max {
+			t.Fatalf("Expected at most %d events sent after %ds, got %d", max, second, got)
+		}
+	}
+	if got := sent(); got != 10 {
+		t.Errorf("Expected 10 events sent, got %d", got)
+	}
+
+	var dropped uint64
+	for _, runner := range runners {
+		dropped += runner.Stats().RateLimited
+	}
+	if want := uint64(fires - 10); dropped != want {
+		t.Errorf("Expected %d fires to be rate limited, got %d", want, dropped)
+	}
+}
+
+func TestRateLimitCorruptedBucket(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	kubeClient := kubeclient.Get(ctx)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-testing", Name: "rate-limit"},
+		Data:       map[string]string{rateLimitTokensKey: "many"},
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps("knative-testing").Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create configmap:", err)
+	}
+
+	limiter := NewConfigMapRateLimiter(kubeClient, "knative-testing", "rate-limit", 1, 1)
+	if allowed, err := limiter.Take(ctx); err != nil || !allowed {
+		t.Errorf("Expected a corrupted bucket to be reset, got (%v, %v)", allowed, err)
+	}
+	if allowed, err := limiter.Take(ctx); err != nil || allowed {
+		t.Errorf("Expected the reset bucket to be exhausted, got (%v, %v)", allowed, err)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	bucket := newTokenBucket(1, 3, fakeClock)
+
+	// The burst goes at once, the next sends waiting for the rate.
+	for i, want := range []time.Duration{0, 0, 0, time.Second, 2 * time.Second} {
+		if got := bucket.reserve(); got != want {
+			t.Errorf("Expected send %d to wait %v, got %v", i+1, want, got)
+		}
+	}
+
+	// The reserved tokens are refilled first.
+	fakeClock.Step(2 * time.Second)
+	if got := bucket.reserve(); got != time.Second {
+		t.Errorf("Expected the send after 2s to wait 1s, got %v", got)
+	}
+
+	// Idle, the bucket refills up to the burst.
+	fakeClock.Step(time.Minute)
+	for i := 0; i < 3; i++ {
+		if got := bucket.reserve(); got != 0 {
+			t.Errorf("Expected send %d of the burst not to wait, got %v", i+1, got)
+		}
+	}
+	if got := bucket.reserve(); got != time.Second {
+		t.Errorf("Expected the send after the burst to wait 1s, got %v", got)
+	}
+}
+
+func TestEmissionRate(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	fakeClock := clock.NewFakeClock(time.Now())
+	runner.clock = fakeClock
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{
+			EmissionRateAnnotation:  "1",
+			EmissionBurstAnnotation: "2",
+		}
+	}))
+	job := runner.cron.Entry(entryID).Job
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			job.Run()
+		}
+	}()
+
+	// The burst is sent at once, the third fire waiting for a token.
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatal("Expected the third fire to be throttled")
+	}
+	if got := len(ce.Sent()); got != 2 {
+		t.Errorf("Expected the 2 fires of the burst to be sent, got %d", got)
+	}
+
+	fakeClock.Step(time.Second)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the third fire to be sent once a token is available")
+	}
+	if got := len(ce.Sent()); got != 3 {
+		t.Errorf("Expected 3 events sent, got %d", got)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"sync/atomic"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// recoverTick returns job recovering from its panics. The schedule registered
+// under entry is removed once job panicked on maxPanics consecutive fires.
+func (a *cronJobsRunner) recoverTick(source *sourcesv1beta1.PingSource, opts jobOptions, entry *int64, job func()) func() {
+	namespace, name := source.Namespace, source.Name
+	var panics int32
+	return func() {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				atomic.StoreInt32(&panics, 0)
+				return
+			}
+			n := atomic.AddInt32(&panics, 1)
+			if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+				a.Logger.Errorw("cron job panicked", zap.String("source", opts.stats.key), zap.Any("panic", recovered),
+					zap.Int32("consecutive", n), zap.Int("suppressed", suppressed))
+			}
+			if a.maxPanics > 0 && int(n) == a.maxPanics {
+				a.Logger.Errorw("cron job panicked on too many consecutive fires, removing schedule", zap.String("source", opts.stats.key))
+				a.remove(cron.EntryID(atomic.LoadInt64(entry)))
+				if a.panicking != nil {
+					a.panicking(namespace, name, recovered)
+				}
+			}
+		}()
+		job()
+	}
+}
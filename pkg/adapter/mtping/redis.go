@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+const (
+	// redisScheme is the sink URI scheme selecting the Redis Streams protocol.
+	// The sink URI has the form redis://[user:password@]host[:port]/<stream>.
+	redisScheme = "redis"
+
+	defaultRedisPort = "6379"
+)
+
+// redisSender appends CloudEvents to a Redis Stream using XADD.
+type redisSender struct {
+	dialer net.Dialer
+}
+
+// Send appends the event attributes and data as the fields of a new entry
+// of the stream designated by target. It returns the ID of the new entry.
+func (s *redisSender) Send(ctx context.Context, target *url.URL, event cloudevents.Event) (string, error) {
+	stream := strings.TrimPrefix(target.Path, "/")
+	if stream == "" {
+		return "", fmt.Errorf("missing stream name in redis sink %q", target.Host)
+	}
+
+	conn, rw, err := s.dial(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	return redisDo(rw, append([]string{"XADD", stream, "*"}, redisFields(event)...)...)
+}
+
+// dial connects and authenticates to the Redis server designated by target.
+func (s *redisSender) dial(ctx context.Context, target *url.URL) (net.Conn, *bufio.ReadWriter, error) {
+	host := target.Host
+	if target.Port() == "" {
+		host = net.JoinHostPort(target.Hostname(), defaultRedisPort)
+	}
+
+	conn, err := s.dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	if target.User != nil {
+		args := []string{"AUTH"}
+		if password, ok := target.User.Password(); ok {
+			if username := target.User.Username(); username != "" {
+				args = append(args, username)
+			}
+			args = append(args, password)
+		} else {
+			args = append(args, target.User.Username())
+		}
+		if _, err := redisDo(rw, args...); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	return conn, rw, nil
+}
+
+// redisFields flattens the event into stream entry field/value pairs.
+func redisFields(event cloudevents.Event) []string {
+	fields := []string{
+		"specversion", event.SpecVersion(),
+		"id", event.ID(),
+		"source", event.Source(),
+		"type", event.Type(),
+	}
+	if ct := event.DataContentType(); ct != "" {
+		fields = append(fields, "datacontenttype", ct)
+	}
+	if !event.Time().IsZero() {
+		fields = append(fields, "time", event.Time().Format(time.RFC3339Nano))
+	}
+
+	extensions := event.Extensions()
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, name, fmt.Sprint(extensions[name]))
+	}
+
+	return append(fields, "data", string(event.Data()))
+}
+
+// redisDo writes a command encoded as a RESP array of bulk strings and
+// reads a single reply.
+func redisDo(rw *bufio.ReadWriter, args ...string) (string, error) {
+	fmt.Fprintf(rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := rw.Flush(); err != nil {
+		return "", err
+	}
+	return redisReadReply(rw.Reader)
+}
+
+func redisReadReply(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return "", errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("redis: %s", line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("malformed redis reply %q", line)
+		}
+		if n < 0 {
+			return "", nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	default:
+		return "", fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestRedisStreamSink(t *testing.T) {
+	redis := newFakeRedis(t)
+	defer redis.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	sinkURI, _ := apis.ParseURL(fmt.Sprintf("redis://%s/pings", redis.Addr()))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+		s.Spec.SourceSpec = duckv1.SourceSpec{
+			CloudEventOverrides: &duckv1.CloudEventOverrides{
+				Extensions: map[string]string{"ext": "value"},
+			},
+		}
+	}))
+
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := len(ce.Sent()); got != 0 {
+		t.Error("Expected no event to be sent over HTTP, got", got)
+	}
+
+	entries := redis.Entries("pings")
+	if len(entries) != 1 {
+		t.Fatal("Expected 1 stream entry, got", len(entries))
+	}
+
+	want := map[string]string{
+		"specversion":     "1.0",
+		"source":          sourcesv1beta1.PingSourceSource("test-ns", "test-name"),
+		"type":            sourcesv1beta1.PingSourceEventType,
+		"datacontenttype": "application/json",
+		"ext":             "value",
+		"data":            `{"body":"some data"}`,
+	}
+	for k, v := range want {
+		if got := entries[0][k]; got != v {
+			t.Errorf("Expected stream entry field %q to be %q, got %q", k, v, got)
+		}
+	}
+	if entries[0]["id"] == "" {
+		t.Error("Expected stream entry to carry the event id")
+	}
+}
+
+func TestRedisStreamSinkMissingStream(t *testing.T) {
+	redis := newFakeRedis(t)
+	defer redis.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	sinkURI, _ := apis.ParseURL(fmt.Sprintf("redis://%s", redis.Addr()))
+	event := cloudevents.NewEvent()
+	event.SetID("id")
+	event.SetType(sourcesv1beta1.PingSourceEventType)
+	event.SetSource(sourcesv1beta1.PingSourceSource("test-ns", "test-name"))
+
+	ctx = cloudevents.ContextWithTarget(context.Background(), sinkURI.String())
+	if result := runner.send(ctx, runner.Client, event); cloudevents.IsACK(result) {
+		t.Error("Expected an error when the stream name is missing")
+	}
+}
+
+// fakeRedis is a minimal RESP server recording XADD and SET commands.
+type fakeRedis struct {
+	t        *testing.T
+	listener net.Listener
+
+	mu      sync.Mutex
+	streams map[string][]map[string]string
+	keys    map[string]string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Failed to listen:", err)
+	}
+	r := &fakeRedis{t: t, listener: l, streams: make(map[string][]map[string]string), keys: make(map[string]string)}
+	go r.serve()
+	return r
+}
+
+func (r *fakeRedis) Addr() string {
+	return r.listener.Addr().String()
+}
+
+func (r *fakeRedis) Close() {
+	r.listener.Close()
+}
+
+func (r *fakeRedis) Entries(stream string) []map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.streams[stream]
+}
+
+func (r *fakeRedis) serve() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		go r.handle(conn)
+	}
+}
+
+func (r *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(args[0]) {
+		case "XADD":
+			if len(args) < 3 || len(args[3:])%2 != 0 {
+				fmt.Fprint(conn, "-ERR wrong number of arguments for 'xadd' command\r\n")
+				continue
+			}
+			entry := make(map[string]string)
+			for i := 3; i < len(args); i += 2 {
+				entry[args[i]] = args[i+1]
+			}
+			r.mu.Lock()
+			r.streams[args[1]] = append(r.streams[args[1]], entry)
+			id := fmt.Sprintf("%d-0", len(r.streams[args[1]]))
+			r.mu.Unlock()
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(id), id)
+		case "SET":
+			if len(args) < 3 {
+				fmt.Fprint(conn, "-ERR wrong number of arguments for 'set' command\r\n")
+				continue
+			}
+			nx := false
+			for _, opt := range args[3:] {
+				nx = nx || strings.ToUpper(opt) == "NX"
+			}
+			r.mu.Lock()
+			_, exists := r.keys[args[1]]
+			if !exists || !nx {
+				r.keys[args[1]] = args[2]
+			}
+			r.mu.Unlock()
+			if exists && nx {
+				fmt.Fprint(conn, "$-1\r\n")
+			} else {
+				fmt.Fprint(conn, "+OK\r\n")
+			}
+		case "GET":
+			if len(args) != 2 {
+				fmt.Fprint(conn, "-ERR wrong number of arguments for 'get' command\r\n")
+				continue
+			}
+			r.mu.Lock()
+			value, exists := r.keys[args[1]]
+			r.mu.Unlock()
+			if exists {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+			} else {
+				fmt.Fprint(conn, "$-1\r\n")
+			}
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
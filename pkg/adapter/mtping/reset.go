@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"io"
+	nethttp "net/http"
+	"syscall"
+)
+
+// resetRetryRoundTripper retries once the requests whose connection is
+// reset or closed by the server before it responds. The idle connections
+// are closed before retrying, as they likely went stale along with the
+// reset one, for the retry to dial a new connection.
+type resetRetryRoundTripper struct {
+	next nethttp.RoundTripper
+}
+
+var _ nethttp.RoundTripper = (*resetRetryRoundTripper)(nil)
+
+func (t *resetRetryRoundTripper) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err == nil || !connectionReset(err) || req.Context().Err() != nil {
+		return resp, err
+	}
+
+	// The body was consumed by the first attempt.
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != nethttp.NoBody {
+		if req.GetBody == nil {
+			return resp, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return resp, err
+		}
+		retry.Body = body
+	}
+	if idler, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		idler.CloseIdleConnections()
+	}
+	return t.next.RoundTrip(retry)
+}
+
+// connectionReset tells whether err is the connection being reset, or
+// closed, by the server.
+func connectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
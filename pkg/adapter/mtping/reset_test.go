@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync"
+	"syscall"
+	"testing"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestConnectionReset(t *testing.T) {
+	testCases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"reset":       {err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}, want: true},
+		"broken pipe": {err: &net.OpError{Op: "write", Err: syscall.EPIPE}, want: true},
+		"closed":      {err: fmt.Errorf("post: %w", io.EOF), want: true},
+		"refused":     {err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}},
+		"other":       {err: errors.New("boom")},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if got := connectionReset(tc.err); got != tc.want {
+				t.Errorf("connectionReset(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryOnReset(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+		bodies   []string
+	)
+	sink := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		mu.Lock()
+		attempts++
+		first := attempts == 1
+		mu.Unlock()
+		if first {
+			// Reset the connection without responding.
+			conn, _, err := w.(nethttp.Hijacker).Hijack()
+			if err != nil {
+				t.Error("Failed to hijack the connection:", err)
+				return
+			}
+			_ = conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	reporter := newFakeReporter()
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx),
+		WithStatsReporter(reporter))
+
+	sinkURI, _ := apis.ParseURL(sink.URL)
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+		s.Annotations = map[string]string{RetryOnResetAnnotation: "true"}
+		s.Spec.ContentType = "text/plain"
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Fatal("Expected the send to be retried once after the reset, got attempts:", attempts)
+	}
+	if len(bodies) != 1 || bodies[0] != "some data" {
+		t.Errorf("Expected the retry to replay the body, got %q", bodies)
+	}
+	if got := reporter.sent["test-ns/test-name"]; got != 1 {
+		t.Errorf("Expected the retried event to be reported sent, got %d", got)
+	}
+	if got := reporter.failed["test-ns/test-name"]; got != 0 {
+		t.Errorf("Expected no failed event reported, got %d", got)
+	}
+}
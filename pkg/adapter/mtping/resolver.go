@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/dynamic"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// addressResolver resolves the URL of Addressable sinks at fire time, so
+// that address changes are picked up without waiting for the source to be
+// reconciled.
+type addressResolver struct {
+	client dynamic.Interface
+	ttl    time.Duration
+	clock  clock.Clock
+
+	mu    sync.Mutex
+	cache map[string]resolvedAddress
+}
+
+type resolvedAddress struct {
+	url     *apis.URL
+	expires time.Time
+}
+
+func newAddressResolver(client dynamic.Interface, ttl time.Duration) *addressResolver {
+	return &addressResolver{
+		client: client,
+		ttl:    ttl,
+		clock:  clock.RealClock{},
+		cache:  make(map[string]resolvedAddress),
+	}
+}
+
+// Resolve returns the URL of the given sink. The address of the referenced
+// Addressable is cached for the resolver TTL. When the address can't be
+// read, the last known one is used, if any.
+func (r *addressResolver) Resolve(ctx context.Context, namespace string, sink duckv1.Destination) (*apis.URL, error) {
+	if sink.Ref == nil {
+		return nil, errors.New("sink has no reference")
+	}
+	ref := sink.Ref
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	key := fmt.Sprintf("%s/%s/%s/%s", ref.APIVersion, ref.Kind, namespace, ref.Name)
+
+	now := r.clock.Now()
+	r.mu.Lock()
+	cached, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return withSinkURI(cached.url, sink.URI), nil
+	}
+
+	url, err := r.address(ctx, namespace, ref)
+	if err != nil {
+		if ok {
+			return withSinkURI(cached.url, sink.URI), nil
+		}
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cache[key] = resolvedAddress{url: url, expires: now.Add(r.ttl)}
+	r.mu.Unlock()
+	return withSinkURI(url, sink.URI), nil
+}
+
+// address reads the status.address.url of the referenced Addressable.
+func (r *addressResolver) address(ctx context.Context, namespace string, ref *duckv1.KReference) (*apis.URL, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	gvr := apis.KindToResource(gv.WithKind(ref.Kind))
+
+	obj, err := r.client.Resource(gvr).Namespace(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	raw, found, err := unstructured.NestedString(obj.Object, "status", "address", "url")
+	if err != nil {
+		return nil, err
+	}
+	if !found || raw == "" {
+		return nil, fmt.Errorf("%s %s/%s has no address", ref.Kind, namespace, ref.Name)
+	}
+	return apis.ParseURL(raw)
+}
+
+// withSinkURI resolves the optional sink URI relative to the address.
+func withSinkURI(address, uri *apis.URL) *apis.URL {
+	if uri == nil {
+		return address
+	}
+	return address.ResolveReference(uri)
+}
+
+// resolveSink returns ctx targeting the address the sink reference of opts
+// resolves to, or the last reconciled address when it can't be resolved.
+func (a *cronJobsRunner) resolveSink(ctx context.Context, opts jobOptions, source string) context.Context {
+	if opts.sink == nil {
+		return ctx
+	}
+	sinkURI, err := a.resolver.Resolve(ctx, opts.namespace, *opts.sink)
+	if err != nil {
+		a.Logger.Warnw("failed to resolve sink, using the last reconciled address", zap.String("source", source), zap.Error(err))
+		return ctx
+	}
+	sinkURI = sourcesv1beta1.PrefixSinkPath(sinkURI, opts.sinkPathPrefix)
+	return cloudevents.ContextWithTarget(ctx, sinkURI.String())
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// targetRecorder records the targets the events are sent to.
+type targetRecorder struct {
+	cloudevents.Client
+
+	mu      sync.Mutex
+	targets []string
+}
+
+func (c *targetRecorder) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	c.mu.Lock()
+	c.targets = append(c.targets, cecontext.TargetFrom(ctx).String())
+	c.mu.Unlock()
+	return c.Client.Send(ctx, event)
+}
+
+func addressable(url string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.knative.dev/v1",
+		"kind":       "Sink",
+		"metadata": map[string]interface{}{
+			"namespace": "test-ns",
+			"name":      "test-sink",
+		},
+		"status": map[string]interface{}{
+			"address": map[string]interface{}{
+				"url": url,
+			},
+		},
+	}}
+}
+
+func TestSinkResolution(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), addressable("http://first.example.com"))
+	gvr := schema.GroupVersionResource{Group: "example.knative.dev", Version: "v1", Resource: "sinks"}
+
+	ce := &targetRecorder{Client: adaptertesting.NewTestClient()}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithSinkResolution(dynamicClient, time.Minute))
+	fakeClock := clock.NewFakeClock(time.Now())
+	runner.resolver.clock = fakeClock
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("reconciled.example.com")
+		s.Spec.SourceSpec = duckv1.SourceSpec{
+			Sink: duckv1.Destination{
+				Ref: &duckv1.KReference{
+					APIVersion: "example.knative.dev/v1",
+					Kind:       "Sink",
+					Name:       "test-sink",
+				},
+				URI: &apis.URL{Path: "/path"},
+			},
+		}
+	}))
+	job := runner.cron.Entry(entryID).Job
+
+	job.Run()
+
+	// The address changes.
+	if _, err := dynamicClient.Resource(gvr).Namespace("test-ns").Update(ctx, addressable("http://second.example.com"), metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Failed to update the addressable:", err)
+	}
+	job.Run() // cached address
+	fakeClock.Step(time.Minute)
+	job.Run() // new address
+
+	// The address can't be read anymore.
+	if err := dynamicClient.Resource(gvr).Namespace("test-ns").Delete(ctx, "test-sink", metav1.DeleteOptions{}); err != nil {
+		t.Fatal("Failed to delete the addressable:", err)
+	}
+	fakeClock.Step(time.Minute)
+	job.Run() // last known address
+
+	want := []string{
+		"http://first.example.com/path",
+		"http://first.example.com/path",
+		"http://second.example.com/path",
+		"http://second.example.com/path",
+	}
+	if len(ce.targets) != len(want) {
+		t.Fatalf("Expected %d events to be sent, got %d", len(want), len(ce.targets))
+	}
+	for i := range want {
+		if ce.targets[i] != want[i] {
+			t.Errorf("Expected event %d to be sent to %q, got %q", i, want[i], ce.targets[i])
+		}
+	}
+}
+
+func TestSinkResolutionFallback(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	ce := &targetRecorder{Client: adaptertesting.NewTestClient()}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithSinkResolution(dynamicClient, time.Minute))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("reconciled.example.com")
+		s.Spec.SourceSpec = duckv1.SourceSpec{
+			Sink: duckv1.Destination{
+				Ref: &duckv1.KReference{
+					APIVersion: "example.knative.dev/v1",
+					Kind:       "Sink",
+					Name:       "missing-sink",
+				},
+			},
+		}
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	if len(ce.targets) != 1 || ce.targets[0] != "http://reconciled.example.com" {
+		t.Error("Expected the event to be sent to the reconciled address, got", ce.targets)
+	}
+}
+
+func TestBrokerNameResolution(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	broker := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "eventing.knative.dev/v1",
+		"kind":       "Broker",
+		"metadata": map[string]interface{}{
+			"namespace": "test-ns",
+			"name":      "default",
+		},
+		"status": map[string]interface{}{
+			"address": map[string]interface{}{
+				"url": "http://broker-ingress.example.com/test-ns/default",
+			},
+		},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), broker)
+
+	ce := &targetRecorder{Client: adaptertesting.NewTestClient()}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithSinkResolution(dynamicClient, time.Minute))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("reconciled.example.com")
+		s.Spec.BrokerName = "default"
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	want := "http://broker-ingress.example.com/test-ns/default"
+	if len(ce.targets) != 1 || ce.targets[0] != want {
+		t.Errorf("Expected the event to be sent to %s, got %v", want, ce.targets)
+	}
+}
+
+func TestSinkPathPrefix(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), addressable(server.URL+"/test-ns/test-sink"))
+
+	ce, err := newClient(nethttp.DefaultTransport)
+	if err != nil {
+		t.Fatal("Failed to create client:", err)
+	}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithSinkResolution(dynamicClient, time.Minute))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("reconciled.example.com")
+		s.Spec.SourceSpec = duckv1.SourceSpec{
+			Sink: duckv1.Destination{
+				Ref: &duckv1.KReference{
+					APIVersion: "example.knative.dev/v1",
+					Kind:       "Sink",
+					Name:       "test-sink",
+				},
+			},
+		}
+		s.Spec.SinkPathPrefix = "/gateway"
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := "/gateway/test-ns/test-sink"; len(paths) != 1 || paths[0] != want {
+		t.Errorf("Expected a request to %s, got %v", want, paths)
+	}
+}
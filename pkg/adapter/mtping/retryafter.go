@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"math/rand"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// maxRetryAfter bounds the time spent honoring a Retry-After header so
+// retries stay within the schedule period.
+const maxRetryAfter = 30 * time.Second
+
+// retryAfterRoundTripper honors the Retry-After header of 429 and 503
+// responses by waiting, before returning the response, for the advertised
+// delay randomly spread by jitter.
+type retryAfterRoundTripper struct {
+	next nethttp.RoundTripper
+
+	// jitter is the fraction of the advertised delay by which the actual
+	// delay is randomly shortened or lengthened.
+	jitter float64
+	clock  clock.Clock
+}
+
+var _ nethttp.RoundTripper = (*retryAfterRoundTripper)(nil)
+
+func (t *retryAfterRoundTripper) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || (resp.StatusCode != nethttp.StatusTooManyRequests && resp.StatusCode != nethttp.StatusServiceUnavailable) {
+		return resp, err
+	}
+
+	delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), t.clock.Now())
+	if !ok {
+		return resp, err
+	}
+	delay = jitterDelay(delay, t.jitter, rand.Float64()) //nolint:gosec // Cryptographic randomness not necessary here.
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+
+	timer := t.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+	case <-timer.C():
+	}
+	return resp, err
+}
+
+// parseRetryAfter parses a Retry-After header value, either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := nethttp.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// jitterDelay spreads delay within [delay*(1-jitter), delay*(1+jitter)]
+// using r, a random number in [0, 1).
+func jitterDelay(delay time.Duration, jitter, r float64) time.Duration {
+	if jitter <= 0 {
+		return delay
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return time.Duration(float64(delay) * (1 + jitter*(2*r-1)))
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		"seconds":     {value: "3", want: 3 * time.Second, wantOk: true},
+		"http date":   {value: now.Add(5 * time.Second).Format(nethttp.TimeFormat), want: 5 * time.Second, wantOk: true},
+		"past date":   {value: now.Add(-5 * time.Second).Format(nethttp.TimeFormat), want: 0, wantOk: true},
+		"empty":       {value: ""},
+		"negative":    {value: "-1"},
+		"not a value": {value: "soon"},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got, ok := parseRetryAfter(tc.value, now)
+			if ok != tc.wantOk || got != tc.want {
+				t.Errorf("parseRetryAfter(%q) = (%v, %v), want (%v, %v)", tc.value, got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestJitterDelay(t *testing.T) {
+	delay := 10 * time.Second
+	if got := jitterDelay(delay, 0, 0.9); got != delay {
+		t.Errorf("Expected no jitter, got %v", got)
+	}
+	if got := jitterDelay(delay, 0.5, 0); got != 5*time.Second {
+		t.Errorf("Expected the lower bound, got %v", got)
+	}
+	if got := jitterDelay(delay, 0.5, 0.5); got != delay {
+		t.Errorf("Expected the advertised delay, got %v", got)
+	}
+	if got := jitterDelay(delay, 2, 0); got != 0 {
+		t.Errorf("Expected the jitter to be capped, got %v", got)
+	}
+}
+
+// retryAfterClock records the waits honoring the Retry-After headers,
+// ending them at once.
+type retryAfterClock struct {
+	clock.RealClock
+
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (c *retryAfterClock) NewTimer(d time.Duration) clock.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	return c.RealClock.NewTimer(0)
+}
+
+func TestRetryAfterJitterSpreadsRetries(t *testing.T) {
+	const sources = 5
+
+	var mu sync.Mutex
+	limited := sets.NewString()
+	sink := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		source := r.Header.Get("Ce-Source")
+		mu.Lock()
+		defer mu.Unlock()
+		if limited.Has(source) {
+			w.WriteHeader(nethttp.StatusAccepted)
+			return
+		}
+		limited.Insert(source)
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(nethttp.StatusTooManyRequests)
+	}))
+	defer sink.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	waits := &retryAfterClock{}
+	runner.clients.clock = waits
+
+	sinkURI, _ := apis.ParseURL(sink.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < sources; i++ {
+		entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Name = fmt.Sprint("test-name-", i)
+			s.Status.SinkURI = sinkURI
+			s.Annotations = map[string]string{RetryAfterJitterAnnotation: "0.5"}
+		}))
+		job := runner.cron.Entry(entryID).Job
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job.Run()
+		}()
+	}
+	wg.Wait()
+
+	if got := runner.Stats().Sources; len(got) != sources {
+		t.Fatalf("Expected %d sources, got %d", sources, len(got))
+	}
+	for name, stats := range runner.Stats().Sources {
+		if stats.Sent != 1 {
+			t.Errorf("Expected the event of %s to be sent once retried, got %+v", name, stats)
+		}
+	}
+	if len(waits.waits) != sources {
+		t.Fatalf("Expected %d waits honoring Retry-After, got %v", sources, waits.waits)
+	}
+	min, max := time.Hour, time.Duration(0)
+	for _, d := range waits.waits {
+		// Retry-After of 1s with a 0.5 jitter.
+		if d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Errorf("Expected the wait to honor Retry-After with jitter, got %v", d)
+		}
+		if d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+	}
+	if max-min < 10*time.Millisecond {
+		t.Errorf("Expected the waits to be spread across sources, got %v", waits.waits)
+	}
+}
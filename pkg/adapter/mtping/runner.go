@@ -18,18 +18,34 @@ package mtping
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/rand"
+	nethttp "net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
-	"github.com/google/uuid"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/robfig/cron/v3"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/kubernetes"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/tracing/propagation/tracecontextb3"
 
 	kncloudevents "knative.dev/eventing/pkg/adapter/v2"
 	"knative.dev/eventing/pkg/adapter/v2/util/crstatusevent"
@@ -39,10 +55,35 @@ import (
 type CronJobRunner interface {
 	Start(stopCh <-chan struct{})
 	Stop()
-	AddSchedule(source *sourcesv1beta1.PingSource) cron.EntryID
-	RemoveSchedule(id cron.EntryID)
+	StopWithTimeout(d time.Duration) error
+	PauseAll()
+	Drain(d time.Duration) error
+	DrainState() DrainState
+	AddSchedule(source *sourcesv1beta1.PingSource) (cron.EntryID, error)
+	UpdateSchedule(source *sourcesv1beta1.PingSource) (cron.EntryID, error)
+	RemoveSchedule(id cron.EntryID) error
+	RemoveScheduleByKey(namespace, name string) error
+	RemoveQueued(namespace, name string) error
+	GetSchedule(namespace, name string) (cron.Entry, bool)
+	ListSchedules() []cron.Entry
+	LastRun(namespace, name string) (triggered, succeeded time.Time, ok bool)
+	MoveSchedule(sourceKey string, targetShard CronJobRunner) (cron.EntryID, error)
 }
 
+var (
+	// ErrInvalidEntryID is returned when given an entry ID that can't have
+	// been returned by a successful AddSchedule.
+	ErrInvalidEntryID = errors.New("invalid cron entry ID")
+
+	// ErrEntryNotFound is returned when no schedule is registered under the
+	// given entry ID.
+	ErrEntryNotFound = errors.New("cron entry not found")
+
+	// ErrStopTimeout is returned when jobs are still running once the stop
+	// timeout is reached.
+	ErrStopTimeout = errors.New("stop timeout reached")
+)
+
 type cronJobsRunner struct {
 	// The cron job runner
 	cron cron.Cron
@@ -53,43 +94,447 @@ type cronJobsRunner struct {
 	// Where to send logs
 	Logger *zap.SugaredLogger
 
+	// clock tells the time for rate limiting the error logs, summaries,
+	// pacing and telling apart the schedules firing together.
+	clock clock.Clock
+
 	// kubeClient for sending k8s events
 	kubeClient kubernetes.Interface
+
+	// clients holds the clients of the sources customizing the transport.
+	clients *clientPool
+
+	// redis sends cloudevents to sinks using the redis scheme.
+	redis redisSender
+
+	// ws sends cloudevents to sinks using the ws and wss schemes.
+	ws wsSender
+
+	// pubsub sends cloudevents to sinks using the pubsub scheme.
+	pubsub pubsubSender
+
+	cronOpts []cron.Option
+
+	// fireSettings are set by the options. The fires read the settings
+	// current when they start instead, from settings.
+	fireSettings
+	// settings holds the *fireSettings replaced by Reconfigure.
+	settings atomic.Value
+
+	// region is the value of the region extension set on all events.
+	region string
+
+	// flush flushes the metrics on Stop.
+	flush func()
+
+	// queue persists the events that failed to be sent, and those of the
+	// fires still in flight when Stop times out. Optional.
+	queue *diskQueue
+	// pendingMu guards pending.
+	pendingMu sync.Mutex
+	// pending holds the fires sending their events, when queue is set.
+	pending map[*pendingFire]struct{}
+	// resendMu guards resending.
+	resendMu sync.Mutex
+	// resending holds the keys of the sources whose persisted events are
+	// being sent again.
+	resending map[string]bool
+
+	// resolver resolves the address of Addressable sinks at fire time.
+	// Optional.
+	resolver *addressResolver
+
+	// deliveryStatus reports the results of the deliveries of the sources.
+	// Optional.
+	deliveryStatus *kncloudevents.DeliveryStatusReporter
+
+	// schemas records the schema fingerprints of the emitted events.
+	// Optional.
+	schemas *schemaRecorder
+
+	// dedup makes sure the ticks fired by several replicas are emitted
+	// once. Optional.
+	dedup DedupStore
+	// collisionPolicy tells what to do with the events whose ID is claimed
+	// by a different event. Defaults to skip.
+	collisionPolicy DedupCollisionPolicy
+
+	// complete is called with the namespace and name of the sources
+	// removed after their first successful send. Optional.
+	complete func(namespace, name string)
+
+	// invalid is called with the namespace and name of the sources
+	// skipping an event not matching their EventSchema. Optional.
+	invalid func(namespace, name string, err error)
+
+	// partial is called with the namespace and name of the sources whose
+	// fire partially failed, and the sent and failed counts of the fire,
+	// and once all the events of a following fire are sent, with a zero
+	// failed count. Optional.
+	partial func(namespace, name string, sent, failed uint64)
+	// batchReports hands the reports to partial, when set.
+	batchReports *batchReports
+
+	// missingKeys is called with the namespace and name of the sources
+	// whose templates reference missing keys, when they start or stop
+	// doing so, with a nil error once they don't. Optional.
+	missingKeys func(namespace, name string, err error, skipped bool)
+
+	// maxPanics is the number of consecutive fires a job can panic on
+	// before its schedule is removed. Zero means unbounded.
+	maxPanics int
+	// panicking is called with the namespace and name of the sources
+	// removed for their job panicking, and the last value recovered.
+	// Optional.
+	panicking func(namespace, name string, recovered interface{})
+
+	// latencyWindow is the number of the last sends of each source whose
+	// latency is reported. Zero disables measuring the latency.
+	latencyWindow int
+	// latencyPercentile is the percentile of the send latency reported.
+	latencyPercentile int
+	// onLatency is called with the namespace and name of the sources and
+	// their send latency, at most once per latencyInterval. Optional.
+	onLatency       func(namespace, name string, latency *sourcesv1beta1.PingSourceSendLatency)
+	latencyInterval time.Duration
+
+	// otel records the counters and the send latency in the mtping views.
+	// Optional.
+	otel *otelMetrics
+
+	// reporter reports the per-source metrics.
+	reporter StatsReporter
+
+	// jitter randomizes the retry backoff. The CloudEvents SDK retries,
+	// without jitter, when empty, attemptSpans is false and the retry
+	// bounds are zero.
+	jitter JitterStrategy
+	// retryMaxAttempts bounds the attempts of a send, retryMaxTries+1 when
+	// zero.
+	retryMaxAttempts int
+	// retryMaxElapsed bounds the time spent retrying a send, besides the
+	// next fire of the source. Zero means unbounded.
+	retryMaxElapsed time.Duration
+	// fireJitter is the window the fires of the sources are spread over.
+	// Zero fires them on schedule.
+	fireJitter time.Duration
+	// rand returns random numbers in [0, 1), for jittering the retries and
+	// picking the type variants.
+	rand func() float64
+	// attemptSpans is true when the span of each fire has a child span per
+	// send attempt. The runner retries the sends itself then.
+	attemptSpans bool
+
+	// drainTimeout bounds the time Stop waits for in-flight jobs. Zero
+	// means unbounded.
+	drainTimeout time.Duration
+
+	// pauseMu guards paused and the additions to inflight.
+	pauseMu sync.RWMutex
+	// paused is true once PauseAll has been called.
+	paused bool
+	// pausedCh is closed once PauseAll has been called.
+	pausedCh chan struct{}
+	// inflight tracks the jobs currently running, inflightCount counting
+	// them.
+	inflight      sync.WaitGroup
+	inflightCount int64
+
+	// maxGoroutines is the maximum number of jobs running at the same time,
+	// goroutines holding a token per job running when it is set.
+	maxGoroutines int32
+	goroutines    chan struct{}
+	// shed is the number of fires dropped because maxGoroutines was reached.
+	shed uint64
+	// sendSlots holds a token per fire sending its events, when their
+	// number is bounded. sendQueueTimeout is how long a fire waits for a
+	// free slot, sendsDropped counting the fires giving up.
+	maxSends         int
+	sendSlots        chan struct{}
+	sendQueueTimeout time.Duration
+	sendsDropped     uint64
+	// rateLimited is the number of fires dropped by limiter.
+	rateLimited uint64
+	// dedupCollisions is the number of events whose ID was claimed by a
+	// different event.
+	dedupCollisions uint64
+
+	// statsMu guards deliveries, summaries and scheduled.
+	statsMu sync.Mutex
+	// deliveries holds the delivery counters of the scheduled sources.
+	deliveries map[cron.EntryID]*deliveryStats
+	// scheduled holds the jobs of the scheduled sources, keyed by
+	// namespace/name.
+	scheduled map[string]*scheduledJob
+	// summaries holds the entry of the summary schedule of the sources
+	// having one.
+	summaries map[cron.EntryID]cron.EntryID
+}
+
+// fireSettings are the runner settings read on each fire, which Reconfigure
+// replaces.
+type fireSettings struct {
+	// traceParent is true when a traceparent extension is set on all events.
+	traceParent bool
+
+	// transforms are applied in order to all the events.
+	transforms []Transform
+
+	// limiter bounds the rate of the fires across replicas. Optional.
+	limiter RateLimiter
+}
+
+// Transform modifies an event before it is sent. An error skips the fire.
+type Transform func(event *cloudevents.Event) error
+
+// RunnerStats reports counters about the runner activity.
+type RunnerStats struct {
+	// Shed is the number of fires dropped because the goroutine budget
+	// was exhausted.
+	Shed uint64
+	// RateLimited is the number of fires dropped because the rate limit
+	// shared by the replicas was reached.
+	RateLimited uint64
+	// DedupCollisions is the number of events whose ID was already claimed
+	// in the dedup store by a different event.
+	DedupCollisions uint64
+	// SendsDropped is the number of fires dropped for finding no free slot
+	// among the concurrent sends.
+	SendsDropped uint64
+
+	// Sources holds the delivery counters of the scheduled sources, keyed
+	// by namespace/name.
+	Sources map[string]SourceStats
+}
+
+// SourceStats reports the deliveries of a source.
+type SourceStats struct {
+	// Sent is the number of events successfully sent, each chunk of a
+	// split event counting as one.
+	Sent uint64
+	// Failed is the number of events that failed to be sent, retries
+	// included, or skipped for failing to be rendered or fit the sink.
+	Failed uint64
+	// LastFireSent and LastFireFailed are the numbers of events of the
+	// last fire sending events that were sent and failed, telling the
+	// fires whose batch partially failed.
+	LastFireSent   uint64
+	LastFireFailed uint64
+	// Invalid is the number of events skipped for not matching the
+	// EventSchema of the source.
+	Invalid uint64
+	// Skipped is the number of fires skipped by the FireCondition of the
+	// source.
+	Skipped uint64
+	// Stale is the number of fires skipped for being older than the
+	// MaxFireStaleness of the source when about to be sent.
+	Stale uint64
+	// MissingKey is the number of fires skipped for their templates
+	// referencing missing keys, with the Strict TemplateMissingKey.
+	MissingKey uint64
+
+	// ShadowSent is the number of events copied to the ShadowSink of the
+	// source.
+	ShadowSent uint64
+	// ShadowFailed is the number of events that failed to be copied to the
+	// ShadowSink of the source.
+	ShadowFailed uint64
+
+	// Schema is the fingerprint of the shape of the last event, when
+	// schema fingerprints are recorded.
+	Schema string
+	// SchemaChanges is the number of times Schema changed.
+	SchemaChanges uint64
+
+	// Latency summarizes the latency of the last sends, when measured.
+	Latency LatencyStats
+}
+
+// deliveryStats counts the deliveries of a source.
+type deliveryStats struct {
+	fires   uint64
+	sent    uint64
+	failed  uint64
+	invalid uint64
+	skipped uint64
+	stale   uint64
+	key     string
+
+	// missingKey counts the fires skipped for missing template keys.
+	missingKey uint64
+	// templateKeys is the last state of the template keys, reported on
+	// its changes.
+	templateKeys int32
+
+	namespace string
+	name      string
+
+	shadowSent   uint64
+	shadowFailed uint64
+
+	// failing is 1 once a fire of the source failed, until one is sent.
+	failing int32
+	// partial is 1 once some events of a fire of the source failed, until
+	// all the events of one are sent.
+	partial int32
+
+	// lastFire holds the sent and failed counts of the last fire sending
+	// events, as a *fireCounts.
+	lastFire atomic.Value
+
+	// lastTriggered and lastSucceeded are the Unix times, in nanoseconds,
+	// of the last fire of the source and of the last one sent. Zero when
+	// there is none.
+	lastTriggered int64
+	lastSucceeded int64
+
+	// latency holds the latency of the last sends. Optional.
+	latency *latencyWindow
 }
 
 const (
 	resourceGroup = "pingsources.sources.knative.dev"
+
+	// regionExtension is the CloudEvent extension carrying the region the
+	// adapter runs in.
+	regionExtension = "region"
+
+	// dataRefExtension is the CloudEvent extension carrying the URL of the
+	// payload of the sources having a DataRef.
+	dataRefExtension = "dataref"
+
+	// errorLogInterval is the minimum interval between two errors logged
+	// for the same source. The errors in between are counted and reported
+	// with the next logged one.
+	errorLogInterval = time.Minute
 )
 
-func NewCronJobsRunner(ceClient cloudevents.Client, kubeClient kubernetes.Interface, logger *zap.SugaredLogger, opts ...cron.Option) *cronJobsRunner {
-	return &cronJobsRunner{
-		cron:       *cron.New(opts...),
+func NewCronJobsRunner(ceClient cloudevents.Client, kubeClient kubernetes.Interface, logger *zap.SugaredLogger, opts ...Option) *cronJobsRunner {
+	runner := &cronJobsRunner{
 		Client:     ceClient,
 		Logger:     logger,
 		kubeClient: kubeClient,
+		clock:      clock.RealClock{},
+		rand:       rand.Float64, //nolint:gosec // Cryptographic randomness not necessary here.
+		flush:      func() { metrics.FlushExporter() },
+		reporter:   NewStatsReporter(),
+
+		latencyPercentile: defaultLatencyPercentile,
+	}
+	for _, opt := range opts {
+		opt(runner)
 	}
+	settings := runner.fireSettings
+	runner.settings.Store(&settings)
+	runner.cron = *cron.New(runner.cronOpts...)
+	runner.clients = newClientPool(ceClient, logger)
+	runner.pausedCh = make(chan struct{})
+	if runner.partial != nil {
+		runner.batchReports = newBatchReports()
+		go runner.batchReports.run(runner.pausedCh, runner.partial)
+	}
+	runner.deliveries = make(map[cron.EntryID]*deliveryStats)
+	runner.summaries = make(map[cron.EntryID]cron.EntryID)
+	runner.scheduled = make(map[string]*scheduledJob)
+	runner.pending = make(map[*pendingFire]struct{})
+	runner.resending = make(map[string]bool)
+	if runner.maxGoroutines > 0 {
+		runner.goroutines = make(chan struct{}, runner.maxGoroutines)
+	}
+	if runner.maxSends > 0 {
+		runner.sendSlots = make(chan struct{}, runner.maxSends)
+	}
+	return runner
 }
 
-func (a *cronJobsRunner) AddSchedule(source *sourcesv1beta1.PingSource) cron.EntryID {
-	event := cloudevents.NewEvent()
-	event.SetType(sourcesv1beta1.PingSourceEventType)
-	event.SetSource(sourcesv1beta1.PingSourceSource(source.Namespace, source.Name))
-	event.SetData(cloudevents.ApplicationJSON, makeMessage(source.Spec.JsonData))
-	if source.Spec.CloudEventOverrides != nil && source.Spec.CloudEventOverrides.Extensions != nil {
-		for key, override := range source.Spec.CloudEventOverrides.Extensions {
-			event.SetExtension(key, override)
+// AddSchedule adds the schedule of source. It returns a *ScheduleError, and
+// registers nothing, when source can't be scheduled, or is already, its
+// changes being applied by UpdateSchedule.
+func (a *cronJobsRunner) AddSchedule(source *sourcesv1beta1.PingSource) (cron.EntryID, error) {
+	if _, scheduled := a.GetSchedule(source.Namespace, source.Name); scheduled {
+		return 0, newScheduleError(source, ReasonAlreadyScheduled, errors.New("source already scheduled"))
+	}
+	return a.schedule(source, nil, nil)
+}
+
+// schedule adds the schedule of source, or replaces the job of current in
+// place when not nil. The schedule goes on with the counters of stats, new
+// ones when nil. It returns a *ScheduleError when source can't be scheduled.
+func (a *cronJobsRunner) schedule(source *sourcesv1beta1.PingSource, current *scheduledJob, stats *deliveryStats) (cron.EntryID, error) {
+	if err := validateSchedule(source); err != nil {
+		return 0, err
+	}
+	event, err := makeEvent(source)
+	if err != nil {
+		return 0, newScheduleError(source, ReasonInvalidData, err)
+	}
+	if a.region != "" {
+		if _, ok := event.Extensions()[regionExtension]; !ok {
+			event.SetExtension(regionExtension, a.region)
 		}
 	}
 
+	ctx, err := a.fireContext(source)
+	if err != nil {
+		return 0, err
+	}
+	// Credentials are read on each reconcile, picking up rotations.
+	if ctx, err = a.basicAuthContext(ctx, source); err != nil {
+		return 0, err
+	}
+	// Like the credentials, the data is read on each reconcile.
+	if ctx, err = a.secretDataContext(ctx, source, &event); err != nil {
+		return 0, err
+	}
+
+	// The counters of the sources updated in place or moved go on.
+	if stats == nil {
+		stats = &deliveryStats{key: source.Namespace + "/" + source.Name, namespace: source.Namespace, name: source.Name}
+	}
+	opts, err := a.newJobOptions(ctx, source, event.Source(), stats)
+	if err != nil {
+		return 0, err
+	}
+
+	job := current
+	if job == nil {
+		job = &scheduledJob{schedule: scheduleOf(source), stats: opts.stats}
+	}
+	a.scheduleJob(ctx, source, event, opts, job)
+	a.register(ctx, source, event, opts, job)
+	// The events persisted for the source are sent again like its own, and
+	// when it is scheduled, not to be sent for the deleted sources.
+	if current == nil && a.queue != nil {
+		go a.resendQueued(ctx, opts)
+	}
+	return job.id, nil
+}
+
+// fireContext returns the context the fires of source send their events
+// with. It returns a *ScheduleError when the delivery spec of source is
+// invalid.
+func (a *cronJobsRunner) fireContext(source *sourcesv1beta1.PingSource) (context.Context, error) {
 	ctx := context.Background()
 	ctx = cloudevents.ContextWithTarget(ctx, source.Status.SinkURI.String())
 
 	var kubeEventSink record.EventSink = &typedcorev1.EventSinkImpl{Interface: a.kubeClient.CoreV1().Events(source.Namespace)}
 	ctx = crstatusevent.ContextWithCRStatus(ctx, &kubeEventSink, "ping-source-mt-adapter", source, a.Logger.Infof)
 
+	retries, err := newDeliveryRetries(source.Spec.Delivery)
+	if err != nil {
+		return nil, newScheduleError(source, ReasonInvalidSpec, err)
+	}
+
 	// Simple retry configuration to be less than 1mn.
 	// We might want to retry more times for less-frequent schedule.
-	ctx = cloudevents.ContextWithRetriesExponentialBackoff(ctx, 50*time.Millisecond, 5)
+	if retries == nil && a.jitter == "" && !a.attemptSpans && a.retryMaxAttempts == 0 && a.retryMaxElapsed == 0 {
+		ctx = cloudevents.ContextWithRetriesExponentialBackoff(ctx, retryPeriod, retryMaxTries)
+	} else {
+		ctx = withJitteredRetries(ctx)
+	}
+	if retries != nil {
+		ctx = withDeliveryRetries(ctx, retries)
+	}
 
 	metricTag := &kncloudevents.MetricTag{
 		Namespace:     source.Namespace,
@@ -98,12 +543,303 @@ func (a *cronJobsRunner) AddSchedule(source *sourcesv1beta1.PingSource) cron.Ent
 	}
 
 	ctx = kncloudevents.ContextWithMetricTag(ctx, metricTag)
-	id, _ := a.cron.AddFunc(source.Spec.Schedule, a.cronTick(ctx, event))
-	return id
+	// Used when the adapter creates the EventTypes.
+	ctx = kncloudevents.ContextWithEventTypeOwner(ctx, source.Namespace, metav1.OwnerReference{
+		APIVersion: sourcesv1beta1.SchemeGroupVersion.String(),
+		Kind:       "PingSource",
+		Name:       source.Name,
+		UID:        source.UID,
+	})
+	return ctx, nil
 }
 
-func (a *cronJobsRunner) RemoveSchedule(id cron.EntryID) {
+// newJobOptions returns the settings of the cron job of source sending the
+// events of eventSource, and counting them with stats. It returns a
+// *ScheduleError when source has invalid settings.
+func (a *cronJobsRunner) newJobOptions(ctx context.Context, source *sourcesv1beta1.PingSource, eventSource string, stats *deliveryStats) (jobOptions, error) {
+	opts := jobOptions{
+		client:      a.Client,
+		logSampling: uint64(intAnnotation(source, LogSamplingAnnotation, 1)),
+		stats:       stats,
+		errorLog:    &logLimiter{interval: errorLogInterval},
+
+		maxEventSize:   intAnnotation(source, MaxEventSizeAnnotation, 0),
+		oversizePolicy: OversizePolicy(source.Annotations[OversizePolicyAnnotation]),
+
+		namespace:    source.Namespace,
+		name:         source.Name,
+		fireJitter:   a.fireJitter,
+		queueSends:   ConcurrencyPolicy(source.Annotations[ConcurrencyPolicyAnnotation]) != ConcurrencySkip,
+		scheduleSpec: strings.Join(append([]string{source.Spec.Schedule}, source.Spec.Schedules...), ", "),
+	}
+	if source.Spec.Interval != nil {
+		opts.scheduleSpec = "@every " + source.Spec.Interval.Duration.String()
+	}
+	if boolAnnotation(source, PreciseTimingAnnotation) {
+		opts.fireJitter = 0
+	}
+	if dest := source.SinkDestination(); a.resolver != nil && dest.Ref != nil {
+		opts.sink = &dest
+		opts.sinkPathPrefix = source.Spec.SinkPathPrefix
+	}
+	if source.Status.ShadowSinkURI != nil {
+		opts.shadowSink = source.Status.ShadowSinkURI.String()
+		opts.shadowLog = &logLimiter{interval: errorLogInterval}
+	}
+	if source.Status.AlertSinkURI != nil {
+		opts.alertSink = source.Status.AlertSinkURI.String()
+	}
+	if source.Status.DeadLetterSinkURI != nil {
+		opts.deadLetterSink = source.Status.DeadLetterSinkURI.String()
+	}
+	if opts.logSampling == 0 {
+		opts.logSampling = 1
+	}
+	a.latencyOptions(source, &opts)
+	if source.Spec.BatchSpread != nil {
+		opts.batchSpread = source.Spec.BatchSpread.Duration
+	}
+	if source.Spec.Ordered {
+		opts.ordered = &sync.Mutex{}
+	}
+
+	// Like the credentials, the schema is read on each reconcile.
+	if err := a.eventSchemaOptions(ctx, source, &opts); err != nil {
+		return opts, err
+	}
+	if err := a.fireOptions(source, &opts); err != nil {
+		return opts, err
+	}
+	if err := a.templateOptions(source, &opts); err != nil {
+		return opts, err
+	}
+
+	// The schedule was validated with the source.
+	opts.schedule, _ = sourceSchedule(source)
+	if opts.schedule != nil {
+		opts.ticks = newTickTracker(opts.schedule, a.clock.Now())
+	}
+
+	if err := a.clientOptions(ctx, source, eventSource, &opts); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// fireOptions sets the settings of opts deciding whether and when the fires
+// of source send their event.
+func (a *cronJobsRunner) fireOptions(source *sourcesv1beta1.PingSource, opts *jobOptions) error {
+	if source.Spec.MaxFireStaleness != nil {
+		opts.maxStaleness = source.Spec.MaxFireStaleness.Duration
+	}
+
+	if rate, ok := floatAnnotation(source, EmissionRateAnnotation); ok && rate > 0 {
+		opts.emission = newTokenBucket(rate, intAnnotation(source, EmissionBurstAnnotation, 1), a.clock)
+	}
+
+	if source.Spec.FireCondition != "" {
+		condition, err := compileFireCondition(source.Spec.FireCondition)
+		if err != nil {
+			return newScheduleError(source, ReasonInvalidSpec, fmt.Errorf("invalid fire condition: %w", err))
+		}
+		opts.condition = condition
+	}
+
+	if spec, ok := source.Annotations[TimeSourceAnnotation]; ok {
+		eventTime, err := parseTimeSource(spec)
+		if err != nil {
+			return newScheduleError(source, ReasonInvalidSpec, fmt.Errorf("invalid cloudevent time source: %w", err))
+		}
+		opts.eventTime = eventTime
+	}
+	return nil
+}
+
+// scheduleJob sets the tick of job firing for source, and adds job to the
+// cron when it has no entry yet.
+func (a *cronJobsRunner) scheduleJob(ctx context.Context, source *sourcesv1beta1.PingSource, event cloudevents.Event, opts jobOptions, job *scheduledJob) {
+	// entry is set once the schedule is added, before the first fire.
+	var entry int64
+	a.stopAfterFirstSuccess(source, &opts, &entry)
+
+	var (
+		tick     func()
+		schedule cron.Schedule
+	)
+	if _, ok := source.Annotations[SecondOffsetsAnnotation]; ok || len(source.Spec.Schedules) > 0 {
+		schedules, _ := sourceSchedules(source)
+		tick = a.schedulesTick(ctx, event, opts, schedules, OverlapPolicy(source.Annotations[OverlapPolicyAnnotation]))
+		schedule = schedules.single()
+	} else {
+		tick = a.cronTick(ctx, event, opts)
+		schedule = opts.schedule
+	}
+	job.tick.Store(a.budgetTick(ctx, event, opts, a.concurrencyTick(source, a.recoverTick(source, opts, &entry, tick))))
+	// The jobs updated in place keep their cron entry.
+	if job.id == 0 {
+		job.id = a.cron.Schedule(schedule, job)
+	}
+	atomic.StoreInt64(&entry, int64(job.id))
+}
+
+// stopAfterFirstSuccess sets opts to remove the schedule registered under
+// entry after the first successful fire, when source stops then.
+func (a *cronJobsRunner) stopAfterFirstSuccess(source *sourcesv1beta1.PingSource, opts *jobOptions, entry *int64) {
+	if !source.Spec.StopAfterFirstSuccess {
+		return
+	}
+	var once sync.Once
+	namespace, name := source.Namespace, source.Name
+	opts.onSuccess = func() {
+		once.Do(func() {
+			a.remove(cron.EntryID(atomic.LoadInt64(entry)))
+			if a.complete != nil {
+				a.complete(namespace, name)
+			}
+		})
+	}
+}
+
+// register records job as the schedule of source, along with its counters
+// and summary schedule.
+func (a *cronJobsRunner) register(ctx context.Context, source *sourcesv1beta1.PingSource, event cloudevents.Event, opts jobOptions, job *scheduledJob) {
+	summaryID := a.scheduleSummary(ctx, source, event, opts)
+
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+	if previous, ok := a.summaries[job.id]; ok {
+		a.cron.Remove(previous)
+		delete(a.summaries, job.id)
+	}
+	a.deliveries[job.id] = opts.stats
+	if summaryID > 0 {
+		a.summaries[job.id] = summaryID
+	}
+	a.scheduled[opts.stats.key] = job
+	job.source = source
+}
+
+// RemoveSchedule removes the schedule registered under id. It returns
+// ErrInvalidEntryID when id is not a valid entry ID and ErrEntryNotFound
+// when no schedule is registered under id.
+func (a *cronJobsRunner) RemoveSchedule(id cron.EntryID) error {
+	if _, err := a.Entry(id); err != nil {
+		return err
+	}
+	a.remove(id)
+	return nil
+}
+
+// remove removes the schedule registered under id, its summary schedule
+// and its counters.
+func (a *cronJobsRunner) remove(id cron.EntryID) {
 	a.cron.Remove(id)
+
+	a.statsMu.Lock()
+	summaryID, ok := a.summaries[id]
+	if stats, found := a.deliveries[id]; found {
+		if job, scheduled := a.scheduled[stats.key]; scheduled && job.id == id {
+			delete(a.scheduled, stats.key)
+			a.clients.Release(stats.key)
+			if a.deliveryStatus != nil {
+				a.deliveryStatus.Forget(types.NamespacedName{Namespace: stats.namespace, Name: stats.name})
+			}
+		}
+		if a.otel != nil {
+			a.otel.forget(stats)
+		}
+	}
+	delete(a.deliveries, id)
+	delete(a.summaries, id)
+	a.statsMu.Unlock()
+
+	if ok {
+		a.cron.Remove(summaryID)
+	}
+}
+
+// Entry returns the cron entry registered under id. It returns
+// ErrInvalidEntryID when id is not a valid entry ID and ErrEntryNotFound
+// when no schedule is registered under id.
+func (a *cronJobsRunner) Entry(id cron.EntryID) (cron.Entry, error) {
+	if id <= 0 {
+		return cron.Entry{}, fmt.Errorf("%w: %d", ErrInvalidEntryID, id)
+	}
+	entry := a.cron.Entry(id)
+	if !entry.Valid() {
+		return cron.Entry{}, fmt.Errorf("%w: %d", ErrEntryNotFound, id)
+	}
+	return entry, nil
+}
+
+// RemoveScheduleByKey removes the schedule of the source with the given
+// namespace and name. It returns ErrEntryNotFound when the source isn't
+// scheduled.
+func (a *cronJobsRunner) RemoveScheduleByKey(namespace, name string) error {
+	a.statsMu.Lock()
+	job, ok := a.scheduled[namespace+"/"+name]
+	a.statsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s/%s", ErrEntryNotFound, namespace, name)
+	}
+	a.remove(job.id)
+	return nil
+}
+
+// GetSchedule returns the cron entry of the source with the given namespace
+// and name, and whether it is scheduled.
+func (a *cronJobsRunner) GetSchedule(namespace, name string) (cron.Entry, bool) {
+	a.statsMu.Lock()
+	job, ok := a.scheduled[namespace+"/"+name]
+	a.statsMu.Unlock()
+	if !ok {
+		return cron.Entry{}, false
+	}
+	entry := a.cron.Entry(job.id)
+	return entry, entry.Valid()
+}
+
+// LastRun returns the time of the last fire of the source with the given
+// namespace and name, and of the last one sent, the zero time telling
+// there is none yet. ok is false when the source isn't scheduled, the times
+// being forgotten once its schedule is removed.
+func (a *cronJobsRunner) LastRun(namespace, name string) (triggered, succeeded time.Time, ok bool) {
+	a.statsMu.Lock()
+	job, ok := a.scheduled[namespace+"/"+name]
+	a.statsMu.Unlock()
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	return unixTime(atomic.LoadInt64(&job.stats.lastTriggered)), unixTime(atomic.LoadInt64(&job.stats.lastSucceeded)), true
+}
+
+// unixTime returns the time of the given Unix nanoseconds, zero being the
+// zero time.
+func unixTime(nsec int64) time.Time {
+	if nsec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nsec)
+}
+
+// ListSchedules returns the cron entries of the scheduled sources, by
+// ascending entry ID.
+func (a *cronJobsRunner) ListSchedules() []cron.Entry {
+	a.statsMu.Lock()
+	ids := make([]cron.EntryID, 0, len(a.scheduled))
+	for _, job := range a.scheduled {
+		ids = append(ids, job.id)
+	}
+	a.statsMu.Unlock()
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	entries := make([]cron.Entry, 0, len(ids))
+	for _, id := range ids {
+		if entry := a.cron.Entry(id); entry.Valid() {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
 }
 
 func (a *cronJobsRunner) Start(stopCh <-chan struct{}) {
@@ -111,33 +847,568 @@ func (a *cronJobsRunner) Start(stopCh <-chan struct{}) {
 	<-stopCh
 }
 
+// PauseAll stops scheduling fires and makes the fires already scheduled
+// return immediately. Jobs in flight are not interrupted, but send their
+// remaining events without pacing them, nor waiting to retry them.
+func (a *cronJobsRunner) PauseAll() {
+	a.pauseMu.Lock()
+	defer a.pauseMu.Unlock()
+	if a.paused {
+		return
+	}
+	a.paused = true
+	close(a.pausedCh)
+	a.cron.Stop() // no more ticks
+}
+
+// Stop stops the runner like StopWithTimeout, waiting for the jobs in
+// flight up to the drain timeout, or until they are done when there is none.
 func (a *cronJobsRunner) Stop() {
-	ctx := a.cron.Stop() // no more ticks
-	if ctx != nil {
-		// Wait for all jobs to be done.
-		<-ctx.Done()
+	if err := a.StopWithTimeout(a.drainTimeout); err != nil {
+		if a.queue != nil {
+			a.Logger.Warnw("drain timeout reached, in-flight cloudevents were persisted", zap.Error(err))
+		} else {
+			a.Logger.Warnw("drain timeout reached, in-flight cloudevents may be lost", zap.Error(err))
+		}
 	}
 }
 
-func (a *cronJobsRunner) cronTick(ctx context.Context, event cloudevents.Event) func() {
+// StopWithTimeout stops the runner, waiting for the jobs in flight up to d,
+// or until they are done when d is not positive. It returns ErrStopTimeout,
+// telling the number of jobs still running, when d is reached first. The
+// events those jobs didn't send yet are persisted when the runner has a
+// persistent queue.
+func (a *cronJobsRunner) StopWithTimeout(d time.Duration) error {
+	err := a.Drain(d)
+	if err != nil && a.queue != nil {
+		a.persistPending()
+	}
+
+	a.ws.Close()
+	a.pubsub.Close()
+
+	// Only flush once all jobs are done so the last fires are recorded.
+	if a.flush != nil {
+		a.flush()
+	}
+	return err
+}
+
+// Reconfigure replaces the settings of the runner set by WithTraceParent,
+// WithTransforms and WithRateLimiter with the ones set by opts, the other
+// options being ignored. The fires in flight keep the settings they started
+// with, the fires starting once Reconfigure returns use the new ones.
+func (a *cronJobsRunner) Reconfigure(opts ...Option) {
+	staged := &cronJobsRunner{Logger: a.Logger}
+	for _, opt := range opts {
+		opt(staged)
+	}
+	settings := staged.fireSettings
+	a.settings.Store(&settings)
+}
+
+// begin registers a job in flight. It returns false when the runner is paused.
+func (a *cronJobsRunner) begin() bool {
+	a.pauseMu.RLock()
+	defer a.pauseMu.RUnlock()
+	if a.paused {
+		return false
+	}
+	a.inflight.Add(1)
+	atomic.AddInt64(&a.inflightCount, 1)
+	return true
+}
+
+// end marks a job begun with begin as done.
+func (a *cronJobsRunner) end() {
+	atomic.AddInt64(&a.inflightCount, -1)
+	a.inflight.Done()
+}
+
+// Stats returns a snapshot of the runner counters.
+func (a *cronJobsRunner) Stats() RunnerStats {
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+
+	sources := make(map[string]SourceStats, len(a.deliveries))
+	for _, d := range a.deliveries {
+		stats := SourceStats{
+			Sent:    atomic.LoadUint64(&d.sent),
+			Failed:  atomic.LoadUint64(&d.failed),
+			Invalid: atomic.LoadUint64(&d.invalid),
+			Skipped: atomic.LoadUint64(&d.skipped),
+			Stale:   atomic.LoadUint64(&d.stale),
+
+			MissingKey: atomic.LoadUint64(&d.missingKey),
+
+			ShadowSent:   atomic.LoadUint64(&d.shadowSent),
+			ShadowFailed: atomic.LoadUint64(&d.shadowFailed),
+		}
+		if a.schemas != nil {
+			stats.Schema, stats.SchemaChanges = a.schemas.Get(d.key)
+		}
+		if d.latency != nil {
+			stats.Latency = d.latency.Summary(a.latencyPercentile)
+		}
+		if last, ok := d.lastFire.Load().(*fireCounts); ok {
+			stats.LastFireSent, stats.LastFireFailed = last.sent, last.failed
+		}
+		sources[d.key] = stats
+	}
+	return RunnerStats{
+		Shed:            atomic.LoadUint64(&a.shed),
+		RateLimited:     atomic.LoadUint64(&a.rateLimited),
+		DedupCollisions: atomic.LoadUint64(&a.dedupCollisions),
+		SendsDropped:    atomic.LoadUint64(&a.sendsDropped),
+		Sources:         sources,
+	}
+}
+
+// jobOptions holds the settings of the cron job of a source.
+type jobOptions struct {
+	// client sends the events of the source over HTTP.
+	client cloudevents.Client
+
+	// logSampling is N when only one fire out of N is logged. Failures
+	// are not sampled, but rate limited by errorLog.
+	logSampling uint64
+
+	// onSuccess is called after each successful send. Optional.
+	onSuccess func()
+
+	// eventSchema validates the event data before sending. Optional.
+	eventSchema *jsonSchema
+	// onInvalid is called with the validation error of the skipped events.
+	// Optional.
+	onInvalid func(err error)
+
+	// onLatency is called with the send latency after each successful fire,
+	// when measured. Optional.
+	onLatency func(latency LatencyStats)
+
+	// stats counts the deliveries of the source.
+	stats *deliveryStats
+
+	// condition tells whether to emit on each fire. Optional.
+	condition *fireCondition
+
+	// schedule tells when the next fire is, the retries of a fire giving
+	// up before it. Optional.
+	schedule cron.Schedule
+
+	// ticks measures the skew of the fires from the schedule. Optional.
+	ticks *tickTracker
+
+	// maxStaleness is the maximum age of a fire when its event is about to
+	// be sent. Zero means unbounded.
+	maxStaleness time.Duration
+
+	// emission caps the rate of the sends. Optional.
+	emission *tokenBucket
+
+	// eventTime sets the time of the events on each fire. The time of the
+	// send is used when nil.
+	eventTime eventClock
+
+	// templates renders the event type and source on each fire. Optional.
+	templates *eventTemplates
+	// overrides renders the templated extensions on each fire. Optional.
+	overrides *kncloudevents.Overrides
+	// types picks the event type on each fire. Optional.
+	types *weightedTypes
+
+	// errorLog limits the rate of the errors logged for the source.
+	errorLog *logLimiter
+
+	// maxEventSize is the maximum size of the data of the events accepted
+	// by the sink. Zero means unlimited.
+	maxEventSize int
+	// oversizePolicy tells what to do with the events exceeding maxEventSize.
+	oversizePolicy OversizePolicy
+
+	// batchSpread is the window the events sent on a fire are spread over.
+	// Zero sends them at once.
+	batchSpread time.Duration
+
+	// ordered is held while sending the events of a fire, when the sends
+	// of the source are serialized. Optional.
+	ordered *sync.Mutex
+
+	// sink is resolved on each fire when its reference is set. Optional.
+	sink *duckv1.Destination
+	// sinkPathPrefix is prepended to the path of the resolved sink.
+	sinkPathPrefix string
+	// shadowSink gets a copy of the events. Optional.
+	shadowSink string
+	// shadowLog limits the rate of the shadow sink errors logged, apart
+	// from the errors of the source.
+	shadowLog *logLimiter
+	// alertSink gets an alert when the source starts failing. Optional.
+	alertSink string
+
+	// deadLetterSink gets the events failing to be sent. Optional.
+	deadLetterSink string
+	// namespace is the namespace of the source.
+	namespace string
+	// name is the name of the source.
+	name string
+	// scheduleSpec is the schedule of the source, as set in its spec.
+	scheduleSpec string
+	// fireJitter is the window the offset of the fires of the source is
+	// picked in. Zero doesn't delay them.
+	fireJitter time.Duration
+	// queueSends is true when the fires of the source wait for a free send
+	// slot, rather than being dropped at once.
+	queueSends bool
+}
+
+func (a *cronJobsRunner) cronTick(ctx context.Context, event cloudevents.Event, opts jobOptions) func() {
+	var fires uint64
 	return func() {
-		event := event.Clone()
-		event.SetID(uuid.New().String()) // provide an ID here so we can track it with logging
-		defer a.Logger.Debug("Finished sending cloudevent id: ", event.ID())
-		target := cecontext.TargetFrom(ctx).String()
-		source := event.Context.GetSource()
+		if !a.begin() {
+			return
+		}
+		defer a.end()
+		if a.otel != nil {
+			defer a.otel.export(ctx, a, opts.stats)
+		}
+		fired := a.clock.Now()
+		atomic.StoreInt64(&opts.stats.lastTriggered, fired.UnixNano())
+		tick := a.scheduledTick(opts, fired)
+
+		ctx, span := a.traceFire(ctx, opts, event.Source())
+		defer span.End()
+		if opts.schedule != nil {
+			ctx = withRetryDeadline(ctx, opts.schedule.Next(fired))
+		}
+
+		// The fire keeps these settings even if reconfigured meanwhile.
+		settings := a.settings.Load().(*fireSettings)
+		if !a.allowFire(ctx, settings, event.Source()) {
+			return
+		}
+		fireCount := atomic.AddUint64(&opts.stats.fires, 1)
+
+		now := a.clock.Now()
+		if !a.shouldFire(opts, fireCount, now, event.Source()) {
+			return
+		}
+		event, ok := a.fireEvent(ctx, settings, opts, event, tick, now)
+		if !ok || !a.validEvent(opts, event) {
+			return
+		}
+		a.recordSchema(opts, event)
+
+		sampled := (atomic.AddUint64(&fires, 1)-1)%opts.logSampling == 0
+		if sampled {
+			defer a.Logger.Debug("Finished sending cloudevent id: ", event.ID())
+		}
 
 		// Provide a delay so not all ping fired instantaneously distribute load on resources.
 		time.Sleep(time.Duration(rand.Intn(500)) * time.Millisecond) //nolint:gosec // Cryptographic randomness not necessary here.
 
-		a.Logger.Debugf("sending cloudevent id: %s, source: %s, target: %s", event.ID(), source, target)
+		// Checked before claiming the event, for a less delayed replica to
+		// send it instead.
+		if a.stale(opts, event, fired) || !a.delayFire(opts, fired) {
+			return
+		}
+		if a.dedup != nil && !a.claim(ctx, event) {
+			return
+		}
+
+		events, ok := a.fitEvent(opts, event)
+		if !ok {
+			return
+		}
+		ctx = a.resolveSink(ctx, opts, event.Source())
+
+		// The counts are recorded once the send slot and the order are
+		// released.
+		counts := &fireCounts{}
+		defer a.recordFire(opts, counts)
+		if !a.acquireSend(opts.queueSends) {
+			atomic.AddUint64(&a.sendsDropped, 1)
+			a.Logger.Debugw("no free send slot, dropping fire", zap.String("source", event.Source()))
+			return
+		}
+		defer a.releaseSend()
+
+		// The copies are sent in parallel, their failures not failing the
+		// fire.
+		if opts.shadowSink != "" {
+			defer a.mirror(ctx, opts, events)()
+		}
+
+		// The events not sent yet are persisted if Stop times out.
+		pending := a.track(ctx, events)
+		defer a.untrack(pending)
+
+		if opts.ordered != nil {
+			opts.ordered.Lock()
+			defer opts.ordered.Unlock()
+		}
+		if !a.sendEvents(ctx, opts, events, pending, sampled, counts) {
+			// Persisted on Stop, to be sent after the restart.
+			return
+		}
+		a.finishFire(ctx, span, opts, event, counts)
+	}
+}
+
+// fireEvent returns a copy of event to send on the fire of tick happening
+// at now, with the attributes set at fire time. It returns false when the
+// fire is skipped.
+func (a *cronJobsRunner) fireEvent(ctx context.Context, settings *fireSettings, opts jobOptions, event cloudevents.Event, tick, now time.Time) (cloudevents.Event, bool) {
+	event = event.Clone()
+	a.setFireID(&event, tick)
+	if opts.eventTime != nil {
+		event.SetTime(opts.eventTime(now))
+	}
+	if !a.renderTemplates(opts, &event, tick, now) {
+		return event, false
+	}
+	// The traced fires carry their trace to the receivers.
+	if settings.traceParent || traced(ctx) {
+		traceContext(ctx).AddTracingAttributes(&event)
+	}
+	for _, transform := range settings.transforms {
+		if err := transform(&event); err != nil {
+			atomic.AddUint64(&opts.stats.failed, 1)
+			if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+				a.Logger.Errorw("failed to transform cloudevent, skipping fire", zap.String("source", event.Source()),
+					zap.Error(err), zap.Int("suppressed", suppressed))
+			}
+			return event, false
+		}
+	}
+	return event, true
+}
+
+// stale tells whether the fire at fired is too old for its event to be sent.
+func (a *cronJobsRunner) stale(opts jobOptions, event cloudevents.Event, fired time.Time) bool {
+	age := a.clock.Since(fired)
+	if opts.maxStaleness <= 0 || age <= opts.maxStaleness {
+		return false
+	}
+	atomic.AddUint64(&opts.stats.stale, 1)
+	if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+		a.Logger.Warnw("fire exceeds the maximum staleness, skipping it", zap.String("source", event.Source()),
+			zap.String("id", event.ID()), zap.Duration("age", age), zap.Int("suppressed", suppressed))
+	}
+	return true
+}
 
-		if result := a.Client.Send(ctx, event); !cloudevents.IsACK(result) {
-			// Exhausted number of retries. Event is lost.
-			a.Logger.Error("failed to send cloudevent result: ", zap.Any("result", result),
-				zap.String("source", source), zap.String("target", target), zap.String("id", event.ID()))
+// sendEvents sends the events of a fire, counting them with counts. All the
+// events are sent even when one fails, the failed ones being persisted for
+// a later attempt. It returns false when the runner stopped before all of
+// them were sent.
+func (a *cronJobsRunner) sendEvents(ctx context.Context, opts jobOptions, events []cloudevents.Event, pending *pendingFire, sampled bool, counts *fireCounts) bool {
+	interval := opts.batchSpread / time.Duration(len(events))
+	for i, event := range events {
+		if i > 0 && interval > 0 {
+			a.pace(interval)
+		}
+		if opts.emission != nil {
+			if wait := opts.emission.reserve(); wait > 0 {
+				a.pace(wait)
+			}
 		}
+		if !pending.claim() {
+			return false
+		}
+		if a.deliver(ctx, opts, event, sampled) {
+			counts.sent++
+		} else {
+			counts.failed++
+		}
+	}
+	return true
+}
+
+// finishFire records the outcome of the fire of event, once its events
+// counted with counts are sent.
+func (a *cronJobsRunner) finishFire(ctx context.Context, span *trace.Span, opts jobOptions, event cloudevents.Event, counts *fireCounts) {
+	if counts.failed > 0 {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: "failed to send the cloudevent"})
+		a.recordFailure(ctx, opts, event)
+		return
+	}
+	atomic.StoreInt64(&opts.stats.lastSucceeded, a.clock.Now().UnixNano())
+	a.recordSuccess(opts)
+	if opts.onLatency != nil {
+		opts.onLatency(opts.stats.latency.Summary(a.latencyPercentile))
+	}
+
+	if opts.onSuccess != nil {
+		opts.onSuccess()
+	}
+}
+
+// pace waits for d, or until the runner is paused so that the remaining
+// events are sent before it stops.
+func (a *cronJobsRunner) pace(d time.Duration) {
+	select {
+	case <-a.clock.After(d):
+	case <-a.pausedCh:
+	}
+}
+
+// deliver sends the event to the target found in ctx, persisting it on
+// failure. It returns whether the event was sent.
+func (a *cronJobsRunner) deliver(ctx context.Context, opts jobOptions, event cloudevents.Event, sampled bool) bool {
+	target := cecontext.TargetFrom(ctx).String()
+	if sampled {
+		a.Logger.Debugf("sending cloudevent id: %s, source: %s, target: %s", event.ID(), event.Source(), target)
+	}
+
+	start := a.clock.Now()
+	result := a.send(ctx, opts.client, event)
+	elapsed := a.clock.Since(start)
+	a.reporter.ReportSendLatency(opts.stats.namespace, opts.stats.name, elapsed)
+	a.reportRetries(opts, result)
+	code := responseCode(result)
+	if !a.countsEvents(ctx, opts.client) {
+		a.reporter.ReportEventCount(opts.stats.namespace, opts.stats.name, event, code)
+	}
+	a.reporter.ReportDispatchLatency(opts.stats.namespace, opts.stats.name, code, elapsed)
+	if a.deliveryStatus != nil {
+		var err error
+		if !cloudevents.IsACK(result) {
+			err = result
+		}
+		a.deliveryStatus.ReportContext(ctx, err)
+	}
+	if !cloudevents.IsACK(result) {
+		a.reporter.ReportEventFailed(opts.stats.namespace, opts.stats.name)
+		// Exhausted number of retries. Event is lost.
+		if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+			a.Logger.Errorw("failed to send cloudevent", zap.Any("result", result),
+				zap.String("source", event.Source()), zap.String("target", target), zap.String("id", event.ID()),
+				zap.Int("suppressed", suppressed))
+		}
+		// The dead-lettered events aren't sent again.
+		if !a.deadLetter(opts, target, event, result) {
+			a.persist(ctx, target, event)
+		}
+		return false
+	}
+	a.reporter.ReportEventSent(opts.stats.namespace, opts.stats.name)
+	if opts.stats.latency != nil {
+		opts.stats.latency.Record(elapsed)
+	}
+	if a.otel != nil {
+		a.otel.recordLatency(ctx, opts.stats, elapsed)
+	}
+	return true
+}
+
+// send delivers the event to the target found in ctx using the protocol
+// selected by the target scheme. HTTP is used by default, through client.
+func (a *cronJobsRunner) send(ctx context.Context, client cloudevents.Client, event cloudevents.Event) protocol.Result {
+	if target := cecontext.TargetFrom(ctx); target != nil {
+		switch target.Scheme {
+		case redisScheme:
+			id, err := a.redis.Send(ctx, target, event)
+			if err != nil {
+				return err
+			}
+			a.Logger.Debugf("appended cloudevent id: %s to redis stream entry: %s", event.ID(), id)
+			return protocol.ResultACK
+		case wsScheme, wssScheme:
+			if err := a.ws.Send(ctx, target, event); err != nil {
+				return err
+			}
+			return protocol.ResultACK
+		case pubsubScheme:
+			if err := a.pubsub.Send(ctx, target, event); err != nil {
+				return err
+			}
+			return protocol.ResultACK
+		}
+	}
+	if hasJitteredRetries(ctx) {
+		return a.sendWithRetries(ctx, client, event)
+	}
+	return client.Send(ctx, event)
+}
+
+// countsEvents tells whether client counts the events sent to the target
+// of ctx, the default client of the adapter counting the HTTP ones.
+func (a *cronJobsRunner) countsEvents(ctx context.Context, client cloudevents.Client) bool {
+	if client != nil && client != a.Client {
+		return false
+	}
+	if target := cecontext.TargetFrom(ctx); target != nil {
+		switch target.Scheme {
+		case redisScheme, wsScheme, wssScheme, pubsubScheme:
+			return false
+		}
+	}
+	return true
+}
+
+// newClient returns a CloudEvents HTTP client sending requests through rt.
+func newClient(rt nethttp.RoundTripper) (cloudevents.Client, error) {
+	p, err := cloudevents.NewHTTP(cloudevents.WithRoundTripper(&ochttp.Transport{
+		Base:        rt,
+		Propagation: tracecontextb3.TraceContextEgress,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return cloudevents.NewClient(p, cloudevents.WithTimeNow(), cloudevents.WithUUIDs())
+}
+
+// makeEvent returns the event sent on each fire of the source, without
+// the attributes set at fire time.
+func makeEvent(source *sourcesv1beta1.PingSource) (cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	event.SetType(sourcesv1beta1.PingSourceEventType)
+	event.SetSource(sourcesv1beta1.PingSourceSource(source.Namespace, source.Name))
+	if source.Spec.DataRef != "" {
+		event.SetExtension(dataRefExtension, source.Spec.DataRef)
+	} else {
+		contentType, data, err := makeData(source)
+		if err != nil {
+			return event, err
+		}
+		if err := event.SetData(contentType, data); err != nil {
+			return event, fmt.Errorf("failed to set the cloudevent data: %w", err)
+		}
+	}
+	for key, value := range source.Annotations {
+		if name := strings.TrimPrefix(key, ExtensionAnnotationPrefix); name != key && name != "" {
+			event.SetExtension(name, value)
+		}
+	}
+	// The explicit overrides win over the extensions set by annotations.
+	if source.Spec.CloudEventOverrides != nil && source.Spec.CloudEventOverrides.Extensions != nil {
+		for key, override := range source.Spec.CloudEventOverrides.Extensions {
+			event.SetExtension(key, override)
+		}
+	}
+	return event, nil
+}
+
+// makeData returns the content type and the data of the events of source.
+// JsonData is wrapped in a JSON object unless it is one, or a content type is
+// set.
+func makeData(source *sourcesv1beta1.PingSource) (string, interface{}, error) {
+	contentType := source.Spec.ContentType
+	if source.Spec.DataBase64 != "" {
+		data, err := base64.StdEncoding.DecodeString(source.Spec.DataBase64)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid dataBase64: %w", err)
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		return contentType, data, nil
+	}
+	if contentType != "" {
+		return contentType, []byte(source.Spec.JsonData), nil
 	}
+	return cloudevents.ApplicationJSON, makeMessage(source.Spec.JsonData), nil
 }
 
 type message struct {
@@ -17,12 +17,22 @@ limitations under the License.
 package mtping
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/robfig/cron/v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -37,51 +47,69 @@ import (
 
 const threeSecondsTillNextMinCronJob = 60 - 3
 
+// mustAddSchedule adds the schedule of source with runner, failing the test
+// when it can't be scheduled.
+func mustAddSchedule(t *testing.T, runner *cronJobsRunner, source *sourcesv1beta1.PingSource) cron.EntryID {
+	t.Helper()
+	id, err := runner.AddSchedule(source)
+	if err != nil {
+		t.Fatal("Failed to add the schedule:", err)
+	}
+	return id
+}
+
+// newTestSource returns the PingSource test-ns/test-name sending "some data"
+// to a-sink every minute, changed by opts.
+func newTestSource(opts ...func(*sourcesv1beta1.PingSource)) *sourcesv1beta1.PingSource {
+	source := &sourcesv1beta1.PingSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-name",
+			Namespace: "test-ns",
+		},
+		Spec: sourcesv1beta1.PingSourceSpec{
+			Schedule: "* * * * ?",
+			JsonData: "some data",
+		},
+		Status: sourcesv1beta1.PingSourceStatus{
+			SourceStatus: duckv1.SourceStatus{
+				SinkURI: apis.HTTP("a-sink"),
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(source)
+	}
+	return source
+}
+
+func mustMakeEvent(t *testing.T, source *sourcesv1beta1.PingSource) cloudevents.Event {
+	t.Helper()
+	event, err := makeEvent(source)
+	if err != nil {
+		t.Fatal("Failed to make the event:", err)
+	}
+	return event
+}
+
 func TestAddRunRemoveSchedules(t *testing.T) {
 	testCases := map[string]struct {
 		src   *sourcesv1beta1.PingSource
 		delay time.Duration
 	}{
 		"TestAddRunRemoveSchedule": {
-			src: &sourcesv1beta1.PingSource{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-name",
-					Namespace: "test-ns",
-				},
-				Spec: sourcesv1beta1.PingSourceSpec{
-					SourceSpec: duckv1.SourceSpec{
-						CloudEventOverrides: &duckv1.CloudEventOverrides{},
-					},
-					Schedule: "* * * * ?",
-					JsonData: "some data",
-				},
-				Status: sourcesv1beta1.PingSourceStatus{
-					SourceStatus: duckv1.SourceStatus{
-						SinkURI: &apis.URL{Path: "a sink"},
-					},
-				},
-			},
+			src: newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.SourceSpec = duckv1.SourceSpec{
+					CloudEventOverrides: &duckv1.CloudEventOverrides{},
+				}
+			}),
 		}, "TestAddRunRemoveScheduleWithExtensionOverride": {
-			src: &sourcesv1beta1.PingSource{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-name",
-					Namespace: "test-ns",
-				},
-				Spec: sourcesv1beta1.PingSourceSpec{
-					SourceSpec: duckv1.SourceSpec{
-						CloudEventOverrides: &duckv1.CloudEventOverrides{
-							Extensions: map[string]string{"1": "one", "2": "two"},
-						},
+			src: newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.SourceSpec = duckv1.SourceSpec{
+					CloudEventOverrides: &duckv1.CloudEventOverrides{
+						Extensions: map[string]string{"1": "one", "2": "two"},
 					},
-					Schedule: "* * * * ?",
-					JsonData: "some data",
-				},
-				Status: sourcesv1beta1.PingSourceStatus{
-					SourceStatus: duckv1.SourceStatus{
-						SinkURI: &apis.URL{Path: "a sink"},
-					},
-				},
-			},
+				}
+			}),
 		},
 	}
 	for n, tc := range testCases {
@@ -91,7 +119,7 @@ func TestAddRunRemoveSchedules(t *testing.T) {
 			ce := adaptertesting.NewTestClient()
 
 			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger)
-			entryId := runner.AddSchedule(tc.src)
+			entryId := mustAddSchedule(t, runner, tc.src)
 
 			entry := runner.cron.Entry(entryId)
 			if entry.ID != entryId {
@@ -102,7 +130,9 @@ func TestAddRunRemoveSchedules(t *testing.T) {
 
 			validateSent(t, ce, `{"body":"some data"}`, tc.src.Spec.CloudEventOverrides.Extensions)
 
-			runner.RemoveSchedule(entryId)
+			if err := runner.RemoveSchedule(entryId); err != nil {
+				t.Error("Unexpected error removing the entry:", err)
+			}
 
 			entry = runner.cron.Entry(entryId)
 			if entry.ID == entryId {
@@ -112,6 +142,96 @@ func TestAddRunRemoveSchedules(t *testing.T) {
 	}
 }
 
+func TestInvalidEntryIDs(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logger)
+
+	if err := runner.RemoveSchedule(cron.EntryID(42)); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound removing a never-added entry, got %v", err)
+	}
+	if err := runner.RemoveSchedule(cron.EntryID(0)); !errors.Is(err, ErrInvalidEntryID) {
+		t.Errorf("Expected ErrInvalidEntryID removing the zero entry, got %v", err)
+	}
+	if _, err := runner.Entry(cron.EntryID(-1)); !errors.Is(err, ErrInvalidEntryID) {
+		t.Errorf("Expected ErrInvalidEntryID looking up an invalid entry, got %v", err)
+	}
+	if _, err := runner.Entry(cron.EntryID(42)); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound looking up a never-added entry, got %v", err)
+	}
+
+	id, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.Schedule = "not a schedule"
+	}))
+	if err == nil {
+		t.Error("Expected the zero entry ID for an invalid schedule, got", id)
+	}
+	if got := len(runner.cron.Entries()); got != 0 {
+		t.Error("Expected no entry to be registered, got", got)
+	}
+}
+
+func TestScheduleIndex(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	source := func(name, schedule string) *sourcesv1beta1.PingSource {
+		return newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Name = name
+			s.Spec.Schedule = schedule
+		})
+	}
+	first := mustAddSchedule(t, runner, source("first", "* * * * ?"))
+	second := mustAddSchedule(t, runner, source("second", "0 * * * ?"))
+
+	var scheduleErr *ScheduleError
+	if id, err := runner.AddSchedule(source("first", "0 0 * * ?")); !errors.As(err, &scheduleErr) || scheduleErr.Reason != ReasonAlreadyScheduled || id != 0 {
+		t.Errorf("Expected an AlreadyScheduled error adding an already scheduled source, got (%d, %v)", id, err)
+	}
+
+	entry, ok := runner.GetSchedule("test-ns", "first")
+	if !ok || entry.ID != first {
+		t.Errorf("GetSchedule(first) = (%d, %v), want (%d, true)", entry.ID, ok, first)
+	}
+	if _, ok := runner.GetSchedule("test-ns", "unknown"); ok {
+		t.Error("Expected an unknown source not to be scheduled")
+	}
+
+	var ids []cron.EntryID
+	for _, entry := range runner.ListSchedules() {
+		ids = append(ids, entry.ID)
+	}
+	if want := []cron.EntryID{first, second}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ListSchedules() = %v, want %v", ids, want)
+	}
+
+	if err := runner.RemoveScheduleByKey("test-ns", "first"); err != nil {
+		t.Error("Unexpected error removing the first source:", err)
+	}
+	if err := runner.RemoveScheduleByKey("test-ns", "first"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected ErrEntryNotFound removing the first source again, got %v", err)
+	}
+	if _, ok := runner.GetSchedule("test-ns", "first"); ok {
+		t.Error("Expected the first source not to be scheduled once removed")
+	}
+	if _, err := runner.Entry(first); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Expected the first entry to be removed, got %v", err)
+	}
+
+	if err := runner.RemoveSchedule(second); err != nil {
+		t.Error("Unexpected error removing the second entry:", err)
+	}
+	if got := runner.ListSchedules(); len(got) != 0 {
+		t.Error("Expected no schedule left, got", got)
+	}
+
+	// The removed sources can be added again.
+	if _, err := runner.AddSchedule(source("first", "* * * * ?")); err != nil {
+		t.Error("Expected the removed source to be added again, got", err)
+	}
+}
+
 func TestStartStopCron(t *testing.T) {
 	ctx, _ := rectesting.SetupFakeContext(t)
 	logger := logging.FromContext(ctx)
@@ -151,28 +271,16 @@ func TestStartStopCronDelayWait(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go func() {
-		runner.AddSchedule(
-			&sourcesv1beta1.PingSource{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-name",
-					Namespace: "test-ns",
-				},
-				Spec: sourcesv1beta1.PingSourceSpec{
-					SourceSpec: duckv1.SourceSpec{
-						CloudEventOverrides: &duckv1.CloudEventOverrides{},
-					},
-					Schedule: "* * * * *",
-					JsonData: "some delayed data",
-				},
-				Status: sourcesv1beta1.PingSourceStatus{
-					SourceStatus: duckv1.SourceStatus{
-						SinkURI: &apis.URL{Path: "a delayed sink"},
-					},
-				},
-			})
-		runner.Start(ctx.Done())
-	}()
+	mustAddSchedule(t, runner,
+		newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Spec.Schedule = "* * * * *"
+			s.Spec.JsonData = "some delayed data"
+			s.Status.SinkURI = apis.HTTP("delayed-sink")
+			s.Spec.SourceSpec = duckv1.SourceSpec{
+				CloudEventOverrides: &duckv1.CloudEventOverrides{},
+			}
+		}))
+	go runner.Start(ctx.Done())
 
 	tn = time.Now()
 	seconds = tn.Second()
@@ -185,6 +293,252 @@ func TestStartStopCronDelayWait(t *testing.T) {
 
 }
 
+func TestStopWithTimeout(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClientWithDelay(2 * time.Second)
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.JsonData = "some delayed data"
+		s.Status.SinkURI = apis.HTTP("delayed-sink")
+	}))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runner.cron.Entry(entryID).Job.Run()
+	}()
+	defer func() { <-done }()
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		return atomic.LoadInt64(&runner.inflightCount) == 1, nil
+	}); err != nil {
+		t.Fatal("Expected the job to be running")
+	}
+
+	start := time.Now()
+	err := runner.StopWithTimeout(200 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected StopWithTimeout to return within the timeout, took %v", elapsed)
+	}
+	if !errors.Is(err, ErrStopTimeout) {
+		t.Fatalf("Expected ErrStopTimeout, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 jobs still running") {
+		t.Errorf("Expected the error to tell the job still running, got %q", err)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClientWithDelay(500 * time.Millisecond)
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.JsonData = "some delayed data"
+		s.Status.SinkURI = apis.HTTP("delayed-sink")
+	}))
+	if state := runner.DrainState(); state.Paused || state.InFlight != 0 {
+		t.Errorf("Expected an idle running runner, got %+v", state)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runner.cron.Entry(entryID).Job.Run()
+	}()
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		return runner.DrainState().InFlight == 1, nil
+	}); err != nil {
+		t.Fatal("Expected the job to be running")
+	}
+
+	if err := runner.Drain(5 * time.Second); err != nil {
+		t.Fatal("Drain() =", err)
+	}
+	<-done
+	if state := runner.DrainState(); !state.Paused || state.InFlight != 0 {
+		t.Errorf("Expected a drained runner, got %+v", state)
+	}
+	validateSent(t, ce, `{"body":"some delayed data"}`, nil)
+
+	// The runner can still be stopped.
+	if err := runner.StopWithTimeout(time.Second); err != nil {
+		t.Error("StopWithTimeout() =", err)
+	}
+}
+
+func TestConcurrencyPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		want        int
+	}{
+		"allow by default": {
+			want: 2,
+		},
+		"skip": {
+			annotations: map[string]string{ConcurrencyPolicyAnnotation: string(ConcurrencySkip)},
+			want:        1,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClientWithDelay(500 * time.Millisecond)
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = apis.HTTP("slow-sink")
+				s.Annotations = tc.annotations
+			}))
+			job := runner.cron.Entry(entryID).Job
+
+			// The second tick fires while the first one is still sending.
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				job.Run()
+			}()
+			time.Sleep(100 * time.Millisecond)
+			job.Run()
+			<-done
+
+			if got := len(ce.Sent()); got != tc.want {
+				t.Errorf("Expected %d events sent, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGoroutineBudgetSheds(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	ce := adaptertesting.NewTestClientWithDelay(500 * time.Millisecond)
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger, WithMaxGoroutines(1))
+	entryId := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryId).Job
+
+	const fires = 5
+	var wg sync.WaitGroup
+	wg.Add(fires)
+	for i := 0; i < fires; i++ {
+		go func() {
+			defer wg.Done()
+			job.Run()
+		}()
+	}
+	wg.Wait()
+
+	shed := runner.Stats().Shed
+	if shed == 0 {
+		t.Error("Expected fires to be shed")
+	}
+	if got := uint64(len(ce.Sent())); got+shed != fires {
+		t.Errorf("Expected sent (%d) + shed (%d) to be %d", got, shed, fires)
+	}
+}
+
+func TestGoroutineBudgetShedsBeforeJob(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithMaxGoroutines(1))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{ConcurrencyPolicyAnnotation: string(ConcurrencySkip)}
+	}))
+	job := runner.cron.Entry(entryID).Job
+
+	// Exhaust the goroutine budget for the fire to be shed.
+	if !runner.acquire() {
+		t.Fatal("Expected to acquire the goroutine budget")
+	}
+	job.Run()
+	runner.release()
+	if got := runner.Stats().Shed; got != 1 {
+		t.Errorf("Expected 1 fire shed, got %d", got)
+	}
+	if triggered, _, _ := runner.LastRun("test-ns", "test-name"); !triggered.IsZero() {
+		t.Error("Expected the shed fire not to run the job, triggered at", triggered)
+	}
+
+	// The shed fire held neither the budget nor the concurrency policy.
+	job.Run()
+	if got := len(ce.Sent()); got != 1 {
+		t.Errorf("Expected 1 event sent, got %d", got)
+	}
+	if triggered, _, _ := runner.LastRun("test-ns", "test-name"); triggered.IsZero() {
+		t.Error("Expected the fire to run the job")
+	}
+}
+
+func TestRegionExtension(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	ce := adaptertesting.NewTestClient()
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger, WithRegion("eu-west-1"))
+	entryId := mustAddSchedule(t, runner, newTestSource())
+	runner.cron.Entry(entryId).Job.Run()
+
+	validateSent(t, ce, `{"body":"some data"}`, map[string]string{"region": "eu-west-1"})
+}
+
+func TestStopFlushesMetricsAfterDrain(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	ce := adaptertesting.NewTestClient()
+	blocking := &blockingClient{Client: ce, release: make(chan struct{}), sending: make(chan struct{}, 1)}
+
+	var mu sync.Mutex
+	var flushed []int
+	runner := NewCronJobsRunner(blocking, kubeclient.Get(ctx), logger, WithMetricsFlush(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		flushed = append(flushed, len(ce.Sent()))
+	}))
+	entryID := mustAddSchedule(t, runner, newTestSource())
+
+	go runner.cron.Entry(entryID).Job.Run()
+	waitSignal(t, blocking.sending, "the fire to be in flight")
+
+	stopped := make(chan struct{})
+	go func() {
+		runner.Stop()
+		close(stopped)
+	}()
+	// Stop pauses the runner before draining the fire in flight.
+	waitSignal(t, runner.pausedCh, "the runner to be paused")
+	mu.Lock()
+	if len(flushed) != 0 {
+		t.Error("Expected metrics not to be flushed while a fire is in flight")
+	}
+	mu.Unlock()
+
+	close(blocking.release)
+	waitSignal(t, stopped, "the runner to be stopped")
+	if len(flushed) != 1 {
+		t.Fatal("Expected metrics to be flushed once, got", len(flushed))
+	}
+	if flushed[0] != 1 {
+		t.Error("Expected metrics to be flushed after the in-flight fire was sent")
+	}
+}
+
+func TestStopDrainTimeout(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	ce := adaptertesting.NewTestClientWithDelay(10 * time.Second)
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger, WithDrainTimeout(200*time.Millisecond))
+	entryId := mustAddSchedule(t, runner, newTestSource())
+	go runner.cron.Entry(entryId).Job.Run()
+	time.Sleep(50 * time.Millisecond) // fire in flight
+
+	start := time.Now()
+	runner.Stop()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Error("Expected Stop to return after the drain timeout, took", elapsed)
+	}
+}
+
 func validateSent(t *testing.T, ce *adaptertesting.TestCloudEventsClient, wantData string,
 	extensions map[string]string) {
 	if got := len(ce.Sent()); got != 1 {
@@ -215,3 +569,523 @@ func validateSent(t *testing.T, ce *adaptertesting.TestCloudEventsClient, wantDa
 		}
 	}
 }
+
+func TestStopAfterFirstSuccess(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	sendErr := errors.New("sink unavailable")
+	ce := adaptertesting.NewTestClientWithResults(sendErr, sendErr)
+
+	var completed []string
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger, WithCompletionHandler(func(namespace, name string) {
+		completed = append(completed, namespace+"/"+name)
+	}))
+	entryId := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.StopAfterFirstSuccess = true
+	}))
+	job := runner.cron.Entry(entryId).Job
+
+	for i := 0; i < 2; i++ {
+		job.Run()
+		if _, err := runner.Entry(entryId); err != nil {
+			t.Fatalf("Expected the schedule to be kept after failed fire %d, got %v", i+1, err)
+		}
+	}
+	if len(completed) != 0 {
+		t.Fatal("Expected no completion before a successful send, got", completed)
+	}
+
+	job.Run()
+	if _, err := runner.Entry(entryId); !errors.Is(err, ErrEntryNotFound) {
+		t.Error("Expected the schedule to be removed after the successful send, got", err)
+	}
+	if len(completed) != 1 || completed[0] != "test-ns/test-name" {
+		t.Error("Expected a single completion of test-ns/test-name, got", completed)
+	}
+	validateSent(t, ce, `{"body":"some data"}`, nil)
+}
+
+func TestDeliveryStats(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	sendErr := errors.New("sink unavailable")
+	ce := adaptertesting.NewTestClientWithResults(nil, sendErr, nil, sendErr)
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger)
+	entryId := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryId).Job
+	for i := 0; i < 4; i++ {
+		job.Run()
+	}
+
+	want := SourceStats{Sent: 2, Failed: 2, LastFireFailed: 1}
+	if got := runner.Stats().Sources["test-ns/test-name"]; got != want {
+		t.Errorf("Expected stats %+v, got %+v", want, got)
+	}
+
+	if err := runner.RemoveSchedule(entryId); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got := len(runner.Stats().Sources); got != 0 {
+		t.Error("Expected no stats for removed sources, got", got)
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	logger := logging.FromContext(ctx)
+	ce := adaptertesting.NewTestClient()
+
+	redact := func(event *cloudevents.Event) error {
+		var data map[string]interface{}
+		if err := event.DataAs(&data); err != nil {
+			return err
+		}
+		delete(data, "password")
+		return event.SetData(cloudevents.ApplicationJSON, data)
+	}
+	fail := func(event *cloudevents.Event) error {
+		if event.Source() == sourcesv1beta1.PingSourceSource("test-ns", "failing") {
+			return errors.New("transform failed")
+		}
+		return nil
+	}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logger, WithTransforms(redact, fail))
+
+	for _, name := range []string{"first", "second", "failing"} {
+		entryId := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Name = name
+			s.Spec.JsonData = `{"user":"` + name + `","password":"secret"}`
+		}))
+		runner.cron.Entry(entryId).Job.Run()
+	}
+
+	if got := len(ce.Sent()); got != 2 {
+		t.Fatal("Expected 2 events to be sent, got", got)
+	}
+	for i, name := range []string{"first", "second"} {
+		if got, want := string(ce.Sent()[i].Data()), `{"user":"`+name+`"}`; got != want {
+			t.Errorf("Expected data %q, got %q", want, got)
+		}
+	}
+	if got := runner.Stats().Sources["test-ns/failing"].Failed; got != 1 {
+		t.Error("Expected the fire failing to transform to be counted as failed, got", got)
+	}
+}
+
+func TestReconfigureDuringFire(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+
+	setConfig := func(config string) Transform {
+		return func(event *cloudevents.Event) error {
+			event.SetExtension("config", config)
+			return nil
+		}
+	}
+	// delay holds the first fire until released, after it started.
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	delay := func(*cloudevents.Event) error {
+		once.Do(func() {
+			close(started)
+			<-release
+		})
+		return nil
+	}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithTransforms(delay, setConfig("old")))
+
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryID).Job
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job.Run()
+	}()
+	<-started
+	runner.Reconfigure(WithTransforms(setConfig("new")))
+	close(release)
+	<-done
+
+	job.Run()
+
+	sent := ce.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 events sent, got %d", len(sent))
+	}
+	for i, want := range []string{"old", "new"} {
+		if got := sent[i].Extensions()["config"]; got != want {
+			t.Errorf("Expected event %d to be sent with the %s config, got %v", i, want, got)
+		}
+	}
+}
+
+func TestMaxFireStaleness(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+
+	// queueDelay holds the fires back by the given delays in turn, as when
+	// they are queued behind others.
+	fakeClock := clock.NewFakeClock(time.Now())
+	delays := []time.Duration{30 * time.Second, 2 * time.Minute, 0}
+	queueDelay := func(*cloudevents.Event) error {
+		fakeClock.Step(delays[0])
+		delays = delays[1:]
+		return nil
+	}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithTransforms(queueDelay))
+	runner.clock = fakeClock
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.MaxFireStaleness = &metav1.Duration{Duration: time.Minute}
+	}))
+	job := runner.cron.Entry(entryID).Job
+	for i := 0; i < 3; i++ {
+		job.Run()
+	}
+
+	if got := len(ce.Sent()); got != 2 {
+		t.Errorf("Expected the fresh fires to be sent, got %d events", got)
+	}
+	stats := runner.Stats().Sources["test-ns/test-name"]
+	if stats.Stale != 1 || stats.Sent != 2 {
+		t.Errorf("Expected 1 stale fire skipped and 2 sent, got %+v", stats)
+	}
+}
+
+func TestAnnotationExtensions(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{
+			ExtensionAnnotationPrefix + "team":     "payments",
+			ExtensionAnnotationPrefix + "tier":     "annotated",
+			ExtensionAnnotationPrefix:              "no name",
+			"other.knative.dev/ignored":            "ignored",
+			VerboseLoggingAnnotation + "-disabled": "false",
+		}
+		s.Spec.SourceSpec = duckv1.SourceSpec{
+			CloudEventOverrides: &duckv1.CloudEventOverrides{
+				Extensions: map[string]string{"tier": "overridden"},
+			},
+		}
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := len(ce.Sent()); got != 1 {
+		t.Fatal("Expected 1 event sent, got", got)
+	}
+	want := map[string]interface{}{
+		"team": "payments",
+		"tier": "overridden",
+	}
+	if got := ce.Sent()[0].Extensions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected extensions %v, got %v", want, got)
+	}
+}
+
+func TestDataRef(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.DataRef = "https://storage.example.com/payload.json"
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := len(ce.Sent()); got != 1 {
+		t.Fatal("Expected 1 event sent, got", got)
+	}
+	event := ce.Sent()[0]
+	if got := event.Extensions()[dataRefExtension]; got != "https://storage.example.com/payload.json" {
+		t.Errorf("Expected the dataref extension to be the payload URL, got %v", got)
+	}
+	if data := event.Data(); len(data) != 0 {
+		t.Errorf("Expected no inline data, got %q", data)
+	}
+	if got := event.DataContentType(); got != "" {
+		t.Errorf("Expected no data content type, got %q", got)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	testCases := map[string]struct {
+		spec            sourcesv1beta1.PingSourceSpec
+		wantData        []byte
+		wantContentType string
+	}{
+		"text payload": {
+			spec:            sourcesv1beta1.PingSourceSpec{JsonData: "some data", ContentType: "text/plain"},
+			wantData:        []byte("some data"),
+			wantContentType: "text/plain",
+		},
+		"binary payload": {
+			spec:            sourcesv1beta1.PingSourceSpec{DataBase64: "AAEC/w==", ContentType: "image/png"},
+			wantData:        []byte{0x00, 0x01, 0x02, 0xff},
+			wantContentType: "image/png",
+		},
+		"binary payload without content type": {
+			spec:            sourcesv1beta1.PingSourceSpec{DataBase64: "AAEC/w=="},
+			wantData:        []byte{0x00, 0x01, 0x02, 0xff},
+			wantContentType: "application/octet-stream",
+		},
+		"binary payload over json data": {
+			spec:            sourcesv1beta1.PingSourceSpec{JsonData: "some data", DataBase64: "AAEC/w=="},
+			wantData:        []byte{0x00, 0x01, 0x02, 0xff},
+			wantContentType: "application/octet-stream",
+		},
+		"json data": {
+			spec:            sourcesv1beta1.PingSourceSpec{JsonData: "some data"},
+			wantData:        []byte(`{"body":"some data"}`),
+			wantContentType: cloudevents.ApplicationJSON,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			tc.spec.Schedule = "* * * * ?"
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec = tc.spec
+			}))
+			runner.cron.Entry(entryID).Job.Run()
+
+			if got := len(ce.Sent()); got != 1 {
+				t.Fatal("Expected 1 event sent, got", got)
+			}
+			event := ce.Sent()[0]
+			if got := event.Data(); !bytes.Equal(got, tc.wantData) {
+				t.Errorf("Expected data %q, got %q", tc.wantData, got)
+			}
+			if got := event.DataContentType(); got != tc.wantContentType {
+				t.Errorf("Expected data content type %q, got %q", tc.wantContentType, got)
+			}
+		})
+	}
+}
+
+func TestInvalidDataBase64(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	_, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.DataBase64 = "not base64!"
+	}))
+	if err == nil {
+		t.Error("Expected a source with invalid base64 data not to be scheduled")
+	}
+}
+
+func TestMakeEventInvalidData(t *testing.T) {
+	testCases := map[string]struct {
+		spec    sourcesv1beta1.PingSourceSpec
+		wantErr bool
+	}{
+		"invalid dataBase64": {
+			spec:    sourcesv1beta1.PingSourceSpec{DataBase64: "not base64!", ContentType: "application/octet-stream"},
+			wantErr: true,
+		},
+		"valid dataBase64": {
+			spec: sourcesv1beta1.PingSourceSpec{DataBase64: "c29tZSBkYXRh"},
+		},
+		"referenced data": {
+			spec: sourcesv1beta1.PingSourceSpec{DataBase64: "not base64!", DataRef: "ref"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := makeEvent(newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec = tc.spec
+			}))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("makeEvent() = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPanickingJobDisabled(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+
+	panics := 0
+	crash := func(*cloudevents.Event) error {
+		panics++
+		panic("boom")
+	}
+	var disabled []string
+	var recovered interface{}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithTransforms(crash),
+		WithPanicHandler(3, func(namespace, name string, r interface{}) {
+			disabled = append(disabled, namespace+"/"+name)
+			recovered = r
+		}))
+
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryID).Job
+	for i := 0; i < 2; i++ {
+		job.Run()
+	}
+	if len(disabled) != 0 {
+		t.Fatal("Expected the source not to be disabled before 3 consecutive panics, got", disabled)
+	}
+	if _, err := runner.Entry(entryID); err != nil {
+		t.Fatal("Expected the schedule to be kept, got", err)
+	}
+
+	job.Run()
+	if panics != 3 {
+		t.Errorf("Expected 3 fires to panic, got %d", panics)
+	}
+	if len(disabled) != 1 || disabled[0] != "test-ns/test-name" || recovered != "boom" {
+		t.Errorf("Expected the source to be disabled once with the recovered panic, got %v and %v", disabled, recovered)
+	}
+	if _, err := runner.Entry(entryID); !errors.Is(err, ErrEntryNotFound) {
+		t.Error("Expected the schedule of the panicking source to be removed, got", err)
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event sent, got %d", got)
+	}
+}
+
+func TestPanicCountReset(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+
+	// Every other fire panics, never on consecutive fires.
+	fires := 0
+	flaky := func(*cloudevents.Event) error {
+		fires++
+		if fires%2 == 1 {
+			panic("boom")
+		}
+		return nil
+	}
+	var disabled int
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithTransforms(flaky),
+		WithPanicHandler(2, func(string, string, interface{}) { disabled++ }))
+
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryID).Job
+	for i := 0; i < 6; i++ {
+		job.Run()
+	}
+
+	if disabled != 0 {
+		t.Errorf("Expected the source not to be disabled, got disabled %d times", disabled)
+	}
+	if got := len(ce.Sent()); got != 3 {
+		t.Errorf("Expected 3 events sent, got %d", got)
+	}
+}
+
+// overlapClient takes delay to send each event, recording the maximum
+// number of sends in flight at the same time.
+type overlapClient struct {
+	cloudevents.Client
+
+	delay       time.Duration
+	inflight    int32
+	maxInflight int32
+}
+
+func (c *overlapClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	n := atomic.AddInt32(&c.inflight, 1)
+	defer atomic.AddInt32(&c.inflight, -1)
+	for {
+		max := atomic.LoadInt32(&c.maxInflight)
+		if n <= max || atomic.CompareAndSwapInt32(&c.maxInflight, max, n) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+	return c.Client.Send(ctx, event)
+}
+
+func TestOrderedSends(t *testing.T) {
+	for _, ordered := range []bool{true, false} {
+		ctx, _ := rectesting.SetupFakeContext(t)
+		ce := adaptertesting.NewTestClient()
+		client := &overlapClient{Client: ce, delay: 200 * time.Millisecond}
+		runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+		entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Spec.JsonData = `{"a":"0123456789"}`
+			s.Annotations = map[string]string{MaxEventSizeAnnotation: "8", OversizePolicyAnnotation: string(OversizeSplit)}
+			s.Spec.Ordered = ordered
+		}))
+		job := runner.cron.Entry(entryID).Job
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				job.Run()
+			}()
+		}
+		wg.Wait()
+
+		// Each fire sends 3 chunks.
+		if got := runner.Stats().Sources["test-ns/test-name"].Sent; got != 9 {
+			t.Errorf("Expected 9 chunks sent, got %d", got)
+		}
+		maxInflight := atomic.LoadInt32(&client.maxInflight)
+		if ordered && maxInflight != 1 {
+			t.Errorf("Expected the sends of an ordered source not to overlap, got %d in flight", maxInflight)
+		}
+		if !ordered && maxInflight == 1 {
+			t.Error("Expected the sends of an unordered source to overlap")
+		}
+	}
+}
+
+func TestLastRun(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	nack := protocol.NewReceipt(false, "%w", protocol.ResultNACK)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClientWithResults(protocol.ResultACK, nack), kubeclient.Get(ctx), logging.FromContext(ctx))
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+	runner.clock = fakeClock
+
+	if _, _, ok := runner.LastRun("test-ns", "test-name"); ok {
+		t.Fatal("Expected no last run for a source not scheduled")
+	}
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("mysink")
+	}))
+	triggered, succeeded, ok := runner.LastRun("test-ns", "test-name")
+	if !ok || !triggered.IsZero() || !succeeded.IsZero() {
+		t.Fatalf("Expected no run yet, got triggered %v, succeeded %v, ok %v", triggered, succeeded, ok)
+	}
+
+	job := runner.cron.Entry(entryID).Job
+	job.Run()
+	triggered, succeeded, _ = runner.LastRun("test-ns", "test-name")
+	if !triggered.Equal(start) || !succeeded.Equal(start) {
+		t.Errorf("Expected the sent fire at %v, got triggered %v, succeeded %v", start, triggered, succeeded)
+	}
+
+	fakeClock.Step(time.Minute)
+	job.Run()
+	triggered, succeeded, _ = runner.LastRun("test-ns", "test-name")
+	if !triggered.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected the failed fire to advance the triggered time to %v, got %v", start.Add(time.Minute), triggered)
+	}
+	if !succeeded.Equal(start) {
+		t.Errorf("Expected the failed fire to keep the succeeded time %v, got %v", start, succeeded)
+	}
+
+	if err := runner.RemoveScheduleByKey("test-ns", "test-name"); err != nil {
+		t.Fatal("Failed to remove the schedule:", err)
+	}
+	if _, _, ok := runner.LastRun("test-ns", "test-name"); ok {
+		t.Error("Expected the last run to be cleared with the schedule")
+	}
+}
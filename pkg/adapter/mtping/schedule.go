@@ -0,0 +1,211 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/robfig/cron/v3"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// scheduleIndexExtension is the index of the schedule an event was sent for,
+// when all the schedules firing at the same time send an event.
+const scheduleIndexExtension = "scheduleindex"
+
+// offsetSchedule fires at the given second offsets within each minute
+// matched by a minute-granularity schedule.
+type offsetSchedule struct {
+	minutes cron.Schedule
+	// offsets are sorted and distinct.
+	offsets []time.Duration
+}
+
+var _ cron.Schedule = (*offsetSchedule)(nil)
+
+// Next implements cron.Schedule.
+func (s *offsetSchedule) Next(t time.Time) time.Time {
+	// Fire in the current minute when it matches and an offset is left.
+	minute := t.Truncate(time.Minute)
+	if fires(s.minutes, minute) {
+		for _, offset := range s.offsets {
+			if next := minute.Add(offset); next.After(t) {
+				return next
+			}
+		}
+	}
+	next := s.minutes.Next(minute)
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(s.offsets[0])
+}
+
+// parseSecondOffsets parses a comma-separated list of seconds within a minute.
+func parseSecondOffsets(value string) ([]time.Duration, error) {
+	seen := make(map[int]bool)
+	var offsets []time.Duration
+	for _, field := range strings.Split(value, ",") {
+		second, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || second < 0 || second > 59 {
+			return nil, fmt.Errorf("invalid second offset %q, must be an integer between 0 and 59", field)
+		}
+		if !seen[second] {
+			seen[second] = true
+			offsets = append(offsets, time.Duration(second)*time.Second)
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets, nil
+}
+
+// intervalSchedule fires at the multiples of an interval, the fires of a
+// source being the same on all the adapter replicas and across restarts.
+type intervalSchedule time.Duration
+
+var _ cron.Schedule = intervalSchedule(0)
+
+// Next implements cron.Schedule.
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(s)).Add(time.Duration(s))
+}
+
+// multiSchedule fires whenever one of its schedules fires.
+type multiSchedule []cron.Schedule
+
+var _ cron.Schedule = (multiSchedule)(nil)
+
+// Next implements cron.Schedule.
+func (s multiSchedule) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, schedule := range s {
+		if n := schedule.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+// firing returns the indices of the schedules firing at t.
+func (s multiSchedule) firing(t time.Time) []int {
+	var indices []int
+	for i, schedule := range s {
+		if fires(schedule, t) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// fires returns whether schedule fires at t, t having a second granularity.
+func fires(schedule cron.Schedule, t time.Time) bool {
+	return schedule.Next(t.Add(-time.Second)).Equal(t)
+}
+
+// sourceSchedules parses Schedule and Schedules of source, in this order,
+// aligned on the seconds of SecondOffsetsAnnotation when set, or returns the
+// schedule of its Interval.
+func sourceSchedules(source *sourcesv1beta1.PingSource) (multiSchedule, error) {
+	// The second offsets align minute schedules, not applying to an interval.
+	if interval := source.Spec.Interval; interval != nil {
+		if interval.Duration < time.Second {
+			return nil, fmt.Errorf("invalid interval %v, must be at least 1s", interval.Duration)
+		}
+		return multiSchedule{intervalSchedule(interval.Duration)}, nil
+	}
+
+	var offsets []time.Duration
+	if value, ok := source.Annotations[SecondOffsetsAnnotation]; ok {
+		var err error
+		if offsets, err = parseSecondOffsets(value); err != nil {
+			return nil, err
+		}
+	}
+
+	specs := append([]string{source.Spec.Schedule}, source.Spec.Schedules...)
+	schedules := make(multiSchedule, 0, len(specs))
+	for _, spec := range specs {
+		schedule, err := sourcesv1beta1.ParseSchedule(inTimezone(source, spec))
+		if err != nil {
+			return nil, err
+		}
+		if offsets != nil {
+			schedule = &offsetSchedule{minutes: schedule, offsets: offsets}
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// inTimezone returns spec evaluated in the timezone of source, unless spec
+// sets its own timezone. An invalid timezone fails the parsing of spec.
+func inTimezone(source *sourcesv1beta1.PingSource, spec string) string {
+	if tz := source.Spec.Timezone; tz != "" && !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		return "CRON_TZ=" + tz + " " + spec
+	}
+	return spec
+}
+
+// single returns a schedule firing whenever one of the schedules fires.
+func (s multiSchedule) single() cron.Schedule {
+	if len(s) == 1 {
+		return s[0]
+	}
+	return s
+}
+
+// sourceSchedule parses the schedules of source into a single schedule.
+func sourceSchedule(source *sourcesv1beta1.PingSource) (cron.Schedule, error) {
+	schedules, err := sourceSchedules(source)
+	if err != nil {
+		return nil, err
+	}
+	return schedules.single(), nil
+}
+
+// schedulesTick returns the job sending the events of source for its
+// schedules, according to policy when several fire at the same time.
+func (a *cronJobsRunner) schedulesTick(ctx context.Context, event cloudevents.Event, opts jobOptions,
+	schedules multiSchedule, policy OverlapPolicy) func() {
+	if policy != OverlapSendAll || len(schedules) == 1 {
+		return a.cronTick(ctx, event, opts)
+	}
+
+	ticks := make([]func(), len(schedules))
+	for i := range schedules {
+		event := event.Clone()
+		event.SetExtension(scheduleIndexExtension, i)
+		ticks[i] = a.cronTick(ctx, event, opts)
+	}
+	return func() {
+		firing := schedules.firing(a.clock.Now().Truncate(time.Second))
+		if len(firing) == 0 {
+			// Fired late, the firing schedules can't be told apart.
+			firing = []int{0}
+		}
+		for _, i := range firing {
+			ticks[i]()
+		}
+	}
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestSecondOffsets(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 10, 0, time.UTC)
+	testCases := map[string]struct {
+		schedule string
+		offsets  string
+		want     []string
+	}{
+		"every minute": {
+			schedule: "* * * * *",
+			offsets:  "35,5",
+			want:     []string{"12:00:35", "12:01:05", "12:01:35", "12:02:05"},
+		},
+		"even minutes": {
+			schedule: "*/2 * * * *",
+			offsets:  "5,35",
+			want:     []string{"12:00:35", "12:02:05", "12:02:35", "12:04:05"},
+		},
+		"offset passed in matching minute": {
+			schedule: "*/2 * * * *",
+			offsets:  "0,5",
+			want:     []string{"12:02:00", "12:02:05", "12:04:00", "12:04:05"},
+		},
+		"duplicated offsets": {
+			schedule: "* * * * *",
+			offsets:  "30, 30",
+			want:     []string{"12:00:30", "12:01:30", "12:02:30", "12:03:30"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+			entryId := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Schedule = "CRON_TZ=UTC " + tc.schedule
+				s.Annotations = map[string]string{SecondOffsetsAnnotation: tc.offsets}
+			}))
+			if entryId == 0 {
+				t.Fatal("Expected the source to be scheduled")
+			}
+			schedule := runner.cron.Entry(entryId).Schedule
+
+			fakeClock := clock.NewFakeClock(start)
+			var got []string
+			for i := 0; i < 4; i++ {
+				next := schedule.Next(fakeClock.Now())
+				got = append(got, next.Format("15:04:05"))
+				fakeClock.SetTime(next)
+			}
+			for i := range tc.want {
+				if got[i] != tc.want[i] {
+					t.Errorf("Expected fires at %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidSecondOffsets(t *testing.T) {
+	for _, offsets := range []string{"", "60", "-1", "5,x"} {
+		t.Run(offsets, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+			entryId, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Schedule = "* * * * *"
+				s.Annotations = map[string]string{SecondOffsetsAnnotation: offsets}
+			}))
+			if err == nil {
+				t.Error("Expected invalid offsets to be rejected, got", entryId)
+			}
+		})
+	}
+}
+
+func TestOverlappingSchedules(t *testing.T) {
+	testCases := map[string]struct {
+		policy OverlapPolicy
+		now    string
+		want   []interface{} // scheduleindex extensions, nil when unset
+	}{
+		"coalesce": {
+			policy: OverlapCoalesce,
+			now:    "12:02:00",
+			want:   []interface{}{nil},
+		},
+		"default": {
+			now:  "12:02:00",
+			want: []interface{}{nil},
+		},
+		"send all": {
+			policy: OverlapSendAll,
+			now:    "12:02:00",
+			want:   []interface{}{int32(0), int32(1)},
+		},
+		"send all single": {
+			policy: OverlapSendAll,
+			now:    "12:03:00",
+			want:   []interface{}{int32(0)},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+			now, _ := time.Parse("15:04:05", tc.now)
+			runner.clock = clock.NewFakeClock(time.Date(2020, 6, 1, now.Hour(), now.Minute(), 0, 0, time.UTC))
+
+			annotations := map[string]string{}
+			if tc.policy != "" {
+				annotations[OverlapPolicyAnnotation] = string(tc.policy)
+			}
+			entryId := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Schedule = "CRON_TZ=UTC * * * * *"
+				s.Annotations = annotations
+				s.Spec.Schedules = []string{"CRON_TZ=UTC */2 * * * *"}
+			}))
+			if entryId == 0 {
+				t.Fatal("Expected the source to be scheduled")
+			}
+			runner.cron.Entry(entryId).Job.Run()
+
+			sent := ce.Sent()
+			if len(sent) != len(tc.want) {
+				t.Fatalf("Expected %d events, got %d", len(tc.want), len(sent))
+			}
+			for i, event := range sent {
+				if got := event.Extensions()[scheduleIndexExtension]; got != tc.want[i] {
+					t.Errorf("Expected event %d scheduleindex %v, got %v", i, tc.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiScheduleNext(t *testing.T) {
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.Schedule = "CRON_TZ=UTC 0 * * * *"
+		s.Spec.Schedules = []string{"CRON_TZ=UTC 30 * * * *", "CRON_TZ=UTC 45 12 * * *"}
+	})
+	schedule, err := sourceSchedule(source)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	fakeClock := clock.NewFakeClock(time.Date(2020, 6, 1, 12, 10, 0, 0, time.UTC))
+	var got []string
+	for i := 0; i < 4; i++ {
+		next := schedule.Next(fakeClock.Now())
+		got = append(got, next.Format("15:04:05"))
+		fakeClock.SetTime(next)
+	}
+	want := []string{"12:30:00", "12:45:00", "13:00:00", "13:30:00"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected fires at %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestSecondsSchedule(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 10, 0, time.UTC)
+	testCases := map[string]struct {
+		schedule string
+		want     []string
+	}{
+		"every 15 seconds": {
+			schedule: "*/15 * * * * *",
+			want:     []string{"12:00:15", "12:00:30", "12:00:45", "12:01:00"},
+		},
+		"standard": {
+			schedule: "* * * * ?",
+			want:     []string{"12:01:00", "12:02:00", "12:03:00", "12:04:00"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Schedule = "CRON_TZ=UTC " + tc.schedule
+			}))
+			if entryID == 0 {
+				t.Fatal("Expected the schedule to be added")
+			}
+
+			schedule := runner.cron.Entry(entryID).Schedule
+			var got []string
+			for next := start; len(got) < len(tc.want); {
+				next = schedule.Next(next)
+				got = append(got, next.Format("15:04:05"))
+			}
+			for i := range tc.want {
+				if got[i] != tc.want[i] {
+					t.Errorf("Expected fires at %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidSecondsSchedule(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	entryID, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.Schedule = "*/15 * * * * * *"
+	}))
+	if err == nil {
+		t.Error("Expected a 7 fields schedule not to be added, got entry", entryID)
+	}
+}
+
+func TestIntervalSchedule(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 12, 0, time.UTC)
+	testCases := map[string]struct {
+		interval time.Duration
+		want     []string
+	}{
+		"every 10 seconds": {
+			interval: 10 * time.Second,
+			want:     []string{"12:00:20", "12:00:30", "12:00:40", "12:00:50"},
+		},
+		"every 30 seconds": {
+			interval: 30 * time.Second,
+			want:     []string{"12:00:30", "12:01:00", "12:01:30", "12:02:00"},
+		},
+		"every 90 seconds": {
+			interval: 90 * time.Second,
+			want:     []string{"12:01:30", "12:03:00", "12:04:30", "12:06:00"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Interval = &metav1.Duration{Duration: tc.interval}
+			}))
+			if entryID == 0 {
+				t.Fatal("Expected the schedule to be added")
+			}
+
+			schedule := runner.cron.Entry(entryID).Schedule
+			var got []string
+			for next := start; len(got) < len(tc.want); {
+				next = schedule.Next(next)
+				got = append(got, next.Format("15:04:05"))
+			}
+			for i := range tc.want {
+				if got[i] != tc.want[i] {
+					t.Errorf("Expected fires at %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidIntervalSchedule(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	entryID, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.Interval = &metav1.Duration{Duration: 100 * time.Millisecond}
+	}))
+	if err == nil {
+		t.Error("Expected a sub-second interval not to be added, got entry", entryID)
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("Timezone database not available:", err)
+	}
+	// The clocks of New York move forward on March 8th 2020.
+	start := time.Date(2020, 3, 7, 0, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		schedules []string
+		want      []time.Time
+	}{
+		"schedule": {
+			want: []time.Time{
+				time.Date(2020, 3, 7, 14, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 8, 13, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 9, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		"additional schedules": {
+			schedules: []string{"0 12 * * *"},
+			want: []time.Time{
+				time.Date(2020, 3, 7, 14, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 7, 17, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 8, 13, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 8, 16, 0, 0, 0, time.UTC),
+			},
+		},
+		"schedule setting its own timezone": {
+			schedules: []string{"CRON_TZ=UTC 0 12 * * *"},
+			want: []time.Time{
+				time.Date(2020, 3, 7, 12, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 7, 14, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 8, 12, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 8, 13, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+			entryId := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Schedule = "0 9 * * *"
+				s.Spec.Schedules = tc.schedules
+				s.Spec.Timezone = newYork.String()
+			}))
+			if entryId == 0 {
+				t.Fatal("Expected the source to be scheduled")
+			}
+			schedule := runner.cron.Entry(entryId).Schedule
+
+			next := start
+			for _, want := range tc.want {
+				next = schedule.Next(next)
+				if !next.Equal(want) {
+					t.Errorf("Expected a fire at %v, got %v", want, next.UTC())
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidTimezone(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	_, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.Schedule = "0 9 * * *"
+		s.Spec.Timezone = "Knative/Land"
+	}))
+	if err == nil {
+		t.Error("Expected a source with an invalid timezone not to be scheduled")
+	}
+	if entries := runner.cron.Entries(); len(entries) != 0 {
+		t.Errorf("Expected no cron entry, got %d", len(entries))
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"fmt"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// ScheduleErrorReason tells why a source can't be scheduled, in the form of
+// a condition reason.
+type ScheduleErrorReason string
+
+const (
+	// ReasonAlreadyScheduled is for the sources added while already
+	// scheduled, their changes being applied by UpdateSchedule.
+	ReasonAlreadyScheduled ScheduleErrorReason = "AlreadyScheduled"
+	// ReasonInvalidSchedule is for the schedules failing to parse.
+	ReasonInvalidSchedule ScheduleErrorReason = "InvalidSchedule"
+	// ReasonInvalidSink is for the sources without an absolute sink URI.
+	ReasonInvalidSink ScheduleErrorReason = "InvalidSink"
+	// ReasonInvalidData is for the data that can't be encoded as told by
+	// the content type.
+	ReasonInvalidData ScheduleErrorReason = "InvalidData"
+	// ReasonInvalidSpec is for the other settings failing to parse.
+	ReasonInvalidSpec ScheduleErrorReason = "InvalidSpec"
+	// ReasonReadFailed is for the credentials, data or schema of the source
+	// failing to be read.
+	ReasonReadFailed ScheduleErrorReason = "ReadFailed"
+)
+
+// ScheduleError is returned when a source can't be scheduled.
+type ScheduleError struct {
+	// Reason tells why the source can't be scheduled.
+	Reason ScheduleErrorReason
+	// Source is the CloudEvent source of the source.
+	Source string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ScheduleError) Error() string {
+	return fmt.Sprintf("failed to schedule %s: %s: %v", e.Source, e.Reason, e.Err)
+}
+
+func (e *ScheduleError) Unwrap() error {
+	return e.Err
+}
+
+// errMissingSink is returned for the sources without a sink URI.
+var errMissingSink = errors.New("the sink URI is missing")
+
+// newScheduleError returns the error telling source can't be scheduled for
+// reason.
+func newScheduleError(source *sourcesv1beta1.PingSource, reason ScheduleErrorReason, err error) *ScheduleError {
+	return &ScheduleError{
+		Reason: reason,
+		Source: sourcesv1beta1.PingSourceSource(source.Namespace, source.Name),
+		Err:    err,
+	}
+}
+
+// validateSchedule verifies the schedules, sink and data of source, before
+// anything is registered for it.
+func validateSchedule(source *sourcesv1beta1.PingSource) error {
+	if _, err := sourceSchedules(source); err != nil {
+		return newScheduleError(source, ReasonInvalidSchedule, err)
+	}
+	if spec, ok := source.Annotations[SummaryScheduleAnnotation]; ok {
+		if _, err := sourcesv1beta1.ParseSchedule(spec); err != nil {
+			return newScheduleError(source, ReasonInvalidSchedule, fmt.Errorf("invalid summary schedule: %w", err))
+		}
+	}
+
+	sink := source.Status.SinkURI
+	if sink == nil {
+		return newScheduleError(source, ReasonInvalidSink, errMissingSink)
+	}
+	if !sink.URL().IsAbs() {
+		return newScheduleError(source, ReasonInvalidSink, errRelativeSink)
+	}
+
+	if _, _, err := makeData(source); err != nil {
+		return newScheduleError(source, ReasonInvalidData, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"testing"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestAddScheduleValidation(t *testing.T) {
+	testCases := map[string]struct {
+		update     func(*sourcesv1beta1.PingSource)
+		wantReason ScheduleErrorReason
+	}{
+		"valid": {
+			update: func(*sourcesv1beta1.PingSource) {},
+		},
+		"invalid schedule": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Schedule = "not a schedule"
+			},
+			wantReason: ReasonInvalidSchedule,
+		},
+		"invalid additional schedule": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Schedules = []string{"0 * * * *", "61 * * * *"}
+			},
+			wantReason: ReasonInvalidSchedule,
+		},
+		"invalid timezone": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Timezone = "Mars/Olympus_Mons"
+			},
+			wantReason: ReasonInvalidSchedule,
+		},
+		"invalid summary schedule": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Annotations = map[string]string{SummaryScheduleAnnotation: "never"}
+			},
+			wantReason: ReasonInvalidSchedule,
+		},
+		"missing sink": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = nil
+			},
+			wantReason: ReasonInvalidSink,
+		},
+		"relative sink": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = &apis.URL{Path: "/relative"}
+			},
+			wantReason: ReasonInvalidSink,
+		},
+		"invalid dataBase64": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Spec.JsonData = ""
+				s.Spec.DataBase64 = "not base64!"
+				s.Spec.ContentType = "application/octet-stream"
+			},
+			wantReason: ReasonInvalidData,
+		},
+		"invalid fire condition": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Spec.FireCondition = "fireCount %"
+			},
+			wantReason: ReasonInvalidSpec,
+		},
+		"invalid delivery backoff delay": {
+			update: func(s *sourcesv1beta1.PingSource) {
+				s.Spec.Delivery = &eventingduckv1.DeliverySpec{BackoffDelay: ptr.String("soon")}
+			},
+			wantReason: ReasonInvalidSpec,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			source := newTestSource(tc.update)
+
+			id, err := runner.AddSchedule(source)
+			if tc.wantReason == "" {
+				if err != nil || id == 0 {
+					t.Fatalf("Expected the source to be scheduled, got (%d, %v)", id, err)
+				}
+				return
+			}
+			var scheduleErr *ScheduleError
+			if !errors.As(err, &scheduleErr) {
+				t.Fatalf("Expected a *ScheduleError, got %v", err)
+			}
+			if scheduleErr.Reason != tc.wantReason {
+				t.Errorf("Expected the reason %s, got %s (%v)", tc.wantReason, scheduleErr.Reason, err)
+			}
+			if want := sourcesv1beta1.PingSourceSource("test-ns", "test-name"); scheduleErr.Source != want {
+				t.Errorf("Expected the source %q, got %q", want, scheduleErr.Source)
+			}
+			if id != 0 {
+				t.Errorf("Expected the zero entry ID, got %d", id)
+			}
+			if got := len(runner.cron.Entries()); got != 0 {
+				t.Errorf("Expected no cron entry registered, got %d", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+)
+
+// schemaFingerprint returns a digest of the shape of event: its type, content
+// type, data schema, extension names and the structure of its JSON data.
+// Events differing only by their values have the same fingerprint.
+func schemaFingerprint(event cloudevents.Event) string {
+	extensions := make([]string, 0, len(event.Extensions()))
+	for name := range event.Extensions() {
+		extensions = append(extensions, name)
+	}
+	sort.Strings(extensions)
+
+	var b strings.Builder
+	b.WriteString("type=" + event.Type())
+	b.WriteString(";datacontenttype=" + event.DataContentType())
+	b.WriteString(";dataschema=" + event.DataSchema())
+	b.WriteString(";extensions=" + strings.Join(extensions, ","))
+	b.WriteString(";data=" + dataShape(event.Data()))
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// dataShape describes the structure of data when it is JSON.
+func dataShape(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "bytes"
+	}
+	return jsonShape(v)
+}
+
+// jsonShape describes the structure of a decoded JSON value, listing the
+// distinct shapes of the elements of arrays.
+func jsonShape(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		fields := make([]string, 0, len(v))
+		for k, e := range v {
+			fields = append(fields, k+":"+jsonShape(e))
+		}
+		sort.Strings(fields)
+		return "{" + strings.Join(fields, ",") + "}"
+	case []interface{}:
+		seen := make(map[string]bool, len(v))
+		elems := make([]string, 0, len(v))
+		for _, e := range v {
+			if s := jsonShape(e); !seen[s] {
+				seen[s] = true
+				elems = append(elems, s)
+			}
+		}
+		sort.Strings(elems)
+		return "[" + strings.Join(elems, "|") + "]"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// schemaRecorder records the last schema fingerprint of the events of each
+// source, and how many times it changed. Records are kept when a schedule
+// is removed so that the changes made on reconcile are detected.
+type schemaRecorder struct {
+	mu      sync.Mutex
+	records map[string]*schemaRecord // key: resource namespace/name
+}
+
+type schemaRecord struct {
+	fingerprint string
+	changes     uint64
+}
+
+func newSchemaRecorder() *schemaRecorder {
+	return &schemaRecorder{records: make(map[string]*schemaRecord)}
+}
+
+// Record records the fingerprint of an event of the source key. It returns
+// the previous fingerprint and true when it differs from fingerprint.
+func (r *schemaRecorder) Record(key, fingerprint string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[key]
+	if !ok {
+		r.records[key] = &schemaRecord{fingerprint: fingerprint}
+		return "", false
+	}
+	previous := record.fingerprint
+	if previous == fingerprint {
+		return previous, false
+	}
+	record.fingerprint = fingerprint
+	record.changes++
+	return previous, true
+}
+
+// Get returns the last fingerprint recorded for the source key, and the
+// number of times it changed.
+func (r *schemaRecorder) Get(key string) (string, uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.records[key]; ok {
+		return record.fingerprint, record.changes
+	}
+	return "", 0
+}
+
+// recordSchema records the schema of the event of a fire, logging its
+// changes.
+func (a *cronJobsRunner) recordSchema(opts jobOptions, event cloudevents.Event) {
+	if a.schemas == nil {
+		return
+	}
+	fingerprint := schemaFingerprint(event)
+	if previous, changed := a.schemas.Record(opts.stats.key, fingerprint); changed {
+		a.Logger.Infow("cloudevent schema changed", zap.String("source", event.Source()),
+			zap.String("previous", previous), zap.String("fingerprint", fingerprint))
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestSchemaFingerprint(t *testing.T) {
+	fingerprint := func(data string) string {
+		source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Spec.JsonData = data
+		})
+		return schemaFingerprint(mustMakeEvent(t, source))
+	}
+
+	base := fingerprint(`{"user":"alice","age":30,"tags":["a","b"]}`)
+	testCases := map[string]struct {
+		data     string
+		wantSame bool
+	}{
+		"same shape": {
+			data:     `{"age":1,"tags":["c"],"user":"bob"}`,
+			wantSame: true,
+		},
+		"added field": {
+			data: `{"user":"alice","age":30,"tags":["a"],"admin":true}`,
+		},
+		"changed type": {
+			data: `{"user":"alice","age":"30","tags":["a"]}`,
+		},
+		"not json": {
+			data: "some data",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if got := fingerprint(tc.data) == base; got != tc.wantSame {
+				t.Errorf("Expected same fingerprint %v, got %v", tc.wantSame, got)
+			}
+		})
+	}
+}
+
+func TestSchemaChangeDetected(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	core, logs := observer.New(zapcore.InfoLevel)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), zap.New(core).Sugar(), WithSchemaFingerprints())
+
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.JsonData = `{"count":1}`
+	})
+	entryId := mustAddSchedule(t, runner, source)
+	// fire reconciles the source with data and fires it once.
+	fire := func(data string) SourceStats {
+		if err := runner.RemoveSchedule(entryId); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		source.Spec.JsonData = data
+		entryId = mustAddSchedule(t, runner, source)
+		runner.cron.Entry(entryId).Job.Run()
+		return runner.Stats().Sources["test-ns/test-name"]
+	}
+
+	first := fire(`{"count":1}`)
+	if first.Schema == "" || first.SchemaChanges != 0 {
+		t.Fatalf("Expected a recorded schema and no change, got %+v", first)
+	}
+	if got := fire(`{"count":2}`); got.Schema != first.Schema || got.SchemaChanges != 0 {
+		t.Errorf("Expected the schema to be unchanged by new values, got %+v", got)
+	}
+	changed := fire(`{"count":"two"}`)
+	if changed.Schema == first.Schema || changed.SchemaChanges != 1 {
+		t.Errorf("Expected the schema change to be detected, got %+v", changed)
+	}
+
+	entries := logs.FilterMessage("cloudevent schema changed").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected a single schema change logged, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["previous"] != first.Schema || fields["fingerprint"] != changed.Schema {
+		t.Errorf("Expected the change from %s to %s to be logged, got %v", first.Schema, changed.Schema, fields)
+	}
+}
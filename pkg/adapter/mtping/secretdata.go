@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+type sensitiveDataKey struct{}
+
+// withSensitiveData returns a context telling that the events sent with it
+// carry data that must not be logged.
+func withSensitiveData(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sensitiveDataKey{}, true)
+}
+
+// hasSensitiveData returns whether the events sent with ctx carry data that
+// must not be logged.
+func hasSensitiveData(ctx context.Context) bool {
+	sensitive, _ := ctx.Value(sensitiveDataKey{}).(bool)
+	return sensitive
+}
+
+// readSecretData reads the data referenced by the DataFromSecret of the given
+// source. It returns nil when the reference is optional and the Secret or
+// its key is missing. Errors never include the data.
+func readSecretData(ctx context.Context, kubeClient kubernetes.Interface, source *sourcesv1beta1.PingSource) ([]byte, error) {
+	ref := source.Spec.DataFromSecret
+	optional := ref.Optional != nil && *ref.Optional
+
+	secret, err := kubeClient.CoreV1().Secrets(source.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) && optional {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		if optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("secret %s/%s has no %s key", source.Namespace, ref.Name, ref.Key)
+	}
+	return data, nil
+}
+
+// secretDataContext sets the data of event from the Secret of source, when
+// it has one, and returns ctx marking the data sensitive.
+func (a *cronJobsRunner) secretDataContext(ctx context.Context, source *sourcesv1beta1.PingSource, event *cloudevents.Event) (context.Context, error) {
+	if source.Spec.DataFromSecret == nil {
+		return ctx, nil
+	}
+	data, err := readSecretData(ctx, a.kubeClient, source)
+	if err != nil {
+		return nil, newScheduleError(source, ReasonReadFailed, fmt.Errorf("failed to read the cloudevent data: %w", err))
+	}
+	if data != nil {
+		if err := event.SetData(cloudevents.ApplicationJSON, makeMessage(string(data))); err != nil {
+			return nil, newScheduleError(source, ReasonInvalidData, fmt.Errorf("failed to set the cloudevent data: %w", err))
+		}
+	}
+	return withSensitiveData(ctx), nil
+}
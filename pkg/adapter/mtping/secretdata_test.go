@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestDataFromSecret(t *testing.T) {
+	const token = "s3cr3t-t0k3n"
+
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	kubeClient := kubeclient.Get(ctx)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "payload",
+			Namespace: "test-ns",
+		},
+		Data: map[string][]byte{
+			"data": []byte(`{"token":"` + token + `"}`),
+		},
+	}
+	if _, err := kubeClient.CoreV1().Secrets("test-ns").Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create secret:", err)
+	}
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeClient, zap.New(core).Sugar())
+	sinkURI, _ := apis.ParseURL(server.URL)
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+		s.Annotations = map[string]string{VerboseLoggingAnnotation: "true"}
+		s.Spec.DataFromSecret = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "payload"},
+			Key:                  "data",
+		}
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 || !strings.Contains(bodies[0], token) {
+		t.Errorf("Expected an event carrying the secret data, got %v", bodies)
+	}
+
+	if logs.FilterMessage("sending request").Len() == 0 {
+		t.Error("Expected the request to be logged")
+	}
+	for _, entry := range logs.All() {
+		if logged := entry.Message + fmt.Sprint(entry.ContextMap()); strings.Contains(logged, token) {
+			t.Errorf("Expected the secret data not to be logged, got %q", logged)
+		}
+	}
+}
+
+func TestDataFromMissingSecret(t *testing.T) {
+	testCases := map[string]struct {
+		optional      bool
+		wantScheduled bool
+	}{
+		"required": {},
+		"optional": {
+			optional:      true,
+			wantScheduled: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			_, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = apis.HTTP("sink.example.com")
+				s.Spec.DataFromSecret = &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+					Key:                  "data",
+					Optional:             ptr.Bool(tc.optional),
+				}
+			}))
+			if got := err == nil; got != tc.wantScheduled {
+				t.Errorf("Expected scheduled %v, got %v", tc.wantScheduled, got)
+			}
+		})
+	}
+}
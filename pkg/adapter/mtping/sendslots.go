@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+// acquireSend takes a send slot, waiting up to the queue timeout for one
+// to be released when queue is true. It returns false when no slot could
+// be taken, or when the runner is paused meanwhile.
+func (a *cronJobsRunner) acquireSend(queue bool) bool {
+	if a.sendSlots == nil {
+		return true
+	}
+	select {
+	case a.sendSlots <- struct{}{}:
+		return true
+	default:
+	}
+	if !queue || a.sendQueueTimeout <= 0 {
+		return false
+	}
+	select {
+	case a.sendSlots <- struct{}{}:
+		return true
+	case <-a.clock.After(a.sendQueueTimeout):
+		return false
+	case <-a.pausedCh:
+		return false
+	}
+}
+
+func (a *cronJobsRunner) releaseSend() {
+	if a.sendSlots != nil {
+		<-a.sendSlots
+	}
+}
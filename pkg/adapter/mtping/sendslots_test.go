@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+// peakClient measures the peak number of its concurrent sends, each one
+// taking delay, or blocking until release is closed when set.
+type peakClient struct {
+	*adaptertesting.TestCloudEventsClient
+
+	delay   time.Duration
+	release chan struct{}
+
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (c *peakClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	c.mu.Lock()
+	c.current++
+	if c.current > c.peak {
+		c.peak = c.current
+	}
+	c.mu.Unlock()
+
+	if c.release != nil {
+		<-c.release
+	} else {
+		time.Sleep(c.delay)
+	}
+
+	c.mu.Lock()
+	c.current--
+	c.mu.Unlock()
+	return c.TestCloudEventsClient.Send(ctx, event)
+}
+
+func (c *peakClient) Peak() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peak
+}
+
+func TestMaxConcurrentSends(t *testing.T) {
+	const (
+		limit   = 3
+		sources = 20
+	)
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := &peakClient{TestCloudEventsClient: adaptertesting.NewTestClient(), delay: 50 * time.Millisecond}
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithMaxConcurrentSends(limit, time.Minute))
+
+	var wg sync.WaitGroup
+	for i := 0; i < sources; i++ {
+		entryID := mustAddSchedule(t, runner, jitterSource(fmt.Sprint("test-name-", i), "* * * * ?", nil))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runner.cron.Entry(entryID).Job.Run()
+		}()
+	}
+	wg.Wait()
+
+	if got := ce.Peak(); got > limit {
+		t.Errorf("Expected at most %d concurrent sends, got %d", limit, got)
+	}
+	if got := len(ce.Sent()); got != sources {
+		t.Errorf("Expected the %d fires to wait for their turn, got %d events sent", sources, got)
+	}
+	if got := runner.Stats().SendsDropped; got != 0 {
+		t.Errorf("Expected no fire dropped, got %d", got)
+	}
+}
+
+func TestMaxConcurrentSendsDropped(t *testing.T) {
+	testCases := map[string]struct {
+		annotations  map[string]string
+		queueTimeout time.Duration
+	}{
+		"queue timeout": {
+			queueTimeout: 100 * time.Millisecond,
+		},
+		"skip policy": {
+			annotations:  map[string]string{ConcurrencyPolicyAnnotation: string(ConcurrencySkip)},
+			queueTimeout: time.Minute,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			release := make(chan struct{})
+			ce := &peakClient{TestCloudEventsClient: adaptertesting.NewTestClient(), release: release}
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithMaxConcurrentSends(1, tc.queueTimeout))
+
+			busy := mustAddSchedule(t, runner, jitterSource("busy", "* * * * ?", nil))
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				runner.cron.Entry(busy).Job.Run()
+			}()
+			if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+				return ce.Peak() == 1, nil
+			}); err != nil {
+				t.Fatal("The first fire never sent")
+			}
+
+			dropped := mustAddSchedule(t, runner, jitterSource("dropped", "* * * * ?", tc.annotations))
+			runner.cron.Entry(dropped).Job.Run()
+			close(release)
+			<-done
+
+			if got := runner.Stats().SendsDropped; got != 1 {
+				t.Errorf("Expected the second fire to be dropped, got %d fires dropped", got)
+			}
+			if got := len(ce.Sent()); got != 1 {
+				t.Errorf("Expected only the first event to be sent, got %d", got)
+			}
+		})
+	}
+}
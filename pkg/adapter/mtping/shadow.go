@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+)
+
+// mirror sends copies of the events to the shadow sink of the source in the
+// background, and returns a function waiting for them to be sent.
+func (a *cronJobsRunner) mirror(ctx context.Context, opts jobOptions, events []cloudevents.Event) func() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.shadow(ctx, opts, events)
+	}()
+	return wg.Wait
+}
+
+// shadowContext returns the context the copies of the events sent with ctx
+// are sent with. The context of the fire is not used, for the copies not to
+// be retried, nor to carry the sink credentials, nor to be counted with the
+// source events, but they share its deadline and aren't logged when it
+// carries sensitive data.
+func shadowContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	shadowCtx, cancel := context.Background(), context.CancelFunc(func() {})
+	if deadline, ok := ctx.Deadline(); ok {
+		shadowCtx, cancel = context.WithDeadline(shadowCtx, deadline)
+	}
+	if hasSensitiveData(ctx) {
+		shadowCtx = withSensitiveData(shadowCtx)
+	}
+	return shadowCtx, cancel
+}
+
+// shadow sends the events to the shadow sink of the source, once.
+func (a *cronJobsRunner) shadow(ctx context.Context, opts jobOptions, events []cloudevents.Event) {
+	ctx, cancel := shadowContext(ctx)
+	defer cancel()
+	ctx = cloudevents.ContextWithTarget(ctx, opts.shadowSink)
+	for _, event := range events {
+		if result := a.send(ctx, opts.client, event.Clone()); !cloudevents.IsACK(result) {
+			atomic.AddUint64(&opts.stats.shadowFailed, 1)
+			if suppressed, ok := opts.shadowLog.Allow(a.clock.Now()); ok {
+				a.Logger.Warnw("failed to send cloudevent to the shadow sink", zap.Any("result", result),
+					zap.String("source", event.Source()), zap.String("id", event.ID()), zap.Int("suppressed", suppressed))
+			}
+			continue
+		}
+		atomic.AddUint64(&opts.stats.shadowSent, 1)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+const testShadowSink = "http://shadow.example.com/"
+
+// shadowClient records the events sent to the shadow sink apart, failing
+// them when fail is set.
+type shadowClient struct {
+	cloudevents.Client
+
+	fail      bool
+	mu        sync.Mutex
+	shadow    []cloudevents.Event
+	sensitive []bool
+}
+
+func (c *shadowClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	if cecontext.TargetFrom(ctx).String() != testShadowSink {
+		return c.Client.Send(ctx, event)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shadow = append(c.shadow, event)
+	c.sensitive = append(c.sensitive, hasSensitiveData(ctx))
+	if c.fail {
+		return errors.New("shadow sink unavailable")
+	}
+	return protocol.ResultACK
+}
+
+func TestShadowSink(t *testing.T) {
+	for _, fail := range []bool{false, true} {
+		ctx, _ := rectesting.SetupFakeContext(t)
+		ce := adaptertesting.NewTestClient()
+		client := &shadowClient{Client: ce, fail: fail}
+		runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+		shadowURI, _ := apis.ParseURL(testShadowSink)
+		entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Status.ShadowSinkURI = shadowURI
+		}))
+		job := runner.cron.Entry(entryID).Job
+		job.Run()
+		job.Run()
+
+		sent := ce.Sent()
+		if len(sent) != 2 {
+			t.Fatalf("Expected 2 events sent to the sink, got %d", len(sent))
+		}
+		if len(client.shadow) != 2 {
+			t.Fatalf("Expected 2 events sent to the shadow sink, got %d", len(client.shadow))
+		}
+		for i := range sent {
+			if got, want := client.shadow[i].ID(), sent[i].ID(); got != want {
+				t.Errorf("Expected the shadow sink to get a copy of event %s, got %s", want, got)
+			}
+		}
+
+		stats := runner.Stats().Sources["test-ns/test-name"]
+		if stats.Sent != 2 || stats.Failed != 0 {
+			t.Errorf("Expected the shadow sink not to affect the fires, got %+v", stats)
+		}
+		if fail && (stats.ShadowFailed != 2 || stats.ShadowSent != 0) {
+			t.Errorf("Expected 2 events failing to be copied, got %+v", stats)
+		}
+		if !fail && (stats.ShadowSent != 2 || stats.ShadowFailed != 0) {
+			t.Errorf("Expected 2 events copied, got %+v", stats)
+		}
+	}
+}
+
+func TestShadowSinkSensitiveData(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	kubeClient := kubeclient.Get(ctx)
+	if _, err := kubeClient.CoreV1().Secrets("test-ns").Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "payload"},
+		Data:       map[string][]byte{"data": []byte(`{"token":"s3cr3t"}`)},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create secret:", err)
+	}
+	client := &shadowClient{Client: adaptertesting.NewTestClient()}
+	runner := NewCronJobsRunner(client, kubeClient, logging.FromContext(ctx))
+
+	shadowURI, _ := apis.ParseURL(testShadowSink)
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.ShadowSinkURI = shadowURI
+		s.Spec.JsonData = ""
+		s.Spec.DataFromSecret = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "payload"},
+			Key:                  "data",
+		}
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	if len(client.sensitive) != 1 || !client.sensitive[0] {
+		t.Errorf("Expected the copy of the secret data to be sent as sensitive, got %v", client.sensitive)
+	}
+}
+
+func TestShadowContext(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(withSensitiveData(context.Background()), deadline)
+	defer cancel()
+	ctx = cloudevents.ContextWithRetriesConstantBackoff(ctx, time.Second, 3)
+
+	shadowCtx, shadowCancel := shadowContext(ctx)
+	defer shadowCancel()
+	if got, ok := shadowCtx.Deadline(); !ok || !got.Equal(deadline) {
+		t.Errorf("Expected the deadline of the fire %v, got %v", deadline, got)
+	}
+	if !hasSensitiveData(shadowCtx) {
+		t.Error("Expected the copies to carry the sensitivity of the fire")
+	}
+	if cecontext.RetriesFrom(shadowCtx).Strategy != cecontext.BackoffStrategyNone {
+		t.Error("Expected the copies not to be retried")
+	}
+}
@@ -0,0 +1,397 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/hash"
+	kle "knative.dev/pkg/leaderelection"
+	"knative.dev/pkg/reconciler"
+
+	"knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+const (
+	// EnvSharding enables the sharding of the PingSources across the
+	// replicas, see ShardingEnabled.
+	EnvSharding = "K_SHARDING"
+
+	// shardMemberLabel labels the leases telling the replicas sharding the
+	// PingSources are alive, its value being the component.
+	shardMemberLabel = "sources.knative.dev/shard-member"
+
+	// The defaults of knative.dev/pkg, for the durations left zero.
+	defaultShardLeaseDuration = 15 * time.Second
+	defaultShardRenewDeadline = 10 * time.Second
+	defaultShardRetryPeriod   = 2 * time.Second
+)
+
+// ShardingEnabled tells whether the replicas share the buckets of the
+// PingSources, each claiming its fair share of them and giving the others
+// back as replicas come, instead of the buckets staying with the replicas
+// having claimed them first.
+func ShardingEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(EnvSharding))
+	return enabled
+}
+
+// shardedAdapter is implemented by the adapters sharding the PingSources.
+type shardedAdapter interface {
+	// shardingConfig returns the configuration of the buckets, nil when
+	// the PingSources aren't sharded.
+	shardingConfig() *kle.ComponentConfig
+	// park stops firing the schedule of source, its bucket being given
+	// back, keeping its state until the bucket is claimed again.
+	park(ctx context.Context, source *v1beta1.PingSource)
+}
+
+// shardedReconciler runs a sharder promoting and demoting la in the buckets
+// it claims. It is itself promoted in the universal bucket, once, the
+// adapter running without the leader electors of knative.dev/pkg.
+type shardedReconciler struct {
+	controller.Reconciler
+	sharder *sharder
+	ctx     context.Context
+	once    sync.Once
+}
+
+var _ reconciler.LeaderAware = (*shardedReconciler)(nil)
+
+// Promote implements reconciler.LeaderAware
+func (r *shardedReconciler) Promote(_ reconciler.Bucket, enq func(reconciler.Bucket, types.NamespacedName)) error {
+	r.once.Do(func() {
+		go r.sharder.run(r.ctx, enq)
+	})
+	return nil
+}
+
+// Demote implements reconciler.LeaderAware
+func (r *shardedReconciler) Demote(reconciler.Bucket) {}
+
+// sharder claims its fair share of the buckets through their leases, the
+// number of buckets divided by the number of replicas alive, rounded up.
+// It tells it is alive through a lease of its own, renewed while running.
+type sharder struct {
+	logger    *zap.SugaredLogger
+	kc        kubernetes.Interface
+	namespace string
+	id        string
+	// member is the name of the lease telling the replica is alive, named
+	// after its pod for a restarted replica to renew it.
+	member  string
+	cc      kle.ComponentConfig
+	buckets []reconciler.Bucket
+	la      reconciler.LeaderAware
+
+	mu  sync.Mutex
+	enq func(reconciler.Bucket, types.NamespacedName)
+	// terms are the elections of the buckets being run, leading or not.
+	terms map[string]*term // key: bucket name
+	// cooling holds when the buckets given back can be claimed again, to
+	// let the other replicas claim them first.
+	cooling map[string]time.Time // key: bucket name
+}
+
+// term is an election of a bucket, until cancelled or its lease is lost.
+type term struct {
+	cancel  context.CancelFunc
+	leading bool
+	stopped bool
+}
+
+// newSharder returns the sharder of the buckets of queueName, using the
+// leases of the namespace.
+func newSharder(logger *zap.SugaredLogger, kc kubernetes.Interface, namespace, queueName string, cc kle.ComponentConfig, la reconciler.LeaderAware) (*sharder, error) {
+	id := cc.Identity
+	if id == "" {
+		uid, err := kle.UniqueID()
+		if err != nil {
+			return nil, err
+		}
+		id = uid
+	}
+	if cc.Buckets == 0 {
+		cc.Buckets = 1
+	}
+	if cc.LeaseDuration <= 0 {
+		cc.LeaseDuration = defaultShardLeaseDuration
+	}
+	if cc.RenewDeadline <= 0 {
+		cc.RenewDeadline = defaultShardRenewDeadline
+	}
+	if cc.RetryPeriod <= 0 {
+		cc.RetryPeriod = defaultShardRetryPeriod
+	}
+	pod := os.Getenv("POD_NAME")
+	if pod == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		pod = hostname
+	}
+
+	// Named like the buckets of knative.dev/pkg, the replicas being able to
+	// run with either.
+	names := make(sets.String, cc.Buckets)
+	for i := uint32(0); i < cc.Buckets; i++ {
+		names.Insert(strings.ToLower(fmt.Sprintf("%s.%s.%02d-of-%02d", cc.Component, queueName, i, cc.Buckets)))
+	}
+
+	return &sharder{
+		logger:    logger,
+		kc:        kc,
+		namespace: namespace,
+		id:        id,
+		member:    strings.ToLower(fmt.Sprintf("%s.member.%s", cc.Component, pod)),
+		cc:        cc,
+		buckets:   hash.NewBucketSet(names).Buckets(),
+		la:        la,
+		terms:     make(map[string]*term),
+		cooling:   make(map[string]time.Time),
+	}, nil
+}
+
+// run claims the buckets until ctx is done, giving them all back then.
+func (s *sharder) run(ctx context.Context, enq func(reconciler.Bucket, types.NamespacedName)) {
+	s.mu.Lock()
+	s.enq = enq
+	s.mu.Unlock()
+
+	member := s.member
+	defer func() {
+		if err := s.kc.CoordinationV1().Leases(s.namespace).Delete(context.Background(), member, metav1.DeleteOptions{}); err != nil {
+			s.logger.Warnw("failed to delete the member lease", zap.String("lease", member), zap.Error(err))
+		}
+	}()
+
+	ticker := time.NewTicker(s.cc.RetryPeriod)
+	defer ticker.Stop()
+	for {
+		if err := s.renewMember(ctx, member); err != nil {
+			s.logger.Warnw("failed to renew the member lease", zap.String("lease", member), zap.Error(err))
+		} else {
+			s.rebalance(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// renewMember tells the other replicas this one is alive.
+func (s *sharder) renewMember(ctx context.Context, name string) error {
+	leases := s.kc.CoordinationV1().Leases(s.namespace)
+	now := metav1.NowMicro()
+	lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		seconds := int32(s.cc.LeaseDuration / time.Second)
+		_, err = leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{shardMemberLabel: s.cc.Component},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &s.id,
+				LeaseDurationSeconds: &seconds,
+				RenewTime:            &now,
+			},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	// Held by the previous run of a restarted replica.
+	lease.Spec.HolderIdentity = &s.id
+	lease.Spec.RenewTime = &now
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// members returns the number of replicas sharding the buckets, the ones
+// having renewed their member lease within the lease duration. The leases of
+// the replicas gone without deleting theirs are deleted.
+func (s *sharder) members(ctx context.Context) (int, error) {
+	leases := s.kc.CoordinationV1().Leases(s.namespace)
+	list, err := leases.List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", shardMemberLabel, s.cc.Component),
+	})
+	if err != nil {
+		return 0, err
+	}
+	alive := 0
+	for _, lease := range list.Items {
+		if renewed := lease.Spec.RenewTime; renewed != nil && time.Since(renewed.Time) < s.cc.LeaseDuration {
+			alive++
+			continue
+		}
+		if lease.Name == s.member {
+			continue
+		}
+		// Not deleted when renewed in between.
+		if err := leases.Delete(ctx, lease.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+		}); err != nil && !apierrs.IsNotFound(err) && !apierrs.IsConflict(err) {
+			s.logger.Warnw("failed to delete the expired member lease", zap.String("lease", lease.Name), zap.Error(err))
+		}
+	}
+	return alive, nil
+}
+
+// rebalance gives back the buckets beyond the fair share of the replica,
+// or claims the buckets it lacks.
+func (s *sharder) rebalance(ctx context.Context) {
+	members, err := s.members(ctx)
+	if err != nil {
+		s.logger.Warnw("failed to list the members", zap.Error(err))
+		return
+	}
+	if members < 1 {
+		members = 1
+	}
+	share := (len(s.buckets) + members - 1) / members
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	leading := make([]string, 0, len(s.terms))
+	for name, t := range s.terms {
+		if t.leading {
+			leading = append(leading, name)
+		}
+	}
+	sort.Strings(leading)
+
+	if len(leading) > share {
+		for _, name := range leading[share:] {
+			s.logger.Infow("giving the bucket back", zap.String("bucket", name), zap.Int("share", share))
+			s.terms[name].cancel()
+			s.cooling[name] = time.Now().Add(s.cc.LeaseDuration)
+		}
+		return
+	}
+
+	for _, bkt := range s.buckets {
+		name := bkt.Name()
+		t, running := s.terms[name]
+		switch {
+		case len(leading) >= share:
+			// Stop claiming the buckets led by the other replicas.
+			if running && !t.leading {
+				t.cancel()
+			}
+		case !running && time.Now().After(s.cooling[name]):
+			if err := s.elect(ctx, bkt); err != nil {
+				s.logger.Warnw("failed to claim the bucket", zap.String("bucket", name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// elect runs an election of bkt until ctx is done, s.mu being held.
+func (s *sharder) elect(ctx context.Context, bkt reconciler.Bucket) error {
+	rl, err := resourcelock.New(resourcelock.LeasesResourceLock,
+		s.namespace,
+		bkt.Name(),
+		s.kc.CoreV1(),
+		s.kc.CoordinationV1(),
+		resourcelock.ResourceLockConfig{
+			Identity: s.id,
+		})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	t := &term{cancel: cancel}
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          rl,
+		LeaseDuration: s.cc.LeaseDuration,
+		RenewDeadline: s.cc.RenewDeadline,
+		RetryPeriod:   s.cc.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				s.promote(bkt, t)
+			},
+			// Run returning tells the term is over.
+			OnStoppedLeading: func() {},
+		},
+		ReleaseOnCancel: true,
+		Name:            rl.Identity(),
+	})
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	s.terms[bkt.Name()] = t
+	go func() {
+		le.Run(ctx)
+		s.demote(bkt, t)
+	}()
+	return nil
+}
+
+func (s *sharder) promote(bkt reconciler.Bucket, t *term) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	s.logger.Infow("started leading the bucket", zap.String("bucket", bkt.Name()))
+	t.leading = true
+	if err := s.la.Promote(bkt, s.enq); err != nil {
+		s.logger.Errorw("failed to promote the bucket", zap.String("bucket", bkt.Name()), zap.Error(err))
+	}
+}
+
+func (s *sharder) demote(bkt reconciler.Bucket, t *term) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.stopped = true
+	t.cancel()
+	if s.terms[bkt.Name()] == t {
+		delete(s.terms, bkt.Name())
+	}
+	if t.leading {
+		s.logger.Infow("stopped leading the bucket", zap.String("bucket", bkt.Name()))
+		s.la.Demote(bkt)
+	}
+}
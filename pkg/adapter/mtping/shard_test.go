@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+
+	kle "knative.dev/pkg/leaderelection"
+	"knative.dev/pkg/reconciler"
+)
+
+// bucketRecorder records the buckets it leads.
+type bucketRecorder struct {
+	mu  sync.Mutex
+	led sets.String
+}
+
+func (r *bucketRecorder) Promote(b reconciler.Bucket, _ func(reconciler.Bucket, types.NamespacedName)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.led.Insert(b.Name())
+	return nil
+}
+
+func (r *bucketRecorder) Demote(b reconciler.Bucket) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.led.Delete(b.Name())
+}
+
+func (r *bucketRecorder) buckets() sets.String {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return sets.NewString(r.led.UnsortedList()...)
+}
+
+func TestSharderRebalance(t *testing.T) {
+	kc := fakekubeclientset.NewSimpleClientset()
+	replica := func(id string) (*sharder, *bucketRecorder) {
+		recorder := &bucketRecorder{led: sets.NewString()}
+		s, err := newSharder(zap.NewNop().Sugar(), kc, "knative-testing", "pingsources", kle.ComponentConfig{
+			Component:     "pingsource-mt-adapter",
+			Buckets:       4,
+			LeaseDuration: 600 * time.Millisecond,
+			RenewDeadline: 400 * time.Millisecond,
+			RetryPeriod:   100 * time.Millisecond,
+			Identity:      id,
+		}, recorder)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		// The replicas run in the same pod.
+		s.member = "pingsource-mt-adapter.member." + id
+		return s, recorder
+	}
+	enq := func(reconciler.Bucket, types.NamespacedName) {}
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		if err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+			return cond(), nil
+		}); err != nil {
+			t.Fatal("Timed out waiting for", what)
+		}
+	}
+
+	first, firstBuckets := replica("first")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go first.run(ctx, enq)
+	waitFor("the first replica to claim all the buckets", func() bool {
+		return firstBuckets.buckets().Len() == 4
+	})
+
+	second, secondBuckets := replica("second")
+	secondCtx, secondCancel := context.WithCancel(context.Background())
+	defer secondCancel()
+	go second.run(secondCtx, enq)
+	waitFor("the buckets to be shared", func() bool {
+		return firstBuckets.buckets().Len() == 2 && secondBuckets.buckets().Len() == 2
+	})
+	if both := firstBuckets.buckets().Intersection(secondBuckets.buckets()); both.Len() != 0 {
+		t.Errorf("Expected the buckets to be led by one replica, got %v led by both", both.List())
+	}
+
+	secondCancel()
+	waitFor("the first replica to claim the buckets back", func() bool {
+		return firstBuckets.buckets().Len() == 4 && secondBuckets.buckets().Len() == 0
+	})
+}
+
+func TestSharderMemberLeases(t *testing.T) {
+	os.Setenv("POD_NAME", "pingsource-mt-adapter-6d8f9")
+	defer os.Unsetenv("POD_NAME")
+	ctx := context.Background()
+	expired := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	kc := fakekubeclientset.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-testing",
+			Name:      "pingsource-mt-adapter.member.gone",
+			Labels:    map[string]string{shardMemberLabel: "pingsource-mt-adapter"},
+		},
+		Spec: coordinationv1.LeaseSpec{RenewTime: &expired},
+	})
+
+	// The zero durations are defaulted, for the ticker not to panic.
+	s, err := newSharder(zap.NewNop().Sugar(), kc, "knative-testing", "pingsources", kle.ComponentConfig{
+		Component: "pingsource-mt-adapter",
+	}, &bucketRecorder{led: sets.NewString()})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.cc.LeaseDuration != defaultShardLeaseDuration || s.cc.RenewDeadline != defaultShardRenewDeadline || s.cc.RetryPeriod != defaultShardRetryPeriod {
+		t.Errorf("Expected the default durations, got %+v", s.cc)
+	}
+	if want := "pingsource-mt-adapter.member.pingsource-mt-adapter-6d8f9"; s.member != want {
+		t.Errorf("Expected the member lease to be named %q, got %q", want, s.member)
+	}
+
+	if err := s.renewMember(ctx, s.member); err != nil {
+		t.Fatal("Failed to renew the member lease:", err)
+	}
+	members, err := s.members(ctx)
+	if err != nil {
+		t.Fatal("Failed to count the members:", err)
+	}
+	if members != 1 {
+		t.Errorf("Expected 1 member alive, got %d", members)
+	}
+	if _, err := kc.CoordinationV1().Leases("knative-testing").Get(ctx, "pingsource-mt-adapter.member.gone", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the expired member lease to be deleted, got %v", err)
+	}
+	if _, err := kc.CoordinationV1().Leases("knative-testing").Get(ctx, s.member, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the member lease to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+	sourceslisters "knative.dev/eventing/pkg/client/listers/sources/v1beta1"
+)
+
+// readSinkCACerts reads the CA certificates referenced by the SinkCACerts of
+// the given source. It returns an empty string when the reference is
+// optional and the Secret, the ConfigMap or its key is missing, the system
+// CAs being used then.
+func readSinkCACerts(ctx context.Context, kubeClient kubernetes.Interface, source *sourcesv1beta1.PingSource) (string, error) {
+	if ref := source.Spec.SinkCACerts.SecretKeyRef; ref != nil {
+		optional := ref.Optional != nil && *ref.Optional
+		secret, err := kubeClient.CoreV1().Secrets(source.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && optional {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		data, ok := secret.Data[ref.Key]
+		if !ok && !optional {
+			return "", fmt.Errorf("secret %s/%s has no %s key", source.Namespace, ref.Name, ref.Key)
+		}
+		return string(data), nil
+	}
+
+	ref := source.Spec.SinkCACerts.ConfigMapKeyRef
+	optional := ref.Optional != nil && *ref.Optional
+	cm, err := kubeClient.CoreV1().ConfigMaps(source.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) && optional {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	data, ok := cm.Data[ref.Key]
+	if !ok && !optional {
+		return "", fmt.Errorf("configmap %s/%s has no %s key", source.Namespace, ref.Name, ref.Key)
+	}
+	return data, nil
+}
+
+// referencesSinkCACerts returns whether the SinkCACerts of the given source
+// reference the Secret, or the ConfigMap when not secret, of the given name.
+func referencesSinkCACerts(source *sourcesv1beta1.PingSource, secret bool, name string) bool {
+	ca := source.Spec.SinkCACerts
+	if ca == nil {
+		return false
+	}
+	if secret {
+		return ca.SecretKeyRef != nil && ca.SecretKeyRef.Name == name
+	}
+	return ca.ConfigMapKeyRef != nil && ca.ConfigMapKeyRef.Name == name
+}
+
+// reloadingAdapter is implemented by the adapters ignoring the identical
+// updates of a source, for the updates following a change of its CA
+// certificates not to be ignored.
+type reloadingAdapter interface {
+	// reload makes the next update of the source reschedule it.
+	reload(namespace, name string)
+}
+
+// sinkCACertsPollInterval is the interval the Secrets holding sink CA
+// certificates are read at, the adapter not being allowed to watch them.
+const sinkCACertsPollInterval = time.Minute
+
+// watchSinkCACerts enqueues the sources whose SinkCACerts reference a Secret
+// or a ConfigMap each time it changes, for their transport to be rebuilt
+// with the new certificates. The ConfigMaps are watched, while the Secrets
+// referenced by the sources are read each interval, the adapter only being
+// allowed to get them.
+func watchSinkCACerts(ctx context.Context, kubeClient kubernetes.Interface, mtadapter MTAdapter, lister sourceslisters.PingSourceLister,
+	enqueue func(interface{}), interval time.Duration) {
+	reschedule := rescheduler(mtadapter, enqueue)
+	changed := func(obj interface{}) {
+		object, err := kmeta.DeletionHandlingAccessor(obj)
+		if err != nil {
+			return
+		}
+		sources, err := lister.PingSources(object.GetNamespace()).List(labels.Everything())
+		if err != nil {
+			logging.FromContext(ctx).Errorw("failed to list the sources of the changed CA certificates", zap.Error(err))
+			return
+		}
+		for _, source := range sources {
+			if referencesSinkCACerts(source, false, object.GetName()) {
+				reschedule(source)
+			}
+		}
+	}
+
+	factory := kubeinformers.NewSharedInformerFactory(kubeClient, controller.GetResyncPeriod(ctx))
+	factory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: changed,
+		UpdateFunc: func(old, new interface{}) {
+			// The resyncs don't change the certificates.
+			if old.(metav1.Object).GetResourceVersion() != new.(metav1.Object).GetResourceVersion() {
+				changed(new)
+			}
+		},
+		DeleteFunc: changed,
+	})
+	factory.Start(ctx.Done())
+
+	poller := newSecretPoller(kubeClient, lister, reschedule)
+	go wait.UntilWithContext(ctx, poller.poll, interval)
+}
+
+// rescheduler returns a function reloading the certificates of a source
+// before enqueuing it.
+func rescheduler(mtadapter MTAdapter, enqueue func(interface{})) func(source *sourcesv1beta1.PingSource) {
+	return func(source *sourcesv1beta1.PingSource) {
+		if ra, ok := mtadapter.(reloadingAdapter); ok {
+			ra.reload(source.Namespace, source.Name)
+		}
+		enqueue(source)
+	}
+}
+
+// secretPoller reads the Secrets referenced by the SinkCACerts of the
+// sources, rescheduling the sources of the changed ones.
+type secretPoller struct {
+	kubeClient kubernetes.Interface
+	lister     sourceslisters.PingSourceLister
+	reschedule func(source *sourcesv1beta1.PingSource)
+
+	// versions holds the resource version of each Secret read, empty when
+	// missing.
+	versions map[types.NamespacedName]string
+}
+
+func newSecretPoller(kubeClient kubernetes.Interface, lister sourceslisters.PingSourceLister, reschedule func(source *sourcesv1beta1.PingSource)) *secretPoller {
+	return &secretPoller{
+		kubeClient: kubeClient,
+		lister:     lister,
+		reschedule: reschedule,
+		versions:   make(map[types.NamespacedName]string),
+	}
+}
+
+// poll reads the referenced Secrets once, rescheduling the sources of the
+// ones changed since the previous poll. The Secrets read for the first time
+// were read when their sources were scheduled.
+func (p *secretPoller) poll(ctx context.Context) {
+	all, err := p.lister.List(labels.Everything())
+	if err != nil {
+		logging.FromContext(ctx).Errorw("failed to list the sources referencing CA certificates", zap.Error(err))
+		return
+	}
+	referencing := make(map[types.NamespacedName][]*sourcesv1beta1.PingSource)
+	for _, source := range all {
+		if ca := source.Spec.SinkCACerts; ca != nil && ca.SecretKeyRef != nil {
+			key := types.NamespacedName{Namespace: source.Namespace, Name: ca.SecretKeyRef.Name}
+			referencing[key] = append(referencing[key], source)
+		}
+	}
+
+	versions := make(map[types.NamespacedName]string, len(referencing))
+	for key, sources := range referencing {
+		secret, err := p.kubeClient.CoreV1().Secrets(key.Namespace).Get(ctx, key.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			versions[key] = ""
+		case err != nil:
+			logging.FromContext(ctx).Warnw("failed to read the secret of the CA certificates", zap.Any("secret", key), zap.Error(err))
+			// Compared again on the next poll.
+			if version, ok := p.versions[key]; ok {
+				versions[key] = version
+			}
+			continue
+		default:
+			versions[key] = secret.ResourceVersion
+		}
+		if previous, ok := p.versions[key]; ok && previous != versions[key] {
+			for _, source := range sources {
+				p.reschedule(source)
+			}
+		}
+	}
+	// The Secrets not referenced anymore are forgotten.
+	p.versions = versions
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"encoding/pem"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+	fakepingsourceinformer "knative.dev/eventing/pkg/client/injection/informers/sources/v1beta1/pingsource/fake"
+)
+
+func TestSinkCACertsRotation(t *testing.T) {
+	var received int32
+	sink := httptest.NewTLSServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer sink.Close()
+	sinkCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: sink.Certificate().Raw}))
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	secrets := kubeclient.Get(ctx).CoreV1().Secrets("test-ns")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "sink-ca"},
+		Data:       map[string][]byte{"ca.crt": []byte(newTestCA(t))},
+	}
+	if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create the secret:", err)
+	}
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	sinkURI, _ := apis.ParseURL(sink.URL)
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		// Not to retry the sends to the untrusted sink.
+		s.Spec.Delivery = &eventingduckv1.DeliverySpec{Retry: ptr.Int32(0)}
+		s.Spec.SinkCACerts = &sourcesv1beta1.PingSourceSinkCACerts{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "sink-ca"},
+				Key:                  "ca.crt",
+			},
+		}
+		s.Status.SinkURI = sinkURI
+	})
+	entryID := mustAddSchedule(t, runner, source)
+	runner.cron.Entry(entryID).Job.Run()
+	if got := atomic.LoadInt32(&received); got != 0 {
+		t.Fatalf("Expected the sink not to be trusted, got %d events", got)
+	}
+
+	// The rotated CA is used once the source is rescheduled.
+	secret.Data["ca.crt"] = []byte(sinkCA)
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Failed to update the secret:", err)
+	}
+	if err := runner.RemoveSchedule(entryID); err != nil {
+		t.Fatal("Failed to remove the schedule:", err)
+	}
+	entryID = mustAddSchedule(t, runner, source)
+	runner.cron.Entry(entryID).Job.Run()
+	if got := atomic.LoadInt32(&received); got != 1 {
+		t.Errorf("Expected the sink to receive 1 event, got %d", got)
+	}
+}
+
+func TestSinkCACertsMissing(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	sinkURI, _ := apis.ParseURL("https://sink.example.com")
+	source := func(optional bool) *sourcesv1beta1.PingSource {
+		return newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Status.SinkURI = sinkURI
+			s.Spec.SinkCACerts = &sourcesv1beta1.PingSourceSinkCACerts{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "trust-bundle"},
+					Key:                  "ca.crt",
+					Optional:             ptr.Bool(optional),
+				},
+			}
+		})
+	}
+
+	if _, err := runner.AddSchedule(source(false)); err == nil {
+		t.Error("Expected an error for a missing trust bundle")
+	}
+	if _, err := runner.AddSchedule(source(true)); err != nil {
+		t.Error("Expected the system CAs to be used for a missing optional trust bundle, got", err)
+	}
+}
+
+func TestPollSinkCACertsSecrets(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sources := fakepingsourceinformer.Get(ctx)
+	for name, ca := range map[string]*sourcesv1beta1.PingSourceSinkCACerts{
+		"from-secret": {SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "sink-ca"},
+			Key:                  "ca.crt",
+		}},
+		"from-configmap": {ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "sink-ca"},
+			Key:                  "ca.crt",
+		}},
+		"without": nil,
+	} {
+		sources.Informer().GetIndexer().Add(newTestSource(func(s *sourcesv1beta1.PingSource) {
+			s.Name = name
+			s.Spec.SinkCACerts = ca
+		}))
+	}
+
+	enqueued := sets.NewString()
+	adapter := &reloadAdapter{reloaded: sets.NewString()}
+	poller := newSecretPoller(kubeclient.Get(ctx), sources.Lister(), rescheduler(adapter, func(obj interface{}) {
+		enqueued.Insert(obj.(*sourcesv1beta1.PingSource).Name)
+	}))
+
+	poller.poll(ctx)
+	if enqueued.Len() != 0 {
+		t.Errorf("Expected no source to be enqueued on the first poll, got %v", enqueued.List())
+	}
+
+	secrets := kubeclient.Get(ctx).CoreV1().Secrets("test-ns")
+	secret, err := secrets.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "sink-ca", ResourceVersion: "1"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal("Failed to create the secret:", err)
+	}
+	poller.poll(ctx)
+	if !enqueued.Equal(sets.NewString("from-secret")) {
+		t.Errorf("Expected only the source of the secret to be enqueued, got %v", enqueued.List())
+	}
+	if !adapter.reloaded.Equal(enqueued) {
+		t.Errorf("Expected the enqueued sources to be reloaded, got %v", adapter.reloaded.List())
+	}
+
+	enqueued.Delete("from-secret")
+	poller.poll(ctx)
+	if enqueued.Len() != 0 {
+		t.Errorf("Expected no source to be enqueued for an unchanged secret, got %v", enqueued.List())
+	}
+
+	secret.ResourceVersion = "2"
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Failed to update the secret:", err)
+	}
+	poller.poll(ctx)
+	if !enqueued.Equal(sets.NewString("from-secret")) {
+		t.Errorf("Expected the source of the updated secret to be enqueued, got %v", enqueued.List())
+	}
+}
+
+// reloadAdapter records the sources it reloads.
+type reloadAdapter struct {
+	testAdapter
+	reloaded sets.String
+}
+
+func (a *reloadAdapter) reload(namespace, name string) {
+	a.reloaded.Insert(name)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// chunkIDExtension is the ID of the event a chunk is part of.
+	chunkIDExtension = "chunkid"
+	// chunkIndexExtension is the position of a chunk, starting at 0.
+	chunkIndexExtension = "chunkindex"
+	// chunkCountExtension is the number of chunks of the event.
+	chunkCountExtension = "chunkcount"
+	// chunkContentTypeExtension is the content type of the data of the
+	// event, the chunks being sent as bytes.
+	chunkContentTypeExtension = "chunkcontenttype"
+
+	// chunkContentType is the content type of the chunks, their data not
+	// being valid in the content type of the event, such as JSON split in
+	// the middle of a value.
+	chunkContentType = "application/octet-stream"
+)
+
+// splitEvent splits the data of event in chunks of at most size bytes, each
+// sent as an event with the attributes of the original one, but for their
+// content type.
+func splitEvent(event cloudevents.Event, size int) []cloudevents.Event {
+	data := event.Data()
+	count := (len(data) + size - 1) / size
+
+	chunks := make([]cloudevents.Event, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(data) {
+			end = len(data)
+		}
+
+		chunk := event.Clone()
+		chunk.SetID(fmt.Sprintf("%s-%d", event.ID(), i))
+		chunk.DataEncoded = data[i*size : end]
+		chunk.SetDataContentType(chunkContentType)
+		if contentType := event.DataContentType(); contentType != "" {
+			chunk.SetExtension(chunkContentTypeExtension, contentType)
+		}
+		chunk.SetExtension(chunkIDExtension, event.ID())
+		chunk.SetExtension(chunkIndexExtension, i)
+		chunk.SetExtension(chunkCountExtension, count)
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// fitEvent returns the events to send for event, split in chunks when it
+// exceeds the size limit of the sink and the source splits the oversized
+// events. It returns false when event is skipped.
+func (a *cronJobsRunner) fitEvent(opts jobOptions, event cloudevents.Event) ([]cloudevents.Event, bool) {
+	if opts.maxEventSize <= 0 || len(event.Data()) <= opts.maxEventSize {
+		return []cloudevents.Event{event}, true
+	}
+	if opts.oversizePolicy != OversizeSplit {
+		atomic.AddUint64(&opts.stats.failed, 1)
+		if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+			a.Logger.Errorw("cloudevent exceeds the sink size limit, skipping it", zap.String("source", event.Source()),
+				zap.String("id", event.ID()), zap.Int("size", len(event.Data())), zap.Int("suppressed", suppressed))
+		}
+		return nil, false
+	}
+	return splitEvent(event, opts.maxEventSize), true
+}
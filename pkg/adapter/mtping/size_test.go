@@ -0,0 +1,225 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+const sinkMaxSize = 16
+
+// sizeLimitedSink records the bodies of the requests it receives, and
+// rejects the ones larger than sinkMaxSize.
+type sizeLimitedSink struct {
+	mu       sync.Mutex
+	bodies   []string
+	chunks   []int
+	rejected int
+}
+
+func (s *sizeLimitedSink) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(body) > sinkMaxSize {
+		s.rejected++
+		w.WriteHeader(nethttp.StatusRequestEntityTooLarge)
+		return
+	}
+	s.bodies = append(s.bodies, string(body))
+	index, _ := strconv.Atoi(r.Header.Get("Ce-" + chunkIndexExtension))
+	s.chunks = append(s.chunks, index)
+	w.WriteHeader(nethttp.StatusAccepted)
+}
+
+func TestOversizePolicies(t *testing.T) {
+	const data = "some data exceeding the sink limit"
+	want := `{"body":"` + data + `"}`
+
+	for _, policy := range []OversizePolicy{OversizeSkip, OversizeSplit} {
+		t.Run(string(policy), func(t *testing.T) {
+			sink := &sizeLimitedSink{}
+			server := httptest.NewServer(sink)
+			defer server.Close()
+
+			ctx, _ := rectesting.SetupFakeContext(t)
+			client, err := newClient(nethttp.DefaultTransport)
+			if err != nil {
+				t.Fatal("Failed to create client:", err)
+			}
+			runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			sinkURI, _ := apis.ParseURL(server.URL)
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.JsonData = data
+				s.Status.SinkURI = sinkURI
+				s.Annotations = map[string]string{
+					MaxEventSizeAnnotation:   strconv.Itoa(sinkMaxSize),
+					OversizePolicyAnnotation: string(policy),
+				}
+			}))
+			runner.cron.Entry(entryID).Job.Run()
+
+			sink.mu.Lock()
+			defer sink.mu.Unlock()
+			if sink.rejected != 0 {
+				t.Error("Expected no oversized request, got", sink.rejected)
+			}
+			stats := runner.Stats().Sources["test-ns/test-name"]
+
+			switch policy {
+			case OversizeSkip:
+				if len(sink.bodies) != 0 {
+					t.Error("Expected the oversized event to be skipped, got", sink.bodies)
+				}
+				if stats.Failed != 1 {
+					t.Error("Expected the skipped event to be counted as failed, got", stats.Failed)
+				}
+			case OversizeSplit:
+				wantChunks := (len(want) + sinkMaxSize - 1) / sinkMaxSize
+				if len(sink.bodies) != wantChunks {
+					t.Fatalf("Expected %d chunks, got %d", wantChunks, len(sink.bodies))
+				}
+				for i, index := range sink.chunks {
+					if index != i {
+						t.Errorf("Expected chunk %d to have index %d, got %d", i, i, index)
+					}
+				}
+				if got := strings.Join(sink.bodies, ""); got != want {
+					t.Errorf("Expected the chunks to make %q, got %q", want, got)
+				}
+				if stats.Sent != uint64(wantChunks) || stats.LastFireSent != uint64(wantChunks) {
+					t.Errorf("Expected each of the %d chunks to be counted as sent, got %+v", wantChunks, stats)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchSpread(t *testing.T) {
+	for _, pause := range []bool{false, true} {
+		t.Run("pause="+strconv.FormatBool(pause), func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+			fakeClock := clock.NewFakeClock(time.Now())
+			runner.clock = fakeClock
+
+			source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.JsonData = `{"message":"` + strings.Repeat("x", 3*sinkMaxSize) + `"}`
+				s.Annotations = map[string]string{
+					MaxEventSizeAnnotation:   strconv.Itoa(sinkMaxSize),
+					OversizePolicyAnnotation: string(OversizeSplit),
+				}
+				s.Spec.BatchSpread = &metav1.Duration{Duration: 4 * time.Second}
+			})
+			chunks := len(splitEvent(mustMakeEvent(t, source), sinkMaxSize))
+			if chunks != 4 {
+				t.Fatal("Expected the event to be split in 4 chunks, got", chunks)
+			}
+			entryId := mustAddSchedule(t, runner, source)
+
+			done := make(chan struct{})
+			go func() {
+				runner.cron.Entry(entryId).Job.Run()
+				close(done)
+			}()
+
+			// A chunk is sent every second.
+			for sent := 1; sent < chunks; sent++ {
+				if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+					return fakeClock.HasWaiters(), nil
+				}); err != nil {
+					t.Fatal("Expected the job to wait before sending the next chunk:", err)
+				}
+				if got := len(ce.Sent()); got != sent {
+					t.Fatalf("Expected %d chunks to be sent, got %d", sent, got)
+				}
+				if pause {
+					// The remaining chunks are sent at once.
+					runner.PauseAll()
+					break
+				}
+				fakeClock.Step(time.Second)
+			}
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected the job to be done")
+			}
+			if got := len(ce.Sent()); got != chunks {
+				t.Errorf("Expected %d chunks to be sent, got %d", chunks, got)
+			}
+		})
+	}
+}
+
+func TestSplitEventStructured(t *testing.T) {
+	event := cloudevents.NewEvent()
+	event.SetID("id")
+	event.SetType("dev.knative.sources.ping")
+	event.SetSource("/test")
+	if err := event.SetData(cloudevents.ApplicationJSON, map[string]string{"message": "some data exceeding the sink limit"}); err != nil {
+		t.Fatal("Failed to set the data:", err)
+	}
+
+	var data []byte
+	for _, chunk := range splitEvent(event, sinkMaxSize) {
+		// The chunks of JSON data aren't valid JSON.
+		encoded, err := format.JSON.Marshal(&chunk)
+		if err != nil {
+			t.Fatal("Failed to encode the chunk in structured mode:", err)
+		}
+		var decoded cloudevents.Event
+		if err := format.JSON.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatalf("Failed to decode the chunk %s: %v", encoded, err)
+		}
+		if got := decoded.DataContentType(); got != chunkContentType {
+			t.Errorf("Expected the chunk content type %q, got %q", chunkContentType, got)
+		}
+		if got := decoded.Extensions()[chunkContentTypeExtension]; got != cloudevents.ApplicationJSON {
+			t.Errorf("Expected the content type of the event in the %s extension, got %v", chunkContentTypeExtension, got)
+		}
+		data = append(data, decoded.Data()...)
+	}
+	if got, want := string(data), string(event.Data()); got != want {
+		t.Errorf("Expected the chunks to make up %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// tickTracker follows the scheduled times of the fires of a source, for
+// their skew to be measured.
+type tickTracker struct {
+	schedule cron.Schedule
+
+	mu sync.Mutex
+	// prev is the scheduled time of the current fire, next the one of the
+	// following fire.
+	prev, next time.Time
+}
+
+func newTickTracker(schedule cron.Schedule, now time.Time) *tickTracker {
+	return &tickTracker{schedule: schedule, next: schedule.Next(now)}
+}
+
+// observe returns how late the fire at the given time is compared to its
+// scheduled time, the last tick before it, false for the fires before the
+// first tick. The fires sending several events observe the same time more
+// than once, for the same skew.
+func (t *tickTracker) observe(fired time.Time) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// The ticks missed meanwhile are skipped, the fire being late from the
+	// last one.
+	for !t.next.IsZero() && !fired.Before(t.next) {
+		t.prev = t.next
+		t.next = t.schedule.Next(t.next)
+	}
+	if t.prev.IsZero() {
+		return 0, false
+	}
+	return fired.Sub(t.prev), true
+}
+
+// scheduledTick returns the scheduled time of the fire at fired, reporting
+// its skew, or fired when not known.
+func (a *cronJobsRunner) scheduledTick(opts jobOptions, fired time.Time) time.Time {
+	if opts.ticks == nil {
+		return fired
+	}
+	skew, ok := opts.ticks.observe(fired)
+	if !ok {
+		return fired
+	}
+	a.reporter.ReportScheduleSkew(opts.stats.namespace, opts.stats.name, skew)
+	return fired.Add(-skew)
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestTickTracker(t *testing.T) {
+	schedule, err := cron.ParseStandard("* * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 30, 0, time.UTC)
+	tracker := newTickTracker(schedule, start)
+
+	if got, ok := tracker.observe(start.Add(time.Second)); ok {
+		t.Errorf("observe() before the first tick = %v, want none", got)
+	}
+	if got, _ := tracker.observe(start.Add(30*time.Second + 200*time.Millisecond)); got != 200*time.Millisecond {
+		t.Errorf("observe() = %v, want 200ms", got)
+	}
+	// The events of the same fire observe the same skew.
+	if got, _ := tracker.observe(start.Add(30*time.Second + 300*time.Millisecond)); got != 300*time.Millisecond {
+		t.Errorf("observe() = %v, want 300ms", got)
+	}
+	// A fire delayed past the next tick is late from that tick.
+	if got, _ := tracker.observe(start.Add(100 * time.Second)); got != 10*time.Second {
+		t.Errorf("observe() = %v, want 10s", got)
+	}
+}
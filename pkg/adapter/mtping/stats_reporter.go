@@ -0,0 +1,241 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"log"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/source"
+)
+
+var (
+	// eventsSentM is a counter which records the number of events sent by
+	// a PingSource.
+	eventsSentM = stats.Int64(
+		"ping_events_sent_total",
+		"Number of events sent by a PingSource",
+		stats.UnitDimensionless,
+	)
+
+	// eventsFailedM is a counter which records the number of events a
+	// PingSource failed to send.
+	eventsFailedM = stats.Int64(
+		"ping_events_failed_total",
+		"Number of events a PingSource failed to send",
+		stats.UnitDimensionless,
+	)
+
+	// eventRetriesM is a counter which records the number of retries of
+	// the events sent by a PingSource.
+	eventRetriesM = stats.Int64(
+		"ping_event_retries_total",
+		"Number of retries of the events sent by a PingSource",
+		stats.UnitDimensionless,
+	)
+
+	// eventsDeadLetteredM is a counter which records the number of events
+	// a PingSource sent to its dead letter sink.
+	eventsDeadLetteredM = stats.Int64(
+		"ping_events_dead_lettered_total",
+		"Number of events a PingSource sent to its dead letter sink",
+		stats.UnitDimensionless,
+	)
+
+	// sendLatencyInMsecM records the time spent sending an event to the
+	// sink of a PingSource, in milliseconds.
+	sendLatencyInMsecM = stats.Float64(
+		"ping_event_send_latency",
+		"The time spent sending an event to the sink of a PingSource",
+		stats.UnitMilliseconds,
+	)
+
+	// dispatchTimeInMsecM records the time spent dispatching an event of a
+	// PingSource, in milliseconds, by response code.
+	dispatchTimeInMsecM = stats.Float64(
+		"event_dispatch_latencies",
+		"The time spent dispatching an event from a PingSource",
+		stats.UnitMilliseconds,
+	)
+
+	// scheduleSkewInMsecM records how late the fires of a PingSource are
+	// compared to their scheduled times, in milliseconds.
+	scheduleSkewInMsecM = stats.Float64(
+		"ping_schedule_skew",
+		"The time between the scheduled time of a PingSource fire and the actual one",
+		stats.UnitMilliseconds,
+	)
+
+	responseCodeKey      = tag.MustNewKey(metricskey.LabelResponseCode)
+	responseCodeClassKey = tag.MustNewKey(metricskey.LabelResponseCodeClass)
+)
+
+func init() {
+	registerViews()
+}
+
+// StatsReporter defines the interface for sending the PingSource metrics.
+type StatsReporter interface {
+	ReportEventSent(namespace, name string)
+	ReportEventFailed(namespace, name string)
+	ReportSendLatency(namespace, name string, d time.Duration)
+	ReportEventRetries(namespace, name string, retries int)
+	ReportEventDeadLettered(namespace, name string)
+	// ReportEventCount counts an event sent by the source in the
+	// event_count of the sources, for the clients not counting their
+	// events. responseCode is 0 when the sink didn't respond.
+	ReportEventCount(namespace, name string, event cloudevents.Event, responseCode int)
+	ReportDispatchLatency(namespace, name string, responseCode int, d time.Duration)
+	ReportScheduleSkew(namespace, name string, d time.Duration)
+}
+
+var _ StatsReporter = (*reporter)(nil)
+
+// reporter reports the PingSource metrics to the metrics exporter.
+type reporter struct {
+	// source reports the event_count shared by the sources.
+	source source.StatsReporter
+}
+
+// NewStatsReporter creates a reporter that collects and reports the
+// PingSource metrics.
+func NewStatsReporter() StatsReporter {
+	// Creating the source reporter never fails, it only creates an empty
+	// tag map.
+	sr, _ := source.NewStatsReporter()
+	return &reporter{source: sr}
+}
+
+func registerViews() {
+	err := metrics.RegisterResourceView(
+		&view.View{
+			Description: eventsSentM.Description(),
+			Measure:     eventsSentM,
+			Aggregation: view.Count(),
+		},
+		&view.View{
+			Description: eventsFailedM.Description(),
+			Measure:     eventsFailedM,
+			Aggregation: view.Count(),
+		},
+		&view.View{
+			Description: eventRetriesM.Description(),
+			Measure:     eventRetriesM,
+			Aggregation: view.Sum(),
+		},
+		&view.View{
+			Description: eventsDeadLetteredM.Description(),
+			Measure:     eventsDeadLetteredM,
+			Aggregation: view.Count(),
+		},
+		&view.View{
+			Description: sendLatencyInMsecM.Description(),
+			Measure:     sendLatencyInMsecM,
+			Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...), // 1, 2, 5, 10, 20, 50, 100, 500, 1000, 5000, 10000
+		},
+		&view.View{
+			Description: dispatchTimeInMsecM.Description(),
+			Measure:     dispatchTimeInMsecM,
+			Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...),
+			TagKeys:     []tag.Key{responseCodeKey, responseCodeClassKey},
+		},
+		&view.View{
+			Description: scheduleSkewInMsecM.Description(),
+			Measure:     scheduleSkewInMsecM,
+			Aggregation: view.Distribution(metrics.Buckets125(1, 60000)...), // 1, 2, 5, ..., 20000, 50000, 60000
+		},
+	)
+	if err != nil {
+		log.Printf("failed to register opencensus views, %s", err)
+	}
+}
+
+// ReportEventSent counts an event sent by the source.
+func (r *reporter) ReportEventSent(namespace, name string) {
+	metrics.Record(sourceContext(namespace, name), eventsSentM.M(1))
+}
+
+// ReportEventFailed counts an event the source failed to send.
+func (r *reporter) ReportEventFailed(namespace, name string) {
+	metrics.Record(sourceContext(namespace, name), eventsFailedM.M(1))
+}
+
+// ReportEventRetries counts the retries of an event of the source.
+func (r *reporter) ReportEventRetries(namespace, name string, retries int) {
+	metrics.Record(sourceContext(namespace, name), eventRetriesM.M(int64(retries)))
+}
+
+// ReportEventDeadLettered counts an event the source sent to its dead
+// letter sink.
+func (r *reporter) ReportEventDeadLettered(namespace, name string) {
+	metrics.Record(sourceContext(namespace, name), eventsDeadLetteredM.M(1))
+}
+
+// ReportSendLatency captures the time spent sending an event of the source.
+func (r *reporter) ReportSendLatency(namespace, name string, d time.Duration) {
+	// convert time.Duration in nanoseconds to milliseconds.
+	metrics.Record(sourceContext(namespace, name), sendLatencyInMsecM.M(float64(d/time.Millisecond)))
+}
+
+// ReportEventCount counts an event of the source by response code.
+func (r *reporter) ReportEventCount(namespace, name string, event cloudevents.Event, responseCode int) {
+	_ = r.source.ReportEventCount(&source.ReportArgs{
+		Namespace:     namespace,
+		EventType:     event.Type(),
+		EventSource:   event.Source(),
+		Name:          name,
+		ResourceGroup: resourceGroup,
+	}, responseCode)
+}
+
+// ReportDispatchLatency captures the time spent dispatching an event of the
+// source by response code.
+func (r *reporter) ReportDispatchLatency(namespace, name string, responseCode int, d time.Duration) {
+	ctx, err := tag.New(sourceContext(namespace, name),
+		metrics.MaybeInsertIntTag(responseCodeKey, responseCode, responseCode > 0),
+		metrics.MaybeInsertStringTag(responseCodeClassKey, metrics.ResponseCodeClass(responseCode), responseCode > 0))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, dispatchTimeInMsecM.M(float64(d/time.Millisecond)))
+}
+
+// ReportScheduleSkew captures how late a fire of the source was.
+func (r *reporter) ReportScheduleSkew(namespace, name string, d time.Duration) {
+	metrics.Record(sourceContext(namespace, name), scheduleSkewInMsecM.M(float64(d/time.Millisecond)))
+}
+
+// sourceContext returns a context recording the metrics on the resource of
+// the source.
+func sourceContext(namespace, name string) context.Context {
+	return metricskey.WithResource(context.Background(), resource.Resource{
+		Type: metricskey.ResourceTypeKnativeSource,
+		Labels: map[string]string{
+			metricskey.LabelNamespaceName: namespace,
+			metricskey.LabelName:          name,
+			metricskey.LabelResourceGroup: resourceGroup,
+		},
+	})
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.opencensus.io/resource"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestStatsReporter(t *testing.T) {
+	// OpenCensus metrics carry global state that need to be reset between unit tests.
+	metricstest.Unregister("ping_events_sent_total", "ping_events_failed_total", "ping_event_retries_total",
+		"ping_events_dead_lettered_total", "ping_event_send_latency", "event_dispatch_latencies", "ping_schedule_skew")
+	registerViews()
+
+	r := NewStatsReporter()
+	r.ReportEventSent("reporter-ns", "reporter-name")
+	r.ReportEventSent("reporter-ns", "reporter-name")
+	r.ReportEventFailed("reporter-ns", "reporter-name")
+	r.ReportSendLatency("reporter-ns", "reporter-name", 1100*time.Millisecond)
+	r.ReportSendLatency("reporter-ns", "reporter-name", 9100*time.Millisecond)
+	r.ReportEventRetries("reporter-ns", "reporter-name", 3)
+	r.ReportEventDeadLettered("reporter-ns", "reporter-name")
+	r.ReportDispatchLatency("reporter-ns", "reporter-name", 202, 10*time.Millisecond)
+	r.ReportDispatchLatency("reporter-ns", "reporter-name", 202, 20*time.Millisecond)
+	r.ReportScheduleSkew("reporter-ns", "reporter-name", 5*time.Millisecond)
+
+	event := cloudevents.NewEvent()
+	event.SetType("reporter-type")
+	event.SetSource("reporter-source")
+	r.ReportEventCount("reporter-ns", "reporter-name", event, 202)
+
+	resource := &resource.Resource{
+		Type: metricskey.ResourceTypeKnativeSource,
+		Labels: map[string]string{
+			metricskey.LabelNamespaceName: "reporter-ns",
+			metricskey.LabelName:          "reporter-name",
+			metricskey.LabelResourceGroup: resourceGroup,
+		},
+	}
+	assertSourceMetric(t, metricstest.IntMetric("ping_events_sent_total", 2, nil).WithResource(resource))
+	assertSourceMetric(t, metricstest.IntMetric("ping_events_failed_total", 1, nil).WithResource(resource))
+	assertSourceMetric(t, metricstest.IntMetric("ping_event_retries_total", 3, nil).WithResource(resource))
+	assertSourceMetric(t, metricstest.IntMetric("ping_events_dead_lettered_total", 1, nil).WithResource(resource))
+	assertSourceMetric(t, metricstest.DistributionCountOnlyMetric("ping_event_send_latency", 2, nil).WithResource(resource))
+	assertSourceMetric(t, metricstest.DistributionCountOnlyMetric("event_dispatch_latencies", 2, map[string]string{
+		metricskey.LabelResponseCode:      "202",
+		metricskey.LabelResponseCodeClass: "2xx",
+	}).WithResource(resource))
+	assertSourceMetric(t, metricstest.DistributionCountOnlyMetric("ping_schedule_skew", 1, nil).WithResource(resource))
+	// The event_count of the sources is shared with the other tests.
+	assertSourceValue(t, metricstest.IntMetric("event_count", 1, map[string]string{
+		metricskey.LabelNamespaceName:     "reporter-ns",
+		metricskey.LabelName:              "reporter-name",
+		metricskey.LabelEventType:         "reporter-type",
+		metricskey.LabelEventSource:       "reporter-source",
+		metricskey.LabelResourceGroup:     resourceGroup,
+		metricskey.LabelResponseCode:      "202",
+		metricskey.LabelResponseCodeClass: "2xx",
+	}))
+}
+
+// assertSourceMetric verifies that want was reported, the metrics of the
+// other sources being ignored.
+func assertSourceMetric(t *testing.T, want metricstest.Metric) {
+	t.Helper()
+	metricstest.EnsureRecorded()
+	got := metricstest.GetMetric(want.Name)
+	for _, m := range got {
+		if want.Equal(m) {
+			return
+		}
+	}
+	t.Errorf("Expected metric %v, got %v", want, got)
+}
+
+// assertSourceValue verifies that the value of want was reported, the
+// values of the other sources being ignored.
+func assertSourceValue(t *testing.T, want metricstest.Metric) {
+	t.Helper()
+	metricstest.EnsureRecorded()
+	for _, m := range metricstest.GetMetric(want.Name) {
+		for _, v := range m.Values {
+			if want.Equal(metricstest.Metric{Name: m.Name, Values: []metricstest.Value{v}}) {
+				return
+			}
+		}
+	}
+	t.Errorf("Expected metric value %v", want)
+}
+
+// fakeReporter counts the reported metrics by namespace/name.
+type fakeReporter struct {
+	mu           sync.Mutex
+	sent         map[string]int
+	failed       map[string]int
+	latencies    map[string]int
+	retries      map[string]int
+	deadLettered map[string]int
+	counts       map[string]int
+	dispatches   map[string]int
+	skews        map[string]int
+}
+
+func newFakeReporter() *fakeReporter {
+	return &fakeReporter{sent: map[string]int{}, failed: map[string]int{}, latencies: map[string]int{},
+		retries: map[string]int{}, deadLettered: map[string]int{}, counts: map[string]int{}, dispatches: map[string]int{},
+		skews: map[string]int{}}
+}
+
+func (r *fakeReporter) ReportEventSent(namespace, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent[namespace+"/"+name]++
+}
+
+func (r *fakeReporter) ReportEventFailed(namespace, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed[namespace+"/"+name]++
+}
+
+func (r *fakeReporter) ReportSendLatency(namespace, name string, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[namespace+"/"+name]++
+}
+
+func (r *fakeReporter) ReportEventRetries(namespace, name string, retries int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retries[namespace+"/"+name] += retries
+}
+
+func (r *fakeReporter) ReportEventDeadLettered(namespace, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deadLettered[namespace+"/"+name]++
+}
+
+func (r *fakeReporter) ReportEventCount(namespace, name string, _ cloudevents.Event, _ int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[namespace+"/"+name]++
+}
+
+// ReportDispatchLatency counts the dispatches by namespace/name/code.
+func (r *fakeReporter) ReportDispatchLatency(namespace, name string, responseCode int, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispatches[fmt.Sprintf("%s/%s/%d", namespace, name, responseCode)]++
+}
+
+func (r *fakeReporter) ReportScheduleSkew(namespace, name string, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skews[namespace+"/"+name]++
+}
+
+func TestSendMetrics(t *testing.T) {
+	testCases := map[string]struct {
+		results    []protocol.Result
+		wantSent   int
+		wantFailed int
+		wantCode   int
+	}{
+		"sent": {
+			wantSent: 1,
+			wantCode: 200,
+		},
+		"failed": {
+			results:    []protocol.Result{cehttp.NewResult(400, "%w", protocol.ResultNACK)},
+			wantFailed: 1,
+			wantCode:   400,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			reporter := newFakeReporter()
+			runner := NewCronJobsRunner(adaptertesting.NewTestClientWithResults(tc.results...), kubeclient.Get(ctx), logging.FromContext(ctx),
+				WithStatsReporter(reporter))
+
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Status.SinkURI = apis.HTTP("mysink")
+			}))
+			runner.cron.Entry(entryID).Job.Run()
+
+			if got := reporter.sent["test-ns/test-name"]; got != tc.wantSent {
+				t.Errorf("Expected %d sent events reported, got %d", tc.wantSent, got)
+			}
+			if got := reporter.failed["test-ns/test-name"]; got != tc.wantFailed {
+				t.Errorf("Expected %d failed events reported, got %d", tc.wantFailed, got)
+			}
+			if got := reporter.latencies["test-ns/test-name"]; got != 1 {
+				t.Errorf("Expected 1 send latency reported, got %d", got)
+			}
+			if got := reporter.dispatches[fmt.Sprintf("test-ns/test-name/%d", tc.wantCode)]; got != 1 {
+				t.Errorf("Expected 1 dispatch latency reported with code %d, got %v", tc.wantCode, reporter.dispatches)
+			}
+			// The default client counts its events.
+			if got := reporter.counts["test-ns/test-name"]; got != 0 {
+				t.Errorf("Expected no event count reported, got %d", got)
+			}
+			// The job is run before the first tick of its schedule.
+			if got := reporter.skews["test-ns/test-name"]; got != 0 {
+				t.Errorf("Expected no schedule skew reported, got %d", got)
+			}
+		})
+	}
+}
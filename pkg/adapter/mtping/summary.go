@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// summary is the data of the summary events.
+type summary struct {
+	// Since is when the previous summary was sent, or when the source was
+	// scheduled.
+	Since time.Time `json:"since"`
+	// Until is when the summary was made.
+	Until time.Time `json:"until"`
+
+	// Fires is the number of times the schedule fired.
+	Fires uint64 `json:"fires"`
+	// Sent is the number of events successfully sent.
+	Sent uint64 `json:"sent"`
+	// Failed is the number of events that were not sent.
+	Failed uint64 `json:"failed"`
+}
+
+// summaryTick returns the job sending a summary of the deliveries of the
+// source counted by opts.stats since its previous run. The summary events
+// carry the attributes of event.
+func (a *cronJobsRunner) summaryTick(ctx context.Context, event cloudevents.Event, opts jobOptions) func() {
+	var mu sync.Mutex
+	since := a.clock.Now()
+	var last summary
+	return func() {
+		if !a.begin() {
+			return
+		}
+		defer a.end()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		current := summary{
+			Since:  since,
+			Until:  a.clock.Now(),
+			Fires:  atomic.LoadUint64(&opts.stats.fires),
+			Sent:   atomic.LoadUint64(&opts.stats.sent),
+			Failed: atomic.LoadUint64(&opts.stats.failed),
+		}
+		data := current
+		data.Fires -= last.Fires
+		data.Sent -= last.Sent
+		data.Failed -= last.Failed
+
+		event := event.Clone()
+		event.SetID(uuid.New().String())
+		event.SetType(sourcesv1beta1.PingSourceSummaryEventType)
+		if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+			a.Logger.Errorw("failed to encode summary", zap.String("source", event.Source()), zap.Error(err))
+			return
+		}
+
+		// Counts not sent are reported with the next summary.
+		if a.deliver(ctx, opts, event, true) {
+			last = current
+			since = current.Until
+		}
+	}
+}
+
+// scheduleSummary adds the summary schedule of source, when it has one. It
+// returns the ID of its cron entry, zero when source has none.
+func (a *cronJobsRunner) scheduleSummary(ctx context.Context, source *sourcesv1beta1.PingSource, event cloudevents.Event, opts jobOptions) cron.EntryID {
+	spec, ok := source.Annotations[SummaryScheduleAnnotation]
+	if !ok {
+		return 0
+	}
+	// The schedule was validated with the source.
+	schedule, _ := sourcesv1beta1.ParseSchedule(spec)
+	return a.cron.Schedule(schedule, cron.FuncJob(a.summaryTick(ctx, event, opts)))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestSummary(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	sendErr := errors.New("sink unavailable")
+	ce := adaptertesting.NewTestClientWithResults(nil, sendErr, nil, nil, nil, nil)
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+	runner.clock = fakeClock
+
+	entryId := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{SummaryScheduleAnnotation: "0 * * * *"}
+	}))
+	job := runner.cron.Entry(entryId).Job
+	summaryJob := runner.cron.Entry(runner.summaries[entryId]).Job
+
+	// 3 fires, one failing, then a summary.
+	for i := 0; i < 3; i++ {
+		job.Run()
+	}
+	fakeClock.Step(time.Hour)
+	summaryJob.Run()
+	// A single fire, then a summary.
+	job.Run()
+	fakeClock.Step(time.Hour)
+	summaryJob.Run()
+
+	var summaries []summary
+	for _, event := range ce.Sent() {
+		if event.Type() != sourcesv1beta1.PingSourceSummaryEventType {
+			continue
+		}
+		if got, want := event.Source(), sourcesv1beta1.PingSourceSource("test-ns", "test-name"); got != want {
+			t.Errorf("Expected summary source %q, got %q", want, got)
+		}
+		var s summary
+		if err := event.DataAs(&s); err != nil {
+			t.Fatal("Failed to decode summary:", err)
+		}
+		summaries = append(summaries, s)
+	}
+
+	want := []summary{{
+		Since:  start,
+		Until:  start.Add(time.Hour),
+		Fires:  3,
+		Sent:   2,
+		Failed: 1,
+	}, {
+		Since: start.Add(time.Hour),
+		Until: start.Add(2 * time.Hour),
+		Fires: 1,
+		Sent:  1,
+	}}
+	if len(summaries) != len(want) {
+		t.Fatalf("Expected %d summaries, got %d", len(want), len(summaries))
+	}
+	for i := range want {
+		got := summaries[i]
+		if !got.Since.Equal(want[i].Since) || !got.Until.Equal(want[i].Until) ||
+			got.Fires != want[i].Fires || got.Sent != want[i].Sent || got.Failed != want[i].Failed {
+			t.Errorf("Expected summary %d to be %+v, got %+v", i, want[i], got)
+		}
+	}
+
+	summaryId := runner.summaries[entryId]
+	if err := runner.RemoveSchedule(entryId); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, err := runner.Entry(summaryId); !errors.Is(err, ErrEntryNotFound) {
+		t.Error("Expected the summary schedule to be removed with the source, got", err)
+	}
+}
+
+func TestInvalidSummarySchedule(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryId, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{SummaryScheduleAnnotation: "hourly"}
+	}))
+	if err == nil {
+		t.Error("Expected the source not to be scheduled, got", entryId)
+	}
+	if got := len(runner.cron.Entries()); got != 0 {
+		t.Error("Expected no schedule, got", got)
+	}
+}
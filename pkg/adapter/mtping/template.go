@@ -0,0 +1,260 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+
+	kncloudevents "knative.dev/eventing/pkg/adapter/v2"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// templateData is the data the type and source templates are rendered with.
+type templateData struct {
+	// Time is the fire time.
+	Time        time.Time
+	Namespace   string
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// eventTemplates renders the type and source of the events of a source on
+// each fire.
+type eventTemplates struct {
+	data   templateData
+	typ    *template.Template
+	source *template.Template
+
+	// lenientTyp and lenientSource render the missing keys empty, telling
+	// the failures of typ and source on missing keys apart.
+	lenientTyp    *template.Template
+	lenientSource *template.Template
+	// lenient renders the missing keys empty instead of failing, for the
+	// sources with the Lenient TemplateMissingKey.
+	lenient bool
+}
+
+// missingKeyError is the error of the templates referencing a key missing
+// from the labels or annotations of the source.
+type missingKeyError struct {
+	err error
+}
+
+func (e *missingKeyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *missingKeyError) Unwrap() error {
+	return e.err
+}
+
+// newEventTemplates parses the type and source templates of the given
+// source. It returns nil when the source has none.
+func newEventTemplates(source *sourcesv1beta1.PingSource) (*eventTemplates, error) {
+	if source.Spec.CloudEventType == "" && source.Spec.CloudEventSource == "" {
+		return nil, nil
+	}
+
+	t := &eventTemplates{
+		data: templateData{
+			Namespace:   source.Namespace,
+			Name:        source.Name,
+			Labels:      source.Labels,
+			Annotations: source.Annotations,
+		},
+		lenient: source.Spec.TemplateMissingKey == sourcesv1beta1.TemplateMissingKeyLenient,
+	}
+	var err error
+	if source.Spec.CloudEventType != "" {
+		if t.typ, t.lenientTyp, err = parseTemplates("cloudEventType", source.Spec.CloudEventType); err != nil {
+			return nil, err
+		}
+	}
+	if source.Spec.CloudEventSource != "" {
+		if t.source, t.lenientSource, err = parseTemplates("cloudEventSource", source.Spec.CloudEventSource); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// parseTemplates parses text failing on the missing keys, and rendering them
+// empty.
+func parseTemplates(name, text string) (strict, lenient *template.Template, err error) {
+	if strict, err = template.New(name).Option("missingkey=error").Parse(text); err != nil {
+		return nil, nil, err
+	}
+	lenient, err = template.New(name).Option("missingkey=zero").Parse(text)
+	return strict, lenient, err
+}
+
+// Render sets the type and source of event to the templates rendered at the
+// given fire time. The event is left unchanged on error, a *missingKeyError
+// when the templates reference missing keys. When lenient, the missing keys
+// are rendered empty and their error is returned as missing.
+func (t *eventTemplates) Render(event *cloudevents.Event, now time.Time) (missing, err error) {
+	data := t.data
+	data.Time = now
+
+	var typ, source string
+	if t.typ != nil {
+		if typ, err = t.execute(t.typ, t.lenientTyp, data, &missing); err != nil {
+			return nil, err
+		}
+		if typ == "" {
+			return nil, errors.New("cloudEventType rendered to an empty type")
+		}
+	}
+	if t.source != nil {
+		if source, err = t.execute(t.source, t.lenientSource, data, &missing); err != nil {
+			return nil, err
+		}
+		if source == "" {
+			return nil, errors.New("cloudEventSource rendered to an empty source")
+		}
+		if _, err := url.Parse(source); err != nil {
+			return nil, fmt.Errorf("cloudEventSource rendered to an invalid URI reference: %w", err)
+		}
+	}
+
+	if typ != "" {
+		event.SetType(typ)
+	}
+	if source != "" {
+		event.SetSource(source)
+	}
+	return missing, nil
+}
+
+// execute renders strict, or lenient when strict fails on missing keys and
+// t is lenient, their error being set to missing.
+func (t *eventTemplates) execute(strict, lenient *template.Template, data templateData, missing *error) (string, error) {
+	rendered, err := execute(strict, data)
+	if err == nil {
+		return rendered, nil
+	}
+	// The keys are missing when rendering them empty succeeds.
+	rendered, lenientErr := execute(lenient, data)
+	if lenientErr != nil {
+		return "", err
+	}
+	if !t.lenient {
+		return "", &missingKeyError{err: err}
+	}
+	*missing = &missingKeyError{err: err}
+	return rendered, nil
+}
+
+func execute(t *template.Template, data templateData) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// templateOptions sets opts to render the templated attributes of the
+// events of source on each fire.
+func (a *cronJobsRunner) templateOptions(source *sourcesv1beta1.PingSource, opts *jobOptions) error {
+	templates, err := newEventTemplates(source)
+	if err != nil {
+		return newScheduleError(source, ReasonInvalidSpec, fmt.Errorf("invalid cloudevent type or source template: %w", err))
+	}
+	opts.templates = templates
+	overrides, err := kncloudevents.NewOverrides(source.Spec.CloudEventOverrides)
+	if err != nil {
+		return newScheduleError(source, ReasonInvalidSpec, fmt.Errorf("invalid cloudevent overrides: %w", err))
+	}
+	// The static overrides are set once by makeEvent.
+	if overrides.Templated() {
+		opts.overrides = overrides
+	}
+	opts.types = newWeightedTypes(source.Spec.TypeVariants)
+	return nil
+}
+
+// renderTemplates renders the templated attributes of the event of the fire
+// of tick, happening at now. It returns false when the fire is skipped.
+func (a *cronJobsRunner) renderTemplates(opts jobOptions, event *cloudevents.Event, tick, now time.Time) bool {
+	if opts.templates != nil {
+		missing, err := opts.templates.Render(event, now)
+		if err != nil {
+			atomic.AddUint64(&opts.stats.failed, 1)
+			if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+				a.Logger.Errorw("failed to render cloudevent type or source, skipping fire", zap.String("source", event.Source()),
+					zap.Error(err), zap.Int("suppressed", suppressed))
+			}
+			var missingKey *missingKeyError
+			if errors.As(err, &missingKey) {
+				atomic.AddUint64(&opts.stats.missingKey, 1)
+				a.reportTemplateKeys(opts, templateKeysSkipped, err)
+			}
+			return false
+		}
+		if missing != nil {
+			if suppressed, ok := opts.errorLog.Allow(a.clock.Now()); ok {
+				a.Logger.Warnw("cloudevent type or source template references a missing key, rendering it empty",
+					zap.String("source", event.Source()), zap.Error(missing), zap.Int("suppressed", suppressed))
+			}
+			a.reportTemplateKeys(opts, templateKeysRenderedEmpty, missing)
+		} else {
+			a.reportTemplateKeys(opts, templateKeysResolved, nil)
+		}
+	}
+	if opts.types != nil {
+		event.SetType(opts.types.pick(a.rand()))
+	}
+	if opts.overrides != nil {
+		opts.overrides.Apply(event, kncloudevents.OverrideValues{
+			Timestamp:       now.UTC().Format(time.RFC3339),
+			ScheduleTick:    tick.UTC().Format(time.RFC3339),
+			SourceName:      opts.stats.name,
+			SourceNamespace: opts.stats.namespace,
+		})
+	}
+	return true
+}
+
+// The states of the template keys of a source, reported on their
+// transitions.
+const (
+	templateKeysUnknown int32 = iota
+	templateKeysResolved
+	templateKeysRenderedEmpty
+	templateKeysSkipped
+)
+
+// reportTemplateKeys reports the state of the template keys of the source of
+// opts to the missingKeys handler when it changed, err being the error of
+// the missing keys. The first state of the source is always reported, for
+// the status written by a previous adapter to be resolved.
+func (a *cronJobsRunner) reportTemplateKeys(opts jobOptions, state int32, err error) {
+	if a.missingKeys == nil || atomic.SwapInt32(&opts.stats.templateKeys, state) == state {
+		return
+	}
+	a.missingKeys(opts.namespace, opts.name, err, state == templateKeysSkipped)
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestTemplatedTypeAndSource(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Labels = map[string]string{"stream": "billing"}
+		s.Spec.CloudEventType = "dev.example.{{.Labels.stream}}"
+		s.Spec.CloudEventSource = "/pings/{{.Namespace}}/{{.Name}}"
+	}))
+	job := runner.cron.Entry(entryID).Job
+	job.Run()
+	job.Run()
+
+	if got := len(ce.Sent()); got != 2 {
+		t.Fatal("Expected 2 events to be sent, got", got)
+	}
+	for _, event := range ce.Sent() {
+		if got, want := event.Type(), "dev.example.billing"; got != want {
+			t.Errorf("Expected type %q, got %q", want, got)
+		}
+		if got, want := event.Source(), "/pings/test-ns/test-name"; got != want {
+			t.Errorf("Expected source %q, got %q", want, got)
+		}
+	}
+}
+
+func TestTypeAndSourceOverrides(t *testing.T) {
+	testCases := map[string]struct {
+		typ, source          string
+		wantType, wantSource string
+	}{
+		"overridden": {
+			typ:        "dev.example.heartbeat",
+			source:     "/streams/heartbeat",
+			wantType:   "dev.example.heartbeat",
+			wantSource: "/streams/heartbeat",
+		},
+		"defaults": {
+			wantType:   sourcesv1beta1.PingSourceEventType,
+			wantSource: sourcesv1beta1.PingSourceSource("test-ns", "test-name"),
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.SourceSpec = duckv1.SourceSpec{
+					CloudEventOverrides: &duckv1.CloudEventOverrides{
+						Extensions: map[string]string{"stream": "heartbeat"},
+					},
+				}
+				s.Spec.CloudEventType = tc.typ
+				s.Spec.CloudEventSource = tc.source
+			}))
+			runner.cron.Entry(entryID).Job.Run()
+
+			if got := len(ce.Sent()); got != 1 {
+				t.Fatal("Expected 1 event to be sent, got", got)
+			}
+			event := ce.Sent()[0]
+			if got := event.Context.GetType(); got != tc.wantType {
+				t.Errorf("Expected type %q, got %q", tc.wantType, got)
+			}
+			if got := event.Context.GetSource(); got != tc.wantSource {
+				t.Errorf("Expected source %q, got %q", tc.wantSource, got)
+			}
+			if got := event.Extensions()["stream"]; got != "heartbeat" {
+				t.Errorf("Expected the stream extension override to be kept, got %v", got)
+			}
+		})
+	}
+}
+
+func TestTemplatesRenderEachFire(t *testing.T) {
+	templates, err := newEventTemplates(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.CloudEventType = `dev.example.{{.Time.Format "1504"}}`
+	}))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	for _, tc := range []struct {
+		time time.Time
+		want string
+	}{
+		{time.Date(2020, 1, 1, 10, 30, 0, 0, time.UTC), "dev.example.1030"},
+		{time.Date(2020, 1, 1, 10, 31, 0, 0, time.UTC), "dev.example.1031"},
+	} {
+		event := mustMakeEvent(t, newTestSource())
+		if _, err := templates.Render(&event, tc.time); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if got := event.Type(); got != tc.want {
+			t.Errorf("Expected type %q, got %q", tc.want, got)
+		}
+		if got, want := event.Source(), sourcesv1beta1.PingSourceSource("test-ns", "test-name"); got != want {
+			t.Errorf("Expected the default source %q, got %q", want, got)
+		}
+	}
+}
+
+func TestTemplatesRenderErrors(t *testing.T) {
+	for name, spec := range map[string]sourcesv1beta1.PingSourceSpec{
+		"missing label": {CloudEventType: "{{.Labels.missing}}"},
+		"empty type":    {CloudEventType: "{{if false}}x{{end}}"},
+		"invalid uri":   {CloudEventSource: "%zz"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			templates, err := newEventTemplates(&sourcesv1beta1.PingSource{Spec: spec})
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			event := cloudevents.NewEvent()
+			event.SetType("type")
+			event.SetSource("source")
+			if _, err := templates.Render(&event, time.Now()); err == nil {
+				t.Error("Expected a render error")
+			}
+			if event.Type() != "type" || event.Source() != "source" {
+				t.Error("Expected the event to be left unchanged")
+			}
+		})
+	}
+}
+
+func TestTemplateMissingKey(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy   sourcesv1beta1.TemplateMissingKeyPolicy
+		wantSent bool
+	}{
+		"default": {},
+		"strict":  {policy: sourcesv1beta1.TemplateMissingKeyStrict},
+		"lenient": {policy: sourcesv1beta1.TemplateMissingKeyLenient, wantSent: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			var skips []bool
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx),
+				WithMissingTemplateKeyHandler(func(namespace, name string, err error, skipped bool) {
+					if err == nil {
+						t.Error("Expected the missing keys not to be reported resolved")
+					}
+					skips = append(skips, skipped)
+				}))
+
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Spec.CloudEventType = "dev.example.{{.Labels.team}}ping"
+				s.Spec.TemplateMissingKey = tc.policy
+			}))
+			// The missing keys are reported once.
+			runner.cron.Entry(entryID).Job.Run()
+			ce.Reset()
+			runner.cron.Entry(entryID).Job.Run()
+
+			stats := runner.Stats().Sources["test-ns/test-name"]
+			if tc.wantSent {
+				if len(ce.Sent()) != 1 || ce.Sent()[0].Type() != "dev.example.ping" {
+					t.Fatalf("Expected an event of type dev.example.ping to be sent, got %v", ce.Sent())
+				}
+				if stats.Failed != 0 || stats.MissingKey != 0 {
+					t.Errorf("Expected no failed fire, got %+v", stats)
+				}
+			} else {
+				if got := len(ce.Sent()); got != 0 {
+					t.Fatal("Expected the fire to be skipped, got", got)
+				}
+				if stats.Failed != 2 || stats.MissingKey != 2 {
+					t.Errorf("Expected the skipped fires to be counted as failed for missing keys, got %+v", stats)
+				}
+			}
+			if len(skips) != 1 || skips[0] == tc.wantSent {
+				t.Errorf("Expected the missing keys to be reported once, skipping the fire: %v, got %v", !tc.wantSent, skips)
+			}
+		})
+	}
+}
+
+func TestTemplateKeysResolved(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	var reported []error
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx),
+		WithMissingTemplateKeyHandler(func(namespace, name string, err error, skipped bool) {
+			reported = append(reported, err)
+		}))
+
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Labels = map[string]string{"team": "blue"}
+		s.Spec.CloudEventType = "dev.example.{{.Labels.team}}"
+	})
+	entryID := mustAddSchedule(t, runner, source)
+	// The first clean render resolves the keys missing for a previous
+	// adapter, once.
+	runner.cron.Entry(entryID).Job.Run()
+	runner.cron.Entry(entryID).Job.Run()
+	if len(reported) != 1 || reported[0] != nil {
+		t.Fatalf("Expected the keys to be reported resolved once, got %v", reported)
+	}
+
+	source.Labels = nil
+	entryID, err := runner.UpdateSchedule(source)
+	if err != nil {
+		t.Fatal("Failed to update the schedule:", err)
+	}
+	runner.cron.Entry(entryID).Job.Run()
+	source.Labels = map[string]string{"team": "blue"}
+	if entryID, err = runner.UpdateSchedule(source); err != nil {
+		t.Fatal("Failed to update the schedule:", err)
+	}
+	runner.cron.Entry(entryID).Job.Run()
+	if len(reported) != 3 || reported[1] == nil || reported[2] != nil {
+		t.Errorf("Expected the keys to be reported missing then resolved, got %v", reported)
+	}
+}
+
+func TestTemplatedOverrides(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.SourceSpec = duckv1.SourceSpec{
+			CloudEventOverrides: &duckv1.CloudEventOverrides{
+				Extensions: map[string]string{
+					"origin":  "{{.SourceNamespace}}/{{.SourceName}}",
+					"firedat": "{{.ScheduleTick}}",
+					"stream":  "heartbeat",
+				},
+			},
+		}
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := len(ce.Sent()); got != 1 {
+		t.Fatal("Expected 1 event to be sent, got", got)
+	}
+	event := ce.Sent()[0]
+	if got := event.Extensions()["origin"]; got != "test-ns/test-name" {
+		t.Errorf("Expected the origin extension to be rendered, got %v", got)
+	}
+	if got, ok := event.Extensions()["firedat"].(string); !ok {
+		t.Errorf("Expected the firedat extension to be rendered, got %v", event.Extensions()["firedat"])
+	} else if _, err := time.Parse(time.RFC3339, got); err != nil {
+		t.Errorf("Expected the firedat extension to be a timestamp, got %q", got)
+	}
+	if got := event.Extensions()["stream"]; got != "heartbeat" {
+		t.Errorf("Expected the stream extension override to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"fmt"
+	"time"
+)
+
+// eventClock returns the time attribute of the events of a source fired at
+// now.
+type eventClock func(now time.Time) time.Time
+
+// parseTimeSource returns the eventClock described by the value of
+// TimeSourceAnnotation: an RFC 3339 timestamp fixing the time of all the
+// events, or a duration shifting the time of the fires.
+func parseTimeSource(s string) (eventClock, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return func(time.Time) time.Time { return t }, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return func(now time.Time) time.Time { return now.Add(d) }, nil
+	}
+	return nil, fmt.Errorf("%q is neither an RFC 3339 timestamp nor a duration", s)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+	"time"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestTimeSource(t *testing.T) {
+	epoch := time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		timeSource string
+		// want returns the range the event time must be in, given the time
+		// before and after the fire.
+		want func(before, after time.Time) (time.Time, time.Time)
+	}{
+		"fixed epoch": {
+			timeSource: "1970-01-01T00:00:00Z",
+			want: func(time.Time, time.Time) (time.Time, time.Time) {
+				return epoch, epoch
+			},
+		},
+		"shifted": {
+			timeSource: "-24h",
+			want: func(before, after time.Time) (time.Time, time.Time) {
+				return before.Add(-24 * time.Hour), after.Add(-24 * time.Hour)
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ctx, _ := rectesting.SetupFakeContext(t)
+			ce := adaptertesting.NewTestClient()
+			runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+			entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+				s.Annotations = map[string]string{TimeSourceAnnotation: tc.timeSource}
+			}))
+			before := time.Now()
+			runner.cron.Entry(entryID).Job.Run()
+			after := time.Now()
+
+			sent := ce.Sent()
+			if len(sent) != 1 {
+				t.Fatalf("Expected 1 event sent, got %d", len(sent))
+			}
+			from, to := tc.want(before, after)
+			if got := sent[0].Time(); got.Before(from) || got.After(to) {
+				t.Errorf("Expected the event time to be in [%v, %v], got %v", from, to, got)
+			}
+		})
+	}
+}
+
+func TestInvalidTimeSource(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	id, err := runner.AddSchedule(newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Annotations = map[string]string{TimeSourceAnnotation: "yesterday"}
+	}))
+	if err == nil {
+		t.Error("Expected a source with an invalid time source not to be scheduled, got entry", id)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"crypto/rand"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// fireSpanName is the name of the spans of the fires.
+	fireSpanName = "mtping.fire"
+	// attemptSpanName is the name of the spans of the send attempts, the
+	// children of the span of their fire.
+	attemptSpanName = "mtping.send.attempt"
+)
+
+// traceContext returns the distributed tracing extension of the span found
+// in ctx. When there is none, a new trace context is generated so the event
+// starts a new trace.
+func traceContext(ctx context.Context) extensions.DistributedTracingExtension {
+	if span := trace.FromContext(ctx); span != nil {
+		return extensions.FromSpanContext(span.SpanContext())
+	}
+
+	var sc trace.SpanContext
+	// crypto/rand.Read never fails on supported platforms.
+	_, _ = rand.Read(sc.TraceID[:])
+	_, _ = rand.Read(sc.SpanID[:])
+	sc.TraceOptions = 1 // sampled
+	return extensions.FromSpanContext(sc)
+}
+
+// traceFire starts the span of a fire. The span is recorded only when the
+// tracing configuration samples it, the tracing being otherwise a no-op.
+// Its trace context is the one of the events of the fire.
+func (a *cronJobsRunner) traceFire(ctx context.Context, opts jobOptions, source string) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, fireSpanName)
+	if span.IsRecordingEvents() {
+		span.AddAttributes(
+			trace.StringAttribute("source", source),
+			trace.StringAttribute("namespace", opts.namespace),
+			trace.StringAttribute("name", opts.name),
+			trace.StringAttribute("schedule", opts.scheduleSpec),
+		)
+	}
+	return ctx, span
+}
+
+// traced returns whether the span found in ctx is sampled.
+func traced(ctx context.Context) bool {
+	span := trace.FromContext(ctx)
+	return span != nil && span.SpanContext().IsSampled()
+}
+
+// sendAttempt sends the event through client, tracing the attempt when the
+// runner has attempt spans.
+func (a *cronJobsRunner) sendAttempt(ctx context.Context, client cloudevents.Client, event cloudevents.Event, attempt int) protocol.Result {
+	if !a.attemptSpans {
+		return client.Send(ctx, event)
+	}
+	ctx, span := trace.StartSpan(ctx, attemptSpanName)
+	defer span.End()
+	result := client.Send(ctx, event)
+	span.AddAttributes(
+		trace.Int64Attribute("attempt", int64(attempt)),
+		trace.StringAttribute("outcome", attemptOutcome(result)),
+	)
+	if !cloudevents.IsACK(result) {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: result.Error()})
+	}
+	return result
+}
+
+// attemptOutcome returns the outcome of a send attempt: ack, nack or
+// undelivered.
+func attemptOutcome(result protocol.Result) string {
+	switch {
+	case cloudevents.IsACK(result):
+		return "ack"
+	case cloudevents.IsNACK(result):
+		return "nack"
+	default:
+		return "undelivered"
+	}
+}
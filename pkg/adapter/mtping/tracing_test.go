@@ -0,0 +1,223 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.opencensus.io/trace"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+var traceParentRegexp = regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-0[01]$`)
+
+// zeroTraceIDsRegexp matches the traceparents whose trace or span ID is all
+// zeros, which W3C Trace Context makes invalid.
+var zeroTraceIDsRegexp = regexp.MustCompile(`^00-(0{32}|[0-9a-f]{32}-0{16})-`)
+
+func TestTraceParent(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithTraceParent())
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryID).Job
+	job.Run()
+	job.Run()
+
+	sent := ce.Sent()
+	if len(sent) != 2 {
+		t.Fatal("Expected 2 events to be sent, got", len(sent))
+	}
+
+	seen := make(map[string]bool)
+	for _, event := range sent {
+		dt, ok := extensions.GetDistributedTracingExtension(event)
+		if !ok {
+			t.Fatal("Expected the traceparent extension to be set")
+		}
+		if !traceParentRegexp.MatchString(dt.TraceParent) {
+			t.Errorf("Expected a valid traceparent, got %q", dt.TraceParent)
+		}
+		if zeroTraceIDsRegexp.MatchString(dt.TraceParent) {
+			t.Errorf("Expected non-zero trace and span IDs, got %q", dt.TraceParent)
+		}
+		seen[dt.TraceParent] = true
+	}
+	if len(seen) != 2 {
+		t.Error("Expected each fire to start a new trace")
+	}
+}
+
+func TestTraceContextFromSpan(t *testing.T) {
+	ctx, span := trace.StartSpan(context.Background(), "test")
+	defer span.End()
+
+	dt := traceContext(ctx)
+	sc, err := dt.ToSpanContext()
+	if err != nil {
+		t.Fatal("Failed to parse traceparent:", err)
+	}
+	if sc.TraceID != span.SpanContext().TraceID || sc.SpanID != span.SpanContext().SpanID {
+		t.Errorf("Expected the trace context of the current span, got %q", dt.TraceParent)
+	}
+}
+
+// spanRecorder records the spans exported.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+func (r *spanRecorder) named(name string) []*trace.SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var spans []*trace.SpanData
+	for _, s := range r.spans {
+		if s.Name == name {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+func TestAttemptSpans(t *testing.T) {
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	unavailable := cehttp.NewResult(503, "%w", protocol.ResultNACK)
+	ce := adaptertesting.NewTestClientWithResults(unavailable, unavailable)
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx), WithAttemptSpans())
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := len(ce.Sent()); got != 1 {
+		t.Fatal("Expected the event to be sent on the last attempt, got", got)
+	}
+	fires := recorder.named(fireSpanName)
+	if len(fires) != 1 {
+		t.Fatal("Expected 1 fire span, got", len(fires))
+	}
+	attempts := recorder.named(attemptSpanName)
+	if len(attempts) != 3 {
+		t.Fatal("Expected 3 attempt spans, got", len(attempts))
+	}
+	wantOutcomes := []string{"nack", "nack", "ack"}
+	for i, span := range attempts {
+		if span.ParentSpanID != fires[0].SpanID || span.TraceID != fires[0].TraceID {
+			t.Errorf("Expected attempt %d to be a child of the fire span", i+1)
+		}
+		if got := span.Attributes["attempt"]; got != int64(i+1) {
+			t.Errorf("Expected attempt %d to have attempt attribute %d, got %v", i+1, i+1, got)
+		}
+		if got := span.Attributes["outcome"]; got != wantOutcomes[i] {
+			t.Errorf("Expected attempt %d to have outcome %q, got %v", i+1, wantOutcomes[i], got)
+		}
+	}
+}
+
+func TestFireSpans(t *testing.T) {
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClientWithResults(protocol.ResultACK, cehttp.NewResult(400, "%w", protocol.ResultNACK))
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	job := runner.cron.Entry(entryID).Job
+	job.Run()
+	job.Run()
+
+	fires := recorder.named(fireSpanName)
+	if len(fires) != 2 {
+		t.Fatal("Expected a fire span per run, got", len(fires))
+	}
+	for i, span := range fires {
+		for key, want := range map[string]string{"namespace": "test-ns", "name": "test-name", "schedule": "* * * * ?"} {
+			if got := span.Attributes[key]; got != want {
+				t.Errorf("Expected fire %d to have %s attribute %q, got %v", i+1, key, want, got)
+			}
+		}
+	}
+	if code := fires[0].Status.Code; code != trace.StatusCodeOK {
+		t.Error("Expected the delivered fire to have an OK status, got", code)
+	}
+	if code := fires[1].Status.Code; code != trace.StatusCodeUnavailable {
+		t.Error("Expected the failed fire to have an unavailable status, got", code)
+	}
+
+	sent := ce.Sent()
+	if len(sent) != 1 {
+		t.Fatal("Expected the first event to be sent, got", len(sent))
+	}
+	dt, ok := extensions.GetDistributedTracingExtension(sent[0])
+	if !ok {
+		t.Fatal("Expected the traceparent extension to be set")
+	}
+	sc, err := dt.ToSpanContext()
+	if err != nil {
+		t.Fatal("Failed to parse traceparent:", err)
+	}
+	if sc.TraceID != fires[0].TraceID || sc.SpanID != fires[0].SpanID {
+		t.Errorf("Expected the event to carry the trace context of its fire span, got %q", dt.TraceParent)
+	}
+}
+
+func TestFireSpansNotSampled(t *testing.T) {
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	entryID := mustAddSchedule(t, runner, newTestSource())
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := len(recorder.named(fireSpanName)); got != 0 {
+		t.Error("Expected no fire span recorded, got", got)
+	}
+	if _, ok := extensions.GetDistributedTracingExtension(ce.Sent()[0]); ok {
+		t.Error("Expected no traceparent extension when not tracing")
+	}
+}
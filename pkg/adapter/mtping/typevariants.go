@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"sort"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// weightedTypes picks the type of the events of a source among its type
+// variants, according to their weights.
+type weightedTypes struct {
+	types []string
+	// bounds are the cumulative weights of types.
+	bounds []int
+}
+
+// newWeightedTypes returns the selector of the given variants, or nil when
+// there is none. The variants without a positive weight are never picked.
+func newWeightedTypes(variants []sourcesv1beta1.TypeVariant) *weightedTypes {
+	w := &weightedTypes{}
+	total := 0
+	for _, variant := range variants {
+		if variant.Weight <= 0 {
+			continue
+		}
+		total += variant.Weight
+		w.types = append(w.types, variant.Type)
+		w.bounds = append(w.bounds, total)
+	}
+	if len(w.types) == 0 {
+		return nil
+	}
+	return w
+}
+
+// pick returns the type whose weight range holds r, in [0, 1).
+func (w *weightedTypes) pick(r float64) string {
+	n := int(r * float64(w.bounds[len(w.bounds)-1]))
+	i := sort.SearchInts(w.bounds, n+1)
+	if i == len(w.types) {
+		i--
+	}
+	return w.types[i]
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestWeightedTypesPick(t *testing.T) {
+	w := newWeightedTypes([]sourcesv1beta1.TypeVariant{
+		{Type: "a", Weight: 1},
+		{Type: "never", Weight: 0},
+		{Type: "b", Weight: 3},
+	})
+	testCases := map[float64]string{
+		0:      "a",
+		0.2499: "a",
+		0.25:   "b",
+		0.9999: "b",
+	}
+	for r, want := range testCases {
+		if got := w.pick(r); got != want {
+			t.Errorf("pick(%v) = %q, want %q", r, got, want)
+		}
+	}
+
+	if w := newWeightedTypes(nil); w != nil {
+		t.Error("Expected no selector without variants, got", w)
+	}
+}
+
+func TestTypeVariants(t *testing.T) {
+	const fires = 3000
+	weights := map[string]int{"type.low": 1, "type.mid": 3, "type.high": 6}
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = apis.HTTP("mysink")
+		s.Spec.TypeVariants = []sourcesv1beta1.TypeVariant{
+			{Type: "type.low", Weight: weights["type.low"]},
+			{Type: "type.mid", Weight: weights["type.mid"]},
+			{Type: "type.high", Weight: weights["type.high"]},
+		}
+	}))
+	fire := runner.cron.Entry(entryID).Job.Run
+	// The fires are concurrent, each one being randomly delayed.
+	var wg sync.WaitGroup
+	wg.Add(fires)
+	for i := 0; i < fires; i++ {
+		go func() {
+			defer wg.Done()
+			fire()
+		}()
+	}
+	wg.Wait()
+
+	counts := make(map[string]int)
+	for _, event := range ce.Sent() {
+		counts[event.Type()]++
+	}
+	if len(counts) != len(weights) {
+		t.Fatal("Expected the events to have the variant types, got", counts)
+	}
+	for typ, weight := range weights {
+		want := float64(weight) / 10
+		got := float64(counts[typ]) / fires
+		// About 5 standard deviations for the most likely type.
+		if math.Abs(got-want) > 0.05 {
+			t.Errorf("Expected a ratio of about %.2f for %s, got %.3f", want, typ, got)
+		}
+	}
+}
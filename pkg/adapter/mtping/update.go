@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/robfig/cron/v3"
+
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// scheduledJob is the job of the cron entry of a source. UpdateSchedule
+// replaces its tick in place, keeping the entry.
+type scheduledJob struct {
+	id cron.EntryID
+	// schedule holds the settings the entry was registered with, see
+	// scheduleOf.
+	schedule string
+	stats    *deliveryStats
+	// source is the one the job was last scheduled for, guarded by the
+	// statsMu of the runner.
+	source *sourcesv1beta1.PingSource
+
+	// tick holds the func() run on each fire.
+	tick atomic.Value
+}
+
+var _ cron.Job = (*scheduledJob)(nil)
+
+func (j *scheduledJob) Run() {
+	j.tick.Load().(func())()
+}
+
+// scheduleOf returns the settings of source the times of its fires depend
+// on.
+func scheduleOf(source *sourcesv1beta1.PingSource) string {
+	settings := append([]string{source.Spec.Schedule, source.Spec.Timezone, source.Annotations[SecondOffsetsAnnotation]}, source.Spec.Schedules...)
+	if source.Spec.Interval != nil {
+		settings = append(settings, source.Spec.Interval.Duration.String())
+	}
+	return strings.Join(settings, "\n")
+}
+
+// UpdateSchedule applies the changes of source to its schedule, found by
+// its namespace and name. The cron entry is kept when the schedule itself
+// didn't change, for the fires not to be disrupted, and registered again
+// otherwise. Sources not scheduled yet are added like with AddSchedule. It
+// returns a *ScheduleError, and removes the previous schedule, when source
+// can't be scheduled.
+func (a *cronJobsRunner) UpdateSchedule(source *sourcesv1beta1.PingSource) (cron.EntryID, error) {
+	a.statsMu.Lock()
+	current, ok := a.scheduled[source.Namespace+"/"+source.Name]
+	a.statsMu.Unlock()
+	if !ok {
+		return a.AddSchedule(source)
+	}
+	if current.schedule != scheduleOf(source) {
+		a.remove(current.id)
+		return a.AddSchedule(source)
+	}
+	id, err := a.schedule(source, current, current.stats)
+	if err != nil {
+		a.remove(current.id)
+	}
+	return id, err
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+func TestUpdateScheduleInPlace(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	client := &targetRecorder{Client: ce}
+	runner := NewCronJobsRunner(client, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	source := newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.JsonData = `{"msg":"before"}`
+	})
+	id := mustAddSchedule(t, runner, source)
+	runner.cron.Entry(id).Job.Run()
+
+	source = source.DeepCopy()
+	source.Spec.JsonData = `{"msg":"after"}`
+	source.Spec.CloudEventOverrides = &duckv1.CloudEventOverrides{Extensions: map[string]string{"release": "v2"}}
+	source.Status.SinkURI = &apis.URL{Scheme: "http", Host: "other-sink.example.com"}
+	if got, err := runner.UpdateSchedule(source); err != nil || got != id {
+		t.Fatalf("Expected the entry %d to be kept, got (%d, %v)", id, got, err)
+	}
+	if got := len(runner.cron.Entries()); got != 1 {
+		t.Fatalf("Expected 1 cron entry, got %d", got)
+	}
+	runner.cron.Entry(id).Job.Run()
+
+	sent := ce.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 events sent, got %d", len(sent))
+	}
+	if got := string(sent[1].Data()); got != `{"msg":"after"}` {
+		t.Errorf("Expected the updated data to be sent, got %s", got)
+	}
+	if got := sent[1].Extensions()["release"]; got != "v2" {
+		t.Errorf("Expected the updated extensions to be set, got %v", got)
+	}
+	if got := client.targets[1]; got != "http://other-sink.example.com" {
+		t.Errorf("Expected the event to be sent to the updated sink, got %s", got)
+	}
+	if got := runner.Stats().Sources["test-ns/test-name"].Sent; got != 2 {
+		t.Errorf("Expected the counters to go on across the update, got %d events sent", got)
+	}
+}
+
+func TestUpdateScheduleChangedSchedule(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	source := newTestSource()
+	id := mustAddSchedule(t, runner, source)
+
+	source = source.DeepCopy()
+	source.Spec.Schedule = "0 * * * ?"
+	updated, err := runner.UpdateSchedule(source)
+	if err != nil || updated == id {
+		t.Fatalf("Expected the schedule to be registered again, got (%d, %v)", updated, err)
+	}
+	entries := runner.cron.Entries()
+	if len(entries) != 1 || entries[0].ID != updated {
+		t.Errorf("Expected only the entry %d, got %+v", updated, entries)
+	}
+}
+
+func TestUpdateScheduleChangedInterval(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	source := newTestSource()
+	source.Spec.Schedule = ""
+	source.Spec.Interval = &metav1.Duration{Duration: 10 * time.Second}
+	id := mustAddSchedule(t, runner, source)
+
+	source = source.DeepCopy()
+	source.Spec.Interval.Duration = 30 * time.Second
+	updated, err := runner.UpdateSchedule(source)
+	if err != nil || updated == id {
+		t.Fatalf("Expected the schedule to be registered again, got (%d, %v)", updated, err)
+	}
+	start := time.Date(2020, 6, 1, 12, 0, 12, 0, time.UTC)
+	if next := runner.cron.Entry(updated).Schedule.Next(start); !next.Equal(start.Add(18 * time.Second)) {
+		t.Error("Expected the next fire at 12:00:30, got", next)
+	}
+}
+
+func TestUpdateScheduleNotAdded(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	id, err := runner.UpdateSchedule(newTestSource())
+	if err != nil {
+		t.Fatal("Expected the source to be scheduled, got", err)
+	}
+	runner.cron.Entry(id).Job.Run()
+	if got := len(ce.Sent()); got != 1 {
+		t.Errorf("Expected 1 event sent, got %d", got)
+	}
+
+	// Once removed, the source is added again.
+	if err := runner.RemoveSchedule(id); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got, err := runner.UpdateSchedule(newTestSource()); err != nil || got == id {
+		t.Errorf("Expected the removed source to be added with a new entry, got (%d, %v)", got, err)
+	}
+}
+
+func TestUpdateScheduleInvalid(t *testing.T) {
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+
+	source := newTestSource()
+	mustAddSchedule(t, runner, source)
+
+	source = source.DeepCopy()
+	source.Spec.FireCondition = "fireCount %"
+	if got, err := runner.UpdateSchedule(source); err == nil {
+		t.Errorf("Expected an invalid source not to be scheduled, got entry %d", got)
+	}
+	if got := len(runner.cron.Entries()); got != 0 {
+		t.Errorf("Expected the previous schedule to be removed, got %d entries", got)
+	}
+}
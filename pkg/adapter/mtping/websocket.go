@@ -0,0 +1,349 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	encbinary "encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+const (
+	// wsScheme and wssScheme are the sink URI schemes selecting the
+	// WebSocket protocol, in the clear and over TLS.
+	wsScheme  = "ws"
+	wssScheme = "wss"
+
+	// wsSubprotocol is the WebSocket subprotocol of the CloudEvents sent in
+	// structured mode, as JSON text messages.
+	wsSubprotocol = "cloudevents.json"
+
+	// wsAcceptGUID is appended to the key of the opening handshake to
+	// compute the accept value of the server, see RFC 6455 section 1.3.
+	wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// wsMaxFrameSize bounds the size of the frames read from the sinks.
+	wsMaxFrameSize = 1 << 20
+
+	// The opcodes of the frames, see RFC 6455 section 5.2.
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// wsSender pushes CloudEvents over WebSocket connections kept open between
+// the sends, one per sink, reconnecting when a connection is lost.
+type wsSender struct {
+	dialer net.Dialer
+
+	mu    sync.Mutex
+	conns map[string]*wsConn
+}
+
+// wsConn is a client WebSocket connection.
+type wsConn struct {
+	net.Conn
+	r *bufio.Reader
+
+	// wmu serializes the writes of the sends and of the replies to the
+	// control frames of the sink.
+	wmu sync.Mutex
+}
+
+// Send sends the event as a text message over the connection to target,
+// establishing it first if needed. A send failing over an established
+// connection is tried once more over a new connection.
+func (s *wsSender) Send(ctx context.Context, target *url.URL, event cloudevents.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := target.String()
+	conn, reused := s.conns[key]
+	for {
+		if conn == nil {
+			if conn, err = s.dial(ctx, target); err != nil {
+				return err
+			}
+			if s.conns == nil {
+				s.conns = make(map[string]*wsConn)
+			}
+			s.conns[key] = conn
+			go s.watch(key, conn)
+		}
+
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			deadline = time.Now().Add(30 * time.Second)
+		}
+		err := conn.writeFrame(wsOpText, payload, deadline)
+		if err == nil {
+			return nil
+		}
+
+		// The connection is lost, reconnect on the next attempt.
+		conn.Close()
+		delete(s.conns, key)
+		if !reused {
+			return err
+		}
+		conn, reused = nil, false
+	}
+}
+
+// watch reads the frames of the sink over conn until the connection is
+// lost, forgetting it then so that the next send reconnects. The messages
+// are discarded, the pings answered.
+func (s *wsSender) watch(key string, conn *wsConn) {
+	for {
+		op, payload, err := conn.readFrame()
+		if err != nil {
+			break
+		}
+		if op == wsOpPing {
+			_ = conn.writeFrame(wsOpPong, payload, time.Now().Add(30*time.Second))
+		} else if op == wsOpClose {
+			break
+		}
+	}
+	conn.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conns[key] == conn {
+		delete(s.conns, key)
+	}
+}
+
+// Close closes all the connections.
+func (s *wsSender) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, conn := range s.conns {
+		conn.Close()
+		delete(s.conns, key)
+	}
+}
+
+// dial opens a WebSocket connection to target, performing the opening
+// handshake of RFC 6455 section 4.1. The origin is the HTTP equivalent of
+// the target.
+func (s *wsSender) dial(ctx context.Context, target *url.URL) (*wsConn, error) {
+	origin := url.URL{Scheme: "http", Host: target.Host}
+	port := "80"
+	if target.Scheme == wssScheme {
+		origin.Scheme, port = "https", "443"
+	}
+	host := target.Host
+	if target.Port() == "" {
+		host = net.JoinHostPort(target.Hostname(), port)
+	}
+
+	dialer := s.dialer
+	if dialer.Timeout == 0 {
+		dialer.Timeout = 30 * time.Second
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+	if target.Scheme == wssScheme {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: target.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	ws := &wsConn{Conn: conn, r: bufio.NewReader(conn)}
+	if err := ws.handshake(target, origin.String()); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket handshake with %s failed: %w", target.Host, err)
+	}
+	// The deadlines of the writes are set by the sends, the reads wait for
+	// the sink.
+	_ = conn.SetDeadline(time.Time{})
+	return ws, nil
+}
+
+func (c *wsConn) handshake(target *url.URL, origin string) error {
+	nonce := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	u := *target
+	u.Scheme = "http"
+	if target.Scheme == wssScheme {
+		u.Scheme = "https"
+	}
+	req := &nethttp.Request{
+		Method:     nethttp.MethodGet,
+		URL:        &u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Host:       target.Host,
+		Header: nethttp.Header{
+			"Upgrade":                {"websocket"},
+			"Connection":             {"Upgrade"},
+			"Sec-WebSocket-Key":      {key},
+			"Sec-WebSocket-Version":  {"13"},
+			"Sec-WebSocket-Protocol": {wsSubprotocol},
+			"Origin":                 {origin},
+		},
+	}
+	if err := req.Write(c.Conn); err != nil {
+		return err
+	}
+
+	resp, err := nethttp.ReadResponse(c.r, req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1024))
+	resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusSwitchingProtocols {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return errors.New("the connection was not upgraded to websocket")
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		return errors.New("invalid Sec-WebSocket-Accept")
+	}
+	if p := resp.Header.Get("Sec-WebSocket-Protocol"); p != "" && p != wsSubprotocol {
+		return fmt.Errorf("unexpected subprotocol %q", p)
+	}
+	return nil
+}
+
+// wsAccept returns the accept value of the server for key.
+func wsAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// writeFrame writes payload in a single masked frame of the given opcode,
+// as the frames of the clients have to be, see RFC 6455 section 5.3.
+func (c *wsConn) writeFrame(op byte, payload []byte, deadline time.Time) error {
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | op // FIN
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = append(header, 0, 0)
+		encbinary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 127
+		header = append(header, make([]byte, 8)...)
+		encbinary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	header[1] |= 0x80 // MASK
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(rand.Reader, mask); err != nil {
+		return err
+	}
+	header = append(header, mask...)
+
+	frame := append(header, payload...)
+	for i := range payload {
+		frame[len(header)+i] ^= mask[i%4]
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_ = c.SetWriteDeadline(deadline)
+	_, err := c.Write(frame)
+	return err
+}
+
+// readFrame reads a frame, returning its opcode and unmasked payload. The
+// fragments of a message are returned as separate frames.
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return 0, nil, err
+	}
+	op, masked, n := header[0]&0x0F, header[1]&0x80 != 0, uint64(header[1]&0x7F)
+	switch n {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.r, ext); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(encbinary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.r, ext); err != nil {
+			return 0, nil, err
+		}
+		n = encbinary.BigEndian.Uint64(ext)
+	}
+	if n > wsMaxFrameSize {
+		return 0, nil, fmt.Errorf("websocket frame of %d bytes exceeds %d bytes", n, wsMaxFrameSize)
+	}
+
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.r, mask); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return op, payload, nil
+}
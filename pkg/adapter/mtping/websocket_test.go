@@ -0,0 +1,195 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtping
+
+import (
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+	rectesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertesting "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
+)
+
+// newFakeWebSocketSink returns a WebSocket server forwarding the received
+// events to events, and closing each connection after closeAfter messages
+// when positive.
+func newFakeWebSocketSink(events chan<- cloudevents.Event, protocols chan<- []string, connections *int32, closeAfter int) *httptest.Server {
+	return httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		conn, rw, err := w.(nethttp.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		atomic.AddInt32(connections, 1)
+		protocols <- r.Header["Sec-Websocket-Protocol"]
+		fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+			"Sec-WebSocket-Accept: %s\r\nSec-WebSocket-Protocol: %s\r\n\r\n", wsAccept(r.Header.Get("Sec-WebSocket-Key")), wsSubprotocol)
+
+		ws := &wsConn{Conn: conn, r: rw.Reader}
+		for received := 0; closeAfter <= 0 || received < closeAfter; {
+			op, payload, err := ws.readFrame()
+			if err != nil {
+				return
+			}
+			if op != wsOpText {
+				continue
+			}
+			var event cloudevents.Event
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return
+			}
+			events <- event
+			received++
+		}
+	}))
+}
+
+func TestWebSocketSink(t *testing.T) {
+	events := make(chan cloudevents.Event, 10)
+	protocols := make(chan []string, 10)
+	var connections int32
+	// The sink drops the connection after each event.
+	server := newFakeWebSocketSink(events, protocols, &connections, 1)
+	defer server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	ce := adaptertesting.NewTestClient()
+	runner := NewCronJobsRunner(ce, kubeclient.Get(ctx), logging.FromContext(ctx))
+	defer runner.ws.Close()
+
+	sinkURI, _ := apis.ParseURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Spec.JsonData = `{"msg":"hello"}`
+		s.Status.SinkURI = sinkURI
+	}))
+	job := runner.cron.Entry(entryID).Job
+
+	for fire := 1; fire <= 2; fire++ {
+		job.Run()
+
+		select {
+		case event := <-events:
+			if event.Type() != sourcesv1beta1.PingSourceEventType || event.Source() != sourcesv1beta1.PingSourceSource("test-ns", "test-name") {
+				t.Errorf("Unexpected event received: %v", event)
+			}
+			if got := string(event.Data()); got != `{"msg":"hello"}` {
+				t.Errorf("Expected the event data, got %q", got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the sink to receive the event of fire %d", fire)
+		}
+		if got := <-protocols; len(got) != 1 || got[0] != wsSubprotocol {
+			t.Errorf("Expected the %s subprotocol, got %v", wsSubprotocol, got)
+		}
+
+		// Wait for the sender to notice the connection was closed.
+		if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			runner.ws.mu.Lock()
+			defer runner.ws.mu.Unlock()
+			return len(runner.ws.conns) == 0, nil
+		}); err != nil {
+			t.Fatal("Expected the closed connection to be forgotten")
+		}
+	}
+
+	if got := atomic.LoadInt32(&connections); got != 2 {
+		t.Errorf("Expected the sender to reconnect, got %d connections", got)
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event sent over HTTP, got %d", got)
+	}
+}
+
+func TestWebSocketSinkReusesConnection(t *testing.T) {
+	events := make(chan cloudevents.Event, 10)
+	protocols := make(chan []string, 10)
+	var connections int32
+	server := newFakeWebSocketSink(events, protocols, &connections, 0)
+	defer server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	defer runner.ws.Close()
+
+	sinkURI, _ := apis.ParseURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+	}))
+	job := runner.cron.Entry(entryID).Job
+	for fire := 1; fire <= 3; fire++ {
+		job.Run()
+		select {
+		case <-events:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the sink to receive the event of fire %d", fire)
+		}
+	}
+
+	if got := atomic.LoadInt32(&connections); got != 1 {
+		t.Errorf("Expected the events to be sent over a single connection, got %d", got)
+	}
+}
+
+func TestWebSocketSinkUnreachable(t *testing.T) {
+	server := httptest.NewServer(nil)
+	sinkURI, _ := apis.ParseURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := runner.Stats().Sources["test-ns/test-name"].Failed; got != 1 {
+		t.Errorf("Expected the fire to fail, got %d failures", got)
+	}
+}
+
+func TestWebSocketSinkNotUpgraded(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.WriteHeader(nethttp.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, _ := rectesting.SetupFakeContext(t)
+	runner := NewCronJobsRunner(adaptertesting.NewTestClient(), kubeclient.Get(ctx), logging.FromContext(ctx))
+	sinkURI, _ := apis.ParseURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	entryID := mustAddSchedule(t, runner, newTestSource(func(s *sourcesv1beta1.PingSource) {
+		s.Status.SinkURI = sinkURI
+	}))
+	runner.cron.Entry(entryID).Job.Run()
+
+	if got := runner.Stats().Sources["test-ns/test-name"].Failed; got != 1 {
+		t.Errorf("Expected the fire to fail, got %d failures", got)
+	}
+}
@@ -0,0 +1,385 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	nethttp "net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/uuid"
+	"go.opencensus.io/resource"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+const (
+	// DefaultBatchLinger is how long a batch waits for more events when
+	// K_BATCH_LINGER isn't set.
+	DefaultBatchLinger = 100 * time.Millisecond
+
+	// batchIdleTimeout is how long the goroutine batching the events of a
+	// target lives without any event.
+	batchIdleTimeout = time.Minute
+)
+
+var (
+	// batchSizeM records the number of events of the batched requests sent
+	// by the adapters.
+	batchSizeM = stats.Int64(
+		"adapter_batch_size",
+		"Number of events of the batched requests sent by the adapter",
+		stats.UnitDimensionless,
+	)
+)
+
+func init() {
+	err := metrics.RegisterResourceView(&view.View{
+		Description: batchSizeM.Description(),
+		Measure:     batchSizeM,
+		Aggregation: view.Distribution(metrics.Buckets125(1, 1000)...), // 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000
+	})
+	if err != nil {
+		log.Printf("failed to register opencensus views, %s", err)
+	}
+}
+
+// batcher coalesces the events sent to the same target with the same retries
+// into CloudEvents batched mode requests of up to maxSize events, sent once
+// full or once the first event of the batch waited for linger. The events of a
+// target are queued while its previous batch is being sent, the senders
+// blocking once maxSize events are queued.
+type batcher struct {
+	// requester is the HTTP protocol of the client of the adapter, for the
+	// batches to be sent with its transport, timeout and tracing.
+	requester protocol.Requester
+	// target is the default target of the events, overridden by the target
+	// of their context.
+	target  string
+	maxSize int
+	linger  time.Duration
+	// source tags the batch sizes of the events not tagged with their
+	// source.
+	source MetricTag
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	targets map[batchKey]*targetBatcher
+}
+
+// batchKey keys the events batched together, the retries of a batch being
+// the ones of the context of its events.
+type batchKey struct {
+	target  string
+	retries cecontext.RetryParams
+}
+
+type batchedEvent struct {
+	ctx   context.Context
+	event cloudevents.Event
+	// done is called with the result of the delivery of the batch of the
+	// event, by the goroutine of its target.
+	done func(protocol.Result)
+}
+
+type targetBatcher struct {
+	batchKey
+	events chan batchedEvent
+	// pending is the number of events being queued, guarded by the mutex of
+	// the batcher, for the goroutine not to exit with events being queued.
+	pending int
+}
+
+func newBatcher(requester protocol.Requester, target string, maxSize int, linger time.Duration, source MetricTag,
+	logger *zap.SugaredLogger) *batcher {
+	return &batcher{
+		requester: requester,
+		target:    target,
+		maxSize:   maxSize,
+		linger:    linger,
+		source:    source,
+		logger:    logger,
+		targets:   make(map[batchKey]*targetBatcher),
+	}
+}
+
+// send queues the event for its target, returning the result of the delivery
+// of its batch once sent.
+func (b *batcher) send(ctx context.Context, out event.Event) protocol.Result {
+	result := make(chan protocol.Result, 1)
+	b.enqueue(ctx, out, func(res protocol.Result) {
+		result <- res
+	})
+	select {
+	case res := <-result:
+		return res
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue queues the event for its target, returning once queued, and calls
+// done with the result of the delivery of its batch once sent. It blocks only
+// while maxSize events of the target are queued, done being called with the
+// error of ctx if it is done before.
+func (b *batcher) enqueue(ctx context.Context, out event.Event, done func(protocol.Result)) {
+	key := batchKey{target: b.target, retries: *cecontext.RetriesFrom(ctx)}
+	if u := cecontext.TargetFrom(ctx); u != nil {
+		key.target = u.String()
+	}
+	if key.target == "" {
+		done(errors.New("the event has no target"))
+		return
+	}
+	if out.ID() == "" {
+		out.SetID(uuid.New().String())
+	}
+	if out.Time().IsZero() {
+		out.SetTime(time.Now())
+	}
+	if err := out.Validate(); err != nil {
+		done(err)
+		return
+	}
+
+	b.mu.Lock()
+	t, ok := b.targets[key]
+	if !ok {
+		t = &targetBatcher{batchKey: key, events: make(chan batchedEvent, b.maxSize)}
+		b.targets[key] = t
+		go b.run(t)
+	}
+	t.pending++
+	b.mu.Unlock()
+
+	select {
+	case t.events <- batchedEvent{ctx: ctx, event: out, done: done}:
+	case <-ctx.Done():
+		b.mu.Lock()
+		t.pending--
+		b.mu.Unlock()
+		done(ctx.Err())
+	}
+}
+
+// run sends the batches of the target until it is idle.
+func (b *batcher) run(t *targetBatcher) {
+	idle := time.NewTimer(batchIdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case first := <-t.events:
+			if !idle.Stop() {
+				<-idle.C
+			}
+			b.received(t)
+			b.sendBatch(t, b.collect(t, first))
+		case <-idle.C:
+			b.mu.Lock()
+			if t.pending == 0 {
+				delete(b.targets, t.batchKey)
+				b.mu.Unlock()
+				return
+			}
+			b.mu.Unlock()
+		}
+		idle.Reset(batchIdleTimeout)
+	}
+}
+
+func (b *batcher) received(t *targetBatcher) {
+	b.mu.Lock()
+	t.pending--
+	b.mu.Unlock()
+}
+
+// collect returns the batch starting with first, once full or once first
+// waited for the linger of the batcher.
+func (b *batcher) collect(t *targetBatcher, first batchedEvent) []batchedEvent {
+	batch := []batchedEvent{first}
+	linger := time.NewTimer(b.linger)
+	defer linger.Stop()
+	for len(batch) < b.maxSize {
+		select {
+		case e := <-t.events:
+			b.received(t)
+			batch = append(batch, e)
+		case <-linger.C:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (b *batcher) sendBatch(t *targetBatcher, batch []batchedEvent) {
+	events := make([]cloudevents.Event, 0, len(batch))
+	for _, e := range batch {
+		events = append(events, e.event)
+	}
+	b.reportBatchSize(batch[0].ctx, len(batch))
+
+	// The batch is traced and its backoff bound to the context of its first
+	// event.
+	ctx, span := trace.StartSpan(batch[0].ctx, observability.ClientSpanName, trace.WithSpanKind(trace.SpanKindClient))
+	span.AddAttributes(trace.Int64Attribute("cloudevents.batch.size", int64(len(batch))))
+	result := b.post(ctx, t, events)
+	if !cloudevents.IsACK(result) {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: result.Error()})
+		b.logger.Warnw("Failed to send a batch of events", zap.String("target", t.target),
+			zap.Int("size", len(batch)), zap.Error(result))
+	}
+	span.End()
+	for _, e := range batch {
+		e.done(result)
+	}
+}
+
+// post sends the events to the target in a CloudEvents batched mode request,
+// retrying like the CloudEvents HTTP protocol does with the retries of the
+// batch, and returning a result like it does.
+func (b *batcher) post(ctx context.Context, t *targetBatcher, events []cloudevents.Event) protocol.Result {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	// Each attempt is sent once by the protocol, for its request to be
+	// written again from the body.
+	ctx = cecontext.WithRetryParams(cecontext.WithTarget(ctx, t.target), &cecontext.RetryParams{})
+	switch t.retries.Strategy {
+	case cecontext.BackoffStrategyConstant, cecontext.BackoffStrategyLinear, cecontext.BackoffStrategyExponential:
+	default:
+		return b.postOnce(ctx, body)
+	}
+
+	then := time.Now()
+	var results []protocol.Result
+	for retry := 0; ; retry++ {
+		result := b.postOnce(ctx, body)
+		if cloudevents.IsACK(result) || !retryable(result) {
+			return http.NewRetriesResult(result, retry, then, results)
+		}
+		if err := t.retries.Backoff(ctx, retry+1); err != nil {
+			return http.NewRetriesResult(result, retry, then, results)
+		}
+		results = append(results, result)
+	}
+}
+
+func (b *batcher) postOnce(ctx context.Context, body []byte) protocol.Result {
+	resp, result := b.requester.Request(ctx, batchMessage(body))
+	if resp != nil {
+		// Closes the body for the connection to be reused.
+		_ = resp.Finish(nil)
+	}
+	return result
+}
+
+// batchMessage is the binding.Message of the JSON array of the events of a
+// batch, written as a structured message of the batched content mode.
+type batchMessage []byte
+
+var _ binding.Message = batchMessage(nil)
+
+func (batchMessage) ReadEncoding() binding.Encoding {
+	return binding.EncodingStructured
+}
+
+func (m batchMessage) ReadStructured(ctx context.Context, w binding.StructuredWriter) error {
+	return w.SetStructuredEvent(ctx, batchFormat{}, bytes.NewReader(m))
+}
+
+func (batchMessage) ReadBinary(context.Context, binding.BinaryWriter) error {
+	return binding.ErrNotBinary
+}
+
+func (batchMessage) Finish(error) error {
+	return nil
+}
+
+// batchFormat is the format of the batched content mode, only used for its
+// media type: the batches are marshalled as a whole.
+type batchFormat struct{}
+
+var _ format.Format = batchFormat{}
+
+func (batchFormat) MediaType() string {
+	return event.ApplicationCloudEventsBatchJSON
+}
+
+func (batchFormat) Marshal(*event.Event) ([]byte, error) {
+	return nil, errors.New("the events of a batch are marshalled together")
+}
+
+func (batchFormat) Unmarshal([]byte, *event.Event) error {
+	return errors.New("the events of a batch are unmarshalled together")
+}
+
+// retryable tells whether the CloudEvents HTTP protocol retries the failed
+// send with the result.
+func retryable(result protocol.Result) bool {
+	var uErr *url.Error
+	if errors.As(result, &uErr) {
+		return true
+	}
+	var res *http.Result
+	if errors.As(result, &res) {
+		switch res.StatusCode {
+		case nethttp.StatusNotFound, nethttp.StatusTooEarly, nethttp.StatusTooManyRequests,
+			nethttp.StatusServiceUnavailable, nethttp.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+// reportBatchSize records the size of a batch, for the source of ctx, the one
+// of the first event of the batch, when the adapter is multi-tenant.
+func (b *batcher) reportBatchSize(ctx context.Context, size int) {
+	source := b.source
+	if tag, ok := ctx.Value(metricKey{}).(*MetricTag); ok {
+		source = *tag
+	}
+	labels := map[string]string{
+		metricskey.LabelNamespaceName: source.Namespace,
+		metricskey.LabelName:          source.Name,
+	}
+	if source.ResourceGroup != "" {
+		labels[metricskey.LabelResourceGroup] = source.ResourceGroup
+	}
+	metrics.Record(metricskey.WithResource(context.Background(), resource.Resource{
+		Type:   metricskey.ResourceTypeKnativeSource,
+		Labels: labels,
+	}), batchSizeM.M(int64(size)))
+}
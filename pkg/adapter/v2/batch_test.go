@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/source"
+)
+
+// codesReporter reports the response codes of the events, counted by the
+// goroutines of the batcher.
+type codesReporter struct {
+	codes chan int
+}
+
+func (r *codesReporter) ReportEventCount(args *source.ReportArgs, responseCode int) error {
+	r.codes <- responseCode
+	return nil
+}
+
+func newBatchTestEvent(i int) cloudevents.Event {
+	e := cloudevents.NewEvent()
+	e.SetID("id-" + strconv.Itoa(i))
+	e.SetSource("unit/test")
+	e.SetType("unit.type")
+	return e
+}
+
+func newBatchTestClient(t *testing.T, sink string, maxSize int, linger string) (cloudevents.Client, *codesReporter) {
+	t.Helper()
+	reporter := &codesReporter{codes: make(chan int, 10)}
+	c, err := NewCloudEventsClientCRStatus(&EnvConfig{
+		Sink:           sink,
+		EnvSinkTimeout: "5",
+		BatchMaxSize:   maxSize,
+		BatchLinger:    linger,
+	}, reporter, nil)
+	if err != nil {
+		t.Fatal("NewCloudEventsClientCRStatus =", err)
+	}
+	return c, reporter
+}
+
+func wantCodes(t *testing.T, r *codesReporter, code, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case got := <-r.codes:
+			if got != code {
+				t.Errorf("Expected the response code %d, got %d", code, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected %d events to be reported, got %d", n, i)
+		}
+	}
+}
+
+// sendAll sends the events concurrently, returning their results.
+func sendAll(ctx context.Context, c cloudevents.Client, events ...cloudevents.Event) chan protocol.Result {
+	results := make(chan protocol.Result, len(events))
+	for _, e := range events {
+		go func(e cloudevents.Event) {
+			results <- c.Send(ctx, e)
+		}(e)
+	}
+	return results
+}
+
+func wantResults(t *testing.T, results chan protocol.Result, code, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case result := <-results:
+			var res *cehttp.Result
+			if !cloudevents.ResultAs(result, &res) || res.StatusCode != code {
+				t.Errorf("Expected the response code %d, got %v", code, result)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected %d sends to return, got %d", n, i)
+		}
+	}
+}
+
+func TestBatcher(t *testing.T) {
+	sizes := make(chan int, 10)
+	status := int32(http.StatusAccepted)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != event.ApplicationCloudEventsBatchJSON {
+			t.Errorf("Expected the content type %q, got %q", event.ApplicationCloudEventsBatchJSON, got)
+		}
+		var events []cloudevents.Event
+		if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+			t.Error("Decode =", err)
+		}
+		sizes <- len(events)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer srv.Close()
+
+	c, reporter := newBatchTestClient(t, srv.URL, 3, "1s")
+	// Full.
+	results := sendAll(context.Background(), c, newBatchTestEvent(0), newBatchTestEvent(1), newBatchTestEvent(2))
+	if got := <-sizes; got != 3 {
+		t.Errorf("Expected a batch of 3 events, got %d", got)
+	}
+	wantResults(t, results, http.StatusAccepted, 3)
+	wantCodes(t, reporter, http.StatusAccepted, 3)
+
+	// Lingering.
+	if result := c.Send(context.Background(), newBatchTestEvent(3)); !cloudevents.IsACK(result) {
+		t.Fatal("Send =", result)
+	}
+	if got := <-sizes; got != 1 {
+		t.Errorf("Expected a batch of 1 event, got %d", got)
+	}
+	wantCodes(t, reporter, http.StatusAccepted, 1)
+
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	if result := c.Send(context.Background(), newBatchTestEvent(4)); !cloudevents.IsNACK(result) {
+		t.Fatal("Expected the send to fail, got", result)
+	}
+	<-sizes
+	wantCodes(t, reporter, http.StatusServiceUnavailable, 1)
+}
+
+func TestBatcherRetries(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	c, reporter := newBatchTestClient(t, srv.URL, 2, "10ms")
+	ctx := cloudevents.ContextWithRetriesConstantBackoff(context.Background(), 10*time.Millisecond, 2)
+	results := sendAll(ctx, c, newBatchTestEvent(0), newBatchTestEvent(1))
+	wantResults(t, results, http.StatusAccepted, 2)
+	wantCodes(t, reporter, http.StatusAccepted, 2)
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Expected the batch to be sent 3 times, got %d", got)
+	}
+
+	// Out of retries.
+	atomic.StoreInt32(&requests, -10)
+	if result := c.Send(ctx, newBatchTestEvent(2)); !cloudevents.IsNACK(result) {
+		t.Error("Expected the send to fail, got", result)
+	}
+	if got := atomic.LoadInt32(&requests); got != -7 {
+		t.Errorf("Expected the batch to be sent 3 times, got %d", got+10)
+	}
+}
+
+func TestBatcherBackpressure(t *testing.T) {
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	c, reporter := newBatchTestClient(t, srv.URL, 2, "1s")
+	sent := sendAll(context.Background(), c, newBatchTestEvent(0), newBatchTestEvent(1))
+	<-received
+
+	// Queued while the full batch is being sent.
+	queued := sendAll(context.Background(), c, newBatchTestEvent(2), newBatchTestEvent(3))
+	b := c.(*client).batcher
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for _, t := range b.targets {
+			return len(t.events) == 2, nil
+		}
+		return false, nil
+	}); err != nil {
+		t.Fatal("Expected 2 events to be queued:", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if result := c.Send(ctx, newBatchTestEvent(4)); cloudevents.IsACK(result) {
+		t.Error("Expected the send to block while the queue is full")
+	}
+	// Counted without a response code.
+	wantCodes(t, reporter, 0, 1)
+
+	close(release)
+	wantResults(t, sent, http.StatusAccepted, 2)
+	wantResults(t, queued, http.StatusAccepted, 2)
+	wantCodes(t, reporter, http.StatusAccepted, 4)
+}
+
+func TestBatcherSendAsync(t *testing.T) {
+	sizes := make(chan int, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var events []cloudevents.Event
+		if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+			t.Error("Decode =", err)
+		}
+		sizes <- len(events)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	c, reporter := newBatchTestClient(t, srv.URL, 3, "1s")
+	// The events sent one after the other are batched together.
+	results := make(chan protocol.Result, 3)
+	for i := 0; i < 3; i++ {
+		c.(AsyncSender).SendAsync(context.Background(), newBatchTestEvent(i), func(result protocol.Result) {
+			results <- result
+		})
+	}
+	if got := <-sizes; got != 3 {
+		t.Errorf("Expected a batch of 3 events, got %d", got)
+	}
+	wantResults(t, results, http.StatusAccepted, 3)
+	wantCodes(t, reporter, http.StatusAccepted, 3)
+}
+
+func TestGetBatchConfig(t *testing.T) {
+	if size, linger := (&EnvConfig{}).GetBatchConfig(); size != 0 || linger != DefaultBatchLinger {
+		t.Errorf("Expected no batching lingering %v, got %d events lingering %v", DefaultBatchLinger, size, linger)
+	}
+	if size, linger := (&EnvConfig{BatchMaxSize: 10, BatchLinger: "1s"}).GetBatchConfig(); size != 10 || linger != time.Second {
+		t.Errorf("Expected 10 events lingering 1s, got %d events lingering %v", size, linger)
+	}
+}
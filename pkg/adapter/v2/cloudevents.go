@@ -24,6 +24,7 @@ import (
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
@@ -49,18 +50,32 @@ func newCloudEventsClientCRStatus(env EnvConfigAccessor, target string, ceOverri
 		target = env.GetSink()
 	}
 
+	var namespace, name string
+
 	pOpts := make([]http.Option, 0)
 	if len(target) > 0 {
 		pOpts = append(pOpts, cloudevents.WithTarget(target))
 	}
-	pOpts = append(pOpts, cloudevents.WithRoundTripper(&ochttp.Transport{
+	transport := &ochttp.Transport{
 		Propagation: tracecontextb3.TraceContextEgress,
-	}))
+	}
+	pOpts = append(pOpts, cloudevents.WithRoundTripper(transport))
 
+	var refreshedSink *sinkFile
+	var batchMaxSize int
+	var batchLinger, timeout time.Duration
 	if env != nil {
+		if path := env.GetSinkFile(); path != "" {
+			refreshedSink = &sinkFile{watchedFile{path: path}}
+		}
+		if path := env.GetCACertsFile(); path != "" {
+			transport.Base = &caCertsTransport{file: watchedFile{path: path}}
+		}
 		if sinkWait := env.GetSinktimeout(); sinkWait > 0 {
-			pOpts = append(pOpts, setTimeOut(time.Duration(sinkWait)*time.Second))
+			timeout = time.Duration(sinkWait) * time.Second
+			pOpts = append(pOpts, setTimeOut(timeout))
 		}
+		batchMaxSize, batchLinger = env.GetBatchConfig()
 		var err error
 		if ceOverrides == nil {
 			ceOverrides, err = env.GetCloudEventOverrides()
@@ -68,6 +83,11 @@ func newCloudEventsClientCRStatus(env EnvConfigAccessor, target string, ceOverri
 				return nil, err
 			}
 		}
+		namespace, name = env.GetNamespace(), env.GetName()
+	}
+	overrides, err := NewOverrides(ceOverrides)
+	if err != nil {
+		return nil, err
 	}
 
 	p, err := cloudevents.NewHTTP(pOpts...)
@@ -83,12 +103,21 @@ func newCloudEventsClientCRStatus(env EnvConfigAccessor, target string, ceOverri
 	if err != nil {
 		return nil, err
 	}
-	return &client{
+	c := &client{
 		ceClient:            ceClient,
-		ceOverrides:         ceOverrides,
+		ceOverrides:         overrides,
+		namespace:           namespace,
+		name:                name,
+		sinkFile:            refreshedSink,
 		reporter:            reporter,
 		crStatusEventClient: *crStatusEventClient,
-	}, nil
+	}
+	if batchMaxSize > 1 {
+		logger := env.GetLogger()
+		logger.Infof("Batching up to %d events lingering %v", batchMaxSize, batchLinger)
+		c.batcher = newBatcher(p, target, batchMaxSize, batchLinger, MetricTag{Namespace: namespace, Name: name}, logger)
+	}
+	return c, nil
 }
 
 func setTimeOut(duration time.Duration) http.Option {
@@ -106,23 +135,64 @@ func setTimeOut(duration time.Duration) http.Option {
 
 type client struct {
 	ceClient            cloudevents.Client
-	ceOverrides         *duckv1.CloudEventOverrides
+	ceOverrides         *Overrides
 	reporter            source.StatsReporter
 	crStatusEventClient crstatusevent.CRStatusEventClient
+
+	// namespace and name are the ones of the source of the adapter, when
+	// known.
+	namespace, name string
+	// sinkFile overrides the sink with the one refreshed by a SinkBinding.
+	// Optional.
+	sinkFile *sinkFile
+	// batcher coalesces the sent events into batched requests. Optional.
+	batcher *batcher
 }
 
 var _ cloudevents.Client = (*client)(nil)
 
-// Send implements client.Send
+// AsyncSender is implemented by the clients able to send the events without
+// waiting for their delivery, like the ones batching them.
+type AsyncSender interface {
+	// SendAsync sends the event like Send, calling done with its result.
+	// When the events are not batched, it returns once done is called.
+	SendAsync(ctx context.Context, out event.Event, done func(protocol.Result))
+}
+
+var _ AsyncSender = (*client)(nil)
+
+// Send implements client.Send. When batching, the result is the one of the
+// batch of the event.
 func (c *client) Send(ctx context.Context, out event.Event) protocol.Result {
-	c.applyOverrides(&out)
-	res := c.ceClient.Send(ctx, out)
+	c.applyOverrides(ctx, &out)
+	ctx = c.withRefreshedSink(ctx)
+	var res protocol.Result
+	if c.batcher != nil {
+		res = c.batcher.send(ctx, out)
+	} else {
+		res = c.ceClient.Send(ctx, out)
+	}
 	return c.reportCount(ctx, out, res)
 }
 
-// Request implements client.Request
+// SendAsync implements AsyncSender, returning once the event is queued for
+// its batch when batching.
+func (c *client) SendAsync(ctx context.Context, out event.Event, done func(protocol.Result)) {
+	if c.batcher == nil {
+		done(c.Send(ctx, out))
+		return
+	}
+	c.applyOverrides(ctx, &out)
+	ctx = c.withRefreshedSink(ctx)
+	c.batcher.enqueue(ctx, out, func(res protocol.Result) {
+		done(c.reportCount(ctx, out, res))
+	})
+}
+
+// Request implements client.Request, the events being never batched.
 func (c *client) Request(ctx context.Context, out event.Event) (*event.Event, protocol.Result) {
-	c.applyOverrides(&out)
+	c.applyOverrides(ctx, &out)
+	ctx = c.withRefreshedSink(ctx)
 	resp, res := c.ceClient.Request(ctx, out)
 	return resp, c.reportCount(ctx, out, res)
 }
@@ -132,12 +202,31 @@ func (c *client) StartReceiver(ctx context.Context, fn interface{}) error {
 	return errors.New("not implemented")
 }
 
-func (c *client) applyOverrides(event *cloudevents.Event) {
-	if c.ceOverrides != nil && c.ceOverrides.Extensions != nil {
-		for n, v := range c.ceOverrides.Extensions {
-			event.SetExtension(n, v)
-		}
+// withRefreshedSink targets the refreshed sink, unless ctx has a target.
+func (c *client) withRefreshedSink(ctx context.Context) context.Context {
+	if c.sinkFile == nil || cecontext.TargetFrom(ctx) != nil {
+		return ctx
+	}
+	if sink := c.sinkFile.sink(); sink != "" {
+		return cloudevents.ContextWithTarget(ctx, sink)
+	}
+	return ctx
+}
+
+func (c *client) applyOverrides(ctx context.Context, event *cloudevents.Event) {
+	values := OverrideValues{
+		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		SourceNamespace: c.namespace,
+		SourceName:      c.name,
+	}
+	if tick, ok := ScheduleTickFromContext(ctx); ok {
+		values.ScheduleTick = tick.UTC().Format(time.RFC3339)
+	}
+	// The multi-tenant adapters tag the sends with their source.
+	if tag, ok := ctx.Value(metricKey{}).(*MetricTag); ok {
+		values.SourceNamespace, values.SourceName = tag.Namespace, tag.Name
 	}
+	c.ceOverrides.Apply(event, values)
 }
 
 func (c *client) reportCount(ctx context.Context, event cloudevents.Event, result protocol.Result) error {
@@ -19,9 +19,11 @@ import (
 	"encoding/json"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	kle "knative.dev/pkg/leaderelection"
@@ -29,6 +31,8 @@ import (
 	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/tracing"
 	tracingconfig "knative.dev/pkg/tracing/config"
+
+	"knative.dev/eventing/pkg/apis/feature"
 )
 
 type EnvConfigConstructor func() EnvConfigAccessor
@@ -45,6 +49,14 @@ const (
 	EnvConfigTracingConfig        = "K_TRACING_CONFIG"
 	EnvConfigLeaderElectionConfig = "K_LEADER_ELECTION_CONFIG"
 	EnvSinkTimeout                = "K_SINK_TIMEOUT"
+	EnvConfigSinkFile             = "K_SINK_FILE"
+	EnvConfigCACertsFile          = "K_CA_CERTS_FILE"
+	EnvConfigEventTypeAutoCreate  = "K_EVENTTYPE_AUTO_CREATE"
+	EnvConfigEventTypeOwner       = "K_EVENTTYPE_OWNER"
+	EnvConfigEventTransformers    = "K_EVENT_TRANSFORMERS"
+	EnvConfigDeliveryStatus       = "K_DELIVERY_STATUS"
+	EnvConfigBatchMaxSize         = "K_BATCH_MAX_SIZE"
+	EnvConfigBatchLinger          = "K_BATCH_LINGER"
 )
 
 // EnvConfig is the minimal set of configuration parameters
@@ -91,6 +103,43 @@ type EnvConfig struct {
 	// Time in seconds to wait for sink to respond
 	EnvSinkTimeout string `envconfig:"K_SINK_TIMEOUT"`
 
+	// SinkFile is the path of a file holding the sink, kept up to date by
+	// the SinkBindings refreshing their sink. It takes precedence over Sink
+	// once readable.
+	SinkFile string `envconfig:"K_SINK_FILE"`
+
+	// CACertsFile is the path of a file holding the PEM encoded CA
+	// certificates of the sink, kept up to date like SinkFile.
+	CACertsFile string `envconfig:"K_CA_CERTS_FILE"`
+
+	// EventTypeAutoCreate is the eventtype-auto-create flag of
+	// config-features, the adapter creating the EventTypes of the events it
+	// sends when enabled.
+	EventTypeAutoCreate string `envconfig:"K_EVENTTYPE_AUTO_CREATE"`
+
+	// EventTypeOwnerJson is a json string of the metav1.OwnerReference of
+	// the source owning the EventTypes, in the namespace of the adapter.
+	// The multi-tenant adapters set their owners per event instead.
+	EventTypeOwnerJson string `envconfig:"K_EVENTTYPE_OWNER"`
+
+	// EventTransformersJson is a json string of the TransformRules of
+	// config-event-transformers, applied to the events before sending them.
+	EventTransformersJson string `envconfig:"K_EVENT_TRANSFORMERS"`
+
+	// DeliveryStatus is the resource group of the source, like
+	// apiserversources.sources.knative.dev, the adapter reporting the
+	// delivery status of the source to a Lease when set.
+	DeliveryStatus string `envconfig:"K_DELIVERY_STATUS"`
+
+	// BatchMaxSize is the maximum number of events coalesced into a
+	// CloudEvents batched mode request, the events being sent one by one
+	// when lower than 2.
+	BatchMaxSize int `envconfig:"K_BATCH_MAX_SIZE"`
+
+	// BatchLinger is how long a batch waits for more events before being
+	// sent, like 100ms.
+	BatchLinger string `envconfig:"K_BATCH_LINGER"`
+
 	// cached zap logger
 	logger *zap.SugaredLogger
 }
@@ -125,6 +174,35 @@ type EnvConfigAccessor interface {
 
 	// Get the timeout to apply on a request to a sink
 	GetSinktimeout() int
+
+	// GetSinkFile returns the path of the file holding the refreshed sink,
+	// empty when not refreshed.
+	GetSinkFile() string
+
+	// GetCACertsFile returns the path of the file holding the CA
+	// certificates of the sink, empty when not set.
+	GetCACertsFile() string
+
+	// IsEventTypeAutoCreate tells whether the adapter creates the
+	// EventTypes of the events it sends.
+	IsEventTypeAutoCreate() bool
+
+	// GetEventTypeOwner returns the owner of the EventTypes, nil when not
+	// set.
+	GetEventTypeOwner() (*metav1.OwnerReference, error)
+
+	// GetEventTransformRules returns the rules of the transformer applied
+	// to the events before sending them.
+	GetEventTransformRules() ([]TransformRule, error)
+
+	// GetDeliveryStatusResourceGroup returns the resource group of the
+	// source whose delivery status is reported, empty when not reported.
+	GetDeliveryStatusResourceGroup() string
+
+	// GetBatchConfig returns the maximum number of events of the batched
+	// requests, lower than 2 when the events aren't batched, and how long
+	// the batches wait for more events.
+	GetBatchConfig() (int, time.Duration)
 }
 
 var _ EnvConfigAccessor = (*EnvConfig)(nil)
@@ -160,6 +238,11 @@ func (e *EnvConfig) GetLogger() *zap.SugaredLogger {
 }
 
 func (e *EnvConfig) GetSink() string {
+	if e.SinkFile != "" {
+		if sink := (&sinkFile{watchedFile{path: e.SinkFile}}).sink(); sink != "" {
+			return sink
+		}
+	}
 	return e.Sink
 }
 
@@ -179,6 +262,52 @@ func (e *EnvConfig) GetSinktimeout() int {
 	return -1
 }
 
+func (e *EnvConfig) GetSinkFile() string {
+	return e.SinkFile
+}
+
+func (e *EnvConfig) GetCACertsFile() string {
+	return e.CACertsFile
+}
+
+func (e *EnvConfig) IsEventTypeAutoCreate() bool {
+	return feature.Flag(strings.ToLower(e.EventTypeAutoCreate)) == feature.Enabled
+}
+
+func (e *EnvConfig) GetEventTypeOwner() (*metav1.OwnerReference, error) {
+	if e.EventTypeOwnerJson == "" {
+		return nil, nil
+	}
+	var owner metav1.OwnerReference
+	if err := json.Unmarshal([]byte(e.EventTypeOwnerJson), &owner); err != nil {
+		return nil, err
+	}
+	return &owner, nil
+}
+
+func (e *EnvConfig) GetEventTransformRules() ([]TransformRule, error) {
+	if e.EventTransformersJson == "" {
+		return nil, nil
+	}
+	return ParseTransformRules(e.EventTransformersJson)
+}
+
+func (e *EnvConfig) GetDeliveryStatusResourceGroup() string {
+	return e.DeliveryStatus
+}
+
+func (e *EnvConfig) GetBatchConfig() (int, time.Duration) {
+	linger := DefaultBatchLinger
+	if e.BatchLinger != "" {
+		if d, err := time.ParseDuration(e.BatchLinger); err == nil && d > 0 {
+			linger = d
+		} else {
+			e.GetLogger().Warnf("Batch linger configuration is invalid, default to %v", DefaultBatchLinger)
+		}
+	}
+	return e.BatchMaxSize, linger
+}
+
 func (e *EnvConfig) SetupTracing(logger *zap.SugaredLogger) error {
 	config, err := tracingconfig.JSONToTracingConfig(e.TracingConfigJson)
 	if err != nil {
@@ -246,3 +375,31 @@ func GetSinkTimeout(logger *zap.SugaredLogger) int {
 	}
 	return -1
 }
+
+// GetBatching returns the batching of the events of the adapters, read from
+// the environment of the controller: the maximum number of events of the
+// batched requests, 0 when the events aren't batched, and the linger of the
+// batches, empty for the default.
+func GetBatching(logger *zap.SugaredLogger) (int, string) {
+	str := os.Getenv(EnvConfigBatchMaxSize)
+	if str == "" {
+		return 0, ""
+	}
+	maxSize, err := strconv.Atoi(str)
+	if err != nil || maxSize < 0 {
+		if logger != nil {
+			logger.Errorf("%s environment value is invalid. It must be a positive integer. (got %s)", EnvConfigBatchMaxSize, str)
+		}
+		return 0, ""
+	}
+	linger := os.Getenv(EnvConfigBatchLinger)
+	if linger != "" {
+		if d, err := time.ParseDuration(linger); err != nil || d <= 0 {
+			if logger != nil {
+				logger.Errorf("%s environment value is invalid. It must be a positive duration. (got %s)", EnvConfigBatchLinger, linger)
+			}
+			linger = ""
+		}
+	}
+	return maxSize, linger
+}
@@ -19,6 +19,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -92,3 +93,46 @@ func TestEmptySinkTimeout(t *testing.T) {
 		t.Error("Expected env.EnvSinkTimeout to be -1, got:", env.GetSinktimeout())
 	}
 }
+
+func TestEventTypeEnvConfig(t *testing.T) {
+	os.Setenv("K_EVENTTYPE_AUTO_CREATE", "enabled")
+	os.Setenv("K_EVENTTYPE_OWNER", `{"apiVersion":"sources.knative.dev/v1","kind":"ApiServerSource","name":"source","uid":"1234"}`)
+	defer func() {
+		os.Unsetenv("K_EVENTTYPE_AUTO_CREATE")
+		os.Unsetenv("K_EVENTTYPE_OWNER")
+	}()
+
+	var env myEnvConfig
+	if err := envconfig.Process("", &env); err != nil {
+		t.Error("Expected no error:", err)
+	}
+
+	if !env.IsEventTypeAutoCreate() {
+		t.Error("Expected the EventType creation to be enabled")
+	}
+	owner, err := env.GetEventTypeOwner()
+	if err != nil {
+		t.Fatal("Expected no error:", err)
+	}
+	if owner == nil || owner.Kind != "ApiServerSource" || owner.UID != "1234" {
+		t.Error("Unexpected owner:", owner)
+	}
+}
+
+func TestEventTransformersEnvConfig(t *testing.T) {
+	os.Setenv("K_EVENT_TRANSFORMERS", `[{"remove":"$.data.password"}]`)
+	defer os.Unsetenv("K_EVENT_TRANSFORMERS")
+
+	var env myEnvConfig
+	if err := envconfig.Process("", &env); err != nil {
+		t.Error("Expected no error:", err)
+	}
+
+	rules, err := env.GetEventTransformRules()
+	if err != nil {
+		t.Fatal("Expected no error:", err)
+	}
+	if diff := cmp.Diff([]TransformRule{{Remove: "$.data.password"}}, rules); diff != "" {
+		t.Error("unexpected rules (-want, +got) =", diff)
+	}
+}
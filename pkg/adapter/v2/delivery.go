@@ -0,0 +1,298 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/kmeta"
+)
+
+const (
+	// DeliveryStatusLabelKey labels the delivery status Leases with the
+	// resource group of their source.
+	DeliveryStatusLabelKey = "sources.knative.dev/delivery-status"
+	// DeliverySourceAnnotationKey is the annotation of the delivery status
+	// Leases holding the name of their source.
+	DeliverySourceAnnotationKey = "sources.knative.dev/delivery-source"
+	// ConsecutiveFailuresAnnotationKey is the annotation of the delivery
+	// status Leases holding the number of deliveries failing in a row.
+	ConsecutiveFailuresAnnotationKey = "sources.knative.dev/consecutive-failures"
+	// LastErrorAnnotationKey is the annotation of the delivery status Leases
+	// holding the error of the last delivery failing.
+	LastErrorAnnotationKey = "sources.knative.dev/last-error"
+	// LastErrorTimeAnnotationKey is the annotation of the delivery status
+	// Leases holding the time of the last delivery failing.
+	LastErrorTimeAnnotationKey = "sources.knative.dev/last-error-time"
+
+	// deliveryStatusInterval is how often the changed delivery statuses are
+	// written to their Leases.
+	deliveryStatusInterval = 10 * time.Second
+	// maxLastErrorLength bounds the length of the last error annotation.
+	maxLastErrorLength = 1024
+)
+
+// DeliveryStatus is the health of the deliveries of a source to its sink.
+type DeliveryStatus struct {
+	// ConsecutiveFailures is the number of deliveries failing in a row,
+	// zero once a delivery succeeds.
+	ConsecutiveFailures int
+	// LastError is the error of the last delivery failing.
+	LastError string
+	// LastErrorTime is the time of the last delivery failing.
+	LastErrorTime time.Time
+}
+
+// DeliveryStatusLeaseName returns the name of the Lease holding the delivery
+// status of the source of the resource group, like pingsources.sources.knative.dev.
+func DeliveryStatusLeaseName(resourceGroup, name string) string {
+	resource := strings.SplitN(resourceGroup, ".", 2)[0]
+	return kmeta.ChildName(name, "-"+resource+"-delivery")
+}
+
+// DeliveryStatusFromLease returns the delivery status held by the Lease.
+func DeliveryStatusFromLease(lease *coordinationv1.Lease) DeliveryStatus {
+	var status DeliveryStatus
+	status.ConsecutiveFailures, _ = strconv.Atoi(lease.Annotations[ConsecutiveFailuresAnnotationKey])
+	status.LastError = lease.Annotations[LastErrorAnnotationKey]
+	status.LastErrorTime, _ = time.Parse(time.RFC3339, lease.Annotations[LastErrorTimeAnnotationKey])
+	return status
+}
+
+// DeliveryStatusReporter reports the delivery statuses of the sources of a
+// resource group to Leases in the namespaces of the sources, for the source
+// reconcilers to propagate them to the statuses of the sources. The Leases
+// are only written when the statuses change, serving as a heartbeat of the
+// failing deliveries.
+type DeliveryStatusReporter struct {
+	kubeClient    kubernetes.Interface
+	resourceGroup string
+	holder        string
+	logger        *zap.SugaredLogger
+
+	mu      sync.Mutex
+	sources map[types.NamespacedName]*deliveryState
+}
+
+type deliveryState struct {
+	status DeliveryStatus
+	// owner owns the Lease, for it to be deleted with the source. Optional.
+	owner *metav1.OwnerReference
+	dirty bool
+}
+
+// NewDeliveryStatusReporter returns a DeliveryStatusReporter writing the
+// Leases of the sources of resourceGroup with kubeClient.
+func NewDeliveryStatusReporter(kubeClient kubernetes.Interface, resourceGroup string, logger *zap.SugaredLogger) *DeliveryStatusReporter {
+	holder, _ := os.Hostname()
+	return &DeliveryStatusReporter{
+		kubeClient:    kubeClient,
+		resourceGroup: resourceGroup,
+		holder:        holder,
+		logger:        logger,
+		sources:       make(map[types.NamespacedName]*deliveryState),
+	}
+}
+
+// Report records the result of a delivery of the source, err being nil when
+// it succeeded. The Lease of the source is owned by owner when not nil.
+func (r *DeliveryStatusReporter) Report(source types.NamespacedName, owner *metav1.OwnerReference, err error) {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sources[source]
+	if !ok {
+		s = &deliveryState{dirty: true}
+		r.sources[source] = s
+	}
+	if owner != nil {
+		s.owner = owner
+	}
+	if err == nil {
+		if s.status.ConsecutiveFailures > 0 {
+			s.status.ConsecutiveFailures = 0
+			s.dirty = true
+		}
+		return
+	}
+	s.status.ConsecutiveFailures++
+	s.status.LastError = err.Error()
+	if len(s.status.LastError) > maxLastErrorLength {
+		s.status.LastError = s.status.LastError[:maxLastErrorLength]
+	}
+	s.status.LastErrorTime = now
+	s.dirty = true
+}
+
+// ReportContext records the result of a delivery of a multi-tenant adapter,
+// for the source of the MetricTag of ctx. The Lease of the source is owned by
+// the owner of the EventTypes of ctx, when set.
+func (r *DeliveryStatusReporter) ReportContext(ctx context.Context, err error) {
+	tag, ok := ctx.Value(metricKey{}).(*MetricTag)
+	if !ok {
+		return
+	}
+	var owner *metav1.OwnerReference
+	if o := eventTypeOwnerFromContext(ctx); o != nil && o.namespace == tag.Namespace {
+		ref := o.owner
+		owner = &ref
+	}
+	r.Report(types.NamespacedName{Namespace: tag.Namespace, Name: tag.Name}, owner, err)
+}
+
+// Forget forgets the delivery status of the source, no longer delivered by
+// the adapter.
+func (r *DeliveryStatusReporter) Forget(source types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sources, source)
+}
+
+// Start writes the changed delivery statuses to their Leases until ctx is
+// done.
+func (r *DeliveryStatusReporter) Start(ctx context.Context) {
+	ticker := time.NewTicker(deliveryStatusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.flush(ctx)
+		}
+	}
+}
+
+// flush writes the changed delivery statuses to their Leases, the ones
+// failing to be written being written again on the next flush.
+func (r *DeliveryStatusReporter) flush(ctx context.Context) {
+	changed := make(map[types.NamespacedName]deliveryState)
+	r.mu.Lock()
+	for source, s := range r.sources {
+		if s.dirty {
+			changed[source] = *s
+			s.dirty = false
+		}
+	}
+	r.mu.Unlock()
+
+	for source, s := range changed {
+		if err := r.write(ctx, source, s); err != nil {
+			r.logger.Warnw("Failed to write the delivery status", zap.String("source", source.String()), zap.Error(err))
+			r.mu.Lock()
+			if s, ok := r.sources[source]; ok {
+				s.dirty = true
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *DeliveryStatusReporter) write(ctx context.Context, source types.NamespacedName, s deliveryState) error {
+	leases := r.kubeClient.CoordinationV1().Leases(source.Namespace)
+	lease, err := leases.Get(ctx, DeliveryStatusLeaseName(r.resourceGroup, source.Name), metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DeliveryStatusLeaseName(r.resourceGroup, source.Name),
+				Namespace: source.Namespace,
+				Labels:    map[string]string{DeliveryStatusLabelKey: r.resourceGroup},
+			},
+		}
+		r.setStatus(lease, source, s)
+		_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	lease = lease.DeepCopy()
+	r.setStatus(lease, source, s)
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+func (r *DeliveryStatusReporter) setStatus(lease *coordinationv1.Lease, source types.NamespacedName, s deliveryState) {
+	if lease.Annotations == nil {
+		lease.Annotations = make(map[string]string)
+	}
+	lease.Annotations[DeliverySourceAnnotationKey] = source.Name
+	lease.Annotations[ConsecutiveFailuresAnnotationKey] = strconv.Itoa(s.status.ConsecutiveFailures)
+	if !s.status.LastErrorTime.IsZero() {
+		lease.Annotations[LastErrorAnnotationKey] = s.status.LastError
+		lease.Annotations[LastErrorTimeAnnotationKey] = s.status.LastErrorTime.UTC().Format(time.RFC3339)
+	}
+	if s.owner != nil {
+		lease.OwnerReferences = []metav1.OwnerReference{*s.owner}
+	}
+	now := metav1.NewMicroTime(time.Now())
+	lease.Spec.HolderIdentity = &r.holder
+	lease.Spec.RenewTime = &now
+}
+
+// deliveryStatusClient reports the results of the deliveries of the wrapped
+// client, sending the events of a single source.
+type deliveryStatusClient struct {
+	cloudevents.Client
+	reporter *DeliveryStatusReporter
+	source   types.NamespacedName
+	owner    *metav1.OwnerReference
+}
+
+// NewDeliveryStatusClient returns a client reporting the results of the
+// deliveries of client, for the source owning the Lease when owner isn't nil.
+func NewDeliveryStatusClient(client cloudevents.Client, reporter *DeliveryStatusReporter, source types.NamespacedName, owner *metav1.OwnerReference) cloudevents.Client {
+	return &deliveryStatusClient{
+		Client:   client,
+		reporter: reporter,
+		source:   source,
+		owner:    owner,
+	}
+}
+
+func (c *deliveryStatusClient) Send(ctx context.Context, out event.Event) protocol.Result {
+	result := c.Client.Send(ctx, out)
+	c.report(result)
+	return result
+}
+
+func (c *deliveryStatusClient) Request(ctx context.Context, out event.Event) (*event.Event, protocol.Result) {
+	resp, result := c.Client.Request(ctx, out)
+	c.report(result)
+	return resp, result
+}
+
+func (c *deliveryStatusClient) report(result protocol.Result) {
+	if cloudevents.IsACK(result) {
+		c.reporter.Report(c.source, c.owner, nil)
+	} else {
+		c.reporter.Report(c.source, c.owner, result)
+	}
+}
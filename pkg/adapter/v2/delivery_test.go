@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	logtesting "knative.dev/pkg/logging/testing"
+
+	"knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+const testResourceGroup = "pingsources.sources.knative.dev"
+
+func getDeliveryStatus(t *testing.T, r *DeliveryStatusReporter, source types.NamespacedName) DeliveryStatus {
+	t.Helper()
+	lease, err := r.kubeClient.CoordinationV1().Leases(source.Namespace).Get(context.Background(),
+		DeliveryStatusLeaseName(testResourceGroup, source.Name), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Get =", err)
+	}
+	if got := lease.Labels[DeliveryStatusLabelKey]; got != testResourceGroup {
+		t.Errorf("Expected the label %q, got %q", testResourceGroup, got)
+	}
+	if got := lease.Annotations[DeliverySourceAnnotationKey]; got != source.Name {
+		t.Errorf("Expected the source %q, got %q", source.Name, got)
+	}
+	return DeliveryStatusFromLease(lease)
+}
+
+func TestDeliveryStatusReporter(t *testing.T) {
+	ctx := context.Background()
+	source := types.NamespacedName{Namespace: "ns", Name: "source"}
+	owner := metav1.OwnerReference{APIVersion: "sources.knative.dev/v1beta1", Kind: "PingSource", Name: "source", UID: "uid-1"}
+	r := NewDeliveryStatusReporter(kubefake.NewSimpleClientset(), testResourceGroup, logtesting.TestLogger(t))
+
+	r.Report(source, &owner, errors.New("boom"))
+	r.Report(source, nil, errors.New("bang"))
+	r.flush(ctx)
+
+	status := getDeliveryStatus(t, r, source)
+	if status.ConsecutiveFailures != 2 || status.LastError != "bang" || status.LastErrorTime.IsZero() {
+		t.Errorf("Unexpected delivery status %+v", status)
+	}
+	lease, _ := r.kubeClient.CoordinationV1().Leases("ns").Get(ctx, DeliveryStatusLeaseName(testResourceGroup, "source"), metav1.GetOptions{})
+	if diff := cmp.Diff([]metav1.OwnerReference{owner}, lease.OwnerReferences); diff != "" {
+		t.Error("unexpected owners (-want, +got) =", diff)
+	}
+
+	r.Report(source, nil, nil)
+	r.flush(ctx)
+	if status := getDeliveryStatus(t, r, source); status.ConsecutiveFailures != 0 || status.LastError != "bang" {
+		t.Errorf("Unexpected delivery status after a success %+v", status)
+	}
+
+	// Not changed, not written again.
+	r.Report(source, nil, nil)
+	if r.sources[source].dirty {
+		t.Error("Expected an unchanged delivery status not to be written")
+	}
+
+	r.Forget(source)
+	if _, ok := r.sources[source]; ok {
+		t.Error("Expected the delivery status to be forgotten")
+	}
+}
+
+func TestDeliveryStatusReporterContext(t *testing.T) {
+	owner := metav1.OwnerReference{APIVersion: "sources.knative.dev/v1beta1", Kind: "PingSource", Name: "source", UID: "uid-1"}
+	r := NewDeliveryStatusReporter(kubefake.NewSimpleClientset(), testResourceGroup, logtesting.TestLogger(t))
+
+	// No source in the context.
+	r.ReportContext(context.Background(), errors.New("boom"))
+	if len(r.sources) != 0 {
+		t.Errorf("Expected no delivery status, got %d", len(r.sources))
+	}
+
+	ctx := ContextWithMetricTag(context.Background(), &MetricTag{Namespace: "ns", Name: "source"})
+	ctx = ContextWithEventTypeOwner(ctx, "ns", owner)
+	r.ReportContext(ctx, errors.New("boom"))
+	s := r.sources[types.NamespacedName{Namespace: "ns", Name: "source"}]
+	if s == nil || s.status.ConsecutiveFailures != 1 {
+		t.Fatalf("Unexpected delivery status %+v", s)
+	}
+	if diff := cmp.Diff(&owner, s.owner); diff != "" {
+		t.Error("unexpected owner (-want, +got) =", diff)
+	}
+}
+
+func TestDeliveryStatusClient(t *testing.T) {
+	source := types.NamespacedName{Namespace: "ns", Name: "source"}
+	r := NewDeliveryStatusReporter(kubefake.NewSimpleClientset(), testResourceGroup, logtesting.TestLogger(t))
+	c := NewDeliveryStatusClient(test.NewTestClientWithResults(
+		cloudevents.NewHTTPResult(http.StatusServiceUnavailable, "unavailable"),
+		cloudevents.ResultACK,
+	), r, source, nil)
+
+	event := newEventTypeTestEvent("dev.knative.sources.ping")
+	c.Send(context.Background(), event)
+	if got := r.sources[source].status.ConsecutiveFailures; got != 1 {
+		t.Errorf("Expected 1 failure, got %d", got)
+	}
+	c.Send(context.Background(), event)
+	if got := r.sources[source].status.ConsecutiveFailures; got != 0 {
+		t.Errorf("Expected no failure after an ACK, got %d", got)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // No strong cryptography needed.
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"go.uber.org/zap"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/client/clientset/versioned"
+)
+
+// EventType owner context
+
+type eventTypeOwner struct {
+	namespace string
+	owner     metav1.OwnerReference
+}
+
+type eventTypeOwnerKey struct{}
+
+// ContextWithEventTypeOwner returns a copy of parent context in which the
+// EventTypes of the sent events are owned by owner, in namespace. The
+// multi-tenant adapters set it per source.
+func ContextWithEventTypeOwner(ctx context.Context, namespace string, owner metav1.OwnerReference) context.Context {
+	return context.WithValue(ctx, eventTypeOwnerKey{}, &eventTypeOwner{namespace: namespace, owner: owner})
+}
+
+func eventTypeOwnerFromContext(ctx context.Context) *eventTypeOwner {
+	if owner, ok := ctx.Value(eventTypeOwnerKey{}).(*eventTypeOwner); ok {
+		return owner
+	}
+	return nil
+}
+
+// eventTypeClient creates the EventTypes of the events sent by the wrapped
+// client, once per event type, source, schema and owner.
+type eventTypeClient struct {
+	cloudevents.Client
+	eventingClient versioned.Interface
+
+	// defaultOwner owns the EventTypes when the context of the events
+	// doesn't have an owner. Optional.
+	defaultOwner *eventTypeOwner
+	// sink returns the sink of the events without a target in their
+	// context.
+	sink func() string
+
+	mu sync.Mutex
+	// registered are the keys of the EventTypes created or updated, or
+	// that the adapter isn't allowed to.
+	registered map[string]struct{}
+}
+
+// NewEventTypeClient returns a client creating, in the namespace of the
+// owner of the context or else in namespace, the EventTypes of the events
+// sent by ceClient, owned by the source. The events without an owner don't
+// have an EventType.
+func NewEventTypeClient(ceClient cloudevents.Client, eventingClient versioned.Interface, namespace string, owner *metav1.OwnerReference, sink func() string) cloudevents.Client {
+	c := &eventTypeClient{
+		Client:         ceClient,
+		eventingClient: eventingClient,
+		sink:           sink,
+		registered:     make(map[string]struct{}),
+	}
+	if owner != nil {
+		c.defaultOwner = &eventTypeOwner{namespace: namespace, owner: *owner}
+	}
+	return c
+}
+
+// Send implements client.Send
+func (c *eventTypeClient) Send(ctx context.Context, out event.Event) protocol.Result {
+	c.register(ctx, out)
+	return c.Client.Send(ctx, out)
+}
+
+// Request implements client.Request
+func (c *eventTypeClient) Request(ctx context.Context, out event.Event) (*event.Event, protocol.Result) {
+	c.register(ctx, out)
+	return c.Client.Request(ctx, out)
+}
+
+// register creates the EventType of the event or adds the owner to the
+// existing one. The failures are logged, without failing the send.
+func (c *eventTypeClient) register(ctx context.Context, e event.Event) {
+	owner := eventTypeOwnerFromContext(ctx)
+	if owner == nil {
+		owner = c.defaultOwner
+	}
+	if owner == nil || e.Type() == "" {
+		return
+	}
+	name := eventTypeName(e)
+	key := owner.namespace + "/" + name + "/" + string(owner.owner.UID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.registered[key]; ok {
+		return
+	}
+
+	logger := logging.FromContext(ctx).With(zap.String("eventtype", owner.namespace+"/"+name), zap.String("type", e.Type()))
+	err := c.createOrUpdate(ctx, owner, name, e)
+	switch {
+	case err == nil:
+		c.registered[key] = struct{}{}
+	case apierrs.IsForbidden(err):
+		// Not retried on every event.
+		logger.Warnw("Not allowed to create the EventType", zap.Error(err))
+		c.registered[key] = struct{}{}
+	default:
+		logger.Errorw("Failed to create the EventType", zap.Error(err))
+	}
+}
+
+func (c *eventTypeClient) createOrUpdate(ctx context.Context, owner *eventTypeOwner, name string, e event.Event) error {
+	eventTypes := c.eventingClient.EventingV1beta1().EventTypes(owner.namespace)
+	et, err := eventTypes.Get(ctx, name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		_, err = eventTypes.Create(ctx, c.makeEventType(ctx, owner, name, e), metav1.CreateOptions{})
+		if apierrs.IsAlreadyExists(err) {
+			// Created concurrently, the owner is added on the next event.
+			return fmt.Errorf("created concurrently: %w", err)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	for _, ref := range et.OwnerReferences {
+		if ref.UID == owner.owner.UID {
+			return nil
+		}
+	}
+	et = et.DeepCopy()
+	et.OwnerReferences = append(et.OwnerReferences, owner.owner)
+	_, err = eventTypes.Update(ctx, et, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *eventTypeClient) makeEventType(ctx context.Context, owner *eventTypeOwner, name string, e event.Event) *v1beta1.EventType {
+	et := &v1beta1.EventType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       owner.namespace,
+			OwnerReferences: []metav1.OwnerReference{owner.owner},
+		},
+		Spec: v1beta1.EventTypeSpec{
+			Type:   e.Type(),
+			Broker: c.broker(ctx),
+		},
+	}
+	if source, err := apis.ParseURL(e.Source()); err == nil && source != nil {
+		et.Spec.Source = source
+	}
+	if schema, err := apis.ParseURL(e.DataSchema()); err == nil && schema != nil {
+		et.Spec.Schema = schema
+	}
+	return et
+}
+
+// broker returns the name of the broker of the sink when it is the
+// ingress of a broker, /<namespace>/<name>. It is empty otherwise, the
+// default broker.
+func (c *eventTypeClient) broker(ctx context.Context) string {
+	var target *url.URL
+	if t := cecontext.TargetFrom(ctx); t != nil {
+		target = t
+	} else if c.sink != nil {
+		target, _ = url.Parse(c.sink())
+	}
+	if target == nil || !strings.HasPrefix(target.Host, "broker-ingress.") {
+		return ""
+	}
+	if parts := strings.Split(strings.Trim(target.Path, "/"), "/"); len(parts) == 2 {
+		return parts[1]
+	}
+	return ""
+}
+
+// eventTypeName names the EventTypes with the hash of the type, source and
+// schema of the events, the ones of different sources being shared.
+func eventTypeName(e event.Event) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(e.Type()+e.Source()+e.DataSchema()))) //nolint:gosec // No strong cryptography needed.
+}
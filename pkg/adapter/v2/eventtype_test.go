@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/go-cmp/cmp"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/client/clientset/versioned/fake"
+)
+
+func newEventTypeTestEvent(eventType string) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID("1234")
+	event.SetType(eventType)
+	event.SetSource("/apis/v1/namespaces/ns")
+	return event
+}
+
+func TestEventTypeClient(t *testing.T) {
+	owner := metav1.OwnerReference{APIVersion: "sources.knative.dev/v1", Kind: "ApiServerSource", Name: "source", UID: "uid-1"}
+	eventingClient := fake.NewSimpleClientset()
+	ceClient := test.NewTestClient()
+	c := NewEventTypeClient(ceClient, eventingClient, "ns", &owner, func() string {
+		return "http://broker-ingress.knative-eventing.svc.cluster.local/ns/my-broker"
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if result := c.Send(ctx, newEventTypeTestEvent("dev.knative.apiserver.resource.add")); !cloudevents.IsACK(result) {
+			t.Fatal("Send =", result)
+		}
+	}
+	if len(ceClient.Sent()) != 2 {
+		t.Errorf("Expected 2 events sent, got %d", len(ceClient.Sent()))
+	}
+
+	ets, err := eventingClient.EventingV1beta1().EventTypes("ns").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("List =", err)
+	}
+	if len(ets.Items) != 1 {
+		t.Fatalf("Expected 1 EventType, got %d", len(ets.Items))
+	}
+	source, _ := apis.ParseURL("/apis/v1/namespaces/ns")
+	want := v1beta1.EventTypeSpec{
+		Type:   "dev.knative.apiserver.resource.add",
+		Source: source,
+		Broker: "my-broker",
+	}
+	if diff := cmp.Diff(want, ets.Items[0].Spec); diff != "" {
+		t.Error("unexpected spec (-want, +got) =", diff)
+	}
+	if diff := cmp.Diff([]metav1.OwnerReference{owner}, ets.Items[0].OwnerReferences); diff != "" {
+		t.Error("unexpected owners (-want, +got) =", diff)
+	}
+
+	// The second event is cached.
+	creates := 0
+	for _, action := range eventingClient.Actions() {
+		if action.GetVerb() == "create" {
+			creates++
+		}
+	}
+	if creates != 1 {
+		t.Errorf("Expected 1 create, got %d", creates)
+	}
+}
+
+func TestEventTypeClientOwnerFromContext(t *testing.T) {
+	first := metav1.OwnerReference{APIVersion: "sources.knative.dev/v1beta1", Kind: "PingSource", Name: "first", UID: "uid-1"}
+	second := metav1.OwnerReference{APIVersion: "sources.knative.dev/v1beta1", Kind: "PingSource", Name: "second", UID: "uid-2"}
+	eventingClient := fake.NewSimpleClientset()
+	c := NewEventTypeClient(test.NewTestClient(), eventingClient, "", nil, nil)
+
+	// Without owner, no EventType.
+	c.Send(context.Background(), newEventTypeTestEvent("dev.knative.sources.ping"))
+	c.Send(ContextWithEventTypeOwner(context.Background(), "ns", first), newEventTypeTestEvent("dev.knative.sources.ping"))
+	c.Send(ContextWithEventTypeOwner(context.Background(), "ns", second), newEventTypeTestEvent("dev.knative.sources.ping"))
+
+	ets, err := eventingClient.EventingV1beta1().EventTypes("ns").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("List =", err)
+	}
+	if len(ets.Items) != 1 {
+		t.Fatalf("Expected 1 EventType, got %d", len(ets.Items))
+	}
+	if diff := cmp.Diff([]metav1.OwnerReference{first, second}, ets.Items[0].OwnerReferences); diff != "" {
+		t.Error("unexpected owners (-want, +got) =", diff)
+	}
+	if broker := ets.Items[0].Spec.Broker; broker != "" {
+		t.Errorf("Expected the default broker, got %q", broker)
+	}
+}
+
+func TestEventTypeClientForbidden(t *testing.T) {
+	owner := metav1.OwnerReference{APIVersion: "sources.knative.dev/v1", Kind: "ApiServerSource", Name: "source", UID: "uid-1"}
+	eventingClient := fake.NewSimpleClientset()
+	eventingClient.PrependReactor("get", "eventtypes", func(clientgotesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrs.NewForbidden(schema.GroupResource{Group: "eventing.knative.dev", Resource: "eventtypes"}, "", nil)
+	})
+	ceClient := test.NewTestClient()
+	c := NewEventTypeClient(ceClient, eventingClient, "ns", &owner, nil)
+
+	for i := 0; i < 2; i++ {
+		if result := c.Send(context.Background(), newEventTypeTestEvent("dev.knative.apiserver.resource.add")); !cloudevents.IsACK(result) {
+			t.Fatal("Send =", result)
+		}
+	}
+	if len(ceClient.Sent()) != 2 {
+		t.Errorf("Expected 2 events sent, got %d", len(ceClient.Sent()))
+	}
+	// Not retried.
+	if got := len(eventingClient.Actions()); got != 1 {
+		t.Errorf("Expected 1 action, got %d", got)
+	}
+}
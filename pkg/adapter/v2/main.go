@@ -29,6 +29,8 @@ import (
 	"github.com/kelseyhightower/envconfig"
 	"go.opencensus.io/stats/view"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/controller"
@@ -43,6 +45,7 @@ import (
 	"knative.dev/pkg/source"
 
 	"knative.dev/eventing/pkg/adapter/v2/util/crstatusevent"
+	"knative.dev/eventing/pkg/client/clientset/versioned"
 )
 
 // Adapter is the interface receive adapters are expected to implement
@@ -164,6 +167,40 @@ func MainWithInformers(ctx context.Context, component string, env EnvConfigAcces
 		logger.Fatal("Error building cloud event client", zap.Error(err))
 	}
 
+	if resourceGroup := env.GetDeliveryStatusResourceGroup(); resourceGroup != "" {
+		owner, err := env.GetEventTypeOwner()
+		if err != nil {
+			logger.Error("Error loading the owner of the delivery status", zap.Error(err))
+		}
+		kubeClient := kubernetes.NewForConfigOrDie(sharedmain.ParseAndGetConfigOrDie())
+		deliveryStatus := NewDeliveryStatusReporter(kubeClient, resourceGroup, logger)
+		go deliveryStatus.Start(ctx)
+		source := types.NamespacedName{Namespace: env.GetNamespace(), Name: env.GetName()}
+		eventsClient = NewDeliveryStatusClient(eventsClient, deliveryStatus, source, owner)
+	}
+
+	if env.IsEventTypeAutoCreate() {
+		owner, err := env.GetEventTypeOwner()
+		if err != nil {
+			logger.Error("Error loading the owner of the EventTypes", zap.Error(err))
+		}
+		eventingClient := versioned.NewForConfigOrDie(sharedmain.ParseAndGetConfigOrDie())
+		eventsClient = NewEventTypeClient(eventsClient, eventingClient, env.GetNamespace(), owner, env.GetSink)
+	}
+
+	// The transformers run first, before the EventTypes of the events are
+	// created and the CloudEvents overrides applied.
+	transformers := EventTransformersFromContext(ctx)
+	rules, err := env.GetEventTransformRules()
+	if err != nil {
+		logger.Error("Error loading the event transform rules", zap.Error(err))
+	} else if len(rules) > 0 {
+		transformers = append(transformers, NewRulesTransformer(rules))
+	}
+	if len(transformers) > 0 {
+		eventsClient = NewTransformingClient(eventsClient, transformers...)
+	}
+
 	// Configuring the adapter
 	adapter := ctor(ctx, env, eventsClient)
 
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// OverrideValues are the runtime values the templated CloudEventOverrides
+// extensions are rendered with, e.g. "{{.SourceName}}-{{.Timestamp}}".
+type OverrideValues struct {
+	// Timestamp is the time the event is sent at, in RFC 3339.
+	Timestamp string
+	// ScheduleTick is the scheduled time of the fire sending the event, in
+	// RFC 3339, empty for the sources not firing on a schedule.
+	ScheduleTick string
+	// SourceName is the name of the source sending the event.
+	SourceName string
+	// SourceNamespace is the namespace of the source sending the event.
+	SourceNamespace string
+}
+
+// Overrides applies CloudEventOverrides to the events, rendering the
+// templated extensions on each event.
+type Overrides struct {
+	extensions map[string]string
+	templates  map[string]*template.Template
+}
+
+// NewOverrides parses the extensions of ceOverrides, the ones containing
+// "{{" being templates of the OverrideValues.
+func NewOverrides(ceOverrides *duckv1.CloudEventOverrides) (*Overrides, error) {
+	o := &Overrides{}
+	if ceOverrides == nil {
+		return o, nil
+	}
+	for name, value := range ceOverrides.Extensions {
+		if !strings.Contains(value, "{{") {
+			if o.extensions == nil {
+				o.extensions = make(map[string]string)
+			}
+			o.extensions[name] = value
+			continue
+		}
+		t, err := template.New(name).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template of the extension %q: %w", name, err)
+		}
+		// The references to unknown values only fail on execution.
+		if err := t.Execute(&strings.Builder{}, OverrideValues{}); err != nil {
+			return nil, fmt.Errorf("invalid template of the extension %q: %w", name, err)
+		}
+		if o.templates == nil {
+			o.templates = make(map[string]*template.Template)
+		}
+		o.templates[name] = t
+	}
+	return o, nil
+}
+
+// Apply sets the extensions of the overrides on event, the templated ones
+// being rendered with values.
+func (o *Overrides) Apply(event *cloudevents.Event, values OverrideValues) {
+	if o == nil {
+		return
+	}
+	for name, value := range o.extensions {
+		event.SetExtension(name, value)
+	}
+	for name, t := range o.templates {
+		var sb strings.Builder
+		// The templates were checked by NewOverrides.
+		if err := t.Execute(&sb, values); err == nil {
+			event.SetExtension(name, sb.String())
+		}
+	}
+}
+
+// Templated tells whether some extensions of the overrides are templates.
+func (o *Overrides) Templated() bool {
+	return o != nil && len(o.templates) > 0
+}
+
+type scheduleTickKey struct{}
+
+// ContextWithScheduleTick returns a copy of ctx carrying the scheduled time
+// of the fire the events are sent on, for the ScheduleTick of the
+// templated overrides.
+func ContextWithScheduleTick(ctx context.Context, tick time.Time) context.Context {
+	return context.WithValue(ctx, scheduleTickKey{}, tick)
+}
+
+// ScheduleTickFromContext returns the scheduled time of the fire stored in
+// ctx, false when not set.
+func ScheduleTickFromContext(ctx context.Context) (time.Time, bool) {
+	tick, ok := ctx.Value(scheduleTickKey{}).(time.Time)
+	return tick, ok
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestNewOverridesErrors(t *testing.T) {
+	for name, value := range map[string]string{
+		"unterminated":  "{{.SourceName",
+		"unknown value": "{{.Missing}}",
+		"unknown func":  "{{missing .SourceName}}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewOverrides(&duckv1.CloudEventOverrides{Extensions: map[string]string{"ext": value}}); err == nil {
+				t.Errorf("Expected %q to be rejected", value)
+			}
+		})
+	}
+}
+
+func TestOverridesApply(t *testing.T) {
+	overrides, err := NewOverrides(&duckv1.CloudEventOverrides{Extensions: map[string]string{
+		"static":  "value",
+		"stamped": "{{.SourceNamespace}}/{{.SourceName}}@{{.Timestamp}}",
+		"tick":    "{{.ScheduleTick}}",
+	}})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !overrides.Templated() {
+		t.Error("Expected the overrides to be templated")
+	}
+
+	event := cloudevents.NewEvent()
+	overrides.Apply(&event, OverrideValues{
+		Timestamp:       "2020-01-01T00:00:05Z",
+		ScheduleTick:    "2020-01-01T00:00:00Z",
+		SourceName:      "name",
+		SourceNamespace: "ns",
+	})
+	for name, want := range map[string]string{
+		"static":  "value",
+		"stamped": "ns/name@2020-01-01T00:00:05Z",
+		"tick":    "2020-01-01T00:00:00Z",
+	} {
+		if got := event.Extensions()[name]; got != want {
+			t.Errorf("Expected extension %s to be %q, got %v", name, want, got)
+		}
+	}
+}
+
+func TestClientOverrideValues(t *testing.T) {
+	overrides, err := NewOverrides(&duckv1.CloudEventOverrides{Extensions: map[string]string{
+		"source": "{{.SourceNamespace}}/{{.SourceName}}",
+		"tick":   "{{.ScheduleTick}}",
+	}})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	c := &client{ceOverrides: overrides, namespace: "env-ns", name: "env-name"}
+
+	event := cloudevents.NewEvent()
+	c.applyOverrides(context.Background(), &event)
+	if got := event.Extensions()["source"]; got != "env-ns/env-name" {
+		t.Errorf("Expected the source of the environment, got %v", got)
+	}
+	if got := event.Extensions()["tick"]; got != "" {
+		t.Errorf("Expected no schedule tick, got %v", got)
+	}
+
+	ctx := ContextWithMetricTag(context.Background(), &MetricTag{Namespace: "tag-ns", Name: "tag-name"})
+	ctx = ContextWithScheduleTick(ctx, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	c.applyOverrides(ctx, &event)
+	if got := event.Extensions()["source"]; got != "tag-ns/tag-name" {
+		t.Errorf("Expected the source of the metric tag, got %v", got)
+	}
+	if got := event.Extensions()["tick"]; got != "2020-01-01T00:00:00Z" {
+		t.Errorf("Expected the schedule tick, got %v", got)
+	}
+}
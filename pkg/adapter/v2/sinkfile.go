@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	nethttp "net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// watchedFile reads a file projected into the pod, again only once it
+// changed.
+type watchedFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	data    []byte
+}
+
+// read returns the content of the file and whether it changed since the
+// previous read.
+func (f *watchedFile) read() ([]byte, bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, false, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.data != nil && info.ModTime().Equal(f.modTime) {
+		return f.data, false, nil
+	}
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, false, err
+	}
+	changed := !bytes.Equal(data, f.data)
+	f.modTime, f.data = info.ModTime(), data
+	return data, changed, nil
+}
+
+// sinkFile reads the sink refreshed by a SinkBinding.
+type sinkFile struct {
+	watchedFile
+}
+
+// sink returns the current sink, empty when the file isn't readable yet.
+func (f *sinkFile) sink() string {
+	data, _, err := f.read()
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(data))
+}
+
+// caCertsTransport trusts the CA certificates of a file, picking up their
+// rotations.
+type caCertsTransport struct {
+	file watchedFile
+
+	mu        sync.Mutex
+	transport nethttp.RoundTripper
+}
+
+var _ nethttp.RoundTripper = (*caCertsTransport)(nil)
+
+func (t *caCertsTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	transport, err := t.current()
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+// current returns the transport trusting the current CA certificates.
+func (t *caCertsTransport) current() (nethttp.RoundTripper, error) {
+	data, changed, err := t.file.read()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		// The last certificates are kept while the file is being replaced.
+		if t.transport != nil {
+			return t.transport, nil
+		}
+		return nil, err
+	}
+	if t.transport != nil && !changed {
+		return t.transport, nil
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no CA certificates found in " + t.file.path)
+	}
+	transport := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if old, ok := t.transport.(*nethttp.Transport); ok {
+		old.CloseIdleConnections()
+	}
+	t.transport = transport
+	return transport, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+func TestSinkFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sinkfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sink")
+	c := &client{sinkFile: &sinkFile{watchedFile{path: path}}}
+
+	// The sink isn't projected yet.
+	if got := cecontext.TargetFrom(c.withRefreshedSink(context.Background())); got != nil {
+		t.Errorf("Expected no target, got %v", got)
+	}
+
+	writeFile(t, path, "http://first.ns.svc.cluster.local\n", time.Now())
+	if got := cecontext.TargetFrom(c.withRefreshedSink(context.Background())); got == nil || got.String() != "http://first.ns.svc.cluster.local" {
+		t.Errorf("Expected the first sink, got %v", got)
+	}
+
+	writeFile(t, path, "http://second.ns.svc.cluster.local", time.Now().Add(time.Second))
+	if got := cecontext.TargetFrom(c.withRefreshedSink(context.Background())); got == nil || got.String() != "http://second.ns.svc.cluster.local" {
+		t.Errorf("Expected the refreshed sink, got %v", got)
+	}
+
+	// The explicit targets win.
+	ctx := cecontext.WithTarget(context.Background(), "http://explicit")
+	if got := cecontext.TargetFrom(c.withRefreshedSink(ctx)); got.String() != "http://explicit" {
+		t.Errorf("Expected the explicit target, got %v", got)
+	}
+
+	env := &EnvConfig{Sink: "http://env", SinkFile: path}
+	if got := env.GetSink(); got != "http://second.ns.svc.cluster.local" {
+		t.Errorf("GetSink() = %q, want the refreshed sink", got)
+	}
+}
+
+func TestCACertsTransport(t *testing.T) {
+	server := httptest.NewTLSServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "cacerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ca.crt")
+	client := &nethttp.Client{Transport: &caCertsTransport{file: watchedFile{path: path}}}
+
+	if _, err := client.Get(server.URL); err == nil {
+		t.Error("Expected the request to fail without CA certificates")
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	writeFile(t, path, string(cert), time.Now())
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal("Expected the CA certificates to be trusted:", err)
+	}
+	resp.Body.Close()
+}
+
+func writeFile(t *testing.T, path, data string, modTime time.Time) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
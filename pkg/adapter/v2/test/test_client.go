@@ -28,9 +28,10 @@ import (
 )
 
 type TestCloudEventsClient struct {
-	lock  sync.Mutex
-	sent  []cloudevents.Event
-	delay time.Duration
+	lock    sync.Mutex
+	sent    []cloudevents.Event
+	delay   time.Duration
+	results []protocol.Result
 }
 
 var _ cloudevents.Client = (*TestCloudEventsClient)(nil)
@@ -41,6 +42,13 @@ func (c *TestCloudEventsClient) Send(ctx context.Context, out event.Event) proto
 	}
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if len(c.results) > 0 {
+		result := c.results[0]
+		c.results = c.results[1:]
+		if !protocol.IsACK(result) {
+			return result
+		}
+	}
 	// TODO: improve later.
 	c.sent = append(c.sent, out)
 	return http.NewResult(200, "%w", protocol.ResultACK)
@@ -90,3 +98,12 @@ func NewTestClientWithDelay(delay time.Duration) *TestCloudEventsClient {
 	}
 	return c
 }
+
+// NewTestClientWithResults returns a client replying to the successive sends
+// with the given results, and acknowledging all sends once exhausted. Only
+// the acknowledged events are recorded as sent.
+func NewTestClientWithResults(results ...protocol.Result) *TestCloudEventsClient {
+	c := NewTestClient()
+	c.results = results
+	return c
+}
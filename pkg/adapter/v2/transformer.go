@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// EventTransformer mutates the event before it is sent, e.g. setting
+// attributes, redacting or enriching its data. The event isn't sent when it
+// returns an error.
+type EventTransformer func(ctx context.Context, event *cloudevents.Event) error
+
+type transformersKey struct{}
+
+// WithEventTransformers returns a copy of parent context in which the
+// client of the adapter runs the transformers, in order, before sending
+// the events. The transformers of the K_EVENT_TRANSFORMERS rules run after
+// them.
+func WithEventTransformers(ctx context.Context, transformers ...EventTransformer) context.Context {
+	return context.WithValue(ctx, transformersKey{}, append(EventTransformersFromContext(ctx), transformers...))
+}
+
+// EventTransformersFromContext returns the transformers registered with
+// WithEventTransformers.
+func EventTransformersFromContext(ctx context.Context) []EventTransformer {
+	if transformers, ok := ctx.Value(transformersKey{}).([]EventTransformer); ok {
+		return transformers
+	}
+	return nil
+}
+
+// transformingClient runs the transformers on the events sent by the
+// wrapped client.
+type transformingClient struct {
+	cloudevents.Client
+	transformers []EventTransformer
+}
+
+// NewTransformingClient returns a client running the transformers, in
+// order, on the events before ceClient sends them.
+func NewTransformingClient(ceClient cloudevents.Client, transformers ...EventTransformer) cloudevents.Client {
+	return &transformingClient{Client: ceClient, transformers: transformers}
+}
+
+// Send implements client.Send
+func (c *transformingClient) Send(ctx context.Context, out event.Event) protocol.Result {
+	if err := c.transform(ctx, &out); err != nil {
+		return err
+	}
+	return c.Client.Send(ctx, out)
+}
+
+// Request implements client.Request
+func (c *transformingClient) Request(ctx context.Context, out event.Event) (*event.Event, protocol.Result) {
+	if err := c.transform(ctx, &out); err != nil {
+		return nil, err
+	}
+	return c.Client.Request(ctx, out)
+}
+
+func (c *transformingClient) transform(ctx context.Context, out *event.Event) error {
+	// The caller keeps its event, e.g. to send it again.
+	*out = out.Clone()
+	for _, transform := range c.transformers {
+		if err := transform(ctx, out); err != nil {
+			return fmt.Errorf("failed to transform the event: %w", err)
+		}
+	}
+	return nil
+}
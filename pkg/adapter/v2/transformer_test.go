@@ -0,0 +1,206 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func newTransformTestEvent(t *testing.T) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID("1234")
+	event.SetType("dev.knative.example")
+	event.SetSource("/example")
+	event.SetExtension("secret", "s3cr3t")
+	if err := event.SetData(cloudevents.ApplicationJSON, map[string]interface{}{
+		"user":  map[string]interface{}{"name": "john", "password": "pwd"},
+		"items": []interface{}{map[string]interface{}{"id": 1, "token": "a"}, map[string]interface{}{"id": 2, "token": "b"}},
+	}); err != nil {
+		t.Fatal("SetData =", err)
+	}
+	return event
+}
+
+func TestTransformingClient(t *testing.T) {
+	ceClient := test.NewTestClient()
+	var calls []string
+	c := NewTransformingClient(ceClient,
+		func(_ context.Context, event *cloudevents.Event) error {
+			calls = append(calls, "first")
+			event.SetExtension("first", "yes")
+			return nil
+		},
+		func(_ context.Context, event *cloudevents.Event) error {
+			calls = append(calls, "second")
+			if event.Extensions()["first"] != "yes" {
+				t.Error("Expected the first transformer to run before the second")
+			}
+			return nil
+		})
+
+	event := newTransformTestEvent(t)
+	if result := c.Send(context.Background(), event); !cloudevents.IsACK(result) {
+		t.Fatal("Send =", result)
+	}
+	if diff := cmp.Diff([]string{"first", "second"}, calls); diff != "" {
+		t.Error("unexpected calls (-want, +got) =", diff)
+	}
+	if _, ok := event.Extensions()["first"]; ok {
+		t.Error("Expected the event of the caller to be unchanged")
+	}
+	if sent := ceClient.Sent(); len(sent) != 1 || sent[0].Extensions()["first"] != "yes" {
+		t.Error("Expected the transformed event to be sent, got", sent)
+	}
+}
+
+func TestTransformingClientError(t *testing.T) {
+	ceClient := test.NewTestClient()
+	errTransform := errors.New("transform error")
+	c := NewTransformingClient(ceClient, func(context.Context, *cloudevents.Event) error {
+		return errTransform
+	})
+
+	if result := c.Send(context.Background(), newTransformTestEvent(t)); !errors.Is(result, errTransform) {
+		t.Error("Expected the transform error, got", result)
+	}
+	if _, result := c.Request(context.Background(), newTransformTestEvent(t)); !errors.Is(result, errTransform) {
+		t.Error("Expected the transform error, got", result)
+	}
+	if len(ceClient.Sent()) != 0 {
+		t.Error("Expected no events sent, got", ceClient.Sent())
+	}
+}
+
+func TestEventTransformersFromContext(t *testing.T) {
+	noop := func(context.Context, *cloudevents.Event) error { return nil }
+	ctx := context.Background()
+	if got := EventTransformersFromContext(ctx); len(got) != 0 {
+		t.Error("Expected no transformers, got", len(got))
+	}
+	ctx = WithEventTransformers(ctx, noop)
+	ctx = WithEventTransformers(ctx, noop, noop)
+	if got := EventTransformersFromContext(ctx); len(got) != 3 {
+		t.Error("Expected 3 transformers, got", len(got))
+	}
+}
+
+func TestRulesTransformer(t *testing.T) {
+	tests := map[string]struct {
+		rules    string
+		wantData map[string]interface{}
+		wantExts map[string]interface{}
+	}{
+		"remove data field": {
+			rules: `[{"remove": "$.data.user.password"}]`,
+			wantData: map[string]interface{}{
+				"user":  map[string]interface{}{"name": "john"},
+				"items": []interface{}{map[string]interface{}{"id": 1.0, "token": "a"}, map[string]interface{}{"id": 2.0, "token": "b"}},
+			},
+			wantExts: map[string]interface{}{"secret": "s3cr3t"},
+		},
+		"remove with wildcard and extension": {
+			rules: `
+- remove: $.data.items[*].token
+- remove: $['secret']`,
+			wantData: map[string]interface{}{
+				"user":  map[string]interface{}{"name": "john", "password": "pwd"},
+				"items": []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": 2.0}},
+			},
+			wantExts: nil,
+		},
+		"remove array element": {
+			rules: `[{"remove": "$.data.items[0]"}, {"remove": "$.data.user"}]`,
+			wantData: map[string]interface{}{
+				"items": []interface{}{map[string]interface{}{"id": 2.0, "token": "b"}},
+			},
+			wantExts: map[string]interface{}{"secret": "s3cr3t"},
+		},
+		"add extension and nested field": {
+			rules: `
+- add: $.cluster
+  value: prod
+- add: $.data.meta.origin
+  value: {zone: eu}
+- add: $.data.items[1].token
+  value: redacted
+- add: $.data.items[5].token
+  value: ignored`,
+			wantData: map[string]interface{}{
+				"user":  map[string]interface{}{"name": "john", "password": "pwd"},
+				"items": []interface{}{map[string]interface{}{"id": 1.0, "token": "a"}, map[string]interface{}{"id": 2.0, "token": "redacted"}},
+				"meta":  map[string]interface{}{"origin": map[string]interface{}{"zone": "eu"}},
+			},
+			wantExts: map[string]interface{}{"secret": "s3cr3t", "cluster": "prod"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rules, err := ParseTransformRules(tc.rules)
+			if err != nil {
+				t.Fatal("ParseTransformRules =", err)
+			}
+			event := newTransformTestEvent(t)
+			if err := NewRulesTransformer(rules)(context.Background(), &event); err != nil {
+				t.Fatal("transform =", err)
+			}
+
+			if event.ID() != "1234" || event.Type() != "dev.knative.example" || event.Source() != "/example" {
+				t.Error("Unexpected attributes:", event)
+			}
+			var data map[string]interface{}
+			if err := json.Unmarshal(event.Data(), &data); err != nil {
+				t.Fatal("Unmarshal =", err)
+			}
+			if diff := cmp.Diff(tc.wantData, data); diff != "" {
+				t.Error("unexpected data (-want, +got) =", diff)
+			}
+			if diff := cmp.Diff(tc.wantExts, event.Extensions()); diff != "" {
+				t.Error("unexpected extensions (-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestParseTransformRulesErrors(t *testing.T) {
+	for name, rules := range map[string]string{
+		"not a list":         `{"remove": "$.data"}`,
+		"both remove add":    `[{"remove": "$.data", "add": "$.data", "value": 1}]`,
+		"neither remove add": `[{"value": 1}]`,
+		"missing value":      `[{"add": "$.data.field"}]`,
+		"remove event":       `[{"remove": "$"}]`,
+		"remove required":    `[{"remove": "$.type"}]`,
+		"remove wildcard":    `[{"remove": "$.*"}]`,
+		"path without $":     `[{"remove": "data.field"}]`,
+		"empty name":         `[{"remove": "$..field"}]`,
+		"unterminated":       `[{"remove": "$.data[0"}]`,
+		"invalid index":      `[{"remove": "$.data[-1]"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseTransformRules(rules); err == nil {
+				t.Errorf("Expected %s to be invalid", rules)
+			}
+		})
+	}
+}
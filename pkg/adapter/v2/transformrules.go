@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// TransformRule is a rule of the built-in transformer, removing or setting
+// the fields at a JSONPath of the events in their JSON form, e.g.
+// $.data.password or $.myextension. The paths are made of .name, ['name'],
+// [index] and the [*] or .* wildcards.
+type TransformRule struct {
+	// Remove removes the fields at the path.
+	Remove string `json:"remove,omitempty"`
+
+	// Add sets the fields at the path to Value, creating the missing
+	// objects of its last names. The wildcards only match the existing
+	// fields.
+	Add   string      `json:"add,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// requiredAttributes can't be removed, the events being invalid without.
+var requiredAttributes = map[string]bool{"id": true, "source": true, "specversion": true, "type": true}
+
+// ParseTransformRules parses the YAML or JSON list of TransformRules.
+func ParseTransformRules(data string) ([]TransformRule, error) {
+	var rules []TransformRule
+	if err := yaml.Unmarshal([]byte(data), &rules); err != nil {
+		return nil, err
+	}
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r *TransformRule) validate() error {
+	switch {
+	case r.Remove != "" && r.Add != "":
+		return errors.New("expected either remove or add, got both")
+	case r.Remove != "":
+		path, err := parseJSONPath(r.Remove)
+		if err != nil {
+			return err
+		}
+		if len(path) == 0 {
+			return errors.New("can't remove the event")
+		}
+		if len(path) == 1 && (path[0].wildcard || requiredAttributes[path[0].name]) {
+			return fmt.Errorf("can't remove the required attribute %s", r.Remove)
+		}
+	case r.Add != "":
+		path, err := parseJSONPath(r.Add)
+		if err != nil {
+			return err
+		}
+		if len(path) == 0 {
+			return errors.New("can't replace the event")
+		}
+		if r.Value == nil {
+			return fmt.Errorf("missing the value of %s", r.Add)
+		}
+	default:
+		return errors.New("expected either remove or add")
+	}
+	return nil
+}
+
+// NewRulesTransformer returns the transformer applying the rules, in order.
+func NewRulesTransformer(rules []TransformRule) EventTransformer {
+	return func(_ context.Context, event *cloudevents.Event) error {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		var doc interface{}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return err
+		}
+
+		for _, rule := range rules {
+			if rule.Remove != "" {
+				path, _ := parseJSONPath(rule.Remove)
+				doc = removePath(doc, path)
+			} else {
+				path, _ := parseJSONPath(rule.Add)
+				doc = setPath(doc, path, rule.Value)
+			}
+		}
+
+		if b, err = json.Marshal(doc); err != nil {
+			return err
+		}
+		transformed := cloudevents.NewEvent()
+		if err := json.Unmarshal(b, &transformed); err != nil {
+			return err
+		}
+		if err := transformed.Validate(); err != nil {
+			return err
+		}
+		*event = transformed
+		return nil
+	}
+}
+
+// pathSegment is a name, an index or a wildcard of a JSONPath.
+type pathSegment struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func parseJSONPath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid path %q, expected to start with $", path)
+	}
+	var segments []pathSegment
+	for rest := path[1:]; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			segments = append(segments, pathSegment{wildcard: true})
+			rest = rest[2:]
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q, empty name", path)
+			}
+			segments = append(segments, pathSegment{name: rest[1 : end+1]})
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q, unterminated [", path)
+			}
+			inner := rest[1:end]
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{name: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid path %q, invalid index %q", path, inner)
+				}
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q at %q", path, rest)
+		}
+	}
+	return segments, nil
+}
+
+// removePath returns node without the fields at path.
+func removePath(node interface{}, path []pathSegment) interface{} {
+	if len(path) == 0 {
+		return node
+	}
+	segment, rest := path[0], path[1:]
+	switch node := node.(type) {
+	case map[string]interface{}:
+		if segment.isIndex {
+			return node
+		}
+		for name, child := range node {
+			if !segment.wildcard && name != segment.name {
+				continue
+			}
+			if len(rest) == 0 {
+				delete(node, name)
+			} else {
+				node[name] = removePath(child, rest)
+			}
+		}
+	case []interface{}:
+		if !segment.isIndex && !segment.wildcard {
+			return node
+		}
+		if len(rest) == 0 {
+			if segment.wildcard {
+				return []interface{}{}
+			}
+			if segment.index < len(node) {
+				return append(node[:segment.index], node[segment.index+1:]...)
+			}
+			return node
+		}
+		for i := range node {
+			if segment.wildcard || i == segment.index {
+				node[i] = removePath(node[i], rest)
+			}
+		}
+	}
+	return node
+}
+
+// setPath returns node with the fields at path set to value.
+func setPath(node interface{}, path []pathSegment, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	segment, rest := path[0], path[1:]
+	switch node := node.(type) {
+	case map[string]interface{}:
+		switch {
+		case segment.isIndex:
+		case segment.wildcard:
+			for name, child := range node {
+				node[name] = setPath(child, rest, value)
+			}
+		default:
+			child, ok := node[segment.name]
+			if !ok && len(rest) != 0 {
+				child = map[string]interface{}{}
+			}
+			node[segment.name] = setPath(child, rest, value)
+		}
+	case []interface{}:
+		for i := range node {
+			if segment.wildcard || (segment.isIndex && i == segment.index) {
+				node[i] = setPath(node[i], rest, value)
+			}
+		}
+	}
+	return node
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// NamespaceDefaultsGetter gets the Broker defaults of the namespace-local
+// config-br-defaults ConfigMaps.
+type NamespaceDefaultsGetter interface {
+	// NamespaceDefaults returns the defaults of the namespace, nil if the
+	// namespace has no config-br-defaults ConfigMap.
+	NamespaceDefaults(namespace string) (*ClassAndBrokerConfig, error)
+}
+
+// NewNamespaceDefaultsFromConfigMap creates a ClassAndBrokerConfig from the
+// supplied namespace-local configMap.
+func NewNamespaceDefaultsFromConfigMap(config *corev1.ConfigMap) (*ClassAndBrokerConfig, error) {
+	value, present := config.Data[BrokerDefaultsKey]
+	if !present || value == "" {
+		return nil, fmt.Errorf("ConfigMap %s/%s is missing (or empty) key: %q", config.Namespace, config.Name, BrokerDefaultsKey)
+	}
+	nc := &ClassAndBrokerConfig{}
+	if err := parseEntry(value, nc); err != nil {
+		return nil, fmt.Errorf("Failed to parse the entry of ConfigMap %s/%s: %s", config.Namespace, config.Name, err)
+	}
+	return nc, nil
+}
+
+// NewNamespaceDefaultsGetter creates a NamespaceDefaultsGetter listing the
+// config-br-defaults ConfigMaps with lister. The one of the system namespace
+// holds the cluster defaults so it is ignored.
+func NewNamespaceDefaultsGetter(lister corev1listers.ConfigMapLister, systemNamespace string) NamespaceDefaultsGetter {
+	return &listerNamespaceDefaults{lister: lister, systemNamespace: systemNamespace}
+}
+
+type listerNamespaceDefaults struct {
+	lister          corev1listers.ConfigMapLister
+	systemNamespace string
+}
+
+func (g *listerNamespaceDefaults) NamespaceDefaults(namespace string) (*ClassAndBrokerConfig, error) {
+	if namespace == "" || namespace == g.systemNamespace {
+		return nil, nil
+	}
+	cm, err := g.lister.ConfigMaps(namespace).Get(DefaultsConfigName)
+	if apierrs.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return NewNamespaceDefaultsFromConfigMap(cm)
+}
+
+// brokerClasses returns the Broker classes the cluster defaults reference.
+func (d *Defaults) brokerClasses() map[string]bool {
+	classes := make(map[string]bool)
+	if d == nil {
+		return classes
+	}
+	if d.ClusterDefault != nil && d.ClusterDefault.BrokerClass != "" {
+		classes[d.ClusterDefault.BrokerClass] = true
+	}
+	for _, c := range d.NamespaceDefaultsConfig {
+		if c != nil && c.BrokerClass != "" {
+			classes[c.BrokerClass] = true
+		}
+	}
+	return classes
+}
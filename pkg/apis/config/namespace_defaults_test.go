@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func namespaceDefaultsConfigMap(namespace, entry string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      DefaultsConfigName,
+		},
+		Data: map[string]string{
+			BrokerDefaultsKey: entry,
+		},
+	}
+}
+
+func namespaceDefaultsGetter(t *testing.T, cms ...*corev1.ConfigMap) NamespaceDefaultsGetter {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, cm := range cms {
+		if err := indexer.Add(cm); err != nil {
+			t.Fatal("Failed to add the ConfigMap:", err)
+		}
+	}
+	return NewNamespaceDefaultsGetter(corev1listers.NewConfigMapLister(indexer), "knative-eventing")
+}
+
+func TestConfigNamespaceDefaults(t *testing.T) {
+	cfg := &Config{
+		Defaults: &Defaults{
+			NamespaceDefaultsConfig: map[string]*ClassAndBrokerConfig{
+				"cluster-namespace": {
+					BrokerClass: "ClusterNamespaceClass",
+				},
+			},
+			ClusterDefault: &ClassAndBrokerConfig{
+				BrokerClass: "ClusterClass",
+				BrokerConfig: &BrokerConfig{
+					KReference: &duckv1.KReference{
+						Kind: "ConfigMap",
+						Name: "cluster-config",
+					},
+				},
+			},
+		},
+		NamespaceDefaults: namespaceDefaultsGetter(t,
+			namespaceDefaultsConfigMap("local", `
+brokerClass: ClusterNamespaceClass
+kind: ConfigMap
+name: local-config
+`),
+			namespaceDefaultsConfigMap("class-only", "brokerClass: ClusterClass"),
+			namespaceDefaultsConfigMap("unknown-class", "brokerClass: UnknownClass"),
+			namespaceDefaultsConfigMap("invalid", "brokerClass: [ClusterClass"),
+			namespaceDefaultsConfigMap("cluster-namespace", "name: cluster-namespace-config"),
+			namespaceDefaultsConfigMap("knative-eventing", "brokerClass: UnknownClass"),
+		),
+	}
+
+	tests := map[string]struct {
+		class       string
+		configName  string
+		invalidErrs bool
+	}{
+		"local":             {class: "ClusterNamespaceClass", configName: "local-config"},
+		"class-only":        {class: "ClusterClass", configName: "cluster-config"},
+		"unknown-class":     {class: "UnknownClass", configName: "cluster-config", invalidErrs: true},
+		"invalid":           {class: "ClusterClass", configName: "cluster-config", invalidErrs: true},
+		"cluster-namespace": {class: "ClusterNamespaceClass", configName: "cluster-namespace-config"},
+		"knative-eventing":  {class: "ClusterClass", configName: "cluster-config"},
+		"none":              {class: "ClusterClass", configName: "cluster-config"},
+	}
+	for ns, tc := range tests {
+		t.Run(ns, func(t *testing.T) {
+			class, err := cfg.GetBrokerClass(ns)
+			if err != nil {
+				t.Error("GetBrokerClass Failed =", err)
+			}
+			if class != tc.class {
+				t.Errorf("GetBrokerClass Failed, wanted %s, got: %s", tc.class, class)
+			}
+			c, err := cfg.GetBrokerConfig(ns)
+			if err != nil {
+				t.Error("GetBrokerConfig Failed =", err)
+			}
+			if c.Name != tc.configName {
+				t.Errorf("GetBrokerConfig Failed, wanted %s, got: %s", tc.configName, c.Name)
+			}
+			if err := cfg.ValidateNamespaceDefaults(ns); (err != nil) != tc.invalidErrs {
+				t.Errorf("ValidateNamespaceDefaults = %v, wanted an error: %t", err, tc.invalidErrs)
+			}
+		})
+	}
+}
+
+func TestNewNamespaceDefaultsFromConfigMap(t *testing.T) {
+	if _, err := NewNamespaceDefaultsFromConfigMap(namespaceDefaultsConfigMap("ns", "")); err == nil {
+		t.Error("NewNamespaceDefaultsFromConfigMap(empty) succeeded, wanted an error")
+	}
+	c, err := NewNamespaceDefaultsFromConfigMap(namespaceDefaultsConfigMap("ns", `
+brokerClass: SomeClass
+apiVersion: v1
+kind: ConfigMap
+name: some-config
+namespace: ns
+delivery:
+  retry: 3
+`))
+	if err != nil {
+		t.Fatal("NewNamespaceDefaultsFromConfigMap =", err)
+	}
+	if c.BrokerClass != "SomeClass" || c.Name != "some-config" || c.Delivery == nil || *c.Delivery.Retry != 3 {
+		t.Errorf("NewNamespaceDefaultsFromConfigMap = %+v", c)
+	}
+}
@@ -18,6 +18,7 @@ package config
 
 import (
 	"context"
+	"fmt"
 
 	"knative.dev/pkg/configmap"
 )
@@ -28,6 +29,62 @@ type cfgKey struct{}
 // +k8s:deepcopy-gen=false
 type Config struct {
 	Defaults *Defaults
+
+	// NamespaceDefaults gets the namespace-local Broker defaults, taking
+	// precedence over Defaults. No namespace-local defaults are used when nil.
+	NamespaceDefaults NamespaceDefaultsGetter
+}
+
+// GetBrokerConfig returns the Broker Configuration of the namespace-local
+// defaults, and if that doesn't exist, the one of the cluster Defaults.
+func (c *Config) GetBrokerConfig(ns string) (*BrokerConfig, error) {
+	if nd := c.namespaceDefaults(ns); nd != nil && nd.BrokerConfig != nil {
+		return nd.BrokerConfig, nil
+	}
+	return c.Defaults.GetBrokerConfig(ns)
+}
+
+// GetBrokerClass returns the Broker Class of the namespace-local defaults,
+// and if that doesn't exist, the one of the cluster Defaults.
+func (c *Config) GetBrokerClass(ns string) (string, error) {
+	if nd := c.namespaceDefaults(ns); nd != nil && nd.BrokerClass != "" {
+		return nd.BrokerClass, nil
+	}
+	return c.Defaults.GetBrokerClass(ns)
+}
+
+// ValidateNamespaceDefaults returns an error if the namespace-local defaults
+// of ns can't be parsed or reference a Broker Class the cluster Defaults
+// don't.
+func (c *Config) ValidateNamespaceDefaults(ns string) error {
+	if c.NamespaceDefaults == nil {
+		return nil
+	}
+	nd, err := c.NamespaceDefaults.NamespaceDefaults(ns)
+	if err != nil {
+		return err
+	}
+	if nd == nil || nd.BrokerClass == "" {
+		return nil
+	}
+	if !c.Defaults.brokerClasses()[nd.BrokerClass] {
+		return fmt.Errorf("the Broker Class %q of the %s ConfigMap of namespace %q isn't configured in the cluster %s ConfigMap",
+			nd.BrokerClass, DefaultsConfigName, ns, DefaultsConfigName)
+	}
+	return nil
+}
+
+// namespaceDefaults returns the namespace-local defaults of ns, nil if there
+// are none or they can't be parsed.
+func (c *Config) namespaceDefaults(ns string) *ClassAndBrokerConfig {
+	if c.NamespaceDefaults == nil {
+		return nil
+	}
+	nd, err := c.NamespaceDefaults.NamespaceDefaults(ns)
+	if err != nil {
+		return nil
+	}
+	return nd
 }
 
 // FromContext extracts a Config from the provided context.
@@ -61,6 +118,8 @@ func ToContext(ctx context.Context, c *Config) context.Context {
 // +k8s:deepcopy-gen=false
 type Store struct {
 	*configmap.UntypedStore
+
+	namespaceDefaults NamespaceDefaultsGetter
 }
 
 // NewStore creates a new store of Configs and optionally calls functions when ConfigMaps are updated.
@@ -79,6 +138,13 @@ func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value i
 	return store
 }
 
+// WithNamespaceDefaults makes the Configs loaded from the Store get the
+// namespace-local Broker defaults with getter.
+func (s *Store) WithNamespaceDefaults(getter NamespaceDefaultsGetter) *Store {
+	s.namespaceDefaults = getter
+	return s
+}
+
 // ToContext attaches the current Config state to the provided context.
 func (s *Store) ToContext(ctx context.Context) context.Context {
 	return ToContext(ctx, s.Load())
@@ -87,6 +153,7 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 // Load creates a Config from the current config state of the Store.
 func (s *Store) Load() *Config {
 	return &Config{
-		Defaults: s.UntypedLoad(DefaultsConfigName).(*Defaults).DeepCopy(),
+		Defaults:          s.UntypedLoad(DefaultsConfigName).(*Defaults).DeepCopy(),
+		NamespaceDefaults: s.namespaceDefaults,
 	}
 }
@@ -42,3 +42,13 @@ func (source *DeliveryStatus) ConvertTo(ctx context.Context, sink apis.Convertib
 func (sink *DeliveryStatus) ConvertFrom(ctx context.Context, source apis.Convertible) error {
 	return fmt.Errorf("v1 is the highest known version, got: %T", source)
 }
+
+// ConvertTo implements apis.Convertible
+func (source *DeliveryFormat) ConvertTo(ctx context.Context, sink apis.Convertible) error {
+	return fmt.Errorf("v1 is the highest known version, got: %T", sink)
+}
+
+// ConvertFrom implements apis.Convertible
+func (sink *DeliveryFormat) ConvertFrom(ctx context.Context, source apis.Convertible) error {
+	return fmt.Errorf("v1 is the highest known version, got: %T", source)
+}
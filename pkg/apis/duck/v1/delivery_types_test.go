@@ -64,6 +64,52 @@ func TestDeliverySpecValidation(t *testing.T) {
 		want: func() *apis.FieldError {
 			return apis.ErrGeneric("invalid value: "+invalidBackoffDelay, "backoffDelay")
 		}(),
+	}, {
+		name: "valid timeout",
+		spec: &DeliverySpec{Timeout: pointer.StringPtr("PT30S")},
+	}, {
+		name: "invalid timeout",
+		spec: &DeliverySpec{Timeout: &invalidString},
+		want: func() *apis.FieldError {
+			return apis.ErrInvalidValue(invalidString, "timeout")
+		}(),
+	}, {
+		name: "zero timeout",
+		spec: &DeliverySpec{Timeout: pointer.StringPtr("PT0S")},
+		want: func() *apis.FieldError {
+			return apis.ErrInvalidValue("PT0S", "timeout")
+		}(),
+	}, {
+		name: "valid format",
+		spec: &DeliverySpec{Format: &DeliveryFormat{
+			Encoding:        encodingPtr(EncodingStructured),
+			DataContentType: pointer.StringPtr(DataContentTypeAvro),
+			SchemaRef:       apis.HTTP("schemas.example.com/order.avsc"),
+		}},
+	}, {
+		name: "invalid format encoding",
+		spec: &DeliverySpec{Format: &DeliveryFormat{Encoding: encodingPtr("protobuf")}},
+		want: func() *apis.FieldError {
+			return apis.ErrInvalidValue("protobuf", "format.encoding")
+		}(),
+	}, {
+		name: "invalid format dataContentType",
+		spec: &DeliverySpec{Format: &DeliveryFormat{DataContentType: pointer.StringPtr("text/xml")}},
+		want: func() *apis.FieldError {
+			return apis.ErrInvalidValue("text/xml", "format.dataContentType")
+		}(),
+	}, {
+		name: "avro format without schema",
+		spec: &DeliverySpec{Format: &DeliveryFormat{DataContentType: pointer.StringPtr(DataContentTypeAvro)}},
+		want: func() *apis.FieldError {
+			return apis.ErrMissingField("format.schemaRef")
+		}(),
+	}, {
+		name: "relative schemaRef",
+		spec: &DeliverySpec{Format: &DeliveryFormat{SchemaRef: &apis.URL{Path: "/order.avsc"}}},
+		want: func() *apis.FieldError {
+			return apis.ErrInvalidValue("/order.avsc", "format.schemaRef")
+		}(),
 	}, {
 		name: "negative retry",
 		spec: &DeliverySpec{Retry: pointer.Int32Ptr(-1)},
@@ -87,3 +133,7 @@ func TestDeliverySpecValidation(t *testing.T) {
 		})
 	}
 }
+
+func encodingPtr(e EncodingType) *EncodingType {
+	return &e
+}
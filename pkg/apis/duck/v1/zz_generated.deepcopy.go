@@ -133,6 +133,37 @@ func (in *ChannelableStatus) DeepCopy() *ChannelableStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeliveryFormat) DeepCopyInto(out *DeliveryFormat) {
+	*out = *in
+	if in.Encoding != nil {
+		in, out := &in.Encoding, &out.Encoding
+		*out = new(EncodingType)
+		**out = **in
+	}
+	if in.DataContentType != nil {
+		in, out := &in.DataContentType, &out.DataContentType
+		*out = new(string)
+		**out = **in
+	}
+	if in.SchemaRef != nil {
+		in, out := &in.SchemaRef, &out.SchemaRef
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeliveryFormat.
+func (in *DeliveryFormat) DeepCopy() *DeliveryFormat {
+	if in == nil {
+		return nil
+	}
+	out := new(DeliveryFormat)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeliverySpec) DeepCopyInto(out *DeliverySpec) {
 	*out = *in
@@ -156,6 +187,16 @@ func (in *DeliverySpec) DeepCopyInto(out *DeliverySpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.Format != nil {
+		in, out := &in.Format, &out.Format
+		*out = new(DeliveryFormat)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
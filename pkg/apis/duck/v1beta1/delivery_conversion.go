@@ -31,6 +31,7 @@ func (source *DeliverySpec) ConvertTo(ctx context.Context, to apis.Convertible)
 	case *eventingduckv1.DeliverySpec:
 		sink.Retry = source.Retry
 		sink.BackoffDelay = source.BackoffDelay
+		sink.Timeout = source.Timeout
 		if source.BackoffPolicy != nil {
 			if *source.BackoffPolicy == BackoffPolicyLinear {
 				linear := eventingduckv1.BackoffPolicyLinear
@@ -43,6 +44,12 @@ func (source *DeliverySpec) ConvertTo(ctx context.Context, to apis.Convertible)
 			}
 		}
 		sink.DeadLetterSink = source.DeadLetterSink
+		if source.Format != nil {
+			sink.Format = &eventingduckv1.DeliveryFormat{}
+			if err := source.Format.ConvertTo(ctx, sink.Format); err != nil {
+				return err
+			}
+		}
 		return nil
 	default:
 		return fmt.Errorf("unknown version, got: %T", sink)
@@ -55,6 +62,7 @@ func (sink *DeliverySpec) ConvertFrom(ctx context.Context, from apis.Convertible
 	case *eventingduckv1.DeliverySpec:
 		sink.Retry = source.Retry
 		sink.BackoffDelay = source.BackoffDelay
+		sink.Timeout = source.Timeout
 		if source.BackoffPolicy != nil {
 			if *source.BackoffPolicy == eventingduckv1.BackoffPolicyLinear {
 				linear := BackoffPolicyLinear
@@ -68,6 +76,38 @@ func (sink *DeliverySpec) ConvertFrom(ctx context.Context, from apis.Convertible
 
 		}
 		sink.DeadLetterSink = source.DeadLetterSink
+		if source.Format != nil {
+			sink.Format = &DeliveryFormat{}
+			if err := sink.Format.ConvertFrom(ctx, source.Format); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown version, got: %T", source)
+	}
+}
+
+// ConvertTo implements apis.Convertible
+func (source *DeliveryFormat) ConvertTo(ctx context.Context, to apis.Convertible) error {
+	switch sink := to.(type) {
+	case *eventingduckv1.DeliveryFormat:
+		sink.Encoding = (*eventingduckv1.EncodingType)(source.Encoding)
+		sink.DataContentType = source.DataContentType
+		sink.SchemaRef = source.SchemaRef
+		return nil
+	default:
+		return fmt.Errorf("unknown version, got: %T", sink)
+	}
+}
+
+// ConvertFrom implements apis.Convertible
+func (sink *DeliveryFormat) ConvertFrom(ctx context.Context, from apis.Convertible) error {
+	switch source := from.(type) {
+	case *eventingduckv1.DeliveryFormat:
+		sink.Encoding = (*EncodingType)(source.Encoding)
+		sink.DataContentType = source.DataContentType
+		sink.SchemaRef = source.SchemaRef
 		return nil
 	default:
 		return fmt.Errorf("unknown version, got: %T", source)
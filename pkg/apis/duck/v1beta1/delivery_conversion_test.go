@@ -58,6 +58,9 @@ func TestDeliverySpecConversion(t *testing.T) {
 	var backoffPolicyExp BackoffPolicyType = BackoffPolicyExponential
 	var backoffPolicyBad BackoffPolicyType = "garbage"
 	badPolicyString := `unknown BackoffPolicy, got: "garbage"`
+	timeout := "PT10S"
+	encoding := EncodingBinary
+	avro := DataContentTypeAvro
 
 	tests := []struct {
 		name string
@@ -96,6 +99,21 @@ func TestDeliverySpecConversion(t *testing.T) {
 				URI: apis.HTTP("example.com"),
 			},
 		},
+	}, {
+		name: "with timeout",
+		in: &DeliverySpec{
+			Retry:   &retryCount,
+			Timeout: &timeout,
+		},
+	}, {
+		name: "with format",
+		in: &DeliverySpec{
+			Format: &DeliveryFormat{
+				Encoding:        &encoding,
+				DataContentType: &avro,
+				SchemaRef:       apis.HTTP("schemas.example.com/order.avsc"),
+			},
+		},
 	}, {
 		name: "with bad backoff",
 		in: &DeliverySpec{
@@ -135,6 +153,9 @@ func TestDeliverySpecConversionV1(t *testing.T) {
 	var backoffPolicyExp v1.BackoffPolicyType = v1.BackoffPolicyExponential
 	var backoffPolicyBad v1.BackoffPolicyType = "garbage"
 	badPolicyString := `unknown BackoffPolicy, got: "garbage"`
+	timeout := "PT10S"
+	encoding := v1.EncodingBinary
+	avro := v1.DataContentTypeAvro
 
 	tests := []struct {
 		name string
@@ -173,6 +194,21 @@ func TestDeliverySpecConversionV1(t *testing.T) {
 				URI: apis.HTTP("example.com"),
 			},
 		},
+	}, {
+		name: "with timeout",
+		in: &v1.DeliverySpec{
+			Retry:   &retryCount,
+			Timeout: &timeout,
+		},
+	}, {
+		name: "with format",
+		in: &v1.DeliverySpec{
+			Format: &v1.DeliveryFormat{
+				Encoding:        &encoding,
+				DataContentType: &avro,
+				SchemaRef:       apis.HTTP("schemas.example.com/order.avsc"),
+			},
+		},
 	}, {
 		name: "with bad backoff",
 		in: &v1.DeliverySpec{
@@ -51,6 +51,18 @@ type DeliverySpec struct {
 	// For exponential policy, backoff delay is backoffDelay*2^<numberOfRetries>.
 	// +optional
 	BackoffDelay *string `json:"backoffDelay,omitempty"`
+
+	// Timeout is the timeout of each single request sent to the destination,
+	// in ISO-8601 duration format, after which it is failed and possibly
+	// retried. No timeout is set by default.
+	// +optional
+	Timeout *string `json:"timeout,omitempty"`
+
+	// Format is the format the events are converted to before being sent
+	// to the destination. The events are sent as they were received by
+	// default.
+	// +optional
+	Format *DeliveryFormat `json:"format,omitempty"`
 }
 
 func (ds *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
@@ -81,6 +93,16 @@ func (ds *DeliverySpec) Validate(ctx context.Context) *apis.FieldError {
 			errs = errs.Also(apis.ErrInvalidValue(*ds.BackoffDelay, "backoffDelay"))
 		}
 	}
+
+	if ds.Timeout != nil {
+		if t, te := period.Parse(*ds.Timeout); te != nil || !t.IsPositive() {
+			errs = errs.Also(apis.ErrInvalidValue(*ds.Timeout, "timeout"))
+		}
+	}
+
+	if fe := ds.Format.Validate(ctx); fe != nil {
+		errs = errs.Also(fe.ViaField("format"))
+	}
 	return errs
 }
 
@@ -95,6 +117,79 @@ const (
 	BackoffPolicyExponential BackoffPolicyType = "exponential"
 )
 
+// DeliveryFormat is the format of the events sent to a destination.
+type DeliveryFormat struct {
+	// Encoding is the content mode of the events sent over HTTP (binary,
+	// structured). The events keep the mode they were received in by
+	// default.
+	// +optional
+	Encoding *EncodingType `json:"encoding,omitempty"`
+
+	// DataContentType is the content type the data of the events is
+	// transcoded to (application/json, application/avro). The data is
+	// sent as is by default.
+	// +optional
+	DataContentType *string `json:"dataContentType,omitempty"`
+
+	// SchemaRef is the URL of the Avro schema of the data, required to
+	// transcode the data to or from Avro. The dataschema of the events is
+	// never fetched.
+	// +optional
+	SchemaRef *apis.URL `json:"schemaRef,omitempty"`
+}
+
+// EncodingType is the type for the content modes of the events.
+type EncodingType string
+
+const (
+	// Binary content mode, the attributes being sent as headers
+	EncodingBinary EncodingType = "binary"
+
+	// Structured content mode, the event being sent as a JSON document
+	EncodingStructured EncodingType = "structured"
+)
+
+const (
+	// DataContentTypeJSON is the content type of the JSON data.
+	DataContentTypeJSON = "application/json"
+
+	// DataContentTypeAvro is the content type of the Avro binary data.
+	DataContentTypeAvro = "application/avro"
+)
+
+func (f *DeliveryFormat) Validate(ctx context.Context) *apis.FieldError {
+	if f == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	if f.Encoding != nil {
+		switch *f.Encoding {
+		case EncodingBinary, EncodingStructured:
+			// nothing
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(*f.Encoding, "encoding"))
+		}
+	}
+
+	if f.DataContentType != nil {
+		switch *f.DataContentType {
+		case DataContentTypeJSON:
+			// nothing
+		case DataContentTypeAvro:
+			if f.SchemaRef == nil {
+				errs = errs.Also(apis.ErrMissingField("schemaRef"))
+			}
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(*f.DataContentType, "dataContentType"))
+		}
+	}
+
+	if f.SchemaRef != nil && !f.SchemaRef.URL().IsAbs() {
+		errs = errs.Also(apis.ErrInvalidValue(f.SchemaRef.String(), "schemaRef"))
+	}
+	return errs
+}
+
 // DeliveryStatus contains the Status of an object supporting delivery options.
 type DeliveryStatus struct {
 	// DeadLetterChannel is a KReference that is the reference to the native, platform specific channel
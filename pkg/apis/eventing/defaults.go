@@ -21,6 +21,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/eventing/pkg/apis/config"
+	"knative.dev/pkg/apis"
 )
 
 // DefaultBrokerClassIfUnset sets default broker class annotation if unset.
@@ -31,10 +32,27 @@ func DefaultBrokerClassIfUnset(ctx context.Context, obj *metav1.ObjectMeta) {
 	}
 	if _, present := annotations[BrokerClassKey]; !present {
 		cfg := config.FromContextOrDefaults(ctx)
-		c, err := cfg.Defaults.GetBrokerClass(obj.Namespace)
+		c, err := cfg.GetBrokerClass(obj.Namespace)
 		if err == nil {
 			annotations[BrokerClassKey] = c
 			obj.SetAnnotations(annotations)
 		}
 	}
 }
+
+// ValidateNamespaceDefaults returns an error if the namespace-local Broker
+// defaults of the namespace are invalid.
+func ValidateNamespaceDefaults(ctx context.Context, namespace string) *apis.FieldError {
+	cfg := config.FromContext(ctx)
+	if cfg == nil {
+		return nil
+	}
+	if err := cfg.ValidateNamespaceDefaults(namespace); err != nil {
+		return &apis.FieldError{
+			Message: "invalid namespace Broker defaults",
+			Paths:   []string{"metadata.namespace"},
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
@@ -56,6 +56,11 @@ const (
 	// if a Source has event types defines in its CRD.
 	EventTypesAnnotationKey = "registry.knative.dev/eventTypes"
 
+	// BrokerRecordingAnnotationKey is the annotation key the Brokers set to
+	// "true" for the ingress of the MT channel based Broker to record their
+	// events, for them to be replayed to their Triggers.
+	BrokerRecordingAnnotationKey = GroupName + "/broker.recording"
+
 	// BrokerChannelAddressStatusAnnotationKey is the broker status
 	// annotation key used to specify the address of its channel.
 	BrokerChannelAddressStatusAnnotationKey = "knative.dev/channelAddress"
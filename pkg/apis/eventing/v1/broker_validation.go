@@ -19,6 +19,7 @@ package v1
 import (
 	"context"
 
+	"knative.dev/eventing/pkg/apis/eventing"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmp"
 )
@@ -40,6 +41,8 @@ func (b *Broker) Validate(ctx context.Context) *apis.FieldError {
 	if apis.IsInUpdate(ctx) {
 		original := apis.GetBaseline(ctx).(*Broker)
 		errs = errs.Also(b.CheckImmutableFields(ctx, original))
+	} else {
+		errs = errs.Also(eventing.ValidateNamespaceDefaults(ctx, b.Namespace))
 	}
 	return errs
 }
@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/eventing/pkg/apis/config"
 	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -198,6 +199,45 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+type namespaceDefaults map[string]*config.ClassAndBrokerConfig
+
+func (d namespaceDefaults) NamespaceDefaults(namespace string) (*config.ClassAndBrokerConfig, error) {
+	return d[namespace], nil
+}
+
+func TestValidateNamespaceDefaults(t *testing.T) {
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Defaults: &config.Defaults{
+			ClusterDefault: &config.ClassAndBrokerConfig{BrokerClass: "MTChannelBasedBroker"},
+		},
+		NamespaceDefaults: namespaceDefaults{
+			"valid":   {BrokerClass: "MTChannelBasedBroker"},
+			"invalid": {BrokerClass: "UnknownBroker"},
+		},
+	})
+	broker := func(namespace string) *Broker {
+		return &Broker{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Annotations: map[string]string{"eventing.knative.dev/broker.class": "MTChannelBasedBroker"},
+			},
+		}
+	}
+
+	for _, ns := range []string{"valid", "none"} {
+		if err := broker(ns).Validate(ctx); err != nil {
+			t.Errorf("Broker.Validate() in namespace %s = %v", ns, err)
+		}
+	}
+	if err := broker("invalid").Validate(ctx); err == nil {
+		t.Error("Broker.Validate() in namespace invalid succeeded, wanted an error")
+	}
+	// Existing Brokers can still be updated.
+	if err := broker("invalid").Validate(apis.WithinUpdate(ctx, broker("invalid"))); err != nil {
+		t.Error("Broker.Validate() of an update in namespace invalid =", err)
+	}
+}
+
 func TestValidSpec(t *testing.T) {
 	bop := eventingduckv1.BackoffPolicyExponential
 	tests := []struct {
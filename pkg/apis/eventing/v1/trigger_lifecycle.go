@@ -23,7 +23,7 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
-var triggerCondSet = apis.NewLivingConditionSet(TriggerConditionBroker, TriggerConditionSubscribed, TriggerConditionDependency, TriggerConditionSubscriberResolved)
+var triggerCondSet = apis.NewLivingConditionSet(TriggerConditionBroker, TriggerConditionSubscribed, TriggerConditionDependencies, TriggerConditionSubscriberResolved)
 
 const (
 	// TriggerConditionReady has status True when all subconditions below have been set to True.
@@ -33,7 +33,12 @@ const (
 
 	TriggerConditionSubscribed apis.ConditionType = "SubscriptionReady"
 
-	TriggerConditionDependency apis.ConditionType = "DependencyReady"
+	// TriggerConditionDependencies has status True when all the dependencies of the Trigger,
+	// including the one of the DependencyAnnotation, are ready.
+	TriggerConditionDependencies apis.ConditionType = "DependenciesReady"
+
+	// Deprecated: use TriggerConditionDependencies instead.
+	TriggerConditionDependency = TriggerConditionDependencies
 
 	TriggerConditionSubscriberResolved apis.ConditionType = "SubscriberResolved"
 
@@ -151,19 +156,19 @@ func (ts *TriggerStatus) MarkSubscriberResolvedUnknown(reason, messageFormat str
 }
 
 func (ts *TriggerStatus) MarkDependencySucceeded() {
-	triggerCondSet.Manage(ts).MarkTrue(TriggerConditionDependency)
+	triggerCondSet.Manage(ts).MarkTrue(TriggerConditionDependencies)
 }
 
 func (ts *TriggerStatus) MarkDependencyFailed(reason, messageFormat string, messageA ...interface{}) {
-	triggerCondSet.Manage(ts).MarkFalse(TriggerConditionDependency, reason, messageFormat, messageA...)
+	triggerCondSet.Manage(ts).MarkFalse(TriggerConditionDependencies, reason, messageFormat, messageA...)
 }
 
 func (ts *TriggerStatus) MarkDependencyUnknown(reason, messageFormat string, messageA ...interface{}) {
-	triggerCondSet.Manage(ts).MarkUnknown(TriggerConditionDependency, reason, messageFormat, messageA...)
+	triggerCondSet.Manage(ts).MarkUnknown(TriggerConditionDependencies, reason, messageFormat, messageA...)
 }
 
 func (ts *TriggerStatus) MarkDependencyNotConfigured() {
-	triggerCondSet.Manage(ts).MarkUnknown(TriggerConditionDependency,
+	triggerCondSet.Manage(ts).MarkUnknown(TriggerConditionDependencies,
 		"DependencyNotConfigured", "Dependency has not yet been reconciled.")
 }
 
@@ -19,6 +19,7 @@ package v1
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/kmeta"
@@ -27,6 +28,7 @@ import (
 const (
 	// DependencyAnnotation is the annotation key used to mark the sources that the Trigger depends on.
 	// This will be used when the kn client creates a source and trigger pair for the user such that the trigger only receives events produced by the paired source.
+	// Deprecated: use the Dependencies of the TriggerSpec instead, the annotation being one more dependency.
 	DependencyAnnotation = "knative.dev/dependency"
 
 	// InjectionAnnotation is the annotation key used to enable knative eventing
@@ -81,6 +83,26 @@ type TriggerSpec struct {
 	// +optional
 	Filter *TriggerFilter `json:"filter,omitempty"`
 
+	// Filters is a list of filters of the CloudEvents Subscriptions API
+	// dialects, including CloudEvents SQL expressions. Only the events
+	// passing all of them, and the Filter, will be sent to the Subscriber.
+	//
+	// +optional
+	Filters []SubscriptionsAPIFilter `json:"filters,omitempty"`
+
+	// Delivery limits the rate and the concurrency of the events sent to
+	// the Subscriber, and sets their format.
+	//
+	// +optional
+	Delivery *TriggerDelivery `json:"delivery,omitempty"`
+
+	// Dependencies are the sources, or the other Triggers, of the namespace
+	// of the Trigger that the Trigger depends on. The Trigger is not ready
+	// until all of them are ready.
+	//
+	// +optional
+	Dependencies []duckv1.KReference `json:"dependencies,omitempty"`
+
 	// Subscriber is the addressable that receives events from the Broker that pass the Filter. It
 	// is required.
 	Subscriber duckv1.Destination `json:"subscriber"`
@@ -98,11 +120,79 @@ type TriggerFilter struct {
 	Attributes TriggerFilterAttributes `json:"attributes,omitempty"`
 }
 
+// TriggerDelivery is the flow control and the format of the events sent to
+// the Subscriber, the events exceeding the limits waiting for their turn.
+type TriggerDelivery struct {
+	// RateLimit is the maximum number of events per second sent to the
+	// Subscriber, bursts of up to a second of events being allowed. Not
+	// limited by default.
+	//
+	// +optional
+	RateLimit *int32 `json:"rateLimit,omitempty"`
+
+	// MaxInFlight is the maximum number of events being sent to the
+	// Subscriber at the same time. Not limited by default.
+	//
+	// +optional
+	MaxInFlight *int32 `json:"maxInFlight,omitempty"`
+
+	// Format is the format the events are converted to before being sent
+	// to the Subscriber. The events are sent as they were received by
+	// default.
+	//
+	// +optional
+	Format *eventingduckv1.DeliveryFormat `json:"format,omitempty"`
+}
+
 // TriggerFilterAttributes is a map of context attribute names to values for
 // filtering by equality. Only exact matches will pass the filter. You can use the value ''
 // to indicate all strings match.
 type TriggerFilterAttributes map[string]string
 
+// SubscriptionsAPIFilter is a filter of the CloudEvents Subscriptions API,
+// setting a single dialect. The filters without any always pass.
+type SubscriptionsAPIFilter struct {
+	// All passes the events passing all the filters.
+	//
+	// +optional
+	All []SubscriptionsAPIFilter `json:"all,omitempty"`
+
+	// Any passes the events passing any of the filters.
+	//
+	// +optional
+	Any []SubscriptionsAPIFilter `json:"any,omitempty"`
+
+	// Not passes the events not passing the filter.
+	//
+	// +optional
+	Not *SubscriptionsAPIFilter `json:"not,omitempty"`
+
+	// Exact passes the events whose context attribute is equal to the
+	// value, a single attribute being set.
+	//
+	// +optional
+	Exact map[string]string `json:"exact,omitempty"`
+
+	// Prefix passes the events whose context attribute starts with the
+	// value, a single attribute being set.
+	//
+	// +optional
+	Prefix map[string]string `json:"prefix,omitempty"`
+
+	// Suffix passes the events whose context attribute ends with the
+	// value, a single attribute being set.
+	//
+	// +optional
+	Suffix map[string]string `json:"suffix,omitempty"`
+
+	// CESQL passes the events the CloudEvents SQL expression evaluates to
+	// true for. The events failing to be evaluated don't pass.
+	// More info: https://github.com/cloudevents/spec/blob/master/cesql/spec.md
+	//
+	// +optional
+	CESQL string `json:"cesql,omitempty"`
+}
+
 // TriggerStatus represents the current state of a Trigger.
 type TriggerStatus struct {
 	// inherits duck/v1 Status, which currently provides:
@@ -23,6 +23,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
@@ -397,6 +399,185 @@ func TestTriggerSpecValidation(t *testing.T) {
 	}
 }
 
+func TestTriggerSpecFiltersValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []SubscriptionsAPIFilter
+		want    *apis.FieldError
+	}{{
+		name: "valid filters",
+		filters: []SubscriptionsAPIFilter{{
+			Exact: map[string]string{"type": "com.example.someevent"},
+		}, {
+			Any: []SubscriptionsAPIFilter{{
+				Prefix: map[string]string{"source": "/mycontext"},
+			}, {
+				Not: &SubscriptionsAPIFilter{
+					Suffix: map[string]string{"subject": ".txt"},
+				},
+			}},
+		}, {
+			All: []SubscriptionsAPIFilter{{
+				CESQL: "myextension LIKE 'my-%' AND EXISTS subject",
+			}, {}},
+		}},
+	}, {
+		name: "multiple dialects",
+		filters: []SubscriptionsAPIFilter{{
+			Exact: map[string]string{"type": "com.example.someevent"},
+			CESQL: "true",
+		}},
+		want: apis.ErrMultipleOneOf("exact", "cesql").ViaFieldIndex("filters", 0),
+	}, {
+		name: "multiple attributes",
+		filters: []SubscriptionsAPIFilter{{
+			Exact: map[string]string{"type": "com.example.someevent", "source": "/mycontext"},
+		}},
+		want: (&apis.FieldError{
+			Message: "Only one attribute can be filtered, use all to filter more",
+			Paths:   []string{"exact"},
+		}).ViaFieldIndex("filters", 0),
+	}, {
+		name: "invalid attribute name",
+		filters: []SubscriptionsAPIFilter{{
+			Prefix: map[string]string{"invALID": "/mycontext"},
+		}},
+		want: (&apis.FieldError{
+			Message: `Invalid attribute name: "invALID"`,
+			Paths:   []string{"prefix"},
+		}).ViaFieldIndex("filters", 0),
+	}, {
+		name: "empty suffix",
+		filters: []SubscriptionsAPIFilter{{
+			Suffix: map[string]string{"subject": ""},
+		}},
+		want: apis.ErrInvalidValue("", "subject").ViaField("suffix").ViaFieldIndex("filters", 0),
+	}, {
+		name: "invalid nested expression",
+		filters: []SubscriptionsAPIFilter{{
+			All: []SubscriptionsAPIFilter{{
+				Not: &SubscriptionsAPIFilter{
+					CESQL: "type =",
+				},
+			}},
+		}},
+		want: (&apis.FieldError{
+			Message: "Invalid CESQL expression",
+			Paths:   []string{"cesql"},
+			Details: "unexpected end of expression",
+		}).ViaField("not").ViaFieldIndex("all", 0).ViaFieldIndex("filters", 0),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := &TriggerSpec{
+				Broker:     "test_broker",
+				Filters:    test.filters,
+				Subscriber: validSubscriber,
+			}
+			got := ts.Validate(context.TODO())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate TriggerSpec (-want, +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerSpecDeliveryValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery *TriggerDelivery
+		want     *apis.FieldError
+	}{{
+		name:     "valid delivery",
+		delivery: &TriggerDelivery{RateLimit: pointer.Int32Ptr(100), MaxInFlight: pointer.Int32Ptr(10)},
+	}, {
+		name:     "empty delivery",
+		delivery: &TriggerDelivery{},
+	}, {
+		name:     "zero rate limit",
+		delivery: &TriggerDelivery{RateLimit: pointer.Int32Ptr(0)},
+		want:     apis.ErrInvalidValue(0, "rateLimit").ViaField("delivery"),
+	}, {
+		name:     "negative max in flight",
+		delivery: &TriggerDelivery{MaxInFlight: pointer.Int32Ptr(-1)},
+		want:     apis.ErrInvalidValue(-1, "maxInFlight").ViaField("delivery"),
+	}, {
+		name:     "valid format",
+		delivery: &TriggerDelivery{Format: &eventingduckv1.DeliveryFormat{DataContentType: pointer.StringPtr(eventingduckv1.DataContentTypeJSON)}},
+	}, {
+		name:     "invalid format",
+		delivery: &TriggerDelivery{Format: &eventingduckv1.DeliveryFormat{DataContentType: pointer.StringPtr(eventingduckv1.DataContentTypeAvro)}},
+		want:     apis.ErrMissingField("schemaRef").ViaField("format").ViaField("delivery"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := &TriggerSpec{
+				Broker:     "test_broker",
+				Delivery:   test.delivery,
+				Subscriber: validSubscriber,
+			}
+			got := ts.Validate(context.TODO())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate TriggerSpec (-want, +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerDependenciesValidation(t *testing.T) {
+	pingSource := duckv1.KReference{APIVersion: "sources.knative.dev/v1beta1", Kind: "PingSource", Name: "ping"}
+	tests := []struct {
+		name string
+		deps []duckv1.KReference
+		want *apis.FieldError
+	}{{
+		name: "valid dependencies",
+		deps: []duckv1.KReference{pingSource, {
+			APIVersion: "eventing.knative.dev/v1",
+			Kind:       "Trigger",
+			Name:       "other-trigger",
+			Namespace:  "test-ns",
+		}},
+	}, {
+		name: "missing name",
+		deps: []duckv1.KReference{pingSource, {APIVersion: "sources.knative.dev/v1", Kind: "ApiServerSource"}},
+		want: apis.ErrMissingField("name").ViaFieldIndex("spec.dependencies", 1),
+	}, {
+		name: "other namespace",
+		deps: []duckv1.KReference{{APIVersion: "sources.knative.dev/v1beta1", Kind: "PingSource", Name: "ping", Namespace: "other-ns"}},
+		want: (&apis.FieldError{
+			Message: `Namespace must be empty or equal to the trigger namespace "test-ns"`,
+			Paths:   []string{"namespace"},
+		}).ViaFieldIndex("spec.dependencies", 0),
+	}, {
+		name: "itself",
+		deps: []duckv1.KReference{{APIVersion: "eventing.knative.dev/v1beta1", Kind: "Trigger", Name: "test-trigger"}},
+		want: (&apis.FieldError{
+			Message: "A trigger cannot depend on itself",
+			Paths:   []string{"name"},
+		}).ViaFieldIndex("spec.dependencies", 0),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tr := &Trigger{
+				ObjectMeta: v1.ObjectMeta{Name: "test-trigger", Namespace: "test-ns"},
+				Spec: TriggerSpec{
+					Broker:       "test_broker",
+					Dependencies: test.deps,
+					Subscriber:   validSubscriber,
+				},
+			}
+			got := tr.Validate(context.TODO())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate Trigger (-want, +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestTriggerImmutableFields(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -132,6 +132,62 @@ func (in *BrokerStatus) DeepCopy() *BrokerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionsAPIFilter) DeepCopyInto(out *SubscriptionsAPIFilter) {
+	*out = *in
+	if in.All != nil {
+		in, out := &in.All, &out.All
+		*out = make([]SubscriptionsAPIFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Any != nil {
+		in, out := &in.Any, &out.Any
+		*out = make([]SubscriptionsAPIFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Not != nil {
+		in, out := &in.Not, &out.Not
+		*out = new(SubscriptionsAPIFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exact != nil {
+		in, out := &in.Exact, &out.Exact
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Suffix != nil {
+		in, out := &in.Suffix, &out.Suffix
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionsAPIFilter.
+func (in *SubscriptionsAPIFilter) DeepCopy() *SubscriptionsAPIFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionsAPIFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Trigger) DeepCopyInto(out *Trigger) {
 	*out = *in
@@ -160,6 +216,37 @@ func (in *Trigger) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerDelivery) DeepCopyInto(out *TriggerDelivery) {
+	*out = *in
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxInFlight != nil {
+		in, out := &in.MaxInFlight, &out.MaxInFlight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Format != nil {
+		in, out := &in.Format, &out.Format
+		*out = new(apisduckv1.DeliveryFormat)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerDelivery.
+func (in *TriggerDelivery) DeepCopy() *TriggerDelivery {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerDelivery)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TriggerFilter) DeepCopyInto(out *TriggerFilter) {
 	*out = *in
@@ -246,6 +333,23 @@ func (in *TriggerSpec) DeepCopyInto(out *TriggerSpec) {
 		*out = new(TriggerFilter)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = make([]SubscriptionsAPIFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Delivery != nil {
+		in, out := &in.Delivery, &out.Delivery
+		*out = new(TriggerDelivery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]duckv1.KReference, len(*in))
+		copy(*out, *in)
+	}
 	in.Subscriber.DeepCopyInto(&out.Subscriber)
 	return
 }
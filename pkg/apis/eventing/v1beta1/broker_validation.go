@@ -19,6 +19,7 @@ package v1beta1
 import (
 	"context"
 
+	"knative.dev/eventing/pkg/apis/eventing"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmp"
 )
@@ -36,6 +37,9 @@ func (b *Broker) Validate(ctx context.Context) *apis.FieldError {
 		errs = errs.Also(apis.ErrMissingField(BrokerClassAnnotationKey))
 	}
 
+	if !apis.IsInUpdate(ctx) {
+		errs = errs.Also(eventing.ValidateNamespaceDefaults(ctx, b.Namespace))
+	}
 	return errs.Also(b.Spec.Validate(withNS).ViaField("spec"))
 }
 
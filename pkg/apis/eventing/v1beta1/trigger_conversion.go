@@ -20,12 +20,14 @@ import (
 	"context"
 	"fmt"
 
+	duckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	duckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	v1 "knative.dev/eventing/pkg/apis/eventing/v1"
 	"knative.dev/pkg/apis"
 )
 
 // ConvertTo implements apis.Convertible
-func (source *Trigger) ConvertTo(_ context.Context, to apis.Convertible) error {
+func (source *Trigger) ConvertTo(ctx context.Context, to apis.Convertible) error {
 	switch sink := to.(type) {
 	case *v1.Trigger:
 		sink.ObjectMeta = source.ObjectMeta
@@ -39,6 +41,20 @@ func (source *Trigger) ConvertTo(_ context.Context, to apis.Convertible) error {
 				sink.Spec.Filter.Attributes[k] = v
 			}
 		}
+		sink.Spec.Filters = convertFiltersTo(source.Spec.Filters)
+		sink.Spec.Dependencies = source.Spec.Dependencies
+		if source.Spec.Delivery != nil {
+			sink.Spec.Delivery = &v1.TriggerDelivery{
+				RateLimit:   source.Spec.Delivery.RateLimit,
+				MaxInFlight: source.Spec.Delivery.MaxInFlight,
+			}
+			if source.Spec.Delivery.Format != nil {
+				sink.Spec.Delivery.Format = &duckv1.DeliveryFormat{}
+				if err := source.Spec.Delivery.Format.ConvertTo(ctx, sink.Spec.Delivery.Format); err != nil {
+					return err
+				}
+			}
+		}
 		sink.Status.Status = source.Status.Status
 		sink.Status.SubscriberURI = source.Status.SubscriberURI
 		return nil
@@ -48,7 +64,7 @@ func (source *Trigger) ConvertTo(_ context.Context, to apis.Convertible) error {
 }
 
 // ConvertFrom implements apis.Convertible
-func (sink *Trigger) ConvertFrom(_ context.Context, from apis.Convertible) error {
+func (sink *Trigger) ConvertFrom(ctx context.Context, from apis.Convertible) error {
 	switch source := from.(type) {
 	case *v1.Trigger:
 		sink.ObjectMeta = source.ObjectMeta
@@ -63,6 +79,20 @@ func (sink *Trigger) ConvertFrom(_ context.Context, from apis.Convertible) error
 				Attributes: attributes,
 			}
 		}
+		sink.Spec.Filters = convertFiltersFrom(source.Spec.Filters)
+		sink.Spec.Dependencies = source.Spec.Dependencies
+		if source.Spec.Delivery != nil {
+			sink.Spec.Delivery = &TriggerDelivery{
+				RateLimit:   source.Spec.Delivery.RateLimit,
+				MaxInFlight: source.Spec.Delivery.MaxInFlight,
+			}
+			if source.Spec.Delivery.Format != nil {
+				sink.Spec.Delivery.Format = &duckv1beta1.DeliveryFormat{}
+				if err := sink.Spec.Delivery.Format.ConvertFrom(ctx, source.Spec.Delivery.Format); err != nil {
+					return err
+				}
+			}
+		}
 		sink.Status.Status = source.Status.Status
 		sink.Status.SubscriberURI = source.Status.SubscriberURI
 		return nil
@@ -70,3 +100,58 @@ func (sink *Trigger) ConvertFrom(_ context.Context, from apis.Convertible) error
 		return fmt.Errorf("unknown version, got: %T", source)
 	}
 }
+
+func convertFiltersTo(filters []SubscriptionsAPIFilter) []v1.SubscriptionsAPIFilter {
+	if filters == nil {
+		return nil
+	}
+	converted := make([]v1.SubscriptionsAPIFilter, 0, len(filters))
+	for _, f := range filters {
+		c := v1.SubscriptionsAPIFilter{
+			All:    convertFiltersTo(f.All),
+			Any:    convertFiltersTo(f.Any),
+			Exact:  copyAttributes(f.Exact),
+			Prefix: copyAttributes(f.Prefix),
+			Suffix: copyAttributes(f.Suffix),
+			CESQL:  f.CESQL,
+		}
+		if f.Not != nil {
+			c.Not = &convertFiltersTo([]SubscriptionsAPIFilter{*f.Not})[0]
+		}
+		converted = append(converted, c)
+	}
+	return converted
+}
+
+func convertFiltersFrom(filters []v1.SubscriptionsAPIFilter) []SubscriptionsAPIFilter {
+	if filters == nil {
+		return nil
+	}
+	converted := make([]SubscriptionsAPIFilter, 0, len(filters))
+	for _, f := range filters {
+		c := SubscriptionsAPIFilter{
+			All:    convertFiltersFrom(f.All),
+			Any:    convertFiltersFrom(f.Any),
+			Exact:  copyAttributes(f.Exact),
+			Prefix: copyAttributes(f.Prefix),
+			Suffix: copyAttributes(f.Suffix),
+			CESQL:  f.CESQL,
+		}
+		if f.Not != nil {
+			c.Not = &convertFiltersFrom([]v1.SubscriptionsAPIFilter{*f.Not})[0]
+		}
+		converted = append(converted, c)
+	}
+	return converted
+}
+
+func copyAttributes(attrs map[string]string) map[string]string {
+	if attrs == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		copied[k] = v
+	}
+	return copied
+}
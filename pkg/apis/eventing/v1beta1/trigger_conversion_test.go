@@ -22,7 +22,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	v1 "knative.dev/eventing/pkg/apis/eventing/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -138,6 +141,32 @@ func TestTriggerConversionRoundTripV1beta1(t *testing.T) {
 				Filter: &TriggerFilter{
 					Attributes: TriggerFilterAttributes{"source": "mysource", "type": "mytype"},
 				},
+				Filters: []SubscriptionsAPIFilter{{
+					Exact: map[string]string{"type": "mytype"},
+				}, {
+					Any: []SubscriptionsAPIFilter{{
+						Prefix: map[string]string{"source": "my"},
+					}, {
+						Not: &SubscriptionsAPIFilter{
+							Suffix: map[string]string{"subject": ".txt"},
+						},
+					}},
+				}, {
+					CESQL: "EXISTS myext",
+				}},
+				Dependencies: []duckv1.KReference{{
+					APIVersion: "sources.knative.dev/v1beta1",
+					Kind:       "PingSource",
+					Name:       "ping",
+				}},
+				Delivery: &TriggerDelivery{
+					RateLimit:   pointer.Int32Ptr(10),
+					MaxInFlight: pointer.Int32Ptr(2),
+					Format: &eventingduckv1beta1.DeliveryFormat{
+						DataContentType: pointer.StringPtr(eventingduckv1beta1.DataContentTypeAvro),
+						SchemaRef:       apis.HTTP("schemas.example.com/order.avsc"),
+					},
+				},
 				Subscriber: duckv1.Destination{
 					Ref: &duckv1.KReference{
 						Kind:       "subscriberKind",
@@ -267,6 +296,32 @@ func TestTriggerConversionRoundTripV1(t *testing.T) {
 				Filter: &v1.TriggerFilter{
 					Attributes: v1.TriggerFilterAttributes{"source": "mysource", "type": "mytype"},
 				},
+				Filters: []v1.SubscriptionsAPIFilter{{
+					Exact: map[string]string{"type": "mytype"},
+				}, {
+					Any: []v1.SubscriptionsAPIFilter{{
+						Prefix: map[string]string{"source": "my"},
+					}, {
+						Not: &v1.SubscriptionsAPIFilter{
+							Suffix: map[string]string{"subject": ".txt"},
+						},
+					}},
+				}, {
+					CESQL: "EXISTS myext",
+				}},
+				Dependencies: []duckv1.KReference{{
+					APIVersion: "sources.knative.dev/v1beta1",
+					Kind:       "PingSource",
+					Name:       "ping",
+				}},
+				Delivery: &v1.TriggerDelivery{
+					RateLimit:   pointer.Int32Ptr(10),
+					MaxInFlight: pointer.Int32Ptr(2),
+					Format: &eventingduckv1.DeliveryFormat{
+						DataContentType: pointer.StringPtr(eventingduckv1.DataContentTypeAvro),
+						SchemaRef:       apis.HTTP("schemas.example.com/order.avsc"),
+					},
+				},
 				Subscriber: duckv1.Destination{
 					Ref: &duckv1.KReference{
 						Kind:       "subscriberKind",
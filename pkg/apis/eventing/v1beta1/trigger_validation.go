@@ -23,9 +23,13 @@ import (
 	"regexp"
 
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/kmp"
 
+	"knative.dev/eventing/pkg/eventfilter/cesql"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var (
@@ -37,6 +41,9 @@ var (
 func (t *Trigger) Validate(ctx context.Context) *apis.FieldError {
 	errs := t.Spec.Validate(ctx).ViaField("spec")
 	errs = t.validateAnnotation(errs, DependencyAnnotation, t.validateDependencyAnnotation)
+	for i, dep := range t.Spec.Dependencies {
+		errs = errs.Also(t.validateDependency(ctx, dep).ViaFieldIndex("spec.dependencies", i))
+	}
 	errs = t.validateAnnotation(errs, InjectionAnnotation, t.validateInjectionAnnotation)
 	return errs
 }
@@ -61,13 +68,113 @@ func (ts *TriggerSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
+	for i, f := range ts.Filters {
+		errs = errs.Also(f.Validate(ctx).ViaFieldIndex("filters", i))
+	}
+
 	if fe := ts.Subscriber.Validate(ctx); fe != nil {
 		errs = errs.Also(fe.ViaField("subscriber"))
 	}
 
+	if ts.Delivery != nil {
+		errs = errs.Also(ts.Delivery.Validate(ctx).ViaField("delivery"))
+	}
+
+	return errs
+}
+
+// Validate the TriggerDelivery.
+func (d *TriggerDelivery) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if d.RateLimit != nil && *d.RateLimit <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(*d.RateLimit, "rateLimit"))
+	}
+	if d.MaxInFlight != nil && *d.MaxInFlight <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(*d.MaxInFlight, "maxInFlight"))
+	}
+	if fe := d.Format.Validate(ctx); fe != nil {
+		errs = errs.Also(fe.ViaField("format"))
+	}
 	return errs
 }
 
+// Validate the SubscriptionsAPIFilter.
+func (f *SubscriptionsAPIFilter) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+
+	var dialects []string
+	if len(f.All) != 0 {
+		dialects = append(dialects, "all")
+	}
+	if len(f.Any) != 0 {
+		dialects = append(dialects, "any")
+	}
+	if f.Not != nil {
+		dialects = append(dialects, "not")
+	}
+	if len(f.Exact) != 0 {
+		dialects = append(dialects, "exact")
+	}
+	if len(f.Prefix) != 0 {
+		dialects = append(dialects, "prefix")
+	}
+	if len(f.Suffix) != 0 {
+		dialects = append(dialects, "suffix")
+	}
+	if f.CESQL != "" {
+		dialects = append(dialects, "cesql")
+	}
+	if len(dialects) > 1 {
+		errs = errs.Also(apis.ErrMultipleOneOf(dialects...))
+	}
+
+	for i, sub := range f.All {
+		errs = errs.Also(sub.Validate(ctx).ViaFieldIndex("all", i))
+	}
+	for i, sub := range f.Any {
+		errs = errs.Also(sub.Validate(ctx).ViaFieldIndex("any", i))
+	}
+	if f.Not != nil {
+		errs = errs.Also(f.Not.Validate(ctx).ViaField("not"))
+	}
+	errs = errs.Also(validateFilterAttribute(f.Exact, "exact", true))
+	errs = errs.Also(validateFilterAttribute(f.Prefix, "prefix", false))
+	errs = errs.Also(validateFilterAttribute(f.Suffix, "suffix", false))
+	if f.CESQL != "" {
+		if _, err := cesql.Parse(f.CESQL); err != nil {
+			errs = errs.Also(&apis.FieldError{
+				Message: "Invalid CESQL expression",
+				Paths:   []string{"cesql"},
+				Details: err.Error(),
+			})
+		}
+	}
+	return errs
+}
+
+// validateFilterAttribute verifies the attribute of the dialect filtering
+// a single one.
+func validateFilterAttribute(attrs map[string]string, dialect string, allowEmpty bool) *apis.FieldError {
+	if len(attrs) > 1 {
+		return &apis.FieldError{
+			Message: "Only one attribute can be filtered, use all to filter more",
+			Paths:   []string{dialect},
+		}
+	}
+	for attr, value := range attrs {
+		if !validAttributeName.MatchString(attr) {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid attribute name: %q", attr),
+				Paths:   []string{dialect},
+			}
+		}
+		if value == "" && !allowEmpty {
+			return apis.ErrInvalidValue(value, attr).ViaField(dialect)
+		}
+	}
+	return nil
+}
+
 // CheckImmutableFields checks that any immutable fields were not changed.
 func (t *Trigger) CheckImmutableFields(ctx context.Context, original *Trigger) *apis.FieldError {
 	if original == nil {
@@ -138,6 +245,30 @@ func (t *Trigger) validateDependencyAnnotation(dependencyAnnotation string) *api
 	return errs
 }
 
+func (t *Trigger) validateDependency(ctx context.Context, dep duckv1.KReference) *apis.FieldError {
+	errs := dep.Validate(ctx)
+	if dep.Namespace != "" && dep.Namespace != t.GetNamespace() {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("Namespace must be empty or equal to the trigger namespace %q", t.GetNamespace()),
+			Paths:   []string{"namespace"},
+		})
+	}
+	if IsTriggerReference(dep.APIVersion, dep.Kind) && dep.Name == t.GetName() {
+		errs = errs.Also(&apis.FieldError{
+			Message: "A trigger cannot depend on itself",
+			Paths:   []string{"name"},
+		})
+	}
+	return errs
+}
+
+// IsTriggerReference returns whether the apiVersion and kind of a reference
+// are the ones of a Trigger, of any version.
+func IsTriggerReference(apiVersion, kind string) bool {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	return err == nil && gv.Group == SchemeGroupVersion.Group && kind == "Trigger"
+}
+
 func (t *Trigger) validateInjectionAnnotation(injectionAnnotation string) *apis.FieldError {
 	if injectionAnnotation != "enabled" && injectionAnnotation != "disabled" {
 		return &apis.FieldError{
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package feature holds the feature flags of config-features.
+package feature
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// FlagsConfigName is the name of the ConfigMap of the feature flags.
+	FlagsConfigName = "config-features"
+
+	// EventTypeAutoCreate creates the EventTypes of the events the source
+	// adapters emit, owned by their source.
+	EventTypeAutoCreate = "eventtype-auto-create"
+
+	// TopologyAwareDispatch makes the Broker filters prefer the endpoints of
+	// their zone when sending the events to the Trigger subscribers.
+	TopologyAwareDispatch = "topology-aware-dispatch"
+
+	// AdmissionSinkResolution makes the webhook resolve the sinks of the
+	// sources when they are created or their sink changes, rejecting the
+	// sources whose sink isn't addressable.
+	AdmissionSinkResolution = "admission-sink-resolution"
+)
+
+// Flag is the state of a feature.
+type Flag string
+
+const (
+	// Enabled turns a feature on.
+	Enabled Flag = "enabled"
+	// Disabled turns a feature off, the default.
+	Disabled Flag = "disabled"
+)
+
+// Flags are the states of the features, by name.
+type Flags map[string]Flag
+
+// NewFlagsConfigFromMap creates the Flags of the ConfigMap data.
+func NewFlagsConfigFromMap(data map[string]string) (Flags, error) {
+	flags := Flags{}
+	for name, value := range data {
+		if strings.HasPrefix(name, "_") {
+			// The examples and the like.
+			continue
+		}
+		switch flag := Flag(strings.ToLower(strings.TrimSpace(value))); flag {
+		case Enabled, Disabled:
+			flags[name] = flag
+		default:
+			return nil, fmt.Errorf("invalid value %q of the feature %s, expected %s or %s", value, name, Enabled, Disabled)
+		}
+	}
+	return flags, nil
+}
+
+// NewFlagsConfigFromConfigMap creates the Flags of config-features.
+func NewFlagsConfigFromConfigMap(config *corev1.ConfigMap) (Flags, error) {
+	return NewFlagsConfigFromMap(config.Data)
+}
+
+// IsEnabled tells whether the feature is enabled, the features being
+// disabled by default.
+func (f Flags) IsEnabled(name string) bool {
+	return f[name] == Enabled
+}
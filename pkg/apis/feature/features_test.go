@@ -0,0 +1,67 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package feature
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestNewFlagsConfigFromConfigMap(t *testing.T) {
+	flags, err := NewFlagsConfigFromConfigMap(&corev1.ConfigMap{Data: map[string]string{
+		EventTypeAutoCreate: "Enabled",
+		"other":             "disabled",
+		"_example":          "anything",
+	}})
+	if err != nil {
+		t.Fatal("NewFlagsConfigFromConfigMap =", err)
+	}
+	if !flags.IsEnabled(EventTypeAutoCreate) {
+		t.Errorf("Expected %s to be enabled", EventTypeAutoCreate)
+	}
+	if flags.IsEnabled("other") || flags.IsEnabled("unknown") {
+		t.Error("Expected the other features to be disabled")
+	}
+	if len(flags) != 2 {
+		t.Errorf("Expected 2 flags, got %v", flags)
+	}
+}
+
+func TestNewFlagsConfigFromMapInvalid(t *testing.T) {
+	if _, err := NewFlagsConfigFromMap(map[string]string{EventTypeAutoCreate: "yes"}); err == nil {
+		t.Error("Expected an error for an invalid flag")
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: FlagsConfigName},
+		Data:       map[string]string{AdmissionSinkResolution: "enabled"},
+	})
+
+	if flags := FromContext(store.ToContext(context.Background())); !flags.IsEnabled(AdmissionSinkResolution) {
+		t.Errorf("Expected %s to be enabled, got %v", AdmissionSinkResolution, flags)
+	}
+	if flags := FromContext(context.Background()); len(flags) != 0 {
+		t.Errorf("Expected no flags without a store, got %v", flags)
+	}
+}
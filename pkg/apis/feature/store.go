@@ -0,0 +1,76 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package feature
+
+import (
+	"context"
+
+	"knative.dev/pkg/configmap"
+)
+
+type flagsKey struct{}
+
+// FromContext extracts the Flags from the context, empty when none are
+// attached, all the features being disabled.
+func FromContext(ctx context.Context) Flags {
+	if flags, ok := ctx.Value(flagsKey{}).(Flags); ok {
+		return flags
+	}
+	return Flags{}
+}
+
+// ToContext attaches the Flags to the context.
+func ToContext(ctx context.Context, flags Flags) context.Context {
+	return context.WithValue(ctx, flagsKey{}, flags)
+}
+
+// Store is a typed wrapper around configmap.UntypedStore watching the feature
+// flags of config-features, for the webhook.
+type Store struct {
+	*configmap.UntypedStore
+}
+
+// NewStore creates a Store of the feature flags, optionally calling the
+// functions when config-features is updated.
+func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value interface{})) *Store {
+	return &Store{
+		UntypedStore: configmap.NewUntypedStore(
+			"features",
+			logger,
+			configmap.Constructors{
+				FlagsConfigName: NewFlagsConfigFromConfigMap,
+			},
+			onAfterStore...,
+		),
+	}
+}
+
+// ToContext attaches the current feature flags to the context.
+func (s *Store) ToContext(ctx context.Context) context.Context {
+	return ToContext(ctx, s.Load())
+}
+
+// Load returns the current feature flags.
+func (s *Store) Load() Flags {
+	flags := Flags{}
+	if loaded, ok := s.UntypedLoad(FlagsConfigName).(Flags); ok {
+		for name, flag := range loaded {
+			flags[name] = flag
+		}
+	}
+	return flags
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+)
+
+func TestDeliveryWithErrorPolicy(t *testing.T) {
+	continueOnError, abortOnError, deadLetterOnError := ErrorPolicyContinue, ErrorPolicyAbort, ErrorPolicyDeadLetter
+	next := &duckv1.Destination{URI: apis.HTTP("next.example.com")}
+	deadLetterSink := &duckv1.Destination{URI: apis.HTTP("dls.example.com")}
+
+	tests := map[string]struct {
+		delivery *eventingduckv1.DeliverySpec
+		timeout  *string
+		onError  *ErrorPolicyType
+		next     *duckv1.Destination
+		want     *eventingduckv1.DeliverySpec
+	}{
+		"unset": {
+			delivery: &eventingduckv1.DeliverySpec{Retry: ptr.Int32(3)},
+			next:     next,
+			want:     &eventingduckv1.DeliverySpec{Retry: ptr.Int32(3)},
+		},
+		"no delivery": {
+			next: next,
+		},
+		"timeout": {
+			delivery: &eventingduckv1.DeliverySpec{Retry: ptr.Int32(3), Timeout: ptr.String("PT1S")},
+			timeout:  ptr.String("PT5S"),
+			want:     &eventingduckv1.DeliverySpec{Retry: ptr.Int32(3), Timeout: ptr.String("PT5S")},
+		},
+		"continue": {
+			delivery: &eventingduckv1.DeliverySpec{Retry: ptr.Int32(3)},
+			onError:  &continueOnError,
+			next:     next,
+			want:     &eventingduckv1.DeliverySpec{Retry: ptr.Int32(3), DeadLetterSink: next},
+		},
+		"continue without next": {
+			onError: &continueOnError,
+			want:    &eventingduckv1.DeliverySpec{},
+		},
+		"abort": {
+			delivery: &eventingduckv1.DeliverySpec{DeadLetterSink: deadLetterSink},
+			onError:  &abortOnError,
+			next:     next,
+			want:     &eventingduckv1.DeliverySpec{},
+		},
+		"dead letter": {
+			delivery: &eventingduckv1.DeliverySpec{DeadLetterSink: deadLetterSink},
+			onError:  &deadLetterOnError,
+			next:     next,
+			want:     &eventingduckv1.DeliverySpec{DeadLetterSink: deadLetterSink},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			before := tc.delivery.DeepCopy()
+			got := DeliveryWithErrorPolicy(tc.delivery, tc.timeout, tc.onError, tc.next)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error("Unexpected delivery (-want, +got):", diff)
+			}
+			if diff := cmp.Diff(before, tc.delivery); diff != "" {
+				t.Error("The delivery of the step was changed (-before, +after):", diff)
+			}
+		})
+	}
+}
@@ -105,6 +105,9 @@ func (ps *ParallelStatus) PropagateSubscriptionStatuses(filterSubscriptions []*m
 		} else {
 			allReady = false
 		}
+		if dlsCondition := s.Status.GetCondition(messagingv1.SubscriptionConditionDeadLetterSinkResolved); dlsCondition != nil {
+			ps.BranchStatuses[i].SubscriptionStatus.DeadLetterSinkCondition = dlsCondition.DeepCopy()
+		}
 
 		fs := filterSubscriptions[i]
 		ps.BranchStatuses[i].FilterSubscriptionStatus = ParallelSubscriptionStatus{
@@ -237,6 +237,25 @@ func TestParallelPropagateSubscriptionStatuses(t *testing.T) {
 	}
 }
 
+func TestParallelPropagateDeadLetterSinkConditions(t *testing.T) {
+	withDeadLetterSink := getSubscription("sub1", true)
+	withDeadLetterSink.Status.MarkDeadLetterSinkResolved()
+
+	ps := ParallelStatus{}
+	ps.PropagateSubscriptionStatuses(
+		[]*messagingv1.Subscription{getSubscription("fsub0", true), getSubscription("fsub1", true)},
+		[]*messagingv1.Subscription{getSubscription("sub0", true), withDeadLetterSink},
+	)
+
+	if c := ps.BranchStatuses[0].SubscriptionStatus.DeadLetterSinkCondition; c != nil {
+		t.Error("Unexpected dead letter sink condition of the branch without dead letter sink:", c)
+	}
+	c := ps.BranchStatuses[1].SubscriptionStatus.DeadLetterSinkCondition
+	if c == nil || c.Status != corev1.ConditionTrue {
+		t.Error("Unexpected dead letter sink condition:", c)
+	}
+}
+
 func TestParallelPropagateChannelStatuses(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -99,6 +99,18 @@ type ParallelBranch struct {
 	// This includes things like retries, DLQ, etc.
 	// +optional
 	Delivery *eventingduckv1.DeliverySpec `json:"delivery,omitempty"`
+
+	// Timeout is the timeout of each request sent to the subscriber, in
+	// ISO-8601 duration format, overriding the one of the delivery.
+	// +optional
+	Timeout *string `json:"timeout,omitempty"`
+
+	// OnError is what happens to the events the subscriber fails to handle:
+	// continue sends them unchanged to the reply of the branch, abort drops
+	// them and deadLetter sends them to the dead letter sink of the delivery.
+	// They go where the underlying channel sends them when unset.
+	// +optional
+	OnError *ErrorPolicyType `json:"onError,omitempty"`
 }
 
 // ParallelStatus represents the current state of a Parallel.
@@ -147,6 +159,13 @@ type ParallelSubscriptionStatus struct {
 
 	// ReadyCondition indicates whether the Subscription is ready or not.
 	ReadyCondition apis.Condition `json:"ready"`
+
+	// DeadLetterSinkCondition indicates whether the destination of the events
+	// the subscriber fails to handle, the reply of the branch for onError
+	// continue, has been resolved. It is only set for the subscriber
+	// Subscription, when the failed events are sent somewhere.
+	// +optional
+	DeadLetterSinkCondition *apis.Condition `json:"deadLetterSink,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -102,6 +102,9 @@ func (ss *SequenceStatus) PropagateSubscriptionStatuses(subscriptions []*messagi
 		} else {
 			allReady = false
 		}
+		if dlsCondition := s.Status.GetCondition(messagingv1.SubscriptionConditionDeadLetterSinkResolved); dlsCondition != nil {
+			ss.SubscriptionStatuses[i].DeadLetterSinkCondition = dlsCondition.DeepCopy()
+		}
 
 	}
 	if allReady {
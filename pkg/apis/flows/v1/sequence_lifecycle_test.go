@@ -259,6 +259,22 @@ func TestSequencePropagateSubscriptionStatuses(t *testing.T) {
 	}
 }
 
+func TestSequencePropagateDeadLetterSinkConditions(t *testing.T) {
+	withDeadLetterSink := getSubscription("sub1", true)
+	withDeadLetterSink.Status.MarkDeadLetterSinkNotResolved("Unresolvable", "Failed to resolve the dead letter sink")
+
+	ps := SequenceStatus{}
+	ps.PropagateSubscriptionStatuses([]*messagingv1.Subscription{getSubscription("sub0", true), withDeadLetterSink})
+
+	if c := ps.SubscriptionStatuses[0].DeadLetterSinkCondition; c != nil {
+		t.Error("Unexpected dead letter sink condition of the step without dead letter sink:", c)
+	}
+	c := ps.SubscriptionStatuses[1].DeadLetterSinkCondition
+	if c == nil || c.Status != corev1.ConditionFalse || c.Reason != "Unresolvable" {
+		t.Error("Unexpected dead letter sink condition:", c)
+	}
+}
+
 func TestSequencePropagateChannelStatuses(t *testing.T) {
 	tests := []struct {
 		name     string
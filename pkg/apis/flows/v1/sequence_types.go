@@ -91,6 +91,18 @@ type SequenceStep struct {
 	// This includes things like retries, DLQ, etc.
 	// +optional
 	Delivery *eventingduckv1.DeliverySpec `json:"delivery,omitempty"`
+
+	// Timeout is the timeout of each request sent to the subscriber, in
+	// ISO-8601 duration format, overriding the one of the delivery.
+	// +optional
+	Timeout *string `json:"timeout,omitempty"`
+
+	// OnError is what happens to the events the subscriber fails to handle:
+	// continue passes them on to the next step, abort drops them and
+	// deadLetter sends them to the dead letter sink of the delivery. They go
+	// where the underlying channel sends them when unset.
+	// +optional
+	OnError *ErrorPolicyType `json:"onError,omitempty"`
 }
 
 type SequenceChannelStatus struct {
@@ -107,6 +119,12 @@ type SequenceSubscriptionStatus struct {
 
 	// ReadyCondition indicates whether the Subscription is ready or not.
 	ReadyCondition apis.Condition `json:"ready"`
+
+	// DeadLetterSinkCondition indicates whether the destination of the events
+	// the step fails to handle, the next step for onError continue, has been
+	// resolved. It is only set when the failed events are sent somewhere.
+	// +optional
+	DeadLetterSinkCondition *apis.Condition `json:"deadLetterSink,omitempty"`
 }
 
 // SequenceStatus represents the current state of a Sequence.
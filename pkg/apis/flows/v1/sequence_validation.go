@@ -64,5 +64,9 @@ func (ss *SequenceStep) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
+	if ee := validateErrorPolicy(ctx, ss.Timeout, ss.OnError, ss.Delivery); ee != nil {
+		errs = errs.Also(ee)
+	}
+
 	return errs
 }
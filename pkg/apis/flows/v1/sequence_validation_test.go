@@ -250,6 +250,8 @@ func TestSequenceSpecValidate(t *testing.T) {
 }
 
 func TestSequenceStepValidate(t *testing.T) {
+	continueOnError, deadLetterOnError, unknownOnError := ErrorPolicyContinue, ErrorPolicyDeadLetter, ErrorPolicyType("retry")
+	timeout, invalidTimeout := "PT5S", "5s"
 	tests := []struct {
 		name string
 		ss   *SequenceStep
@@ -290,6 +292,51 @@ func TestSequenceStepValidate(t *testing.T) {
 				return errs.Also(apis.ErrInvalidValue("invalid delay", "delivery.backoffDelay"))
 			}(),
 		},
+		{
+			name: "valid timeout and onError",
+			ss: &SequenceStep{
+				Destination: getValidDestination(),
+				Timeout:     &timeout,
+				OnError:     &continueOnError,
+			},
+			want: nil,
+		},
+		{
+			name: "invalid timeout",
+			ss: &SequenceStep{
+				Destination: getValidDestination(),
+				Timeout:     &invalidTimeout,
+			},
+			want: apis.ErrInvalidValue(invalidTimeout, "timeout"),
+		},
+		{
+			name: "unknown onError",
+			ss: &SequenceStep{
+				Destination: getValidDestination(),
+				OnError:     &unknownOnError,
+			},
+			want: apis.ErrInvalidValue(unknownOnError, "onError"),
+		},
+		{
+			name: "onError deadLetter without dead letter sink",
+			ss: &SequenceStep{
+				Destination: getValidDestination(),
+				Delivery:    getValidDelivery(),
+				OnError:     &deadLetterOnError,
+			},
+			want: apis.ErrMissingField("delivery.deadLetterSink"),
+		},
+		{
+			name: "onError continue with a dead letter sink",
+			ss: &SequenceStep{
+				Destination: getValidDestination(),
+				Delivery: &eventingduckv1.DeliverySpec{
+					DeadLetterSink: getValidDestinationRef(),
+				},
+				OnError: &continueOnError,
+			},
+			want: apis.ErrGeneric("onError continue can't be combined with a dead letter sink", "onError", "delivery.deadLetterSink"),
+		},
 	}
 
 	for _, test := range tests {
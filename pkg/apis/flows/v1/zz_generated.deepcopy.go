@@ -24,6 +24,7 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	apisduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	messagingv1 "knative.dev/eventing/pkg/apis/messaging/v1"
+	apis "knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
@@ -74,6 +75,16 @@ func (in *ParallelBranch) DeepCopyInto(out *ParallelBranch) {
 		*out = new(apisduckv1.DeliverySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.OnError != nil {
+		in, out := &in.OnError, &out.OnError
+		*out = new(ErrorPolicyType)
+		**out = **in
+	}
 	return
 }
 
@@ -221,6 +232,11 @@ func (in *ParallelSubscriptionStatus) DeepCopyInto(out *ParallelSubscriptionStat
 	*out = *in
 	out.Subscription = in.Subscription
 	in.ReadyCondition.DeepCopyInto(&out.ReadyCondition)
+	if in.DeadLetterSinkCondition != nil {
+		in, out := &in.DeadLetterSinkCondition, &out.DeadLetterSinkCondition
+		*out = new(apis.Condition)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -387,6 +403,16 @@ func (in *SequenceStep) DeepCopyInto(out *SequenceStep) {
 		*out = new(apisduckv1.DeliverySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.OnError != nil {
+		in, out := &in.OnError, &out.OnError
+		*out = new(ErrorPolicyType)
+		**out = **in
+	}
 	return
 }
 
@@ -405,6 +431,11 @@ func (in *SequenceSubscriptionStatus) DeepCopyInto(out *SequenceSubscriptionStat
 	*out = *in
 	out.Subscription = in.Subscription
 	in.ReadyCondition.DeepCopyInto(&out.ReadyCondition)
+	if in.DeadLetterSinkCondition != nil {
+		in, out := &in.DeadLetterSinkCondition, &out.DeadLetterSinkCondition
+		*out = new(apis.Condition)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
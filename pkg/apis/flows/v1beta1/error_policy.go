@@ -0,0 +1,66 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	"context"
+
+	"knative.dev/pkg/apis"
+
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
+)
+
+// ErrorPolicyType is the type of the policies applied to the events a step or
+// a branch fails to handle once its retries are exhausted.
+type ErrorPolicyType string
+
+const (
+	// ErrorPolicyContinue passes the failed events on unchanged, to the next
+	// step of a Sequence or to the reply of a Parallel branch.
+	ErrorPolicyContinue ErrorPolicyType = "continue"
+
+	// ErrorPolicyAbort drops the failed events, ending their flow.
+	ErrorPolicyAbort ErrorPolicyType = "abort"
+
+	// ErrorPolicyDeadLetter sends the failed events to the dead letter sink of
+	// the delivery.
+	ErrorPolicyDeadLetter ErrorPolicyType = "deadLetter"
+)
+
+func validateErrorPolicy(ctx context.Context, timeout *string, onError *ErrorPolicyType, delivery *eventingduckv1beta1.DeliverySpec) *apis.FieldError {
+	var errs *apis.FieldError
+	if timeout != nil {
+		errs = errs.Also((&eventingduckv1beta1.DeliverySpec{Timeout: timeout}).Validate(ctx))
+	}
+	if onError == nil {
+		return errs
+	}
+	hasDeadLetterSink := delivery != nil && delivery.DeadLetterSink != nil
+	switch *onError {
+	case ErrorPolicyContinue, ErrorPolicyAbort:
+		if hasDeadLetterSink {
+			errs = errs.Also(apis.ErrGeneric("onError "+string(*onError)+" can't be combined with a dead letter sink", "onError", "delivery.deadLetterSink"))
+		}
+	case ErrorPolicyDeadLetter:
+		if !hasDeadLetterSink {
+			errs = errs.Also(apis.ErrMissingField("delivery.deadLetterSink"))
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(*onError, "onError"))
+	}
+	return errs
+}
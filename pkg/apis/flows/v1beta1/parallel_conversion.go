@@ -42,6 +42,8 @@ func (source *Parallel) ConvertTo(ctx context.Context, obj apis.Convertible) err
 				Filter:     b.Filter,
 				Subscriber: b.Subscriber,
 				Reply:      b.Reply,
+				Timeout:    b.Timeout,
+				OnError:    (*v1.ErrorPolicyType)(b.OnError),
 			}
 
 			if b.Delivery != nil {
@@ -73,16 +75,18 @@ func (source *Parallel) ConvertTo(ctx context.Context, obj apis.Convertible) err
 			for i, b := range source.Status.BranchStatuses {
 				sink.Status.BranchStatuses[i] = v1.ParallelBranchStatus{
 					FilterSubscriptionStatus: v1.ParallelSubscriptionStatus{
-						Subscription:   b.FilterSubscriptionStatus.Subscription,
-						ReadyCondition: b.FilterSubscriptionStatus.ReadyCondition,
+						Subscription:            b.FilterSubscriptionStatus.Subscription,
+						ReadyCondition:          b.FilterSubscriptionStatus.ReadyCondition,
+						DeadLetterSinkCondition: b.FilterSubscriptionStatus.DeadLetterSinkCondition,
 					},
 					FilterChannelStatus: v1.ParallelChannelStatus{
 						Channel:        b.FilterChannelStatus.Channel,
 						ReadyCondition: b.FilterChannelStatus.ReadyCondition,
 					},
 					SubscriptionStatus: v1.ParallelSubscriptionStatus{
-						Subscription:   b.SubscriptionStatus.Subscription,
-						ReadyCondition: b.SubscriptionStatus.ReadyCondition,
+						Subscription:            b.SubscriptionStatus.Subscription,
+						ReadyCondition:          b.SubscriptionStatus.ReadyCondition,
+						DeadLetterSinkCondition: b.SubscriptionStatus.DeadLetterSinkCondition,
 					},
 				}
 			}
@@ -107,6 +111,8 @@ func (sink *Parallel) ConvertFrom(ctx context.Context, obj apis.Convertible) err
 				Filter:     b.Filter,
 				Subscriber: b.Subscriber,
 				Reply:      b.Reply,
+				Timeout:    b.Timeout,
+				OnError:    (*ErrorPolicyType)(b.OnError),
 			}
 			if b.Delivery != nil {
 				sink.Spec.Branches[i].Delivery = &eventingduckv1beta1.DeliverySpec{}
@@ -136,16 +142,18 @@ func (sink *Parallel) ConvertFrom(ctx context.Context, obj apis.Convertible) err
 			for i, b := range source.Status.BranchStatuses {
 				sink.Status.BranchStatuses[i] = ParallelBranchStatus{
 					FilterSubscriptionStatus: ParallelSubscriptionStatus{
-						Subscription:   b.FilterSubscriptionStatus.Subscription,
-						ReadyCondition: b.FilterSubscriptionStatus.ReadyCondition,
+						Subscription:            b.FilterSubscriptionStatus.Subscription,
+						ReadyCondition:          b.FilterSubscriptionStatus.ReadyCondition,
+						DeadLetterSinkCondition: b.FilterSubscriptionStatus.DeadLetterSinkCondition,
 					},
 					FilterChannelStatus: ParallelChannelStatus{
 						Channel:        b.FilterChannelStatus.Channel,
 						ReadyCondition: b.FilterChannelStatus.ReadyCondition,
 					},
 					SubscriptionStatus: ParallelSubscriptionStatus{
-						Subscription:   b.SubscriptionStatus.Subscription,
-						ReadyCondition: b.SubscriptionStatus.ReadyCondition,
+						Subscription:            b.SubscriptionStatus.Subscription,
+						ReadyCondition:          b.SubscriptionStatus.ReadyCondition,
+						DeadLetterSinkCondition: b.SubscriptionStatus.DeadLetterSinkCondition,
 					},
 				}
 			}
@@ -40,6 +40,8 @@ func (source *Sequence) ConvertTo(ctx context.Context, obj apis.Convertible) err
 		for i, s := range source.Spec.Steps {
 			sink.Spec.Steps[i] = v1.SequenceStep{
 				Destination: s.Destination,
+				Timeout:     s.Timeout,
+				OnError:     (*v1.ErrorPolicyType)(s.OnError),
 			}
 
 			if s.Delivery != nil {
@@ -65,8 +67,9 @@ func (source *Sequence) ConvertTo(ctx context.Context, obj apis.Convertible) err
 			sink.Status.SubscriptionStatuses = make([]v1.SequenceSubscriptionStatus, len(source.Status.SubscriptionStatuses))
 			for i, s := range source.Status.SubscriptionStatuses {
 				sink.Status.SubscriptionStatuses[i] = v1.SequenceSubscriptionStatus{
-					Subscription:   s.Subscription,
-					ReadyCondition: s.ReadyCondition,
+					Subscription:            s.Subscription,
+					ReadyCondition:          s.ReadyCondition,
+					DeadLetterSinkCondition: s.DeadLetterSinkCondition,
 				}
 			}
 		}
@@ -97,6 +100,8 @@ func (sink *Sequence) ConvertFrom(ctx context.Context, obj apis.Convertible) err
 		for i, s := range source.Spec.Steps {
 			sink.Spec.Steps[i] = SequenceStep{
 				Destination: s.Destination,
+				Timeout:     s.Timeout,
+				OnError:     (*ErrorPolicyType)(s.OnError),
 			}
 			if s.Delivery != nil {
 				sink.Spec.Steps[i].Delivery = &eventingduckv1beta1.DeliverySpec{}
@@ -122,8 +127,9 @@ func (sink *Sequence) ConvertFrom(ctx context.Context, obj apis.Convertible) err
 			sink.Status.SubscriptionStatuses = make([]SequenceSubscriptionStatus, len(source.Status.SubscriptionStatuses))
 			for i, s := range source.Status.SubscriptionStatuses {
 				sink.Status.SubscriptionStatuses[i] = SequenceSubscriptionStatus{
-					Subscription:   s.Subscription,
-					ReadyCondition: s.ReadyCondition,
+					Subscription:            s.Subscription,
+					ReadyCondition:          s.ReadyCondition,
+					DeadLetterSinkCondition: s.DeadLetterSinkCondition,
 				}
 			}
 		}
@@ -37,6 +37,13 @@ const (
 
 	// SubscriptionConditionChannelReady has status True when the channel has marked the subscriber as 'ready'
 	SubscriptionConditionChannelReady apis.ConditionType = "ChannelReady"
+
+	// SubscriptionConditionDeadLetterSinkResolved has status True when the
+	// spec.delivery.deadLetterSink has been resolved to the URI in
+	// status.physicalSubscription.deadLetterSinkUri. It is only set when a
+	// dead letter sink is. It doesn't affect the readiness, the failures to
+	// resolve it being reported by ReferencesResolved already.
+	SubscriptionConditionDeadLetterSinkResolved apis.ConditionType = "DeadLetterSinkResolved"
 )
 
 // GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
@@ -113,3 +120,19 @@ func (ss *SubscriptionStatus) MarkChannelUnknown(reason, messageFormat string, m
 func (ss *SubscriptionStatus) MarkNotAddedToChannel(reason, messageFormat string, messageA ...interface{}) {
 	SubCondSet.Manage(ss).MarkFalse(SubscriptionConditionAddedToChannel, reason, messageFormat, messageA...)
 }
+
+// MarkDeadLetterSinkResolved sets the DeadLetterSinkResolved condition to True state.
+func (ss *SubscriptionStatus) MarkDeadLetterSinkResolved() {
+	SubCondSet.Manage(ss).MarkTrue(SubscriptionConditionDeadLetterSinkResolved)
+}
+
+// MarkDeadLetterSinkNotResolved sets the DeadLetterSinkResolved condition to False state.
+func (ss *SubscriptionStatus) MarkDeadLetterSinkNotResolved(reason, messageFormat string, messageA ...interface{}) {
+	SubCondSet.Manage(ss).MarkFalse(SubscriptionConditionDeadLetterSinkResolved, reason, messageFormat, messageA...)
+}
+
+// ClearDeadLetterSinkResolved removes the DeadLetterSinkResolved condition,
+// for the Subscriptions without a dead letter sink.
+func (ss *SubscriptionStatus) ClearDeadLetterSinkResolved() {
+	_ = SubCondSet.Manage(ss).ClearCondition(SubscriptionConditionDeadLetterSinkResolved)
+}
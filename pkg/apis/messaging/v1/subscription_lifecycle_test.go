@@ -273,3 +273,32 @@ func TestSubscriptionIsReady(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscriptionDeadLetterSinkResolved(t *testing.T) {
+	ss := &SubscriptionStatus{}
+	ss.InitializeConditions()
+	ss.MarkReferencesResolved()
+	ss.MarkChannelReady()
+	ss.MarkAddedToChannel()
+
+	ss.MarkDeadLetterSinkNotResolved("Unresolvable", "could not resolve")
+	if c := ss.GetCondition(SubscriptionConditionDeadLetterSinkResolved); !c.IsFalse() || c.Severity != apis.ConditionSeverityInfo {
+		t.Errorf("DeadLetterSinkResolved = %v, want False with the info severity", c)
+	}
+	if !ss.IsReady() {
+		t.Error("Subscription not ready, the dead letter sink shouldn't affect the readiness")
+	}
+
+	ss.MarkDeadLetterSinkResolved()
+	if c := ss.GetCondition(SubscriptionConditionDeadLetterSinkResolved); !c.IsTrue() {
+		t.Errorf("DeadLetterSinkResolved = %v, want True", c)
+	}
+
+	ss.ClearDeadLetterSinkResolved()
+	if c := ss.GetCondition(SubscriptionConditionDeadLetterSinkResolved); c != nil {
+		t.Errorf("DeadLetterSinkResolved = %v, want none", c)
+	}
+	if !ss.IsReady() {
+		t.Error("Subscription not ready after clearing DeadLetterSinkResolved")
+	}
+}
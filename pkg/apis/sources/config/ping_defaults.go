@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Knative Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// PingDefaultsConfigName is the name of config map for the defaults and
+	// limits of the PingSources.
+	PingDefaultsConfigName = "config-ping-defaults"
+
+	// IntervalFloorKey is the key in the ConfigMap of the shortest interval
+	// PingSources can fire at.
+	IntervalFloorKey = "interval-floor"
+
+	// DefaultIntervalFloor is the shortest interval when IntervalFloorKey
+	// isn't set.
+	DefaultIntervalFloor = time.Second
+
+	// DataMaxSizeKey is the key in the ConfigMap of the largest inline data
+	// of the PingSources, a quantity of bytes like 64Ki. The data isn't
+	// limited when it isn't set.
+	DataMaxSizeKey = "data-max-size"
+)
+
+// NewPingDefaultsConfigFromMap creates a PingDefaults from the supplied Map
+func NewPingDefaultsConfigFromMap(data map[string]string) (*PingDefaults, error) {
+	nc := &PingDefaults{IntervalFloor: DefaultIntervalFloor}
+
+	if value, present := data[IntervalFloorKey]; present && value != "" {
+		floor, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", IntervalFloorKey, err)
+		}
+		if floor < time.Second || floor%time.Second != 0 {
+			return nil, fmt.Errorf("%q must be a positive number of seconds, got %v", IntervalFloorKey, floor)
+		}
+		nc.IntervalFloor = floor
+	}
+
+	if value, present := data[DataMaxSizeKey]; present && value != "" {
+		size, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", DataMaxSizeKey, err)
+		}
+		if size.Sign() <= 0 {
+			return nil, fmt.Errorf("%q must be a positive quantity, got %v", DataMaxSizeKey, value)
+		}
+		nc.DataMaxSize = size.Value()
+	}
+	return nc, nil
+}
+
+// NewPingDefaultsConfigFromConfigMap creates a PingDefaults from the supplied configMap
+func NewPingDefaultsConfigFromConfigMap(config *corev1.ConfigMap) (*PingDefaults, error) {
+	return NewPingDefaultsConfigFromMap(config.Data)
+}
+
+// PingDefaults includes the defaults and limits of the PingSources enforced
+// by the webhook.
+type PingDefaults struct {
+	// IntervalFloor is the shortest interval PingSources can fire at.
+	IntervalFloor time.Duration `json:"intervalFloor,omitempty"`
+	// DataMaxSize is the largest inline data of the PingSources in bytes,
+	// not limited when 0.
+	DataMaxSize int64 `json:"dataMaxSize,omitempty"`
+}
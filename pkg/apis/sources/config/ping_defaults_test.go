@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Knative Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewPingDefaultsConfigFromMap(t *testing.T) {
+	tests := map[string]struct {
+		data    map[string]string
+		want    *PingDefaults
+		wantErr bool
+	}{
+		"empty": {
+			data: map[string]string{},
+			want: &PingDefaults{IntervalFloor: DefaultIntervalFloor},
+		},
+		"interval floor": {
+			data: map[string]string{IntervalFloorKey: "10s"},
+			want: &PingDefaults{IntervalFloor: 10 * time.Second},
+		},
+		"invalid interval floor": {
+			data:    map[string]string{IntervalFloorKey: "ten seconds"},
+			wantErr: true,
+		},
+		"sub-second interval floor": {
+			data:    map[string]string{IntervalFloorKey: "500ms"},
+			wantErr: true,
+		},
+		"fractional interval floor": {
+			data:    map[string]string{IntervalFloorKey: "1500ms"},
+			wantErr: true,
+		},
+		"data max size": {
+			data: map[string]string{DataMaxSizeKey: "64Ki"},
+			want: &PingDefaults{IntervalFloor: DefaultIntervalFloor, DataMaxSize: 64 * 1024},
+		},
+		"invalid data max size": {
+			data:    map[string]string{DataMaxSizeKey: "large"},
+			wantErr: true,
+		},
+		"negative data max size": {
+			data:    map[string]string{DataMaxSizeKey: "-1Ki"},
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewPingDefaultsConfigFromMap(tc.data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("NewPingDefaultsConfigFromMap() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error("Unexpected defaults (-want, +got):", diff)
+			}
+		})
+	}
+}
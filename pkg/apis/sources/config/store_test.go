@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Knative Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestStoreLoadWithContext(t *testing.T) {
+	store := NewStore(logtesting.TestLogger(t))
+
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: PingDefaultsConfigName},
+		Data:       map[string]string{IntervalFloorKey: "5s"},
+	})
+
+	config := FromContextOrDefaults(store.ToContext(context.Background()))
+	if diff := cmp.Diff(&PingDefaults{IntervalFloor: 5 * time.Second}, config.PingDefaults); diff != "" {
+		t.Error("Unexpected defaults config (-want, +got):", diff)
+	}
+}
+
+func TestStoreLoadWithContextOrDefaults(t *testing.T) {
+	config := FromContextOrDefaults(context.Background())
+	if diff := cmp.Diff(&PingDefaults{IntervalFloor: DefaultIntervalFloor}, config.PingDefaults); diff != "" {
+		t.Error("Unexpected defaults config (-want, +got):", diff)
+	}
+}
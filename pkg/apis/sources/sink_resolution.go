@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/resolver"
+)
+
+type sinkResolverKey struct{}
+
+// WithSinkResolver notes on the context the resolver the validation of the
+// sources resolves their sinks with. The webhook sets it when the
+// admission-sink-resolution feature is enabled.
+func WithSinkResolver(ctx context.Context, r *resolver.URIResolver) context.Context {
+	return context.WithValue(ctx, sinkResolverKey{}, r)
+}
+
+// GetSinkResolver returns the resolver of the sinks of the context, nil when
+// the sinks aren't resolved at admission.
+func GetSinkResolver(ctx context.Context) *resolver.URIResolver {
+	r, _ := ctx.Value(sinkResolverKey{}).(*resolver.URIResolver)
+	return r
+}
+
+// ValidateSinkResolvable resolves the sink of the source with the resolver of
+// the context, when set, reporting the sinks failing to resolve. The sink
+// isn't resolved when an update leaves it unchanged, original being the sink
+// before the update, for the sources whose sink went away to still be
+// updated.
+func ValidateSinkResolvable(ctx context.Context, source kmeta.Accessor, sink duckv1.Destination, original *duckv1.Destination) *apis.FieldError {
+	r := GetSinkResolver(ctx)
+	if r == nil || (original != nil && equality.Semantic.DeepEqual(sink, *original)) {
+		return nil
+	}
+	if sink.Ref != nil && sink.Ref.Namespace == "" {
+		ref := *sink.Ref
+		ref.Namespace = source.GetNamespace()
+		sink.Ref = &ref
+	}
+	if _, err := r.URIFromDestinationV1(ctx, sink, source); err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprint("the sink can't be resolved: ", err),
+			Paths:   []string{apis.CurrentField},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sources
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/client/injection/ducks/duck/v1/addressable"
+	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
+	"knative.dev/pkg/resolver"
+)
+
+func newSinkResolver(t *testing.T, objects ...runtime.Object) *resolver.URIResolver {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	gv := schema.GroupVersion{Group: "eventing.knative.dev", Version: "v1"}
+	scheme.AddKnownTypeWithName(gv.WithKind("Broker"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gv.WithKind("BrokerList"), &unstructured.UnstructuredList{})
+	ctx, _ := fakedynamicclient.With(context.Background(), scheme, objects...)
+	ctx = addressable.WithDuck(ctx)
+	return resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+}
+
+func TestValidateSinkResolvable(t *testing.T) {
+	broker := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "eventing.knative.dev/v1",
+		"kind":       "Broker",
+		"metadata": map[string]interface{}{
+			"namespace": "ns",
+			"name":      "ready",
+		},
+		"status": map[string]interface{}{
+			"address": map[string]interface{}{
+				"url": "http://broker.ns.svc.cluster.local",
+			},
+		},
+	}}
+	brokerSink := func(name string) duckv1.Destination {
+		return duckv1.Destination{Ref: &duckv1.KReference{APIVersion: "eventing.knative.dev/v1", Kind: "Broker", Name: name}}
+	}
+	source := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "source"}}
+	ctx := WithSinkResolver(context.Background(), newSinkResolver(t, broker))
+
+	tests := map[string]struct {
+		ctx      context.Context
+		sink     duckv1.Destination
+		original *duckv1.Destination
+		wantErr  bool
+	}{
+		"not resolved at admission": {
+			ctx:  context.Background(),
+			sink: brokerSink("missing"),
+		},
+		"addressable": {
+			ctx:  ctx,
+			sink: brokerSink("ready"),
+		},
+		"uri": {
+			ctx:  ctx,
+			sink: duckv1.Destination{URI: apis.HTTP("example.com")},
+		},
+		"missing": {
+			ctx:     ctx,
+			sink:    brokerSink("missing"),
+			wantErr: true,
+		},
+		"unchanged by an update": {
+			ctx:      ctx,
+			sink:     brokerSink("missing"),
+			original: &duckv1.Destination{Ref: brokerSink("missing").Ref},
+		},
+		"changed by an update": {
+			ctx:      ctx,
+			sink:     brokerSink("missing"),
+			original: &duckv1.Destination{URI: apis.HTTP("example.com")},
+			wantErr:  true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateSinkResolvable(tc.ctx, source, tc.sink, tc.original)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateSinkResolvable() = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -36,6 +36,10 @@ const (
 
 	// ApiServerConditionSufficientPermissions has status True when the ApiServerSource has sufficient permissions to access resources.
 	ApiServerConditionSufficientPermissions apis.ConditionType = "SufficientPermissions"
+
+	// ApiServerConditionSinkDeliverable has status False when the deliveries of the ApiServerSource to its
+	// sink fail, as reported by the adapter. The ApiServerSource is not Ready until a delivery succeeds.
+	ApiServerConditionSinkDeliverable apis.ConditionType = "SinkDeliverable"
 )
 
 var apiserverCondSet = apis.NewLivingConditionSet(
@@ -120,6 +124,23 @@ func (s *ApiServerSourceStatus) MarkNoSufficientPermissions(reason, messageForma
 	apiserverCondSet.Manage(s).MarkFalse(ApiServerConditionSufficientPermissions, reason, messageFormat, messageA...)
 }
 
+// MarkSinkDeliverable sets the condition that the deliveries of the source to its sink succeed.
+func (s *ApiServerSourceStatus) MarkSinkDeliverable() {
+	apiserverCondSet.Manage(s).MarkTrue(ApiServerConditionSinkDeliverable)
+}
+
+// MarkSinkUndeliverable sets the condition that the deliveries of the source to its sink fail,
+// marking it not ready.
+func (s *ApiServerSourceStatus) MarkSinkUndeliverable(reason, messageFormat string, messageA ...interface{}) {
+	apiserverCondSet.Manage(s).MarkFalse(ApiServerConditionSinkDeliverable, reason, messageFormat, messageA...)
+	apiserverCondSet.Manage(s).MarkFalse(ApiServerConditionReady, reason, messageFormat, messageA...)
+}
+
+// ClearSinkDeliverable removes the condition of the deliveries of the source, not reported yet.
+func (s *ApiServerSourceStatus) ClearSinkDeliverable() {
+	_ = apiserverCondSet.Manage(s).ClearCondition(ApiServerConditionSinkDeliverable)
+}
+
 // IsReady returns true if the resource is ready overall.
 func (s *ApiServerSourceStatus) IsReady() bool {
 	return apiserverCondSet.Manage(s).IsHappy()
@@ -80,6 +80,17 @@ type ApiServerSourceSpec struct {
 	// source. Defaults to default if not set.
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// SendInitialEvents sends an add event for each existing object of the
+	// Resources when the source starts, and when it lists them again.
+	// +optional
+	SendInitialEvents bool `json:"sendInitialEvents,omitempty"`
+
+	// ResyncPeriod is the ISO-8601 duration, e.g. PT1H, after which the add
+	// events of the existing objects are sent again. Requires
+	// SendInitialEvents.
+	// +optional
+	ResyncPeriod *string `json:"resyncPeriod,omitempty"`
 }
 
 // ApiServerSourceStatus defines the observed state of ApiServerSource
@@ -104,7 +115,8 @@ type APIVersionKind struct {
 	Kind string `json:"kind"`
 }
 
-// APIVersionKindSelector is an APIVersion Kind tuple with a LabelSelector.
+// APIVersionKindSelector is an APIVersion Kind tuple with a LabelSelector and
+// a FieldSelector.
 type APIVersionKindSelector struct {
 	// APIVersion - the API version of the resource to watch.
 	APIVersion string `json:"apiVersion"`
@@ -118,6 +130,12 @@ type APIVersionKindSelector struct {
 	// More info: http://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
 	// +optional
 	LabelSelector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// FieldSelector filters this source to objects to those resources pass the
+	// field selector, e.g. spec.nodeName=node-1,status.phase=Running.
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/field-selectors/
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -20,9 +20,14 @@ import (
 	"context"
 	"strings"
 
+	"github.com/rickb777/date/period"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"knative.dev/eventing/pkg/apis/sources"
 )
 
 const (
@@ -33,7 +38,15 @@ const (
 )
 
 func (c *ApiServerSource) Validate(ctx context.Context) *apis.FieldError {
-	return c.Spec.Validate(ctx).ViaField("spec")
+	errs := c.Spec.Validate(ctx).ViaField("spec")
+	if errs == nil {
+		var original *duckv1.Destination
+		if base, ok := apis.GetBaseline(ctx).(*ApiServerSource); ok {
+			original = &base.Spec.Sink
+		}
+		errs = sources.ValidateSinkResolvable(ctx, c, c.Spec.Sink, original).ViaField("spec", "sink")
+	}
+	return errs
 }
 
 func (cs *ApiServerSourceSpec) Validate(ctx context.Context) *apis.FieldError {
@@ -61,6 +74,19 @@ func (cs *ApiServerSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 		if strings.TrimSpace(res.Kind) == "" {
 			errs = errs.Also(apis.ErrMissingField("kind").ViaFieldIndex("resources", i))
 		}
+		if res.FieldSelector != "" {
+			if _, err := fields.ParseSelector(res.FieldSelector); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(res.FieldSelector, "fieldSelector").ViaFieldIndex("resources", i))
+			}
+		}
+	}
+
+	if cs.ResyncPeriod != nil {
+		if p, err := period.Parse(*cs.ResyncPeriod); err != nil || !p.IsPositive() {
+			errs = errs.Also(apis.ErrInvalidValue(*cs.ResyncPeriod, "resyncPeriod"))
+		} else if !cs.SendInitialEvents {
+			errs = errs.Also(apis.ErrGeneric("resyncPeriod requires sendInitialEvents", "resyncPeriod", "sendInitialEvents"))
+		}
 	}
 
 	if cs.ResourceOwner != nil {
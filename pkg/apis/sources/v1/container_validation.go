@@ -21,10 +21,21 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"knative.dev/eventing/pkg/apis/sources"
 )
 
 func (c *ContainerSource) Validate(ctx context.Context) *apis.FieldError {
-	return c.Spec.Validate(ctx).ViaField("spec")
+	errs := c.Spec.Validate(ctx).ViaField("spec")
+	if errs == nil {
+		var original *duckv1.Destination
+		if base, ok := apis.GetBaseline(ctx).(*ContainerSource); ok {
+			original = &base.Spec.Sink
+		}
+		errs = sources.ValidateSinkResolvable(ctx, c, c.Spec.Sink, original).ViaField("spec", "sink")
+	}
+	return errs
 }
 
 func (cs *ContainerSourceSpec) Validate(ctx context.Context) *apis.FieldError {
@@ -44,6 +55,7 @@ func (cs *ContainerSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 			}
 		}
 	}
+	errs = errs.Also(ValidateSinkContainers(&cs.Template).ViaField("template"))
 	return errs
 }
 
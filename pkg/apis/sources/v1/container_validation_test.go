@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
@@ -98,6 +99,20 @@ func TestContainerSourceValidation(t *testing.T) {
 			errs = errs.Also(fe)
 			return errs
 		}(),
+	}, {
+		name: "sink bound to the source container of the sidecars",
+		spec: makeSidecarsContainerSourceSpec("setup, source"),
+	}, {
+		name: "sink bound to unknown containers",
+		spec: makeSidecarsContainerSourceSpec("source,exporter,other"),
+		want: &apis.FieldError{
+			Message: "unknown containers: exporter, other",
+			Paths:   []string{"template.metadata.annotations[" + SinkBindingContainersAnnotation + "]"},
+		},
+	}, {
+		name: "sink bound to no container",
+		spec: makeSidecarsContainerSourceSpec(" , "),
+		want: apis.ErrInvalidValue(" , ", "template.metadata.annotations["+SinkBindingContainersAnnotation+"]"),
 	},
 	}
 
@@ -110,3 +125,31 @@ func TestContainerSourceValidation(t *testing.T) {
 		})
 	}
 }
+
+func makeSidecarsContainerSourceSpec(sinkContainers string) ContainerSourceSpec {
+	return ContainerSourceSpec{
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{SinkBindingContainersAnnotation: sinkContainers},
+			},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{
+					Name:  "setup",
+					Image: "setup-image",
+				}},
+				Containers: []corev1.Container{{
+					Name:  "source",
+					Image: "source-image",
+				}, {
+					Name:  "proxy",
+					Image: "proxy-image",
+				}},
+			},
+		},
+		SourceSpec: duckv1.SourceSpec{
+			Sink: duckv1.Destination{
+				URI: apis.HTTP("sink.ns.svc.cluster.local"),
+			},
+		},
+	}
+}
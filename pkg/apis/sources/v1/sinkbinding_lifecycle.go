@@ -20,15 +20,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
+	"strings"
 
 	"go.uber.org/zap"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/apis/duck"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/tracker"
 )
@@ -94,6 +98,97 @@ func (sbs *SinkBindingStatus) MarkSink(uri *apis.URL) {
 	}
 }
 
+// MarkSinkAvailable sets the condition that the sink answered the last probe.
+func (sbs *SinkBindingStatus) MarkSinkAvailable() {
+	sbCondSet.Manage(sbs).MarkTrue(SinkBindingConditionAvailable)
+}
+
+// MarkSinkUnavailable sets the condition that the sink didn't answer the
+// last probe.
+func (sbs *SinkBindingStatus) MarkSinkUnavailable(reason, messageFormat string, messageA ...interface{}) {
+	sbCondSet.Manage(sbs).MarkFalse(SinkBindingConditionAvailable, reason, messageFormat, messageA...)
+}
+
+// RefreshesSink tells whether the sink is projected into the subject as a
+// file kept up to date.
+func (sb *SinkBinding) RefreshesSink() bool {
+	return sb.Annotations[SinkBindingRefreshAnnotation] == "true" || sb.Annotations[SinkBindingCACertsSecretAnnotation] != ""
+}
+
+// SinkConfigMapName is the name of the ConfigMap holding the sink of the
+// SinkBinding, when refreshed.
+func (sb *SinkBinding) SinkConfigMapName() string {
+	return kmeta.ChildName(sb.Name, "-sinkbinding")
+}
+
+// sinkVolumeName is the name of the volume projecting the refreshed sink.
+const sinkVolumeName = "knative-sinkbinding"
+
+// sinkVolume returns the volume projecting the sink and the CA certificates
+// of sb. They are optional, for the pods not to wait for them.
+func (sb *SinkBinding) sinkVolume() corev1.Volume {
+	optional := true
+	sources := []corev1.VolumeProjection{{
+		ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: sb.SinkConfigMapName()},
+			Items:                []corev1.KeyToPath{{Key: SinkBindingSinkKey, Path: SinkBindingSinkKey}},
+			Optional:             &optional,
+		},
+	}}
+	if secret := sb.Annotations[SinkBindingCACertsSecretAnnotation]; secret != "" {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+				Items:                []corev1.KeyToPath{{Key: SinkBindingCACertsKey, Path: SinkBindingCACertsKey}},
+				Optional:             &optional,
+			},
+		})
+	}
+	return corev1.Volume{
+		Name: sinkVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	}
+}
+
+// refreshEnv returns the environment pointing the container at the
+// projected files.
+func (sb *SinkBinding) refreshEnv() []corev1.EnvVar {
+	env := []corev1.EnvVar{{
+		Name:  "K_SINK_FILE",
+		Value: path.Join(SinkBindingMountPath, SinkBindingSinkKey),
+	}}
+	if sb.Annotations[SinkBindingCACertsSecretAnnotation] != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "K_CA_CERTS_FILE",
+			Value: path.Join(SinkBindingMountPath, SinkBindingCACertsKey),
+		})
+	}
+	return env
+}
+
+// SinkContainers returns the names of the containers of the
+// SinkBindingContainersAnnotation of the pod template annotations, nil for
+// all of them.
+func SinkContainers(annotations map[string]string) sets.String {
+	value, ok := annotations[SinkBindingContainersAnnotation]
+	if !ok {
+		return nil
+	}
+	names := sets.NewString()
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names.Insert(name)
+		}
+	}
+	return names
+}
+
+func bindsContainer(bound sets.String, name string) bool {
+	return bound == nil || bound.Has(name)
+}
+
 // Do implements psbinding.Bindable
 func (sb *SinkBinding) Do(ctx context.Context, ps *duckv1.WithPod) {
 	// First undo so that we can just unconditionally append below.
@@ -120,8 +215,12 @@ func (sb *SinkBinding) Do(ctx context.Context, ps *duckv1.WithPod) {
 		}
 	}
 
+	bound := SinkContainers(ps.Spec.Template.Annotations)
 	spec := ps.Spec.Template.Spec
 	for i := range spec.InitContainers {
+		if !bindsContainer(bound, spec.InitContainers[i].Name) {
+			continue
+		}
 		spec.InitContainers[i].Env = append(spec.InitContainers[i].Env, corev1.EnvVar{
 			Name:  "K_SINK",
 			Value: uri.String(),
@@ -132,6 +231,9 @@ func (sb *SinkBinding) Do(ctx context.Context, ps *duckv1.WithPod) {
 		})
 	}
 	for i := range spec.Containers {
+		if !bindsContainer(bound, spec.Containers[i].Name) {
+			continue
+		}
 		spec.Containers[i].Env = append(spec.Containers[i].Env, corev1.EnvVar{
 			Name:  "K_SINK",
 			Value: uri.String(),
@@ -141,6 +243,23 @@ func (sb *SinkBinding) Do(ctx context.Context, ps *duckv1.WithPod) {
 			Value: ceOverrides,
 		})
 	}
+
+	if !sb.RefreshesSink() {
+		return
+	}
+	// The init containers run before the sink changes, they keep K_SINK.
+	ps.Spec.Template.Spec.Volumes = append(ps.Spec.Template.Spec.Volumes, sb.sinkVolume())
+	for i := range spec.Containers {
+		if !bindsContainer(bound, spec.Containers[i].Name) {
+			continue
+		}
+		spec.Containers[i].Env = append(spec.Containers[i].Env, sb.refreshEnv()...)
+		spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      sinkVolumeName,
+			MountPath: SinkBindingMountPath,
+			ReadOnly:  true,
+		})
+	}
 }
 
 func (sb *SinkBinding) Undo(ctx context.Context, ps *duckv1.WithPod) {
@@ -167,7 +286,7 @@ func (sb *SinkBinding) Undo(ctx context.Context, ps *duckv1.WithPod) {
 		env := make([]corev1.EnvVar, 0, len(spec.Containers[i].Env))
 		for j, ev := range c.Env {
 			switch ev.Name {
-			case "K_SINK", "K_CE_OVERRIDES":
+			case "K_SINK", "K_CE_OVERRIDES", "K_SINK_FILE", "K_CA_CERTS_FILE":
 				continue
 			default:
 				env = append(env, spec.Containers[i].Env[j])
@@ -175,4 +294,25 @@ func (sb *SinkBinding) Undo(ctx context.Context, ps *duckv1.WithPod) {
 		}
 		spec.Containers[i].Env = env
 	}
+	for i, c := range spec.Containers {
+		if len(c.VolumeMounts) == 0 {
+			continue
+		}
+		mounts := make([]corev1.VolumeMount, 0, len(c.VolumeMounts))
+		for _, vm := range c.VolumeMounts {
+			if vm.Name != sinkVolumeName {
+				mounts = append(mounts, vm)
+			}
+		}
+		spec.Containers[i].VolumeMounts = mounts
+	}
+	if len(spec.Volumes) > 0 {
+		volumes := make([]corev1.Volume, 0, len(spec.Volumes))
+		for _, v := range spec.Volumes {
+			if v.Name != sinkVolumeName {
+				volumes = append(volumes, v)
+			}
+		}
+		ps.Spec.Template.Spec.Volumes = volumes
+	}
 }
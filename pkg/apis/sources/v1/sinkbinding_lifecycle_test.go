@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -144,6 +145,17 @@ func TestSinkBindingStatusIsReady(t *testing.T) {
 			return s
 		}(),
 		want: true,
+	}, {
+		name: "mark sink unreachable",
+		s: func() *SinkBindingStatus {
+			s := &SinkBindingStatus{}
+			s.InitializeConditions()
+			s.MarkSink(sink)
+			s.MarkSinkUnavailable("SinkUnreachable", "connection refused")
+			s.MarkBindingAvailable()
+			return s
+		}(),
+		want: true,
 	}}
 
 	for _, test := range tests {
@@ -538,3 +550,241 @@ func TestSinkBindingDoNoURI(t *testing.T) {
 		t.Error("Undo (-want, +got):", cmp.Diff(want, got))
 	}
 }
+
+func TestSinkBindingDoRefresh(t *testing.T) {
+	destination := duckv1.Destination{
+		URI: &apis.URL{
+			Scheme: "https",
+			Host:   "thing.ns.svc.cluster.local",
+		},
+	}
+	optional := true
+	got := &duckv1.WithPod{
+		Spec: duckv1.WithPodSpec{
+			Template: duckv1.PodSpecable{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{
+						Name:  "setup",
+						Image: "busybox",
+					}},
+					Containers: []corev1.Container{{
+						Name:  "blah",
+						Image: "busybox",
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "data",
+							MountPath: "/data",
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "data",
+					}},
+				},
+			},
+		},
+	}
+	want := &duckv1.WithPod{
+		Spec: duckv1.WithPodSpec{
+			Template: duckv1.PodSpecable{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{
+						Name:  "setup",
+						Image: "busybox",
+						Env: []corev1.EnvVar{{
+							Name:  "K_SINK",
+							Value: destination.URI.String(),
+						}, {
+							Name: "K_CE_OVERRIDES",
+						}},
+					}},
+					Containers: []corev1.Container{{
+						Name:  "blah",
+						Image: "busybox",
+						Env: []corev1.EnvVar{{
+							Name:  "K_SINK",
+							Value: destination.URI.String(),
+						}, {
+							Name: "K_CE_OVERRIDES",
+						}, {
+							Name:  "K_SINK_FILE",
+							Value: "/var/run/knative/sinkbinding/sink",
+						}, {
+							Name:  "K_CA_CERTS_FILE",
+							Value: "/var/run/knative/sinkbinding/ca.crt",
+						}},
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "data",
+							MountPath: "/data",
+						}, {
+							Name:      "knative-sinkbinding",
+							MountPath: SinkBindingMountPath,
+							ReadOnly:  true,
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "data",
+					}, {
+						Name: "knative-sinkbinding",
+						VolumeSource: corev1.VolumeSource{
+							Projected: &corev1.ProjectedVolumeSource{
+								Sources: []corev1.VolumeProjection{{
+									ConfigMap: &corev1.ConfigMapProjection{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "binding-sinkbinding"},
+										Items:                []corev1.KeyToPath{{Key: "sink", Path: "sink"}},
+										Optional:             &optional,
+									},
+								}, {
+									Secret: &corev1.SecretProjection{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "sink-ca"},
+										Items:                []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+										Optional:             &optional,
+									},
+								}},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	ctx, _ := fakedynamicclient.With(context.Background(), scheme.Scheme, got)
+	ctx = addressable.WithDuck(ctx)
+	r := resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+	ctx = WithURIResolver(context.Background(), r)
+
+	sb := &SinkBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "binding",
+			Annotations: map[string]string{SinkBindingCACertsSecretAnnotation: "sink-ca"},
+		},
+		Spec: SinkBindingSpec{
+			SourceSpec: duckv1.SourceSpec{
+				Sink: destination,
+			},
+		},
+	}
+	// Binding twice keeps a single volume.
+	sb.Do(ctx, got)
+	sb.Do(ctx, got)
+
+	if !cmp.Equal(got, want) {
+		t.Error("Do (-want, +got):", cmp.Diff(want, got))
+	}
+
+	sb.Undo(ctx, got)
+	if got, want := got.Spec.Template.Spec.Volumes, []corev1.Volume{{Name: "data"}}; !cmp.Equal(got, want) {
+		t.Error("Undo volumes (-want, +got):", cmp.Diff(want, got))
+	}
+	if got, want := got.Spec.Template.Spec.Containers[0].VolumeMounts, []corev1.VolumeMount{{Name: "data", MountPath: "/data"}}; !cmp.Equal(got, want) {
+		t.Error("Undo volume mounts (-want, +got):", cmp.Diff(want, got))
+	}
+	if got := got.Spec.Template.Spec.Containers[0].Env; len(got) != 0 {
+		t.Error("Undo left the environment:", got)
+	}
+}
+
+func TestSinkBindingDoSinkContainers(t *testing.T) {
+	destination := duckv1.Destination{
+		URI: &apis.URL{
+			Scheme: "http",
+			Host:   "thing.ns.svc.cluster.local",
+		},
+	}
+	got := &duckv1.WithPod{
+		Spec: duckv1.WithPodSpec{
+			Template: duckv1.PodSpecable{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{SinkBindingContainersAnnotation: "source"},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{
+						Name:  "setup",
+						Image: "busybox",
+					}},
+					Containers: []corev1.Container{{
+						Name:  "source",
+						Image: "busybox",
+					}, {
+						Name:  "proxy",
+						Image: "busybox",
+						Env: []corev1.EnvVar{{
+							Name:  "K_SINK",
+							Value: "bound before the annotation",
+						}},
+					}},
+				},
+			},
+		},
+	}
+	want := &duckv1.WithPod{
+		Spec: duckv1.WithPodSpec{
+			Template: duckv1.PodSpecable{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{SinkBindingContainersAnnotation: "source"},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{
+						Name:  "setup",
+						Image: "busybox",
+					}},
+					Containers: []corev1.Container{{
+						Name:  "source",
+						Image: "busybox",
+						Env: []corev1.EnvVar{{
+							Name:  "K_SINK",
+							Value: destination.URI.String(),
+						}, {
+							Name: "K_CE_OVERRIDES",
+						}, {
+							Name:  "K_SINK_FILE",
+							Value: "/var/run/knative/sinkbinding/sink",
+						}},
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "knative-sinkbinding",
+							MountPath: SinkBindingMountPath,
+							ReadOnly:  true,
+						}},
+					}, {
+						Name:  "proxy",
+						Image: "busybox",
+						Env:   []corev1.EnvVar{},
+					}},
+				},
+			},
+		},
+	}
+
+	ctx, _ := fakedynamicclient.With(context.Background(), scheme.Scheme, got)
+	ctx = addressable.WithDuck(ctx)
+	r := resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
+	ctx = WithURIResolver(context.Background(), r)
+
+	sb := &SinkBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "binding",
+			Annotations: map[string]string{SinkBindingRefreshAnnotation: "true"},
+		},
+		Spec: SinkBindingSpec{
+			SourceSpec: duckv1.SourceSpec{
+				Sink: destination,
+			},
+		},
+	}
+	sb.Do(ctx, got)
+
+	// The volume is the one of TestSinkBindingDoRefresh.
+	got.Spec.Template.Spec.Volumes = nil
+	if !cmp.Equal(got, want) {
+		t.Error("Do (-want, +got):", cmp.Diff(want, got))
+	}
+}
+
+func TestSinkContainers(t *testing.T) {
+	if got := SinkContainers(nil); got != nil {
+		t.Errorf("SinkContainers(nil) = %v, want nil", got)
+	}
+	got := SinkContainers(map[string]string{SinkBindingContainersAnnotation: "source, exporter,,"})
+	if want := []string{"exporter", "source"}; !cmp.Equal(got.List(), want) {
+		t.Errorf("SinkContainers() = %v, want %v", got.List(), want)
+	}
+}
@@ -75,6 +75,41 @@ const (
 	// SinkBindingConditionSinkProvided is configured to indicate whether the
 	// sink has been properly extracted from the resolver.
 	SinkBindingConditionSinkProvided apis.ConditionType = "SinkProvided"
+
+	// SinkBindingConditionAvailable is configured to indicate whether the
+	// sink answered the last probe of the binder. It doesn't affect the
+	// readiness, the sink being allowed to come up after the subject.
+	SinkBindingConditionAvailable apis.ConditionType = "SinkBindingAvailable"
+)
+
+const (
+	// SinkBindingRefreshAnnotation set to "true" projects the sink into the
+	// containers of the subject as a file kept up to date, at the path of
+	// the K_SINK_FILE environment variable, rather than only as K_SINK,
+	// set once when the pods are created.
+	SinkBindingRefreshAnnotation = "sinkbinding.knative.dev/refresh"
+
+	// SinkBindingCACertsSecretAnnotation names the Secret, in the namespace
+	// of the SinkBinding, holding the CA certificates of the sink in its
+	// ca.crt key. They are projected like the refreshed sink, at the path of
+	// the K_CA_CERTS_FILE environment variable, and picked up on rotation.
+	SinkBindingCACertsSecretAnnotation = "sinkbinding.knative.dev/ca-certs-secret"
+
+	// SinkBindingContainersAnnotation, on the pod template of the subject,
+	// lists the comma separated names of the containers and init containers
+	// the sink is bound to, all of them when it isn't set. It lets the
+	// sidecars of the subject run without the sink.
+	SinkBindingContainersAnnotation = "sinkbinding.knative.dev/containers"
+
+	// SinkBindingMountPath is where the refreshed sink and CA certificates
+	// are mounted in the containers of the subject.
+	SinkBindingMountPath = "/var/run/knative/sinkbinding"
+	// SinkBindingSinkKey is the key of the sink in the ConfigMap of the
+	// SinkBinding, and its file name.
+	SinkBindingSinkKey = "sink"
+	// SinkBindingCACertsKey is the key of the CA certificates in the Secret
+	// of the SinkBinding annotation, and their file name.
+	SinkBindingCACertsKey = "ca.crt"
 )
 
 // SinkBindingStatus communicates the observed state of the SinkBinding (from the controller).
@@ -18,7 +18,11 @@ package v1
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
@@ -28,6 +32,15 @@ func (fb *SinkBinding) Validate(ctx context.Context) *apis.FieldError {
 	if fb.Spec.Subject.Namespace != "" && fb.Namespace != fb.Spec.Subject.Namespace {
 		err = err.Also(apis.ErrInvalidValue(fb.Spec.Subject.Namespace, "spec.subject.namespace"))
 	}
+	if secret, ok := fb.Annotations[SinkBindingCACertsSecretAnnotation]; ok {
+		if msgs := validation.IsDNS1123Subdomain(secret); len(msgs) > 0 {
+			err = err.Also(&apis.FieldError{
+				Message: "invalid CA certificates Secret name",
+				Paths:   []string{"metadata.annotations[" + SinkBindingCACertsSecretAnnotation + "]"},
+				Details: strings.Join(msgs, ", "),
+			})
+		}
+	}
 	return err
 }
 
@@ -36,3 +49,26 @@ func (fbs *SinkBindingSpec) Validate(ctx context.Context) *apis.FieldError {
 	return fbs.Subject.Validate(ctx).ViaField("subject").Also(
 		fbs.Sink.Validate(ctx).ViaField("sink"))
 }
+
+// ValidateSinkContainers validates the SinkBindingContainersAnnotation of
+// the pod template, which must name some of its containers.
+func ValidateSinkContainers(template *corev1.PodTemplateSpec) *apis.FieldError {
+	bound := SinkContainers(template.Annotations)
+	if bound == nil {
+		return nil
+	}
+	path := "metadata.annotations[" + SinkBindingContainersAnnotation + "]"
+	if bound.Len() == 0 {
+		return apis.ErrInvalidValue(template.Annotations[SinkBindingContainersAnnotation], path)
+	}
+	for _, c := range append(template.Spec.InitContainers, template.Spec.Containers...) {
+		bound.Delete(c.Name)
+	}
+	if bound.Len() > 0 {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("unknown containers: %s", strings.Join(bound.List(), ", ")),
+			Paths:   []string{path},
+		}
+	}
+	return nil
+}
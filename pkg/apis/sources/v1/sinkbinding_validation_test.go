@@ -110,6 +110,40 @@ func TestSinkBindingValidation(t *testing.T) {
 			},
 		},
 		want: apis.ErrGeneric("expected at least one, got none", "spec.sink.ref", "spec.sink.uri"),
+	}, {
+		name: "invalid CA certificates secret",
+		in: &SinkBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "matt",
+				Namespace:   "moore",
+				Annotations: map[string]string{SinkBindingCACertsSecretAnnotation: "Not_A_Name"},
+			},
+			Spec: SinkBindingSpec{
+				BindingSpec: duckv1.BindingSpec{
+					Subject: tracker.Reference{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       "jeanne",
+						Namespace:  "moore",
+					},
+				},
+				SourceSpec: duckv1.SourceSpec{
+					Sink: duckv1.Destination{
+						Ref: &duckv1.KReference{
+							APIVersion: "serving.knative.dev/v1",
+							Kind:       "Service",
+							Name:       "gemma",
+							Namespace:  "moore",
+						},
+					},
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid CA certificates Secret name",
+			Paths:   []string{"metadata.annotations[" + SinkBindingCACertsSecretAnnotation + "]"},
+			Details: "a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+		},
 	}}
 
 	for _, test := range tests {
@@ -41,8 +41,9 @@ func (source *ApiServerSource) ConvertTo(ctx context.Context, obj apis.Convertib
 		}
 		for i, v := range source.Spec.Resources {
 			sink.Spec.Resources[i] = v1.APIVersionKindSelector{
-				APIVersion: v.APIVersion,
-				Kind:       v.Kind,
+				APIVersion:    v.APIVersion,
+				Kind:          v.Kind,
+				FieldSelector: v.FieldSelector,
 			}
 
 			if v.LabelSelector != nil {
@@ -81,6 +82,11 @@ func (source *ApiServerSource) ConvertTo(ctx context.Context, obj apis.Convertib
 		}
 
 		sink.Spec.ServiceAccountName = source.Spec.ServiceAccountName
+		sink.Spec.SendInitialEvents = source.Spec.SendInitialEvents
+		if source.Spec.ResyncPeriod != nil {
+			resyncPeriod := *source.Spec.ResyncPeriod
+			sink.Spec.ResyncPeriod = &resyncPeriod
+		}
 
 		// Status
 		source.Status.SourceStatus.DeepCopyInto(&sink.Status.SourceStatus)
@@ -112,6 +118,7 @@ func (sink *ApiServerSource) ConvertFrom(ctx context.Context, obj apis.Convertib
 			sink.Spec.Resources[i] = APIVersionKindSelector{}
 			sink.Spec.Resources[i].APIVersion = v.APIVersion
 			sink.Spec.Resources[i].Kind = v.Kind
+			sink.Spec.Resources[i].FieldSelector = v.FieldSelector
 			if v.LabelSelector != nil {
 				sink.Spec.Resources[i].LabelSelector = v.LabelSelector.DeepCopy()
 			}
@@ -127,6 +134,11 @@ func (sink *ApiServerSource) ConvertFrom(ctx context.Context, obj apis.Convertib
 		}
 
 		sink.Spec.ServiceAccountName = source.Spec.ServiceAccountName
+		sink.Spec.SendInitialEvents = source.Spec.SendInitialEvents
+		if source.Spec.ResyncPeriod != nil {
+			resyncPeriod := *source.Spec.ResyncPeriod
+			sink.Spec.ResyncPeriod = &resyncPeriod
+		}
 
 		// Status
 		source.Status.SourceStatus.DeepCopyInto(&sink.Status.SourceStatus)
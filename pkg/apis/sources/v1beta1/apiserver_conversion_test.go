@@ -27,6 +27,7 @@ import (
 	v1 "knative.dev/eventing/pkg/apis/sources/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
 )
 
 type testObject struct{}
@@ -110,8 +111,9 @@ func TestApiServerSourceConversionRoundTripUp(t *testing.T) {
 						MatchLabels: map[string]string{"A1": "K1"},
 					},
 				}, {
-					APIVersion: "A2",
-					Kind:       "K2",
+					APIVersion:    "A2",
+					Kind:          "K2",
+					FieldSelector: "spec.nodeName=node-2",
 				}},
 				EventMode: "Ref",
 			},
@@ -159,8 +161,9 @@ func TestApiServerSourceConversionRoundTripUp(t *testing.T) {
 						}},
 					},
 				}, {
-					APIVersion: "A2",
-					Kind:       "K2",
+					APIVersion:    "A2",
+					Kind:          "K2",
+					FieldSelector: "spec.nodeName=node-2",
 				}},
 				ResourceOwner: &APIVersionKind{
 					APIVersion: "custom/v1",
@@ -168,6 +171,8 @@ func TestApiServerSourceConversionRoundTripUp(t *testing.T) {
 				},
 				EventMode:          "Resource",
 				ServiceAccountName: "adult",
+				SendInitialEvents:  true,
+				ResyncPeriod:       ptr.String("PT1H"),
 			},
 			Status: ApiServerSourceStatus{
 				SourceStatus: duckv1.SourceStatus{
@@ -258,8 +263,9 @@ func TestApiServerSourceConversionRoundTripDown(t *testing.T) {
 						MatchLabels: map[string]string{"A1": "K1"},
 					},
 				}, {
-					APIVersion: "A2",
-					Kind:       "K2",
+					APIVersion:    "A2",
+					Kind:          "K2",
+					FieldSelector: "spec.nodeName=node-2",
 				}},
 				EventMode: "Ref",
 			},
@@ -302,8 +308,9 @@ func TestApiServerSourceConversionRoundTripDown(t *testing.T) {
 						}},
 					},
 				}, {
-					APIVersion: "A2",
-					Kind:       "K2",
+					APIVersion:    "A2",
+					Kind:          "K2",
+					FieldSelector: "spec.nodeName=node-2",
 				}},
 				ResourceOwner: &v1.APIVersionKind{
 					APIVersion: "custom/v1",
@@ -311,6 +318,8 @@ func TestApiServerSourceConversionRoundTripDown(t *testing.T) {
 				},
 				EventMode:          "Resource",
 				ServiceAccountName: "adult",
+				SendInitialEvents:  true,
+				ResyncPeriod:       ptr.String("PT1H"),
 			},
 			Status: v1.ApiServerSourceStatus{
 				SourceStatus: duckv1.SourceStatus{
@@ -25,6 +25,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"knative.dev/pkg/apis"
+	"knative.dev/pkg/ptr"
 )
 
 func TestAPIServerValidation(t *testing.T) {
@@ -125,6 +126,108 @@ func TestAPIServerValidation(t *testing.T) {
 			},
 		},
 		want: errors.New("missing field(s): resources[0].kind"),
+	}, {
+		name: "valid field selector",
+		spec: ApiServerSourceSpec{
+			EventMode: "Resource",
+			Resources: []APIVersionKindSelector{{
+				APIVersion:    "v1",
+				Kind:          "Pod",
+				FieldSelector: "spec.nodeName=node-1,status.phase!=Running",
+			}},
+			SourceSpec: duckv1.SourceSpec{
+				Sink: duckv1.Destination{
+					Ref: &duckv1.KReference{
+						APIVersion: "v1alpha1",
+						Kind:       "broker",
+						Name:       "default",
+					},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid field selector",
+		spec: ApiServerSourceSpec{
+			EventMode: "Resource",
+			Resources: []APIVersionKindSelector{{
+				APIVersion:    "v1",
+				Kind:          "Pod",
+				FieldSelector: "spec.nodeName",
+			}},
+			SourceSpec: duckv1.SourceSpec{
+				Sink: duckv1.Destination{
+					Ref: &duckv1.KReference{
+						APIVersion: "v1alpha1",
+						Kind:       "broker",
+						Name:       "default",
+					},
+				},
+			},
+		},
+		want: errors.New("invalid value: spec.nodeName: resources[0].fieldSelector"),
+	}, {
+		name: "initial events with resync period",
+		spec: ApiServerSourceSpec{
+			EventMode: "Resource",
+			Resources: []APIVersionKindSelector{{
+				APIVersion: "v1",
+				Kind:       "Pod",
+			}},
+			SourceSpec: duckv1.SourceSpec{
+				Sink: duckv1.Destination{
+					Ref: &duckv1.KReference{
+						APIVersion: "v1alpha1",
+						Kind:       "broker",
+						Name:       "default",
+					},
+				},
+			},
+			SendInitialEvents: true,
+			ResyncPeriod:      ptr.String("PT1H"),
+		},
+		want: nil,
+	}, {
+		name: "invalid resync period",
+		spec: ApiServerSourceSpec{
+			EventMode: "Resource",
+			Resources: []APIVersionKindSelector{{
+				APIVersion: "v1",
+				Kind:       "Pod",
+			}},
+			SourceSpec: duckv1.SourceSpec{
+				Sink: duckv1.Destination{
+					Ref: &duckv1.KReference{
+						APIVersion: "v1alpha1",
+						Kind:       "broker",
+						Name:       "default",
+					},
+				},
+			},
+			SendInitialEvents: true,
+			ResyncPeriod:      ptr.String("1h"),
+		},
+		want: errors.New("invalid value: 1h: resyncPeriod"),
+	}, {
+		name: "resync period without initial events",
+		spec: ApiServerSourceSpec{
+			EventMode: "Resource",
+			Resources: []APIVersionKindSelector{{
+				APIVersion: "v1",
+				Kind:       "Pod",
+			}},
+			SourceSpec: duckv1.SourceSpec{
+				Sink: duckv1.Destination{
+					Ref: &duckv1.KReference{
+						APIVersion: "v1alpha1",
+						Kind:       "broker",
+						Name:       "default",
+					},
+				},
+			},
+			ResyncPeriod: ptr.String("PT1H"),
+		},
+		want: errors.New("resyncPeriod requires sendInitialEvents: resyncPeriod, sendInitialEvents"),
 	}, {
 		name: "owner - invalid apiVersion",
 		spec: ApiServerSourceSpec{
@@ -29,7 +29,8 @@ func (s *PingSource) SetDefaults(ctx context.Context) {
 }
 
 func (ss *PingSourceSpec) SetDefaults(ctx context.Context) {
-	if ss.Schedule == "" {
+	// The PingSources firing at an interval don't have a schedule.
+	if ss.Schedule == "" && ss.Interval == nil {
 		ss.Schedule = defaultSchedule
 	}
 }
@@ -19,8 +19,10 @@ package v1beta1
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestPingSourceSetDefaults(t *testing.T) {
@@ -55,6 +57,18 @@ func TestPingSourceSetDefaults(t *testing.T) {
 				},
 			},
 		},
+		"with interval": {
+			initial: PingSource{
+				Spec: PingSourceSpec{
+					Interval: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+			expected: PingSource{
+				Spec: PingSourceSpec{
+					Interval: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+		},
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
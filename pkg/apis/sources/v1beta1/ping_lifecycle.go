@@ -18,11 +18,13 @@ package v1beta1
 
 import (
 	"fmt"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
 const (
@@ -34,6 +36,34 @@ const (
 
 	// PingSourceConditionDeployed has status True when the PingSource has had it's receive adapter deployment created.
 	PingSourceConditionDeployed apis.ConditionType = "Deployed"
+
+	// PingSourceConditionCompleted has status True when the PingSource has stopped sending events
+	// after its first successful send. It does not contribute to the Ready condition.
+	PingSourceConditionCompleted apis.ConditionType = "Completed"
+
+	// PingSourceConditionEventsValid has status False when the PingSource skipped events not
+	// matching its EventSchema, degrading it. It does not contribute to the Ready condition.
+	PingSourceConditionEventsValid apis.ConditionType = "EventsValid"
+
+	// PingSourceConditionBatchesDelivered has status False when a fire of the PingSource sent only
+	// part of its events, such as of the chunks of an event split to fit the sink, degrading it, and
+	// True once a following fire sent all of them. It does not contribute to the Ready condition.
+	PingSourceConditionBatchesDelivered apis.ConditionType = "BatchesDelivered"
+
+	// PingSourceConditionTemplateKeysResolved has status False when the templates of the PingSource
+	// reference keys missing from its labels or annotations, skipping its fires with the Strict
+	// TemplateMissingKey or rendering the keys empty with the Lenient one, degrading it. It is
+	// cleared once the templates render without missing keys. It does not contribute to the Ready
+	// condition.
+	PingSourceConditionTemplateKeysResolved apis.ConditionType = "TemplateKeysResolved"
+
+	// PingSourceConditionJobHealthy has status False when the adapter disabled the PingSource
+	// after its job panicked on consecutive fires. The PingSource is not Ready until its spec changes.
+	PingSourceConditionJobHealthy apis.ConditionType = "JobHealthy"
+
+	// PingSourceConditionSinkDeliverable has status False when the deliveries of the PingSource to its
+	// sink fail, as reported by the adapter. The PingSource is not Ready until a delivery succeeds.
+	PingSourceConditionSinkDeliverable apis.ConditionType = "SinkDeliverable"
 )
 
 var PingSourceCondSet = apis.NewLivingConditionSet(
@@ -43,8 +73,32 @@ var PingSourceCondSet = apis.NewLivingConditionSet(
 const (
 	// PingSourceEventType is the default PingSource CloudEvent type.
 	PingSourceEventType = "dev.knative.sources.ping"
+
+	// PingSourceSummaryEventType is the CloudEvent type of the periodic
+	// summaries of the fires of a PingSource.
+	PingSourceSummaryEventType = "dev.knative.sources.ping.summary"
+
+	// PingSourceAlertEventType is the CloudEvent type of the alerts sent
+	// when a PingSource starts failing.
+	PingSourceAlertEventType = "dev.knative.sources.ping.alert"
 )
 
+// SinkDestination returns where the events of the PingSource are sent: its
+// Broker when BrokerName is set, its Sink otherwise.
+func (s *PingSource) SinkDestination() duckv1.Destination {
+	if s.Spec.BrokerName != "" {
+		return duckv1.Destination{
+			Ref: &duckv1.KReference{
+				APIVersion: "eventing.knative.dev/v1",
+				Kind:       "Broker",
+				Namespace:  s.Namespace,
+				Name:       s.Spec.BrokerName,
+			},
+		}
+	}
+	return *s.Spec.Sink.DeepCopy()
+}
+
 // GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
 func (*PingSource) GetConditionSet() apis.ConditionSet {
 	return PingSourceCondSet
@@ -55,6 +109,20 @@ func PingSourceSource(namespace, name string) string {
 	return fmt.Sprintf("/apis/v1/namespaces/%s/pingsources/%s", namespace, name)
 }
 
+// PrefixSinkPath returns a copy of uri with prefix prepended to its path.
+func PrefixSinkPath(uri *apis.URL, prefix string) *apis.URL {
+	if uri == nil || prefix == "" {
+		return uri
+	}
+	prefixed := *uri
+	prefixed.Path = strings.TrimSuffix(prefix, "/")
+	if uri.Path != "" {
+		prefixed.Path += "/" + strings.TrimPrefix(uri.Path, "/")
+	}
+	prefixed.RawPath = ""
+	return &prefixed
+}
+
 // GetUntypedSpec returns the spec of the PingSource.
 func (s *PingSource) GetUntypedSpec() interface{} {
 	return s.Spec
@@ -120,3 +188,96 @@ func (s *PingSourceStatus) PropagateDeploymentAvailability(d *appsv1.Deployment)
 		PingSourceCondSet.Manage(s).MarkUnknown(PingSourceConditionDeployed, "DeploymentUnavailable", "The Deployment '%s' is unavailable.", d.Name)
 	}
 }
+
+// MarkCompleted sets the condition that the source stopped sending events after its first successful send.
+func (s *PingSourceStatus) MarkCompleted() {
+	PingSourceCondSet.Manage(s).MarkTrue(PingSourceConditionCompleted)
+}
+
+// IsCompleted returns true if the source stopped sending events after its first successful send.
+func (s *PingSourceStatus) IsCompleted() bool {
+	c := s.GetCondition(PingSourceConditionCompleted)
+	return c != nil && c.IsTrue()
+}
+
+// MarkEventsInvalid sets the condition that the source skipped events not matching its EventSchema.
+func (s *PingSourceStatus) MarkEventsInvalid(reason, messageFormat string, messageA ...interface{}) {
+	PingSourceCondSet.Manage(s).MarkFalse(PingSourceConditionEventsValid, reason, messageFormat, messageA...)
+}
+
+// AreEventsInvalid returns true if the source skipped events not matching its EventSchema.
+func (s *PingSourceStatus) AreEventsInvalid() bool {
+	c := s.GetCondition(PingSourceConditionEventsValid)
+	return c != nil && c.IsFalse()
+}
+
+// MarkBatchPartiallyDelivered sets the condition that a fire of the source sent only part of its
+// events.
+func (s *PingSourceStatus) MarkBatchPartiallyDelivered(reason, messageFormat string, messageA ...interface{}) {
+	PingSourceCondSet.Manage(s).MarkFalse(PingSourceConditionBatchesDelivered, reason, messageFormat, messageA...)
+}
+
+// MarkBatchesDelivered sets the condition that the last fire of the source sent all its events.
+func (s *PingSourceStatus) MarkBatchesDelivered() {
+	PingSourceCondSet.Manage(s).MarkTrue(PingSourceConditionBatchesDelivered)
+}
+
+// IsBatchPartiallyDelivered returns true if a fire of the source sent only part of its events.
+func (s *PingSourceStatus) IsBatchPartiallyDelivered() bool {
+	c := s.GetCondition(PingSourceConditionBatchesDelivered)
+	return c != nil && c.IsFalse()
+}
+
+// MarkTemplateKeysMissing sets the condition that the templates of the source reference missing
+// keys.
+func (s *PingSourceStatus) MarkTemplateKeysMissing(reason, messageFormat string, messageA ...interface{}) {
+	PingSourceCondSet.Manage(s).MarkFalse(PingSourceConditionTemplateKeysResolved, reason, messageFormat, messageA...)
+}
+
+// AreTemplateKeysMissing returns true if the templates of the source reference missing keys.
+func (s *PingSourceStatus) AreTemplateKeysMissing() bool {
+	c := s.GetCondition(PingSourceConditionTemplateKeysResolved)
+	return c != nil && c.IsFalse()
+}
+
+// ClearTemplateKeysMissing removes the condition that the templates of the source reference
+// missing keys, once they render without.
+func (s *PingSourceStatus) ClearTemplateKeysMissing() {
+	_ = PingSourceCondSet.Manage(s).ClearCondition(PingSourceConditionTemplateKeysResolved)
+}
+
+// MarkJobPanicking sets the condition that the adapter disabled the source after its job panicked
+// on consecutive fires, marking it not ready.
+func (s *PingSourceStatus) MarkJobPanicking(reason, messageFormat string, messageA ...interface{}) {
+	PingSourceCondSet.Manage(s).MarkFalse(PingSourceConditionJobHealthy, reason, messageFormat, messageA...)
+	PingSourceCondSet.Manage(s).MarkFalse(PingSourceConditionReady, reason, messageFormat, messageA...)
+}
+
+// IsJobPanicking returns true if the adapter disabled the source after its job panicked.
+func (s *PingSourceStatus) IsJobPanicking() bool {
+	c := s.GetCondition(PingSourceConditionJobHealthy)
+	return c != nil && c.IsFalse()
+}
+
+// ClearJobPanicking removes the condition that the adapter disabled the source, for it to be
+// scheduled again.
+func (s *PingSourceStatus) ClearJobPanicking() {
+	_ = PingSourceCondSet.Manage(s).ClearCondition(PingSourceConditionJobHealthy)
+}
+
+// MarkSinkDeliverable sets the condition that the deliveries of the source to its sink succeed.
+func (s *PingSourceStatus) MarkSinkDeliverable() {
+	PingSourceCondSet.Manage(s).MarkTrue(PingSourceConditionSinkDeliverable)
+}
+
+// MarkSinkUndeliverable sets the condition that the deliveries of the source to its sink fail,
+// marking it not ready.
+func (s *PingSourceStatus) MarkSinkUndeliverable(reason, messageFormat string, messageA ...interface{}) {
+	PingSourceCondSet.Manage(s).MarkFalse(PingSourceConditionSinkDeliverable, reason, messageFormat, messageA...)
+	PingSourceCondSet.Manage(s).MarkFalse(PingSourceConditionReady, reason, messageFormat, messageA...)
+}
+
+// ClearSinkDeliverable removes the condition of the deliveries of the source, not reported yet.
+func (s *PingSourceStatus) ClearSinkDeliverable() {
+	_ = PingSourceCondSet.Manage(s).ClearCondition(PingSourceConditionSinkDeliverable)
+}
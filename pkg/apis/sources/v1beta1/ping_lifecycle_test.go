@@ -23,7 +23,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
 func TestPingSourceGetConditionSet(t *testing.T) {
@@ -245,6 +247,19 @@ func TestPingSourceStatusGetCondition(t *testing.T) {
 			Type:   PingSourceConditionReady,
 			Status: corev1.ConditionUnknown,
 		},
+	}, {
+		name: "mark completed",
+		s: func() *PingSourceStatus {
+			s := &PingSourceStatus{}
+			s.InitializeConditions()
+			s.MarkCompleted()
+			return s
+		}(),
+		condQuery: PingSourceConditionCompleted,
+		want: &apis.Condition{
+			Type:   PingSourceConditionCompleted,
+			Status: corev1.ConditionTrue,
+		},
 	}}
 
 	for _, test := range tests {
@@ -258,3 +273,186 @@ func TestPingSourceStatusGetCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestPingSourceStatusIsCompleted(t *testing.T) {
+	s := &PingSourceStatus{}
+	s.InitializeConditions()
+	if s.IsCompleted() {
+		t.Error("Expected an initialized source not to be completed")
+	}
+
+	s.MarkSink(apis.HTTP("example"))
+	s.PropagateDeploymentAvailability(availableDeployment)
+	s.MarkCompleted()
+	if !s.IsCompleted() {
+		t.Error("Expected the source to be completed")
+	}
+	if !s.IsReady() {
+		t.Error("Expected completion not to affect readiness")
+	}
+}
+
+func TestPingSourceStatusMarkEventsInvalid(t *testing.T) {
+	s := &PingSourceStatus{}
+	s.InitializeConditions()
+	s.MarkSink(apis.HTTP("example"))
+	s.PropagateDeploymentAvailability(availableDeployment)
+	if s.AreEventsInvalid() {
+		t.Error("Expected an initialized source not to emit invalid events")
+	}
+
+	s.MarkEventsInvalid("SchemaMismatch", "event data does not match the schema")
+	if !s.AreEventsInvalid() {
+		t.Error("Expected the source to be marked as emitting invalid events")
+	}
+	if !s.IsReady() {
+		t.Error("Expected invalid events not to affect readiness")
+	}
+}
+
+func TestPingSourceStatusMarkBatchPartiallyDelivered(t *testing.T) {
+	s := &PingSourceStatus{}
+	s.InitializeConditions()
+	s.MarkSink(apis.HTTP("example"))
+	s.PropagateDeploymentAvailability(availableDeployment)
+	if s.IsBatchPartiallyDelivered() {
+		t.Error("Expected an initialized source not to partially deliver its batches")
+	}
+
+	s.MarkBatchPartiallyDelivered("PartialBatchFailure", "Sent %d of the %d events of a fire", 2, 4)
+	if !s.IsBatchPartiallyDelivered() {
+		t.Error("Expected the source to be marked as partially delivering its batches")
+	}
+	if !s.IsReady() {
+		t.Error("Expected partially delivered batches not to affect readiness")
+	}
+
+	s.MarkBatchesDelivered()
+	if s.IsBatchPartiallyDelivered() {
+		t.Error("Expected the source to be marked as delivering its batches")
+	}
+}
+
+func TestPingSourceStatusMarkTemplateKeysMissing(t *testing.T) {
+	s := &PingSourceStatus{}
+	s.InitializeConditions()
+	s.MarkSink(apis.HTTP("example"))
+	s.PropagateDeploymentAvailability(availableDeployment)
+	if s.AreTemplateKeysMissing() {
+		t.Error("Expected an initialized source not to render missing template keys")
+	}
+
+	s.MarkTemplateKeysMissing("MissingTemplateKey", "map has no entry for key %q", "team")
+	if !s.AreTemplateKeysMissing() {
+		t.Error("Expected the source to be marked as rendering missing template keys")
+	}
+	if !s.IsReady() {
+		t.Error("Expected missing template keys not to affect readiness")
+	}
+
+	s.ClearTemplateKeysMissing()
+	if s.AreTemplateKeysMissing() || s.GetCondition(PingSourceConditionTemplateKeysResolved) != nil {
+		t.Error("Expected the missing template keys condition to be cleared")
+	}
+}
+
+func TestPingSourceSinkDestination(t *testing.T) {
+	sink := duckv1.Destination{URI: apis.HTTP("example.com")}
+	tests := []struct {
+		name string
+		spec PingSourceSpec
+		want duckv1.Destination
+	}{{
+		name: "sink",
+		spec: PingSourceSpec{SourceSpec: duckv1.SourceSpec{Sink: sink}},
+		want: sink,
+	}, {
+		name: "broker name",
+		spec: PingSourceSpec{BrokerName: "default"},
+		want: duckv1.Destination{
+			Ref: &duckv1.KReference{
+				APIVersion: "eventing.knative.dev/v1",
+				Kind:       "Broker",
+				Namespace:  "ns",
+				Name:       "default",
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &PingSource{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"},
+				Spec:       test.spec,
+			}
+			if diff := cmp.Diff(test.want, s.SinkDestination()); diff != "" {
+				t.Error("unexpected destination (-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestPrefixSinkPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		uri    *apis.URL
+		prefix string
+		want   *apis.URL
+	}{{
+		name: "no uri",
+	}, {
+		name: "no prefix",
+		uri:  &apis.URL{Scheme: "http", Host: "example.com", Path: "/sink"},
+		want: &apis.URL{Scheme: "http", Host: "example.com", Path: "/sink"},
+	}, {
+		name:   "prefix",
+		uri:    &apis.URL{Scheme: "http", Host: "example.com", Path: "/ns/sink"},
+		prefix: "/gateway",
+		want:   &apis.URL{Scheme: "http", Host: "example.com", Path: "/gateway/ns/sink"},
+	}, {
+		name:   "prefix with trailing slash",
+		uri:    &apis.URL{Scheme: "http", Host: "example.com", Path: "/ns/sink"},
+		prefix: "/gateway/",
+		want:   &apis.URL{Scheme: "http", Host: "example.com", Path: "/gateway/ns/sink"},
+	}, {
+		name:   "no path",
+		uri:    &apis.URL{Scheme: "http", Host: "example.com"},
+		prefix: "/gateway",
+		want:   &apis.URL{Scheme: "http", Host: "example.com", Path: "/gateway"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, PrefixSinkPath(test.uri, test.prefix)); diff != "" {
+				t.Error("unexpected uri (-want, +got) =", diff)
+			}
+		})
+	}
+}
+
+func TestPingSourceStatusMarkJobPanicking(t *testing.T) {
+	s := &PingSourceStatus{}
+	s.InitializeConditions()
+	s.MarkSink(apis.HTTP("example"))
+	s.PropagateDeploymentAvailability(availableDeployment)
+	if s.IsJobPanicking() {
+		t.Error("Expected a ready source not to be panicking")
+	}
+
+	s.MarkJobPanicking("JobPanicking", "job panicked on 5 consecutive fires")
+	if !s.IsJobPanicking() {
+		t.Error("Expected the source to be panicking")
+	}
+	if s.IsReady() {
+		t.Error("Expected a panicking source not to be ready")
+	}
+	if c := s.GetTopLevelCondition(); c.Reason != "JobPanicking" {
+		t.Errorf("Expected the Ready condition reason to be JobPanicking, got %q", c.Reason)
+	}
+
+	s.ClearJobPanicking()
+	s.MarkSink(apis.HTTP("example"))
+	if s.IsJobPanicking() || !s.IsReady() {
+		t.Error("Expected the source to be ready again once cleared")
+	}
+}
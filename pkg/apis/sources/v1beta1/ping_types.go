@@ -19,10 +19,13 @@ package v1beta1
 import (
 	"knative.dev/pkg/apis"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/kmeta"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 )
 
 // +genclient
@@ -58,10 +61,66 @@ type PingSourceSpec struct {
 	//   and modifications of the event sent to the sink.
 	duckv1.SourceSpec `json:",inline"`
 
-	// Schedule is the cronjob schedule. Defaults to `* * * * *`.
+	// BrokerName is the name of a Broker of the PingSource namespace the
+	// events are sent to, instead of Sink.
+	// +optional
+	BrokerName string `json:"brokerName,omitempty"`
+
+	// SinkPathPrefix is prepended to the path of the resolved sink URI, to
+	// address a sink behind a gateway fronting several sinks. It must be
+	// an absolute path.
+	// +optional
+	SinkPathPrefix string `json:"sinkPathPrefix,omitempty"`
+
+	// SinkCAPEM holds the PEM encoded certificates of the CAs the TLS
+	// certificate of the sink is verified against, instead of the system
+	// ones.
+	// +optional
+	SinkCAPEM string `json:"sinkCAPEM,omitempty"`
+
+	// SinkCACerts references the PEM encoded certificates of the CAs the
+	// TLS certificate of the sink is verified against, such as a trust
+	// bundle, instead of the system ones. It can't be set with SinkCAPEM.
+	// +optional
+	SinkCACerts *PingSourceSinkCACerts `json:"sinkCACerts,omitempty"`
+
+	// ShadowSink is a second sink getting a copy of each event, such as for
+	// validating a migration. The copies are sent once, in parallel, and
+	// their failures don't fail the fires.
+	// +optional
+	ShadowSink *duckv1.Destination `json:"shadowSink,omitempty"`
+
+	// AlertSink gets an alert event each time the source starts failing:
+	// on its first failed fire, and on the first one following a fire sent
+	// successfully.
+	// +optional
+	AlertSink *duckv1.Destination `json:"alertSink,omitempty"`
+
+	// Delivery is how the events failing to be sent are retried and, once
+	// the retries are exhausted, where they are dead-lettered. Retries
+	// default to the adapter ones when not set.
+	// +optional
+	Delivery *eventingduckv1.DeliverySpec `json:"delivery,omitempty"`
+
+	// Schedule is the cronjob schedule. Defaults to `* * * * *`. A leading
+	// seconds field is accepted, e.g. `*/15 * * * * *`.
 	// +optional
 	Schedule string `json:"schedule,omitempty"`
 
+	// Schedules are additional cronjob schedules, firing like Schedule.
+	// When several schedules fire at the same time, the events are sent
+	// according to the pingsource.knative.dev/overlap-policy annotation.
+	// +optional
+	Schedules []string `json:"schedules,omitempty"`
+
+	// Interval fires the events every interval, such as 10s, aligned on the
+	// multiples of the interval, instead of on Schedule. It allows the
+	// sub-minute heartbeats, down to the interval-floor of the
+	// config-ping-defaults ConfigMap. It can't be set with Schedule or
+	// Schedules, and Timezone doesn't apply to it.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
 	// Timezone modifies the actual time relative to the specified timezone.
 	// Defaults to the system time zone.
 	// More general information about time zones: https://www.iana.org/time-zones
@@ -73,6 +132,157 @@ type PingSourceSpec struct {
 	// to "application/json".
 	// +optional
 	JsonData string `json:"jsonData,omitempty"`
+
+	// DataBase64 is base64 encoded data used as the body of the event
+	// posted to the sink, for binary payloads such as protobuf or Avro.
+	// Only one of JsonData, DataBase64, DataFromSecret and DataRef can be
+	// set; DataBase64 takes precedence over JsonData in the sources
+	// admitted before.
+	// +optional
+	DataBase64 string `json:"dataBase64,omitempty"`
+
+	// ContentType is the datacontenttype of the events. When set, JsonData
+	// is sent as is rather than wrapped in a JSON object. Defaults to
+	// "application/json", or "application/octet-stream" with DataBase64.
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+
+	// DataFromSecret selects a key of a Secret of the PingSource namespace
+	// whose value is used like JsonData, for payloads holding secrets. The
+	// Secret is read when the PingSource is reconciled, and the data is never
+	// logged.
+	// +optional
+	DataFromSecret *corev1.SecretKeySelector `json:"dataFromSecret,omitempty"`
+
+	// DataRef is the URL of a payload too large to be inlined. When set, the
+	// events carry it in the dataref extension instead of data.
+	// +optional
+	DataRef string `json:"dataRef,omitempty"`
+
+	// CloudEventType is the type of the events sent to the sink. It is a
+	// Go template rendered on each fire, with .Time, .Namespace, .Name,
+	// .Labels and .Annotations. Defaults to dev.knative.sources.ping.
+	// +optional
+	CloudEventType string `json:"cloudEventType,omitempty"`
+
+	// CloudEventSource is the source of the events sent to the sink. It is a
+	// Go template rendered on each fire, like CloudEventType, and must render
+	// to a URI reference. Defaults to the PingSource path.
+	// +optional
+	CloudEventSource string `json:"cloudEventSource,omitempty"`
+
+	// TemplateMissingKey is how CloudEventType and CloudEventSource render
+	// the keys missing from .Labels and .Annotations: Strict skips the
+	// fire, counting it as failed, and Lenient renders them empty, both
+	// marking the PingSource as degraded. Defaults to Strict.
+	// +optional
+	TemplateMissingKey TemplateMissingKeyPolicy `json:"templateMissingKey,omitempty"`
+
+	// TypeVariants are the types the events are sent with, one being
+	// picked on each fire with a probability proportional to its weight.
+	// It can't be set with CloudEventType.
+	// +optional
+	TypeVariants []TypeVariant `json:"typeVariants,omitempty"`
+
+	// BasicAuth enables HTTP basic authentication to the sink.
+	// +optional
+	BasicAuth *PingSourceBasicAuth `json:"basicAuth,omitempty"`
+
+	// BatchSpread spreads evenly over this window the events sent on a
+	// fire, such as the chunks of the events split to fit the sink size
+	// limit, instead of sending them at once.
+	// +optional
+	BatchSpread *metav1.Duration `json:"batchSpread,omitempty"`
+
+	// EventSchema is the JSON Schema the data of the events is validated
+	// against before being sent. The events not matching it are skipped,
+	// and the PingSource marked as emitting invalid events.
+	// +optional
+	EventSchema *PingSourceEventSchema `json:"eventSchema,omitempty"`
+
+	// FireCondition is a CEL expression evaluated on each fire, the fire
+	// being skipped when false. It can refer to fireCount, the number of
+	// the fire starting at 1, and now, the timestamp of the fire.
+	// +optional
+	FireCondition string `json:"fireCondition,omitempty"`
+
+	// MaxFireStaleness is the maximum age of a fire when its event is about
+	// to be sent. The fires delayed beyond it, such as when the adapter is
+	// overloaded, are skipped rather than sending stale events.
+	// +optional
+	MaxFireStaleness *metav1.Duration `json:"maxFireStaleness,omitempty"`
+
+	// Ordered serializes the sends of the events of the PingSource, for
+	// sinks needing them in order: the events of a fire, including its
+	// batches, are sent once those of the previous fire are done.
+	// +optional
+	Ordered bool `json:"ordered,omitempty"`
+
+	// StopAfterFirstSuccess stops the schedule once an event has been
+	// successfully sent to the sink, and marks the PingSource Completed.
+	// +optional
+	StopAfterFirstSuccess bool `json:"stopAfterFirstSuccess,omitempty"`
+}
+
+// PingSourceBasicAuth references the credentials used to authenticate to
+// the sink with HTTP basic authentication.
+type PingSourceBasicAuth struct {
+	// SecretName is the name of a Secret of the PingSource namespace holding
+	// the credentials under the username and password keys, like the
+	// Secrets of type kubernetes.io/basic-auth. The Secret is read when the
+	// PingSource is reconciled.
+	SecretName string `json:"secretName"`
+}
+
+// PingSourceEventSchema holds the JSON Schema of the data of the events of a
+// PingSource, inline or in a ConfigMap. Exactly one of them must be set.
+type PingSourceEventSchema struct {
+	// Inline is the JSON Schema.
+	// +optional
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMapKeyRef selects a key of a ConfigMap of the PingSource
+	// namespace holding the JSON Schema. The ConfigMap is read when the
+	// PingSource is reconciled.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// PingSourceSinkCACerts references the PEM encoded CA certificates of the sink
+// of a PingSource, in a Secret or a ConfigMap. Exactly one of them must be
+// set. The Secret or ConfigMap is read when the PingSource is reconciled, and
+// again each time it changes.
+type PingSourceSinkCACerts struct {
+	// SecretKeyRef selects a key of a Secret of the PingSource namespace
+	// holding the certificates.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+
+	// ConfigMapKeyRef selects a key of a ConfigMap of the PingSource
+	// namespace holding the certificates.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// TemplateMissingKeyPolicy is how the templates of a PingSource render the
+// keys missing from the maps they index.
+type TemplateMissingKeyPolicy string
+
+const (
+	// TemplateMissingKeyStrict fails the rendering of the templates
+	// referencing a missing key.
+	TemplateMissingKeyStrict TemplateMissingKeyPolicy = "Strict"
+	// TemplateMissingKeyLenient renders the missing keys empty.
+	TemplateMissingKeyLenient TemplateMissingKeyPolicy = "Lenient"
+)
+
+// TypeVariant is a type of the events of a PingSource, and its weight.
+type TypeVariant struct {
+	// Type is the CloudEvent type of the events.
+	Type string `json:"type"`
+
+	// Weight is the relative frequency of the type. Must be positive.
+	Weight int `json:"weight"`
 }
 
 // PingSourceStatus defines the observed state of PingSource.
@@ -85,6 +295,44 @@ type PingSourceStatus struct {
 	// * SinkURI - the current active sink URI that has been configured for the
 	//   Source.
 	duckv1.SourceStatus `json:",inline"`
+
+	// ShadowSinkURI is the resolved URI of the ShadowSink, when set and
+	// resolvable.
+	// +optional
+	ShadowSinkURI *apis.URL `json:"shadowSinkUri,omitempty"`
+
+	// AlertSinkURI is the resolved URI of the AlertSink, when set and
+	// resolvable.
+	// +optional
+	AlertSinkURI *apis.URL `json:"alertSinkUri,omitempty"`
+
+	// DeadLetterSinkURI is the resolved URI of the dead letter sink of the
+	// Delivery, when set and resolvable.
+	// +optional
+	DeadLetterSinkURI *apis.URL `json:"deadLetterSinkUri,omitempty"`
+
+	// SendLatency is the latency of the last events sent to the sink, when
+	// reported by the adapter.
+	// +optional
+	SendLatency *PingSourceSendLatency `json:"sendLatency,omitempty"`
+}
+
+// PingSourceSendLatency summarizes the latency of the last events sent to the
+// sink of a PingSource.
+type PingSourceSendLatency struct {
+	// Samples is the number of sends the latency is computed over.
+	Samples int32 `json:"samples"`
+
+	// Average is the average latency of the sends.
+	Average metav1.Duration `json:"average"`
+
+	// Percentile is the percentile of the sends reported by
+	// PercentileLatency, between 1 and 100.
+	Percentile int32 `json:"percentile"`
+
+	// PercentileLatency is the latency Percentile percent of the sends
+	// didn't exceed.
+	PercentileLatency metav1.Duration `json:"percentileLatency"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
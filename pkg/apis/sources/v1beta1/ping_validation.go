@@ -18,36 +18,301 @@ package v1beta1
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/robfig/cron/v3"
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"knative.dev/eventing/pkg/apis/sources"
+	"knative.dev/eventing/pkg/apis/sources/config"
 )
 
+var errNoPEMCertificate = errors.New("no PEM encoded certificate found")
+
+// scheduleParser accepts the standard cron expressions, optionally preceded
+// by a seconds field.
+var scheduleParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseSchedule parses a PingSource schedule. A 5 fields expression fires at
+// the second 0 and a 6 fields one starts with the seconds.
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	return scheduleParser.Parse(spec)
+}
+
 func (c *PingSource) Validate(ctx context.Context) *apis.FieldError {
-	return c.Spec.Validate(ctx).ViaField("spec")
+	errs := c.Spec.Validate(ctx).ViaField("spec")
+	// The PingSources sending to a brokerName have no sink.
+	if errs == nil && c.Spec.BrokerName == "" {
+		var original *duckv1.Destination
+		if base, ok := apis.GetBaseline(ctx).(*PingSource); ok {
+			original = &base.Spec.Sink
+		}
+		errs = sources.ValidateSinkResolvable(ctx, c, c.Spec.Sink, original).ViaField("spec", "sink")
+	}
+	return errs
 }
 
 func (cs *PingSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 	var errs *apis.FieldError
 
-	schedule := cs.Schedule
-	if cs.Timezone != "" {
-		schedule = "CRON_TZ=" + cs.Timezone + " " + schedule
+	if cs.Interval != nil {
+		errs = errs.Also(cs.validateInterval(ctx))
+	} else {
+		schedule := cs.Schedule
+		if cs.Timezone != "" {
+			schedule = "CRON_TZ=" + cs.Timezone + " " + schedule
+		}
+
+		if _, err := ParseSchedule(schedule); err != nil {
+			if strings.HasPrefix(err.Error(), "provided bad location") {
+				fe := apis.ErrInvalidValue(err, "timezone")
+				errs = errs.Also(fe)
+			} else {
+				fe := apis.ErrInvalidValue(err, "schedule")
+				errs = errs.Also(fe)
+			}
+		}
+
+		for i, schedule := range cs.Schedules {
+			if cs.Timezone != "" {
+				schedule = "CRON_TZ=" + cs.Timezone + " " + schedule
+			}
+			// A bad timezone is already reported above.
+			if _, err := ParseSchedule(schedule); err != nil && !strings.HasPrefix(err.Error(), "provided bad location") {
+				errs = errs.Also(apis.ErrInvalidArrayValue(err, "schedules", i))
+			}
+		}
+	}
+
+	if cs.CloudEventType != "" {
+		if _, err := template.New("cloudEventType").Parse(cs.CloudEventType); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(err, "cloudEventType"))
+		}
+	}
+	if cs.CloudEventSource != "" {
+		if _, err := template.New("cloudEventSource").Parse(cs.CloudEventSource); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(err, "cloudEventSource"))
+		}
+	}
+
+	switch cs.TemplateMissingKey {
+	case "", TemplateMissingKeyStrict, TemplateMissingKeyLenient:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(cs.TemplateMissingKey, "templateMissingKey"))
+	}
+
+	if len(cs.TypeVariants) > 0 && cs.CloudEventType != "" {
+		errs = errs.Also(apis.ErrMultipleOneOf("cloudEventType", "typeVariants"))
+	}
+	for i, variant := range cs.TypeVariants {
+		if variant.Type == "" {
+			errs = errs.Also(apis.ErrMissingField("type").ViaFieldIndex("typeVariants", i))
+		}
+		if variant.Weight <= 0 {
+			errs = errs.Also(apis.ErrInvalidValue(variant.Weight, "weight").ViaFieldIndex("typeVariants", i))
+		}
+	}
+
+	if ref := cs.DataFromSecret; ref != nil {
+		if cs.JsonData != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("jsonData", "dataFromSecret"))
+		}
+		if ref.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("dataFromSecret.name"))
+		}
+		if ref.Key == "" {
+			errs = errs.Also(apis.ErrMissingField("dataFromSecret.key"))
+		}
+	}
+
+	if cs.DataBase64 != "" {
+		if cs.JsonData != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("jsonData", "dataBase64"))
+		}
+		if cs.DataFromSecret != nil {
+			errs = errs.Also(apis.ErrMultipleOneOf("dataBase64", "dataFromSecret"))
+		}
+		if _, err := base64.StdEncoding.DecodeString(cs.DataBase64); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(err, "dataBase64"))
+		}
+	}
+
+	errs = errs.Also(cs.validateDataSize(ctx))
+
+	if cs.DataRef != "" {
+		if cs.DataBase64 != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("dataBase64", "dataRef"))
+		}
+		if cs.JsonData != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("jsonData", "dataRef"))
+		}
+		if cs.DataFromSecret != nil {
+			errs = errs.Also(apis.ErrMultipleOneOf("dataFromSecret", "dataRef"))
+		}
+		if u, err := url.Parse(cs.DataRef); err != nil || !u.IsAbs() {
+			errs = errs.Also(apis.ErrInvalidValue(cs.DataRef, "dataRef"))
+		}
+	}
+
+	if es := cs.EventSchema; es != nil {
+		if (es.Inline == "") == (es.ConfigMapKeyRef == nil) {
+			errs = errs.Also(apis.ErrMissingOneOf("eventSchema.inline", "eventSchema.configMapKeyRef"))
+		}
+		if es.Inline != "" {
+			var schema map[string]interface{}
+			if err := json.Unmarshal([]byte(es.Inline), &schema); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(err.Error(), "eventSchema.inline"))
+			}
+		}
+		if ref := es.ConfigMapKeyRef; ref != nil {
+			if ref.Name == "" {
+				errs = errs.Also(apis.ErrMissingField("eventSchema.configMapKeyRef.name"))
+			}
+			if ref.Key == "" {
+				errs = errs.Also(apis.ErrMissingField("eventSchema.configMapKeyRef.key"))
+			}
+		}
+	}
+
+	if cs.BasicAuth != nil && cs.BasicAuth.SecretName == "" {
+		errs = errs.Also(apis.ErrMissingField("basicAuth.secretName"))
+	}
+
+	if cs.BatchSpread != nil && cs.BatchSpread.Duration < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(cs.BatchSpread.Duration.String(), "batchSpread"))
 	}
 
-	if _, err := cron.ParseStandard(schedule); err != nil {
-		if strings.HasPrefix(err.Error(), "provided bad location") {
-			fe := apis.ErrInvalidValue(err, "timezone")
-			errs = errs.Also(fe)
-		} else {
-			fe := apis.ErrInvalidValue(err, "schedule")
-			errs = errs.Also(fe)
+	if cs.MaxFireStaleness != nil && cs.MaxFireStaleness.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(cs.MaxFireStaleness.Duration.String(), "maxFireStaleness"))
+	}
+
+	if prefix := cs.SinkPathPrefix; prefix != "" {
+		if u, err := url.Parse(prefix); err != nil || !strings.HasPrefix(prefix, "/") || u.Path != prefix {
+			errs = errs.Also(apis.ErrInvalidValue(prefix, "sinkPathPrefix"))
 		}
 	}
 
-	if fe := cs.Sink.Validate(ctx); fe != nil {
+	if cs.SinkCAPEM != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(cs.SinkCAPEM)) {
+		errs = errs.Also(apis.ErrInvalidValue(errNoPEMCertificate, "sinkCAPEM"))
+	}
+
+	if ca := cs.SinkCACerts; ca != nil {
+		if cs.SinkCAPEM != "" {
+			errs = errs.Also(apis.ErrMultipleOneOf("sinkCAPEM", "sinkCACerts"))
+		}
+		if (ca.SecretKeyRef == nil) == (ca.ConfigMapKeyRef == nil) {
+			errs = errs.Also(apis.ErrMissingOneOf("sinkCACerts.secretKeyRef", "sinkCACerts.configMapKeyRef"))
+		}
+		if ref := ca.SecretKeyRef; ref != nil {
+			if ref.Name == "" {
+				errs = errs.Also(apis.ErrMissingField("sinkCACerts.secretKeyRef.name"))
+			}
+			if ref.Key == "" {
+				errs = errs.Also(apis.ErrMissingField("sinkCACerts.secretKeyRef.key"))
+			}
+		}
+		if ref := ca.ConfigMapKeyRef; ref != nil {
+			if ref.Name == "" {
+				errs = errs.Also(apis.ErrMissingField("sinkCACerts.configMapKeyRef.name"))
+			}
+			if ref.Key == "" {
+				errs = errs.Also(apis.ErrMissingField("sinkCACerts.configMapKeyRef.key"))
+			}
+		}
+	}
+
+	if cs.ShadowSink != nil {
+		if fe := cs.ShadowSink.Validate(ctx); fe != nil {
+			errs = errs.Also(fe.ViaField("shadowSink"))
+		}
+	}
+
+	if cs.AlertSink != nil {
+		if fe := cs.AlertSink.Validate(ctx); fe != nil {
+			errs = errs.Also(fe.ViaField("alertSink"))
+		}
+	}
+
+	if fe := cs.Delivery.Validate(ctx); fe != nil {
+		errs = errs.Also(fe.ViaField("delivery"))
+	}
+
+	if cs.BrokerName != "" {
+		if cs.Sink.Ref != nil || cs.Sink.URI != nil {
+			errs = errs.Also(apis.ErrMultipleOneOf("brokerName", "sink"))
+		}
+	} else if fe := cs.Sink.Validate(ctx); fe != nil {
 		errs = errs.Also(fe.ViaField("sink"))
 	}
 	return errs
 }
+
+// validateInterval verifies Interval isn't set with the schedules and is a
+// number of seconds no shorter than the interval floor. The floor doesn't
+// apply to the intervals left unchanged by an update, for the floor to be
+// raised without the existing PingSources failing to be updated.
+func (cs *PingSourceSpec) validateInterval(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if cs.Schedule != "" {
+		errs = errs.Also(apis.ErrMultipleOneOf("interval", "schedule"))
+	}
+	if len(cs.Schedules) > 0 {
+		errs = errs.Also(apis.ErrMultipleOneOf("interval", "schedules"))
+	}
+
+	interval := cs.Interval.Duration
+	if interval%time.Second != 0 {
+		return errs.Also(apis.ErrInvalidValue(interval.String()+", must be a whole number of seconds", "interval"))
+	}
+	if apis.IsInUpdate(ctx) {
+		if original, ok := apis.GetBaseline(ctx).(*PingSource); ok && original.Spec.Interval != nil && original.Spec.Interval.Duration == interval {
+			return errs
+		}
+	}
+	if floor := config.FromContextOrDefaults(ctx).PingDefaults.IntervalFloor; interval < floor {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("interval %v is shorter than the %v floor", interval, floor),
+			Paths:   []string{"interval"},
+		})
+	}
+	return errs
+}
+
+// validateDataSize verifies the inline data is no larger than the data limit.
+// Like the interval floor, the limit doesn't apply to the data left unchanged
+// by an update.
+func (cs *PingSourceSpec) validateDataSize(ctx context.Context) *apis.FieldError {
+	limit := config.FromContextOrDefaults(ctx).PingDefaults.DataMaxSize
+	if limit <= 0 {
+		return nil
+	}
+	if apis.IsInUpdate(ctx) {
+		if original, ok := apis.GetBaseline(ctx).(*PingSource); ok &&
+			original.Spec.JsonData == cs.JsonData && original.Spec.DataBase64 == cs.DataBase64 {
+			return nil
+		}
+	}
+
+	field, size := "jsonData", int64(len(cs.JsonData))
+	if cs.DataBase64 != "" {
+		// The invalid data is reported by Validate.
+		data, _ := base64.StdEncoding.DecodeString(cs.DataBase64)
+		field, size = "dataBase64", int64(len(data))
+	}
+	if size > limit {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("the data of %d bytes is larger than the %d bytes limit", size, limit),
+			Paths:   []string{field},
+		}
+	}
+	return nil
+}
@@ -18,14 +18,25 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/base64"
 	"testing"
+	"text/template"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
 
 	"github.com/google/go-cmp/cmp"
 	"knative.dev/pkg/apis"
+
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	"knative.dev/eventing/pkg/apis/sources/config"
 )
 
+var exponentialBackoff = eventingduckv1.BackoffPolicyExponential
+
 func TestPingSourceValidation(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -48,6 +59,23 @@ func TestPingSourceValidation(t *testing.T) {
 			},
 		},
 		want: nil,
+	}, {
+		name: "valid spec with seconds",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule: "*/15 * * * * *",
+				SourceSpec: duckv1.SourceSpec{
+					Sink: duckv1.Destination{
+						Ref: &duckv1.KReference{
+							APIVersion: "v1alpha1",
+							Kind:       "broker",
+							Name:       "default",
+						},
+					},
+				},
+			},
+		},
+		want: nil,
 	}, {
 		name: "valid spec with timezone",
 		source: PingSource{
@@ -117,10 +145,460 @@ func TestPingSourceValidation(t *testing.T) {
 		},
 		want: func() *apis.FieldError {
 			var errs *apis.FieldError
-			fe := apis.ErrInvalidValue("expected exactly 5 fields, found 1: [2]", "spec.schedule")
+			fe := apis.ErrInvalidValue("expected 5 to 6 fields, found 1: [2]", "spec.schedule")
 			errs = errs.Also(fe)
 			return errs
 		}(),
+	}, {
+		name: "valid spec with type and source templates",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:         "*/2 * * * *",
+				CloudEventType:   "dev.example.{{.Name}}",
+				CloudEventSource: "/pings/{{.Namespace}}/{{.Time.Format \"2006-01-02\"}}",
+				SourceSpec: duckv1.SourceSpec{
+					Sink: duckv1.Destination{
+						Ref: &duckv1.KReference{
+							APIVersion: "v1alpha1",
+							Kind:       "broker",
+							Name:       "default",
+						},
+					},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid type template",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:       "*/2 * * * *",
+				CloudEventType: "dev.example.{{.Name",
+				SourceSpec: duckv1.SourceSpec{
+					Sink: duckv1.Destination{
+						Ref: &duckv1.KReference{
+							APIVersion: "v1alpha1",
+							Kind:       "broker",
+							Name:       "default",
+						},
+					},
+				},
+			},
+		},
+		want: func() *apis.FieldError {
+			_, err := template.New("cloudEventType").Parse("dev.example.{{.Name")
+			return apis.ErrInvalidValue(err, "spec.cloudEventType")
+		}(),
+	}, {
+		name: "basic auth without secret name",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:  "*/2 * * * *",
+				BasicAuth: &PingSourceBasicAuth{},
+				SourceSpec: duckv1.SourceSpec{
+					Sink: duckv1.Destination{
+						Ref: &duckv1.KReference{
+							APIVersion: "v1alpha1",
+							Kind:       "broker",
+							Name:       "default",
+						},
+					},
+				},
+			},
+		},
+		want: apis.ErrMissingField("spec.basicAuth.secretName"),
+	}, {
+		name: "valid broker name",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+			},
+		},
+		want: nil,
+	}, {
+		name: "broker name and sink",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				SourceSpec: duckv1.SourceSpec{
+					Sink: duckv1.Destination{
+						URI: apis.HTTP("example.com"),
+					},
+				},
+			},
+		},
+		want: apis.ErrMultipleOneOf("spec.brokerName", "spec.sink"),
+	}, {
+		name: "valid sink path prefix",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:       "*/2 * * * *",
+				BrokerName:     "default",
+				SinkPathPrefix: "/gateway/sinks",
+			},
+		},
+		want: nil,
+	}, {
+		name: "relative sink path prefix",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:       "*/2 * * * *",
+				BrokerName:     "default",
+				SinkPathPrefix: "gateway",
+			},
+		},
+		want: apis.ErrInvalidValue("gateway", "spec.sinkPathPrefix"),
+	}, {
+		name: "sink path prefix with query",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:       "*/2 * * * *",
+				BrokerName:     "default",
+				SinkPathPrefix: "/gateway?sink=a",
+			},
+		},
+		want: apis.ErrInvalidValue("/gateway?sink=a", "spec.sinkPathPrefix"),
+	}, {
+		name: "data from secret and json data",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				JsonData:   "data",
+				DataFromSecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "payload"},
+					Key:                  "data",
+				},
+			},
+		},
+		want: apis.ErrMultipleOneOf("spec.jsonData", "spec.dataFromSecret"),
+	}, {
+		name: "data from secret without name and key",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:       "*/2 * * * *",
+				BrokerName:     "default",
+				DataFromSecret: &corev1.SecretKeySelector{},
+			},
+		},
+		want: apis.ErrMissingField("spec.dataFromSecret.name", "spec.dataFromSecret.key"),
+	}, {
+		name: "data ref and json data",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				JsonData:   "data",
+				DataRef:    "https://storage.example.com/payload.json",
+			},
+		},
+		want: apis.ErrMultipleOneOf("spec.jsonData", "spec.dataRef"),
+	}, {
+		name: "data base64 and json data",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				JsonData:   "data",
+				DataBase64: "AAEC/w==",
+			},
+		},
+		want: apis.ErrMultipleOneOf("spec.jsonData", "spec.dataBase64"),
+	}, {
+		name: "data base64 and data from secret",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				DataBase64: "AAEC/w==",
+				DataFromSecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "payload"},
+					Key:                  "data",
+				},
+			},
+		},
+		want: apis.ErrMultipleOneOf("spec.dataBase64", "spec.dataFromSecret"),
+	}, {
+		name: "invalid data base64",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				DataBase64: "not base64!",
+			},
+		},
+		want: func() *apis.FieldError {
+			_, err := base64.StdEncoding.DecodeString("not base64!")
+			return apis.ErrInvalidValue(err, "spec.dataBase64")
+		}(),
+	}, {
+		name: "relative data ref",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				DataRef:    "/payload.json",
+			},
+		},
+		want: apis.ErrInvalidValue("/payload.json", "spec.dataRef"),
+	}, {
+		name: "event schema inline and from configmap",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				EventSchema: &PingSourceEventSchema{
+					Inline: `{"type": "object"}`,
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "schemas"},
+						Key:                  "ping",
+					},
+				},
+			},
+		},
+		want: apis.ErrMissingOneOf("spec.eventSchema.inline", "spec.eventSchema.configMapKeyRef"),
+	}, {
+		name: "invalid inline event schema",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:    "*/2 * * * *",
+				BrokerName:  "default",
+				EventSchema: &PingSourceEventSchema{Inline: `["object"]`},
+			},
+		},
+		want: apis.ErrInvalidValue("json: cannot unmarshal array into Go value of type map[string]interface {}", "spec.eventSchema.inline"),
+	}, {
+		name: "event schema from configmap without name and key",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:    "*/2 * * * *",
+				BrokerName:  "default",
+				EventSchema: &PingSourceEventSchema{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{}},
+			},
+		},
+		want: apis.ErrMissingField("spec.eventSchema.configMapKeyRef.name", "spec.eventSchema.configMapKeyRef.key"),
+	}, {
+		name: "negative batch spread",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:    "*/2 * * * *",
+				BrokerName:  "default",
+				BatchSpread: &metav1.Duration{Duration: -time.Second},
+			},
+		},
+		want: apis.ErrInvalidValue("-1s", "spec.batchSpread"),
+	}, {
+		name: "sink CA without certificate",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				SinkCAPEM:  "not a certificate",
+			},
+		},
+		want: apis.ErrInvalidValue("no PEM encoded certificate found", "spec.sinkCAPEM"),
+	}, {
+		name: "sink CA certs from secret and configmap",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				SinkCACerts: &PingSourceSinkCACerts{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "sink-ca"},
+						Key:                  "ca.crt",
+					},
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "trust-bundle"},
+						Key:                  "ca.crt",
+					},
+				},
+			},
+		},
+		want: apis.ErrMissingOneOf("spec.sinkCACerts.secretKeyRef", "spec.sinkCACerts.configMapKeyRef"),
+	}, {
+		name: "sink CA certs from configmap without name and key",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:    "*/2 * * * *",
+				BrokerName:  "default",
+				SinkCACerts: &PingSourceSinkCACerts{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{}},
+			},
+		},
+		want: apis.ErrMissingField("spec.sinkCACerts.configMapKeyRef.name", "spec.sinkCACerts.configMapKeyRef.key"),
+	}, {
+		name: "sink CA certs with sink CA",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				SinkCAPEM:  "not a certificate",
+				SinkCACerts: &PingSourceSinkCACerts{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "sink-ca"},
+						Key:                  "ca.crt",
+					},
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("no PEM encoded certificate found", "spec.sinkCAPEM").Also(
+			apis.ErrMultipleOneOf("spec.sinkCAPEM", "spec.sinkCACerts")),
+	}, {
+		name: "shadow sink without ref nor uri",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				ShadowSink: &duckv1.Destination{},
+			},
+		},
+		want: apis.ErrGeneric("expected at least one, got none", "ref", "uri").ViaField("spec.shadowSink"),
+	}, {
+		name: "alert sink without ref nor uri",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				AlertSink:  &duckv1.Destination{},
+			},
+		},
+		want: apis.ErrGeneric("expected at least one, got none", "ref", "uri").ViaField("spec.alertSink"),
+	}, {
+		name: "valid delivery",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				Delivery: &eventingduckv1.DeliverySpec{
+					DeadLetterSink: &duckv1.Destination{URI: apis.HTTP("dls.example.com")},
+					Retry:          ptr.Int32(3),
+					BackoffPolicy:  &exponentialBackoff,
+					BackoffDelay:   ptr.String("PT0.5S"),
+				},
+			},
+		},
+	}, {
+		name: "invalid delivery",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "*/2 * * * *",
+				BrokerName: "default",
+				Delivery: &eventingduckv1.DeliverySpec{
+					Retry:        ptr.Int32(-1),
+					BackoffDelay: ptr.String("half a second"),
+				},
+			},
+		},
+		want: apis.ErrInvalidValue(int32(-1), "spec.delivery.retry").Also(
+			apis.ErrInvalidValue("half a second", "spec.delivery.backoffDelay")),
+	}, {
+		name: "zero max fire staleness",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:         "*/2 * * * *",
+				BrokerName:       "default",
+				MaxFireStaleness: &metav1.Duration{},
+			},
+		},
+		want: apis.ErrInvalidValue("0s", "spec.maxFireStaleness"),
+	}, {
+		name: "invalid additional schedule",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:  "*/2 * * * *",
+				Schedules: []string{"0 * * * *", "2"},
+				SourceSpec: duckv1.SourceSpec{
+					Sink: duckv1.Destination{
+						Ref: &duckv1.KReference{
+							APIVersion: "v1alpha1",
+							Kind:       "broker",
+							Name:       "default",
+						},
+					},
+				},
+			},
+		},
+		want: func() *apis.FieldError {
+			_, err := ParseSchedule("2")
+			return apis.ErrInvalidArrayValue(err, "spec.schedules", 1)
+		}(),
+	}, {
+		name: "lenient template missing keys",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:           "*/2 * * * *",
+				BrokerName:         "default",
+				CloudEventType:     "dev.example.{{.Labels.team}}",
+				TemplateMissingKey: TemplateMissingKeyLenient,
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid template missing keys",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:           "*/2 * * * *",
+				BrokerName:         "default",
+				TemplateMissingKey: "Ignore",
+			},
+		},
+		want: apis.ErrInvalidValue("Ignore", "spec.templateMissingKey"),
+	}, {
+		name: "invalid type variants",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Schedule:       "*/2 * * * *",
+				BrokerName:     "default",
+				CloudEventType: "dev.example.ping",
+				TypeVariants:   []TypeVariant{{Type: "dev.example.a", Weight: 1}, {Weight: 0}},
+			},
+		},
+		want: apis.ErrMultipleOneOf("spec.cloudEventType", "spec.typeVariants").
+			Also(apis.ErrMissingField("spec.typeVariants[1].type")).
+			Also(apis.ErrInvalidValue(0, "spec.typeVariants[1].weight")),
+	}, {
+		name: "valid interval",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Interval:   &metav1.Duration{Duration: 10 * time.Second},
+				BrokerName: "default",
+			},
+		},
+	}, {
+		name: "interval with schedules",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Interval:   &metav1.Duration{Duration: 10 * time.Second},
+				Schedule:   "* * * * *",
+				Schedules:  []string{"0 * * * *"},
+				BrokerName: "default",
+			},
+		},
+		want: apis.ErrMultipleOneOf("spec.interval", "spec.schedule").
+			Also(apis.ErrMultipleOneOf("spec.interval", "spec.schedules")),
+	}, {
+		name: "interval below the floor",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Interval:   &metav1.Duration{},
+				BrokerName: "default",
+			},
+		},
+		want: &apis.FieldError{
+			Message: "interval 0s is shorter than the 1s floor",
+			Paths:   []string{"spec.interval"},
+		},
+	}, {
+		name: "fractional interval",
+		source: PingSource{
+			Spec: PingSourceSpec{
+				Interval:   &metav1.Duration{Duration: 1500 * time.Millisecond},
+				BrokerName: "default",
+			},
+		},
+		want: apis.ErrInvalidValue("1.5s, must be a whole number of seconds", "spec.interval"),
 	}}
 
 	for _, test := range tests {
@@ -132,3 +610,68 @@ func TestPingSourceValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestPingSourceIntervalFloor(t *testing.T) {
+	ctx := config.ToContext(context.Background(), &config.Config{
+		PingDefaults: &config.PingDefaults{IntervalFloor: 30 * time.Second},
+	})
+	source := func(interval time.Duration) *PingSource {
+		return &PingSource{
+			Spec: PingSourceSpec{
+				Interval:   &metav1.Duration{Duration: interval},
+				BrokerName: "default",
+			},
+		}
+	}
+
+	if err := source(30 * time.Second).Validate(ctx); err != nil {
+		t.Error("Expected the interval at the floor to be valid, got", err)
+	}
+	if err := source(10 * time.Second).Validate(ctx); err == nil {
+		t.Error("Expected the interval below the floor to be invalid")
+	}
+
+	// The floor was raised since the source was created.
+	updateCtx := apis.WithinUpdate(ctx, source(10*time.Second))
+	if err := source(10 * time.Second).Validate(updateCtx); err != nil {
+		t.Error("Expected the unchanged interval to be valid, got", err)
+	}
+	if err := source(20 * time.Second).Validate(updateCtx); err == nil {
+		t.Error("Expected the changed interval below the floor to be invalid")
+	}
+}
+
+func TestPingSourceDataMaxSize(t *testing.T) {
+	ctx := config.ToContext(context.Background(), &config.Config{
+		PingDefaults: &config.PingDefaults{IntervalFloor: config.DefaultIntervalFloor, DataMaxSize: 8},
+	})
+	source := func(jsonData, dataBase64 string) *PingSource {
+		return &PingSource{
+			Spec: PingSourceSpec{
+				Schedule:   "* * * * *",
+				JsonData:   jsonData,
+				DataBase64: dataBase64,
+				BrokerName: "default",
+			},
+		}
+	}
+
+	if err := source(`"short"`, "").Validate(ctx); err != nil {
+		t.Error("Expected the data within the limit to be valid, got", err)
+	}
+	if err := source(`"too long"`, "").Validate(ctx); err == nil {
+		t.Error("Expected the data over the limit to be invalid")
+	}
+	if err := source("", base64.StdEncoding.EncodeToString([]byte("123456789"))).Validate(ctx); err == nil {
+		t.Error("Expected the decoded data over the limit to be invalid")
+	}
+
+	// The limit was lowered since the source was created.
+	updateCtx := apis.WithinUpdate(ctx, source(`"too long"`, ""))
+	if err := source(`"too long"`, "").Validate(updateCtx); err != nil {
+		t.Error("Expected the unchanged data to be valid, got", err)
+	}
+	if err := source(`"too longer"`, "").Validate(updateCtx); err == nil {
+		t.Error("Expected the changed data over the limit to be invalid")
+	}
+}
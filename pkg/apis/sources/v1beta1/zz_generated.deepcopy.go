@@ -21,8 +21,12 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+	apis "knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -139,6 +143,11 @@ func (in *ApiServerSourceSpec) DeepCopyInto(out *ApiServerSourceSpec) {
 		*out = new(APIVersionKind)
 		**out = **in
 	}
+	if in.ResyncPeriod != nil {
+		in, out := &in.ResyncPeriod, &out.ResyncPeriod
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -293,6 +302,43 @@ func (in *PingSource) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PingSourceBasicAuth) DeepCopyInto(out *PingSourceBasicAuth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PingSourceBasicAuth.
+func (in *PingSourceBasicAuth) DeepCopy() *PingSourceBasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(PingSourceBasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PingSourceEventSchema) DeepCopyInto(out *PingSourceEventSchema) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PingSourceEventSchema.
+func (in *PingSourceEventSchema) DeepCopy() *PingSourceEventSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(PingSourceEventSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PingSourceList) DeepCopyInto(out *PingSourceList) {
 	*out = *in
@@ -326,10 +372,114 @@ func (in *PingSourceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PingSourceSendLatency) DeepCopyInto(out *PingSourceSendLatency) {
+	*out = *in
+	out.Average = in.Average
+	out.PercentileLatency = in.PercentileLatency
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PingSourceSendLatency.
+func (in *PingSourceSendLatency) DeepCopy() *PingSourceSendLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(PingSourceSendLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PingSourceSinkCACerts) DeepCopyInto(out *PingSourceSinkCACerts) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PingSourceSinkCACerts.
+func (in *PingSourceSinkCACerts) DeepCopy() *PingSourceSinkCACerts {
+	if in == nil {
+		return nil
+	}
+	out := new(PingSourceSinkCACerts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PingSourceSpec) DeepCopyInto(out *PingSourceSpec) {
 	*out = *in
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
+	if in.SinkCACerts != nil {
+		in, out := &in.SinkCACerts, &out.SinkCACerts
+		*out = new(PingSourceSinkCACerts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ShadowSink != nil {
+		in, out := &in.ShadowSink, &out.ShadowSink
+		*out = new(duckv1.Destination)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSink != nil {
+		in, out := &in.AlertSink, &out.AlertSink
+		*out = new(duckv1.Destination)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Delivery != nil {
+		in, out := &in.Delivery, &out.Delivery
+		*out = new(eventingduckv1.DeliverySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DataFromSecret != nil {
+		in, out := &in.DataFromSecret, &out.DataFromSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TypeVariants != nil {
+		in, out := &in.TypeVariants, &out.TypeVariants
+		*out = make([]TypeVariant, len(*in))
+		copy(*out, *in)
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(PingSourceBasicAuth)
+		**out = **in
+	}
+	if in.BatchSpread != nil {
+		in, out := &in.BatchSpread, &out.BatchSpread
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.EventSchema != nil {
+		in, out := &in.EventSchema, &out.EventSchema
+		*out = new(PingSourceEventSchema)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxFireStaleness != nil {
+		in, out := &in.MaxFireStaleness, &out.MaxFireStaleness
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -347,6 +497,26 @@ func (in *PingSourceSpec) DeepCopy() *PingSourceSpec {
 func (in *PingSourceStatus) DeepCopyInto(out *PingSourceStatus) {
 	*out = *in
 	in.SourceStatus.DeepCopyInto(&out.SourceStatus)
+	if in.ShadowSinkURI != nil {
+		in, out := &in.ShadowSinkURI, &out.ShadowSinkURI
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertSinkURI != nil {
+		in, out := &in.AlertSinkURI, &out.AlertSinkURI
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeadLetterSinkURI != nil {
+		in, out := &in.DeadLetterSinkURI, &out.DeadLetterSinkURI
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SendLatency != nil {
+		in, out := &in.SendLatency, &out.SendLatency
+		*out = new(PingSourceSendLatency)
+		**out = **in
+	}
 	return
 }
 
@@ -455,3 +625,19 @@ func (in *SinkBindingStatus) DeepCopy() *SinkBindingStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TypeVariant) DeepCopyInto(out *TypeVariant) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TypeVariant.
+func (in *TypeVariant) DeepCopy() *TypeVariant {
+	if in == nil {
+		return nil
+	}
+	out := new(TypeVariant)
+	in.DeepCopyInto(out)
+	return out
+}
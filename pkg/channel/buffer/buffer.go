@@ -0,0 +1,143 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package buffer persists the events accepted by the channels until they are
+// dispatched, for them to be redelivered after the dispatcher restarts.
+package buffer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/diskqueue"
+)
+
+// DefaultSize is the default maximum number of buffered events.
+const DefaultSize = 1000
+
+// Entry is a buffered event of a channel.
+type Entry struct {
+	// ID identifies the entry in the buffer.
+	ID string `json:"-"`
+
+	Channel channel.ChannelReference `json:"channel"`
+	Headers nethttp.Header           `json:"headers,omitempty"`
+	Event   cloudevents.Event        `json:"event"`
+}
+
+// FileBuffer is a buffer of at most size events, each stored in a file of
+// the directory.
+type FileBuffer struct {
+	queue *diskqueue.Queue
+
+	mu sync.Mutex
+	// recovered are the IDs of the entries found when the buffer was
+	// created, by channel.
+	recovered map[channel.ChannelReference][]string
+}
+
+// NewFileBuffer creates the buffer of the directory, recovering the entries
+// buffered before.
+func NewFileBuffer(dir string, size int) (*FileBuffer, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid buffer size %d", size)
+	}
+	q, err := diskqueue.New(dir, size, 0)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := q.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the buffer directory: %w", err)
+	}
+
+	b := &FileBuffer{
+		queue:     q,
+		recovered: make(map[channel.ChannelReference][]string),
+	}
+	for _, id := range ids {
+		entry, err := b.read(id)
+		if err != nil {
+			_ = q.Remove(id)
+			continue
+		}
+		b.recovered[entry.Channel] = append(b.recovered[entry.Channel], id)
+	}
+	return b, nil
+}
+
+// Add stores the entry, returning its ID, or false when the buffer is full.
+func (b *FileBuffer) Add(entry Entry) (string, bool, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", false, err
+	}
+	id, err := b.queue.Push(data)
+	if errors.Is(err, diskqueue.ErrFull) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return id, true, nil
+}
+
+// Remove deletes the entry once its event is dispatched.
+func (b *FileBuffer) Remove(id string) error {
+	return b.queue.Remove(id)
+}
+
+// Recovered returns the entries of the channel found when the buffer was
+// created, in the order they were added. They are only returned once.
+func (b *FileBuffer) Recovered(ref channel.ChannelReference) []Entry {
+	b.mu.Lock()
+	ids := b.recovered[ref]
+	delete(b.recovered, ref)
+	b.mu.Unlock()
+
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		entry, err := b.read(id)
+		if err != nil {
+			// Removed since.
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Len returns the number of buffered events.
+func (b *FileBuffer) Len() int {
+	return b.queue.Len()
+}
+
+func (b *FileBuffer) read(id string) (Entry, error) {
+	entry := Entry{ID: id}
+	data, err := b.queue.Read(id)
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(data, &entry)
+	entry.ID = id
+	return entry, err
+}
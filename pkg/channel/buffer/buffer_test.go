@@ -0,0 +1,117 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package buffer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing/pkg/channel"
+)
+
+func TestFileBuffer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "channel-buffer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ref := channel.ChannelReference{Namespace: "ns", Name: "channel"}
+	other := channel.ChannelReference{Namespace: "ns", Name: "other"}
+
+	b, err := NewFileBuffer(dir, 3)
+	if err != nil {
+		t.Fatal("NewFileBuffer =", err)
+	}
+	var ids []string
+	for _, e := range []Entry{
+		{Channel: ref, Event: makeEvent("1")},
+		{Channel: other, Event: makeEvent("2")},
+		{Channel: ref, Event: makeEvent("3")},
+	} {
+		id, ok, err := b.Add(e)
+		if err != nil || !ok {
+			t.Fatalf("Add = %v, %v, want true, nil", ok, err)
+		}
+		ids = append(ids, id)
+	}
+	if _, ok, err := b.Add(Entry{Channel: ref, Event: makeEvent("4")}); err != nil || ok {
+		t.Fatalf("Add to a full buffer = %v, %v, want false, nil", ok, err)
+	}
+	if err := b.Remove(ids[1]); err != nil {
+		t.Fatal("Remove =", err)
+	}
+	if got := b.Len(); got != 2 {
+		t.Errorf("Len = %d, want 2", got)
+	}
+	// Nothing was recovered.
+	if got := b.Recovered(ref); len(got) != 0 {
+		t.Errorf("Recovered = %v, want none", got)
+	}
+
+	// An interrupted write is left over.
+	if err := ioutil.WriteFile(filepath.Join(dir, "interrupted.tmp"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := NewFileBuffer(dir, 3)
+	if err != nil {
+		t.Fatal("NewFileBuffer =", err)
+	}
+	if got := restarted.Len(); got != 2 {
+		t.Errorf("Len after restart = %d, want 2", got)
+	}
+	recovered := restarted.Recovered(ref)
+	if len(recovered) != 2 || recovered[0].Event.ID() != "1" || recovered[1].Event.ID() != "3" {
+		t.Fatalf("Recovered = %v, want the events 1 and 3", recovered)
+	}
+	if got := restarted.Recovered(ref); len(got) != 0 {
+		t.Errorf("Recovered again = %v, want none", got)
+	}
+	if got := restarted.Recovered(other); len(got) != 0 {
+		t.Errorf("Recovered of the other channel = %v, want none", got)
+	}
+	for _, e := range recovered {
+		if err := restarted.Remove(e.ID); err != nil {
+			t.Fatal("Remove =", err)
+		}
+	}
+	if got := restarted.Len(); got != 0 {
+		t.Errorf("Len = %d, want 0", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "interrupted.tmp")); !os.IsNotExist(err) {
+		t.Error("Expected the interrupted write to be removed, got", err)
+	}
+}
+
+func TestNewFileBufferInvalidSize(t *testing.T) {
+	if _, err := NewFileBuffer(os.TempDir(), 0); err == nil {
+		t.Error("Expected an error for a zero size")
+	}
+}
+
+func makeEvent(id string) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetType("com.example.someevent")
+	event.SetSource("/mycontext")
+	return event
+}
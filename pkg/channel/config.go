@@ -17,6 +17,8 @@
 package channel
 
 import (
+	"strconv"
+
 	corev1 "k8s.io/api/core/v1"
 	knconfigmap "knative.dev/pkg/configmap"
 
@@ -46,6 +48,9 @@ var defaultEventDispatcherConfig = EventDispatcherConfig{
 // EventDispatcherConfig holds the configuration parameters for the event dispatcher.
 type EventDispatcherConfig struct {
 	kncloudevents.ConnectionArgs
+	// LogDiscardedEvents controls whether the events failing to be delivered,
+	// and discarded, are logged with their id and source.
+	LogDiscardedEvents bool
 }
 
 // NewEventDisPatcherConfigFromConfigMap converts a k8s configmap into EventDispatcherConfig.
@@ -63,8 +68,17 @@ func NewEventDisPatcherConfigFromConfigMap(config *corev1.ConfigMap) (EventDispa
 			Field:        &c.MaxIdleConnsPerHost,
 		},
 	}
-	err := configmap.ReadInt(requests, config)
-	return c, err
+	if err := configmap.ReadInt(requests, config); err != nil {
+		return c, err
+	}
+	if raw, ok := config.Data["LogDiscardedEvents"]; ok {
+		logDiscarded, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c, err
+		}
+		c.LogDiscardedEvents = logDiscarded
+	}
+	return c, nil
 }
 
 // EventDispatcherConfigStore loads/unloads untyped configuration from configmap.
@@ -40,8 +40,9 @@ func TestGetConfig(t *testing.T) {
 					MaxIdleConns:        20,
 					MaxIdleConnsPerHost: 10,
 				},
+				LogDiscardedEvents: true,
 			},
-			keys: []string{"MaxIdleConnections", "MaxIdleConnectionsPerHost"},
+			keys: []string{"MaxIdleConnections", "MaxIdleConnectionsPerHost", "LogDiscardedEvents"},
 		},
 		{
 			name: "Only MaxIdleConnections is configured",
@@ -27,6 +27,7 @@ import (
 	nethttp "net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
@@ -36,6 +37,7 @@ import (
 
 	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/channel/buffer"
 	"knative.dev/eventing/pkg/kncloudevents"
 )
 
@@ -56,6 +58,12 @@ type Config struct {
 	// AsyncHandler controls whether the Subscriptions are called synchronous or asynchronously.
 	// It is expected to be false when used as a sidecar.
 	AsyncHandler bool `json:"asyncHandler,omitempty"`
+	// LogDiscardedEvents controls whether the events failing to be delivered, to the
+	// subscriber and to the dead letter sink if any, are logged with their id and source.
+	LogDiscardedEvents bool `json:"logDiscardedEvents,omitempty"`
+	// Buffer, when set, persists the events until they are dispatched, for
+	// them to be replayed after a restart.
+	Buffer *buffer.FileBuffer `json:"-"`
 }
 
 // MessageHandler is an http.Handler but has methods for managing
@@ -66,6 +74,7 @@ type MessageHandler interface {
 	nethttp.Handler
 	SetSubscriptions(ctx context.Context, subs []Subscription)
 	GetSubscriptions(ctx context.Context) []Subscription
+	SetLogDiscardedEvents(logDiscardedEvents bool)
 }
 
 // MessageHandler is a http.Handler that takes a single request in and fans it out to N other servers.
@@ -74,12 +83,17 @@ type FanoutMessageHandler struct {
 	// It is expected to be false when used as a sidecar.
 	asyncHandler bool
 
+	// logDiscardedEvents is 1 when the discarded events are logged.
+	logDiscardedEvents int32
+
 	subscriptionsMutex sync.RWMutex
 	subscriptions      []Subscription
 
 	receiver   *channel.MessageReceiver
 	dispatcher channel.MessageDispatcher
 
+	eventBuffer *buffer.FileBuffer
+
 	// TODO: Plumb context through the receiver and dispatcher and use that to store the timeout,
 	// rather than a member variable.
 	timeout time.Duration
@@ -97,7 +111,9 @@ func NewFanoutMessageHandler(logger *zap.Logger, messageDispatcher channel.Messa
 		timeout:      defaultTimeout,
 		reporter:     reporter,
 		asyncHandler: config.AsyncHandler,
+		eventBuffer:  config.Buffer,
 	}
+	handler.SetLogDiscardedEvents(config.LogDiscardedEvents)
 	handler.subscriptions = make([]Subscription, len(config.Subscriptions))
 	for i := range config.Subscriptions {
 		handler.subscriptions[i] = config.Subscriptions[i]
@@ -158,6 +174,15 @@ func (f *FanoutMessageHandler) GetSubscriptions(ctx context.Context) []Subscript
 	return ret
 }
 
+// SetLogDiscardedEvents sets whether the discarded events are logged.
+func (f *FanoutMessageHandler) SetLogDiscardedEvents(logDiscardedEvents bool) {
+	var v int32
+	if logDiscardedEvents {
+		v = 1
+	}
+	atomic.StoreInt32(&f.logDiscardedEvents, v)
+}
+
 func createMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context, channel.ChannelReference, binding.Message, []binding.Transformer, nethttp.Header) error {
 	if f.asyncHandler {
 		return func(ctx context.Context, ref channel.ChannelReference, message binding.Message, transformers []binding.Transformer, additionalHeaders nethttp.Header) error {
@@ -185,11 +210,13 @@ func createMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context
 
 			// We don't need the original message anymore
 			_ = message.Finish(nil)
+			id := f.bufferMessage(ctx, ref, bufferedMessage, additionalHeaders, &reportArgs)
 			go func(m binding.Message, h nethttp.Header, s *trace.Span, r *channel.StatsReporter, args *channel.ReportArgs) {
 				// Run async dispatch with background context.
 				ctx = trace.NewContext(context.Background(), s)
 				// Any returned error is already logged in f.dispatch().
-				dispatchResultForFanout := f.dispatch(ctx, subs, m, h)
+				dispatchResultForFanout := f.dispatch(ctx, subs, m, h, args)
+				f.releaseMessage(id)
 				_ = parseFanoutResultAndReportMetrics(dispatchResultForFanout, *r, *args)
 			}(bufferedMessage, additionalHeaders, parentSpan, &f.reporter, &reportArgs)
 			return nil
@@ -216,11 +243,34 @@ func createMessageReceiverFunction(f *FanoutMessageHandler) func(context.Context
 		reportArgs := channel.ReportArgs{}
 		reportArgs.EventType = string(te)
 		reportArgs.Ns = ref.Namespace
-		dispatchResultForFanout := f.dispatch(ctx, subs, bufferedMessage, additionalHeaders)
+		id := f.bufferMessage(ctx, ref, bufferedMessage, additionalHeaders, &reportArgs)
+		dispatchResultForFanout := f.dispatch(ctx, subs, bufferedMessage, additionalHeaders, &reportArgs)
+		f.releaseMessage(id)
 		return parseFanoutResultAndReportMetrics(dispatchResultForFanout, f.reporter, reportArgs)
 	}
 }
 
+// Replay redelivers the events of the channel buffered before the dispatcher
+// restarted, and not dispatched then.
+func (f *FanoutMessageHandler) Replay(ctx context.Context, ref channel.ChannelReference) {
+	if f.eventBuffer == nil {
+		return
+	}
+	for _, entry := range f.eventBuffer.Recovered(ref) {
+		reportArgs := channel.ReportArgs{
+			Ns:        ref.Namespace,
+			EventType: entry.Event.Type(),
+		}
+		if subs := f.GetSubscriptions(ctx); len(subs) > 0 {
+			event := entry.Event
+			dispatchResultForFanout := f.dispatch(ctx, subs, binding.ToMessage(&event), entry.Headers, &reportArgs)
+			_ = parseFanoutResultAndReportMetrics(dispatchResultForFanout, f.reporter, reportArgs)
+		}
+		_ = f.reporter.ReportEventReplayed(&reportArgs)
+		f.releaseMessage(entry.ID)
+	}
+}
+
 func (f *FanoutMessageHandler) ServeHTTP(response nethttp.ResponseWriter, request *nethttp.Request) {
 	f.receiver.ServeHTTP(response, request)
 }
@@ -244,7 +294,7 @@ func parseFanoutResultAndReportMetrics(result dispatchResult, reporter channel.S
 
 // dispatch takes the event, fans it out to each subscription in subs. If all the fanned out
 // events return successfully, then return nil. Else, return an error.
-func (f *FanoutMessageHandler) dispatch(ctx context.Context, subs []Subscription, bufferedMessage binding.Message, additionalHeaders nethttp.Header) dispatchResult {
+func (f *FanoutMessageHandler) dispatch(ctx context.Context, subs []Subscription, bufferedMessage binding.Message, additionalHeaders nethttp.Header, reportArgs *channel.ReportArgs) dispatchResult {
 	// Bind the lifecycle of the buffered message to the number of subs
 	bufferedMessage = buffering.WithAcksBeforeFinish(bufferedMessage, len(subs))
 
@@ -252,6 +302,9 @@ func (f *FanoutMessageHandler) dispatch(ctx context.Context, subs []Subscription
 	for _, sub := range subs {
 		go func(s Subscription) {
 			dispatchedResultPerSub, err := f.makeFanoutRequest(ctx, bufferedMessage, additionalHeaders, s)
+			if err != nil {
+				f.discarded(ctx, bufferedMessage, s, reportArgs, err)
+			}
 			errorCh <- dispatchResult{err: err, info: dispatchedResultPerSub}
 		}(sub)
 	}
@@ -306,6 +359,59 @@ func (f *FanoutMessageHandler) makeFanoutRequest(ctx context.Context, message bi
 	)
 }
 
+// discarded accounts for the message the subscription failed to deliver, to
+// the subscriber and to the dead letter sink if any, and that is lost.
+func (f *FanoutMessageHandler) discarded(ctx context.Context, message binding.Message, sub Subscription, reportArgs *channel.ReportArgs, err error) {
+	_ = f.reporter.ReportEventDiscarded(reportArgs)
+	if atomic.LoadInt32(&f.logDiscardedEvents) == 0 {
+		return
+	}
+	fields := []zap.Field{
+		zap.Stringer("subscriber", sub.Subscriber),
+		zap.Bool("deadLetterSink", sub.DeadLetter != nil),
+		zap.Error(err),
+	}
+	// The message is buffered, it can be read again.
+	if event, eventErr := binding.ToEvent(ctx, message); eventErr == nil {
+		fields = append(fields, zap.String("id", event.ID()), zap.String("source", event.Source()))
+	}
+	f.logger.Warn("Event discarded", fields...)
+}
+
+// bufferMessage persists the message until it's dispatched, returning the ID
+// of its entry, empty when it isn't buffered.
+func (f *FanoutMessageHandler) bufferMessage(ctx context.Context, ref channel.ChannelReference, message binding.Message, additionalHeaders nethttp.Header, reportArgs *channel.ReportArgs) string {
+	if f.eventBuffer == nil {
+		return ""
+	}
+	// The message is buffered, it can be read again.
+	event, err := binding.ToEvent(ctx, message)
+	if err != nil {
+		f.logger.Warn("Failed to buffer the event", zap.Error(err))
+		return ""
+	}
+	id, ok, err := f.eventBuffer.Add(buffer.Entry{Channel: ref, Headers: additionalHeaders, Event: *event})
+	switch {
+	case err != nil:
+		f.logger.Warn("Failed to buffer the event", zap.Error(err))
+	case !ok:
+		_ = f.reporter.ReportEventOverflowed(reportArgs)
+	default:
+		_ = f.reporter.ReportEventBuffered(reportArgs)
+	}
+	return id
+}
+
+// releaseMessage removes the buffered message once dispatched.
+func (f *FanoutMessageHandler) releaseMessage(id string) {
+	if id == "" {
+		return
+	}
+	if err := f.eventBuffer.Remove(id); err != nil {
+		f.logger.Warn("Failed to remove the buffered event", zap.Error(err))
+	}
+}
+
 type dispatchResult struct {
 	err  error
 	info *channel.DispatchExecutionInfo
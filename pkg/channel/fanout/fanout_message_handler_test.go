@@ -19,8 +19,10 @@ package fanout
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -37,9 +39,11 @@ import (
 	"go.opencensus.io/trace"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"knative.dev/pkg/apis"
 
 	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/channel/buffer"
 )
 
 // Domains used in subscriptions, which will be replaced by the real domains of the started HTTP
@@ -377,6 +381,219 @@ func testFanoutMessageHandler(t *testing.T, async bool, receiverFunc channel.Unb
 	}
 }
 
+func TestFanoutMessageHandler_Discarded(t *testing.T) {
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer subscriber.Close()
+
+	for _, logDiscarded := range []bool{false, true} {
+		core, logs := observer.New(zap.WarnLevel)
+		reporter := &discardReporter{StatsReporter: channel.NewStatsReporter("testcontainer", "testpod")}
+		h, err := NewFanoutMessageHandler(
+			zap.New(core),
+			channel.NewMessageDispatcher(zap.NewNop()),
+			Config{
+				Subscriptions:      []Subscription{{Subscriber: apis.HTTP(subscriber.URL[7:]).URL()}},
+				LogDiscardedEvents: logDiscarded,
+			},
+			reporter,
+		)
+		if err != nil {
+			t.Fatal("NewHandler failed =", err)
+		}
+
+		event := makeCloudEvent()
+		req := httptest.NewRequest(http.MethodPost, "http://channelname.channelnamespace/", nil)
+		if err := bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req); err != nil {
+			t.Fatal("WriteRequest =", err)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		if got := reporter.discarded.Load(); got != 1 {
+			t.Errorf("Discarded events = %d, want 1", got)
+		}
+		entries := logs.FilterMessage("Event discarded").All()
+		if !logDiscarded {
+			if len(entries) != 0 {
+				t.Errorf("Discarded events logged while disabled: %v", entries)
+			}
+			continue
+		}
+		if len(entries) != 1 {
+			t.Fatalf("Discarded events logged = %d, want 1", len(entries))
+		}
+		fields := entries[0].ContextMap()
+		if fields["id"] != event.ID() || fields["source"] != event.Source() || fields["deadLetterSink"] != false {
+			t.Errorf("Unexpected fields of the discarded event log: %v", fields)
+		}
+	}
+}
+
+type discardReporter struct {
+	channel.StatsReporter
+	discarded atomic.Int32
+}
+
+func (r *discardReporter) ReportEventDiscarded(args *channel.ReportArgs) error {
+	r.discarded.Inc()
+	return r.StatsReporter.ReportEventDiscarded(args)
+}
+
+func TestFanoutMessageHandler_Buffer(t *testing.T) {
+	ref := channel.ChannelReference{Name: "channelname", Namespace: "channelnamespace"}
+
+	for _, full := range []bool{false, true} {
+		dir, err := ioutil.TempDir("", "fanout-buffer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		eventBuffer, err := buffer.NewFileBuffer(dir, 1)
+		if err != nil {
+			t.Fatal("NewFileBuffer =", err)
+		}
+		if full {
+			other := makeCloudEvent()
+			if _, _, err := eventBuffer.Add(buffer.Entry{Channel: channel.ChannelReference{Name: "other"}, Event: other}); err != nil {
+				t.Fatal("Add =", err)
+			}
+		}
+
+		var bufferedWhileDispatched atomic.Int32
+		subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			bufferedWhileDispatched.Store(int32(eventBuffer.Len()))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+
+		reporter := &bufferReporter{StatsReporter: channel.NewStatsReporter("testcontainer", "testpod")}
+		h, err := NewFanoutMessageHandler(
+			zap.NewNop(),
+			channel.NewMessageDispatcher(zap.NewNop()),
+			Config{
+				Subscriptions: []Subscription{{Subscriber: apis.HTTP(subscriber.URL[7:]).URL()}},
+				Buffer:        eventBuffer,
+			},
+			reporter,
+		)
+		if err != nil {
+			t.Fatal("NewHandler failed =", err)
+		}
+
+		event := makeCloudEvent()
+		req := httptest.NewRequest(http.MethodPost, "http://"+ref.Name+"."+ref.Namespace+"/", nil)
+		if err := bindingshttp.WriteRequest(context.Background(), binding.ToMessage(&event), req); err != nil {
+			t.Fatal("WriteRequest =", err)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		subscriber.Close()
+
+		// The buffer holds the event while it's dispatched, and the one of
+		// the other channel when full.
+		if got := bufferedWhileDispatched.Load(); got != 1 {
+			t.Errorf("Buffered events while dispatched = %d, want 1", got)
+		}
+		wantBuffered, wantOverflowed, wantLen := int32(1), int32(0), 0
+		if full {
+			wantBuffered, wantOverflowed, wantLen = 0, 1, 1
+		}
+		if got := reporter.buffered.Load(); got != wantBuffered {
+			t.Errorf("Buffered events = %d, want %d", got, wantBuffered)
+		}
+		if got := reporter.overflowed.Load(); got != wantOverflowed {
+			t.Errorf("Overflowed events = %d, want %d", got, wantOverflowed)
+		}
+		if got := eventBuffer.Len(); got != wantLen {
+			t.Errorf("Buffered events after dispatch = %d, want %d", got, wantLen)
+		}
+	}
+}
+
+func TestFanoutMessageHandler_Replay(t *testing.T) {
+	ref := channel.ChannelReference{Name: "channelname", Namespace: "channelnamespace"}
+	dir, err := ioutil.TempDir("", "fanout-buffer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	before, err := buffer.NewFileBuffer(dir, 10)
+	if err != nil {
+		t.Fatal("NewFileBuffer =", err)
+	}
+	event := makeCloudEvent()
+	if _, _, err := before.Add(buffer.Entry{Channel: ref, Event: event}); err != nil {
+		t.Fatal("Add =", err)
+	}
+
+	// The dispatcher restarts.
+	eventBuffer, err := buffer.NewFileBuffer(dir, 10)
+	if err != nil {
+		t.Fatal("NewFileBuffer =", err)
+	}
+
+	var received atomic.String
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(r.Header.Get("ce-id"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer subscriber.Close()
+
+	reporter := &bufferReporter{StatsReporter: channel.NewStatsReporter("testcontainer", "testpod")}
+	h, err := NewFanoutMessageHandler(
+		zap.NewNop(),
+		channel.NewMessageDispatcher(zap.NewNop()),
+		Config{
+			Subscriptions: []Subscription{{Subscriber: apis.HTTP(subscriber.URL[7:]).URL()}},
+			Buffer:        eventBuffer,
+		},
+		reporter,
+	)
+	if err != nil {
+		t.Fatal("NewHandler failed =", err)
+	}
+
+	h.Replay(context.Background(), ref)
+
+	if got := received.Load(); got != event.ID() {
+		t.Errorf("Replayed event id = %q, want %q", got, event.ID())
+	}
+	if got := reporter.replayed.Load(); got != 1 {
+		t.Errorf("Replayed events = %d, want 1", got)
+	}
+	if got := eventBuffer.Len(); got != 0 {
+		t.Errorf("Buffered events after replay = %d, want 0", got)
+	}
+
+	// The events are only replayed once.
+	received.Store("")
+	h.Replay(context.Background(), ref)
+	if got := received.Load(); got != "" {
+		t.Errorf("Event %q replayed twice", got)
+	}
+}
+
+type bufferReporter struct {
+	channel.StatsReporter
+	buffered, replayed, overflowed atomic.Int32
+}
+
+func (r *bufferReporter) ReportEventBuffered(args *channel.ReportArgs) error {
+	r.buffered.Inc()
+	return r.StatsReporter.ReportEventBuffered(args)
+}
+
+func (r *bufferReporter) ReportEventReplayed(args *channel.ReportArgs) error {
+	r.replayed.Inc()
+	return r.StatsReporter.ReportEventReplayed(args)
+}
+
+func (r *bufferReporter) ReportEventOverflowed(args *channel.ReportArgs) error {
+	r.overflowed.Inc()
+	return r.StatsReporter.ReportEventOverflowed(args)
+}
+
 type fakeHandlerWithWg struct {
 	wg      *sync.WaitGroup
 	handler func(http.ResponseWriter, *http.Request)
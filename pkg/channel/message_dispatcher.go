@@ -115,11 +115,11 @@ func (d *MessageDispatcherImpl) DispatchMessageWithRetries(ctx context.Context,
 		// Try to send to destination
 		messagesToFinish = append(messagesToFinish, message)
 
-		ctx, responseMessage, responseAdditionalHeaders, dispatchExecutionInfo, err = d.executeRequest(ctx, destination, message, additionalHeaders, retriesConfig)
+		ctx, responseMessage, responseAdditionalHeaders, dispatchExecutionInfo, err = d.executeRequest(ctx, destination, message, additionalHeaders, retriesConfig, destinationFormat(retriesConfig))
 		if err != nil {
 			// DeadLetter is configured, send the message to it
 			if deadLetter != nil {
-				_, deadLetterResponse, _, dispatchExecutionInfo, deadLetterErr := d.executeRequest(ctx, deadLetter, message, additionalHeaders, retriesConfig)
+				_, deadLetterResponse, _, dispatchExecutionInfo, deadLetterErr := d.executeRequest(ctx, deadLetter, message, additionalHeaders, retriesConfig, nil)
 				if deadLetterErr != nil {
 					return dispatchExecutionInfo, fmt.Errorf("unable to complete request to either %s (%v) or %s (%v)", destination, err, deadLetter, deadLetterErr)
 				}
@@ -150,11 +150,11 @@ func (d *MessageDispatcherImpl) DispatchMessageWithRetries(ctx context.Context,
 		return dispatchExecutionInfo, nil
 	}
 
-	ctx, responseResponseMessage, _, dispatchExecutionInfo, err := d.executeRequest(ctx, reply, responseMessage, responseAdditionalHeaders, retriesConfig)
+	ctx, responseResponseMessage, _, dispatchExecutionInfo, err := d.executeRequest(ctx, reply, responseMessage, responseAdditionalHeaders, retriesConfig, nil)
 	if err != nil {
 		// DeadLetter is configured, send the message to it
 		if deadLetter != nil {
-			_, deadLetterResponse, _, dispatchExecutionInfo, deadLetterErr := d.executeRequest(ctx, deadLetter, message, responseAdditionalHeaders, retriesConfig)
+			_, deadLetterResponse, _, dispatchExecutionInfo, deadLetterErr := d.executeRequest(ctx, deadLetter, message, responseAdditionalHeaders, retriesConfig, nil)
 			if deadLetterErr != nil {
 				return dispatchExecutionInfo, fmt.Errorf("failed to forward reply to %s (%v) and failed to send it to the dead letter sink %s (%v)", reply, err, deadLetter, deadLetterErr)
 			}
@@ -174,7 +174,7 @@ func (d *MessageDispatcherImpl) DispatchMessageWithRetries(ctx context.Context,
 	return dispatchExecutionInfo, nil
 }
 
-func (d *MessageDispatcherImpl) executeRequest(ctx context.Context, url *url.URL, message cloudevents.Message, additionalHeaders nethttp.Header, configs *kncloudevents.RetryConfig) (context.Context, cloudevents.Message, nethttp.Header, *DispatchExecutionInfo, error) {
+func (d *MessageDispatcherImpl) executeRequest(ctx context.Context, url *url.URL, message cloudevents.Message, additionalHeaders nethttp.Header, configs *kncloudevents.RetryConfig, format *kncloudevents.Format) (context.Context, cloudevents.Message, nethttp.Header, *DispatchExecutionInfo, error) {
 	d.logger.Debug("Dispatching event", zap.String("url", url.String()))
 
 	execInfo := DispatchExecutionInfo{
@@ -190,9 +190,9 @@ func (d *MessageDispatcherImpl) executeRequest(ctx context.Context, url *url.URL
 	}
 
 	if span.IsRecordingEvents() {
-		err = kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders, kncloudevents.PopulateSpan(span))
+		err = kncloudevents.WriteHTTPRequestWithFormat(ctx, message, format, req, additionalHeaders, kncloudevents.PopulateSpan(span))
 	} else {
-		err = kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders)
+		err = kncloudevents.WriteHTTPRequestWithFormat(ctx, message, format, req, additionalHeaders)
 	}
 	if err != nil {
 		return ctx, nil, nil, &execInfo, err
@@ -226,6 +226,15 @@ func (d *MessageDispatcherImpl) executeRequest(ctx context.Context, url *url.URL
 	return ctx, responseMessage, utils.PassThroughHeaders(response.Header), &execInfo, nil
 }
 
+// destinationFormat returns the format of the events sent to the
+// destination, the replies and the dead letters being sent as they are.
+func destinationFormat(configs *kncloudevents.RetryConfig) *kncloudevents.Format {
+	if configs == nil {
+		return nil
+	}
+	return configs.Format
+}
+
 func (d *MessageDispatcherImpl) sanitizeURL(u *url.URL) *url.URL {
 	if u == nil {
 		return nil
@@ -46,6 +46,39 @@ var (
 		stats.UnitMilliseconds,
 	)
 
+	// eventDiscardedCountM is a counter which records the number of events
+	// the in-memory Channel failed to deliver and discarded, their dead
+	// letter sink, if any, failing too.
+	eventDiscardedCountM = stats.Int64(
+		"event_discarded_count",
+		"Number of events discarded by the in-memory channel after failing to be delivered",
+		stats.UnitDimensionless,
+	)
+
+	// eventBufferedCountM is a counter which records the number of events
+	// the in-memory Channel buffered until they are dispatched.
+	eventBufferedCountM = stats.Int64(
+		"event_buffered_count",
+		"Number of events buffered by the in-memory channel until they are dispatched",
+		stats.UnitDimensionless,
+	)
+
+	// eventReplayedCountM is a counter which records the number of buffered
+	// events the in-memory Channel redelivered after restarting.
+	eventReplayedCountM = stats.Int64(
+		"event_replayed_count",
+		"Number of buffered events redelivered by the in-memory channel after restarting",
+		stats.UnitDimensionless,
+	)
+
+	// eventOverflowedCountM is a counter which records the number of events
+	// the in-memory Channel failed to buffer, its buffer being full.
+	eventOverflowedCountM = stats.Int64(
+		"event_overflowed_count",
+		"Number of events not buffered by the in-memory channel, its buffer being full",
+		stats.UnitDimensionless,
+	)
+
 	// Create the tag keys that will be used to add tags to our measurements.
 	// Tag keys must conform to the restrictions described in
 	// go.opencensus.io/tag/validate.go. Currently those restrictions are:
@@ -69,6 +102,10 @@ func init() {
 type StatsReporter interface {
 	ReportEventCount(args *ReportArgs, responseCode int) error
 	ReportEventDispatchTime(args *ReportArgs, responseCode int, d time.Duration) error
+	ReportEventDiscarded(args *ReportArgs) error
+	ReportEventBuffered(args *ReportArgs) error
+	ReportEventReplayed(args *ReportArgs) error
+	ReportEventOverflowed(args *ReportArgs) error
 }
 
 var _ StatsReporter = (*reporter)(nil)
@@ -98,21 +135,39 @@ func register() {
 		ContainerTagKey,
 	}
 
-	// Create view to see our measurements.
-	err := metrics.RegisterResourceView(
-		&view.View{
+	countTagKeys := []tag.Key{
+		namespaceKey,
+		eventTypeKey,
+		UniqueTagKey,
+		ContainerTagKey,
+	}
+
+	views := []*view.View{
+		{
 			Description: eventCountM.Description(),
 			Measure:     eventCountM,
 			Aggregation: view.Count(),
 			TagKeys:     tagKeys,
 		},
-		&view.View{
+		{
 			Description: dispatchTimeInMsecM.Description(),
 			Measure:     dispatchTimeInMsecM,
 			Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...), // 1, 2, 5, 10, 20, 50, 100, 500, 1000, 5000, 10000
 			TagKeys:     tagKeys,
 		},
-	)
+	}
+	// The events not dispatched yet, or not at all, have no response code.
+	for _, m := range []*stats.Int64Measure{eventDiscardedCountM, eventBufferedCountM, eventReplayedCountM, eventOverflowedCountM} {
+		views = append(views, &view.View{
+			Description: m.Description(),
+			Measure:     m,
+			Aggregation: view.Count(),
+			TagKeys:     countTagKeys,
+		})
+	}
+
+	// Create view to see our measurements.
+	err := metrics.RegisterResourceView(views...)
 	if err != nil {
 		log.Print("failed to register opencensus views, " + err.Error())
 	}
@@ -139,6 +194,42 @@ func (r *reporter) ReportEventDispatchTime(args *ReportArgs, responseCode int, d
 	return nil
 }
 
+// ReportEventDiscarded captures the count of the discarded events.
+func (r *reporter) ReportEventDiscarded(args *ReportArgs) error {
+	return r.recordCount(args, eventDiscardedCountM)
+}
+
+// ReportEventBuffered captures the count of the buffered events.
+func (r *reporter) ReportEventBuffered(args *ReportArgs) error {
+	return r.recordCount(args, eventBufferedCountM)
+}
+
+// ReportEventReplayed captures the count of the buffered events redelivered
+// after a restart.
+func (r *reporter) ReportEventReplayed(args *ReportArgs) error {
+	return r.recordCount(args, eventReplayedCountM)
+}
+
+// ReportEventOverflowed captures the count of the events failing to be
+// buffered, the buffer being full.
+func (r *reporter) ReportEventOverflowed(args *ReportArgs) error {
+	return r.recordCount(args, eventOverflowedCountM)
+}
+
+func (r *reporter) recordCount(args *ReportArgs, m *stats.Int64Measure) error {
+	ctx, err := tag.New(
+		emptyContext,
+		tag.Insert(namespaceKey, args.Ns),
+		tag.Insert(eventTypeKey, args.EventType),
+		tag.Insert(ContainerTagKey, r.container),
+		tag.Insert(UniqueTagKey, r.uniqueName))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, m.M(1))
+	return nil
+}
+
 func (r *reporter) generateTag(args *ReportArgs, responseCode int) (context.Context, error) {
 	return tag.New(
 		emptyContext,
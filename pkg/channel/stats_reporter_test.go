@@ -61,6 +61,35 @@ func TestStatsReporter(t *testing.T) {
 		return r.ReportEventDispatchTime(args, http.StatusAccepted, 9100*time.Millisecond)
 	})
 	metricstest.CheckDistributionData(t, "event_dispatch_latencies", wantTags, 2, 1100.0, 9100.0)
+
+	// test ReportEventDiscarded
+	countTags := map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelEventType:     "testeventtype",
+		LabelUniqueName:               "testpod",
+		LabelContainerName:            "testcontainer",
+	}
+	expectSuccess(t, func() error {
+		return r.ReportEventDiscarded(args)
+	})
+	metricstest.CheckCountData(t, "event_discarded_count", countTags, 1)
+
+	// test ReportEventBuffered, ReportEventReplayed and ReportEventOverflowed
+	expectSuccess(t, func() error {
+		return r.ReportEventBuffered(args)
+	})
+	expectSuccess(t, func() error {
+		return r.ReportEventBuffered(args)
+	})
+	metricstest.CheckCountData(t, "event_buffered_count", countTags, 2)
+	expectSuccess(t, func() error {
+		return r.ReportEventReplayed(args)
+	})
+	metricstest.CheckCountData(t, "event_replayed_count", countTags, 1)
+	expectSuccess(t, func() error {
+		return r.ReportEventOverflowed(args)
+	})
+	metricstest.CheckCountData(t, "event_overflowed_count", countTags, 1)
 }
 
 func expectSuccess(t *testing.T, f func() error) {
@@ -78,6 +107,10 @@ func resetMetrics() {
 	// OpenCensus metrics carry global state that need to be reset between unit tests.
 	metricstest.Unregister(
 		"event_count",
-		"event_dispatch_latencies")
+		"event_dispatch_latencies",
+		"event_discarded_count",
+		"event_buffered_count",
+		"event_replayed_count",
+		"event_overflowed_count")
 	register()
 }
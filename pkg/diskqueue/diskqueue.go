@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diskqueue persists entries to a directory, one file per entry,
+// for them to survive restarts.
+package diskqueue
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entrySuffix is the suffix of the files of the entries, the files being
+// written under a temporary name first.
+const entrySuffix = ".json"
+
+// ErrFull is returned when pushing an entry would exceed the caps of the
+// queue.
+var ErrFull = errors.New("disk queue is full")
+
+// Queue is a queue of entries, each stored in a file of its directory. The
+// IDs of the entries sort in the order they were pushed.
+type Queue struct {
+	dir string
+	// maxEntries and maxBytes cap the number and the total size of the
+	// entries. Zero means unbounded.
+	maxEntries int
+	maxBytes   int64
+
+	mu    sync.Mutex
+	count int
+	bytes int64
+	seq   uint64
+}
+
+// New creates the queue of the directory, keeping the entries pushed
+// before and removing the files of the writes interrupted by a restart.
+func New(dir string, maxEntries int, maxBytes int64) (*Queue, error) {
+	if maxEntries < 0 || maxBytes < 0 {
+		return nil, fmt.Errorf("invalid disk queue caps %d entries, %d bytes", maxEntries, maxBytes)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the queue directory: %w", err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the queue directory: %w", err)
+	}
+
+	q := &Queue{dir: dir, maxEntries: maxEntries, maxBytes: maxBytes}
+	for _, f := range files {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if !isEntry(f.Name()) {
+			// Left over by a write interrupted by the restart.
+			_ = os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		q.count++
+		q.bytes += f.Size()
+	}
+	return q, nil
+}
+
+// Push stores the entry, returning its ID, or ErrFull when the caps of the
+// queue would be exceeded.
+func (q *Queue) Push(data []byte) (string, error) {
+	size := int64(len(data))
+
+	q.mu.Lock()
+	if (q.maxEntries > 0 && q.count >= q.maxEntries) || (q.maxBytes > 0 && q.bytes+size > q.maxBytes) {
+		q.mu.Unlock()
+		return "", ErrFull
+	}
+	q.count++
+	q.bytes += size
+	q.seq++
+	id := fmt.Sprintf("%020d-%010d", time.Now().UnixNano(), q.seq)
+	q.mu.Unlock()
+
+	if err := q.write(id, data); err != nil {
+		q.mu.Lock()
+		q.count--
+		q.bytes -= size
+		q.mu.Unlock()
+		return "", err
+	}
+	return id, nil
+}
+
+// List returns the IDs of the entries, oldest first.
+func (q *Queue) List() ([]string, error) {
+	files, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.Mode().IsRegular() && isEntry(f.Name()) {
+			ids = append(ids, strings.TrimSuffix(f.Name(), entrySuffix))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Read returns the entry with the given ID.
+func (q *Queue) Read(id string) ([]byte, error) {
+	return ioutil.ReadFile(q.path(id))
+}
+
+// Remove deletes the entry with the given ID. Removing a missing entry is
+// not an error.
+func (q *Queue) Remove(id string) error {
+	path := q.path(id)
+	info, err := os.Stat(path)
+	if err == nil {
+		err = os.Remove(path)
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	q.mu.Lock()
+	q.count--
+	q.bytes -= info.Size()
+	q.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of entries.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.count
+}
+
+func (q *Queue) path(id string) string {
+	return filepath.Join(q.dir, id+entrySuffix)
+}
+
+// write writes the entry to a temporary file first, for the entries to
+// never be found partially written.
+func (q *Queue) write(id string, data []byte) error {
+	tmp := filepath.Join(q.dir, "."+id+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, q.path(id))
+}
+
+// isEntry tells whether the file name is the one of an entry, the names of
+// the temporary files being hidden or not ending with entrySuffix.
+func isEntry(name string) bool {
+	return !strings.HasPrefix(name, ".") && strings.HasSuffix(name, entrySuffix)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskqueue
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diskqueue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := New(dir, 3, 0)
+	if err != nil {
+		t.Fatal("New =", err)
+	}
+	var ids []string
+	for _, data := range []string{"1", "2", "3"} {
+		id, err := q.Push([]byte(data))
+		if err != nil {
+			t.Fatal("Push =", err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := q.Push([]byte("4")); !errors.Is(err, ErrFull) {
+		t.Fatalf("Push to a full queue = %v, want ErrFull", err)
+	}
+	if err := q.Remove(ids[1]); err != nil {
+		t.Fatal("Remove =", err)
+	}
+	if err := q.Remove(ids[1]); err != nil {
+		t.Fatal("Remove of a removed entry =", err)
+	}
+	if got := q.Len(); got != 2 {
+		t.Errorf("Len = %d, want 2", got)
+	}
+
+	// An interrupted write is left over.
+	if err := ioutil.WriteFile(filepath.Join(dir, ".interrupted.tmp"), []byte("5"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := New(dir, 3, 0)
+	if err != nil {
+		t.Fatal("New =", err)
+	}
+	if got := restarted.Len(); got != 2 {
+		t.Errorf("Len after restart = %d, want 2", got)
+	}
+	listed, err := restarted.List()
+	if err != nil {
+		t.Fatal("List =", err)
+	}
+	if len(listed) != 2 || listed[0] != ids[0] || listed[1] != ids[2] {
+		t.Fatalf("List = %v, want %v", listed, []string{ids[0], ids[2]})
+	}
+	if data, err := restarted.Read(listed[1]); err != nil || string(data) != "3" {
+		t.Errorf("Read = %q, %v, want 3", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".interrupted.tmp")); !os.IsNotExist(err) {
+		t.Error("Expected the interrupted write to be removed, got", err)
+	}
+}
+
+func TestQueueMaxBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diskqueue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := New(dir, 0, 10)
+	if err != nil {
+		t.Fatal("New =", err)
+	}
+	first, err := q.Push([]byte("123456"))
+	if err != nil {
+		t.Fatal("Push =", err)
+	}
+	if _, err := q.Push([]byte("12345")); !errors.Is(err, ErrFull) {
+		t.Fatalf("Push exceeding the size cap = %v, want ErrFull", err)
+	}
+	if err := q.Remove(first); err != nil {
+		t.Fatal("Remove =", err)
+	}
+	if _, err := q.Push([]byte("12345")); err != nil {
+		t.Fatal("Push once an entry is removed =", err)
+	}
+
+	// The size of the entries is recovered after a restart.
+	restarted, err := New(dir, 0, 10)
+	if err != nil {
+		t.Fatal("New =", err)
+	}
+	if _, err := restarted.Push([]byte("123456")); !errors.Is(err, ErrFull) {
+		t.Fatalf("Push exceeding the recovered size = %v, want ErrFull", err)
+	}
+}
+
+func TestNewInvalidCaps(t *testing.T) {
+	if _, err := New(os.TempDir(), -1, 0); err == nil {
+		t.Error("Expected an error for a negative cap")
+	}
+}
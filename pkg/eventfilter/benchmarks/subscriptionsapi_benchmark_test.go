@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmarks
+
+import (
+	"testing"
+
+	cetest "github.com/cloudevents/sdk-go/v2/test"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/subscriptionsapi"
+)
+
+func BenchmarkSubscriptionsAPIFilter(b *testing.B) {
+	event := cetest.FullEvent()
+
+	RunFilterBenchmarks(b,
+		func(i interface{}) eventfilter.Filter {
+			f, err := subscriptionsapi.NewFilter(i.(eventingv1beta1.SubscriptionsAPIFilter))
+			if err != nil {
+				b.Fatal(err)
+			}
+			return f
+		},
+		FilterBenchmark{
+			name:  "Pass with exact match of id",
+			arg:   eventingv1beta1.SubscriptionsAPIFilter{Exact: map[string]string{"id": event.ID()}},
+			event: event,
+		},
+		FilterBenchmark{
+			name: "Pass with prefix match of type and suffix match of source",
+			arg: eventingv1beta1.SubscriptionsAPIFilter{All: []eventingv1beta1.SubscriptionsAPIFilter{
+				{Prefix: map[string]string{"type": event.Type()[:1]}},
+				{Suffix: map[string]string{"source": event.Source()[len(event.Source())-1:]}},
+			}},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "Pass with CESQL expression on id and source",
+			arg:   eventingv1beta1.SubscriptionsAPIFilter{CESQL: "id = '" + event.ID() + "' AND source LIKE '%'"},
+			event: event,
+		},
+		FilterBenchmark{
+			name:  "No pass with CESQL expression on id",
+			arg:   eventingv1beta1.SubscriptionsAPIFilter{CESQL: "id = 'qwertyuiopasdfghjklzxcvbnm'"},
+			event: event,
+		},
+	)
+}
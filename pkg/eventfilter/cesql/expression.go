@@ -0,0 +1,347 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+// Expression is a parsed CloudEvents SQL expression.
+type Expression interface {
+	// Evaluate returns the value of the expression for event, a bool, an
+	// int32 or a string.
+	Evaluate(event cloudevents.Event) (interface{}, error)
+}
+
+var errDivisionByZero = errors.New("division by zero")
+
+type literal struct {
+	value interface{}
+}
+
+func (e literal) Evaluate(cloudevents.Event) (interface{}, error) {
+	return e.value, nil
+}
+
+type attribute struct {
+	name string
+}
+
+func (e attribute) Evaluate(event cloudevents.Event) (interface{}, error) {
+	value, ok := eventfilter.ContextAttribute(event, e.name)
+	if !ok {
+		return nil, fmt.Errorf("missing attribute %q", e.name)
+	}
+	return value, nil
+}
+
+type exists struct {
+	name string
+}
+
+func (e *exists) Evaluate(event cloudevents.Event) (interface{}, error) {
+	_, ok := eventfilter.ContextAttribute(event, e.name)
+	return ok, nil
+}
+
+type logicalNot struct {
+	x Expression
+}
+
+func (e *logicalNot) Evaluate(event cloudevents.Event) (interface{}, error) {
+	x, err := evaluateBool(e.x, event)
+	if err != nil {
+		return nil, err
+	}
+	return !x, nil
+}
+
+type negate struct {
+	x Expression
+}
+
+func (e *negate) Evaluate(event cloudevents.Event) (interface{}, error) {
+	x, err := evaluateInt(e.x, event)
+	if err != nil {
+		return nil, err
+	}
+	return -x, nil
+}
+
+type binary struct {
+	op   string
+	x, y Expression
+}
+
+func (e *binary) Evaluate(event cloudevents.Event) (interface{}, error) {
+	switch e.op {
+	case "AND", "OR", "XOR":
+		x, err := evaluateBool(e.x, event)
+		if err != nil {
+			return nil, err
+		}
+		if (e.op == "AND" && !x) || (e.op == "OR" && x) {
+			return x, nil
+		}
+		y, err := evaluateBool(e.y, event)
+		if err != nil {
+			return nil, err
+		}
+		if e.op == "XOR" {
+			return x != y, nil
+		}
+		return y, nil
+
+	case "=", "!=", "<>":
+		x, err := e.x.Evaluate(event)
+		if err != nil {
+			return nil, err
+		}
+		y, err := e.y.Evaluate(event)
+		if err != nil {
+			return nil, err
+		}
+		return equal(x, y) == (e.op == "="), nil
+
+	case "<", "<=", ">", ">=":
+		x, err := e.x.Evaluate(event)
+		if err != nil {
+			return nil, err
+		}
+		y, err := e.y.Evaluate(event)
+		if err != nil {
+			return nil, err
+		}
+		return compare(e.op, x, y)
+	}
+
+	x, err := evaluateInt(e.x, event)
+	if err != nil {
+		return nil, err
+	}
+	y, err := evaluateInt(e.y, event)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/":
+		if y == 0 {
+			return nil, errDivisionByZero
+		}
+		return x / y, nil
+	case "%":
+		if y == 0 {
+			return nil, errDivisionByZero
+		}
+		return x % y, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", e.op)
+}
+
+type like struct {
+	x       Expression
+	pattern *regexp.Regexp
+	not     bool
+}
+
+func (e *like) Evaluate(event cloudevents.Event) (interface{}, error) {
+	x, err := e.x.Evaluate(event)
+	if err != nil {
+		return nil, err
+	}
+	return e.pattern.MatchString(toString(x)) != e.not, nil
+}
+
+type in struct {
+	x   Expression
+	set []Expression
+	not bool
+}
+
+func (e *in) Evaluate(event cloudevents.Event) (interface{}, error) {
+	x, err := e.x.Evaluate(event)
+	if err != nil {
+		return nil, err
+	}
+	for _, element := range e.set {
+		y, err := element.Evaluate(event)
+		if err != nil {
+			return nil, err
+		}
+		if equal(x, y) {
+			return !e.not, nil
+		}
+	}
+	return e.not, nil
+}
+
+type call struct {
+	name string
+	fn   function
+	args []Expression
+}
+
+func (e *call) Evaluate(event cloudevents.Event) (interface{}, error) {
+	args := make([]interface{}, 0, len(e.args))
+	for _, arg := range e.args {
+		v, err := arg.Evaluate(event)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	v, err := e.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.name, err)
+	}
+	return v, nil
+}
+
+// equal compares x and y, casting the string one to the type of the other
+// when their types differ. The values failing to be cast aren't equal.
+func equal(x, y interface{}) bool {
+	if xs, ok := x.(string); ok {
+		if _, ok := y.(string); !ok {
+			cast, err := castLike(xs, y)
+			return err == nil && cast == y
+		}
+	}
+	if ys, ok := y.(string); ok {
+		if _, ok := x.(string); !ok {
+			cast, err := castLike(ys, x)
+			return err == nil && cast == x
+		}
+	}
+	return x == y
+}
+
+// compare compares the strings lexically and the other values as integers.
+func compare(op string, x, y interface{}) (bool, error) {
+	var c int
+	xs, xok := x.(string)
+	ys, yok := y.(string)
+	if xok && yok {
+		c = strings.Compare(xs, ys)
+	} else {
+		xi, err := toInt(x)
+		if err != nil {
+			return false, err
+		}
+		yi, err := toInt(y)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case xi < yi:
+			c = -1
+		case xi > yi:
+			c = 1
+		}
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+// castLike casts s to the type of v.
+func castLike(s string, v interface{}) (interface{}, error) {
+	switch v.(type) {
+	case bool:
+		return toBool(s)
+	case int32:
+		return toInt(s)
+	}
+	return s, nil
+}
+
+func evaluateBool(e Expression, event cloudevents.Event) (bool, error) {
+	v, err := e.Evaluate(event)
+	if err != nil {
+		return false, err
+	}
+	return toBool(v)
+}
+
+func evaluateInt(e Expression, event cloudevents.Event) (int32, error) {
+	v, err := e.Evaluate(event)
+	if err != nil {
+		return 0, err
+	}
+	return toInt(v)
+}
+
+// toBool casts v to a Boolean, the strings true and false being cast
+// regardless of their case.
+func toBool(v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%v can't be cast to a boolean", v)
+}
+
+func toInt(v interface{}) (int32, error) {
+	switch v := v.(type) {
+	case int32:
+		return v, nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 32)
+		if err == nil {
+			return int32(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%v can't be cast to an integer", v)
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestEvaluate(t *testing.T) {
+	event := cloudevents.NewEvent()
+	event.SetType("com.example.someevent")
+	event.SetSource("/mycontext")
+	event.SetID("1234")
+	event.SetExtension("count", 42)
+	event.SetExtension("enabled", true)
+	event.SetExtension("region", "eu-west-1")
+
+	tests := map[string]struct {
+		expression string
+		want       interface{}
+		wantErr    bool
+	}{
+		"equal":                   {expression: "type = 'com.example.someevent'", want: true},
+		"not equal":               {expression: "source <> '/mycontext'", want: false},
+		"double quotes":           {expression: `type != "com.example.other"`, want: true},
+		"escaped quote":           {expression: `'it\'s' = "it's"`, want: true},
+		"keywords case":           {expression: "type = 'a' or Not (id = '1') OR true", want: true},
+		"integer extension":       {expression: "count > 40 AND count <= 42", want: true},
+		"string cast to integer":  {expression: "id = 1234", want: true},
+		"string cast to boolean":  {expression: "'TRUE' = enabled", want: true},
+		"failing cast":            {expression: "type = 1", want: false},
+		"string comparison":       {expression: "region < 'eu-west-2'", want: true},
+		"arithmetic":              {expression: "(count + 8) * 2 / 10 % 7 - -1", want: int32(4)},
+		"precedence":              {expression: "1 + 2 * 3", want: int32(7)},
+		"minimum integer":         {expression: "-2147483648", want: int32(-2147483648)},
+		"xor":                     {expression: "true XOR enabled", want: false},
+		"and before or":           {expression: "true OR true AND false", want: true},
+		"and before xor":          {expression: "true XOR true AND false", want: true},
+		"xor before or":           {expression: "true OR false XOR true", want: true},
+		"logic left associative":  {expression: "true XOR true XOR true", want: true},
+		"comparisons and logic":   {expression: "count > 40 OR count < 0 AND NOT enabled", want: true},
+		"like":                    {expression: "type LIKE 'com.example.%'", want: true},
+		"like any character":      {expression: "region LIKE 'eu-_est-1'", want: true},
+		"not like":                {expression: "type NOT LIKE '%event'", want: false},
+		"like escaped wildcard":   {expression: `'100%' LIKE '100\%' AND '1000' NOT LIKE '100\%'`, want: true},
+		"in":                      {expression: "region IN ('us-east-1', 'eu-west-1')", want: true},
+		"not in":                  {expression: "count NOT IN (1, 2, '42')", want: false},
+		"exists":                  {expression: "EXISTS region AND NOT EXISTS subject", want: true},
+		"functions":               {expression: "UPPER(LEFT(region, 2)) = 'EU' AND LENGTH(CONCAT(id, 'x')) = 5", want: true},
+		"concat_ws":               {expression: "CONCAT_WS('/', 'a', id)", want: "a/1234"},
+		"substring":               {expression: "SUBSTRING(region, 4, 4) = 'west' AND SUBSTRING(region, -1) = '1'", want: true},
+		"right and trim":          {expression: "RIGHT(TRIM('  abc  '), 2)", want: "bc"},
+		"casts":                   {expression: "INT('12') + ABS(-3) = 15 AND BOOL('false') = false AND STRING(count) = '42'", want: true},
+		"type checks":             {expression: "IS_INT(id) AND NOT IS_BOOL(id)", want: true},
+		"short circuit":           {expression: "false AND missing = 'x'", want: false},
+		"missing attribute":       {expression: "missing = 'x'", wantErr: true},
+		"non boolean logic":       {expression: "type AND true", wantErr: true},
+		"division by zero":        {expression: "count / 0", wantErr: true},
+		"negative length":         {expression: "LEFT(region, -1)", wantErr: true},
+		"integer cast fails":      {expression: "type + 1", wantErr: true},
+		"comparison of booleans":  {expression: "enabled > false", wantErr: true},
+		"nested functions errors": {expression: "LENGTH(SUBSTRING(region, 100))", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatal("Unexpected parse error:", err)
+			}
+			got, err := e.Evaluate(event)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"type =",
+		"type = 'unterminated",
+		"Type = 'a'",
+		"type = 'a' extra",
+		"(type = 'a'",
+		"type LIKE region",
+		"type IN ()",
+		"EXISTS 'type'",
+		"UNKNOWN(type)",
+		"LENGTH(type, id)",
+		"SUBSTRING(type)",
+		"2147483648",
+		"type = 'a' ; id = 'b'",
+	} {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := Parse(expression); err == nil {
+				t.Errorf("Expected %q to fail to parse", expression)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cesql
+
+import (
+	"errors"
+	"strings"
+)
+
+// function is a built-in function. maxArgs is negative for the variadic
+// ones.
+type function struct {
+	minArgs, maxArgs int
+	call             func(args []interface{}) (interface{}, error)
+}
+
+var errNegativeLength = errors.New("negative length")
+
+// functions are the built-in functions, by name.
+var functions = map[string]function{
+	"LENGTH": {1, 1, func(args []interface{}) (interface{}, error) {
+		return int32(len([]rune(toString(args[0])))), nil
+	}},
+	"CONCAT": {0, -1, func(args []interface{}) (interface{}, error) {
+		return strings.Join(toStrings(args), ""), nil
+	}},
+	"CONCAT_WS": {1, -1, func(args []interface{}) (interface{}, error) {
+		return strings.Join(toStrings(args[1:]), toString(args[0])), nil
+	}},
+	"LOWER": {1, 1, func(args []interface{}) (interface{}, error) {
+		return strings.ToLower(toString(args[0])), nil
+	}},
+	"UPPER": {1, 1, func(args []interface{}) (interface{}, error) {
+		return strings.ToUpper(toString(args[0])), nil
+	}},
+	"TRIM": {1, 1, func(args []interface{}) (interface{}, error) {
+		return strings.TrimSpace(toString(args[0])), nil
+	}},
+	"LEFT": {2, 2, func(args []interface{}) (interface{}, error) {
+		s := []rune(toString(args[0]))
+		n, err := toInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errNegativeLength
+		}
+		if int(n) < len(s) {
+			s = s[:n]
+		}
+		return string(s), nil
+	}},
+	"RIGHT": {2, 2, func(args []interface{}) (interface{}, error) {
+		s := []rune(toString(args[0]))
+		n, err := toInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errNegativeLength
+		}
+		if int(n) < len(s) {
+			s = s[len(s)-int(n):]
+		}
+		return string(s), nil
+	}},
+	// SUBSTRING returns the characters from the position, counted from 1,
+	// or from the end when negative, up to the length when given.
+	"SUBSTRING": {2, 3, func(args []interface{}) (interface{}, error) {
+		s := []rune(toString(args[0]))
+		pos, err := toInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		start := int(pos) - 1
+		if pos < 0 {
+			start = len(s) + int(pos)
+		}
+		if start < 0 || start > len(s) {
+			return nil, errors.New("position out of range")
+		}
+		s = s[start:]
+		if len(args) == 3 {
+			n, err := toInt(args[2])
+			if err != nil {
+				return nil, err
+			}
+			if n < 0 {
+				return nil, errNegativeLength
+			}
+			if int(n) < len(s) {
+				s = s[:n]
+			}
+		}
+		return string(s), nil
+	}},
+	"ABS": {1, 1, func(args []interface{}) (interface{}, error) {
+		i, err := toInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return -i, nil
+		}
+		return i, nil
+	}},
+	"INT": {1, 1, func(args []interface{}) (interface{}, error) {
+		return toInt(args[0])
+	}},
+	"BOOL": {1, 1, func(args []interface{}) (interface{}, error) {
+		return toBool(args[0])
+	}},
+	"STRING": {1, 1, func(args []interface{}) (interface{}, error) {
+		return toString(args[0]), nil
+	}},
+	"IS_INT": {1, 1, func(args []interface{}) (interface{}, error) {
+		_, err := toInt(args[0])
+		return err == nil, nil
+	}},
+	"IS_BOOL": {1, 1, func(args []interface{}) (interface{}, error) {
+		_, err := toBool(args[0])
+		return err == nil, nil
+	}},
+}
+
+func toStrings(args []interface{}) []string {
+	s := make([]string, 0, len(args))
+	for _, arg := range args {
+		s = append(s, toString(arg))
+	}
+	return s
+}
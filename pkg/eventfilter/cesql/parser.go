@@ -0,0 +1,442 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cesql parses and evaluates CloudEvents SQL expressions, see
+// https://github.com/cloudevents/spec/blob/master/cesql/spec.md
+package cesql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenInteger
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	// text is the identifier, the unquoted string, the digits of the
+	// integer or the symbol.
+	text string
+	pos  int
+}
+
+// symbols are the operators and punctuation, the longest first.
+var symbols = []string{"!=", "<>", "<=", ">=", "(", ")", ",", "=", "<", ">", "+", "-", "*", "/", "%"}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expression); {
+		c := rune(expression[i])
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(expression) && rune(expression[j]) != c; j++ {
+				// Only the quotes are unescaped, the backslashes escaping
+				// the LIKE wildcards being kept.
+				if expression[j] == '\\' && j+1 < len(expression) && rune(expression[j+1]) == c {
+					j++
+				}
+				sb.WriteByte(expression[j])
+			}
+			if j == len(expression) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: i})
+			i = j + 1
+		case isDigit(c):
+			j := i
+			for j < len(expression) && isDigit(rune(expression[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenInteger, text: expression[i:j], pos: i})
+			i = j
+		case isLetter(c):
+			j := i
+			for j < len(expression) && (isLetter(rune(expression[j])) || isDigit(rune(expression[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: expression[i:j], pos: i})
+			i = j
+		default:
+			symbol := ""
+			for _, s := range symbols {
+				if strings.HasPrefix(expression[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, pos: i})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expression)}), nil
+}
+
+func isLetter(c rune) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+// validAttributeName is the form of the attribute names, like the Trigger
+// filters ones.
+var validAttributeName = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// Parse parses the CloudEvents SQL expression.
+func Parse(expression string) (Expression, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.logic()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return e, nil
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) pop() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+// keyword tells whether the next token is the keyword, case-insensitive.
+func (p *parser) keyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdentifier && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) symbol(symbols ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenSymbol {
+		return "", false
+	}
+	for _, s := range symbols {
+		if t.text == s {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(symbol string) error {
+	if _, ok := p.symbol(symbol); !ok {
+		t := p.peek()
+		return fmt.Errorf("expected %q at %d", symbol, t.pos)
+	}
+	p.pop()
+	return nil
+}
+
+// logic parses the logical operations, AND binding tighter than XOR and XOR
+// tighter than OR, like in the CESQL spec.
+func (p *parser) logic() (Expression, error) {
+	return p.logical("OR", func() (Expression, error) {
+		return p.logical("XOR", func() (Expression, error) {
+			return p.logical("AND", p.comparison)
+		})
+	})
+}
+
+// logical parses the left-associative operations of the logical operator,
+// whose operands are parsed by operand.
+func (p *parser) logical(op string, operand func() (Expression, error)) (Expression, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword(op) {
+		p.pop()
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op: op, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) comparison() (Expression, error) {
+	x, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.symbol("=", "!=", "<>", "<", "<=", ">", ">=")
+		if !ok {
+			return x, nil
+		}
+		p.pop()
+		y, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op: op, x: x, y: y}
+	}
+}
+
+func (p *parser) additive() (Expression, error) {
+	x, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.symbol("+", "-")
+		if !ok {
+			return x, nil
+		}
+		p.pop()
+		y, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op: op, x: x, y: y}
+	}
+}
+
+func (p *parser) multiplicative() (Expression, error) {
+	x, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.symbol("*", "/", "%")
+		if !ok {
+			return x, nil
+		}
+		p.pop()
+		y, err := p.postfix()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op: op, x: x, y: y}
+	}
+}
+
+// postfix parses the LIKE and IN operations, binding tighter than the
+// arithmetic ones.
+func (p *parser) postfix() (Expression, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		not := false
+		if p.keyword("NOT") && p.next+1 < len(p.tokens) {
+			after := p.tokens[p.next+1]
+			if after.kind != tokenIdentifier || !(strings.EqualFold(after.text, "LIKE") || strings.EqualFold(after.text, "IN")) {
+				return x, nil
+			}
+			p.pop()
+			not = true
+		}
+		switch {
+		case p.keyword("LIKE"):
+			p.pop()
+			t := p.pop()
+			if t.kind != tokenString {
+				return nil, fmt.Errorf("expected a string pattern at %d", t.pos)
+			}
+			x = &like{x: x, pattern: likePattern(t.text), not: not}
+		case p.keyword("IN"):
+			p.pop()
+			set, err := p.arguments()
+			if err != nil {
+				return nil, err
+			}
+			if len(set) == 0 {
+				return nil, fmt.Errorf("empty set at %d", p.peek().pos)
+			}
+			x = &in{x: x, set: set, not: not}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) unary() (Expression, error) {
+	switch {
+	case p.keyword("NOT"):
+		p.pop()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &logicalNot{x: x}, nil
+	case p.keyword("EXISTS"):
+		p.pop()
+		t := p.pop()
+		if t.kind != tokenIdentifier || !validAttributeName.MatchString(t.text) {
+			return nil, fmt.Errorf("expected an attribute name at %d", t.pos)
+		}
+		return &exists{name: t.text}, nil
+	}
+	if _, ok := p.symbol("-"); ok {
+		p.pop()
+		// The minimum integer isn't a negated integer.
+		if t := p.peek(); t.kind == tokenInteger {
+			p.pop()
+			return integer("-"+t.text, t.pos)
+		}
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &negate{x: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (Expression, error) {
+	t := p.pop()
+	switch t.kind {
+	case tokenString:
+		return literal{value: t.text}, nil
+	case tokenInteger:
+		return integer(t.text, t.pos)
+	case tokenSymbol:
+		if t.text == "(" {
+			x, err := p.logic()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	case tokenIdentifier:
+		if _, ok := p.symbol("("); ok {
+			return p.call(t)
+		}
+		switch {
+		case strings.EqualFold(t.text, "TRUE"):
+			return literal{value: true}, nil
+		case strings.EqualFold(t.text, "FALSE"):
+			return literal{value: false}, nil
+		case validAttributeName.MatchString(t.text):
+			return attribute{name: t.text}, nil
+		}
+		return nil, fmt.Errorf("invalid attribute name %q at %d, attribute names are lowercase alphanumeric", t.text, t.pos)
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *parser) call(name token) (Expression, error) {
+	fn, ok := functions[strings.ToUpper(name.text)]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at %d", name.text, name.pos)
+	}
+	args, err := p.arguments()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("wrong number of arguments of %s at %d, got %d", strings.ToUpper(name.text), name.pos, len(args))
+	}
+	return &call{name: strings.ToUpper(name.text), fn: fn, args: args}, nil
+}
+
+// arguments parses a parenthesized list of expressions.
+func (p *parser) arguments() ([]Expression, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []Expression
+	if _, ok := p.symbol(")"); ok {
+		p.pop()
+		return args, nil
+	}
+	for {
+		arg, err := p.logic()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if _, ok := p.symbol(","); !ok {
+			break
+		}
+		p.pop()
+	}
+	return args, p.expect(")")
+}
+
+func integer(text string, pos int) (Expression, error) {
+	i, err := strconv.ParseInt(text, 10, 32)
+	if err != nil || i < math.MinInt32 || i > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid integer %s at %d", text, pos)
+	}
+	return literal{value: int32(i)}, nil
+}
+
+// likePattern returns the regular expression of the LIKE pattern, where %
+// matches any sequence of characters, _ any character and \ escapes them.
+func likePattern(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '%':
+			sb.WriteString(".*")
+		case c == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
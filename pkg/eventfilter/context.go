@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventfilter
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// ContextAttribute returns the value of the context attribute or extension
+// name of event, false when event doesn't have it. The Boolean and Integer
+// values are returned as bool and int32, the others in their canonical
+// string form.
+func ContextAttribute(event cloudevents.Event, name string) (interface{}, bool) {
+	var value interface{}
+	switch name {
+	case "specversion":
+		value = event.SpecVersion()
+	case "id":
+		value = event.ID()
+	case "source":
+		value = event.Source()
+	case "type":
+		value = event.Type()
+	case "subject":
+		value = event.Subject()
+	case "time":
+		if t := event.Time(); !t.IsZero() {
+			value = types.Timestamp{Time: t}
+		}
+	case "dataschema":
+		value = event.DataSchema()
+	case "datacontenttype":
+		value = event.DataContentType()
+	default:
+		value = event.Extensions()[name]
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return v, v != ""
+	case bool, int32:
+		return v, true
+	default:
+		s, err := types.Format(v)
+		if err != nil {
+			return nil, false
+		}
+		return s, true
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package subscriptionsapi implements the filter dialects of the
+// CloudEvents Subscriptions API, see
+// https://github.com/cloudevents/spec/blob/master/subscriptions-api.md
+package subscriptionsapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/cesql"
+)
+
+// NewFilter returns an event filter of the dialect set by f, failing when
+// its CloudEvents SQL expressions don't parse. The filters without any
+// dialect return NoFilter.
+func NewFilter(f eventingv1beta1.SubscriptionsAPIFilter) (eventfilter.Filter, error) {
+	switch {
+	case len(f.All) > 0:
+		filters, err := newFilters(f.All)
+		if err != nil {
+			return nil, err
+		}
+		return eventfilter.Filters(filters), nil
+	case len(f.Any) > 0:
+		filters, err := newFilters(f.Any)
+		if err != nil {
+			return nil, err
+		}
+		return anyFilter(filters), nil
+	case f.Not != nil:
+		filter, err := NewFilter(*f.Not)
+		if err != nil {
+			return nil, err
+		}
+		return notFilter{filter}, nil
+	case len(f.Exact) > 0:
+		return newAttributeFilter(f.Exact, func(value, filter string) bool { return value == filter }), nil
+	case len(f.Prefix) > 0:
+		return newAttributeFilter(f.Prefix, strings.HasPrefix), nil
+	case len(f.Suffix) > 0:
+		return newAttributeFilter(f.Suffix, strings.HasSuffix), nil
+	case f.CESQL != "":
+		expression, err := cesql.Parse(f.CESQL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CESQL expression %q: %w", f.CESQL, err)
+		}
+		return cesqlFilter{expression}, nil
+	}
+	return eventfilter.Filters(nil), nil
+}
+
+// NewFilters returns an event filter passing the events passing all the
+// filters.
+func NewFilters(filters []eventingv1beta1.SubscriptionsAPIFilter) (eventfilter.Filter, error) {
+	fs, err := newFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	return eventfilter.Filters(fs), nil
+}
+
+func newFilters(filters []eventingv1beta1.SubscriptionsAPIFilter) ([]eventfilter.Filter, error) {
+	fs := make([]eventfilter.Filter, 0, len(filters))
+	for _, f := range filters {
+		filter, err := NewFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, filter)
+	}
+	return fs, nil
+}
+
+type anyFilter []eventfilter.Filter
+
+func (filters anyFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	res := eventfilter.NoFilter
+	for _, f := range filters {
+		switch f.Filter(ctx, event) {
+		case eventfilter.PassFilter:
+			return eventfilter.PassFilter
+		case eventfilter.FailFilter:
+			res = eventfilter.FailFilter
+		}
+	}
+	return res
+}
+
+type notFilter struct {
+	filter eventfilter.Filter
+}
+
+func (f notFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	if f.filter.Filter(ctx, event) == eventfilter.FailFilter {
+		return eventfilter.PassFilter
+	}
+	return eventfilter.FailFilter
+}
+
+type attributeFilter struct {
+	name, value string
+	match       func(value, filter string) bool
+}
+
+func newAttributeFilter(attrs map[string]string, match func(value, filter string) bool) eventfilter.Filter {
+	filters := make(eventfilter.Filters, 0, len(attrs))
+	for name, value := range attrs {
+		filters = append(filters, attributeFilter{name: name, value: value, match: match})
+	}
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return filters
+}
+
+func (f attributeFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	value, ok := eventfilter.ContextAttribute(event, f.name)
+	if !ok {
+		logging.FromContext(ctx).Debug("Attribute not found", zap.String("attribute", f.name))
+		return eventfilter.FailFilter
+	}
+	if !f.match(stringValue(value), f.value) {
+		logging.FromContext(ctx).Debug("Attribute had non-matching value", zap.String("attribute", f.name), zap.String("filter", f.value), zap.Any("received", value))
+		return eventfilter.FailFilter
+	}
+	return eventfilter.PassFilter
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return fmt.Sprint(value)
+}
+
+type cesqlFilter struct {
+	expression cesql.Expression
+}
+
+func (f cesqlFilter) Filter(ctx context.Context, event cloudevents.Event) eventfilter.FilterResult {
+	value, err := f.expression.Evaluate(event)
+	if err != nil {
+		logging.FromContext(ctx).Debug("Failed to evaluate the CESQL expression", zap.Error(err))
+		return eventfilter.FailFilter
+	}
+	if pass, ok := value.(bool); ok && pass {
+		return eventfilter.PassFilter
+	}
+	return eventfilter.FailFilter
+}
+
+var (
+	_ eventfilter.Filter = anyFilter{}
+	_ eventfilter.Filter = notFilter{}
+	_ eventfilter.Filter = attributeFilter{}
+	_ eventfilter.Filter = cesqlFilter{}
+)
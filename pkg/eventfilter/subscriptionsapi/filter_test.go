@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subscriptionsapi
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+func TestFilter(t *testing.T) {
+	event := cloudevents.NewEvent()
+	event.SetType("com.example.someevent")
+	event.SetSource("/mycontext/file.txt")
+	event.SetID("1234")
+	event.SetExtension("count", 42)
+
+	tests := map[string]struct {
+		filter eventingv1beta1.SubscriptionsAPIFilter
+		want   eventfilter.FilterResult
+	}{
+		"empty": {
+			want: eventfilter.NoFilter,
+		},
+		"exact": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Exact: map[string]string{"type": "com.example.someevent"}},
+			want:   eventfilter.PassFilter,
+		},
+		"exact not matching": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Exact: map[string]string{"type": "com.example"}},
+			want:   eventfilter.FailFilter,
+		},
+		"exact integer extension": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Exact: map[string]string{"count": "42"}},
+			want:   eventfilter.PassFilter,
+		},
+		"missing attribute": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Exact: map[string]string{"subject": ""}},
+			want:   eventfilter.FailFilter,
+		},
+		"prefix": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Prefix: map[string]string{"source": "/mycontext/"}},
+			want:   eventfilter.PassFilter,
+		},
+		"suffix": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Suffix: map[string]string{"source": ".json"}},
+			want:   eventfilter.FailFilter,
+		},
+		"all": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{All: []eventingv1beta1.SubscriptionsAPIFilter{
+				{Prefix: map[string]string{"type": "com.example."}},
+				{Suffix: map[string]string{"source": ".txt"}},
+			}},
+			want: eventfilter.PassFilter,
+		},
+		"all failing": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{All: []eventingv1beta1.SubscriptionsAPIFilter{
+				{Prefix: map[string]string{"type": "com.example."}},
+				{Suffix: map[string]string{"source": ".json"}},
+			}},
+			want: eventfilter.FailFilter,
+		},
+		"any": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Any: []eventingv1beta1.SubscriptionsAPIFilter{
+				{Exact: map[string]string{"id": "5678"}},
+				{Exact: map[string]string{"id": "1234"}},
+			}},
+			want: eventfilter.PassFilter,
+		},
+		"any failing": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Any: []eventingv1beta1.SubscriptionsAPIFilter{
+				{Exact: map[string]string{"id": "5678"}},
+				{Exact: map[string]string{"subject": "1234"}},
+			}},
+			want: eventfilter.FailFilter,
+		},
+		"not": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{Not: &eventingv1beta1.SubscriptionsAPIFilter{
+				Exact: map[string]string{"id": "5678"},
+			}},
+			want: eventfilter.PassFilter,
+		},
+		"cesql": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{CESQL: "type LIKE 'com.example.%' AND count > 40"},
+			want:   eventfilter.PassFilter,
+		},
+		"cesql not boolean": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{CESQL: "count + 1"},
+			want:   eventfilter.FailFilter,
+		},
+		"cesql failing evaluation": {
+			filter: eventingv1beta1.SubscriptionsAPIFilter{CESQL: "subject = 'a'"},
+			want:   eventfilter.FailFilter,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewFilter(tt.filter)
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if got := f.Filter(context.TODO(), event); got != tt.want {
+				t.Errorf("Filter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFilterInvalidExpression(t *testing.T) {
+	_, err := NewFilters([]eventingv1beta1.SubscriptionsAPIFilter{{
+		Any: []eventingv1beta1.SubscriptionsAPIFilter{{CESQL: "type ="}},
+	}})
+	if err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,515 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kncloudevents
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// avroSchema is a parsed Avro schema, see
+// https://avro.apache.org/docs/current/spec.html
+type avroSchema struct {
+	// kind is the primitive type name, or record, enum, array, map, fixed
+	// or union.
+	kind string
+	// name is the full name of the named types.
+	name string
+
+	fields   []avroField
+	symbols  []string
+	items    *avroSchema
+	values   *avroSchema
+	branches []*avroSchema
+	size     int
+}
+
+type avroField struct {
+	name string
+	typ  *avroSchema
+	// defaultValue is the JSON default, nil when there is none.
+	defaultValue json.RawMessage
+}
+
+var errAvroTruncated = errors.New("truncated avro data")
+
+var avroPrimitives = map[string]bool{
+	"null": true, "boolean": true, "int": true, "long": true,
+	"float": true, "double": true, "bytes": true, "string": true,
+}
+
+// parseAvroSchema parses the JSON form of an Avro schema.
+func parseAvroSchema(schema []byte) (*avroSchema, error) {
+	var v interface{}
+	if err := json.Unmarshal(schema, &v); err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+	p := avroSchemaParser{named: make(map[string]*avroSchema)}
+	return p.parse(v, "")
+}
+
+type avroSchemaParser struct {
+	// named are the named types, by full name.
+	named map[string]*avroSchema
+}
+
+func (p *avroSchemaParser) parse(v interface{}, namespace string) (*avroSchema, error) {
+	switch v := v.(type) {
+	case string:
+		if avroPrimitives[v] {
+			return &avroSchema{kind: v}, nil
+		}
+		if s, ok := p.named[fullName(v, namespace)]; ok {
+			return s, nil
+		}
+		if s, ok := p.named[v]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unknown avro type %q", v)
+
+	case []interface{}:
+		s := &avroSchema{kind: "union"}
+		for _, branch := range v {
+			b, err := p.parse(branch, namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.branches = append(s.branches, b)
+		}
+		return s, nil
+
+	case map[string]interface{}:
+		typ, _ := v["type"].(string)
+		switch typ {
+		case "record", "error", "enum", "fixed":
+			name, _ := v["name"].(string)
+			if name == "" {
+				return nil, fmt.Errorf("avro %s without name", typ)
+			}
+			if ns, ok := v["namespace"].(string); ok && !strings.Contains(name, ".") {
+				namespace = ns
+			}
+			s := &avroSchema{kind: typ, name: fullName(name, namespace)}
+			if typ == "error" {
+				s.kind = "record"
+			}
+			if i := strings.LastIndex(s.name, "."); i >= 0 {
+				namespace = s.name[:i]
+			}
+			// Registered before the fields, which may refer to it.
+			p.named[s.name] = s
+			return s, p.parseNamed(s, v, namespace)
+		case "array":
+			items, err := p.parse(v["items"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			return &avroSchema{kind: "array", items: items}, nil
+		case "map":
+			values, err := p.parse(v["values"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			return &avroSchema{kind: "map", values: values}, nil
+		}
+		// The primitive types may be wrapped, with a logical type.
+		return p.parse(v["type"], namespace)
+	}
+	return nil, fmt.Errorf("invalid avro schema %v", v)
+}
+
+func (p *avroSchemaParser) parseNamed(s *avroSchema, v map[string]interface{}, namespace string) error {
+	switch s.kind {
+	case "record":
+		fields, _ := v["fields"].([]interface{})
+		for _, f := range fields {
+			f, ok := f.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid field of avro record %s", s.name)
+			}
+			name, _ := f["name"].(string)
+			typ, err := p.parse(f["type"], namespace)
+			if err != nil {
+				return fmt.Errorf("field %s of avro record %s: %w", name, s.name, err)
+			}
+			field := avroField{name: name, typ: typ}
+			if d, ok := f["default"]; ok {
+				field.defaultValue, _ = json.Marshal(d)
+			}
+			s.fields = append(s.fields, field)
+		}
+	case "enum":
+		symbols, _ := v["symbols"].([]interface{})
+		for _, symbol := range symbols {
+			symbol, _ := symbol.(string)
+			s.symbols = append(s.symbols, symbol)
+		}
+	case "fixed":
+		size, ok := v["size"].(float64)
+		if !ok || size < 0 {
+			return fmt.Errorf("invalid size of avro fixed %s", s.name)
+		}
+		s.size = int(size)
+	}
+	return nil
+}
+
+func fullName(name, namespace string) string {
+	if namespace == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// jsonToAvro encodes the JSON document in the Avro binary encoding. The
+// unions are encoded as their first branch the value fits.
+func jsonToAvro(schema *avroSchema, data []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid json data: %w", err)
+	}
+	var b bytes.Buffer
+	if err := encodeAvro(&b, schema, v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func encodeAvro(b *bytes.Buffer, s *avroSchema, v interface{}) error {
+	switch s.kind {
+	case "null":
+		if v != nil {
+			return fmt.Errorf("expected null, got %v", v)
+		}
+	case "boolean":
+		x, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("expected a boolean, got %v", v)
+		}
+		if x {
+			b.WriteByte(1)
+		} else {
+			b.WriteByte(0)
+		}
+	case "int", "long":
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected an integer, got %v", v)
+		}
+		i, err := n.Int64()
+		if err != nil || (s.kind == "int" && (i < math.MinInt32 || i > math.MaxInt32)) {
+			return fmt.Errorf("expected an %s, got %v", s.kind, v)
+		}
+		writeAvroLong(b, i)
+	case "float", "double":
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected a number, got %v", v)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return fmt.Errorf("expected a number, got %v", v)
+		}
+		if s.kind == "float" {
+			var buf [4]byte
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(float32(f)))
+			b.Write(buf[:])
+		} else {
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+			b.Write(buf[:])
+		}
+	case "bytes", "string":
+		x, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %v", v)
+		}
+		writeAvroLong(b, int64(len(x)))
+		b.WriteString(x)
+	case "fixed":
+		x, ok := v.(string)
+		if !ok || len(x) != s.size {
+			return fmt.Errorf("expected %d bytes of %s, got %v", s.size, s.name, v)
+		}
+		b.WriteString(x)
+	case "enum":
+		x, _ := v.(string)
+		for i, symbol := range s.symbols {
+			if symbol == x {
+				writeAvroLong(b, int64(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("expected a symbol of %s, got %v", s.name, v)
+	case "record":
+		x, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected a %s record, got %v", s.name, v)
+		}
+		for _, f := range s.fields {
+			fv, ok := x[f.name]
+			if !ok {
+				if f.defaultValue == nil {
+					return fmt.Errorf("missing field %s of %s", f.name, s.name)
+				}
+				d := json.NewDecoder(bytes.NewReader(f.defaultValue))
+				d.UseNumber()
+				if err := d.Decode(&fv); err != nil {
+					return err
+				}
+			}
+			if err := encodeAvro(b, f.typ, fv); err != nil {
+				return fmt.Errorf("%s.%s: %w", s.name, f.name, err)
+			}
+		}
+	case "array":
+		x, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected an array, got %v", v)
+		}
+		if len(x) > 0 {
+			writeAvroLong(b, int64(len(x)))
+			for _, item := range x {
+				if err := encodeAvro(b, s.items, item); err != nil {
+					return err
+				}
+			}
+		}
+		writeAvroLong(b, 0)
+	case "map":
+		x, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected a map, got %v", v)
+		}
+		if len(x) > 0 {
+			writeAvroLong(b, int64(len(x)))
+			for key, value := range x {
+				writeAvroLong(b, int64(len(key)))
+				b.WriteString(key)
+				if err := encodeAvro(b, s.values, value); err != nil {
+					return err
+				}
+			}
+		}
+		writeAvroLong(b, 0)
+	case "union":
+		for i, branch := range s.branches {
+			var bb bytes.Buffer
+			if err := encodeAvro(&bb, branch, v); err == nil {
+				writeAvroLong(b, int64(i))
+				b.Write(bb.Bytes())
+				return nil
+			}
+		}
+		return fmt.Errorf("no branch of the union fits %v", v)
+	default:
+		return fmt.Errorf("unsupported avro type %s", s.kind)
+	}
+	return nil
+}
+
+func writeAvroLong(b *bytes.Buffer, i int64) {
+	var buf [binary.MaxVarintLen64]byte
+	b.Write(buf[:binary.PutVarint(buf[:], i)])
+}
+
+// avroToJSON decodes the Avro binary data to a JSON document, the unions
+// being written as their value.
+func avroToJSON(schema *avroSchema, data []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	var b bytes.Buffer
+	if err := decodeAvro(&b, schema, r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after the avro data", r.Len())
+	}
+	return b.Bytes(), nil
+}
+
+func decodeAvro(b *bytes.Buffer, s *avroSchema, r *bytes.Reader) error {
+	switch s.kind {
+	case "null":
+		b.WriteString("null")
+	case "boolean":
+		c, err := r.ReadByte()
+		if err != nil {
+			return errAvroTruncated
+		}
+		b.WriteString(strconv.FormatBool(c != 0))
+	case "int", "long":
+		i, err := readAvroLong(r)
+		if err != nil {
+			return err
+		}
+		b.WriteString(strconv.FormatInt(i, 10))
+	case "float", "double":
+		var f float64
+		if s.kind == "float" {
+			var buf [4]byte
+			if _, err := io.ReadFull(r, buf[:]); err != nil {
+				return errAvroTruncated
+			}
+			f = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[:])))
+		} else {
+			var buf [8]byte
+			if _, err := io.ReadFull(r, buf[:]); err != nil {
+				return errAvroTruncated
+			}
+			f = math.Float64frombits(binary.LittleEndian.Uint64(buf[:]))
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("%v can't be written in json", f)
+		}
+		b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	case "bytes", "string":
+		x, err := readAvroString(r)
+		if err != nil {
+			return err
+		}
+		writeJSONString(b, x)
+	case "fixed":
+		buf := make([]byte, s.size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return errAvroTruncated
+		}
+		writeJSONString(b, string(buf))
+	case "enum":
+		i, err := readAvroLong(r)
+		if err != nil {
+			return err
+		}
+		if i < 0 || i >= int64(len(s.symbols)) {
+			return fmt.Errorf("invalid symbol %d of %s", i, s.name)
+		}
+		writeJSONString(b, s.symbols[i])
+	case "record":
+		b.WriteByte('{')
+		for i, f := range s.fields {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(b, f.name)
+			b.WriteByte(':')
+			if err := decodeAvro(b, f.typ, r); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case "array", "map":
+		start, end := byte('['), byte(']')
+		if s.kind == "map" {
+			start, end = '{', '}'
+		}
+		b.WriteByte(start)
+		first := true
+		for {
+			n, err := readAvroBlockCount(r)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				break
+			}
+			for ; n > 0; n-- {
+				if !first {
+					b.WriteByte(',')
+				}
+				first = false
+				if s.kind == "map" {
+					key, err := readAvroString(r)
+					if err != nil {
+						return err
+					}
+					writeJSONString(b, key)
+					b.WriteByte(':')
+					err = decodeAvro(b, s.values, r)
+					if err != nil {
+						return err
+					}
+				} else if err := decodeAvro(b, s.items, r); err != nil {
+					return err
+				}
+			}
+		}
+		b.WriteByte(end)
+	case "union":
+		i, err := readAvroLong(r)
+		if err != nil {
+			return err
+		}
+		if i < 0 || i >= int64(len(s.branches)) {
+			return fmt.Errorf("invalid union branch %d", i)
+		}
+		return decodeAvro(b, s.branches[i], r)
+	default:
+		return fmt.Errorf("unsupported avro type %s", s.kind)
+	}
+	return nil
+}
+
+func readAvroLong(r *bytes.Reader) (int64, error) {
+	i, err := binary.ReadVarint(r)
+	if err != nil {
+		return 0, errAvroTruncated
+	}
+	return i, nil
+}
+
+func readAvroString(r *bytes.Reader) (string, error) {
+	n, err := readAvroLong(r)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || n > int64(r.Len()) {
+		return "", errAvroTruncated
+	}
+	buf := make([]byte, n)
+	_, _ = io.ReadFull(r, buf)
+	return string(buf), nil
+}
+
+// readAvroBlockCount reads the item count of a block of array or map, the
+// negative counts being followed by the size of the block.
+func readAvroBlockCount(r *bytes.Reader) (int64, error) {
+	n, err := readAvroLong(r)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		if _, err := readAvroLong(r); err != nil {
+			return 0, err
+		}
+		n = -n
+	}
+	return n, nil
+}
+
+func writeJSONString(b *bytes.Buffer, s string) {
+	// Marshalling strings can't fail.
+	j, _ := json.Marshal(s)
+	b.Write(j)
+}
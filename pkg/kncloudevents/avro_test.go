@@ -0,0 +1,121 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kncloudevents
+
+import (
+	"bytes"
+	"testing"
+)
+
+const orderSchema = `{
+	"type": "record",
+	"name": "Order",
+	"namespace": "com.example",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "item", "type": "string"},
+		{"name": "quantity", "type": "int", "default": 1},
+		{"name": "price", "type": "double"},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "SHIPPED"]}},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "attributes", "type": {"type": "map", "values": "boolean"}},
+		{"name": "note", "type": ["null", "string"], "default": null},
+		{"name": "parent", "type": ["null", "Order"], "default": null}
+	]
+}`
+
+func TestAvroRoundTrip(t *testing.T) {
+	schema, err := parseAvroSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatal("parseAvroSchema() =", err)
+	}
+
+	tests := map[string]struct {
+		in   string
+		want string
+	}{
+		"full": {
+			in:   `{"id":42,"item":"book","quantity":3,"price":9.5,"status":"SHIPPED","tags":["a","b"],"attributes":{"gift":true},"note":"fragile","parent":{"id":1,"item":"box","price":0,"status":"NEW","tags":[],"attributes":{}}}`,
+			want: `{"id":42,"item":"book","quantity":3,"price":9.5,"status":"SHIPPED","tags":["a","b"],"attributes":{"gift":true},"note":"fragile","parent":{"id":1,"item":"box","quantity":1,"price":0,"status":"NEW","tags":[],"attributes":{},"note":null,"parent":null}}`,
+		},
+		"defaults": {
+			in:   `{"price":1.25,"item":"pen","id":-7,"status":"NEW","tags":[],"attributes":{}}`,
+			want: `{"id":-7,"item":"pen","quantity":1,"price":1.25,"status":"NEW","tags":[],"attributes":{},"note":null,"parent":null}`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := jsonToAvro(schema, []byte(tc.in))
+			if err != nil {
+				t.Fatal("jsonToAvro() =", err)
+			}
+			got, err := avroToJSON(schema, data)
+			if err != nil {
+				t.Fatal("avroToJSON() =", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("avroToJSON() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONToAvroEncoding(t *testing.T) {
+	schema, err := parseAvroSchema([]byte(`{"type":"record","name":"R","fields":[{"name":"n","type":"long"},{"name":"s","type":["null","string"]}]}`))
+	if err != nil {
+		t.Fatal("parseAvroSchema() =", err)
+	}
+	got, err := jsonToAvro(schema, []byte(`{"n":-2,"s":"hi"}`))
+	if err != nil {
+		t.Fatal("jsonToAvro() =", err)
+	}
+	// -2 zigzag encoded, union branch 1, then the string of length 2.
+	want := []byte{0x03, 0x02, 0x04, 'h', 'i'}
+	if !bytes.Equal(got, want) {
+		t.Errorf("jsonToAvro() = %x, want %x", got, want)
+	}
+}
+
+func TestAvroErrors(t *testing.T) {
+	schema, err := parseAvroSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatal("parseAvroSchema() =", err)
+	}
+	for name, in := range map[string]string{
+		"not json":       `{"id":`,
+		"missing field":  `{"id":1}`,
+		"wrong type":     `{"id":"1","item":"a","price":1,"status":"NEW","tags":[],"attributes":{}}`,
+		"int overflow":   `{"id":1,"item":"a","quantity":3000000000,"price":1,"status":"NEW","tags":[],"attributes":{}}`,
+		"unknown symbol": `{"id":1,"item":"a","price":1,"status":"LOST","tags":[],"attributes":{}}`,
+		"no union fits":  `{"id":1,"item":"a","price":1,"status":"NEW","tags":[],"attributes":{},"note":3}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jsonToAvro(schema, []byte(in)); err == nil {
+				t.Error("Expected jsonToAvro() to fail")
+			}
+		})
+	}
+
+	if _, err := avroToJSON(schema, []byte{0x02, 0x10}); err == nil {
+		t.Error("Expected avroToJSON() to fail on truncated data")
+	}
+	for _, s := range []string{`{`, `"unknown"`, `{"type":"record","fields":[]}`, `{"type":"fixed","name":"F"}`} {
+		if _, err := parseAvroSchema([]byte(s)); err == nil {
+			t.Errorf("Expected parseAvroSchema(%s) to fail", s)
+		}
+	}
+}
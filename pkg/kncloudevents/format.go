@@ -0,0 +1,213 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kncloudevents
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	duckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+)
+
+const (
+	// schemaCacheTTL is how long the fetched Avro schemas are used before
+	// being fetched again.
+	schemaCacheTTL = 5 * time.Minute
+
+	// maxSchemaSize bounds the size of the fetched Avro schemas.
+	maxSchemaSize = 1 << 20
+
+	// maxCachedSchemas bounds the number of cached Avro schemas, the least
+	// recently used ones being evicted.
+	maxCachedSchemas = 256
+)
+
+// Format is the format the events are converted to before being sent, see
+// duckv1.DeliveryFormat.
+type Format struct {
+	// Encoding is the content mode of the requests, EncodingUnknown
+	// keeping the one of the messages.
+	Encoding binding.Encoding
+	// DataContentType is the content type the data is transcoded to, the
+	// data being left as is when empty.
+	DataContentType string
+	// SchemaRef is the URL of the Avro schema of the data, the only one
+	// fetched: the dataschema of the events is never fetched.
+	SchemaRef string
+}
+
+// NewFormat returns the Format of the DeliveryFormat.
+func NewFormat(spec duckv1.DeliveryFormat) *Format {
+	f := &Format{}
+	if spec.Encoding != nil {
+		switch *spec.Encoding {
+		case duckv1.EncodingBinary:
+			f.Encoding = binding.EncodingBinary
+		case duckv1.EncodingStructured:
+			f.Encoding = binding.EncodingStructured
+		}
+	}
+	if spec.DataContentType != nil {
+		f.DataContentType = *spec.DataContentType
+	}
+	if spec.SchemaRef != nil {
+		f.SchemaRef = spec.SchemaRef.String()
+	}
+	return f
+}
+
+// WriteHTTPRequestWithFormat writes the message to the request like
+// WriteHTTPRequestWithAdditionalHeaders, converting it to the format first.
+// The message is written as is when the format is nil.
+func WriteHTTPRequestWithFormat(ctx context.Context, message binding.Message, format *Format, req *nethttp.Request,
+	additionalHeaders nethttp.Header, transformers ...binding.Transformer) error {
+	if format == nil {
+		return WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders, transformers...)
+	}
+
+	if format.DataContentType != "" {
+		event, err := binding.ToEvent(ctx, message)
+		if err != nil {
+			return err
+		}
+		// The events of the event messages are not modified, they may be
+		// sent again as they are.
+		transcoded := event.Clone()
+		if err := format.transcode(ctx, &transcoded); err != nil {
+			return fmt.Errorf("failed to transcode the data to %s: %w", format.DataContentType, err)
+		}
+		message = binding.ToMessage(&transcoded)
+	}
+
+	switch format.Encoding {
+	case binding.EncodingBinary:
+		ctx = binding.WithForceBinary(ctx)
+	case binding.EncodingStructured:
+		ctx = binding.WithForceStructured(ctx)
+	}
+	return WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders, transformers...)
+}
+
+// transcode transcodes the data of the event between JSON and Avro.
+func (f *Format) transcode(ctx context.Context, event *cloudevents.Event) error {
+	from := mediaType(event.DataContentType())
+	if from == "" {
+		from = duckv1.DataContentTypeJSON
+	}
+	if event.Data() == nil || from == f.DataContentType {
+		return nil
+	}
+
+	switch {
+	case f.DataContentType == duckv1.DataContentTypeAvro && isJSON(from):
+		schema, err := defaultSchemas.get(ctx, f.SchemaRef)
+		if err != nil {
+			return err
+		}
+		data, err := jsonToAvro(schema, event.Data())
+		if err != nil {
+			return err
+		}
+		event.SetDataSchema(f.SchemaRef)
+		return event.SetData(duckv1.DataContentTypeAvro, data)
+
+	case f.DataContentType == duckv1.DataContentTypeJSON && isAvro(from):
+		if f.SchemaRef == "" {
+			return fmt.Errorf("no schemaRef for the avro data")
+		}
+		schema, err := defaultSchemas.get(ctx, f.SchemaRef)
+		if err != nil {
+			return err
+		}
+		data, err := avroToJSON(schema, event.Data())
+		if err != nil {
+			return err
+		}
+		return event.SetData(duckv1.DataContentTypeJSON, data)
+	}
+	return fmt.Errorf("unsupported data content type %q", event.DataContentType())
+}
+
+// mediaType returns the content type without its parameters.
+func mediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == duckv1.DataContentTypeJSON || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func isAvro(mediaType string) bool {
+	return mediaType == duckv1.DataContentTypeAvro || mediaType == "avro/binary"
+}
+
+// schemaCache fetches the Avro schemas, keeping up to maxCachedSchemas of them
+// for schemaCacheTTL.
+type schemaCache struct {
+	client  *nethttp.Client
+	schemas *cache.LRUExpireCache
+}
+
+var defaultSchemas = &schemaCache{
+	client:  &nethttp.Client{Timeout: 10 * time.Second},
+	schemas: cache.NewLRUExpireCache(maxCachedSchemas),
+}
+
+func (c *schemaCache) get(ctx context.Context, ref string) (*avroSchema, error) {
+	if cached, ok := c.schemas.Get(ref); ok {
+		return cached.(*avroSchema), nil
+	}
+
+	schema, err := c.fetch(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the schema %s: %w", ref, err)
+	}
+	c.schemas.Add(ref, schema, schemaCacheTTL)
+	return schema, nil
+}
+
+func (c *schemaCache) fetch(ctx context.Context, ref string) (*avroSchema, error) {
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSchemaSize))
+	if err != nil {
+		return nil, err
+	}
+	return parseAvroSchema(b)
+}
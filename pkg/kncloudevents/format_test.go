@@ -0,0 +1,189 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kncloudevents
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	duckv1 "knative.dev/eventing/pkg/apis/duck/v1"
+)
+
+func TestNewFormat(t *testing.T) {
+	structured := duckv1.EncodingStructured
+	got := NewFormat(duckv1.DeliveryFormat{
+		Encoding:        &structured,
+		DataContentType: pointer.StringPtr(duckv1.DataContentTypeAvro),
+		SchemaRef:       &apis.URL{Scheme: "http", Host: "schemas.example.com", Path: "/order.avsc"},
+	})
+	want := Format{
+		Encoding:        binding.EncodingStructured,
+		DataContentType: duckv1.DataContentTypeAvro,
+		SchemaRef:       "http://schemas.example.com/order.avsc",
+	}
+	if *got != want {
+		t.Errorf("NewFormat() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestWriteHTTPRequestWithFormat(t *testing.T) {
+	var fetched int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+		_, _ = w.Write([]byte(`{"type":"record","name":"Order","fields":[{"name":"id","type":"long"},{"name":"item","type":"string"}]}`))
+	}))
+	defer server.Close()
+	schemaRef := server.URL + "/order.avsc"
+
+	jsonEvent := newFormatTestEvent(cloudevents.ApplicationJSON, `{"id":42,"item":"book"}`)
+	avroEvent := newFormatTestEvent(duckv1.DataContentTypeAvro, "\x54\x08book")
+	avroEvent.SetDataSchema(schemaRef)
+
+	tests := map[string]struct {
+		event          cloudevents.Event
+		format         *Format
+		wantEncoding   binding.Encoding
+		wantType       string
+		wantData       string
+		wantDataSchema string
+		wantErr        bool
+	}{
+		"no format": {
+			event:        jsonEvent,
+			wantEncoding: binding.EncodingBinary,
+			wantType:     cloudevents.ApplicationJSON,
+			wantData:     `{"id":42,"item":"book"}`,
+		},
+		"structured": {
+			event:        jsonEvent,
+			format:       &Format{Encoding: binding.EncodingStructured},
+			wantEncoding: binding.EncodingStructured,
+			wantType:     cloudevents.ApplicationJSON,
+			wantData:     `{"id":42,"item":"book"}`,
+		},
+		"json to avro": {
+			event:          jsonEvent,
+			format:         &Format{DataContentType: duckv1.DataContentTypeAvro, SchemaRef: schemaRef},
+			wantEncoding:   binding.EncodingBinary,
+			wantType:       duckv1.DataContentTypeAvro,
+			wantData:       "\x54\x08book",
+			wantDataSchema: schemaRef,
+		},
+		"avro to json": {
+			event: avroEvent,
+			format: &Format{Encoding: binding.EncodingStructured, DataContentType: duckv1.DataContentTypeJSON,
+				SchemaRef: schemaRef},
+			wantEncoding:   binding.EncodingStructured,
+			wantType:       duckv1.DataContentTypeJSON,
+			wantData:       `{"id":42,"item":"book"}`,
+			wantDataSchema: schemaRef,
+		},
+		"avro to json without schemaRef": {
+			event:   avroEvent,
+			format:  &Format{DataContentType: duckv1.DataContentTypeJSON},
+			wantErr: true,
+		},
+		"already json": {
+			event:        jsonEvent,
+			format:       &Format{DataContentType: duckv1.DataContentTypeJSON},
+			wantEncoding: binding.EncodingBinary,
+			wantType:     cloudevents.ApplicationJSON,
+			wantData:     `{"id":42,"item":"book"}`,
+		},
+		"unsupported content type": {
+			event:   newFormatTestEvent("text/plain", "hello"),
+			format:  &Format{DataContentType: duckv1.DataContentTypeAvro, SchemaRef: schemaRef},
+			wantErr: true,
+		},
+		"missing schema": {
+			event:   newFormatTestEvent(duckv1.DataContentTypeAvro, "\x54\x08book"),
+			format:  &Format{DataContentType: duckv1.DataContentTypeJSON},
+			wantErr: true,
+		},
+		"data not matching the schema": {
+			event:   newFormatTestEvent(cloudevents.ApplicationJSON, `{"id":"42"}`),
+			format:  &Format{DataContentType: duckv1.DataContentTypeAvro, SchemaRef: schemaRef},
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if n := atomic.LoadInt32(&fetched); n > 1 {
+					t.Errorf("Expected the schema to be fetched once, got %d fetches", n)
+				}
+			}()
+			ctx := context.Background()
+			req, err := http.NewRequest(http.MethodPost, "http://example.com", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			original := tc.event.Clone()
+			message := binding.ToMessage(&tc.event)
+
+			err = WriteHTTPRequestWithFormat(ctx, message, tc.format, req, nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected WriteHTTPRequestWithFormat() to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("WriteHTTPRequestWithFormat() =", err)
+			}
+			if string(tc.event.Data()) != string(original.Data()) {
+				t.Errorf("The event of the message was modified")
+			}
+
+			got := cehttp.NewMessageFromHttpRequest(req)
+			if got.ReadEncoding() != tc.wantEncoding {
+				t.Errorf("Encoding = %v, want %v", got.ReadEncoding(), tc.wantEncoding)
+			}
+			event, err := binding.ToEvent(ctx, got)
+			if err != nil {
+				t.Fatal("Failed to read the event:", err)
+			}
+			if event.DataContentType() != tc.wantType {
+				t.Errorf("DataContentType() = %q, want %q", event.DataContentType(), tc.wantType)
+			}
+			if string(event.Data()) != tc.wantData {
+				t.Errorf("Data() = %q, want %q", event.Data(), tc.wantData)
+			}
+			if event.DataSchema() != tc.wantDataSchema {
+				t.Errorf("DataSchema() = %q, want %q", event.DataSchema(), tc.wantDataSchema)
+			}
+		})
+	}
+}
+
+func newFormatTestEvent(contentType, data string) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID("1234")
+	event.SetType("com.example.order")
+	event.SetSource("/orders")
+	_ = event.SetData(contentType, []byte(data))
+	return event
+}
@@ -90,6 +90,13 @@ type RetryConfig struct {
 	BackoffDelay  *string
 	BackoffPolicy *duckv1.BackoffPolicyType
 
+	// RequestTimeout is the timeout of each attempt, none when zero.
+	RequestTimeout time.Duration
+
+	// Format is the format the events are converted to before being sent
+	// to the destination, none when nil.
+	Format *Format
+
 	CheckRetry CheckRetry
 	Backoff    Backoff
 }
@@ -99,8 +106,15 @@ func (s *HTTPMessageSender) SendWithRetries(req *nethttp.Request, config *RetryC
 		return s.Send(req)
 	}
 
+	client := s.Client
+	if config.RequestTimeout > 0 {
+		withTimeout := *s.Client
+		withTimeout.Timeout = config.RequestTimeout
+		client = &withTimeout
+	}
+
 	retryableClient := retryablehttp.Client{
-		HTTPClient:   s.Client,
+		HTTPClient:   client,
 		RetryWaitMin: defaultRetryWaitMin,
 		RetryWaitMax: defaultRetryWaitMax,
 		RetryMax:     config.RetryMax,
@@ -157,6 +171,18 @@ func RetryConfigFromDeliverySpec(spec duckv1.DeliverySpec) (RetryConfig, error)
 		}
 	}
 
+	if spec.Timeout != nil {
+		timeout, err := period.Parse(*spec.Timeout)
+		if err != nil {
+			return retryConfig, fmt.Errorf("failed to parse Spec.Timeout: %w", err)
+		}
+		retryConfig.RequestTimeout, _ = timeout.Duration()
+	}
+
+	if spec.Format != nil {
+		retryConfig.Format = NewFormat(*spec.Format)
+	}
+
 	return retryConfig, nil
 }
 
@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	cebinding "github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/buffering"
 	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
@@ -181,6 +182,47 @@ func TestHTTPMessageSenderSendWithRetries(t *testing.T) {
 	}
 }
 
+func TestHTTPMessageSenderSendWithRetriesTimeout(t *testing.T) {
+	var n int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// Only the first attempt is slower than the timeout.
+		if atomic.AddInt32(&n, 1) == 1 {
+			time.Sleep(500 * time.Millisecond)
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	r, err := RetryConfigFromDeliverySpec(duckv1.DeliverySpec{
+		Retry:   pointer.Int32Ptr(1),
+		Timeout: pointer.StringPtr("PT0.1S"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 100*time.Millisecond, r.RequestTimeout)
+	r.Backoff = func(attemptNum int, resp *http.Response) time.Duration {
+		return time.Millisecond
+	}
+
+	sender := &HTTPMessageSender{
+		Client: http.DefaultClient,
+	}
+	request, err := http.NewRequest("POST", server.URL, nil)
+	assert.Nil(t, err)
+	got, err := sender.SendWithRetries(request, &r)
+	if err != nil {
+		t.Fatalf("SendWithRetries() error = %v, wantErr nil", err)
+	}
+	if got.StatusCode != http.StatusAccepted {
+		t.Errorf("SendWithRetries() got = %v, want %v", got.StatusCode, http.StatusAccepted)
+	}
+	if count := atomic.LoadInt32(&n); count != 2 {
+		t.Errorf("expected 2 attempts got %d", count)
+	}
+	if http.DefaultClient.Timeout != 0 {
+		t.Error("The timeout leaked into the client of the sender")
+	}
+}
+
 func TestRetriesOnNetworkErrors(t *testing.T) {
 
 	n := int32(10)
@@ -365,3 +407,24 @@ func TestRetryConfigFromDeliverySpecCheckRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryConfigFromDeliverySpecFormat(t *testing.T) {
+	binary := eventingduck.EncodingBinary
+	got, err := RetryConfigFromDeliverySpec(eventingduck.DeliverySpec{
+		Format: &eventingduck.DeliveryFormat{Encoding: &binary},
+	})
+	if err != nil {
+		t.Fatal("RetryConfigFromDeliverySpec() =", err)
+	}
+	if got.Format == nil || got.Format.Encoding != cebinding.EncodingBinary {
+		t.Errorf("Format = %+v, want the binary encoding", got.Format)
+	}
+
+	got, err = RetryConfigFromDeliverySpec(eventingduck.DeliverySpec{})
+	if err != nil {
+		t.Fatal("RetryConfigFromDeliverySpec() =", err)
+	}
+	if got.Format != nil {
+		t.Errorf("Format = %+v, want none", got.Format)
+	}
+}
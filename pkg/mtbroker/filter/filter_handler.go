@@ -21,6 +21,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sync/atomic"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
@@ -32,10 +34,10 @@ import (
 	"go.uber.org/zap"
 	"knative.dev/pkg/logging"
 
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
 	"knative.dev/eventing/pkg/eventfilter"
-	"knative.dev/eventing/pkg/eventfilter/attributes"
 	"knative.dev/eventing/pkg/kncloudevents"
 	broker "knative.dev/eventing/pkg/mtbroker"
 	"knative.dev/eventing/pkg/reconciler/sugar/trigger/path"
@@ -65,6 +67,22 @@ type Handler struct {
 
 	triggerLister eventinglisters.TriggerLister
 	logger        *zap.Logger
+
+	// throttles limit the events sent to the subscribers of the Triggers
+	// setting delivery limits.
+	throttles throttles
+	// filters holds the filters of the Triggers.
+	filters triggerFilters
+
+	// replyValidation holds the *replyValidation of the replies of the
+	// subscribers, see UpdateReplyValidation.
+	replyValidation atomic.Value
+
+	// zoneRouter, when set, resolves the subscribers to the endpoints of the
+	// zone of the filter, see SetZoneRouter.
+	zoneRouter *ZoneRouter
+	// features holds the feature.Flags of config-features.
+	features atomic.Value
 }
 
 // NewHandler creates a new Handler and its associated MessageReceiver. The caller is responsible for
@@ -157,6 +175,11 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	if err := broker.DeleteTTL(event.Context); err != nil {
 		h.logger.Warn("Failed to delete TTL.", zap.Error(err))
 	}
+	// Like the TTL, the hop count is only used by the Broker.
+	hops := broker.GetHops(event.Context)
+	if err := broker.DeleteHops(event.Context); err != nil {
+		h.logger.Warn("Failed to delete the hop count.", zap.Error(err))
+	}
 
 	h.logger.Debug("Received message", zap.Any("triggerRef", triggerRef))
 
@@ -184,7 +207,7 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 
 	// Check if the event should be sent.
 	ctx = logging.WithLogger(ctx, h.logger.Sugar())
-	filterResult := filterEvent(ctx, t.Spec.Filter, *event)
+	filterResult := h.filterEvent(ctx, t, *event)
 
 	if filterResult == eventfilter.FailFilter {
 		// We do not count the event. The event will be counted in the broker ingress.
@@ -194,12 +217,47 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 
 	h.reportArrivalTime(event, reportArgs)
 
-	h.send(ctx, writer, request.Header, subscriberURI.String(), reportArgs, event, ttl)
+	release, err := h.waitForTurn(ctx, t, reportArgs)
+	if err != nil {
+		// The sender gave up, let it retry later.
+		h.logger.Info("Request ended before the turn of the throttled event", zap.Error(err), zap.Any("triggerRef", triggerRef))
+		writer.WriteHeader(http.StatusTooManyRequests)
+		_ = h.reporter.ReportEventCount(reportArgs, http.StatusTooManyRequests)
+		return
+	}
+	defer release()
+
+	h.send(ctx, writer, request.Header, subscriberURI.String(), reportArgs, event, triggerFormat(ctx, t), ttl, hops)
 }
 
-func (h *Handler) send(ctx context.Context, writer http.ResponseWriter, headers http.Header, target string, reportArgs *ReportArgs, event *cloudevents.Event, ttl int32) {
+// triggerFormat returns the format of the events sent to the Subscriber of
+// the Trigger, nil when they are sent as they are.
+func triggerFormat(ctx context.Context, t *eventingv1beta1.Trigger) *kncloudevents.Format {
+	if t.Spec.Delivery == nil || t.Spec.Delivery.Format == nil {
+		return nil
+	}
+	var format eventingduckv1.DeliveryFormat
+	if err := t.Spec.Delivery.Format.ConvertTo(ctx, &format); err != nil {
+		return nil
+	}
+	return kncloudevents.NewFormat(format)
+}
+
+// waitForTurn waits for the Trigger delivery limits to let the event
+// through, returning the function to call once it is sent.
+func (h *Handler) waitForTurn(ctx context.Context, t *eventingv1beta1.Trigger, reportArgs *ReportArgs) (func(), error) {
+	th := h.throttles.get(t)
+	if th == nil {
+		return func() {}, nil
+	}
+	return th.wait(ctx, func(reason string, d time.Duration) {
+		_ = h.reporter.ReportEventThrottled(reportArgs, reason, d)
+	})
+}
+
+func (h *Handler) send(ctx context.Context, writer http.ResponseWriter, headers http.Header, target string, reportArgs *ReportArgs, event *cloudevents.Event, format *kncloudevents.Format, ttl, hops int32) {
 	// send the event to trigger's subscriber
-	response, err := h.sendEvent(ctx, headers, target, event, reportArgs)
+	response, err := h.sendEvent(ctx, headers, target, event, format, reportArgs)
 	if err != nil {
 		h.logger.Error("failed to send event", zap.Error(err))
 		writer.WriteHeader(http.StatusInternalServerError)
@@ -210,25 +268,45 @@ func (h *Handler) send(ctx context.Context, writer http.ResponseWriter, headers
 	h.logger.Debug("Successfully dispatched message", zap.Any("target", target))
 
 	// If there is an event in the response write it to the response
-	statusCode, err := h.writeResponse(ctx, writer, response, ttl, target)
+	replyID, _ := broker.GetReplyID(event.Context)
+	statusCode, err := h.writeResponse(ctx, writer, response, ttl, hops, replyID, target)
 	if err != nil {
+		var rejected *replyRejectedError
+		if errors.As(err, &rejected) {
+			_ = h.reporter.ReportReplyRejected(reportArgs, rejected.reason)
+		}
 		h.logger.Error("failed to write response", zap.Error(err))
 	}
 	_ = h.reporter.ReportEventCount(reportArgs, statusCode)
 }
 
-func (h *Handler) sendEvent(ctx context.Context, headers http.Header, target string, event *cloudevents.Event, reporterArgs *ReportArgs) (*http.Response, error) {
-	// Send the event to the subscriber
-	req, err := h.sender.NewCloudEventRequestWithTarget(ctx, target)
+func (h *Handler) sendEvent(ctx context.Context, headers http.Header, target string, event *cloudevents.Event, format *kncloudevents.Format, reporterArgs *ReportArgs) (*http.Response, error) {
+	// Send the event to the subscriber, through an endpoint of the zone of
+	// the filter when there is one.
+	routed, zone := h.zoneTarget(target)
+	if zone != "" {
+		_ = h.reporter.ReportZoneDispatch(reporterArgs, zone)
+	}
+	requestTarget := target
+	if routed != nil {
+		requestTarget = routed.String()
+	}
+	req, err := h.sender.NewCloudEventRequestWithTarget(ctx, requestTarget)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the request: %w", err)
 	}
+	if routed != nil {
+		// The subscribers keep seeing the host of their Service.
+		if u, err := url.Parse(target); err == nil {
+			req.Host = u.Host
+		}
+	}
 
 	message := binding.ToMessage(event)
 	defer message.Finish(nil)
 
 	additionalHeaders := utils.PassThroughHeaders(headers)
-	err = kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders)
+	err = kncloudevents.WriteHTTPRequestWithFormat(ctx, message, format, req, additionalHeaders)
 	if err != nil {
 		return nil, fmt.Errorf("failed to write request: %w", err)
 	}
@@ -251,7 +329,7 @@ func (h *Handler) sendEvent(ctx context.Context, headers http.Header, target str
 }
 
 // The return values are the status
-func (h *Handler) writeResponse(ctx context.Context, writer http.ResponseWriter, resp *http.Response, ttl int32, target string) (int, error) {
+func (h *Handler) writeResponse(ctx context.Context, writer http.ResponseWriter, resp *http.Response, ttl, hops int32, replyID, target string) (int, error) {
 	response := cehttp.NewMessageFromHttpResponse(resp)
 	defer response.Finish(nil)
 
@@ -283,12 +361,39 @@ func (h *Handler) writeResponse(ctx context.Context, writer http.ResponseWriter,
 		return http.StatusBadGateway, err
 	}
 
+	// The rejected replies are delivery failures, like the malformed ones.
+	validation := h.loadReplyValidation()
+	if err := validation.validate(*event, hops); err != nil {
+		writer.WriteHeader(http.StatusBadGateway)
+		return http.StatusBadGateway, err
+	}
+
 	// Reattach the TTL (with the same value) to the response event before sending it to the Broker.
 	if err := broker.SetTTL(event.Context, ttl); err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
 		return http.StatusInternalServerError, fmt.Errorf("failed to reset TTL: %w", err)
 	}
 
+	// Count the hops of the reply, overwriting the count it may have set.
+	if validation.maxHops > 0 {
+		err = broker.SetHops(event.Context, hops+1)
+	} else {
+		err = broker.DeleteHops(event.Context)
+	}
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		return http.StatusInternalServerError, fmt.Errorf("failed to set the hop count: %w", err)
+	}
+
+	// Correlate the response event with the event it replies to, for the caller
+	// waiting for it at the ingress in request-reply mode.
+	if _, ok := broker.GetReplyID(event.Context); !ok && replyID != "" {
+		if err := broker.SetReplyID(event.Context, replyID); err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return http.StatusInternalServerError, fmt.Errorf("failed to set the reply id: %w", err)
+		}
+	}
+
 	eventResponse := binding.ToMessage(event)
 	defer eventResponse.Finish(nil)
 
@@ -324,15 +429,16 @@ func (h *Handler) getTrigger(ref path.NamespacedNameUID) (*eventingv1beta1.Trigg
 	return t, nil
 }
 
-func filterEvent(ctx context.Context, filter *eventingv1beta1.TriggerFilter, event cloudevents.Event) eventfilter.FilterResult {
-	if filter == nil {
-		return eventfilter.NoFilter
-	}
-	var filters eventfilter.Filters
-	if filter.Attributes != nil && len(filter.Attributes) != 0 {
-		filters = append(filters, attributes.NewAttributesFilter(filter.Attributes))
+// filterEvent filters the event with the filters of the Trigger, built once
+// per generation of the Trigger.
+func (h *Handler) filterEvent(ctx context.Context, t *eventingv1beta1.Trigger, event cloudevents.Event) eventfilter.FilterResult {
+	filter, err := h.filters.get(t)
+	if err != nil {
+		// The webhook rejects the invalid expressions, don't let any event through.
+		logging.FromContext(ctx).Warnw("Failed to create the Trigger filters", zap.Error(err))
+		return eventfilter.FailFilter
 	}
-	return filters.Filter(ctx, event)
+	return filter.Filter(ctx, event)
 }
 
 // triggerFilterAttribute returns the filter attribute value for a given `attributeName`. If it doesn't not exist,
@@ -38,8 +38,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	"knative.dev/pkg/apis"
 
+	eventingduckv1beta1 "knative.dev/eventing/pkg/apis/duck/v1beta1"
 	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	broker "knative.dev/eventing/pkg/mtbroker"
 	reconcilertesting "knative.dev/eventing/pkg/reconciler/testing"
@@ -70,6 +72,7 @@ func init() {
 }
 
 func TestReceiver(t *testing.T) {
+	structuredEncoding := eventingduckv1beta1.EncodingStructured
 	testCases := map[string]struct {
 		triggers                    []*eventingv1beta1.Trigger
 		request                     *http.Request
@@ -85,6 +88,7 @@ func TestReceiver(t *testing.T) {
 		expectedEventDispatchTime   bool
 		expectedEventProcessingTime bool
 		response                    *http.Response
+		expectedReplyID             string
 	}{
 		"Not POST": {
 			request:        httptest.NewRequest(http.MethodGet, validPath, nil),
@@ -121,6 +125,30 @@ func TestReceiver(t *testing.T) {
 			expectedEventCount:        true,
 			expectedEventDispatchTime: true,
 		},
+		"Trigger with delivery limits": {
+			triggers: []*eventingv1beta1.Trigger{
+				makeTriggerWithDelivery(&eventingv1beta1.TriggerDelivery{
+					RateLimit:   pointer.Int32Ptr(10),
+					MaxInFlight: pointer.Int32Ptr(1),
+				}),
+			},
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+		},
+		"Trigger with a structured delivery format": {
+			triggers: []*eventingv1beta1.Trigger{
+				makeTriggerWithDelivery(&eventingv1beta1.TriggerDelivery{
+					Format: &eventingduckv1beta1.DeliveryFormat{Encoding: &structuredEncoding},
+				}),
+			},
+			expectedHeaders: http.Header{
+				"Content-Type": []string{event.ApplicationCloudEventsJSON},
+			},
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+		},
 		"No TTL": {
 			triggers: []*eventingv1beta1.Trigger{
 				makeTrigger(makeTriggerFilterWithAttributes("some-other-type", "")),
@@ -157,6 +185,32 @@ func TestReceiver(t *testing.T) {
 			},
 			expectedEventCount: false,
 		},
+		"Filters pass": {
+			triggers: []*eventingv1beta1.Trigger{
+				makeTriggerWithFilters(makeTriggerFilterWithAttributes(eventType, ""), eventingv1beta1.SubscriptionsAPIFilter{
+					CESQL: "source = '" + eventSource + "' AND EXISTS id",
+				}),
+			},
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+		},
+		"Wrong source with filters": {
+			triggers: []*eventingv1beta1.Trigger{
+				makeTriggerWithFilters(nil, eventingv1beta1.SubscriptionsAPIFilter{
+					Prefix: map[string]string{"source": "some-other-source"},
+				}),
+			},
+			expectedEventCount: false,
+		},
+		"Invalid filters": {
+			triggers: []*eventingv1beta1.Trigger{
+				makeTriggerWithFilters(nil, eventingv1beta1.SubscriptionsAPIFilter{
+					CESQL: "source =",
+				}),
+			},
+			expectedEventCount: false,
+		},
 		"Dispatch failed": {
 			triggers: []*eventingv1beta1.Trigger{
 				makeTrigger(makeTriggerFilterWithAttributes("", "")),
@@ -234,6 +288,17 @@ func TestReceiver(t *testing.T) {
 			expectedEventDispatchTime: true,
 			returnedEvent:             makeDifferentEvent(),
 		},
+		"Returned Cloud Event correlated with the reply id": {
+			triggers: []*eventingv1beta1.Trigger{
+				makeTrigger(makeTriggerFilterWithAttributes("", "")),
+			},
+			event:                     makeEventWithExtension(broker.ReplyIDAttribute, "1234@10.0.0.1:8080"),
+			expectedDispatch:          true,
+			expectedEventCount:        true,
+			expectedEventDispatchTime: true,
+			returnedEvent:             makeDifferentEvent(),
+			expectedReplyID:           "1234@10.0.0.1:8080",
+		},
 		"Error From Trigger": {
 			triggers: []*eventingv1beta1.Trigger{
 				makeTrigger(makeTriggerFilterWithAttributes("", "")),
@@ -429,6 +494,9 @@ func TestReceiver(t *testing.T) {
 
 			// The TTL will be added again.
 			expectedResponseEvent := addTTLToEvent(*tc.returnedEvent)
+			if tc.expectedReplyID != "" {
+				_ = broker.SetReplyID(expectedResponseEvent.Context, tc.expectedReplyID)
+			}
 
 			// cloudevents/sdk-go doesn't preserve the extension type, so get TTL and set it back again.
 			// https://github.com/cloudevents/sdk-go/blob/97abfeb3da0bed09e395bff2c5bcf35b6435cb5f/v2/types/value.go#L57
@@ -468,6 +536,9 @@ type mockReporter struct {
 	eventCountReported          bool
 	eventDispatchTimeReported   bool
 	eventProcessingTimeReported bool
+	eventThrottledReported      bool
+	replyRejectedReason         string
+	dispatchZone                string
 }
 
 func (r *mockReporter) ReportEventCount(args *ReportArgs, responseCode int) error {
@@ -485,6 +556,21 @@ func (r *mockReporter) ReportEventProcessingTime(args *ReportArgs, d time.Durati
 	return nil
 }
 
+func (r *mockReporter) ReportEventThrottled(args *ReportArgs, reason string, d time.Duration) error {
+	r.eventThrottledReported = true
+	return nil
+}
+
+func (r *mockReporter) ReportReplyRejected(args *ReportArgs, reason string) error {
+	r.replyRejectedReason = reason
+	return nil
+}
+
+func (r *mockReporter) ReportZoneDispatch(args *ReportArgs, zone string) error {
+	r.dispatchZone = zone
+	return nil
+}
+
 type fakeHandler struct {
 	failRequest     bool
 	failStatus      int
@@ -586,6 +672,18 @@ func makeTrigger(filter *eventingv1beta1.TriggerFilter) *eventingv1beta1.Trigger
 	}
 }
 
+func makeTriggerWithFilters(filter *eventingv1beta1.TriggerFilter, filters ...eventingv1beta1.SubscriptionsAPIFilter) *eventingv1beta1.Trigger {
+	t := makeTrigger(filter)
+	t.Spec.Filters = filters
+	return t
+}
+
+func makeTriggerWithDelivery(delivery *eventingv1beta1.TriggerDelivery) *eventingv1beta1.Trigger {
+	t := makeTriggerWithoutFilter()
+	t.Spec.Delivery = delivery
+	return t
+}
+
 func makeTriggerWithoutFilter() *eventingv1beta1.Trigger {
 	t := makeTrigger(makeTriggerFilterWithAttributes("", ""))
 	t.Spec.Filter = nil
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+const (
+	// ReplyValidationConfigName is the name of the ConfigMap configuring the
+	// validation of the replies of the Trigger subscribers, before they are
+	// sent back to the Broker.
+	ReplyValidationConfigName = "config-br-reply-validation"
+
+	// replyValidationKey enables, by default, or disables the validation
+	// of the replies against the CloudEvents spec.
+	replyValidationKey = "validation"
+	// replyMaxHopsKey is the maximum number of replies an event can descend
+	// from, 0 for no maximum.
+	replyMaxHopsKey = "max-hops"
+	// replyAttributesKey holds the allowed and denied patterns of the reply
+	// attributes, by attribute name.
+	replyAttributesKey = "attributes"
+
+	// The reasons the replies are rejected for.
+	rejectReasonInvalid   = "invalid"
+	rejectReasonAttribute = "attribute"
+	rejectReasonMaxHops   = "max_hops"
+)
+
+// replyAttributePatterns are the patterns of the values of a reply attribute,
+// where * matches any sequence of characters.
+type replyAttributePatterns struct {
+	// Allow rejects the replies whose attribute, when set, doesn't match
+	// one of the patterns.
+	Allow []string `json:"allow,omitempty"`
+	// Deny rejects the replies whose attribute matches one of the patterns.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// attributeRule is the compiled form of replyAttributePatterns.
+type attributeRule struct {
+	name  string
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// replyValidation validates the replies of the Trigger subscribers.
+type replyValidation struct {
+	spec    bool
+	maxHops int32
+	rules   []attributeRule
+}
+
+// defaultReplyValidation only validates the replies against the spec.
+var defaultReplyValidation = &replyValidation{spec: true}
+
+// replyRejectedError is returned for the rejected replies.
+type replyRejectedError struct {
+	reason string
+	err    error
+}
+
+func (e *replyRejectedError) Error() string {
+	return fmt.Sprintf("rejected the reply (%s): %v", e.reason, e.err)
+}
+
+func (e *replyRejectedError) Unwrap() error {
+	return e.err
+}
+
+func newReplyValidationFromConfigMap(cm *corev1.ConfigMap) (*replyValidation, error) {
+	v := &replyValidation{spec: true}
+
+	switch value := strings.ToLower(cm.Data[replyValidationKey]); value {
+	case "", "enabled":
+	case "disabled":
+		v.spec = false
+	default:
+		return nil, fmt.Errorf("invalid %s %q, expected enabled or disabled", replyValidationKey, value)
+	}
+
+	if value := cm.Data[replyMaxHopsKey]; value != "" {
+		maxHops, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || maxHops < 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a positive integer or 0", replyMaxHopsKey, value)
+		}
+		v.maxHops = int32(maxHops)
+	}
+
+	var attributes map[string]replyAttributePatterns
+	if err := yaml.Unmarshal([]byte(cm.Data[replyAttributesKey]), &attributes); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", replyAttributesKey, err)
+	}
+	for name, patterns := range attributes {
+		rule := attributeRule{name: name}
+		for _, p := range patterns.Allow {
+			rule.allow = append(rule.allow, compileAttributePattern(p))
+		}
+		for _, p := range patterns.Deny {
+			rule.deny = append(rule.deny, compileAttributePattern(p))
+		}
+		v.rules = append(v.rules, rule)
+	}
+	// Validate in a predictable order, for the same reply to always be
+	// rejected for the same attribute.
+	sort.Slice(v.rules, func(i, j int) bool { return v.rules[i].name < v.rules[j].name })
+	return v, nil
+}
+
+// compileAttributePattern returns the regular expression of the pattern,
+// where * matches any sequence of characters.
+func compileAttributePattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("(?s)^" + strings.Join(parts, ".*") + "$")
+}
+
+// validate returns a *replyRejectedError when the reply, to an event
+// descending from hops replies, is rejected.
+func (v *replyValidation) validate(reply cloudevents.Event, hops int32) error {
+	if v.spec {
+		if err := reply.Validate(); err != nil {
+			return &replyRejectedError{reason: rejectReasonInvalid, err: err}
+		}
+	}
+
+	if v.maxHops > 0 && hops+1 > v.maxHops {
+		return &replyRejectedError{
+			reason: rejectReasonMaxHops,
+			err:    fmt.Errorf("the event descends from %d replies, exceeding the maximum of %d", hops+1, v.maxHops),
+		}
+	}
+
+	for _, rule := range v.rules {
+		value, ok := eventfilter.ContextAttribute(reply, rule.name)
+		if !ok {
+			continue
+		}
+		s := fmt.Sprint(value)
+		for _, deny := range rule.deny {
+			if deny.MatchString(s) {
+				return &replyRejectedError{reason: rejectReasonAttribute, err: fmt.Errorf("the %s %q is denied", rule.name, s)}
+			}
+		}
+		if len(rule.allow) > 0 && !matchesAny(rule.allow, s) {
+			return &replyRejectedError{reason: rejectReasonAttribute, err: fmt.Errorf("the %s %q isn't allowed", rule.name, s)}
+		}
+	}
+	return nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateReplyValidation applies the ReplyValidationConfigName ConfigMap, the
+// previous validation being kept when it is invalid.
+func (h *Handler) UpdateReplyValidation(cm *corev1.ConfigMap) {
+	v, err := newReplyValidationFromConfigMap(cm)
+	if err != nil {
+		h.logger.Error("Invalid reply validation, keeping the previous one", zap.Error(err))
+		return
+	}
+	h.replyValidation.Store(v)
+}
+
+// loadReplyValidation returns the current reply validation.
+func (h *Handler) loadReplyValidation() *replyValidation {
+	if v, ok := h.replyValidation.Load().(*replyValidation); ok {
+		return v
+	}
+	return defaultReplyValidation
+}
@@ -0,0 +1,363 @@
+/*
+ * Copyright 2019 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.uber.org/zap/zaptest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+
+	broker "knative.dev/eventing/pkg/mtbroker"
+	reconcilertesting "knative.dev/eventing/pkg/reconciler/testing"
+)
+
+func makeReplyValidationConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ReplyValidationConfigName},
+		Data:       data,
+	}
+}
+
+func TestNewReplyValidationFromConfigMap(t *testing.T) {
+	tests := map[string]struct {
+		data        map[string]string
+		wantSpec    bool
+		wantMaxHops int32
+		wantRules   []string
+		wantErr     bool
+	}{
+		"defaults": {
+			wantSpec: true,
+		},
+		"disabled": {
+			data: map[string]string{replyValidationKey: "Disabled"},
+		},
+		"max hops and attributes": {
+			data: map[string]string{
+				replyMaxHopsKey: "5",
+				replyAttributesKey: `
+type:
+  deny: ["dev.knative.*"]
+source:
+  allow: ["/functions/*"]
+`,
+			},
+			wantSpec:    true,
+			wantMaxHops: 5,
+			wantRules:   []string{"source", "type"},
+		},
+		"invalid validation": {
+			data:    map[string]string{replyValidationKey: "sometimes"},
+			wantErr: true,
+		},
+		"negative max hops": {
+			data:    map[string]string{replyMaxHopsKey: "-1"},
+			wantErr: true,
+		},
+		"invalid max hops": {
+			data:    map[string]string{replyMaxHopsKey: "many"},
+			wantErr: true,
+		},
+		"invalid attributes": {
+			data:    map[string]string{replyAttributesKey: "type: [dev.knative.*]"},
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := newReplyValidationFromConfigMap(makeReplyValidationConfigMap(tc.data))
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if v.spec != tc.wantSpec {
+				t.Errorf("spec = %v, want %v", v.spec, tc.wantSpec)
+			}
+			if v.maxHops != tc.wantMaxHops {
+				t.Errorf("maxHops = %d, want %d", v.maxHops, tc.wantMaxHops)
+			}
+			var rules []string
+			for _, rule := range v.rules {
+				rules = append(rules, rule.name)
+			}
+			if len(rules) != len(tc.wantRules) {
+				t.Fatalf("rules = %v, want %v", rules, tc.wantRules)
+			}
+			for i := range rules {
+				if rules[i] != tc.wantRules[i] {
+					t.Errorf("rules = %v, want %v", rules, tc.wantRules)
+				}
+			}
+		})
+	}
+}
+
+func TestReplyValidation(t *testing.T) {
+	v, err := newReplyValidationFromConfigMap(makeReplyValidationConfigMap(map[string]string{
+		replyMaxHopsKey: "3",
+		replyAttributesKey: `
+type:
+  deny: ["dev.knative.*"]
+source:
+  allow: ["/functions/*", "another-source"]
+myextension:
+  deny: ["forged"]
+`,
+	}))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	tests := map[string]struct {
+		reply      *cloudevents.Event
+		hops       int32
+		validation *replyValidation
+		wantReason string
+	}{
+		"valid": {
+			reply: makeDifferentEvent(),
+		},
+		"allowed source": {
+			reply: func() *cloudevents.Event {
+				e := makeDifferentEvent()
+				e.SetSource("/functions/echo")
+				return e
+			}(),
+		},
+		"not allowed source": {
+			reply: func() *cloudevents.Event {
+				e := makeDifferentEvent()
+				e.SetSource("/functions")
+				return e
+			}(),
+			wantReason: rejectReasonAttribute,
+		},
+		"denied type": {
+			reply: func() *cloudevents.Event {
+				e := makeDifferentEvent()
+				e.SetType("dev.knative.apiserver.resource.add")
+				return e
+			}(),
+			wantReason: rejectReasonAttribute,
+		},
+		"denied extension": {
+			reply: func() *cloudevents.Event {
+				e := makeDifferentEvent()
+				e.SetExtension(extensionName, "forged")
+				return e
+			}(),
+			wantReason: rejectReasonAttribute,
+		},
+		"below the maximum hops": {
+			reply: makeDifferentEvent(),
+			hops:  2,
+		},
+		"exceeding the maximum hops": {
+			reply:      makeDifferentEvent(),
+			hops:       3,
+			wantReason: rejectReasonMaxHops,
+		},
+		"invalid": {
+			reply: func() *cloudevents.Event {
+				e := makeDifferentEvent()
+				e.SetID("")
+				return e
+			}(),
+			wantReason: rejectReasonInvalid,
+		},
+		"invalid without spec validation": {
+			reply: func() *cloudevents.Event {
+				e := makeDifferentEvent()
+				e.SetID("")
+				return e
+			}(),
+			validation: &replyValidation{},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			validation := v
+			if tc.validation != nil {
+				validation = tc.validation
+			}
+			err := validation.validate(*tc.reply, tc.hops)
+			if tc.wantReason == "" {
+				if err != nil {
+					t.Error("Unexpected error:", err)
+				}
+				return
+			}
+			var rejected *replyRejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("Expected the reply to be rejected, got %v", err)
+			}
+			if rejected.reason != tc.wantReason {
+				t.Errorf("Unexpected reason, wanted %s, got %s", tc.wantReason, rejected.reason)
+			}
+		})
+	}
+}
+
+func TestReceiverReplyValidation(t *testing.T) {
+	tests := map[string]struct {
+		data           map[string]string
+		event          *cloudevents.Event
+		returnedEvent  *cloudevents.Event
+		expectedStatus int
+		expectedReason string
+		expectedHops   int32
+	}{
+		"default": {
+			returnedEvent:  makeDifferentEvent(),
+			expectedStatus: http.StatusAccepted,
+		},
+		"denied reply": {
+			data: map[string]string{
+				replyAttributesKey: `source: {deny: ["another-*"]}`,
+			},
+			returnedEvent:  makeDifferentEvent(),
+			expectedStatus: http.StatusBadGateway,
+			expectedReason: rejectReasonAttribute,
+		},
+		"counted hops": {
+			data: map[string]string{replyMaxHopsKey: "3"},
+			event: func() *cloudevents.Event {
+				e := makeEvent()
+				_ = broker.SetHops(e.Context, 1)
+				return e
+			}(),
+			returnedEvent:  makeDifferentEvent(),
+			expectedStatus: http.StatusAccepted,
+			expectedHops:   2,
+		},
+		"forged hops": {
+			data: map[string]string{replyMaxHopsKey: "3"},
+			returnedEvent: func() *cloudevents.Event {
+				e := makeDifferentEvent()
+				_ = broker.SetHops(e.Context, 0)
+				return e
+			}(),
+			expectedStatus: http.StatusAccepted,
+			expectedHops:   1,
+		},
+		"exceeding the maximum hops": {
+			data: map[string]string{replyMaxHopsKey: "3"},
+			event: func() *cloudevents.Event {
+				e := makeEvent()
+				_ = broker.SetHops(e.Context, 3)
+				return e
+			}(),
+			returnedEvent:  makeDifferentEvent(),
+			expectedStatus: http.StatusBadGateway,
+			expectedReason: rejectReasonMaxHops,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fh := fakeHandler{
+				returnedEvent: tc.returnedEvent,
+				t:             t,
+			}
+			s := httptest.NewServer(&fh)
+			defer s.Close()
+
+			trigger := makeTrigger(makeTriggerFilterWithAttributes("", ""))
+			url, err := apis.ParseURL(s.URL)
+			if err != nil {
+				t.Fatalf("Failed to parse URL %q : %s", s.URL, err)
+			}
+			trigger.Status.SubscriberURI = url
+			listers := reconcilertesting.NewListers([]runtime.Object{trigger})
+			reporter := &mockReporter{}
+			h, err := NewHandler(zaptest.NewLogger(t), listers.GetV1Beta1TriggerLister(), reporter, 8080)
+			if err != nil {
+				t.Fatal("Unable to create receiver:", err)
+			}
+			h.UpdateReplyValidation(makeReplyValidationConfigMap(tc.data))
+
+			e := tc.event
+			if e == nil {
+				e = makeEvent()
+			}
+			b, err := e.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := httptest.NewRequest(http.MethodPost, validPath, bytes.NewBuffer(b))
+			request.Header.Set(cehttp.ContentType, event.ApplicationCloudEventsJSON)
+			responseWriter := httptest.NewRecorder()
+			h.ServeHTTP(responseWriter, request)
+
+			response := responseWriter.Result()
+			if response.StatusCode != tc.expectedStatus {
+				t.Errorf("Unexpected status. Expected %v. Actual %v.", tc.expectedStatus, response.StatusCode)
+			}
+			if reporter.replyRejectedReason != tc.expectedReason {
+				t.Errorf("Unexpected rejection reason. Expected %q. Actual %q.", tc.expectedReason, reporter.replyRejectedReason)
+			}
+			if tc.expectedStatus != http.StatusAccepted {
+				return
+			}
+
+			reply, err := binding.ToEvent(context.Background(), cehttp.NewMessageFromHttpResponse(response))
+			if err != nil {
+				t.Fatal("Expected a reply event:", err)
+			}
+			if got := broker.GetHops(reply.Context); got != tc.expectedHops {
+				t.Errorf("Unexpected hops. Expected %d. Actual %d.", tc.expectedHops, got)
+			}
+			if _, ok := reply.Extensions()[broker.HopsAttribute]; ok != (tc.expectedHops > 0) {
+				t.Errorf("Unexpected presence of the hops extension: %v", reply.Extensions())
+			}
+		})
+	}
+}
+
+func TestUpdateReplyValidationKeepsPrevious(t *testing.T) {
+	listers := reconcilertesting.NewListers(nil)
+	h, err := NewHandler(zaptest.NewLogger(t), listers.GetV1Beta1TriggerLister(), &mockReporter{}, 8080)
+	if err != nil {
+		t.Fatal("Unable to create receiver:", err)
+	}
+	if h.loadReplyValidation() != defaultReplyValidation {
+		t.Error("Expected the default reply validation")
+	}
+
+	h.UpdateReplyValidation(makeReplyValidationConfigMap(map[string]string{replyMaxHopsKey: "4"}))
+	h.UpdateReplyValidation(makeReplyValidationConfigMap(map[string]string{replyMaxHopsKey: "many"}))
+	if got := h.loadReplyValidation().maxHops; got != 4 {
+		t.Errorf("maxHops = %d, want the previous 4", got)
+	}
+}
@@ -34,6 +34,19 @@ import (
 const (
 	// anyValue is the default value if the trigger filter attributes are empty.
 	anyValue = "any"
+
+	// LabelThrottleReason is the label of the delivery limit throttling
+	// the events, rate_limit or max_in_flight.
+	LabelThrottleReason = "throttle_reason"
+
+	// LabelRejectReason is the label of the reason the replies are rejected
+	// for, invalid, attribute or max_hops.
+	LabelRejectReason = "reject_reason"
+
+	// LabelDispatchZone is the label of the zone of the sends to the Trigger
+	// subscribers relative to the zone of the filter, same_zone or
+	// cross_zone.
+	LabelDispatchZone = "dispatch_zone"
 )
 
 var (
@@ -61,6 +74,39 @@ var (
 		stats.UnitMilliseconds,
 	)
 
+	// throttledCountM is a counter which records the number of events
+	// waiting for the delivery limits of a Trigger.
+	throttledCountM = stats.Int64(
+		"event_throttled_count",
+		"Number of events throttled by the delivery limits of a Trigger",
+		stats.UnitDimensionless,
+	)
+
+	// throttleTimeInMsecM records the time spent waiting for the delivery
+	// limits of a Trigger, in milliseconds.
+	throttleTimeInMsecM = stats.Float64(
+		"event_throttle_latencies",
+		"The time spent waiting for the delivery limits of a Trigger",
+		stats.UnitMilliseconds,
+	)
+
+	// replyRejectedCountM is a counter which records the number of replies
+	// of the Trigger subscribers rejected by the reply validation.
+	replyRejectedCountM = stats.Int64(
+		"event_reply_rejected_count",
+		"Number of replies of a Trigger subscriber rejected by the reply validation",
+		stats.UnitDimensionless,
+	)
+
+	// zoneDispatchCountM is a counter which records the number of events
+	// sent to the Trigger subscribers with the topology-aware-dispatch, by
+	// zone.
+	zoneDispatchCountM = stats.Int64(
+		"event_zone_dispatch_count",
+		"Number of events sent to a Trigger subscriber in the same or in another zone",
+		stats.UnitDimensionless,
+	)
+
 	// Create the tag keys that will be used to add tags to our measurements.
 	// Tag keys must conform to the restrictions described in
 	// go.opencensus.io/tag/validate.go. Currently those restrictions are:
@@ -69,6 +115,9 @@ var (
 	triggerFilterTypeKey = tag.MustNewKey(metricskey.LabelFilterType)
 	responseCodeKey      = tag.MustNewKey(metricskey.LabelResponseCode)
 	responseCodeClassKey = tag.MustNewKey(metricskey.LabelResponseCodeClass)
+	throttleReasonKey    = tag.MustNewKey(LabelThrottleReason)
+	rejectReasonKey      = tag.MustNewKey(LabelRejectReason)
+	dispatchZoneKey      = tag.MustNewKey(LabelDispatchZone)
 )
 
 type ReportArgs struct {
@@ -87,6 +136,9 @@ type StatsReporter interface {
 	ReportEventCount(args *ReportArgs, responseCode int) error
 	ReportEventDispatchTime(args *ReportArgs, responseCode int, d time.Duration) error
 	ReportEventProcessingTime(args *ReportArgs, d time.Duration) error
+	ReportEventThrottled(args *ReportArgs, reason string, d time.Duration) error
+	ReportReplyRejected(args *ReportArgs, reason string) error
+	ReportZoneDispatch(args *ReportArgs, zone string) error
 }
 
 var _ StatsReporter = (*reporter)(nil)
@@ -127,6 +179,30 @@ func register() {
 			Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...), // 1, 2, 5, 10, 20, 50, 100, 1000, 5000, 10000
 			TagKeys:     []tag.Key{triggerFilterTypeKey, broker.UniqueTagKey, broker.ContainerTagKey},
 		},
+		&view.View{
+			Description: throttledCountM.Description(),
+			Measure:     throttledCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{triggerFilterTypeKey, throttleReasonKey, broker.UniqueTagKey, broker.ContainerTagKey},
+		},
+		&view.View{
+			Description: throttleTimeInMsecM.Description(),
+			Measure:     throttleTimeInMsecM,
+			Aggregation: view.Distribution(metrics.Buckets125(1, 10000)...), // 1, 2, 5, 10, 20, 50, 100, 1000, 5000, 10000
+			TagKeys:     []tag.Key{triggerFilterTypeKey, throttleReasonKey, broker.UniqueTagKey, broker.ContainerTagKey},
+		},
+		&view.View{
+			Description: replyRejectedCountM.Description(),
+			Measure:     replyRejectedCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{triggerFilterTypeKey, rejectReasonKey, broker.UniqueTagKey, broker.ContainerTagKey},
+		},
+		&view.View{
+			Description: zoneDispatchCountM.Description(),
+			Measure:     zoneDispatchCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{triggerFilterTypeKey, dispatchZoneKey, broker.UniqueTagKey, broker.ContainerTagKey},
+		},
 	)
 	if err != nil {
 		log.Printf("failed to register opencensus views, %s", err)
@@ -170,6 +246,40 @@ func (r *reporter) ReportEventProcessingTime(args *ReportArgs, d time.Duration)
 	return nil
 }
 
+// ReportEventThrottled captures the events waiting for the delivery limits
+// and the time they waited.
+func (r *reporter) ReportEventThrottled(args *ReportArgs, reason string, d time.Duration) error {
+	ctx, err := r.generateTag(args, tag.Insert(throttleReasonKey, reason))
+	if err != nil {
+		return err
+	}
+	// convert time.Duration in nanoseconds to milliseconds.
+	metrics.Record(ctx, throttledCountM.M(1))
+	metrics.Record(ctx, throttleTimeInMsecM.M(float64(d/time.Millisecond)))
+	return nil
+}
+
+// ReportReplyRejected captures the replies rejected by the reply validation.
+func (r *reporter) ReportReplyRejected(args *ReportArgs, reason string) error {
+	ctx, err := r.generateTag(args, tag.Insert(rejectReasonKey, reason))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, replyRejectedCountM.M(1))
+	return nil
+}
+
+// ReportZoneDispatch captures the zones of the events sent to the Trigger
+// subscribers.
+func (r *reporter) ReportZoneDispatch(args *ReportArgs, zone string) error {
+	ctx, err := r.generateTag(args, tag.Insert(dispatchZoneKey, zone))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, zoneDispatchCountM.M(1))
+	return nil
+}
+
 func (r *reporter) generateTag(args *ReportArgs, tags ...tag.Mutator) (context.Context, error) {
 	ctx := metricskey.WithResource(emptyContext, resource.Resource{
 		Type: metricskey.ResourceTypeKnativeTrigger,
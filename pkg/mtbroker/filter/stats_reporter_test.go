@@ -90,6 +90,43 @@ func TestStatsReporter(t *testing.T) {
 	})
 	metricstest.AssertMetric(t, metricstest.DistributionCountOnlyMetric("event_processing_latencies", 2, wantTags))
 	metricstest.CheckDistributionData(t, "event_processing_latencies", wantTags, 2, 1000.0, 8000.0)
+
+	// test ReportEventThrottled
+	wantThrottleTags := map[string]string{LabelThrottleReason: throttleReasonRateLimit}
+	for k, v := range wantTags {
+		wantThrottleTags[k] = v
+	}
+	expectSuccess(t, func() error {
+		return r.ReportEventThrottled(args, throttleReasonRateLimit, 100*time.Millisecond)
+	})
+	expectSuccess(t, func() error {
+		return r.ReportEventThrottled(args, throttleReasonRateLimit, 300*time.Millisecond)
+	})
+	metricstest.AssertMetric(t, metricstest.IntMetric("event_throttled_count", 2, wantThrottleTags).WithResource(&resource))
+	metricstest.CheckDistributionData(t, "event_throttle_latencies", wantThrottleTags, 2, 100.0, 300.0)
+
+	// test ReportReplyRejected
+	wantRejectTags := map[string]string{LabelRejectReason: rejectReasonMaxHops}
+	for k, v := range wantTags {
+		wantRejectTags[k] = v
+	}
+	expectSuccess(t, func() error {
+		return r.ReportReplyRejected(args, rejectReasonMaxHops)
+	})
+	metricstest.AssertMetric(t, metricstest.IntMetric("event_reply_rejected_count", 1, wantRejectTags).WithResource(&resource))
+
+	// test ReportZoneDispatch
+	wantZoneTags := map[string]string{LabelDispatchZone: dispatchZoneSame}
+	for k, v := range wantTags {
+		wantZoneTags[k] = v
+	}
+	expectSuccess(t, func() error {
+		return r.ReportZoneDispatch(args, dispatchZoneSame)
+	})
+	expectSuccess(t, func() error {
+		return r.ReportZoneDispatch(args, dispatchZoneSame)
+	})
+	metricstest.AssertMetric(t, metricstest.IntMetric("event_zone_dispatch_count", 2, wantZoneTags).WithResource(&resource))
 }
 
 func TestReporterEmptySourceAndTypeFilter(t *testing.T) {
@@ -153,6 +190,10 @@ func resetMetrics() {
 	metricstest.Unregister(
 		"event_count",
 		"event_dispatch_latencies",
-		"event_processing_latencies")
+		"event_processing_latencies",
+		"event_throttled_count",
+		"event_throttle_latencies",
+		"event_reply_rejected_count",
+		"event_zone_dispatch_count")
 	register()
 }
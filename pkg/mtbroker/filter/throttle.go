@@ -0,0 +1,179 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+)
+
+const (
+	// The reasons of the throttling of the events, as reported.
+	throttleReasonRateLimit   = "rate_limit"
+	throttleReasonMaxInFlight = "max_in_flight"
+)
+
+// tokenBucket allows rate events per second, in bursts of up to rate
+// events.
+type tokenBucket struct {
+	mu   sync.Mutex
+	rate float64
+	// tokens are the available tokens, negative when the waiting events
+	// took tokens in advance.
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int32) *tokenBucket {
+	return &tokenBucket{rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+// reserve takes a token, returning how long to wait before using it.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back the token of an event not sent after all.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.rate, b.tokens+1)
+}
+
+// throttle limits the events sent to the subscriber of a Trigger.
+type throttle struct {
+	// delivery is the one the throttle was made of, to detect its changes.
+	delivery eventingv1beta1.TriggerDelivery
+	// bucket and inFlight are nil when not limited.
+	bucket   *tokenBucket
+	inFlight chan struct{}
+}
+
+func newThrottle(delivery eventingv1beta1.TriggerDelivery) *throttle {
+	t := &throttle{delivery: delivery}
+	if delivery.RateLimit != nil && *delivery.RateLimit > 0 {
+		t.bucket = newTokenBucket(*delivery.RateLimit)
+	}
+	if delivery.MaxInFlight != nil && *delivery.MaxInFlight > 0 {
+		t.inFlight = make(chan struct{}, *delivery.MaxInFlight)
+	}
+	return t
+}
+
+// wait waits for the turn of the event, calling report with the reason and
+// the duration of each wait. The returned function releases the turn once
+// the event is sent. It returns the error of ctx when done before the turn.
+func (t *throttle) wait(ctx context.Context, report func(reason string, d time.Duration)) (func(), error) {
+	if t.bucket != nil {
+		if d := t.bucket.reserve(); d > 0 {
+			report(throttleReasonRateLimit, d)
+			timer := time.NewTimer(d)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				t.bucket.cancel()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	if t.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case t.inFlight <- struct{}{}:
+	default:
+		start := time.Now()
+		select {
+		case t.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		report(throttleReasonMaxInFlight, time.Since(start))
+	}
+	return func() { <-t.inFlight }, nil
+}
+
+func sameDelivery(a, b eventingv1beta1.TriggerDelivery) bool {
+	equal := func(x, y *int32) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	return equal(a.RateLimit, b.RateLimit) && equal(a.MaxInFlight, b.MaxInFlight)
+}
+
+// throttles are the throttles of the Triggers, by UID.
+type throttles struct {
+	mu sync.Mutex
+	m  map[types.UID]*throttle
+}
+
+// get returns the throttle of the Trigger, nil when not limited. The
+// throttle is replaced when the Trigger limits change, the events already
+// sent being released from the former one.
+func (ts *throttles) get(t *eventingv1beta1.Trigger) *throttle {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if t.Spec.Delivery == nil || (t.Spec.Delivery.RateLimit == nil && t.Spec.Delivery.MaxInFlight == nil) {
+		delete(ts.m, t.UID)
+		return nil
+	}
+	if th, ok := ts.m[t.UID]; ok && sameDelivery(th.delivery, *t.Spec.Delivery) {
+		return th
+	}
+	if ts.m == nil {
+		ts.m = make(map[types.UID]*throttle)
+	}
+	th := newThrottle(*t.Spec.Delivery.DeepCopy())
+	ts.m[t.UID] = th
+	return th
+}
+
+func (ts *throttles) remove(uid types.UID) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	delete(ts.m, uid)
+}
+
+// TriggerDeleted forgets the throttle and the filters of the deleted Trigger,
+// to be registered as the delete handler of the Trigger informer.
+func (h *Handler) TriggerDeleted(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if t, ok := obj.(*eventingv1beta1.Trigger); ok {
+		h.throttles.remove(t.UID)
+		h.filters.remove(t.UID)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/utils/pointer"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(10)
+	for i := 0; i < 10; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("Expected the burst to pass, event %d waits %v", i, d)
+		}
+	}
+	if d := b.reserve(); d <= 0 || d > 100*time.Millisecond {
+		t.Errorf("Expected the event to wait up to 100ms, got %v", d)
+	}
+	b.cancel()
+	if d := b.reserve(); d <= 0 || d > 100*time.Millisecond {
+		t.Errorf("Expected the token given back, got %v", d)
+	}
+}
+
+func TestThrottleMaxInFlight(t *testing.T) {
+	th := newThrottle(eventingv1beta1.TriggerDelivery{MaxInFlight: pointer.Int32Ptr(1)})
+	var reasons []string
+	report := func(reason string, d time.Duration) {
+		reasons = append(reasons, reason)
+	}
+
+	release, err := th.wait(context.Background(), report)
+	if err != nil {
+		t.Fatal("wait =", err)
+	}
+
+	// The second event waits for the first one.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := th.wait(ctx, report); err != context.DeadlineExceeded {
+		t.Errorf("Expected the second event to time out, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	release, err = th.wait(context.Background(), report)
+	if err != nil {
+		t.Fatal("wait =", err)
+	}
+	release()
+
+	if len(reasons) != 1 || reasons[0] != throttleReasonMaxInFlight {
+		t.Errorf("Expected a single max in flight throttling reported, got %v", reasons)
+	}
+}
+
+func TestThrottleRateLimit(t *testing.T) {
+	th := newThrottle(eventingv1beta1.TriggerDelivery{RateLimit: pointer.Int32Ptr(20)})
+	var reasons []string
+	report := func(reason string, d time.Duration) {
+		reasons = append(reasons, reason)
+	}
+
+	start := time.Now()
+	for i := 0; i < 22; i++ {
+		release, err := th.wait(context.Background(), report)
+		if err != nil {
+			t.Fatal("wait =", err)
+		}
+		release()
+	}
+	// The 2 events over the burst wait for 50ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected the events to be throttled, took %v", elapsed)
+	}
+	if len(reasons) != 2 || reasons[0] != throttleReasonRateLimit {
+		t.Errorf("Expected 2 rate limit throttlings reported, got %v", reasons)
+	}
+}
+
+func TestThrottles(t *testing.T) {
+	var ts throttles
+	trigger := makeTrigger(nil)
+
+	if th := ts.get(trigger); th != nil {
+		t.Error("Expected no throttle without delivery limits")
+	}
+
+	trigger.Spec.Delivery = &eventingv1beta1.TriggerDelivery{MaxInFlight: pointer.Int32Ptr(2)}
+	th := ts.get(trigger)
+	if th == nil || th.bucket != nil || cap(th.inFlight) != 2 {
+		t.Fatalf("Unexpected throttle %#v", th)
+	}
+	if ts.get(trigger.DeepCopy()) != th {
+		t.Error("Expected the throttle to be kept while the limits don't change")
+	}
+
+	trigger.Spec.Delivery.RateLimit = pointer.Int32Ptr(5)
+	if changed := ts.get(trigger); changed == th || changed.bucket == nil {
+		t.Error("Expected the throttle to be replaced when the limits change")
+	}
+
+	ts.remove(trigger.UID)
+	if len(ts.m) != 0 {
+		t.Error("Expected the throttle to be removed")
+	}
+}
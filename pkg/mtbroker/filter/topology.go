@@ -0,0 +1,204 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1beta1"
+
+	"knative.dev/eventing/pkg/apis/feature"
+)
+
+const (
+	// The zones of the sends, relative to the zone of the filter.
+	dispatchZoneSame  = "same_zone"
+	dispatchZoneCross = "cross_zone"
+)
+
+// ZoneRouter resolves the subscriber URIs of the Kubernetes Services to
+// their ready endpoints in the zone of the filter, the sends to the other
+// zones being left to the Service.
+type ZoneRouter struct {
+	zone          string
+	serviceLister corev1listers.ServiceLister
+	sliceLister   discoverylisters.EndpointSliceLister
+	// next spreads the sends over the endpoints of the zone.
+	next uint32
+}
+
+// NewZoneRouter creates a ZoneRouter for the filters of the zone.
+func NewZoneRouter(zone string, serviceLister corev1listers.ServiceLister, sliceLister discoverylisters.EndpointSliceLister) *ZoneRouter {
+	return &ZoneRouter{
+		zone:          zone,
+		serviceLister: serviceLister,
+		sliceLister:   sliceLister,
+	}
+}
+
+// NodeZone returns the zone of the node, empty when it has none.
+func NodeZone(node *corev1.Node) string {
+	if zone := node.Labels[corev1.LabelZoneFailureDomainStable]; zone != "" {
+		return zone
+	}
+	return node.Labels[corev1.LabelZoneFailureDomain]
+}
+
+// route returns the target resolved to an endpoint of the zone of the
+// router, or nil when there is none, and the zone of the send, empty when
+// it can't be told.
+func (r *ZoneRouter) route(target *url.URL) (*url.URL, string) {
+	// The endpoints can't be verified against the certificates of the
+	// Service names.
+	if target.Scheme != "http" {
+		return nil, ""
+	}
+	name, namespace, ok := serviceOfHost(target.Hostname())
+	if !ok {
+		return nil, ""
+	}
+	port := 80
+	if p := target.Port(); p != "" {
+		var err error
+		if port, err = strconv.Atoi(p); err != nil {
+			return nil, ""
+		}
+	}
+
+	service, err := r.serviceLister.Services(namespace).Get(name)
+	if err != nil || service.Spec.Type == corev1.ServiceTypeExternalName {
+		return nil, ""
+	}
+	portName, ok := servicePortName(service, port)
+	if !ok {
+		return nil, ""
+	}
+	slices, err := r.sliceLister.EndpointSlices(namespace).List(labels.SelectorFromSet(labels.Set{
+		discoveryv1beta1.LabelServiceName: name,
+	}))
+	if err != nil {
+		return nil, ""
+	}
+
+	var same []string
+	others := false
+	for _, slice := range slices {
+		if slice.AddressType == discoveryv1beta1.AddressTypeFQDN {
+			continue
+		}
+		endpointPort, ok := sliceEndpointPort(slice, portName)
+		if !ok {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if len(endpoint.Addresses) == 0 || (endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready) {
+				continue
+			}
+			if endpoint.Topology[corev1.LabelZoneFailureDomainStable] != r.zone {
+				others = true
+				continue
+			}
+			same = append(same, net.JoinHostPort(endpoint.Addresses[0], strconv.Itoa(int(endpointPort))))
+		}
+	}
+
+	if len(same) == 0 {
+		if others {
+			return nil, dispatchZoneCross
+		}
+		return nil, ""
+	}
+	routed := *target
+	routed.Host = same[int(atomic.AddUint32(&r.next, 1)-1)%len(same)]
+	return &routed, dispatchZoneSame
+}
+
+// serviceOfHost returns the name and the namespace of the Service of the
+// host, name.namespace.svc followed or not by the cluster domain.
+func serviceOfHost(host string) (string, string, bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 || parts[2] != "svc" || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// servicePortName returns the name of the TCP port of the Service.
+func servicePortName(service *corev1.Service, port int) (string, bool) {
+	for _, p := range service.Spec.Ports {
+		if int(p.Port) == port && (p.Protocol == "" || p.Protocol == corev1.ProtocolTCP) {
+			return p.Name, true
+		}
+	}
+	return "", false
+}
+
+// sliceEndpointPort returns the number of the port of the EndpointSlice
+// named like the port of the Service.
+func sliceEndpointPort(slice *discoveryv1beta1.EndpointSlice, name string) (int32, bool) {
+	for _, p := range slice.Ports {
+		portName := ""
+		if p.Name != nil {
+			portName = *p.Name
+		}
+		if portName == name && p.Port != nil {
+			return *p.Port, true
+		}
+	}
+	return 0, false
+}
+
+// SetZoneRouter makes the handler prefer the endpoints of the zone of the
+// router when the topology-aware-dispatch feature is enabled. It must be
+// called before the handler is started.
+func (h *Handler) SetZoneRouter(router *ZoneRouter) {
+	h.zoneRouter = router
+}
+
+// UpdateFeatures applies the config-features ConfigMap, the previous
+// features being kept when it is invalid.
+func (h *Handler) UpdateFeatures(cm *corev1.ConfigMap) {
+	flags, err := feature.NewFlagsConfigFromConfigMap(cm)
+	if err != nil {
+		h.logger.Error("Invalid features, keeping the previous ones", zap.Error(err))
+		return
+	}
+	h.features.Store(flags)
+}
+
+// zoneTarget returns the target resolved to an endpoint of the zone of
+// the filter, or nil, and the zone of the send, empty when it can't be told.
+func (h *Handler) zoneTarget(target string) (*url.URL, string) {
+	flags, _ := h.features.Load().(feature.Flags)
+	if h.zoneRouter == nil || !flags.IsEnabled(feature.TopologyAwareDispatch) {
+		return nil, ""
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, ""
+	}
+	return h.zoneRouter.route(u)
+}
@@ -0,0 +1,292 @@
+/*
+ * Copyright 2019 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.uber.org/zap/zaptest"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1beta1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing/pkg/apis/feature"
+	reconcilertesting "knative.dev/eventing/pkg/reconciler/testing"
+)
+
+const (
+	subscriberService = "subscriber"
+	filterZone        = "zone-a"
+)
+
+func makeSubscriberService(ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: subscriberService},
+		Spec:       corev1.ServiceSpec{Ports: ports},
+	}
+}
+
+func makeEndpointSlice(name, port string, number int32, endpoints ...discoveryv1beta1.Endpoint) *discoveryv1beta1.EndpointSlice {
+	return &discoveryv1beta1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      name,
+			Labels:    map[string]string{discoveryv1beta1.LabelServiceName: subscriberService},
+		},
+		AddressType: discoveryv1beta1.AddressTypeIPv4,
+		Endpoints:   endpoints,
+		Ports:       []discoveryv1beta1.EndpointPort{{Name: pointer.StringPtr(port), Port: pointer.Int32Ptr(number)}},
+	}
+}
+
+func makeZoneEndpoint(address, zone string, ready bool) discoveryv1beta1.Endpoint {
+	return discoveryv1beta1.Endpoint{
+		Addresses:  []string{address},
+		Conditions: discoveryv1beta1.EndpointConditions{Ready: pointer.BoolPtr(ready)},
+		Topology:   map[string]string{corev1.LabelZoneFailureDomainStable: zone},
+	}
+}
+
+func makeZoneRouter(t *testing.T, service *corev1.Service, slices ...*discoveryv1beta1.EndpointSlice) *ZoneRouter {
+	services := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if service != nil {
+		if err := services.Add(service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	endpointSlices := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, slice := range slices {
+		if err := endpointSlices.Add(slice); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewZoneRouter(filterZone, corev1listers.NewServiceLister(services), discoverylisters.NewEndpointSliceLister(endpointSlices))
+}
+
+func TestZoneRouterRoute(t *testing.T) {
+	httpPort := corev1.ServicePort{Name: "http", Port: 80}
+	tests := map[string]struct {
+		target   string
+		service  *corev1.Service
+		slices   []*discoveryv1beta1.EndpointSlice
+		wantHost string
+		wantZone string
+	}{
+		"same zone": {
+			target:  "http://subscriber.test-namespace.svc.cluster.local/path",
+			service: makeSubscriberService(httpPort),
+			slices: []*discoveryv1beta1.EndpointSlice{
+				makeEndpointSlice("a", "http", 8080,
+					makeZoneEndpoint("10.0.0.1", "zone-b", true),
+					makeZoneEndpoint("10.0.0.2", filterZone, true)),
+			},
+			wantHost: "10.0.0.2:8080",
+			wantZone: dispatchZoneSame,
+		},
+		"same zone without cluster domain and with a port": {
+			target:  "http://subscriber.test-namespace.svc:8000",
+			service: makeSubscriberService(httpPort, corev1.ServicePort{Name: "other", Port: 8000}),
+			slices: []*discoveryv1beta1.EndpointSlice{
+				makeEndpointSlice("a", "http", 8080, makeZoneEndpoint("10.0.0.2", filterZone, true)),
+				makeEndpointSlice("b", "other", 9090, makeZoneEndpoint("10.0.0.3", filterZone, true)),
+			},
+			wantHost: "10.0.0.3:9090",
+			wantZone: dispatchZoneSame,
+		},
+		"only other zones": {
+			target:  "http://subscriber.test-namespace.svc.cluster.local",
+			service: makeSubscriberService(httpPort),
+			slices: []*discoveryv1beta1.EndpointSlice{
+				makeEndpointSlice("a", "http", 8080, makeZoneEndpoint("10.0.0.1", "zone-b", true)),
+			},
+			wantZone: dispatchZoneCross,
+		},
+		"not ready in the same zone": {
+			target:  "http://subscriber.test-namespace.svc.cluster.local",
+			service: makeSubscriberService(httpPort),
+			slices: []*discoveryv1beta1.EndpointSlice{
+				makeEndpointSlice("a", "http", 8080,
+					makeZoneEndpoint("10.0.0.1", "zone-b", true),
+					makeZoneEndpoint("10.0.0.2", filterZone, false)),
+			},
+			wantZone: dispatchZoneCross,
+		},
+		"no endpoints": {
+			target:  "http://subscriber.test-namespace.svc.cluster.local",
+			service: makeSubscriberService(httpPort),
+		},
+		"unknown service": {
+			target: "http://subscriber.test-namespace.svc.cluster.local",
+		},
+		"external name service": {
+			target: "http://subscriber.test-namespace.svc.cluster.local",
+			service: func() *corev1.Service {
+				s := makeSubscriberService(httpPort)
+				s.Spec.Type = corev1.ServiceTypeExternalName
+				return s
+			}(),
+		},
+		"unknown port": {
+			target:  "http://subscriber.test-namespace.svc.cluster.local:81",
+			service: makeSubscriberService(httpPort),
+			slices: []*discoveryv1beta1.EndpointSlice{
+				makeEndpointSlice("a", "http", 8080, makeZoneEndpoint("10.0.0.2", filterZone, true)),
+			},
+		},
+		"https": {
+			target:  "https://subscriber.test-namespace.svc.cluster.local",
+			service: makeSubscriberService(corev1.ServicePort{Name: "https", Port: 443}),
+			slices: []*discoveryv1beta1.EndpointSlice{
+				makeEndpointSlice("a", "https", 8443, makeZoneEndpoint("10.0.0.2", filterZone, true)),
+			},
+		},
+		"not a service": {
+			target: "http://example.com/subscriber",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			target, err := url.Parse(tc.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			routed, zone := makeZoneRouter(t, tc.service, tc.slices...).route(target)
+			if zone != tc.wantZone {
+				t.Errorf("Unexpected zone, wanted %q, got %q", tc.wantZone, zone)
+			}
+			if tc.wantHost == "" {
+				if routed != nil {
+					t.Errorf("Unexpected route %v", routed)
+				}
+				return
+			}
+			if routed == nil {
+				t.Fatal("Expected a route")
+			}
+			if routed.Host != tc.wantHost || routed.Path != target.Path {
+				t.Errorf("Unexpected route, wanted the host %s and the path %s, got %v", tc.wantHost, target.Path, routed)
+			}
+		})
+	}
+}
+
+func TestZoneRouterSpreadsTheSends(t *testing.T) {
+	router := makeZoneRouter(t, makeSubscriberService(corev1.ServicePort{Port: 80}),
+		makeEndpointSlice("a", "", 8080,
+			makeZoneEndpoint("10.0.0.1", filterZone, true),
+			makeZoneEndpoint("10.0.0.2", filterZone, true)))
+	target, _ := url.Parse("http://subscriber.test-namespace.svc.cluster.local")
+	hosts := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		routed, _ := router.route(target)
+		hosts[routed.Host] = true
+	}
+	if len(hosts) != 2 {
+		t.Errorf("Expected the sends to be spread over the 2 endpoints, got %v", hosts)
+	}
+}
+
+func TestNodeZone(t *testing.T) {
+	for want, l := range map[string]map[string]string{
+		"stable": {corev1.LabelZoneFailureDomainStable: "stable", corev1.LabelZoneFailureDomain: "beta"},
+		"beta":   {corev1.LabelZoneFailureDomain: "beta"},
+		"":       nil,
+	} {
+		if got := NodeZone(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: l}}); got != want {
+			t.Errorf("NodeZone(%v) = %q, want %q", l, got, want)
+		}
+	}
+}
+
+func TestReceiverTopologyAwareDispatch(t *testing.T) {
+	for name, enabled := range map[string]bool{"enabled": true, "disabled": false} {
+		t.Run(name, func(t *testing.T) {
+			var gotHost string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotHost = r.Host
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer s.Close()
+			serverURL, err := apis.ParseURL(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			endpoint, port, _ := net.SplitHostPort(serverURL.Host)
+
+			// The subscriber Service only resolves to the test server
+			// through the router.
+			trigger := makeTrigger(makeTriggerFilterWithAttributes("", ""))
+			trigger.Status.SubscriberURI = apis.HTTP("subscriber.test-namespace.svc.cluster.local")
+			listers := reconcilertesting.NewListers([]runtime.Object{trigger})
+			reporter := &mockReporter{}
+			h, err := NewHandler(zaptest.NewLogger(t), listers.GetV1Beta1TriggerLister(), reporter, 8080)
+			if err != nil {
+				t.Fatal("Unable to create receiver:", err)
+			}
+			number, _ := strconv.Atoi(port)
+			h.SetZoneRouter(makeZoneRouter(t, makeSubscriberService(corev1.ServicePort{Name: "http", Port: 80}),
+				makeEndpointSlice("a", "http", int32(number), makeZoneEndpoint(endpoint, filterZone, true))))
+			state := feature.Disabled
+			if enabled {
+				state = feature.Enabled
+			}
+			h.UpdateFeatures(&corev1.ConfigMap{Data: map[string]string{feature.TopologyAwareDispatch: string(state)}})
+
+			b, err := makeEvent().MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := httptest.NewRequest(http.MethodPost, validPath, bytes.NewBuffer(b))
+			request.Header.Set(cehttp.ContentType, event.ApplicationCloudEventsJSON)
+			responseWriter := httptest.NewRecorder()
+			h.ServeHTTP(responseWriter, request)
+
+			if !enabled {
+				if reporter.dispatchZone != "" {
+					t.Errorf("Unexpected dispatch zone %q", reporter.dispatchZone)
+				}
+				if gotHost != "" {
+					t.Error("Unexpected send to the endpoint")
+				}
+				return
+			}
+			if got := responseWriter.Result().StatusCode; got != http.StatusAccepted {
+				t.Errorf("Unexpected status. Expected %v. Actual %v.", http.StatusAccepted, got)
+			}
+			if reporter.dispatchZone != dispatchZoneSame {
+				t.Errorf("Unexpected dispatch zone, wanted %q, got %q", dispatchZoneSame, reporter.dispatchZone)
+			}
+			if gotHost != "subscriber.test-namespace.svc.cluster.local" {
+				t.Errorf("Unexpected host %q, wanted the host of the Service", gotHost)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/attributes"
+	"knative.dev/eventing/pkg/eventfilter/subscriptionsapi"
+)
+
+// triggerFilters holds the filters of the Triggers, built once per generation
+// of the Triggers for their CloudEvents SQL expressions not to be parsed for
+// each event.
+type triggerFilters struct {
+	mu sync.Mutex
+	m  map[types.UID]*triggerFilter
+}
+
+type triggerFilter struct {
+	generation int64
+	filter     eventfilter.Filter
+	// err is the error building the filter, the expressions being invalid.
+	err error
+}
+
+// get returns the filter of the Trigger, built again when its generation
+// changes, or the error building it.
+func (fs *triggerFilters) get(t *eventingv1beta1.Trigger) (eventfilter.Filter, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if f, ok := fs.m[t.UID]; ok && f.generation == t.Generation {
+		return f.filter, f.err
+	}
+	if fs.m == nil {
+		fs.m = make(map[types.UID]*triggerFilter)
+	}
+	filter, err := newTriggerFilter(t.Spec)
+	fs.m[t.UID] = &triggerFilter{generation: t.Generation, filter: filter, err: err}
+	return filter, err
+}
+
+func (fs *triggerFilters) remove(uid types.UID) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	delete(fs.m, uid)
+}
+
+// newTriggerFilter returns the filter of the events passing the filter and
+// the filters of the Trigger.
+func newTriggerFilter(spec eventingv1beta1.TriggerSpec) (eventfilter.Filter, error) {
+	var filters eventfilter.Filters
+	// The exact match of the attributes is the cheapest, run it first.
+	if spec.Filter != nil && len(spec.Filter.Attributes) != 0 {
+		filters = append(filters, attributes.NewAttributesFilter(spec.Filter.Attributes))
+	}
+	if len(spec.Filters) != 0 {
+		f, err := subscriptionsapi.NewFilters(spec.Filters)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
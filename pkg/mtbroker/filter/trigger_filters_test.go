@@ -0,0 +1,73 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/eventing/pkg/eventfilter"
+)
+
+func TestTriggerFilters(t *testing.T) {
+	var fs triggerFilters
+	trigger := makeTrigger(nil)
+	trigger.Generation = 1
+	trigger.Spec.Filters = []eventingv1beta1.SubscriptionsAPIFilter{{CESQL: "type = 'a'"}}
+
+	event := cloudevents.NewEvent()
+	event.SetType("a")
+	filter := func(trigger *eventingv1beta1.Trigger) eventfilter.FilterResult {
+		t.Helper()
+		f, err := fs.get(trigger)
+		if err != nil {
+			t.Fatal("Unexpected error building the filter:", err)
+		}
+		return f.Filter(context.Background(), event)
+	}
+
+	if got := filter(trigger); got != eventfilter.PassFilter {
+		t.Errorf("Expected the event to pass, got %v", got)
+	}
+	built := fs.m[trigger.UID]
+	if filter(trigger.DeepCopy()); fs.m[trigger.UID] != built {
+		t.Error("Expected the filter to be kept while the generation doesn't change")
+	}
+
+	trigger.Generation = 2
+	trigger.Spec.Filters[0].CESQL = "type = 'b'"
+	if got := filter(trigger); got != eventfilter.FailFilter {
+		t.Errorf("Expected the filter to be built again for the new generation, got %v", got)
+	}
+
+	trigger.Generation = 3
+	trigger.Spec.Filters[0].CESQL = "type ="
+	if _, err := fs.get(trigger); err == nil {
+		t.Error("Expected an error for an invalid expression")
+	}
+	if _, err := fs.get(trigger); err == nil {
+		t.Error("Expected the error to be kept for the generation")
+	}
+
+	fs.remove(trigger.UID)
+	if len(fs.m) != 0 {
+		t.Error("Expected the filter to be removed")
+	}
+}
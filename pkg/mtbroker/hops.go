@@ -0,0 +1,52 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package broker
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cetypes "github.com/cloudevents/sdk-go/v2/types"
+)
+
+const (
+	// HopsAttribute is the name of the CloudEvents extension attribute used to
+	// count the replies an event descends from, for the Broker filter to
+	// reject the replies of the loops exceeding its maximum.
+	HopsAttribute = "knativebrokerhops"
+)
+
+// GetHops returns the hop count of the EventContext, 0 when it has none.
+func GetHops(ctx cloudevents.EventContext) int32 {
+	value, err := ctx.GetExtension(HopsAttribute)
+	if err != nil {
+		return 0
+	}
+	hops, err := cetypes.ToInteger(value)
+	if err != nil || hops < 0 {
+		return 0
+	}
+	return hops
+}
+
+// SetHops sets the hop count into the EventContext.
+func SetHops(ctx cloudevents.EventContext, hops int32) error {
+	return ctx.SetExtension(HopsAttribute, hops)
+}
+
+// DeleteHops removes the hop count CE extension attribute.
+func DeleteHops(ctx cloudevents.EventContext) error {
+	return ctx.SetExtension(HopsAttribute, nil)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2019 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package broker
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestHops(t *testing.T) {
+	tests := map[string]struct {
+		event cloudevents.Event
+		want  int32
+	}{
+		"no hops": {
+			event: cloudevents.NewEvent(),
+			want:  0,
+		},
+		"hops of 3": {
+			event: func() cloudevents.Event {
+				event := cloudevents.NewEvent()
+				_ = SetHops(event.Context, 3)
+				return event
+			}(),
+			want: 3,
+		},
+		"string hops of '5'": {
+			event: func() cloudevents.Event {
+				event := cloudevents.NewEvent()
+				event.SetExtension(HopsAttribute, "5")
+				return event
+			}(),
+			want: 5,
+		},
+		"invalid hops of 'XYZ'": {
+			event: func() cloudevents.Event {
+				event := cloudevents.NewEvent()
+				event.SetExtension(HopsAttribute, "XYZ")
+				return event
+			}(),
+			want: 0,
+		},
+		"negative hops": {
+			event: func() cloudevents.Event {
+				event := cloudevents.NewEvent()
+				_ = SetHops(event.Context, -2)
+				return event
+			}(),
+			want: 0,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := GetHops(tc.event.Context); got != tc.want {
+				t.Errorf("Unexpected hops, wanted %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDeleteHops(t *testing.T) {
+	event := cloudevents.NewEvent()
+	_ = SetHops(event.Context, 3)
+	if err := DeleteHops(event.Context); err != nil {
+		t.Fatal("DeleteHops() =", err)
+	}
+	if _, ok := event.Extensions()[HopsAttribute]; ok {
+		t.Error("Expected the hops to be deleted")
+	}
+}
@@ -58,6 +58,12 @@ type Handler struct {
 	Reporter StatsReporter
 	// BrokerLister gets broker objects
 	BrokerLister eventinglisters.BrokerLister
+	// Replies correlates the events sent in request-reply mode with their
+	// replies, the mode being disabled when nil
+	Replies *Replies
+	// Recording records the events sent to the recording Brokers, the
+	// recording being disabled when nil
+	Recording *Recording
 
 	Logger *zap.Logger
 }
@@ -157,6 +163,18 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		eventType: event.Type(),
 	}
 
+	if h.Replies != nil {
+		if strings.EqualFold(request.Header.Get(RequestReplyHeader), "true") {
+			h.serveRequestReply(ctx, writer, request.Header, event, reporterArgs)
+			return
+		}
+		if h.receiveReply(ctx, request, event) {
+			_ = h.Reporter.ReportEventCount(reporterArgs, http.StatusAccepted)
+			writer.WriteHeader(http.StatusAccepted)
+			return
+		}
+	}
+
 	statusCode, dispatchTime := h.receive(ctx, request.Header, event, brokerNamespace, brokerName)
 	if dispatchTime > noDuration {
 		_ = h.Reporter.ReportEventDispatchTime(reporterArgs, statusCode, dispatchTime)
@@ -166,6 +184,69 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	writer.WriteHeader(statusCode)
 }
 
+// serveRequestReply sends the event to the broker and responds with the first
+// reply correlated with it, or with a gateway timeout when none comes in time.
+func (h *Handler) serveRequestReply(ctx context.Context, writer http.ResponseWriter, headers http.Header, event *cloudevents.Event, reporterArgs *ReportArgs) {
+	id := h.Replies.newID()
+	if err := broker.SetReplyID(event.Context, id); err != nil {
+		h.Logger.Warn("failed to set the reply id", zap.Error(err))
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	replies, cancel := h.Replies.wait(id)
+	defer cancel()
+
+	// The replies sent back to the ingress mustn't be taken for requests.
+	headers = headers.Clone()
+	headers.Del(RequestReplyHeader)
+
+	statusCode, dispatchTime := h.receive(ctx, headers, event, reporterArgs.ns, reporterArgs.broker)
+	if dispatchTime > noDuration {
+		_ = h.Reporter.ReportEventDispatchTime(reporterArgs, statusCode, dispatchTime)
+	}
+	_ = h.Reporter.ReportEventCount(reporterArgs, statusCode)
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		writer.WriteHeader(statusCode)
+		return
+	}
+
+	timer := time.NewTimer(h.Replies.timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-replies:
+		_ = broker.DeleteReplyID(reply.Context)
+		_ = broker.DeleteTTL(reply.Context)
+		message := binding.ToMessage(&reply)
+		defer message.Finish(nil)
+		if err := cehttp.WriteResponseWriter(ctx, message, http.StatusOK, writer); err != nil {
+			h.Logger.Warn("failed to write the reply", zap.String("replyid", id), zap.Error(err))
+		}
+	case <-timer.C:
+		h.Logger.Debug("no reply in time", zap.String("replyid", id))
+		writer.WriteHeader(http.StatusGatewayTimeout)
+	case <-ctx.Done():
+	}
+}
+
+// receiveReply hands the event to the caller waiting for it when it is a reply,
+// forwarding it to the replica of the caller when needed. It returns false when
+// no caller waits for the event, which is then sent to the broker as usual.
+func (h *Handler) receiveReply(ctx context.Context, request *http.Request, event *cloudevents.Event) bool {
+	id, ok := broker.GetReplyID(event.Context)
+	if !ok {
+		return false
+	}
+	if address, remote := h.Replies.owner(id); remote {
+		statusCode, _ := h.send(ctx, request.Header, event, fmt.Sprintf("http://%s%s", address, request.RequestURI))
+		if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+			h.Logger.Warn("failed to forward the reply", zap.String("replyid", id), zap.Int("status", statusCode))
+			return false
+		}
+		return true
+	}
+	return h.Replies.deliver(id, *event)
+}
+
 func (h *Handler) receive(ctx context.Context, headers http.Header, event *cloudevents.Event, brokerNamespace, brokerName string) (int, time.Duration) {
 
 	// Setting the extension as a string as the CloudEvents sdk does not support non-string extensions.
@@ -186,7 +267,19 @@ func (h *Handler) receive(ctx context.Context, headers http.Header, event *cloud
 		channelAddress = guessChannelAddress(brokerName, brokerNamespace, network.GetClusterDomainName())
 	}
 
-	return h.send(ctx, headers, event, channelAddress)
+	statusCode, dispatchTime := h.send(ctx, headers, event, channelAddress)
+	if h.Recording != nil && statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices && h.isRecording(brokerName, brokerNamespace) {
+		if !h.Recording.record(types.NamespacedName{Namespace: brokerNamespace, Name: brokerName}, *event) {
+			h.Logger.Debug("not recording the event, too many brokers are recorded", zap.String("event.id", event.ID()))
+		}
+	}
+	return statusCode, dispatchTime
+}
+
+// isRecording returns whether the events sent to the Broker are recorded.
+func (h *Handler) isRecording(name, namespace string) bool {
+	broker, err := h.BrokerLister.Brokers(namespace).Get(name)
+	return err == nil && strings.EqualFold(broker.Annotations[eventing.BrokerRecordingAnnotationKey], "true")
 }
 
 func (h *Handler) send(ctx context.Context, headers http.Header, event *cloudevents.Event, target string) (int, time.Duration) {
@@ -18,12 +18,15 @@ package ingress
 
 import (
 	"bytes"
+	"context"
 	"io"
 	nethttp "net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/client"
 	"github.com/cloudevents/sdk-go/v2/event"
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
@@ -246,6 +249,166 @@ func TestHandler_ServeHTTP(t *testing.T) {
 	}
 }
 
+func TestHandler_RequestReply(t *testing.T) {
+	logger := zap.NewNop()
+
+	tt := []struct {
+		name       string
+		reply      bool
+		statusCode int
+	}{{
+		name:       "reply",
+		reply:      true,
+		statusCode: nethttp.StatusOK,
+	}, {
+		name:       "no reply in time",
+		statusCode: nethttp.StatusGatewayTimeout,
+	}}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sender, _ := kncloudevents.NewHTTPMessageSenderWithTarget("")
+			b := makeBroker("name", "ns")
+			h := &Handler{
+				Sender:    sender,
+				Defaulter: broker.TTLDefaulter(logger, 100),
+				Reporter:  &mockReporter{},
+				Logger:    logger,
+				Replies:   NewReplies("", nil, nil, 100*time.Millisecond),
+			}
+			// The replies reach another handler of the replica.
+			ingress := httptest.NewServer(&Handler{
+				Sender:   sender,
+				Reporter: &mockReporter{},
+				Logger:   logger,
+				Replies:  h.Replies,
+			})
+			defer ingress.Close()
+
+			// The channel replies to the event through the ingress, like the
+			// Trigger's subscriber would.
+			channel := httptest.NewServer(nethttp.HandlerFunc(func(writer nethttp.ResponseWriter, request *nethttp.Request) {
+				if request.Header.Get(RequestReplyHeader) != "" {
+					t.Errorf("Unexpected %s header sent to the channel", RequestReplyHeader)
+				}
+				e, err := binding.ToEvent(request.Context(), cehttp.NewMessageFromHttpRequest(request))
+				if err != nil {
+					t.Error("Failed to read the event:", err)
+				}
+				writer.WriteHeader(senderResponseStatusCode)
+				if !tc.reply || e == nil {
+					return
+				}
+				id, ok := broker.GetReplyID(e.Context)
+				if !ok {
+					t.Error("Missing reply id")
+				}
+				go func() {
+					reply := event.New()
+					reply.SetType("reply")
+					reply.SetSource("subscriber")
+					reply.SetID("5678")
+					_ = broker.SetTTL(reply.Context, 99)
+					_ = broker.SetReplyID(reply.Context, id)
+					if statusCode := sendEvent(t, ingress.URL+"/ns/name", reply); statusCode != nethttp.StatusAccepted {
+						t.Errorf("expected status code %d for the reply got %d", nethttp.StatusAccepted, statusCode)
+					}
+				}()
+			}))
+			defer channel.Close()
+
+			b.Status.Annotations = map[string]string{
+				eventing.BrokerChannelAddressStatusAnnotationKey: channel.URL,
+			}
+			listers := reconcilertestingv1.NewListers([]runtime.Object{b})
+			h.BrokerLister = listers.GetBrokerLister()
+
+			request := httptest.NewRequest(nethttp.MethodPost, "/ns/name", getValidEvent())
+			request.Header.Add(cehttp.ContentType, event.ApplicationCloudEventsJSON)
+			request.Header.Add(RequestReplyHeader, "true")
+			recorder := httptest.NewRecorder()
+			h.ServeHTTP(recorder, request)
+
+			result := recorder.Result()
+			if result.StatusCode != tc.statusCode {
+				t.Fatalf("expected status code %d got %d", tc.statusCode, result.StatusCode)
+			}
+			if !tc.reply {
+				return
+			}
+			reply, err := binding.ToEvent(context.Background(), cehttp.NewMessageFromHttpResponse(result))
+			if err != nil {
+				t.Fatal("Failed to read the reply:", err)
+			}
+			if reply.Type() != "reply" {
+				t.Errorf("expected the reply event got %v", reply)
+			}
+			if _, ok := reply.Extensions()[broker.ReplyIDAttribute]; ok {
+				t.Errorf("Unexpected %s extension in the reply", broker.ReplyIDAttribute)
+			}
+		})
+	}
+}
+
+func TestHandler_ForwardReply(t *testing.T) {
+	logger := zap.NewNop()
+
+	var forwarded string
+	owner := httptest.NewServer(nethttp.HandlerFunc(func(writer nethttp.ResponseWriter, request *nethttp.Request) {
+		forwarded = request.RequestURI
+		writer.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer owner.Close()
+	ownerURL, _ := url.Parse(owner.URL)
+
+	sender, _ := kncloudevents.NewHTTPMessageSenderWithTarget("")
+	h := &Handler{
+		Sender:    sender,
+		Defaulter: broker.TTLDefaulter(logger, 100),
+		Reporter:  &mockReporter{},
+		Logger:    logger,
+		Replies:   NewReplies("ingress-a", []byte("key"), replicas{"ingress-b": ownerURL.Host}, time.Second),
+	}
+	// The reply id is the one of the replica waiting for the reply.
+	id := NewReplies("ingress-b", []byte("key"), nil, time.Second).newID()
+
+	reply := event.New()
+	reply.SetType("reply")
+	reply.SetSource("subscriber")
+	reply.SetID("5678")
+	_ = broker.SetReplyID(reply.Context, id)
+	b, _ := reply.MarshalJSON()
+	request := httptest.NewRequest(nethttp.MethodPost, "/ns/name", bytes.NewBuffer(b))
+	request.Header.Add(cehttp.ContentType, event.ApplicationCloudEventsJSON)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, request)
+
+	if result := recorder.Result(); result.StatusCode != nethttp.StatusAccepted {
+		t.Errorf("expected status code %d got %d", nethttp.StatusAccepted, result.StatusCode)
+	}
+	if forwarded != "/ns/name" {
+		t.Errorf("expected the reply to be forwarded to /ns/name got %q", forwarded)
+	}
+}
+
+func sendEvent(t *testing.T, target string, e event.Event) int {
+	t.Helper()
+	b, err := e.MarshalJSON()
+	if err != nil {
+		t.Error("Failed to marshal the event:", err)
+		return 0
+	}
+	request, _ := nethttp.NewRequest(nethttp.MethodPost, target, bytes.NewBuffer(b))
+	request.Header.Set(cehttp.ContentType, event.ApplicationCloudEventsJSON)
+	resp, err := nethttp.DefaultClient.Do(request)
+	if err != nil {
+		t.Error("Failed to send the event:", err)
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
 type svc struct {
 	receivedHeaders nethttp.Header
 }
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Recording holds the last events received by the recording Brokers, for them
+// to be replayed. Up to maxBrokers Brokers are recorded, each keeping up to
+// maxEvents events, none older than the retention.
+type Recording struct {
+	maxBrokers int
+	maxEvents  int
+	retention  time.Duration
+	now        func() time.Time
+
+	mu     sync.Mutex
+	events map[types.NamespacedName][]recordedEvent
+}
+
+type recordedEvent struct {
+	at    time.Time
+	event cloudevents.Event
+}
+
+// NewRecording returns a Recording keeping up to maxEvents events per Broker,
+// received within retention, for up to maxBrokers Brokers.
+func NewRecording(maxBrokers, maxEvents int, retention time.Duration) *Recording {
+	return &Recording{
+		maxBrokers: maxBrokers,
+		maxEvents:  maxEvents,
+		retention:  retention,
+		now:        time.Now,
+		events:     make(map[types.NamespacedName][]recordedEvent),
+	}
+}
+
+// record records the event received by the Broker, evicting the oldest events
+// beyond the bounds. The events of a Broker not recorded yet are dropped while
+// maxBrokers Brokers have recorded events, returning false.
+func (r *Recording) record(broker types.NamespacedName, event cloudevents.Event) bool {
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.events[broker]; !ok && len(r.events) >= r.maxBrokers {
+		for b := range r.events {
+			r.evict(b, now)
+		}
+		if len(r.events) >= r.maxBrokers {
+			return false
+		}
+	}
+	events := append(r.evict(broker, now), recordedEvent{at: now, event: event.Clone()})
+	if len(events) > r.maxEvents {
+		// Not to keep the evicted events referenced by the array.
+		events = append([]recordedEvent(nil), events[len(events)-r.maxEvents:]...)
+	}
+	r.events[broker] = events
+	return true
+}
+
+// received returns the events received by the Broker from start, included, to
+// end, excluded, the oldest first.
+func (r *Recording) received(broker types.NamespacedName, start, end time.Time) []cloudevents.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []cloudevents.Event
+	for _, re := range r.evict(broker, r.now()) {
+		if !re.at.Before(start) && re.at.Before(end) {
+			events = append(events, re.event)
+		}
+	}
+	return events
+}
+
+// evict evicts the events of the Broker older than the retention, returning
+// the remaining ones. r.mu must be held.
+func (r *Recording) evict(broker types.NamespacedName, now time.Time) []recordedEvent {
+	events := r.events[broker]
+	i := 0
+	for i < len(events) && now.Sub(events[i].at) > r.retention {
+		i++
+	}
+	events = events[i:]
+	if len(events) == 0 {
+		delete(r.events, broker)
+		return nil
+	}
+	r.events[broker] = events
+	return events
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRecording(t *testing.T) {
+	r := NewRecording(2, 3, time.Minute)
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	b := types.NamespacedName{Namespace: "ns", Name: "name"}
+	other := types.NamespacedName{Namespace: "ns", Name: "other"}
+
+	start := now
+	for i := 0; i < 4; i++ {
+		e := event.New()
+		e.SetType("type")
+		e.SetSource("source")
+		e.SetID(strconv.Itoa(i))
+		r.record(b, e)
+		now = now.Add(10 * time.Second)
+	}
+	r.record(other, event.New())
+	third := types.NamespacedName{Namespace: "ns", Name: "third"}
+	if r.record(third, event.New()) {
+		t.Error("expected the events of a third broker not to be recorded")
+	}
+
+	ids := func(events []event.Event) []string {
+		var ids []string
+		for _, e := range events {
+			ids = append(ids, e.ID())
+		}
+		return ids
+	}
+
+	// The first event is evicted beyond 3 events.
+	if got := ids(r.received(b, start, now)); len(got) != 3 || got[0] != "1" || got[2] != "3" {
+		t.Errorf("expected the events 1 to 3 got %v", got)
+	}
+	// The end is excluded.
+	if got := ids(r.received(b, start, start.Add(30*time.Second))); len(got) != 2 || got[1] != "2" {
+		t.Errorf("expected the events 1 and 2 got %v", got)
+	}
+
+	// The events older than the retention are evicted.
+	now = start.Add(time.Minute + 25*time.Second)
+	if got := ids(r.received(b, start, now)); len(got) != 1 || got[0] != "3" {
+		t.Errorf("expected the event 3 got %v", got)
+	}
+	now = now.Add(time.Hour)
+	if got := r.received(b, start, now); len(got) != 0 {
+		t.Errorf("expected no events got %v", ids(got))
+	}
+	if got := r.received(other, start, now); len(got) != 0 {
+		t.Errorf("expected no events for the other broker got %v", ids(got))
+	}
+	// The brokers without events left are forgotten.
+	if !r.record(third, event.New()) {
+		t.Error("expected the events of the third broker to be recorded once the others expired")
+	}
+	r.record(b, event.New())
+	now = now.Add(time.Hour)
+	if !r.record(other, event.New()) {
+		t.Error("expected the brokers with expired events to be evicted")
+	}
+}
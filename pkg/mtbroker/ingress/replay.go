@@ -0,0 +1,368 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/eventing/pkg/apis/eventing"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1"
+	"knative.dev/eventing/pkg/eventfilter"
+	"knative.dev/eventing/pkg/eventfilter/attributes"
+	"knative.dev/eventing/pkg/kncloudevents"
+	"knative.dev/eventing/pkg/reconciler/sugar/trigger/path"
+)
+
+const (
+	// ReplayJobExtension is the extension the replayed events carry, set to
+	// the ID of their ReplayJob.
+	ReplayJobExtension = "knativereplayjob"
+
+	// maxReplayJobs is the number of ReplayJobs kept, the oldest finished ones
+	// being forgotten beyond it.
+	maxReplayJobs = 100
+
+	// ReplaySubresource is the subresource of the Brokers the callers are
+	// authorized for: create to start a ReplayJob, get to read its status.
+	ReplaySubresource = "replay"
+)
+
+// ReplayRequest requests the events recorded for a Broker to be replayed to
+// one of its Triggers.
+type ReplayRequest struct {
+	// Trigger is the name of the Trigger of the Broker the events are
+	// replayed to.
+	Trigger string `json:"trigger"`
+	// Start is the time the first events replayed were received at.
+	Start time.Time `json:"start"`
+	// End is the time the events replayed were received before, defaulting
+	// to the time of the request.
+	End time.Time `json:"end,omitempty"`
+	// Filter selects the events replayed by exact match of their attributes,
+	// like the Trigger filter.
+	Filter map[string]string `json:"filter,omitempty"`
+}
+
+// ReplayJobPhase is the phase of a ReplayJob.
+type ReplayJobPhase string
+
+const (
+	ReplayJobRunning   ReplayJobPhase = "Running"
+	ReplayJobSucceeded ReplayJobPhase = "Succeeded"
+	ReplayJobFailed    ReplayJobPhase = "Failed"
+)
+
+// ReplayJobStatus reports the progress of a ReplayJob.
+type ReplayJobStatus struct {
+	ID      string `json:"id"`
+	Broker  string `json:"broker"`
+	Trigger string `json:"trigger"`
+	// Replica is the broker-ingress replica running the job, the only one
+	// serving its status.
+	Replica string `json:"replica"`
+
+	Phase ReplayJobPhase `json:"phase"`
+	// Matched is the number of recorded events matching the request.
+	Matched int `json:"matched"`
+	// Sent is the number of events successfully sent to the Trigger.
+	Sent int `json:"sent"`
+	// Failed is the number of events failing to be sent to the Trigger.
+	Failed int `json:"failed"`
+
+	StartTime      time.Time  `json:"startTime"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+}
+
+// ReplayHandler serves the replays of the events recorded for the Brokers:
+// the POST requests to /replay/{namespace}/{broker} start a ReplayJob, whose
+// status is served to the GET requests to /replay/{namespace}/{broker}/{id}.
+// The events are sent to the Triggers through the broker filter, for them to
+// be filtered and delivered like the events sent to the Broker.
+//
+// The requests carry the bearer token of a caller authorized to create, or
+// get, the replay subresource of the Broker. The recordings and the jobs are
+// the ones of the replica: the status of a job is only served by the replica
+// running it.
+type ReplayHandler struct {
+	// Recording holds the recorded events.
+	Recording *Recording
+	// Sender sends the events to the broker filter.
+	Sender *kncloudevents.HTTPMessageSender
+	// TriggerLister gets the Triggers the events are replayed to.
+	TriggerLister eventinglisters.TriggerLister
+	// FilterHost is the host of the broker filter.
+	FilterHost string
+	// KubeClient reviews the tokens of the callers and their access to the
+	// Brokers.
+	KubeClient kubernetes.Interface
+	// Replica is the name of the replica, reported in the status of its jobs.
+	Replica string
+
+	Logger *zap.Logger
+
+	mu   sync.Mutex
+	jobs map[string]*ReplayJobStatus
+}
+
+func (h *ReplayHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(request.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "replay" {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	broker := types.NamespacedName{Namespace: parts[1], Name: parts[2]}
+
+	switch {
+	case len(parts) == 3 && request.Method == http.MethodPost:
+		if h.authorize(writer, request, broker, "create") {
+			h.startReplay(writer, request, broker)
+		}
+	case len(parts) == 4 && request.Method == http.MethodGet:
+		if !h.authorize(writer, request, broker, "get") {
+			return
+		}
+		h.mu.Lock()
+		job, ok := h.jobs[parts[3]]
+		var status ReplayJobStatus
+		if ok && job.Broker == broker.String() {
+			status = *job
+		}
+		h.mu.Unlock()
+		if status.ID == "" {
+			http.Error(writer, fmt.Sprintf("replay job %s not found on replica %s", parts[3], h.Replica), http.StatusNotFound)
+			return
+		}
+		writeReplayJob(writer, http.StatusOK, status)
+	case len(parts) == 3 || len(parts) == 4:
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// authorize reviews the bearer token of the request and the access of its user
+// to the verb on the replay subresource of the Broker, writing the response
+// and returning false when the request is not authorized.
+func (h *ReplayHandler) authorize(writer http.ResponseWriter, request *http.Request, broker types.NamespacedName, verb string) bool {
+	auth := request.Header.Get("Authorization")
+	token := strings.TrimPrefix(auth, "Bearer ")
+	if token == "" || token == auth {
+		http.Error(writer, "a bearer token is required", http.StatusUnauthorized)
+		return false
+	}
+
+	ctx := request.Context()
+	review, err := h.KubeClient.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		h.Logger.Warn("failed to review the token", zap.Error(err))
+		writer.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !review.Status.Authenticated {
+		http.Error(writer, "invalid bearer token", http.StatusUnauthorized)
+		return false
+	}
+
+	user := review.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	sar, err := h.KubeClient.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   broker.Namespace,
+				Verb:        verb,
+				Group:       eventing.GroupName,
+				Resource:    "brokers",
+				Subresource: ReplaySubresource,
+				Name:        broker.Name,
+			},
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		h.Logger.Warn("failed to review the access to the broker", zap.Error(err))
+		writer.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	if !sar.Status.Allowed {
+		http.Error(writer, fmt.Sprintf("user %s cannot %s brokers/%s of broker %s", user.Username, verb, ReplaySubresource, broker),
+			http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (h *ReplayHandler) startReplay(writer http.ResponseWriter, request *http.Request, broker types.NamespacedName) {
+	var replay ReplayRequest
+	if err := json.NewDecoder(request.Body).Decode(&replay); err != nil {
+		http.Error(writer, fmt.Sprint("invalid replay request: ", err), http.StatusBadRequest)
+		return
+	}
+	if replay.End.IsZero() {
+		replay.End = time.Now()
+	}
+	if replay.Trigger == "" || replay.Start.IsZero() || !replay.Start.Before(replay.End) {
+		http.Error(writer, "invalid replay request: a trigger and a start before the end are required", http.StatusBadRequest)
+		return
+	}
+
+	trigger, err := h.TriggerLister.Triggers(broker.Namespace).Get(replay.Trigger)
+	if apierrs.IsNotFound(err) {
+		http.Error(writer, fmt.Sprintf("trigger %s/%s not found", broker.Namespace, replay.Trigger), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.Logger.Warn("failed to get the trigger", zap.Error(err))
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if trigger.Spec.Broker != broker.Name {
+		http.Error(writer, fmt.Sprintf("trigger %s/%s isn't a trigger of broker %s", broker.Namespace, replay.Trigger, broker.Name), http.StatusBadRequest)
+		return
+	}
+
+	filter := attributes.NewAttributesFilter(replay.Filter)
+	var events []cloudevents.Event
+	for _, event := range h.Recording.received(broker, replay.Start, replay.End) {
+		if filter.Filter(request.Context(), event) != eventfilter.FailFilter {
+			events = append(events, event)
+		}
+	}
+
+	job := &ReplayJobStatus{
+		ID:        uuid.New().String(),
+		Broker:    broker.String(),
+		Trigger:   replay.Trigger,
+		Replica:   h.Replica,
+		Phase:     ReplayJobRunning,
+		Matched:   len(events),
+		StartTime: time.Now(),
+	}
+	h.mu.Lock()
+	h.addJob(job)
+	status := *job
+	h.mu.Unlock()
+
+	target := fmt.Sprintf("http://%s%s", h.FilterHost, path.Generate(trigger))
+	go h.replay(job, events, target)
+
+	writeReplayJob(writer, http.StatusAccepted, status)
+}
+
+// addJob adds the job, forgetting the oldest finished ones beyond
+// maxReplayJobs. h.mu must be held.
+func (h *ReplayHandler) addJob(job *ReplayJobStatus) {
+	if h.jobs == nil {
+		h.jobs = make(map[string]*ReplayJobStatus)
+	}
+	h.jobs[job.ID] = job
+
+	var finished []*ReplayJobStatus
+	for _, j := range h.jobs {
+		if j.CompletionTime != nil {
+			finished = append(finished, j)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CompletionTime.Before(*finished[j].CompletionTime)
+	})
+	for i := 0; len(h.jobs) > maxReplayJobs && i < len(finished); i++ {
+		delete(h.jobs, finished[i].ID)
+	}
+}
+
+// replay sends the events to target one at a time, in the order they were
+// received, updating the progress of the job.
+func (h *ReplayHandler) replay(job *ReplayJobStatus, events []cloudevents.Event, target string) {
+	logger := h.Logger.With(zap.String("replayjob", job.ID), zap.String("trigger", job.Broker+"/"+job.Trigger))
+	for _, event := range events {
+		event = event.Clone()
+		event.SetExtension(ReplayJobExtension, job.ID)
+		err := h.send(context.Background(), event, target)
+		if err != nil {
+			logger.Warn("failed to replay the event", zap.String("event.id", event.ID()), zap.Error(err))
+		}
+		h.mu.Lock()
+		if err != nil {
+			job.Failed++
+		} else {
+			job.Sent++
+		}
+		h.mu.Unlock()
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	job.CompletionTime = &now
+	job.Phase = ReplayJobSucceeded
+	if job.Failed > 0 {
+		job.Phase = ReplayJobFailed
+	}
+	h.mu.Unlock()
+	logger.Info("replay finished", zap.Int("sent", job.Sent), zap.Int("failed", job.Failed))
+}
+
+func (h *ReplayHandler) send(ctx context.Context, event cloudevents.Event, target string) error {
+	request, err := h.Sender.NewCloudEventRequestWithTarget(ctx, target)
+	if err != nil {
+		return err
+	}
+	message := binding.ToMessage(&event)
+	defer message.Finish(nil)
+	if err := kncloudevents.WriteHTTPRequestWithAdditionalHeaders(ctx, message, request, nil); err != nil {
+		return err
+	}
+	resp, err := h.Sender.Send(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func writeReplayJob(writer http.ResponseWriter, statusCode int, job ReplayJobStatus) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(job)
+}
@@ -0,0 +1,309 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"knative.dev/eventing/pkg/apis/eventing"
+	"knative.dev/eventing/pkg/kncloudevents"
+	broker "knative.dev/eventing/pkg/mtbroker"
+	reconcilertestingv1 "knative.dev/eventing/pkg/reconciler/testing/v1"
+)
+
+func TestReplay(t *testing.T) {
+	logger := zap.NewNop()
+
+	channel := httptest.NewServer(handler())
+	defer channel.Close()
+
+	var mu sync.Mutex
+	var replayed []*event.Event
+	var paths []string
+	filter := httptest.NewServer(nethttp.HandlerFunc(func(writer nethttp.ResponseWriter, request *nethttp.Request) {
+		e, err := binding.ToEvent(request.Context(), cehttp.NewMessageFromHttpRequest(request))
+		if err != nil {
+			t.Error("Failed to read the event:", err)
+		}
+		mu.Lock()
+		replayed = append(replayed, e)
+		paths = append(paths, request.URL.Path)
+		mu.Unlock()
+		writer.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer filter.Close()
+	filterURL, _ := url.Parse(filter.URL)
+
+	recorded := makeBroker("recorded", "ns")
+	recorded.Annotations = map[string]string{eventing.BrokerRecordingAnnotationKey: "true"}
+	recorded.Status.Annotations = map[string]string{eventing.BrokerChannelAddressStatusAnnotationKey: channel.URL}
+	notRecorded := makeBroker("name", "ns")
+	notRecorded.Status.Annotations = recorded.Status.Annotations
+	listers := reconcilertestingv1.NewListers([]runtime.Object{
+		recorded,
+		notRecorded,
+		reconcilertestingv1.NewTrigger("trigger", "ns", "recorded", reconcilertestingv1.WithTriggerUID("abc")),
+		reconcilertestingv1.NewTrigger("other", "ns", "name"),
+	})
+
+	sender, _ := kncloudevents.NewHTTPMessageSenderWithTarget("")
+	recording := NewRecording(10, 100, time.Hour)
+	ingress := httptest.NewServer(&Handler{
+		Sender:       sender,
+		Defaulter:    broker.TTLDefaulter(logger, 100),
+		Reporter:     &mockReporter{},
+		BrokerLister: listers.GetBrokerLister(),
+		Recording:    recording,
+		Logger:       logger,
+	})
+	defer ingress.Close()
+
+	start := time.Now()
+	for _, e := range []struct{ id, typ, target string }{
+		{"1", "a", "/ns/recorded"},
+		{"2", "b", "/ns/recorded"},
+		{"3", "a", "/ns/recorded"},
+		{"4", "a", "/ns/name"},
+	} {
+		ev := event.New()
+		ev.SetType(e.typ)
+		ev.SetSource("source")
+		ev.SetID(e.id)
+		if statusCode := sendEvent(t, ingress.URL+e.target, ev); statusCode != nethttp.StatusAccepted {
+			t.Fatalf("expected status code %d got %d", nethttp.StatusAccepted, statusCode)
+		}
+	}
+
+	h := &ReplayHandler{
+		Recording:     recording,
+		Sender:        sender,
+		TriggerLister: listers.GetTriggerLister(),
+		FilterHost:    filterURL.Host,
+		KubeClient:    newReplayKubeClient("create", "get"),
+		Replica:       "broker-ingress-0",
+		Logger:        logger,
+	}
+
+	tt := []struct {
+		name       string
+		uri        string
+		request    ReplayRequest
+		statusCode int
+	}{{
+		name:       "unknown trigger",
+		uri:        "/replay/ns/recorded",
+		request:    ReplayRequest{Trigger: "unknown", Start: start},
+		statusCode: nethttp.StatusNotFound,
+	}, {
+		name:       "trigger of another broker",
+		uri:        "/replay/ns/recorded",
+		request:    ReplayRequest{Trigger: "other", Start: start},
+		statusCode: nethttp.StatusBadRequest,
+	}, {
+		name:       "missing start",
+		uri:        "/replay/ns/recorded",
+		request:    ReplayRequest{Trigger: "trigger"},
+		statusCode: nethttp.StatusBadRequest,
+	}, {
+		name:       "unknown path",
+		uri:        "/ns/recorded",
+		request:    ReplayRequest{Trigger: "trigger", Start: start},
+		statusCode: nethttp.StatusNotFound,
+	}}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := postReplay(h, tc.uri, tc.request); result.Code != tc.statusCode {
+				t.Errorf("expected status code %d got %d", tc.statusCode, result.Code)
+			}
+		})
+	}
+
+	result := postReplay(h, "/replay/ns/recorded", ReplayRequest{
+		Trigger: "trigger",
+		Start:   start,
+		Filter:  map[string]string{"type": "a"},
+	})
+	if result.Code != nethttp.StatusAccepted {
+		t.Fatalf("expected status code %d got %d", nethttp.StatusAccepted, result.Code)
+	}
+	var job ReplayJobStatus
+	if err := json.NewDecoder(result.Body).Decode(&job); err != nil {
+		t.Fatal("Failed to read the replay job:", err)
+	}
+	if job.Matched != 2 {
+		t.Errorf("expected 2 events matched got %d", job.Matched)
+	}
+	if job.Replica != "broker-ingress-0" {
+		t.Errorf("expected the replay job run by broker-ingress-0 got %q", job.Replica)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); job.Phase == ReplayJobRunning && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, newReplayRequest(nethttp.MethodGet, "/replay/ns/recorded/"+job.ID, nil))
+		if recorder.Code != nethttp.StatusOK {
+			t.Fatalf("expected status code %d got %d", nethttp.StatusOK, recorder.Code)
+		}
+		if err := json.NewDecoder(recorder.Body).Decode(&job); err != nil {
+			t.Fatal("Failed to read the replay job:", err)
+		}
+	}
+	if job.Phase != ReplayJobSucceeded || job.Sent != 2 || job.Failed != 0 {
+		t.Fatalf("expected the replay job to succeed sending 2 events got %+v", job)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, id := range []string{"1", "3"} {
+		if replayed[i].ID() != id {
+			t.Errorf("expected the event %s replayed got %s", id, replayed[i].ID())
+		}
+		if ext := replayed[i].Extensions()[ReplayJobExtension]; ext != job.ID {
+			t.Errorf("expected the %s extension %q got %v", ReplayJobExtension, job.ID, ext)
+		}
+		if paths[i] != "/triggers/ns/trigger/abc" {
+			t.Errorf("expected the event replayed to /triggers/ns/trigger/abc got %s", paths[i])
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, newReplayRequest(nethttp.MethodGet, "/replay/ns/name/"+job.ID, nil))
+	if recorder.Code != nethttp.StatusNotFound {
+		t.Errorf("expected status code %d for the job of another broker got %d", nethttp.StatusNotFound, recorder.Code)
+	}
+}
+
+func postReplay(h nethttp.Handler, uri string, replay ReplayRequest) *httptest.ResponseRecorder {
+	b, _ := json.Marshal(replay)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, newReplayRequest(nethttp.MethodPost, uri, bytes.NewBuffer(b)))
+	return recorder
+}
+
+// replayToken is the bearer token authenticating the user of the replays.
+const replayToken = "replay-token"
+
+func newReplayRequest(method, uri string, body io.Reader) *nethttp.Request {
+	request := httptest.NewRequest(method, uri, body)
+	request.Header.Set("Authorization", "Bearer "+replayToken)
+	return request
+}
+
+// newReplayKubeClient returns a client authenticating replayToken, whose user
+// is allowed the verbs on the replay subresource of the Brokers.
+func newReplayKubeClient(verbs ...string) *kubefake.Clientset {
+	allowed := sets.NewString(verbs...)
+	client := kubefake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		review := action.(clientgotesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+		if review.Spec.Token == replayToken {
+			review.Status.Authenticated = true
+			review.Status.User.Username = "replayer"
+		}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		sar := action.(clientgotesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview).DeepCopy()
+		attrs := sar.Spec.ResourceAttributes
+		sar.Status.Allowed = sar.Spec.User == "replayer" && attrs.Resource == "brokers" &&
+			attrs.Subresource == ReplaySubresource && allowed.Has(attrs.Verb)
+		return true, sar, nil
+	})
+	return client
+}
+
+func TestReplayAuthorization(t *testing.T) {
+	listers := reconcilertestingv1.NewListers([]runtime.Object{
+		reconcilertestingv1.NewTrigger("trigger", "ns", "recorded"),
+	})
+	h := &ReplayHandler{
+		Recording:     NewRecording(10, 100, time.Hour),
+		TriggerLister: listers.GetTriggerLister(),
+		KubeClient:    newReplayKubeClient("get"),
+		Logger:        zap.NewNop(),
+	}
+	body, _ := json.Marshal(ReplayRequest{Trigger: "trigger", Start: time.Now().Add(-time.Minute)})
+
+	tt := []struct {
+		name          string
+		authorization string
+		method        string
+		uri           string
+		statusCode    int
+	}{{
+		name:       "no token",
+		method:     nethttp.MethodPost,
+		uri:        "/replay/ns/recorded",
+		statusCode: nethttp.StatusUnauthorized,
+	}, {
+		name:          "not a bearer token",
+		authorization: "Basic " + replayToken,
+		method:        nethttp.MethodPost,
+		uri:           "/replay/ns/recorded",
+		statusCode:    nethttp.StatusUnauthorized,
+	}, {
+		name:          "invalid token",
+		authorization: "Bearer invalid",
+		method:        nethttp.MethodPost,
+		uri:           "/replay/ns/recorded",
+		statusCode:    nethttp.StatusUnauthorized,
+	}, {
+		name:          "forbidden",
+		authorization: "Bearer " + replayToken,
+		method:        nethttp.MethodPost,
+		uri:           "/replay/ns/recorded",
+		statusCode:    nethttp.StatusForbidden,
+	}, {
+		name:          "allowed",
+		authorization: "Bearer " + replayToken,
+		method:        nethttp.MethodGet,
+		uri:           "/replay/ns/recorded/unknown",
+		statusCode:    nethttp.StatusNotFound,
+	}}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(tc.method, tc.uri, bytes.NewBuffer(body))
+			if tc.authorization != "" {
+				request.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			h.ServeHTTP(recorder, request)
+			if recorder.Code != tc.statusCode {
+				t.Errorf("expected status code %d got %d", tc.statusCode, recorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	// RequestReplyHeader is the header the callers set to true to wait for the
+	// reply of the subscribers to their event, returned as the response.
+	RequestReplyHeader = "Knative-Request-Reply"
+)
+
+// ReplicaRegistry resolves the names of the ingress replicas to the addresses
+// the replies they wait for are forwarded to.
+type ReplicaRegistry interface {
+	// Address returns the address of the replica, false when there is no
+	// such replica.
+	Address(replica string) (string, bool)
+}
+
+// Replies correlates the events sent in request-reply mode with their replies.
+// The reply ids are opaque tokens naming the ingress replica waiting for the
+// reply, signed with the key shared by the replicas, so that the replies
+// reaching the other replicas are forwarded to it. The reply ids come from the
+// callers: the replies are only forwarded for the ids signed with the key, to
+// the address of their replica in the registry.
+type Replies struct {
+	replica  string
+	key      []byte
+	registry ReplicaRegistry
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	waiters map[string]chan cloudevents.Event
+}
+
+// NewReplies returns the Replies of the ingress replica with the given name,
+// waiting up to timeout for each reply. The replies are only correlated within
+// the replica when key is empty or registry nil.
+func NewReplies(replica string, key []byte, registry ReplicaRegistry, timeout time.Duration) *Replies {
+	return &Replies{
+		replica:  replica,
+		key:      key,
+		registry: registry,
+		timeout:  timeout,
+		waiters:  make(map[string]chan cloudevents.Event),
+	}
+}
+
+// newID returns a new reply id, the base64 encoding of a random nonce followed
+// by the name of the replica, and of the signature of both.
+func (r *Replies) newID() string {
+	nonce := uuid.New()
+	if len(r.key) == 0 {
+		return nonce.String()
+	}
+	payload := append(nonce[:], r.replica...)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(r.sign(payload))
+}
+
+func (r *Replies) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// wait registers a waiter for the reply id, returning the channel receiving the
+// reply and the function unregistering the waiter.
+func (r *Replies) wait(id string) (<-chan cloudevents.Event, func()) {
+	replies := make(chan cloudevents.Event, 1)
+	r.mu.Lock()
+	r.waiters[id] = replies
+	r.mu.Unlock()
+	return replies, func() {
+		r.mu.Lock()
+		delete(r.waiters, id)
+		r.mu.Unlock()
+	}
+}
+
+// deliver hands the reply to its waiter, returning false when none waits for
+// it anymore. Only the first reply is delivered.
+func (r *Replies) deliver(id string, reply cloudevents.Event) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	replies, ok := r.waiters[id]
+	if ok {
+		delete(r.waiters, id)
+		replies <- reply
+	}
+	return ok
+}
+
+// owner returns the address of the replica waiting for the reply id, false
+// when it is this one or the id isn't signed by a replica of the registry.
+func (r *Replies) owner(id string) (string, bool) {
+	if len(r.key) == 0 || r.registry == nil {
+		return "", false
+	}
+	i := strings.IndexByte(id, '.')
+	if i < 0 {
+		return "", false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(id[:i])
+	if err != nil || len(payload) <= len(uuid.UUID{}) {
+		return "", false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(id[i+1:])
+	if err != nil || !hmac.Equal(signature, r.sign(payload)) {
+		return "", false
+	}
+	replica := string(payload[len(uuid.UUID{}):])
+	if replica == r.replica {
+		return "", false
+	}
+	return r.registry.Address(replica)
+}
+
+// EndpointsReplicas is the ReplicaRegistry of the ready pods backing the
+// endpoints of a Service, the replicas being named after their pod.
+type EndpointsReplicas struct {
+	Lister    corev1listers.EndpointsLister
+	Namespace string
+	Name      string
+	// Port is the port the replicas receive the replies on.
+	Port int
+}
+
+// Address implements ReplicaRegistry.
+func (e *EndpointsReplicas) Address(replica string) (string, bool) {
+	endpoints, err := e.Lister.Endpoints(e.Namespace).Get(e.Name)
+	if err != nil {
+		return "", false
+	}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if ref := address.TargetRef; ref != nil && ref.Kind == "Pod" && ref.Name == replica {
+				return net.JoinHostPort(address.IP, strconv.Itoa(e.Port)), true
+			}
+		}
+	}
+	return "", false
+}
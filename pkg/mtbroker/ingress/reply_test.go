@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// replicas is a ReplicaRegistry of the addresses of the replicas by name.
+type replicas map[string]string
+
+func (r replicas) Address(replica string) (string, bool) {
+	address, ok := r[replica]
+	return address, ok
+}
+
+func TestRepliesOwner(t *testing.T) {
+	key := []byte("key")
+	registry := replicas{"ingress-a": "10.0.0.1:8080", "ingress-b": "10.0.0.2:8080"}
+	r := NewReplies("ingress-a", key, registry, time.Second)
+	other := NewReplies("ingress-b", key, registry, time.Second)
+	forged := NewReplies("ingress-b", []byte("other key"), registry, time.Second)
+	unknown := NewReplies("ingress-c", key, registry, time.Second)
+	// The nonce of the id is changed.
+	tampered := []byte(other.newID())
+	if tampered[0] == 'A' {
+		tampered[0] = 'B'
+	} else {
+		tampered[0] = 'A'
+	}
+
+	tests := map[string]struct {
+		id      string
+		address string
+		remote  bool
+	}{
+		"local":             {id: r.newID()},
+		"other replica":     {id: other.newID(), address: "10.0.0.2:8080", remote: true},
+		"other key":         {id: forged.newID()},
+		"unknown replica":   {id: unknown.newID()},
+		"tampered":          {id: string(tampered)},
+		"unsigned":          {id: "1234"},
+		"address":           {id: "1234@10.0.0.2:8080"},
+		"malformed":         {id: "!!!.!!!"},
+		"missing signature": {id: strings.SplitN(other.newID(), ".", 2)[0] + "."},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			address, remote := r.owner(tc.id)
+			if address != tc.address || remote != tc.remote {
+				t.Errorf("owner(%q) = %q, %v, want %q, %v", tc.id, address, remote, tc.address, tc.remote)
+			}
+		})
+	}
+}
+
+func TestRepliesOwnerWithoutKey(t *testing.T) {
+	r := NewReplies("ingress-a", nil, replicas{"ingress-b": "10.0.0.2:8080"}, time.Second)
+	other := NewReplies("ingress-b", nil, replicas{}, time.Second)
+	if address, remote := r.owner(other.newID()); remote {
+		t.Errorf("owner = %q, true, want the replies to be correlated within the replica", address)
+	}
+}
+
+func TestEndpointsReplicas(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	_ = indexer.Add(&corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-eventing", Name: "broker-ingress"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "ingress-a"}},
+				{IP: "fd00::2", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "ingress-b"}},
+			},
+			NotReadyAddresses: []corev1.EndpointAddress{
+				{IP: "10.0.0.3", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "ingress-c"}},
+			},
+		}},
+	})
+	registry := &EndpointsReplicas{
+		Lister:    corev1listers.NewEndpointsLister(indexer),
+		Namespace: "knative-eventing",
+		Name:      "broker-ingress",
+		Port:      8080,
+	}
+
+	for replica, want := range map[string]string{
+		"ingress-a": "10.0.0.1:8080",
+		"ingress-b": "[fd00::2]:8080",
+		"ingress-c": "",
+		"unknown":   "",
+	} {
+		if address, ok := registry.Address(replica); address != want || ok != (want != "") {
+			t.Errorf("Address(%q) = %q, %v, want %q", replica, address, ok, want)
+		}
+	}
+
+	registry.Name = "missing"
+	if _, ok := registry.Address("ingress-a"); ok {
+		t.Error("Expected no replica for missing endpoints")
+	}
+}
+
+func TestRepliesDeliver(t *testing.T) {
+	r := NewReplies("", nil, nil, time.Second)
+	id := r.newID()
+	replies, cancel := r.wait(id)
+	defer cancel()
+
+	reply := cloudevents.NewEvent()
+	reply.SetID("5678")
+	if !r.deliver(id, reply) {
+		t.Fatal("Expected the reply to be delivered")
+	}
+	if got := <-replies; got.ID() != "5678" {
+		t.Errorf("Unexpected reply %v", got)
+	}
+	if r.deliver(id, reply) {
+		t.Error("Expected the second reply not to be delivered")
+	}
+}
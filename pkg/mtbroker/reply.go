@@ -0,0 +1,49 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package broker
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cetypes "github.com/cloudevents/sdk-go/v2/types"
+)
+
+const (
+	// ReplyIDAttribute is the name of the CloudEvents extension attribute used to
+	// correlate the events sent in request-reply mode with the replies of their
+	// subscribers, which carry the same reply id.
+	ReplyIDAttribute = "knativereplyid"
+)
+
+// GetReplyID returns the reply id of the EventContext, false when it has none.
+func GetReplyID(ctx cloudevents.EventContext) (string, bool) {
+	value, err := ctx.GetExtension(ReplyIDAttribute)
+	if err != nil {
+		return "", false
+	}
+	id, err := cetypes.ToString(value)
+	return id, err == nil && id != ""
+}
+
+// SetReplyID sets the reply id into the EventContext.
+func SetReplyID(ctx cloudevents.EventContext, id string) error {
+	return ctx.SetExtension(ReplyIDAttribute, id)
+}
+
+// DeleteReplyID removes the reply id CE extension attribute.
+func DeleteReplyID(ctx cloudevents.EventContext) error {
+	return ctx.SetExtension(ReplyIDAttribute, nil)
+}
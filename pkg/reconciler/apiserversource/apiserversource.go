@@ -31,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	coordinationlisters "k8s.io/client-go/listers/coordination/v1"
 
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/controller"
@@ -38,6 +39,7 @@ import (
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
 
+	"knative.dev/eventing/pkg/adapter/v2"
 	apisources "knative.dev/eventing/pkg/apis/sources"
 	v1 "knative.dev/eventing/pkg/apis/sources/v1"
 	apiserversourcereconciler "knative.dev/eventing/pkg/client/injection/reconciler/sources/v1/apiserversource"
@@ -68,6 +70,10 @@ type Reconciler struct {
 	sinkResolver *resolver.URIResolver
 
 	configs reconcilersource.ConfigAccessor
+
+	// deliveryLister lists the Leases holding the delivery statuses of the
+	// ApiServerSources.
+	deliveryLister coordinationlisters.LeaseLister
 }
 
 var _ apiserversourcereconciler.Interface = (*Reconciler)(nil)
@@ -118,21 +124,41 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1.ApiServerSour
 	}
 	source.Status.CloudEventAttributes = cloudEventAttributes
 
+	// Last, the failing deliveries making the ApiServerSource not ready.
+	r.propagateDeliveryStatus(ctx, source)
+
 	return nil
 }
 
+func (r *Reconciler) propagateDeliveryStatus(ctx context.Context, source *v1.ApiServerSource) {
+	status, err := reconcilersource.DeliveryStatus(r.deliveryLister, resources.DeliveryStatusResourceGroup, source.Namespace, source.Name)
+	switch {
+	case err != nil:
+		logging.FromContext(ctx).Warnw("Unable to get the delivery status", zap.Error(err))
+	case status == nil:
+		source.Status.ClearSinkDeliverable()
+	case status.ConsecutiveFailures > 0:
+		source.Status.MarkSinkUndeliverable("DeliveryFailed", "%s", reconcilersource.DeliveryFailureMessage(status))
+	default:
+		source.Status.MarkSinkDeliverable()
+	}
+}
+
 func (r *Reconciler) createReceiveAdapter(ctx context.Context, src *v1.ApiServerSource, sinkURI string) (*appsv1.Deployment, error) {
 	// TODO: missing.
 	// if err := checkResourcesStatus(src); err != nil {
 	// 	return nil, err
 	// }
 
+	batchMaxSize, batchLinger := adapter.GetBatching(logging.FromContext(ctx))
 	adapterArgs := resources.ReceiveAdapterArgs{
-		Image:   r.receiveAdapterImage,
-		Source:  src,
-		Labels:  resources.Labels(src.Name),
-		SinkURI: sinkURI,
-		Configs: r.configs,
+		Image:        r.receiveAdapterImage,
+		Source:       src,
+		Labels:       resources.Labels(src.Name),
+		SinkURI:      sinkURI,
+		Configs:      r.configs,
+		BatchMaxSize: batchMaxSize,
+		BatchLinger:  batchLinger,
 	}
 	expected, err := resources.MakeReceiveAdapter(&adapterArgs)
 	if err != nil {
@@ -578,6 +578,7 @@ func TestReconcile(t *testing.T) {
 			receiveAdapterImage: image,
 			sinkResolver:        resolver.NewURIResolver(ctx, func(types.NamespacedName) {}),
 			configs:             &reconcilersource.EmptyVarsGenerator{},
+			deliveryLister:      listers.GetLeaseLister(),
 		}
 		return apiserversource.NewReconciler(ctx, logger,
 			fakeeventingclient.Get(ctx), listers.GetApiServerSourceLister(),
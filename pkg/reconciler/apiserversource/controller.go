@@ -27,6 +27,7 @@ import (
 	"knative.dev/pkg/resolver"
 
 	v1 "knative.dev/eventing/pkg/apis/sources/v1"
+	"knative.dev/eventing/pkg/reconciler/apiserversource/resources"
 	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
 
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
@@ -53,10 +54,14 @@ func NewController(
 	deploymentInformer := deploymentinformer.Get(ctx)
 	apiServerSourceInformer := apiserversourceinformer.Get(ctx)
 
+	configs := reconcilersource.WatchConfigurations(ctx, component, cmw,
+		reconcilersource.WithLogging, reconcilersource.WithMetrics, reconcilersource.WithTracing, reconcilersource.WithFeatures,
+		reconcilersource.WithEventTransformers)
+
 	r := &Reconciler{
 		kubeClientSet: kubeclient.Get(ctx),
 		ceSource:      GetCfgHost(ctx),
-		configs:       reconcilersource.WatchConfigurations(ctx, component, cmw),
+		configs:       configs,
 	}
 
 	env := &envConfig{}
@@ -68,6 +73,7 @@ func NewController(
 	impl := apiserversourcereconciler.NewImpl(ctx, r)
 
 	r.sinkResolver = resolver.NewURIResolver(ctx, impl.EnqueueKey)
+	r.deliveryLister = reconcilersource.WatchDeliveryStatuses(ctx, resources.DeliveryStatusResourceGroup, impl.EnqueueKey)
 
 	logging.FromContext(ctx).Info("Setting up event handlers")
 	apiServerSourceInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
@@ -30,6 +30,9 @@ import (
 	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/tracing/config"
 
+	"knative.dev/eventing/pkg/apis/feature"
+	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
+
 	// Fake injection informers
 	_ "knative.dev/eventing/pkg/client/injection/informers/sources/v1/apiserversource/fake"
 	_ "knative.dev/pkg/client/injection/kube/informers/apps/v1/deployment/fake"
@@ -68,6 +71,16 @@ func TestNew(t *testing.T) {
 		Data: map[string]string{
 			"_example": "test-config",
 		},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      feature.FlagsConfigName,
+			Namespace: "knative-eventing",
+		},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reconcilersource.EventTransformersConfigName,
+			Namespace: "knative-eventing",
+		},
 	}))
 
 	if c == nil {
@@ -19,6 +19,9 @@ package resources
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+
+	"github.com/rickb777/date/period"
 
 	"knative.dev/eventing/pkg/adapter/v2"
 
@@ -35,14 +38,24 @@ import (
 	"knative.dev/pkg/system"
 )
 
+// DeliveryStatusResourceGroup is the resource group the adapters report the
+// delivery statuses of the ApiServerSources under.
+var DeliveryStatusResourceGroup = v1.Resource("apiserversources").String()
+
 // ReceiveAdapterArgs are the arguments needed to create a ApiServer Receive Adapter.
-// Every field is required.
+// Every field is required, unless optional.
 type ReceiveAdapterArgs struct {
 	Image   string
 	Source  *v1.ApiServerSource
 	Labels  map[string]string
 	SinkURI string
 	Configs reconcilersource.ConfigAccessor
+	// BatchMaxSize is the maximum number of events of the batched requests,
+	// the events being sent one by one when lower than 2. Optional.
+	BatchMaxSize int
+	// BatchLinger is how long the batches wait for more events, empty for
+	// the default of the adapter. Optional.
+	BatchLinger string
 }
 
 // MakeReceiveAdapter generates (but does not insert into K8s) the Receive Adapter Deployment for
@@ -101,6 +114,16 @@ func makeEnv(args *ReceiveAdapterArgs) ([]corev1.EnvVar, error) {
 		Resources:     make([]apiserver.ResourceWatch, 0, len(args.Source.Spec.Resources)),
 		ResourceOwner: args.Source.Spec.ResourceOwner,
 		EventMode:     args.Source.Spec.EventMode,
+
+		SendInitialEvents: args.Source.Spec.SendInitialEvents,
+	}
+
+	if args.Source.Spec.ResyncPeriod != nil {
+		p, err := period.Parse(*args.Source.Spec.ResyncPeriod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ResyncPeriod: %w", err)
+		}
+		cfg.ResyncPeriod, _ = p.Duration()
 	}
 
 	for _, r := range args.Source.Spec.Resources {
@@ -110,7 +133,7 @@ func makeEnv(args *ReceiveAdapterArgs) ([]corev1.EnvVar, error) {
 		}
 		gvr, _ := meta.UnsafeGuessKindToResource(gv.WithKind(r.Kind))
 
-		rw := apiserver.ResourceWatch{GVR: gvr}
+		rw := apiserver.ResourceWatch{GVR: gvr, FieldSelector: r.FieldSelector}
 
 		if r.LabelSelector != nil {
 			selector, _ := metav1.LabelSelectorAsSelector(r.LabelSelector)
@@ -151,6 +174,19 @@ func makeEnv(args *ReceiveAdapterArgs) ([]corev1.EnvVar, error) {
 
 	envs = append(envs, args.Configs.ToEnvVars()...)
 
+	// The owner of the EventTypes, when the adapter creates them.
+	owner, err := json.Marshal(metav1.OwnerReference{
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Kind:       "ApiServerSource",
+		Name:       args.Source.Name,
+		UID:        args.Source.UID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the EventType owner: %w", err)
+	}
+	envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigEventTypeOwner, Value: string(owner)},
+		corev1.EnvVar{Name: adapter.EnvConfigDeliveryStatus, Value: DeliveryStatusResourceGroup})
+
 	if args.Source.Spec.CloudEventOverrides != nil {
 		ceJson, err := json.Marshal(args.Source.Spec.CloudEventOverrides)
 		if err != nil {
@@ -158,5 +194,11 @@ func makeEnv(args *ReceiveAdapterArgs) ([]corev1.EnvVar, error) {
 		}
 		envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigCEOverrides, Value: string(ceJson)})
 	}
+	if args.BatchMaxSize > 1 {
+		envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigBatchMaxSize, Value: strconv.Itoa(args.BatchMaxSize)})
+		if args.BatchLinger != "" {
+			envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigBatchLinger, Value: args.BatchLinger})
+		}
+	}
 	return envs, nil
 }
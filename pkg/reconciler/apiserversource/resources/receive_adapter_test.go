@@ -30,6 +30,7 @@ import (
 	"knative.dev/eventing/pkg/reconciler/source"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
 
 	_ "knative.dev/pkg/metrics/testing"
 	_ "knative.dev/pkg/system/testing"
@@ -59,6 +60,7 @@ func TestMakeReceiveAdapters(t *testing.T) {
 				LabelSelector: &metav1.LabelSelector{
 					MatchLabels: map[string]string{"test-key1": "test-value1"},
 				},
+				FieldSelector: "spec.nodeName=test-node",
 			}},
 			ResourceOwner: &v1.APIVersionKind{
 				APIVersion: "custom/v1",
@@ -66,6 +68,8 @@ func TestMakeReceiveAdapters(t *testing.T) {
 			},
 			EventMode:          "Resource",
 			ServiceAccountName: "source-svc-acct",
+			SendInitialEvents:  true,
+			ResyncPeriod:       ptr.String("PT1H"),
 		},
 	}
 
@@ -122,7 +126,7 @@ func TestMakeReceiveAdapters(t *testing.T) {
 									Value: "sink-uri",
 								}, {
 									Name:  "K_SOURCE_CONFIG",
-									Value: `{"namespace":"source-namespace","resources":[{"gvr":{"Group":"","Version":"","Resource":"namespaces"}},{"gvr":{"Group":"batch","Version":"v1","Resource":"jobs"}},{"gvr":{"Group":"","Version":"","Resource":"pods"},"selector":"test-key1=test-value1"}],"owner":{"apiVersion":"custom/v1","kind":"Parent"},"mode":"Resource"}`,
+									Value: `{"namespace":"source-namespace","resources":[{"gvr":{"Group":"","Version":"","Resource":"namespaces"}},{"gvr":{"Group":"batch","Version":"v1","Resource":"jobs"}},{"gvr":{"Group":"","Version":"","Resource":"pods"},"selector":"test-key1=test-value1","fieldSelector":"spec.nodeName=test-node"}],"owner":{"apiVersion":"custom/v1","kind":"Parent"},"mode":"Resource","sendInitialEvents":true,"resyncPeriod":3600000000000}`,
 								}, {
 									Name:  "SYSTEM_NAMESPACE",
 									Value: "knative-testing",
@@ -148,6 +152,12 @@ func TestMakeReceiveAdapters(t *testing.T) {
 								}, {
 									Name:  source.EnvTracingCfg,
 									Value: "",
+								}, {
+									Name:  "K_EVENTTYPE_OWNER",
+									Value: `{"apiVersion":"sources.knative.dev/v1","kind":"ApiServerSource","name":"source-name","uid":"1234"}`,
+								}, {
+									Name:  "K_DELIVERY_STATUS",
+									Value: "apiserversources.sources.knative.dev",
 								},
 							},
 						},
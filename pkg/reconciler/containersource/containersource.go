@@ -99,8 +99,11 @@ func (r *Reconciler) reconcileReceiveAdapter(ctx context.Context, source *v1.Con
 		return nil, fmt.Errorf("getting Deployment: %v", err)
 	} else if !metav1.IsControlledBy(ra, source) {
 		return nil, fmt.Errorf("Deployment %q is not owned by ContainerSource %q", ra.Name, source.Name)
-	} else if r.podSpecChanged(&ra.Spec.Template.Spec, &expected.Spec.Template.Spec) {
+	} else if r.podTemplateChanged(&ra.Spec.Template, &expected.Spec.Template) {
+		// Don't modify the informers copy.
+		ra = ra.DeepCopy()
 		ra.Spec.Template.Spec = expected.Spec.Template.Spec
+		ra.Spec.Template.Annotations = mergeTemplateAnnotations(ra.Spec.Template.Annotations, expected.Spec.Template.Annotations)
 		ra, err = r.kubeClientSet.AppsV1().Deployments(expected.Namespace).Update(ctx, ra, metav1.UpdateOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("updating Deployment: %v", err)
@@ -144,6 +147,29 @@ func (r *Reconciler) reconcileSinkBinding(ctx context.Context, source *v1.Contai
 	return sb, nil
 }
 
+// podTemplateChanged also tells whether the containers the sink is bound to
+// changed, for the SinkBinding to bind them again.
+func (r *Reconciler) podTemplateChanged(have *corev1.PodTemplateSpec, want *corev1.PodTemplateSpec) bool {
+	return r.podSpecChanged(&have.Spec, &want.Spec) ||
+		!equality.Semantic.DeepDerivative(want.Annotations, have.Annotations) ||
+		have.Annotations[v1.SinkBindingContainersAnnotation] != want.Annotations[v1.SinkBindingContainersAnnotation]
+}
+
+// mergeTemplateAnnotations returns the annotations of the Deployment pod
+// template with the wanted ones, keeping the ones of the other clients like
+// the rollout restarts.
+func mergeTemplateAnnotations(have, want map[string]string) map[string]string {
+	merged := make(map[string]string, len(have)+len(want))
+	for k, v := range have {
+		merged[k] = v
+	}
+	delete(merged, v1.SinkBindingContainersAnnotation)
+	for k, v := range want {
+		merged[k] = v
+	}
+	return merged
+}
+
 func (r *Reconciler) podSpecChanged(have *corev1.PodSpec, want *corev1.PodSpec) bool {
 	// TODO this won't work, SinkBinding messes with this. n3wscott working on a fix.
 	return !equality.Semantic.DeepDerivative(want, have)
@@ -236,6 +236,49 @@ func TestAllCases(t *testing.T) {
 					), &conditionTrue)),
 				),
 			}},
+		}, {
+			Name: "sink bound to the source container of the sidecars",
+			Objects: []runtime.Object{
+				NewContainerSource(sourceName, testNS,
+					WithContainerSourceUID(sourceUID),
+					WithContainerSourceSpec(makeSidecarsContainerSourceSpec(sinkDest, "source")),
+					WithContainerSourceObjectMetaGeneration(generation),
+				),
+				makeSinkBinding(NewContainerSource(sourceName, testNS,
+					WithContainerSourceSpec(makeSidecarsContainerSourceSpec(sinkDest, "source")),
+					WithContainerSourceUID(sourceUID),
+				), &conditionTrue),
+				// The sink used to be bound to all the containers.
+				makeDeployment(NewContainerSource(sourceName, testNS,
+					WithContainerSourceSpec(makeSidecarsContainerSourceSpec(sinkDest, "")),
+					WithContainerSourceUID(sourceUID),
+				), &conditionTrue),
+			},
+			Key: testNS + "/" + sourceName,
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, deploymentUpdated, "Deployment updated %q", deploymentName),
+				Eventf(corev1.EventTypeNormal, sourceReconciled, `ContainerSource reconciled: "%s/%s"`, testNS, sourceName),
+			},
+			WantUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: makeDeployment(NewContainerSource(sourceName, testNS,
+					WithContainerSourceSpec(makeSidecarsContainerSourceSpec(sinkDest, "source")),
+					WithContainerSourceUID(sourceUID),
+				), &conditionTrue),
+			}},
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewContainerSource(sourceName, testNS,
+					WithContainerSourceUID(sourceUID),
+					WithContainerSourceSpec(makeSidecarsContainerSourceSpec(sinkDest, "source")),
+					WithContainerSourceObjectMetaGeneration(generation),
+					WithInitContainerSourceConditions,
+					WithContainerSourceStatusObservedGeneration(generation),
+					WithContainerSourcePropagateSinkbindingStatus(makeSinkBindingStatus(&conditionTrue)),
+					WithContainerSourcePropagateReceiveAdapterStatus(makeDeployment(NewContainerSource(sourceName, testNS,
+						WithContainerSourceSpec(makeSidecarsContainerSourceSpec(sinkDest, "source")),
+						WithContainerSourceUID(sourceUID),
+					), &conditionTrue)),
+				),
+			}},
 		},
 	}
 
@@ -369,3 +412,18 @@ func makeSinkBindingStatus(ready *corev1.ConditionStatus) *sourcesv1.SinkBinding
 		},
 	}
 }
+
+// makeSidecarsContainerSourceSpec returns the spec of a source with a
+// sidecar, the sink being bound to the sinkContainers, or to all the
+// containers when empty.
+func makeSidecarsContainerSourceSpec(sink duckv1.Destination, sinkContainers string) sourcesv1.ContainerSourceSpec {
+	spec := makeContainerSourceSpec(sink)
+	spec.Template.Spec.Containers = append(spec.Template.Spec.Containers, corev1.Container{
+		Name:  "proxy",
+		Image: image,
+	})
+	if sinkContainers != "" {
+		spec.Template.Annotations = map[string]string{sourcesv1.SinkBindingContainersAnnotation: sinkContainers}
+	}
+	return spec
+}
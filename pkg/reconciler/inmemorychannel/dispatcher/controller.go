@@ -45,6 +45,7 @@ import (
 
 	"knative.dev/eventing/pkg/apis/eventing"
 	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/channel/buffer"
 	eventingclient "knative.dev/eventing/pkg/client/injection/client"
 	inmemorychannelinformer "knative.dev/eventing/pkg/client/injection/informers/messaging/v1/inmemorychannel"
 	"knative.dev/eventing/pkg/inmemorychannel"
@@ -61,6 +62,13 @@ type envConfig struct {
 	// TODO: change this environment variable to something like "PodGroupName".
 	PodName       string `envconfig:"POD_NAME" required:"true"`
 	ContainerName string `envconfig:"CONTAINER_NAME" required:"true"`
+
+	// BufferDirectory is the directory the events are persisted in until
+	// they are dispatched, for them to be redelivered after a restart. The
+	// events aren't persisted when empty.
+	BufferDirectory string `envconfig:"BUFFER_DIRECTORY"`
+	// BufferSize is the maximum number of persisted events.
+	BufferSize int `envconfig:"BUFFER_SIZE"`
 }
 
 // NewController initializes the controller and is called by the generated code.
@@ -83,6 +91,19 @@ func NewController(
 
 	reporter := channel.NewStatsReporter(env.ContainerName, kmeta.ChildName(env.PodName, uuid.New().String()))
 
+	var eventBuffer *buffer.FileBuffer
+	if env.BufferDirectory != "" {
+		size := env.BufferSize
+		if size == 0 {
+			size = buffer.DefaultSize
+		}
+		var err error
+		if eventBuffer, err = buffer.NewFileBuffer(env.BufferDirectory, size); err != nil {
+			logger.Fatalw("Failed to create the event buffer", zap.Error(err))
+		}
+		logger.Infow("Buffering the events", zap.String("directory", env.BufferDirectory), zap.Int("size", size), zap.Int("recovered", eventBuffer.Len()))
+	}
+
 	sh := multichannelfanout.NewMessageHandler(ctx, logger.Desugar(), channel.NewMessageDispatcher(logger.Desugar()), reporter)
 
 	args := &inmemorychannel.InMemoryMessageDispatcherArgs{
@@ -100,6 +121,7 @@ func NewController(
 	r := &Reconciler{
 		multiChannelMessageHandler: sh,
 		reporter:                   reporter,
+		eventBuffer:                eventBuffer,
 		messagingClientSet:         eventingclient.Get(ctx).MessagingV1(),
 	}
 	impl := inmemorychannelreconciler.NewImpl(ctx, r, func(impl *controller.Impl) controller.Options {
@@ -35,6 +35,7 @@ import (
 	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	v1 "knative.dev/eventing/pkg/apis/messaging/v1"
 	"knative.dev/eventing/pkg/channel"
+	"knative.dev/eventing/pkg/channel/buffer"
 	"knative.dev/eventing/pkg/channel/fanout"
 	"knative.dev/eventing/pkg/channel/multichannelfanout"
 	messagingv1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/messaging/v1"
@@ -46,6 +47,7 @@ type Reconciler struct {
 	eventDispatcherConfigStore *channel.EventDispatcherConfigStore
 	multiChannelMessageHandler multichannelfanout.MultiChannelMessageHandler
 	reporter                   channel.StatsReporter
+	eventBuffer                *buffer.FileBuffer
 	messagingClientSet         messagingv1.MessagingV1Interface
 }
 
@@ -78,6 +80,11 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, imc *v1.InMemoryChannel)
 			return err
 		}
 		r.multiChannelMessageHandler.SetChannelHandler(config.HostName, fanoutHandler)
+		// Redeliver the events the channel accepted before the dispatcher
+		// restarted.
+		if ref, err := channel.ParseChannel(config.HostName); err == nil {
+			go fanoutHandler.Replay(context.Background(), ref)
+		}
 	} else {
 		// Just update the config if necessary.
 		haveSubs := handler.GetSubscriptions(ctx)
@@ -87,6 +94,7 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, imc *v1.InMemoryChannel)
 			logging.FromContext(ctx).Info("Updating fanout config: ", zap.String("Diff", diff))
 			handler.SetSubscriptions(ctx, config.FanoutConfig.Subscriptions)
 		}
+		handler.SetLogDiscardedEvents(config.FanoutConfig.LogDiscardedEvents)
 	}
 
 	// Then patch the subscribers to reflect that they are now ready to go
@@ -154,8 +162,10 @@ func (r *Reconciler) newConfigForInMemoryChannel(imc *v1.InMemoryChannel) (*mult
 		Name:      imc.Name,
 		HostName:  imc.Status.Address.URL.Host,
 		FanoutConfig: fanout.Config{
-			AsyncHandler:  true,
-			Subscriptions: subs,
+			AsyncHandler:       true,
+			Subscriptions:      subs,
+			LogDiscardedEvents: r.eventDispatcherConfigStore.GetConfig().LogDiscardedEvents,
+			Buffer:             r.eventBuffer,
 		},
 	}, nil
 }
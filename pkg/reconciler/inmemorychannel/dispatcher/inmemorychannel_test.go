@@ -511,6 +511,7 @@ func TestReconciler_ReconcileKind(t *testing.T) {
 				}
 				r := &Reconciler{
 					multiChannelMessageHandler: handler,
+					eventDispatcherConfigStore: channel.NewEventDispatcherConfigStore(logtesting.TestLogger(t)),
 					messagingClientSet:         fakeEventingClient.MessagingV1(),
 				}
 				e := r.ReconcileKind(ctx, tc.imc)
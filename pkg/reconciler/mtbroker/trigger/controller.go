@@ -19,6 +19,7 @@ package mttrigger
 import (
 	"context"
 
+	"github.com/kelseyhightower/envconfig"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
@@ -31,6 +32,7 @@ import (
 	brokerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1/broker"
 	triggerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1/trigger"
 	"knative.dev/eventing/pkg/duck"
+	"knative.dev/eventing/pkg/kncloudevents"
 	"knative.dev/pkg/client/injection/ducks/duck/v1/source"
 	configmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap"
 	"knative.dev/pkg/configmap"
@@ -41,6 +43,12 @@ import (
 	"knative.dev/pkg/resolver"
 )
 
+type envConfig struct {
+	// GraphPort is the port the dependency graph of the Triggers is served
+	// on, not served when zero.
+	GraphPort int `envconfig:"GRAPH_PORT"`
+}
+
 // NewController initializes the controller and is called by the generated code
 // Registers event handlers to enqueue events
 func NewController(
@@ -99,5 +107,18 @@ func NewController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	var env envConfig
+	if err := envconfig.Process("", &env); err != nil {
+		logger.Fatalw("Failed to process the env", zap.Error(err))
+	}
+	if env.GraphPort > 0 {
+		handler := &graphHandler{triggerLister: triggerInformer.Lister(), logger: logger}
+		go func() {
+			if err := kncloudevents.NewHTTPMessageReceiver(env.GraphPort).StartListen(ctx, handler); err != nil {
+				logger.Errorw("Failed to serve the dependency graph", zap.Error(err))
+			}
+		}()
+	}
+
 	return impl
 }
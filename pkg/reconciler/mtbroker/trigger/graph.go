@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mttrigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1"
+)
+
+// graphPath is the path the dependency graph of the Triggers is served on.
+const graphPath = "/eventing/graph"
+
+var triggerGVK = eventingv1.SchemeGroupVersion.WithKind("Trigger")
+
+// graph is the dependency graph of the Triggers, served for tooling.
+type graph struct {
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+}
+
+// graphNode is a Trigger or a dependency of a Trigger.
+type graphNode struct {
+	// ID identifies the node in the edges, as kind.group/namespace/name.
+	ID         string `json:"id"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	// Ready is the status of the Ready condition of the Triggers, unknown
+	// for the other dependencies.
+	Ready corev1.ConditionStatus `json:"ready,omitempty"`
+}
+
+// graphEdge is a Trigger, From, depending on a node, To.
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func graphNodeID(apiVersion, kind, namespace, name string) string {
+	gk := schema.FromAPIVersionAndKind(apiVersion, kind).GroupKind()
+	return fmt.Sprintf("%s/%s/%s", gk.String(), namespace, name)
+}
+
+// buildGraph returns the dependency graph of the Triggers.
+func buildGraph(triggers []*eventingv1.Trigger) *graph {
+	sort.Slice(triggers, func(i, j int) bool {
+		if triggers[i].Namespace != triggers[j].Namespace {
+			return triggers[i].Namespace < triggers[j].Namespace
+		}
+		return triggers[i].Name < triggers[j].Name
+	})
+
+	g := &graph{Nodes: []graphNode{}, Edges: []graphEdge{}}
+	nodes := make(map[string]int, len(triggers))
+	for _, t := range triggers {
+		node := graphNode{
+			ID:         graphNodeID(triggerGVK.GroupVersion().String(), triggerGVK.Kind, t.Namespace, t.Name),
+			APIVersion: triggerGVK.GroupVersion().String(),
+			Kind:       triggerGVK.Kind,
+			Namespace:  t.Namespace,
+			Name:       t.Name,
+			Ready:      corev1.ConditionUnknown,
+		}
+		if c := t.Status.GetTopLevelCondition(); c != nil {
+			node.Ready = c.Status
+		}
+		nodes[node.ID] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, node)
+	}
+	for _, t := range triggers {
+		// The invalid annotations are reported in the status of the Triggers.
+		dependencies, _ := triggerDependencies(t)
+		from := graphNodeID(triggerGVK.GroupVersion().String(), triggerGVK.Kind, t.Namespace, t.Name)
+		for _, dep := range dependencies {
+			id := graphNodeID(dep.APIVersion, dep.Kind, t.Namespace, dep.Name)
+			if _, ok := nodes[id]; !ok {
+				nodes[id] = len(g.Nodes)
+				g.Nodes = append(g.Nodes, graphNode{
+					ID:         id,
+					APIVersion: dep.APIVersion,
+					Kind:       dep.Kind,
+					Namespace:  t.Namespace,
+					Name:       dep.Name,
+				})
+			}
+			g.Edges = append(g.Edges, graphEdge{From: from, To: id})
+		}
+	}
+	return g
+}
+
+// graphHandler serves the dependency graph of the Triggers of all the
+// namespaces, or of the namespace of the namespace query parameter.
+type graphHandler struct {
+	triggerLister eventinglisters.TriggerLister
+	logger        *zap.SugaredLogger
+}
+
+func (h *graphHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != graphPath {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var triggers []*eventingv1.Trigger
+	var err error
+	if namespace := r.URL.Query().Get("namespace"); namespace != "" {
+		triggers, err = h.triggerLister.Triggers(namespace).List(labels.Everything())
+	} else {
+		triggers, err = h.triggerLister.List(labels.Everything())
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to list the triggers", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(buildGraph(triggers)); err != nil {
+		h.logger.Warnw("Failed to write the dependency graph", zap.Error(err))
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mttrigger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logtesting "knative.dev/pkg/logging/testing"
+
+	. "knative.dev/eventing/pkg/reconciler/testing/v1"
+)
+
+func TestGraphHandler(t *testing.T) {
+	listers := NewListers([]runtime.Object{
+		NewTrigger(triggerName, testNS, brokerName,
+			WithDependencyAnnotation(dependencyAnnotation),
+			WithTriggerDependencies(makeTriggerDependency(otherTriggerName)),
+		),
+		makeReadyTrigger(otherTriggerName),
+		NewTrigger("elsewhere", "other-namespace", brokerName),
+	})
+	h := &graphHandler{triggerLister: listers.GetTriggerLister(), logger: logtesting.TestLogger(t)}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, graphPath+"?namespace="+testNS, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var got graph
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal("Unmarshal =", err)
+	}
+
+	const (
+		other  = "Trigger.eventing.knative.dev/test-namespace/other-trigger"
+		ping   = "PingSource.sources.knative.dev/test-namespace/test-ping-source"
+		tested = "Trigger.eventing.knative.dev/test-namespace/test-trigger"
+	)
+	want := graph{
+		Nodes: []graphNode{{
+			ID:         other,
+			APIVersion: "eventing.knative.dev/v1",
+			Kind:       "Trigger",
+			Namespace:  testNS,
+			Name:       otherTriggerName,
+			Ready:      corev1.ConditionTrue,
+		}, {
+			ID:         tested,
+			APIVersion: "eventing.knative.dev/v1",
+			Kind:       "Trigger",
+			Namespace:  testNS,
+			Name:       triggerName,
+			Ready:      corev1.ConditionUnknown,
+		}, {
+			ID:         ping,
+			APIVersion: "sources.knative.dev/v1beta1",
+			Kind:       "PingSource",
+			Namespace:  testNS,
+			Name:       pingSourceName,
+		}},
+		Edges: []graphEdge{{From: tested, To: ping}, {From: tested, To: other}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected graph (-want, +got) =", diff)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, graphPath, nil))
+	got = graph{}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal("Unmarshal =", err)
+	}
+	if len(got.Nodes) != 4 {
+		t.Errorf("Expected the triggers of all the namespaces, got %d nodes", len(got.Nodes))
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, graphPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
@@ -20,12 +20,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/dynamic"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 
@@ -139,7 +141,7 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, t *eventingv1.Trigger) p
 	}
 	t.Status.PropagateSubscriptionCondition(sub.Status.GetTopLevelCondition())
 
-	if err := r.checkDependencyAnnotation(ctx, t); err != nil {
+	if err := r.checkDependencies(ctx, t); err != nil {
 		return err
 	}
 
@@ -216,27 +218,95 @@ func (r *Reconciler) reconcileSubscription(ctx context.Context, t *eventingv1.Tr
 	return newSub, nil
 }
 
-func (r *Reconciler) checkDependencyAnnotation(ctx context.Context, t *eventingv1.Trigger) error {
-	if dependencyAnnotation, ok := t.GetAnnotations()[eventingv1.DependencyAnnotation]; ok {
-		dependencyObjRef, err := eventingv1.GetObjRefFromDependencyAnnotation(dependencyAnnotation)
-		if err != nil {
-			t.Status.MarkDependencyFailed("ReferenceError", "Unable to unmarshal objectReference from dependency annotation of trigger: %v", err)
-			return fmt.Errorf("getting object ref from dependency annotation %q: %v", dependencyAnnotation, err)
-		}
-		trackSource := r.sourceTracker.TrackInNamespace(ctx, t)
-		// Trigger and its dependent source are in the same namespace, we already did the validation in the webhook.
+// checkDependencies propagates the readiness of the dependencies of the
+// Trigger, the first one not ready making the Trigger not ready.
+func (r *Reconciler) checkDependencies(ctx context.Context, t *eventingv1.Trigger) error {
+	dependencies, err := triggerDependencies(t)
+	if err != nil {
+		t.Status.MarkDependencyFailed("ReferenceError", "Unable to unmarshal objectReference from dependency annotation of trigger: %v", err)
+		return fmt.Errorf("getting object ref from dependency annotation %q: %v", t.GetAnnotations()[eventingv1.DependencyAnnotation], err)
+	}
+	if len(dependencies) == 0 {
+		t.Status.MarkDependencySucceeded()
+		return nil
+	}
+
+	trackSource := r.sourceTracker.TrackInNamespace(ctx, t)
+	// Trigger and its dependent sources are in the same namespace, we already did the validation in the webhook.
+	for _, dependencyObjRef := range dependencies {
 		if err := trackSource(dependencyObjRef); err != nil {
 			return fmt.Errorf("tracking dependency: %v", err)
 		}
+	}
+	// The Triggers of a cycle would never become ready.
+	if cycle := r.dependencyCycle(t); cycle != nil {
+		t.Status.MarkDependencyFailed("DependencyCycle", "The trigger depends on itself: %s", strings.Join(cycle, " -> "))
+		return nil
+	}
+	for _, dependencyObjRef := range dependencies {
 		if err := r.propagateDependencyReadiness(ctx, t, dependencyObjRef); err != nil {
 			return fmt.Errorf("propagating dependency readiness: %v", err)
 		}
-	} else {
-		t.Status.MarkDependencySucceeded()
+		if !t.Status.GetCondition(eventingv1.TriggerConditionDependencies).IsTrue() {
+			return nil
+		}
 	}
 	return nil
 }
 
+// triggerDependencies returns the dependencies of the Trigger, the one of
+// the DependencyAnnotation first.
+func triggerDependencies(t *eventingv1.Trigger) ([]corev1.ObjectReference, error) {
+	dependencies := make([]corev1.ObjectReference, 0, len(t.Spec.Dependencies)+1)
+	if dependencyAnnotation, ok := t.GetAnnotations()[eventingv1.DependencyAnnotation]; ok {
+		dependencyObjRef, err := eventingv1.GetObjRefFromDependencyAnnotation(dependencyAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependencyObjRef)
+	}
+	for _, dep := range t.Spec.Dependencies {
+		dependencies = append(dependencies, corev1.ObjectReference{
+			APIVersion: dep.APIVersion,
+			Kind:       dep.Kind,
+			Name:       dep.Name,
+		})
+	}
+	return dependencies, nil
+}
+
+// dependencyCycle returns the names of the Triggers through which the Trigger
+// depends on itself, starting and ending with the Trigger, nil when it doesn't.
+func (r *Reconciler) dependencyCycle(t *eventingv1.Trigger) []string {
+	visited := sets.NewString(t.Name)
+	var visit func(trigger *eventingv1.Trigger, path []string) []string
+	visit = func(trigger *eventingv1.Trigger, path []string) []string {
+		// The invalid annotations are reported by the Trigger holding them.
+		dependencies, _ := triggerDependencies(trigger)
+		for _, dependencyObjRef := range dependencies {
+			if !eventingv1.IsTriggerReference(dependencyObjRef.APIVersion, dependencyObjRef.Kind) {
+				continue
+			}
+			if dependencyObjRef.Name == t.Name {
+				return append(path, t.Name)
+			}
+			if visited.Has(dependencyObjRef.Name) {
+				continue
+			}
+			visited.Insert(dependencyObjRef.Name)
+			dependency, err := r.triggerLister.Triggers(t.Namespace).Get(dependencyObjRef.Name)
+			if err != nil {
+				continue
+			}
+			if cycle := visit(dependency, append(path, dependency.Name)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit(t, []string{t.Name})
+}
+
 func (r *Reconciler) propagateDependencyReadiness(ctx context.Context, t *eventingv1.Trigger, dependencyObjRef corev1.ObjectReference) error {
 	lister, err := r.sourceTracker.ListerFor(dependencyObjRef)
 	if err != nil {
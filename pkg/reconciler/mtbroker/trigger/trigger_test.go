@@ -70,6 +70,8 @@ const (
 	triggerName = "test-trigger"
 	triggerUID  = "test-trigger-uid"
 
+	otherTriggerName = "other-trigger"
+
 	triggerChannelAPIVersion = "messaging.knative.dev/v1"
 	triggerChannelKind       = "InMemoryChannel"
 	triggerChannelName       = "test-broker-kne-trigger"
@@ -650,6 +652,99 @@ func TestReconcile(t *testing.T) {
 				),
 			}},
 		},
+		{
+			Name: "Dependency ready, other dependency not ready",
+			Key:  testKey,
+			Objects: allBrokerObjectsReadyPlus([]runtime.Object{
+				makeReadySubscription(),
+				makeReadyPingSource(),
+				NewTrigger(otherTriggerName, testNS, brokerName, WithInitTriggerConditions),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithInitTriggerConditions,
+					WithDependencyAnnotation(dependencyAnnotation),
+					WithTriggerDependencies(makeTriggerDependency(otherTriggerName)),
+				)}...),
+			WantErr: false,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					// The first reconciliation will initialize the status conditions.
+					WithInitTriggerConditions,
+					WithDependencyAnnotation(dependencyAnnotation),
+					WithTriggerDependencies(makeTriggerDependency(otherTriggerName)),
+					WithTriggerBrokerReady(),
+					WithTriggerSubscribed(),
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerSubscriberResolvedSucceeded(),
+					WithTriggerDependencyUnknown("", ""),
+				),
+			}},
+		},
+		{
+			Name: "Dependencies ready",
+			Key:  testKey,
+			Objects: allBrokerObjectsReadyPlus([]runtime.Object{
+				makeReadySubscription(),
+				makeReadyPingSource(),
+				makeReadyTrigger(otherTriggerName),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithInitTriggerConditions,
+					WithDependencyAnnotation(dependencyAnnotation),
+					WithTriggerDependencies(makeTriggerDependency(otherTriggerName)),
+				)}...),
+			WantErr: false,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					// The first reconciliation will initialize the status conditions.
+					WithInitTriggerConditions,
+					WithDependencyAnnotation(dependencyAnnotation),
+					WithTriggerDependencies(makeTriggerDependency(otherTriggerName)),
+					WithTriggerBrokerReady(),
+					WithTriggerSubscribed(),
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerSubscriberResolvedSucceeded(),
+					WithTriggerDependencyReady(),
+				),
+			}},
+		},
+		{
+			Name: "Dependency cycle",
+			Key:  testKey,
+			Objects: allBrokerObjectsReadyPlus([]runtime.Object{
+				makeReadySubscription(),
+				NewTrigger(otherTriggerName, testNS, brokerName,
+					WithInitTriggerConditions,
+					WithTriggerDependencies(makeTriggerDependency(triggerName)),
+				),
+				NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					WithInitTriggerConditions,
+					WithTriggerDependencies(makeTriggerDependency(otherTriggerName)),
+				)}...),
+			WantErr: false,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewTrigger(triggerName, testNS, brokerName,
+					WithTriggerUID(triggerUID),
+					WithTriggerSubscriberURI(subscriberURI),
+					// The first reconciliation will initialize the status conditions.
+					WithInitTriggerConditions,
+					WithTriggerDependencies(makeTriggerDependency(otherTriggerName)),
+					WithTriggerBrokerReady(),
+					WithTriggerSubscribed(),
+					WithTriggerStatusSubscriberURI(subscriberURI),
+					WithTriggerSubscriberResolvedSucceeded(),
+					WithTriggerDependencyFailed("DependencyCycle", "The trigger depends on itself: test-trigger -> other-trigger -> test-trigger"),
+				),
+			}},
+		},
 	}
 
 	logger := logtesting.TestLogger(t)
@@ -922,6 +1017,24 @@ func makeReadyPingSource() *sourcesv1beta1.PingSource {
 		rtv1alpha1.WithPingSourceV1B1Sink(u),
 	)
 }
+func makeTriggerDependency(name string) duckv1.KReference {
+	return duckv1.KReference{
+		APIVersion: "eventing.knative.dev/v1",
+		Kind:       "Trigger",
+		Name:       name,
+	}
+}
+
+func makeReadyTrigger(name string) *eventingv1.Trigger {
+	return NewTrigger(name, testNS, brokerName,
+		WithInitTriggerConditions,
+		WithTriggerBrokerReady(),
+		WithTriggerSubscribed(),
+		WithTriggerSubscriberResolvedSucceeded(),
+		WithTriggerDependencyReady(),
+	)
+}
+
 func makeSubscriberKubernetesServiceAsUnstructured() *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
@@ -99,7 +99,6 @@ func NewSubscription(branchNumber int, p *v1.Parallel) *messagingv1.Subscription
 				Ref: p.Spec.Branches[branchNumber].Subscriber.Ref,
 				URI: p.Spec.Branches[branchNumber].Subscriber.URI,
 			},
-			Delivery: p.Spec.Branches[branchNumber].Delivery,
 		},
 	}
 
@@ -114,5 +113,8 @@ func NewSubscription(branchNumber int, p *v1.Parallel) *messagingv1.Subscription
 			URI: p.Spec.Reply.URI,
 		}
 	}
+	// The events the branch fails to handle continue to where its replies go.
+	branch := p.Spec.Branches[branchNumber]
+	r.Spec.Delivery = v1.DeliveryWithErrorPolicy(branch.Delivery, branch.Timeout, branch.OnError, r.Spec.Reply)
 	return r
 }
@@ -64,18 +64,23 @@ func NewController(
 	deploymentInformer := deploymentinformer.Get(ctx)
 	pingSourceInformer := pingsourceinformer.Get(ctx)
 
+	configs := reconcilersource.WatchConfigurations(ctx, component, cmw,
+		reconcilersource.WithLogging, reconcilersource.WithMetrics, reconcilersource.WithTracing, reconcilersource.WithFeatures,
+		reconcilersource.WithEventTransformers)
+
 	r := &Reconciler{
 		kubeClientSet:    kubeclient.Get(ctx),
 		pingLister:       pingSourceInformer.Lister(),
 		deploymentLister: deploymentInformer.Lister(),
 		leConfig:         leConfig,
 		loggingContext:   ctx,
-		configs:          reconcilersource.WatchConfigurations(ctx, component, cmw),
+		configs:          configs,
 	}
 
 	impl := pingsourcereconciler.NewImpl(ctx, r)
 
 	r.sinkResolver = resolver.NewURIResolver(ctx, impl.EnqueueKey)
+	r.deliveryLister = reconcilersource.WatchDeliveryStatuses(ctx, resourceGroup, impl.EnqueueKey)
 
 	logger.Info("Setting up event handlers")
 	pingSourceInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
@@ -27,6 +27,9 @@ import (
 	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/tracing/config"
 
+	"knative.dev/eventing/pkg/apis/feature"
+	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
+
 	// Fake injection informers
 	_ "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype/fake"
 	_ "knative.dev/eventing/pkg/client/injection/informers/sources/v1beta1/pingsource/fake"
@@ -66,6 +69,16 @@ func TestNew(t *testing.T) {
 			Data: map[string]string{
 				"_example": "test-config",
 			},
+		}, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      feature.FlagsConfigName,
+				Namespace: "knative-eventing",
+			},
+		}, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      reconcilersource.EventTransformersConfigName,
+				Namespace: "knative-eventing",
+			},
 		},
 	))
 
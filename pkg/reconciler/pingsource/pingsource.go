@@ -32,6 +32,7 @@ import (
 	"knative.dev/eventing/pkg/adapter/v2"
 
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	coordinationlisters "k8s.io/client-go/listers/coordination/v1"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
@@ -39,9 +40,11 @@ import (
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
 	"knative.dev/pkg/system"
+	tracingconfig "knative.dev/pkg/tracing/config"
 	"knative.dev/pkg/tracker"
 
 	"knative.dev/eventing/pkg/adapter/mtping"
+	"knative.dev/eventing/pkg/apis/feature"
 	"knative.dev/eventing/pkg/apis/sources/v1beta1"
 	pingsourcereconciler "knative.dev/eventing/pkg/client/injection/reconciler/sources/v1beta1/pingsource"
 	listers "knative.dev/eventing/pkg/client/listers/sources/v1beta1"
@@ -59,17 +62,28 @@ const (
 	containerName = "dispatcher"
 )
 
+// resourceGroup is the resource group the adapter reports the delivery
+// statuses of the PingSources under.
+var resourceGroup = v1beta1.Resource("pingsources").String()
+
 func newWarningSinkNotFound(sink *duckv1.Destination) pkgreconciler.Event {
 	b, _ := json.Marshal(sink)
 	return pkgreconciler.NewEvent(corev1.EventTypeWarning, "SinkNotFound", "Sink not found: %s", string(b))
 }
 
+func newWarningBrokerNotFound(name string) pkgreconciler.Event {
+	return pkgreconciler.NewEvent(corev1.EventTypeWarning, "BrokerNotFound", "Broker not found: %q", name)
+}
+
 type Reconciler struct {
 	kubeClientSet kubernetes.Interface
 
 	// listers index properties about resources
 	pingLister       listers.PingSourceLister
 	deploymentLister appsv1listers.DeploymentLister
+	// deliveryLister lists the Leases holding the delivery statuses of the
+	// PingSources.
+	deliveryLister coordinationlisters.LeaseLister
 
 	// tracking mt adapter deployment changes
 	tracker tracker.Interface
@@ -94,7 +108,16 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1beta1.PingSour
 	// 3. Create the EventType that it can emit.
 	//     - Will be garbage collected by K8s when this PingSource is deleted.
 
-	dest := source.Spec.Sink.DeepCopy()
+	// Sources disabled by the adapter for their job panicking are left not
+	// ready until their spec changes.
+	if source.Status.IsJobPanicking() {
+		if source.Status.ObservedGeneration == source.Generation {
+			return nil
+		}
+		source.Status.ClearJobPanicking()
+	}
+
+	dest := source.SinkDestination()
 	if dest.Ref != nil {
 		// To call URIFromDestination(), dest.Ref must have a Namespace. If there is
 		// no Namespace defined in dest.Ref, we will use the Namespace of the source
@@ -105,12 +128,56 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1beta1.PingSour
 		}
 	}
 
-	sinkURI, err := r.sinkResolver.URIFromDestinationV1(ctx, *dest, source)
+	sinkURI, err := r.sinkResolver.URIFromDestinationV1(ctx, dest, source)
 	if err != nil {
+		if source.Spec.BrokerName != "" {
+			source.Status.MarkNoSink("BrokerNotFound", "Broker %q not found", source.Spec.BrokerName)
+			return newWarningBrokerNotFound(source.Spec.BrokerName)
+		}
 		source.Status.MarkNoSink("NotFound", "")
-		return newWarningSinkNotFound(dest)
+		return newWarningSinkNotFound(&dest)
+	}
+	source.Status.MarkSink(v1beta1.PrefixSinkPath(sinkURI, source.Spec.SinkPathPrefix))
+
+	// The shadow sink is best effort, the source is ready without it.
+	source.Status.ShadowSinkURI = nil
+	if shadow := source.Spec.ShadowSink.DeepCopy(); shadow != nil {
+		if shadow.Ref != nil && shadow.Ref.Namespace == "" {
+			shadow.Ref.Namespace = source.GetNamespace()
+		}
+		if shadowURI, err := r.sinkResolver.URIFromDestinationV1(ctx, *shadow, source); err != nil {
+			logging.FromContext(ctx).Warnw("Unable to resolve the shadow sink, not mirroring events", zap.Error(err))
+		} else {
+			source.Status.ShadowSinkURI = shadowURI
+		}
+	}
+
+	// So is the alert sink.
+	source.Status.AlertSinkURI = nil
+	if alert := source.Spec.AlertSink.DeepCopy(); alert != nil {
+		if alert.Ref != nil && alert.Ref.Namespace == "" {
+			alert.Ref.Namespace = source.GetNamespace()
+		}
+		if alertURI, err := r.sinkResolver.URIFromDestinationV1(ctx, *alert, source); err != nil {
+			logging.FromContext(ctx).Warnw("Unable to resolve the alert sink, not sending alerts", zap.Error(err))
+		} else {
+			source.Status.AlertSinkURI = alertURI
+		}
+	}
+
+	// And so is the dead letter sink, the events being dropped without it.
+	source.Status.DeadLetterSinkURI = nil
+	if source.Spec.Delivery != nil && source.Spec.Delivery.DeadLetterSink != nil {
+		dls := source.Spec.Delivery.DeadLetterSink.DeepCopy()
+		if dls.Ref != nil && dls.Ref.Namespace == "" {
+			dls.Ref.Namespace = source.GetNamespace()
+		}
+		if dlsURI, err := r.sinkResolver.URIFromDestinationV1(ctx, *dls, source); err != nil {
+			logging.FromContext(ctx).Warnw("Unable to resolve the dead letter sink, not dead-lettering events", zap.Error(err))
+		} else {
+			source.Status.DeadLetterSinkURI = dlsURI
+		}
 	}
-	source.Status.MarkSink(sinkURI)
 
 	// Make sure the global mt receive adapter is running
 	d, err := r.reconcileReceiveAdapter(ctx, source)
@@ -138,9 +205,26 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1beta1.PingSour
 		Source: v1beta1.PingSourceSource(source.Namespace, source.Name),
 	}}
 
+	// Last, the failing deliveries making the PingSource not ready.
+	r.propagateDeliveryStatus(ctx, source)
+
 	return nil
 }
 
+func (r *Reconciler) propagateDeliveryStatus(ctx context.Context, source *v1beta1.PingSource) {
+	status, err := reconcilersource.DeliveryStatus(r.deliveryLister, resourceGroup, source.Namespace, source.Name)
+	switch {
+	case err != nil:
+		logging.FromContext(ctx).Warnw("Unable to get the delivery status", zap.Error(err))
+	case status == nil:
+		source.Status.ClearSinkDeliverable()
+	case status.ConsecutiveFailures > 0:
+		source.Status.MarkSinkUndeliverable("DeliveryFailed", "%s", reconcilersource.DeliveryFailureMessage(status))
+	default:
+		source.Status.MarkSinkDeliverable()
+	}
+}
+
 func (r *Reconciler) reconcileReceiveAdapter(ctx context.Context, source *v1beta1.PingSource) (*appsv1.Deployment, error) {
 	loggingConfig, err := logging.ConfigToJSON(r.configs.LoggingConfig())
 	if err != nil {
@@ -152,12 +236,27 @@ func (r *Reconciler) reconcileReceiveAdapter(ctx context.Context, source *v1beta
 		logging.FromContext(ctx).Errorw("error while converting metrics config to JSON", zap.Any("receiveAdapter", err))
 	}
 
+	// The adapter samples the spans of the fires as told by config-tracing.
+	tracingConfig, err := tracingconfig.TracingConfigToJSON(r.configs.TracingConfig())
+	if err != nil {
+		logging.FromContext(ctx).Errorw("error while converting tracing config to JSON", zap.Any("receiveAdapter", err))
+	}
+
+	batchMaxSize, batchLinger := adapter.GetBatching(logging.FromContext(ctx))
 	args := resources.Args{
 		LoggingConfig:   loggingConfig,
 		MetricsConfig:   metricsConfig,
+		TracingConfig:   tracingConfig,
 		LeConfig:        r.leConfig,
 		NoShutdownAfter: mtping.GetNoShutDownAfterValue(),
 		SinkTimeout:     adapter.GetSinkTimeout(logging.FromContext(ctx)),
+		Sharding:        mtping.ShardingEnabled(),
+
+		EventTypeAutoCreate: r.configs.FeatureFlags().IsEnabled(feature.EventTypeAutoCreate),
+		EventTransformers:   r.configs.EventTransformRules(),
+
+		BatchMaxSize: batchMaxSize,
+		BatchLinger:  batchLinger,
 	}
 	expected := resources.MakeReceiveAdapterEnvVar(args)
 
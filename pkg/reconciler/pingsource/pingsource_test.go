@@ -18,6 +18,7 @@ package pingsource
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,11 +29,13 @@ import (
 	"knative.dev/eventing/pkg/adapter/mtping"
 
 	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	clientgotesting "k8s.io/client-go/testing"
+	eventingduckv1 "knative.dev/eventing/pkg/apis/duck/v1"
 	sourcesv1beta1 "knative.dev/eventing/pkg/apis/sources/v1beta1"
 	fakeeventingclient "knative.dev/eventing/pkg/client/injection/client/fake"
 	"knative.dev/eventing/pkg/client/injection/reconciler/sources/v1beta1/pingsource"
@@ -49,6 +52,7 @@ import (
 	"knative.dev/pkg/tracker"
 
 	. "knative.dev/eventing/pkg/reconciler/testing"
+	rtv1 "knative.dev/eventing/pkg/reconciler/testing/v1"
 	rtv1beta1 "knative.dev/eventing/pkg/reconciler/testing/v1beta1"
 	_ "knative.dev/pkg/client/injection/ducks/duck/v1beta1/addressable/fake"
 	. "knative.dev/pkg/reconciler/testing"
@@ -65,6 +69,16 @@ var (
 	}
 	sinkDNS = "sink.mynamespace.svc." + network.GetClusterDomainName()
 	sinkURI = apis.HTTP(sinkDNS)
+
+	shadowURI, _ = apis.ParseURL("https://shadow.example.com/events")
+	alertURI, _  = apis.ParseURL("https://alerts.example.com/events")
+	dlsURI, _    = apis.ParseURL("https://dls.example.com/events")
+
+	brokerURI = &apis.URL{
+		Scheme: "http",
+		Host:   "broker-ingress.knative-eventing.svc." + network.GetClusterDomainName(),
+		Path:   "/" + testNS + "/" + brokerName,
+	}
 )
 
 const (
@@ -75,6 +89,7 @@ const (
 	testData     = "data"
 
 	sinkName   = "testsink"
+	brokerName = "testbroker"
 	generation = 1
 )
 
@@ -172,6 +187,460 @@ func TestAllCases(t *testing.T) {
 					WithPingSourceV1B1StatusObservedGeneration(generation),
 				),
 			}},
+		}, {
+			Name: "valid with sink path prefix",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:       testSchedule,
+						JsonData:       testData,
+						SinkPathPrefix: "/gateway",
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:       testSchedule,
+						JsonData:       testData,
+						SinkPathPrefix: "/gateway",
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(&apis.URL{Scheme: "http", Host: sinkDNS, Path: "/gateway"}),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+				),
+			}},
+		}, {
+			Name: "missing broker",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:   testSchedule,
+						JsonData:   testData,
+						BrokerName: brokerName,
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:   testSchedule,
+						JsonData:   testData,
+						BrokerName: brokerName,
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+					WithPingSourceV1B1BrokerNotFound(brokerName),
+				),
+			}},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeWarning, "BrokerNotFound", `Broker not found: "testbroker"`),
+			},
+		}, {
+			Name: "valid broker",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:   testSchedule,
+						JsonData:   testData,
+						BrokerName: brokerName,
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+				rtv1.NewBroker(brokerName, testNS,
+					rtv1.WithInitBrokerConditions,
+					rtv1.WithBrokerAddressURI(brokerURI),
+				),
+				makeAvailableMTAdapter(),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:   testSchedule,
+						JsonData:   testData,
+						BrokerName: brokerName,
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(brokerURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+				),
+			}},
+		}, {
+			Name: "valid with shadow sink",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:   testSchedule,
+						JsonData:   testData,
+						ShadowSink: &duckv1.Destination{URI: shadowURI},
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:   testSchedule,
+						JsonData:   testData,
+						ShadowSink: &duckv1.Destination{URI: shadowURI},
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1ShadowSink(shadowURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+				),
+			}},
+		}, {
+			Name: "valid with alert sink",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:  testSchedule,
+						JsonData:  testData,
+						AlertSink: &duckv1.Destination{URI: alertURI},
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule:  testSchedule,
+						JsonData:  testData,
+						AlertSink: &duckv1.Destination{URI: alertURI},
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1AlertSink(alertURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+				),
+			}},
+		}, {
+			Name: "valid with dead letter sink",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						Delivery: &eventingduckv1.DeliverySpec{
+							DeadLetterSink: &duckv1.Destination{URI: dlsURI},
+						},
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						Delivery: &eventingduckv1.DeliverySpec{
+							DeadLetterSink: &duckv1.Destination{URI: dlsURI},
+						},
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1DeadLetterSink(dlsURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+				),
+			}},
+		}, {
+			Name: "valid with unresolvable shadow sink",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						ShadowSink: &duckv1.Destination{
+							Ref: &duckv1.KReference{
+								Name:       "missing",
+								Kind:       "Channel",
+								APIVersion: "messaging.knative.dev/v1beta1",
+							},
+						},
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						ShadowSink: &duckv1.Destination{
+							Ref: &duckv1.KReference{
+								Name:       "missing",
+								Kind:       "Channel",
+								APIVersion: "messaging.knative.dev/v1beta1",
+							},
+						},
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+				),
+			}},
+		}, {
+			Name: "job panicking",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+					WithPingSourceV1B1JobPanicking,
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+			},
+			Key: testNS + "/" + sourceName,
+		}, {
+			Name: "job panicking, spec changed",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation+1),
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+					WithPingSourceV1B1JobPanicking,
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation+1),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation+1),
+				),
+			}},
+		}, {
+			Name: "sink undeliverable",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+				makeDeliveryStatusLease(3),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+					WithPingSourceV1B1SinkUndeliverable("3 consecutive deliveries failed, the last one at 2020-11-02T10:00:00Z: 503: Service Unavailable"),
+				),
+			}},
+		}, {
+			Name: "sink deliverable",
+			Objects: []runtime.Object{
+				NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+				),
+				rtv1beta1.NewChannel(sinkName, testNS,
+					rtv1beta1.WithInitChannelConditions,
+					rtv1beta1.WithChannelAddress(sinkDNS),
+				),
+				makeAvailableMTAdapter(),
+				makeDeliveryStatusLease(0),
+			},
+			Key: testNS + "/" + sourceName,
+			WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+				Object: NewPingSourceV1Beta1(sourceName, testNS,
+					WithPingSourceV1B1Spec(sourcesv1beta1.PingSourceSpec{
+						Schedule: testSchedule,
+						JsonData: testData,
+						SourceSpec: duckv1.SourceSpec{
+							Sink: sinkDest,
+						},
+					}),
+					WithPingSourceV1B1UID(sourceUID),
+					WithPingSourceV1B1ObjectMetaGeneration(generation),
+					// Status Update:
+					WithInitPingSourceV1B1Conditions,
+					WithPingSourceV1B1Deployed,
+					WithPingSourceV1B1Sink(sinkURI),
+					WithPingSourceV1B1CloudEventAttributes,
+					WithPingSourceV1B1StatusObservedGeneration(generation),
+					WithPingSourceV1B1SinkDeliverable,
+				),
+			}},
 		},
 	}
 
@@ -182,6 +651,7 @@ func TestAllCases(t *testing.T) {
 			kubeClientSet:    fakekubeclient.Get(ctx),
 			pingLister:       listers.GetPingSourceV1beta1Lister(),
 			deploymentLister: listers.GetDeploymentLister(),
+			deliveryLister:   listers.GetLeaseLister(),
 			tracker:          tracker.New(func(types.NamespacedName) {}, 0),
 		}
 		r.sinkResolver = resolver.NewURIResolver(ctx, func(types.NamespacedName) {})
@@ -195,10 +665,27 @@ func TestAllCases(t *testing.T) {
 	))
 }
 
+func makeDeliveryStatusLease(failures int) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      adapter.DeliveryStatusLeaseName(resourceGroup, sourceName),
+			Labels:    map[string]string{adapter.DeliveryStatusLabelKey: resourceGroup},
+			Annotations: map[string]string{
+				adapter.DeliverySourceAnnotationKey:      sourceName,
+				adapter.ConsecutiveFailuresAnnotationKey: strconv.Itoa(failures),
+				adapter.LastErrorAnnotationKey:           "503: Service Unavailable",
+				adapter.LastErrorTimeAnnotationKey:       "2020-11-02T10:00:00Z",
+			},
+		},
+	}
+}
+
 func MakeMTAdapter() *appsv1.Deployment {
 	args := resources.Args{
 		NoShutdownAfter: mtping.GetNoShutDownAfterValue(),
 		SinkTimeout:     adapter.GetSinkTimeout(nil),
+		Sharding:        mtping.ShardingEnabled(),
 	}
 	return &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -22,20 +22,35 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"knative.dev/eventing/pkg/adapter/mtping"
 	"knative.dev/eventing/pkg/adapter/v2"
+	"knative.dev/eventing/pkg/apis/feature"
 	"knative.dev/pkg/system"
 )
 
 type Args struct {
 	MetricsConfig   string
 	LoggingConfig   string
+	TracingConfig   string
 	LeConfig        string
 	NoShutdownAfter int
 	SinkTimeout     int
+	Sharding        bool
+	// EventTypeAutoCreate tells the adapter to create the EventTypes of
+	// the PingSources.
+	EventTypeAutoCreate bool
+	// EventTransformers is the json form of the rules transforming the
+	// events, empty when there are none.
+	EventTransformers string
+	// BatchMaxSize is the maximum number of events of the batched requests,
+	// the events being sent one by one when lower than 2.
+	BatchMaxSize int
+	// BatchLinger is how long the batches wait for more events, empty for
+	// the default of the adapter.
+	BatchLinger string
 }
 
 // MakeReceiveAdapterEnvVar generates the environment variables for the pingsources
 func MakeReceiveAdapterEnvVar(args Args) []corev1.EnvVar {
-	return []corev1.EnvVar{{
+	envs := []corev1.EnvVar{{
 		Name: system.NamespaceEnvKey,
 		ValueFrom: &corev1.EnvVarSource{
 			FieldRef: &corev1.ObjectFieldSelector{
@@ -48,6 +63,9 @@ func MakeReceiveAdapterEnvVar(args Args) []corev1.EnvVar {
 	}, {
 		Name:  adapter.EnvConfigLoggingConfig,
 		Value: args.LoggingConfig,
+	}, {
+		Name:  adapter.EnvConfigTracingConfig,
+		Value: args.TracingConfig,
 	}, {
 		Name:  adapter.EnvConfigLeaderElectionConfig,
 		Value: args.LeConfig,
@@ -57,6 +75,28 @@ func MakeReceiveAdapterEnvVar(args Args) []corev1.EnvVar {
 	}, {
 		Name:  adapter.EnvSinkTimeout,
 		Value: strconv.Itoa(args.SinkTimeout),
+	}, {
+		Name:  mtping.EnvSharding,
+		Value: strconv.FormatBool(args.Sharding),
+	}, {
+		Name:  mtping.EnvHealthPort,
+		Value: strconv.Itoa(mtping.DefaultHealthPort),
+	}, {
+		Name:  mtping.EnvDeliveryStatus,
+		Value: "true",
 	}}
 
+	if args.EventTypeAutoCreate {
+		envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigEventTypeAutoCreate, Value: string(feature.Enabled)})
+	}
+	if args.EventTransformers != "" {
+		envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigEventTransformers, Value: args.EventTransformers})
+	}
+	if args.BatchMaxSize > 1 {
+		envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigBatchMaxSize, Value: strconv.Itoa(args.BatchMaxSize)})
+		if args.BatchLinger != "" {
+			envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigBatchLinger, Value: args.BatchLinger})
+		}
+	}
+	return envs
 }
@@ -29,8 +29,10 @@ func TestMakePingAdapter(t *testing.T) {
 	args := Args{
 		MetricsConfig:   "metrics",
 		LoggingConfig:   "logging",
+		TracingConfig:   "tracing",
 		NoShutdownAfter: 40,
 		SinkTimeout:     48,
+		Sharding:        true,
 	}
 
 	want := []corev1.EnvVar{{
@@ -46,6 +48,9 @@ func TestMakePingAdapter(t *testing.T) {
 	}, {
 		Name:  "K_LOGGING_CONFIG",
 		Value: "logging",
+	}, {
+		Name:  "K_TRACING_CONFIG",
+		Value: "tracing",
 	}, {
 		Name:  "K_LEADER_ELECTION_CONFIG",
 		Value: "",
@@ -55,6 +60,15 @@ func TestMakePingAdapter(t *testing.T) {
 	}, {
 		Name:  "K_SINK_TIMEOUT",
 		Value: "48",
+	}, {
+		Name:  "K_SHARDING",
+		Value: "true",
+	}, {
+		Name:  "K_HEALTH_PORT",
+		Value: "8080",
+	}, {
+		Name:  "K_SOURCE_DELIVERY_STATUS",
+		Value: "true",
 	}}
 
 	got := MakeReceiveAdapterEnvVar(args)
@@ -63,3 +77,36 @@ func TestMakePingAdapter(t *testing.T) {
 		t.Error("unexpected condition (-want, +got) =", diff)
 	}
 }
+
+func TestMakePingAdapterEventTypeAutoCreate(t *testing.T) {
+	got := MakeReceiveAdapterEnvVar(Args{EventTypeAutoCreate: true})
+
+	want := corev1.EnvVar{Name: "K_EVENTTYPE_AUTO_CREATE", Value: "enabled"}
+	if diff := cmp.Diff(want, got[len(got)-1]); diff != "" {
+		t.Error("unexpected env var (-want, +got) =", diff)
+	}
+}
+
+func TestMakePingAdapterEventTransformers(t *testing.T) {
+	got := MakeReceiveAdapterEnvVar(Args{EventTransformers: `[{"remove":"$.data.password"}]`})
+
+	want := corev1.EnvVar{Name: "K_EVENT_TRANSFORMERS", Value: `[{"remove":"$.data.password"}]`}
+	if diff := cmp.Diff(want, got[len(got)-1]); diff != "" {
+		t.Error("unexpected env var (-want, +got) =", diff)
+	}
+}
+
+func TestMakePingAdapterBatching(t *testing.T) {
+	got := MakeReceiveAdapterEnvVar(Args{BatchMaxSize: 10, BatchLinger: "50ms"})
+
+	want := []corev1.EnvVar{{Name: "K_BATCH_MAX_SIZE", Value: "10"}, {Name: "K_BATCH_LINGER", Value: "50ms"}}
+	if diff := cmp.Diff(want, got[len(got)-2:]); diff != "" {
+		t.Error("unexpected env vars (-want, +got) =", diff)
+	}
+
+	// Not batching a single event.
+	got = MakeReceiveAdapterEnvVar(Args{BatchMaxSize: 1})
+	if last := got[len(got)-1]; last.Name == "K_BATCH_MAX_SIZE" {
+		t.Error("unexpected env var", last)
+	}
+}
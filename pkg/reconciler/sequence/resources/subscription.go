@@ -56,7 +56,6 @@ func NewSubscription(stepNumber int, s *v1.Sequence) *messagingv1.Subscription {
 				Ref: s.Spec.Steps[stepNumber].Destination.Ref,
 				URI: s.Spec.Steps[stepNumber].Destination.URI,
 			},
-			Delivery: s.Spec.Steps[stepNumber].Delivery,
 		},
 	}
 	// If it's not the last step, use the next channel as the reply to, if it's the very
@@ -76,5 +75,8 @@ func NewSubscription(stepNumber int, s *v1.Sequence) *messagingv1.Subscription {
 			URI: s.Spec.Reply.URI,
 		}
 	}
+	// The events the step fails to handle continue to where its replies go.
+	step := s.Spec.Steps[stepNumber]
+	r.Spec.Delivery = v1.DeliveryWithErrorPolicy(step.Delivery, step.Timeout, step.OnError, r.Spec.Reply)
 	return r
 }
@@ -19,9 +19,15 @@ package sinkbinding
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
 
 	sbinformer "knative.dev/eventing/pkg/client/injection/informers/sources/v1/sinkbinding"
 	"knative.dev/pkg/client/injection/ducks/duck/v1/podspecable"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/client/injection/kube/informers/core/v1/namespace"
 	"knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
@@ -47,7 +53,11 @@ const (
 )
 
 type SinkBindingSubResourcesReconciler struct {
-	res *resolver.URIResolver
+	res        *resolver.URIResolver
+	kubeClient kubernetes.Interface
+	// probeClient probes the sinks, enqueueAfter probing them again later.
+	probeClient  *http.Client
+	enqueueAfter func(obj interface{}, after time.Duration)
 }
 
 // NewController returns a new SinkBinding reconciler.
@@ -95,7 +105,10 @@ func NewController(
 
 	sbResolver := resolver.NewURIResolver(ctx, impl.EnqueueKey)
 	c.SubResourcesReconciler = &SinkBindingSubResourcesReconciler{
-		res: sbResolver,
+		res:          sbResolver,
+		kubeClient:   kubeclient.Get(ctx),
+		probeClient:  &http.Client{},
+		enqueueAfter: impl.EnqueueAfter,
 	}
 
 	c.WithContext = func(ctx context.Context, b psbinding.Bindable) (context.Context, error) {
@@ -155,6 +168,23 @@ func (s *SinkBindingSubResourcesReconciler) Reconcile(ctx context.Context, b psb
 		return err
 	}
 	sb.Status.MarkSink(uri)
+
+	if s.kubeClient != nil {
+		if err := reconcileSinkConfigMap(ctx, s.kubeClient, sb, uri); err != nil {
+			logging.FromContext(ctx).Errorw("Failed to reconcile the sink ConfigMap", zap.Error(err))
+			sb.Status.MarkBindingUnavailable("SinkConfigMapFailed", err.Error())
+			return err
+		}
+	}
+
+	if s.probeClient != nil {
+		if err := probeSink(ctx, s.probeClient, uri); err != nil {
+			sb.Status.MarkSinkUnavailable("SinkUnreachable", "The sink %s could not be reached: %v", uri, err)
+		} else {
+			sb.Status.MarkSinkAvailable()
+		}
+		s.enqueueAfter(sb, probeInterval)
+	}
 	return nil
 }
 
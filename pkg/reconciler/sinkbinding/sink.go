@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sinkbinding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	v1 "knative.dev/eventing/pkg/apis/sources/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmeta"
+)
+
+const (
+	// probeTimeout bounds the probes of the sinks.
+	probeTimeout = 2 * time.Second
+	// probeInterval is the period the sinks are probed again at.
+	probeInterval = 5 * time.Minute
+)
+
+// probeSink tells whether the sink answers HTTP requests. Any response
+// counts, the sinks only having to accept CloudEvents.
+func probeSink(ctx context.Context, client *http.Client, uri *apis.URL) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodOptions, uri.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// makeSinkConfigMap returns the ConfigMap projecting the sink of sb into
+// its subject.
+func makeSinkConfigMap(sb *v1.SinkBinding, uri *apis.URL) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            sb.SinkConfigMapName(),
+			Namespace:       sb.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(sb)},
+		},
+		Data: map[string]string{
+			v1.SinkBindingSinkKey: uri.String(),
+		},
+	}
+}
+
+// reconcileSinkConfigMap keeps the ConfigMap of the refreshed sink of sb up
+// to date, deleting it once sb stops refreshing it.
+func reconcileSinkConfigMap(ctx context.Context, kubeClient kubernetes.Interface, sb *v1.SinkBinding, uri *apis.URL) error {
+	configMaps := kubeClient.CoreV1().ConfigMaps(sb.Namespace)
+	current, err := configMaps.Get(ctx, sb.SinkConfigMapName(), metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		if !sb.RefreshesSink() {
+			return nil
+		}
+		if _, err := configMaps.Create(ctx, makeSinkConfigMap(sb, uri), metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create the sink ConfigMap: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get the sink ConfigMap: %w", err)
+	}
+
+	if !metav1.IsControlledBy(current, sb) {
+		return fmt.Errorf("the sink ConfigMap %q is not owned by the SinkBinding", current.Name)
+	}
+	if !sb.RefreshesSink() {
+		if err := configMaps.Delete(ctx, current.Name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete the sink ConfigMap: %w", err)
+		}
+		return nil
+	}
+	if current.Data[v1.SinkBindingSinkKey] == uri.String() {
+		return nil
+	}
+	current = current.DeepCopy()
+	current.Data = makeSinkConfigMap(sb, uri).Data
+	if _, err := configMaps.Update(ctx, current, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update the sink ConfigMap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sinkbinding
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	v1 "knative.dev/eventing/pkg/apis/sources/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestProbeSink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	uri, _ := apis.ParseURL(server.URL)
+
+	if err := probeSink(context.Background(), server.Client(), uri); err != nil {
+		t.Error("Expected the sink to be available:", err)
+	}
+	server.Close()
+	if err := probeSink(context.Background(), server.Client(), uri); err == nil {
+		t.Error("Expected the closed sink to be unavailable")
+	}
+}
+
+func TestReconcileSinkConfigMap(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := fake.NewSimpleClientset()
+	sb := &v1.SinkBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "binding",
+			Namespace:   "ns",
+			UID:         "uid",
+			Annotations: map[string]string{v1.SinkBindingRefreshAnnotation: "true"},
+		},
+	}
+	get := func() string {
+		cm, err := kubeClient.CoreV1().ConfigMaps("ns").Get(ctx, "binding-sinkbinding", metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		return cm.Data[v1.SinkBindingSinkKey]
+	}
+
+	if err := reconcileSinkConfigMap(ctx, kubeClient, sb, apis.HTTP("first.ns.svc.cluster.local")); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got := get(); got != "http://first.ns.svc.cluster.local" {
+		t.Errorf("Expected the sink ConfigMap to be created, got %q", got)
+	}
+
+	if err := reconcileSinkConfigMap(ctx, kubeClient, sb, apis.HTTP("second.ns.svc.cluster.local")); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got := get(); got != "http://second.ns.svc.cluster.local" {
+		t.Errorf("Expected the sink ConfigMap to be updated, got %q", got)
+	}
+
+	sb.Annotations = nil
+	if err := reconcileSinkConfigMap(ctx, kubeClient, sb, apis.HTTP("second.ns.svc.cluster.local")); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps("ns").Get(ctx, "binding-sinkbinding", metav1.GetOptions{}); err == nil {
+		t.Error("Expected the sink ConfigMap to be deleted")
+	}
+}
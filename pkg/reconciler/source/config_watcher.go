@@ -18,6 +18,7 @@ package source
 
 import (
 	"context"
+	"encoding/json"
 
 	"go.uber.org/zap"
 
@@ -28,12 +29,23 @@ import (
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics"
 	tracingconfig "knative.dev/pkg/tracing/config"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	"knative.dev/eventing/pkg/apis/feature"
 )
 
 const (
 	EnvLoggingCfg = "K_LOGGING_CONFIG"
 	EnvMetricsCfg = "K_METRICS_CONFIG"
 	EnvTracingCfg = "K_TRACING_CONFIG"
+
+	EnvEventTypeAutoCreate = "K_EVENTTYPE_AUTO_CREATE"
+	EnvEventTransformers   = "K_EVENT_TRANSFORMERS"
+
+	// EventTransformersConfigName is the ConfigMap of the rules transforming
+	// the events of the adapters, under EventTransformersRulesKey.
+	EventTransformersConfigName = "config-event-transformers"
+	EventTransformersRulesKey   = "rules"
 )
 
 type ConfigAccessor interface {
@@ -56,6 +68,10 @@ type ConfigWatcher struct {
 	loggingCfg *logging.Config
 	metricsCfg *metrics.ExporterOptions
 	tracingCfg *tracingconfig.Config
+	features   feature.Flags
+	// transformRules is the json form of the event transform rules, empty
+	// when there are none.
+	transformRules string
 }
 
 // configWatcherOption is a function option for ConfigWatchers.
@@ -104,6 +120,17 @@ func WithTracing(cw *ConfigWatcher, cmw configmap.Watcher) {
 	watchConfigMap(cmw, tracingconfig.ConfigName, cw.updateFromTracingConfigMap)
 }
 
+// WithFeatures observes the feature flags ConfigMap.
+func WithFeatures(cw *ConfigWatcher, cmw configmap.Watcher) {
+	cw.features = feature.Flags{}
+	watchConfigMap(cmw, feature.FlagsConfigName, cw.updateFromFeaturesConfigMap)
+}
+
+// WithEventTransformers observes the event transformers ConfigMap.
+func WithEventTransformers(cw *ConfigWatcher, cmw configmap.Watcher) {
+	watchConfigMap(cmw, EventTransformersConfigName, cw.updateFromEventTransformersConfigMap)
+}
+
 func watchConfigMap(cmw configmap.Watcher, cmName string, obs configmap.Observer) {
 	if dcmw, ok := cmw.(configmap.DefaultingWatcher); ok {
 		dcmw.WatchWithDefault(corev1.ConfigMap{
@@ -140,6 +167,14 @@ func (cw *ConfigWatcher) TracingConfig() *tracingconfig.Config {
 	return cw.tracingCfg
 }
 
+// FeatureFlags returns the feature flags from the ConfigWatcher.
+func (cw *ConfigWatcher) FeatureFlags() feature.Flags {
+	if cw == nil {
+		return nil
+	}
+	return cw.features
+}
+
 func (cw *ConfigWatcher) updateFromLoggingConfigMap(cfg *corev1.ConfigMap) {
 	if cfg == nil {
 		return
@@ -192,6 +227,55 @@ func (cw *ConfigWatcher) updateFromTracingConfigMap(cfg *corev1.ConfigMap) {
 	cw.logger.Debugw("Updated tracing config from ConfigMap", zap.Any("ConfigMap", cfg))
 }
 
+func (cw *ConfigWatcher) updateFromFeaturesConfigMap(cfg *corev1.ConfigMap) {
+	if cfg == nil {
+		return
+	}
+
+	features, err := feature.NewFlagsConfigFromConfigMap(cfg)
+	if err != nil {
+		cw.logger.Warnw("failed to create feature flags from ConfigMap", zap.String("cfg.Name", cfg.Name), zap.Error(err))
+		return
+	}
+
+	cw.features = features
+
+	cw.logger.Debugw("Updated feature flags from ConfigMap", zap.Any("ConfigMap", cfg))
+}
+
+func (cw *ConfigWatcher) updateFromEventTransformersConfigMap(cfg *corev1.ConfigMap) {
+	if cfg == nil {
+		return
+	}
+
+	rules, err := adapter.ParseTransformRules(cfg.Data[EventTransformersRulesKey])
+	if err != nil {
+		cw.logger.Warnw("failed to parse the event transform rules from ConfigMap", zap.String("cfg.Name", cfg.Name), zap.Error(err))
+		return
+	}
+
+	cw.transformRules = ""
+	if len(rules) > 0 {
+		b, err := json.Marshal(rules)
+		if err != nil {
+			cw.logger.Warnw("Error while serializing the event transform rules", zap.Error(err))
+			return
+		}
+		cw.transformRules = string(b)
+	}
+
+	cw.logger.Debugw("Updated event transform rules from ConfigMap", zap.Any("ConfigMap", cfg))
+}
+
+// EventTransformRules returns the json form of the event transform rules
+// from the ConfigWatcher, empty when there are none.
+func (cw *ConfigWatcher) EventTransformRules() string {
+	if cw == nil {
+		return ""
+	}
+	return cw.transformRules
+}
+
 // ToEnvVars serializes the contents of the ConfigWatcher to individual
 // environment variables.
 func (cw *ConfigWatcher) ToEnvVars() []corev1.EnvVar {
@@ -200,6 +284,11 @@ func (cw *ConfigWatcher) ToEnvVars() []corev1.EnvVar {
 	envs = maybeAppendEnvVar(envs, cw.loggingConfigEnvVar(), cw.LoggingConfig() != nil)
 	envs = maybeAppendEnvVar(envs, cw.metricsConfigEnvVar(), cw.MetricsConfig() != nil)
 	envs = maybeAppendEnvVar(envs, cw.tracingConfigEnvVar(), cw.TracingConfig() != nil)
+	// Only set when enabled, the adapters not creating EventTypes by default.
+	envs = maybeAppendEnvVar(envs, corev1.EnvVar{Name: EnvEventTypeAutoCreate, Value: string(feature.Enabled)},
+		cw.FeatureFlags().IsEnabled(feature.EventTypeAutoCreate))
+	envs = maybeAppendEnvVar(envs, corev1.EnvVar{Name: EnvEventTransformers, Value: cw.EventTransformRules()},
+		cw.EventTransformRules() != "")
 
 	return envs
 }
@@ -31,6 +31,8 @@ import (
 	"knative.dev/pkg/metrics"
 	tracingconfig "knative.dev/pkg/tracing/config"
 
+	"knative.dev/eventing/pkg/apis/feature"
+
 	_ "knative.dev/pkg/metrics/testing"
 )
 
@@ -130,6 +132,83 @@ func TestNewConfigWatcher_withOptions(t *testing.T) {
 	}
 }
 
+func TestNewConfigWatcher_withFeatures(t *testing.T) {
+	testCases := []struct {
+		name       string
+		data       map[string]string
+		expectEnvs []corev1.EnvVar
+	}{
+		{
+			name:       "With the EventType creation enabled",
+			data:       map[string]string{feature.EventTypeAutoCreate: "enabled"},
+			expectEnvs: []corev1.EnvVar{{Name: EnvEventTypeAutoCreate, Value: "enabled"}},
+		},
+		{
+			name:       "With the EventType creation disabled",
+			data:       map[string]string{feature.EventTypeAutoCreate: "disabled"},
+			expectEnvs: []corev1.EnvVar{},
+		},
+		{
+			name:       "With empty data",
+			expectEnvs: []corev1.EnvVar{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := loggingtesting.TestContextWithLogger(t)
+			cw := WatchConfigurations(ctx, testComponent,
+				configmap.NewStaticWatcher(newTestConfigMap(feature.FlagsConfigName, tc.data)),
+				WithFeatures,
+			)
+
+			assert.NotNil(t, cw.FeatureFlags(), "feature flags should be enabled")
+			assert.Equal(t, tc.expectEnvs, cw.ToEnvVars())
+		})
+	}
+}
+
+func TestNewConfigWatcher_withEventTransformers(t *testing.T) {
+	testCases := []struct {
+		name       string
+		data       map[string]string
+		expectEnvs []corev1.EnvVar
+	}{
+		{
+			name: "With YAML rules",
+			data: map[string]string{EventTransformersRulesKey: `
+- remove: $.data.password
+- add: $.cluster
+  value: prod`},
+			expectEnvs: []corev1.EnvVar{{
+				Name:  EnvEventTransformers,
+				Value: `[{"remove":"$.data.password"},{"add":"$.cluster","value":"prod"}]`,
+			}},
+		},
+		{
+			name:       "With invalid rules",
+			data:       map[string]string{EventTransformersRulesKey: `[{"remove": "$.id"}]`},
+			expectEnvs: []corev1.EnvVar{},
+		},
+		{
+			name:       "With empty data",
+			expectEnvs: []corev1.EnvVar{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := loggingtesting.TestContextWithLogger(t)
+			cw := WatchConfigurations(ctx, testComponent,
+				configmap.NewStaticWatcher(newTestConfigMap(EventTransformersConfigName, tc.data)),
+				WithEventTransformers,
+			)
+
+			assert.Equal(t, tc.expectEnvs, cw.ToEnvVars())
+		})
+	}
+}
+
 // configMapWatcherWithSampleData constructs a Watcher for static sample data.
 func configMapWatcherWithSampleData() configmap.Watcher {
 	return configmap.NewStaticWatcher(
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
+	coordinationlisters "k8s.io/client-go/listers/coordination/v1"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/controller"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+)
+
+// WatchDeliveryStatuses watches the Leases the adapters report the delivery
+// statuses of the sources of resourceGroup to, enqueuing their source when
+// they change. It returns the lister of the Leases.
+func WatchDeliveryStatuses(ctx context.Context, resourceGroup string, enqueue func(types.NamespacedName)) coordinationlisters.LeaseLister {
+	factory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeclient.Get(ctx), controller.GetResyncPeriod(ctx),
+		kubeinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = labels.SelectorFromSet(labels.Set{adapter.DeliveryStatusLabelKey: resourceGroup}).String()
+		}))
+	informer := factory.Coordination().V1().Leases()
+	informer.Informer().AddEventHandler(controller.HandleAll(func(obj interface{}) {
+		if lease, ok := obj.(*coordinationv1.Lease); ok && lease.Annotations[adapter.DeliverySourceAnnotationKey] != "" {
+			enqueue(types.NamespacedName{Namespace: lease.Namespace, Name: lease.Annotations[adapter.DeliverySourceAnnotationKey]})
+		}
+	}))
+	factory.Start(ctx.Done())
+	return informer.Lister()
+}
+
+// DeliveryStatus returns the delivery status reported for the source of
+// resourceGroup, nil when none was reported.
+func DeliveryStatus(lister coordinationlisters.LeaseLister, resourceGroup, namespace, name string) (*adapter.DeliveryStatus, error) {
+	lease, err := lister.Leases(namespace).Get(adapter.DeliveryStatusLeaseName(resourceGroup, name))
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	status := adapter.DeliveryStatusFromLease(lease)
+	return &status, nil
+}
+
+// DeliveryFailureMessage describes the deliveries failing for the condition
+// of the sources.
+func DeliveryFailureMessage(status *adapter.DeliveryStatus) string {
+	return fmt.Sprintf("%d consecutive deliveries failed, the last one at %s: %s",
+		status.ConsecutiveFailures, status.LastErrorTime.Format(time.RFC3339), status.LastError)
+}
@@ -263,6 +263,7 @@ func (r *Reconciler) resolveDeadLetterSink(ctx context.Context, subscription *v1
 				zap.Error(err),
 				zap.Any("delivery.deadLetterSink", subscription.Spec.Delivery.DeadLetterSink))
 			subscription.Status.MarkReferencesNotResolved(deadLetterSinkResolveFailed, "Failed to resolve spec.delivery.deadLetterSink: %v", err)
+			subscription.Status.MarkDeadLetterSinkNotResolved(deadLetterSinkResolveFailed, "Failed to resolve spec.delivery.deadLetterSink: %v", err)
 			return pkgreconciler.NewEvent(corev1.EventTypeWarning, deadLetterSinkResolveFailed, "Failed to resolve spec.delivery.deadLetterSink: %v", err)
 		}
 		// If there is a change in resolved URI, log it.
@@ -270,8 +271,10 @@ func (r *Reconciler) resolveDeadLetterSink(ctx context.Context, subscription *v1
 			logging.FromContext(ctx).Debugw("Resolved deadLetterSink", zap.String("deadLetterSinkURI", deadLetterSink.String()))
 			subscription.Status.PhysicalSubscription.DeadLetterSinkURI = deadLetterSink
 		}
+		subscription.Status.MarkDeadLetterSinkResolved()
 	} else {
 		subscription.Status.PhysicalSubscription.DeadLetterSinkURI = nil
+		subscription.Status.ClearDeadLetterSinkResolved()
 	}
 	return nil
 }
@@ -591,13 +594,21 @@ func deliverySpec(sub *v1.Subscription) *eventingduckv1beta1.DeliverySpec {
 			},
 		}
 	}
-	if sub.Spec.Delivery != nil && (sub.Spec.Delivery.BackoffDelay != nil || sub.Spec.Delivery.Retry != nil || sub.Spec.Delivery.BackoffPolicy != nil) {
+	if sub.Spec.Delivery != nil && (sub.Spec.Delivery.BackoffDelay != nil || sub.Spec.Delivery.Retry != nil || sub.Spec.Delivery.BackoffPolicy != nil || sub.Spec.Delivery.Timeout != nil) {
 		if delivery == nil {
 			delivery = &eventingduckv1beta1.DeliverySpec{}
 		}
 		delivery.BackoffPolicy = (*eventingduckv1beta1.BackoffPolicyType)(sub.Spec.Delivery.BackoffPolicy)
 		delivery.Retry = sub.Spec.Delivery.Retry
 		delivery.BackoffDelay = sub.Spec.Delivery.BackoffDelay
+		delivery.Timeout = sub.Spec.Delivery.Timeout
+	}
+	if sub.Spec.Delivery != nil && sub.Spec.Delivery.Format != nil {
+		if delivery == nil {
+			delivery = &eventingduckv1beta1.DeliverySpec{}
+		}
+		delivery.Format = &eventingduckv1beta1.DeliveryFormat{}
+		_ = delivery.Format.ConvertFrom(context.Background(), sub.Spec.Delivery.Format)
 	}
 	return delivery
 }
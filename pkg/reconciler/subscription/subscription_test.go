@@ -489,6 +489,7 @@ func TestAllCases(t *testing.T) {
 					// The first reconciliation will initialize the status conditions.
 					WithInitSubscriptionConditions,
 					WithSubscriptionReferencesNotResolved("DeadLetterSinkResolveFailed", `Failed to resolve spec.delivery.deadLetterSink: subscribers.eventing.knative.dev "dlc" not found`),
+					WithSubscriptionDeadLetterSinkNotResolved("DeadLetterSinkResolveFailed", `Failed to resolve spec.delivery.deadLetterSink: subscribers.eventing.knative.dev "dlc" not found`),
 					WithSubscriptionPhysicalSubscriptionSubscriber(subscriberURI),
 				),
 			}},
@@ -534,6 +535,7 @@ func TestAllCases(t *testing.T) {
 					MarkAddedToChannel,
 					WithSubscriptionPhysicalSubscriptionSubscriber(subscriberURI),
 					WithSubscriptionDeadLetterSinkURI(dlcURI),
+					MarkDeadLetterSinkResolved,
 				),
 			}},
 			WantPatches: []clientgotesting.PatchActionImpl{
@@ -1001,6 +1003,7 @@ func TestAllCases(t *testing.T) {
 					MarkAddedToChannel,
 					WithSubscriptionPhysicalSubscriptionSubscriber(serviceURIWithPath),
 					WithSubscriptionDeadLetterSinkURI(dlcURI),
+					MarkDeadLetterSinkResolved,
 				),
 			}},
 			WantPatches: []clientgotesting.PatchActionImpl{
@@ -1029,6 +1032,8 @@ func TestAllCases(t *testing.T) {
 						Retry:         pointer.Int32Ptr(10),
 						BackoffPolicy: &linear,
 						BackoffDelay:  pointer.StringPtr("PT1S"),
+						Timeout:       pointer.StringPtr("PT10S"),
+						Format:        &eventingduck.DeliveryFormat{DataContentType: pointer.StringPtr(eventingduck.DataContentTypeJSON)},
 					}),
 				),
 				NewUnstructured(subscriberGVK, dlcName, testNS,
@@ -1071,8 +1076,11 @@ func TestAllCases(t *testing.T) {
 						Retry:         pointer.Int32Ptr(10),
 						BackoffPolicy: &linear,
 						BackoffDelay:  pointer.StringPtr("PT1S"),
+						Timeout:       pointer.StringPtr("PT10S"),
+						Format:        &eventingduck.DeliveryFormat{DataContentType: pointer.StringPtr(eventingduck.DataContentTypeJSON)},
 					}),
 					WithSubscriptionDeadLetterSinkURI(dlcURI),
+					MarkDeadLetterSinkResolved,
 				),
 			}},
 			WantPatches: []clientgotesting.PatchActionImpl{
@@ -1087,6 +1095,8 @@ func TestAllCases(t *testing.T) {
 							Retry:         pointer.Int32Ptr(10),
 							BackoffPolicy: &linear,
 							BackoffDelay:  pointer.StringPtr("PT1S"),
+							Timeout:       pointer.StringPtr("PT10S"),
+							Format:        &eventingduck.DeliveryFormat{DataContentType: pointer.StringPtr(eventingduck.DataContentTypeJSON)},
 						},
 					},
 				}),
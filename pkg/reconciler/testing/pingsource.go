@@ -153,6 +153,12 @@ func WithPingSourceV1B1SinkNotFound(s *v1beta1.PingSource) {
 	s.Status.MarkNoSink("NotFound", "")
 }
 
+func WithPingSourceV1B1BrokerNotFound(name string) PingSourceV1B1Option {
+	return func(s *v1beta1.PingSource) {
+		s.Status.MarkNoSink("BrokerNotFound", "Broker %q not found", name)
+	}
+}
+
 func WithPingSourceV1B1Sink(uri *apis.URL) PingSourceV1B1Option {
 	return func(s *v1beta1.PingSource) {
 		s.Status.MarkSink(uri)
@@ -169,6 +175,38 @@ func WithPingSourceV1B1Deployed(s *v1beta1.PingSource) {
 	s.Status.PropagateDeploymentAvailability(NewDeployment("any", "any", WithDeploymentAvailable()))
 }
 
+func WithPingSourceV1B1ShadowSink(uri *apis.URL) PingSourceV1B1Option {
+	return func(s *v1beta1.PingSource) {
+		s.Status.ShadowSinkURI = uri
+	}
+}
+
+func WithPingSourceV1B1AlertSink(uri *apis.URL) PingSourceV1B1Option {
+	return func(s *v1beta1.PingSource) {
+		s.Status.AlertSinkURI = uri
+	}
+}
+
+func WithPingSourceV1B1DeadLetterSink(uri *apis.URL) PingSourceV1B1Option {
+	return func(s *v1beta1.PingSource) {
+		s.Status.DeadLetterSinkURI = uri
+	}
+}
+
+func WithPingSourceV1B1JobPanicking(s *v1beta1.PingSource) {
+	s.Status.MarkJobPanicking("JobPanicking", "Disabled after its job panicked on 5 consecutive fires: boom")
+}
+
+func WithPingSourceV1B1SinkDeliverable(s *v1beta1.PingSource) {
+	s.Status.MarkSinkDeliverable()
+}
+
+func WithPingSourceV1B1SinkUndeliverable(message string) PingSourceV1B1Option {
+	return func(s *v1beta1.PingSource) {
+		s.Status.MarkSinkUndeliverable("DeliveryFailed", "%s", message)
+	}
+}
+
 func WithPingSourceV1B1CloudEventAttributes(s *v1beta1.PingSource) {
 	s.Status.CloudEventAttributes = []duckv1.CloudEventAttributes{{
 		Type:   v1beta1.PingSourceEventType,
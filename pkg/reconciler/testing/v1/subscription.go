@@ -207,6 +207,16 @@ func MarkReferencesResolved(s *v1.Subscription) {
 	s.Status.MarkReferencesResolved()
 }
 
+func MarkDeadLetterSinkResolved(s *v1.Subscription) {
+	s.Status.MarkDeadLetterSinkResolved()
+}
+
+func WithSubscriptionDeadLetterSinkNotResolved(reason, msg string) SubscriptionOption {
+	return func(s *v1.Subscription) {
+		s.Status.MarkDeadLetterSinkNotResolved(reason, msg)
+	}
+}
+
 func WithSubscriptionReferencesNotResolved(reason, msg string) SubscriptionOption {
 	return func(s *v1.Subscription) {
 		s.Status.MarkReferencesNotResolved(reason, msg)
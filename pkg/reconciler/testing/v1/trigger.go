@@ -178,6 +178,12 @@ func WithDependencyAnnotation(dependencyAnnotation string) TriggerOption {
 	}
 }
 
+func WithTriggerDependencies(dependencies ...duckv1.KReference) TriggerOption {
+	return func(t *v1.Trigger) {
+		t.Spec.Dependencies = dependencies
+	}
+}
+
 func WithTriggerDependencyReady() TriggerOption {
 	return func(t *v1.Trigger) {
 		t.Status.MarkDependencySucceeded()